@@ -107,7 +107,7 @@ func AnalyzeAction(c *cli.Context) error {
 
 		// Extract word counts for analytics
 		if parseMode != models.ParseModeMinimal {
-			wordCounts := mapreduce.Map(page.ToPlainText(), a)
+			wordCounts := mapreduce.Map(page.ToPlainText(), page.Metadata.Language, a)
 			result.WordCounts = wordCounts
 		}
 
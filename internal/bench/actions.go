@@ -0,0 +1,260 @@
+// Package bench implements the bench command, which parses cached HTML repeatedly to
+// measure parser throughput, latency and allocations.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"github.com/urfave/cli/v2"
+)
+
+// Modes are the parse modes bench accepts, by name.
+var Modes = map[string]models.ParseMode{
+	"minimal": models.ParseModeMinimal,
+	"cheap":   models.ParseModeCheap,
+	"full":    models.ParseModeFull,
+}
+
+// Options select what Run parses and how often.
+type Options struct {
+	Patterns   []string // Glob patterns of HTML files
+	Mode       string   // minimal, cheap or full
+	Iterations int      // Timed parses per file
+	Warmup     int      // Untimed parses per file before the timed ones; the first always runs, to check the file parses
+	CleanHTML  bool
+}
+
+// FileResult is one file's measurements. Latencies are in milliseconds.
+type FileResult struct {
+	File        string  `json:"file"`
+	Bytes       int     `json:"bytes"`
+	Iterations  int     `json:"iterations"`
+	MeanMs      float64 `json:"mean_ms"`
+	P50Ms       float64 `json:"p50_ms"`
+	P95Ms       float64 `json:"p95_ms"`
+	AllocsPerOp uint64  `json:"allocs_per_op"`
+	BytesPerOp  uint64  `json:"bytes_per_op"`
+	Blocks      int     `json:"blocks"` // Content blocks the parse produced
+	Error       string  `json:"error,omitempty"`
+}
+
+// Report is what the bench command prints. Files are ordered slowest p95 first.
+type Report struct {
+	Mode         string       `json:"mode"`
+	Iterations   int          `json:"iterations"`
+	Files        []FileResult `json:"files"`
+	Parses       int          `json:"parses"` // Timed parses that succeeded
+	Failed       int          `json:"failed"` // Files that could not be read or parsed
+	TotalSeconds float64      `json:"total_seconds"`
+	PagesPerSec  float64      `json:"pages_per_sec"`
+	MBPerSec     float64      `json:"mb_per_sec"`
+	P50Ms        float64      `json:"p50_ms"` // Over every timed parse of every file
+	P95Ms        float64      `json:"p95_ms"`
+}
+
+// Run parses every matched file Warmup times untimed, then Iterations times timed, one
+// file at a time so each file's allocation counts are its own.
+func Run(opts Options) (*Report, error) {
+	mode, ok := Modes[strings.ToLower(opts.Mode)]
+	if !ok {
+		return nil, fmt.Errorf("unknown --mode %q (supported: minimal, cheap, full)", opts.Mode)
+	}
+	if opts.Iterations < 1 {
+		return nil, fmt.Errorf("--iterations must be at least 1")
+	}
+	files, err := corpus.ExpandPatterns(opts.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", strings.Join(opts.Patterns, ", "))
+	}
+
+	p := &parser.Parser{}
+	report := &Report{Mode: strings.ToLower(opts.Mode), Iterations: opts.Iterations}
+	var all []time.Duration
+	var totalBytes int
+	var total time.Duration
+	for _, file := range files {
+		result := FileResult{File: file}
+		html, err := os.ReadFile(file)
+		if err != nil {
+			result.Error = err.Error()
+			report.Files = append(report.Files, result)
+			report.Failed++
+			continue
+		}
+		result.Bytes = len(html)
+
+		abs, _ := filepath.Abs(file)
+		req := models.ParseRequest{URL: "file://" + filepath.ToSlash(abs), HTML: string(html), Mode: mode, CleanHTML: opts.CleanHTML}
+		page, err := p.Parse(req)
+		if err != nil {
+			result.Error = err.Error()
+			report.Files = append(report.Files, result)
+			report.Failed++
+			continue
+		}
+		result.Blocks = len(page.AllTextBlocks())
+		for i := 1; i < opts.Warmup; i++ {
+			_, _ = p.Parse(req)
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		durations := make([]time.Duration, opts.Iterations)
+		for i := range durations {
+			start := time.Now()
+			_, _ = p.Parse(req)
+			durations[i] = time.Since(start)
+		}
+		runtime.ReadMemStats(&after)
+
+		var sum time.Duration
+		for _, d := range durations {
+			sum += d
+		}
+		result.Iterations = opts.Iterations
+		result.MeanMs = millis(sum / time.Duration(opts.Iterations))
+		result.P50Ms = millis(percentile(durations, 50))
+		result.P95Ms = millis(percentile(durations, 95))
+		result.AllocsPerOp = (after.Mallocs - before.Mallocs) / uint64(opts.Iterations)
+		result.BytesPerOp = (after.TotalAlloc - before.TotalAlloc) / uint64(opts.Iterations)
+		report.Files = append(report.Files, result)
+
+		all = append(all, durations...)
+		total += sum
+		totalBytes += len(html) * opts.Iterations
+		report.Parses += opts.Iterations
+	}
+
+	sort.SliceStable(report.Files, func(i, j int) bool { return report.Files[i].P95Ms > report.Files[j].P95Ms })
+	report.TotalSeconds = math.Round(total.Seconds()*1000) / 1000
+	if total > 0 {
+		report.PagesPerSec = math.Round(float64(report.Parses)/total.Seconds()*10) / 10
+		report.MBPerSec = math.Round(float64(totalBytes)/(1<<20)/total.Seconds()*100) / 100
+	}
+	report.P50Ms = millis(percentile(all, 50))
+	report.P95Ms = millis(percentile(all, 95))
+	return report, nil
+}
+
+// percentile returns the nearest-rank percentile of durations (0 for none). The
+// slice is sorted in place.
+func percentile(durations []time.Duration, pct int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(float64(pct) / 100 * float64(len(durations))))
+	if rank < 1 {
+		rank = 1
+	}
+	return durations[rank-1]
+}
+
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
+
+// BenchAction runs the benchmark, optionally under the CPU profiler, and prints a
+// summary table or JSON.
+func BenchAction(c *cli.Context) error {
+	opts := Options{
+		Patterns:   append(c.StringSlice("from"), c.Args().Slice()...),
+		Mode:       c.String("mode"),
+		Iterations: c.Int("iterations"),
+		Warmup:     c.Int("warmup"),
+		CleanHTML:  c.Bool("clean-html"),
+	}
+	if len(opts.Patterns) == 0 {
+		return fmt.Errorf("no HTML files given; use --from 'raw/*.html'")
+	}
+	format := strings.ToLower(c.String("format"))
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown --format %q (supported: text, json)", c.String("format"))
+	}
+
+	if path := c.String("cpuprofile"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	report, err := Run(opts)
+	if err != nil {
+		return err
+	}
+
+	if path := c.String("memprofile"); path != "" {
+		if err := writeHeapProfile(path); err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+		return nil
+	}
+	printReport(c.App.Writer, report)
+	return nil
+}
+
+// writeHeapProfile writes the allocation profile of the run to path.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	defer f.Close()
+	runtime.GC() // Up-to-date statistics
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
+
+func printReport(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "%-40s %9s %9s %9s %9s %10s %12s %7s\n", "FILE", "KB", "MEAN ms", "P50 ms", "P95 ms", "ALLOCS/op", "BYTES/op", "BLOCKS")
+	for _, f := range report.Files {
+		name := f.File
+		if len(name) > 40 {
+			name = "…" + name[len(name)-39:]
+		}
+		if f.Error != "" {
+			fmt.Fprintf(w, "%-40s error: %s\n", name, f.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%-40s %9.1f %9.2f %9.2f %9.2f %10d %12d %7d\n",
+			name, float64(f.Bytes)/1024, f.MeanMs, f.P50Ms, f.P95Ms, f.AllocsPerOp, f.BytesPerOp, f.Blocks)
+	}
+	fmt.Fprintf(w, "\nMode %s, %d iterations: %d parses in %.3fs, %.1f pages/s, %.2f MB/s, p50 %.2f ms, p95 %.2f ms\n",
+		report.Mode, report.Iterations, report.Parses, report.TotalSeconds, report.PagesPerSec, report.MBPerSec, report.P50Ms, report.P95Ms)
+	if report.Failed > 0 {
+		fmt.Fprintf(w, "%d file(s) could not be parsed\n", report.Failed)
+	}
+}
@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const benchPage = `<html><head><title>Bench</title></head><body><article>
+<h1>Bench</h1>
+<p>The parser turns this page into sections and blocks for the benchmark to time.</p>
+<h2>Details</h2>
+<p>Another paragraph with enough words to count as readable content for the parser.</p>
+</article></body></html>`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.html"), []byte(benchPage), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.html"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(Options{Patterns: []string{filepath.Join(dir, "*.html")}, Mode: "full", Iterations: 3, Warmup: 1})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Files) != 1 || report.Parses != 3 || report.Failed != 0 {
+		t.Fatalf("report = %+v, want one file parsed 3 times", report)
+	}
+	f := report.Files[0]
+	if f.Bytes != len(benchPage) || f.Blocks == 0 || f.AllocsPerOp == 0 {
+		t.Errorf("file result = %+v, want its size, blocks and allocations", f)
+	}
+	if f.P50Ms > f.P95Ms || report.PagesPerSec <= 0 {
+		t.Errorf("latencies p50 %.2f p95 %.2f, %.1f pages/s", f.P50Ms, f.P95Ms, report.PagesPerSec)
+	}
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	for name, opts := range map[string]Options{
+		"unknown mode":  {Patterns: []string{filepath.Join(dir, "*")}, Mode: "deep", Iterations: 1},
+		"no iterations": {Patterns: []string{filepath.Join(dir, "*")}, Mode: "full"},
+		"no matches":    {Patterns: []string{filepath.Join(dir, "*.html")}, Mode: "full", Iterations: 1},
+	} {
+		if _, err := Run(opts); err == nil {
+			t.Errorf("%s: Run() succeeded, want an error", name)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentile(durations, 50); got != 10*time.Millisecond {
+		t.Errorf("p50 = %v, want 10ms", got)
+	}
+	if got := percentile(durations, 95); got != 19*time.Millisecond {
+		t.Errorf("p95 = %v, want 19ms", got)
+	}
+	if got := percentile(nil, 95); got != 0 {
+		t.Errorf("p95 of nothing = %v, want 0", got)
+	}
+}
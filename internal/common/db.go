@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/urfave/cli/v2"
+)
+
+// DBRetryPolicy reads the global --db-retries and --db-retry-delay flags on top of
+// dbpkg.DefaultRetryPolicy; flags that are unset, or that the app does not define,
+// keep the default.
+func DBRetryPolicy(c *cli.Context) (dbpkg.RetryPolicy, error) {
+	policy := dbpkg.DefaultRetryPolicy
+	if c.IsSet("db-retries") {
+		policy.MaxRetries = c.Int("db-retries")
+	}
+	if c.IsSet("db-retry-delay") {
+		delay, err := time.ParseDuration(c.String("db-retry-delay"))
+		if err != nil {
+			return dbpkg.RetryPolicy{}, fmt.Errorf("invalid --db-retry-delay %q: %w", c.String("db-retry-delay"), err)
+		}
+		policy.BaseDelay = delay
+	}
+	if policy.MaxRetries < 0 || policy.BaseDelay < 0 {
+		return dbpkg.RetryPolicy{}, fmt.Errorf("invalid database retry settings: --db-retries %d --db-retry-delay %s", policy.MaxRetries, policy.BaseDelay)
+	}
+	return policy, nil
+}
+
+// OpenDB opens the database in the current working directory with the retry policy
+// from the global flags.
+func OpenDB(c *cli.Context) (*dbpkg.DB, error) {
+	retry, err := DBRetryPolicy(c)
+	if err != nil {
+		return nil, err
+	}
+	return dbpkg.OpenWithOptions(dbpkg.Options{Retry: retry})
+}
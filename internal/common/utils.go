@@ -1,9 +1,7 @@
 package common
 
 import (
-	"crypto/sha256"
 	"encoding/json"
-	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
@@ -21,6 +19,7 @@ var fieldNameMap = map[string]string{
 	"extraction_quality":      "q",
 	"confidence_distribution": "cd",
 	"block_type_distribution": "bd",
+	"duplicate_of":            "do",
 }
 
 func FilterResultFields(result interface{}, fieldsStr string, isTerse bool) map[string]interface{} {
@@ -72,12 +71,6 @@ func structToMap(obj interface{}) map[string]interface{} {
 	return result
 }
 
-// contentHash computes SHA256 hash of content and returns hex string.
-func ContentHash(data []byte) string {
-	hash := sha256.Sum256(data)
-	return fmt.Sprintf("%x", hash)
-}
-
 // sanitizeURL performs basic cleanup on URLs to handle common copy-paste issues.
 // Removes whitespace, trailing punctuation, markdown artifacts, and encodes spaces.
 func SanitizeURL(rawURL string) string {
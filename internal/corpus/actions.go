@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/dtnitsch/llm-web-parser/internal/common"
 	internaldb "github.com/dtnitsch/llm-web-parser/internal/db"
 	"github.com/dtnitsch/llm-web-parser/models"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
@@ -43,7 +44,7 @@ func CorpusAction(c *cli.Context) error {
 
 	// If no session and no url-ids, default to active session for extract
 	if sessionID == 0 && len(urlIDs) == 0 && c.Command.Name == "extract" {
-		database, err := dbpkg.Open()
+		database, err := common.OpenDB(c)
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
@@ -73,6 +74,25 @@ func CorpusAction(c *cli.Context) error {
 		}
 	}
 
+	// query is scoped to the active or latest session unless --all asks for the whole corpus
+	if c.Command.Name == "query" {
+		if c.Bool("all") && c.IsSet("session") {
+			return fmt.Errorf("use either --session or --all, not both")
+		}
+		if !c.Bool("all") && sessionID == 0 {
+			database, err := common.OpenDB(c)
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			resolved, err := resolveSession(c, database)
+			database.Close()
+			if err != nil {
+				return err
+			}
+			sessionID = int(resolved)
+		}
+	}
+
 	// Build constraints map for verb-specific parameters
 	constraints := make(map[string]interface{})
 	// Check --top first, fall back to --limit
@@ -85,6 +105,50 @@ func CorpusAction(c *cli.Context) error {
 		constraints["top"] = top
 	}
 
+	if domain := c.String("domain"); domain != "" {
+		constraints["domain"] = domain
+	}
+	if facet := c.String("facet"); facet != "" {
+		constraints["facet"] = facet
+	}
+	if c.Bool("snippets") {
+		constraints["snippets"] = true
+	}
+	if c.Bool("explain-sql") {
+		constraints["explain_sql"] = true
+	}
+	if sortBy := c.String("sort"); sortBy != "" {
+		constraints["sort"] = sortBy
+	}
+	if c.Command.Name == "detect" {
+		// The pattern may also be given as an argument after the flags: detect --session=7 classification
+		pattern := c.String("pattern")
+		if c.Args().Present() {
+			pattern = c.Args().First()
+		}
+		constraints["pattern"] = pattern
+		constraints["clean_html"] = c.Bool("clean-html")
+		constraints["min_content_length"] = c.Int("min-content-length")
+	}
+	if c.Command.Name == "trace" {
+		// The term may also be given as an argument after the flags: trace --url-ids=5 error
+		term := c.String("term")
+		if c.Args().Present() {
+			term = strings.Join(c.Args().Slice(), " ")
+		}
+		constraints["term"] = term
+	}
+	if c.Command.Name == "ingest" {
+		// Files may also be given as arguments: ingest export/a.json export/b.json
+		constraints["from"] = append(c.StringSlice("from"), c.Args().Slice()...)
+		constraints["session_tag"] = c.String("session-tag")
+	}
+	if c.Command.Name == "normalize" {
+		constraints["stem"] = c.Bool("stem")
+		constraints["dry_run"] = c.Bool("dry-run")
+		constraints["stopwords"] = c.String("stopwords")
+	}
+
 	// Build request from CLI flags
 	req := models.Request{
 		Verb:        c.Command.Name, // extract, query, etc.
@@ -116,11 +180,23 @@ func CorpusAction(c *cli.Context) error {
 		return nil
 	}
 
-	// Compact output for extract verb
+	// Compact output for extract verb, unless a machine-readable format was asked for
 	if req.Verb == "extract" {
+		if c.IsSet("format") {
+			return outputExtractFormatted(c.App.Writer, &resp, sessionID, c.String("format"))
+		}
 		return outputExtractCompact(&resp, sessionID, isActiveSession, c.Int("top"))
 	}
 
+	if (req.Verb == "detect" || req.Verb == "normalize" || req.Verb == "trace" || req.Verb == "ingest") && strings.EqualFold(req.Format, "json") {
+		output, err := json.MarshalIndent(resp, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(output))
+		return nil
+	}
+
 	// Default: output full YAML for other verbs
 	yamlBytes, err := yaml.Marshal(resp)
 	if err != nil {
@@ -226,6 +302,58 @@ func outputExtractCompact(resp *models.Response, sessionID int, isActiveSession
 	return nil
 }
 
+// ExtractOutput is the keyword list printed by corpus extract --format json|yaml.
+type ExtractOutput struct {
+	SessionID int                   `json:"session_id,omitempty" yaml:"session_id,omitempty"`
+	URLCount  int                   `json:"url_count" yaml:"url_count"`
+	Keywords  []corpus.KeywordCount `json:"keywords" yaml:"keywords"`
+}
+
+// outputExtractFormatted writes the extracted keywords as JSON, YAML or word,count,display CSV.
+func outputExtractFormatted(w io.Writer, resp *models.Response, sessionID int, format string) error {
+	if resp.Error != nil {
+		return fmt.Errorf("%s", resp.Error.Message)
+	}
+	data, ok := resp.Data.(corpus.ExtractResponse)
+	if !ok {
+		return fmt.Errorf("unexpected extract response")
+	}
+	output := ExtractOutput{SessionID: sessionID, URLCount: data.URLCount, Keywords: data.Keywords}
+	if output.Keywords == nil {
+		output.Keywords = []corpus.KeywordCount{}
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		jsonData, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(jsonData))
+	case "yaml":
+		yamlData, err := yaml.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(w, string(yamlData))
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"word", "count", "display"}); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+		for _, kw := range output.Keywords {
+			if err := writer.Write([]string{kw.Word, strconv.Itoa(kw.Count), kw.Display}); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown --format %q (use json, yaml or csv)", format)
+	}
+	return nil
+}
+
 // GrepAction handles corpus grep command - search across multiple URLs
 func GrepAction(c *cli.Context) error {
 	if c.NArg() == 0 {
@@ -238,41 +366,16 @@ func GrepAction(c *cli.Context) error {
 	pattern := c.Args().First()
 
 	// Open database
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer database.Close()
 
 	// Get session ID (custom logic since pattern is positional arg, not session)
-	var sessionID int64
-	if c.IsSet("session") {
-		sessionID = int64(c.Int("session"))
-		if sessionID <= 0 {
-			return fmt.Errorf("invalid session ID: %d (must be > 0)", sessionID)
-		}
-	} else {
-		// Check for active session first
-		activeSessionID := internaldb.GetActiveSession()
-		if activeSessionID > 0 {
-			// Verify it still exists
-			_, err := database.GetSessionByID(activeSessionID)
-			if err == nil {
-				sessionID = activeSessionID
-			}
-		}
-
-		// Fall back to latest session if no active session
-		if sessionID == 0 {
-			sessions, err := database.ListSessions(1)
-			if err != nil {
-				return fmt.Errorf("failed to get latest session: %w", err)
-			}
-			if len(sessions) == 0 {
-				return fmt.Errorf("no sessions found. Run 'lwp fetch --urls \"...\"' first")
-			}
-			sessionID = sessions[0].SessionID
-		}
+	sessionID, err := resolveSession(c, database)
+	if err != nil {
+		return err
 	}
 
 	// Check if session is active
@@ -280,37 +383,9 @@ func GrepAction(c *cli.Context) error {
 	isActive := (sessionID == activeSessionID)
 
 	// Get URLs to search
-	var urlIDs []int64
-	if urlsArg := c.String("urls"); urlsArg != "" {
-		// Parse comma-separated URL IDs or URLs
-		parts := strings.Split(urlsArg, ",")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "" {
-				continue
-			}
-
-			// Try parsing as ID first
-			if id, err := strconv.ParseInt(part, 10, 64); err == nil {
-				urlIDs = append(urlIDs, id)
-			} else {
-				// Try resolving as URL
-				id, err := database.GetURLID(part)
-				if err != nil {
-					return fmt.Errorf("URL not found: %s", part)
-				}
-				urlIDs = append(urlIDs, id)
-			}
-		}
-	} else {
-		// Get all URLs from session
-		urls, err := database.GetSessionURLsWithMetadata(sessionID)
-		if err != nil {
-			return fmt.Errorf("failed to get session URLs: %w", err)
-		}
-		for _, u := range urls {
-			urlIDs = append(urlIDs, u.URLID)
-		}
+	urlIDs, err := resolveURLIDs(c, database, sessionID)
+	if err != nil {
+		return err
 	}
 
 	if len(urlIDs) == 0 {
@@ -352,7 +427,7 @@ func GrepAction(c *cli.Context) error {
 
 	// Search each URL
 	for _, urlID := range urlIDs {
-		data, found, err := manager.GetParsedJSONByID(urlID)
+		page, found, err := manager.GetParsedPageByID(urlID)
 		if err != nil {
 			fmt.Fprintf(c.App.ErrWriter, "Warning: failed to read URL %d: %v\n", urlID, err)
 			continue
@@ -361,19 +436,12 @@ func GrepAction(c *cli.Context) error {
 			continue
 		}
 
-		// Parse YAML to get page
-		var page models.Page
-		if err := yaml.Unmarshal(data, &page); err != nil {
-			fmt.Fprintf(c.App.ErrWriter, "Warning: failed to parse URL %d: %v\n", urlID, err)
-			continue
-		}
-
 		// Count matches per pattern
 		matchesByPattern := make(map[string]int)
 		urlTotal := 0
 
 		for i, re := range regexes {
-			count := countMatches(&page, re)
+			count := countMatches(page, re)
 			matchesByPattern[subPatterns[i]] = count
 			urlTotal += count
 		}
@@ -416,6 +484,73 @@ func GrepAction(c *cli.Context) error {
 	}
 }
 
+// resolveSession returns --session, else the active session, else the latest session.
+func resolveSession(c *cli.Context, database *dbpkg.DB) (int64, error) {
+	if c.IsSet("session") {
+		sessionID := int64(c.Int("session"))
+		if sessionID <= 0 {
+			return 0, fmt.Errorf("invalid session ID: %d (must be > 0)", sessionID)
+		}
+		return sessionID, nil
+	}
+
+	// Check for active session first
+	if activeSessionID := internaldb.GetActiveSession(); activeSessionID > 0 {
+		// Verify it still exists
+		if _, err := database.GetSessionByID(activeSessionID); err == nil {
+			return activeSessionID, nil
+		}
+	}
+
+	// Fall back to latest session if no active session
+	sessions, err := database.ListSessions(1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest session: %w", err)
+	}
+	if len(sessions) == 0 {
+		return 0, fmt.Errorf("no sessions found. Run 'lwp fetch --urls \"...\"' first")
+	}
+	return sessions[0].SessionID, nil
+}
+
+// resolveURLIDs returns the URL IDs named by --urls (IDs or URLs), or every URL in the session.
+func resolveURLIDs(c *cli.Context, database *dbpkg.DB, sessionID int64) ([]int64, error) {
+	var urlIDs []int64
+	if urlsArg := c.String("urls"); urlsArg != "" {
+		// Parse comma-separated URL IDs or URLs
+		parts := strings.Split(urlsArg, ",")
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			// Try parsing as ID first
+			if id, err := strconv.ParseInt(part, 10, 64); err == nil {
+				urlIDs = append(urlIDs, id)
+			} else {
+				// Try resolving as URL
+				id, err := database.GetURLID(part)
+				if err != nil {
+					return nil, fmt.Errorf("URL not found: %s", part)
+				}
+				urlIDs = append(urlIDs, id)
+			}
+		}
+		return urlIDs, nil
+	}
+
+	// Get all URLs from session
+	urls, err := database.GetSessionURLsWithMetadata(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session URLs: %w", err)
+	}
+	for _, u := range urls {
+		urlIDs = append(urlIDs, u.URLID)
+	}
+	return urlIDs, nil
+}
+
 // countMatches counts pattern matches in a page
 func countMatches(page *models.Page, re *regexp.Regexp) int {
 	count := 0
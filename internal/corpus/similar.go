@@ -0,0 +1,87 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SimilarAction lists the URLs of a session most similar to --url-id, by stored keywords
+// and content type.
+func SimilarAction(c *cli.Context) error {
+	urlID := c.Int64("url-id")
+	if urlID <= 0 {
+		return fmt.Errorf("--url-id is required (see 'lwp db urls' for IDs)")
+	}
+	if c.Int("top") < 0 {
+		return fmt.Errorf("--top must be >= 0")
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := resolveSession(c, database)
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetSessionByID(sessionID); err != nil {
+		return fmt.Errorf("failed to get session %d: %w", sessionID, err)
+	}
+
+	result, err := corpus.FindSimilar(database, sessionID, urlID, c.Int("top"))
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(data))
+	default:
+		printSimilar(c.App.Writer, result)
+	}
+	return nil
+}
+
+// printSimilar writes one row per match, with its shared keywords.
+func printSimilar(w io.Writer, result *corpus.SimilarResult) {
+	if len(result.Similar) == 0 {
+		fmt.Fprintf(w, "No URLs in session %d share keywords or a content type with URL %d\n", result.SessionID, result.URLID)
+		return
+	}
+
+	fmt.Fprintf(w, "URLs in session %d similar to [%d] %s\n\n", result.SessionID, result.URLID, result.URL)
+	fmt.Fprintf(w, "  %-6s %6s %8s  %-14s %s\n", "ID", "SCORE", "KEYWORDS", "TYPE", "URL")
+	for _, m := range result.Similar {
+		contentType := m.ContentType
+		if contentType == "" {
+			contentType = "-"
+		}
+		if m.SameContentType {
+			contentType += "*"
+		}
+		fmt.Fprintf(w, "  %-6d %6.3f %8.3f  %-14s %s\n", m.URLID, m.Score, m.KeywordScore, contentType, m.URL)
+		if len(m.SharedKeywords) > 0 {
+			fmt.Fprintf(w, "  %-6s shared: %s\n", "", strings.Join(m.SharedKeywords, ", "))
+		}
+	}
+	fmt.Fprintln(w, "\n* same content type as the query URL")
+}
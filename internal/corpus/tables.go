@@ -0,0 +1,119 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// TableRecords is one extracted table, with rows as header-keyed records.
+type TableRecords struct {
+	URLID   int64               `json:"url_id" yaml:"url_id"`
+	URL     string              `json:"url" yaml:"url"`
+	BlockID string              `json:"block_id,omitempty" yaml:"block_id,omitempty"`
+	Columns []string            `json:"columns" yaml:"columns"`
+	Records []map[string]string `json:"records" yaml:"records"`
+}
+
+// TablesAction returns every table in a session as JSON/YAML records.
+func TablesAction(c *cli.Context) error {
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := resolveSession(c, database)
+	if err != nil {
+		return err
+	}
+
+	urlIDs, err := resolveURLIDs(c, database, sessionID)
+	if err != nil {
+		return err
+	}
+
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, 0)
+	if err != nil {
+		return fmt.Errorf("failed to initialize artifact manager: %w", err)
+	}
+
+	minRows := c.Int("min-rows")
+	tables := []TableRecords{}
+	for _, urlID := range urlIDs {
+		page, found, err := manager.GetParsedPageByID(urlID)
+		if err != nil {
+			fmt.Fprintf(c.App.ErrWriter, "Warning: failed to read URL %d: %v\n", urlID, err)
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		for _, block := range pageTableBlocks(page) {
+			if len(block.Table.Rows) < minRows {
+				continue
+			}
+			tables = append(tables, TableRecords{
+				URLID:   urlID,
+				URL:     page.URL,
+				BlockID: block.ID,
+				Columns: block.Table.RecordKeys(),
+				Records: block.Table.ToRecords(),
+			})
+		}
+	}
+
+	output := map[string]interface{}{
+		"session_id":   sessionID,
+		"total_tables": len(tables),
+		"tables":       tables,
+	}
+
+	if strings.ToLower(c.String("format")) == "yaml" {
+		data, err := yaml.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// pageTableBlocks returns the table blocks of a page in document order.
+func pageTableBlocks(page *models.Page) []models.ContentBlock {
+	var blocks []models.ContentBlock
+	for _, block := range page.FlatContent {
+		if block.Table != nil {
+			blocks = append(blocks, block)
+		}
+	}
+
+	var walk func(sections []models.Section)
+	walk = func(sections []models.Section) {
+		for _, section := range sections {
+			for _, block := range section.Blocks {
+				if block.Table != nil {
+					blocks = append(blocks, block)
+				}
+			}
+			walk(section.Children)
+		}
+	}
+	walk(page.Content)
+
+	return blocks
+}
@@ -0,0 +1,115 @@
+package corpus
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// VocabOutput is every stored keyword of a session with its document frequency.
+type VocabOutput struct {
+	SessionID   int64                    `json:"session_id" yaml:"session_id"`
+	URLCount    int                      `json:"url_count" yaml:"url_count"`
+	MinDocCount int                      `json:"min_doc_count" yaml:"min_doc_count"`
+	Keywords    []dbpkg.KeywordFrequency `json:"keywords" yaml:"keywords"`
+}
+
+// VocabAction lists the distinct keywords of a session with how many URLs each appears in.
+func VocabAction(c *cli.Context) error {
+	minDocCount := c.Int("min-doc-count")
+	if minDocCount < 1 {
+		return fmt.Errorf("--min-doc-count must be >= 1")
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := resolveSession(c, database)
+	if err != nil {
+		return err
+	}
+
+	session, err := database.GetSessionByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session %d: %w", sessionID, err)
+	}
+
+	keywords, err := database.GetKeywordDocFrequency(sessionID, minDocCount)
+	if err != nil {
+		return err
+	}
+	if top := c.Int("top"); top > 0 && len(keywords) > top {
+		keywords = keywords[:top]
+	}
+	if keywords == nil {
+		keywords = []dbpkg.KeywordFrequency{}
+	}
+
+	output := VocabOutput{
+		SessionID:   sessionID,
+		URLCount:    session.URLCount,
+		MinDocCount: minDocCount,
+		Keywords:    keywords,
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(data))
+	case "csv":
+		return writeVocabCSV(c.App.Writer, keywords)
+	default:
+		printVocab(c.App.Writer, output)
+	}
+	return nil
+}
+
+// printVocab writes the keyword table as aligned text.
+func printVocab(w io.Writer, output VocabOutput) {
+	if len(output.Keywords) == 0 {
+		fmt.Fprintf(w, "No keywords in %d+ URLs of session %d (keywords come from fetch --features wordcount or full-parse)\n",
+			output.MinDocCount, output.SessionID)
+		return
+	}
+
+	fmt.Fprintf(w, "Session %d: %d keywords across %d URLs\n\n", output.SessionID, len(output.Keywords), output.URLCount)
+	fmt.Fprintf(w, "%-30s %8s %6s\n", "WORD", "TOTAL", "DOCS")
+	for _, kw := range output.Keywords {
+		fmt.Fprintf(w, "%-30s %8d %6d\n", kw.Word, kw.TotalCount, kw.DocCount)
+	}
+}
+
+// writeVocabCSV writes word,total_count,doc_count rows with a header.
+func writeVocabCSV(w io.Writer, keywords []dbpkg.KeywordFrequency) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"word", "total_count", "doc_count"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, kw := range keywords {
+		if err := writer.Write([]string{kw.Word, strconv.Itoa(kw.TotalCount), strconv.Itoa(kw.DocCount)}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
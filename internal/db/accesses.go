@@ -0,0 +1,117 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// AccessHistory is the machine-readable output of db accesses.
+type AccessHistory struct {
+	URLID     int64          `json:"url_id" yaml:"url_id"`
+	URL       string         `json:"url" yaml:"url"`
+	Attempts  int            `json:"attempts" yaml:"attempts"`
+	Succeeded int            `json:"succeeded" yaml:"succeeded"`
+	Accesses  []AccessOutput `json:"accesses" yaml:"accesses"`
+}
+
+// AccessOutput describes one fetch attempt.
+type AccessOutput struct {
+	AccessedAt time.Time `json:"accessed_at" yaml:"accessed_at"`
+	StatusCode int       `json:"status_code" yaml:"status_code"`
+	ErrorType  string    `json:"error_type,omitempty" yaml:"error_type,omitempty"`
+	Success    bool      `json:"success" yaml:"success"`
+}
+
+// AccessesAction shows a URL's fetch history (by ID or URL), newest first
+func AccessesAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		fmt.Println("Error: URL ID or URL required")
+		fmt.Println()
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	urlID, err := ResolveURLID(c.Args().First(), database)
+	if err != nil {
+		return err
+	}
+	url, err := database.GetURLByID(urlID)
+	if err != nil {
+		return err
+	}
+
+	records, err := database.GetAccessHistory(urlID, c.Int("limit"))
+	if err != nil {
+		return err
+	}
+
+	history := AccessHistory{URLID: urlID, URL: url, Accesses: make([]AccessOutput, 0, len(records))}
+	for _, r := range records {
+		history.Attempts++
+		if r.Success {
+			history.Succeeded++
+		}
+		history.Accesses = append(history.Accesses, AccessOutput{
+			AccessedAt: r.AccessedAt,
+			StatusCode: r.StatusCode,
+			ErrorType:  r.ErrorType,
+			Success:    r.Success,
+		})
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		output, err := json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	case "yaml":
+		output, err := yaml.Marshal(history)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Print(string(output))
+	default:
+		printAccessHistory(history)
+	}
+
+	return nil
+}
+
+// printAccessHistory prints a URL's fetch history in compact text format.
+func printAccessHistory(history AccessHistory) {
+	fmt.Printf("[#%d] %s\n", history.URLID, history.URL)
+
+	if history.Attempts == 0 {
+		fmt.Println("No fetch attempts recorded for this URL")
+		return
+	}
+
+	fmt.Printf("%d attempts, %d succeeded (%.0f%%)\n\n",
+		history.Attempts, history.Succeeded, 100*float64(history.Succeeded)/float64(history.Attempts))
+	for _, a := range history.Accesses {
+		outcome := "ok"
+		if !a.Success {
+			outcome = "FAIL"
+		}
+		status := "-"
+		if a.StatusCode > 0 {
+			status = fmt.Sprintf("%d", a.StatusCode)
+		}
+		fmt.Printf("  %s  %-4s  %3s  %s\n",
+			a.AccessedAt.Local().Format("2006-01-02 15:04:05"), outcome, status, a.ErrorType)
+	}
+}
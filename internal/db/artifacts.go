@@ -0,0 +1,126 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ArtifactListing is the machine-readable output of db artifacts.
+type ArtifactListing struct {
+	URLID     int64            `json:"url_id" yaml:"url_id"`
+	URL       string           `json:"url" yaml:"url"`
+	Artifacts []ArtifactOutput `json:"artifacts" yaml:"artifacts"`
+}
+
+// ArtifactOutput describes one stored artifact.
+type ArtifactOutput struct {
+	Type        string    `json:"type" yaml:"type"`
+	Path        string    `json:"path" yaml:"path"`
+	SizeBytes   int64     `json:"size_bytes" yaml:"size_bytes"`
+	ContentHash string    `json:"content_hash" yaml:"content_hash"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+}
+
+// ArtifactsAction lists the artifacts stored for a URL (by ID or URL)
+func ArtifactsAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		fmt.Println("Error: URL ID or URL required")
+		fmt.Println()
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	urlID, err := ResolveURLID(c.Args().First(), database)
+	if err != nil {
+		return err
+	}
+	url, err := database.GetURLByID(urlID)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := database.ListArtifacts(urlID)
+	if err != nil {
+		return err
+	}
+
+	listing := ArtifactListing{URLID: urlID, URL: url, Artifacts: make([]ArtifactOutput, 0, len(artifacts))}
+	for _, a := range artifacts {
+		listing.Artifacts = append(listing.Artifacts, ArtifactOutput{
+			Type:        a.TypeName,
+			Path:        a.FilePath,
+			SizeBytes:   a.SizeBytes,
+			ContentHash: a.ContentHash,
+			CreatedAt:   a.CreatedAt,
+		})
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		output, err := json.MarshalIndent(listing, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	case "yaml":
+		output, err := yaml.Marshal(listing)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Print(string(output))
+	default:
+		printArtifacts(listing)
+	}
+
+	return nil
+}
+
+// printArtifacts prints an artifact listing in compact text format.
+func printArtifacts(listing ArtifactListing) {
+	fmt.Printf("[#%d] %s\n", listing.URLID, listing.URL)
+
+	if len(listing.Artifacts) == 0 {
+		fmt.Println("No artifacts stored for this URL (the fetch may have failed, or it was never parsed)")
+		fmt.Printf("Tip: Re-fetch it with: llm-web-parser fetch --urls %q\n", listing.URL)
+		return
+	}
+
+	fmt.Println()
+	for _, a := range listing.Artifacts {
+		fmt.Printf("  %-12s %9s  %s  %s  %s\n",
+			a.Type, formatArtifactSize(a.SizeBytes), shortHash(a.ContentHash),
+			a.CreatedAt.Local().Format("2006-01-02 15:04"), a.Path)
+	}
+}
+
+// formatArtifactSize renders a byte count as B, KB or MB.
+func formatArtifactSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// shortHash abbreviates a content hash for display, as git does for commits.
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// BudgetAction reports whether a session fits an LLM context budget and which URLs to
+// include when it does not.
+func BudgetAction(c *cli.Context) error {
+	budget := c.Int("budget")
+	if budget <= 0 {
+		return fmt.Errorf("--budget must be a positive token count, got %d", budget)
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetSessionByID(sessionID); err != nil {
+		return fmt.Errorf("session %d not found: %w", sessionID, err)
+	}
+
+	plan, err := corpus.PlanBudget(database, sessionID, budget, strings.ToLower(c.String("rank")))
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		output, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(output))
+	case "yaml":
+		output, err := yaml.Marshal(plan)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(output))
+	default:
+		printBudgetPlan(c.App.Writer, plan)
+	}
+	return nil
+}
+
+// printBudgetPlan writes the totals, then one row per URL with the suggested ones starred.
+func printBudgetPlan(w io.Writer, plan *corpus.BudgetPlan) {
+	if len(plan.URLs) == 0 {
+		fmt.Fprintf(w, "Session %d has no URLs with token estimates\n", plan.SessionID)
+		return
+	}
+
+	fmt.Fprintf(w, "Session %d: %d estimated tokens across %d URLs, budget %d\n",
+		plan.SessionID, plan.TotalTokens, len(plan.URLs), plan.Budget)
+	if plan.Fits {
+		fmt.Fprintf(w, "Fits: %d tokens to spare\n", plan.Budget-plan.TotalTokens)
+	} else {
+		fmt.Fprintf(w, "Over budget by %d tokens. Suggested (* below, by %s): %d URLs, %d tokens\n",
+			plan.TotalTokens-plan.Budget, plan.Rank, plan.SelectedCount, plan.SelectedTokens)
+	}
+	if plan.Unestimated > 0 {
+		fmt.Fprintf(w, "%d URLs have no token estimate (failed or not fetched) and are left out\n", plan.Unestimated)
+	}
+
+	fmt.Fprintf(w, "\n  %-6s %8s %8s  %s\n", "ID", "TOKENS", "SCORE", "URL")
+	for _, u := range plan.URLs {
+		mark := " "
+		if u.Included {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %-6d %8d %8.2f  %s\n", mark, u.URLID, u.Tokens, u.Score, u.URL)
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ChangedOutput is the output of db changed.
+type ChangedOutput struct {
+	SinceSession int64        `json:"since_session" yaml:"since_session"`
+	Compared     int          `json:"compared" yaml:"compared"` // URLs hashed in the since session and fetched again later
+	Changed      []ChangedURL `json:"changed" yaml:"changed"`
+}
+
+// ChangedURL is a URL whose content hash differs from the one recorded in the since session.
+type ChangedURL struct {
+	URLID         int64  `json:"url_id" yaml:"url_id"`
+	URL           string `json:"url" yaml:"url"`
+	LatestSession int64  `json:"latest_session" yaml:"latest_session"`
+	SinceHash     string `json:"since_hash" yaml:"since_hash"`
+	LatestHash    string `json:"latest_hash" yaml:"latest_hash"`
+}
+
+// ChangedAction lists URLs whose content hash in their latest session differs from the
+// hash recorded in the --since session, for change monitoring without full diffs.
+func ChangedAction(c *cli.Context) error {
+	sinceID := int64(c.Int("since"))
+	if sinceID <= 0 {
+		return fmt.Errorf("--since must be a session ID > 0")
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	if _, err := database.GetSessionByID(sinceID); err != nil {
+		return fmt.Errorf("session %d not found: %w", sinceID, err)
+	}
+	comparisons, err := database.CompareContentHashes(sinceID)
+	if err != nil {
+		return err
+	}
+
+	output := ChangedOutput{SinceSession: sinceID, Compared: len(comparisons), Changed: []ChangedURL{}}
+	for _, cmp := range comparisons {
+		if !cmp.Changed() {
+			continue
+		}
+		output.Changed = append(output.Changed, ChangedURL{
+			URLID:         cmp.URLID,
+			URL:           cmp.URL,
+			LatestSession: cmp.LatestSession,
+			SinceHash:     cmp.SinceHash,
+			LatestHash:    cmp.LatestHash,
+		})
+	}
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(data))
+	default:
+		printChanged(c.App.Writer, output)
+	}
+	return nil
+}
+
+// printChanged writes the change count and one line per changed URL.
+func printChanged(w io.Writer, output ChangedOutput) {
+	if output.Compared == 0 {
+		fmt.Fprintf(w, "No URLs from session %d have been fetched again since (or it recorded no content hashes)\n", output.SinceSession)
+		return
+	}
+
+	fmt.Fprintf(w, "Since session %d: %d of %d re-fetched URLs changed\n", output.SinceSession, len(output.Changed), output.Compared)
+	for _, changed := range output.Changed {
+		fmt.Fprintf(w, "  [%d] %s (session %d)\n", changed.URLID, changed.URL, changed.LatestSession)
+	}
+}
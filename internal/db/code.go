@@ -0,0 +1,76 @@
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/urfave/cli/v2"
+)
+
+// CodeAction writes every code block extracted from a session's URLs, with its language,
+// source URL and section heading, as JSON Lines, a JSON array or CSV.
+func CodeAction(c *cli.Context) error {
+	format := strings.ToLower(c.String("format"))
+	if format != "jsonl" && format != "json" && format != "csv" {
+		return fmt.Errorf("unknown --format %q (use jsonl, json or csv)", c.String("format"))
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetSessionByID(sessionID); err != nil {
+		return fmt.Errorf("session %d not found: %w", sessionID, err)
+	}
+
+	entries, err := corpus.ExportCodeBlocks(database, artifact_manager.NewFileStore(artifact_manager.DefaultBaseDir), sessionID, corpus.CodeOptions{
+		Language: c.String("lang"),
+		MinLines: c.Int("min-lines"),
+	})
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return writeKeywordsJSON(c.App.Writer, entries)
+	case "csv":
+		return writeCodeCSV(c.App.Writer, entries)
+	}
+	encoder := json.NewEncoder(c.App.Writer)
+	for _, e := range entries {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("failed to write JSON line: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeCodeCSV writes one row per code block with a header; the code keeps its newlines.
+func writeCodeCSV(w io.Writer, entries []corpus.CodeEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"url_id", "url", "language", "lines", "context", "source", "code"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, e := range entries {
+		record := []string{strconv.FormatInt(e.URLID, 10), e.URL, e.Language, strconv.Itoa(e.Lines), e.Context, e.Source, e.Code}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -94,10 +94,9 @@ func filterOutline(page *models.Page, urlID int64) string {
 		// Cheap mode: extract headings from flat array
 		for _, block := range page.FlatContent {
 			// Check if block is a heading (h1-h6)
-			if len(block.Type) == 2 && block.Type[0] == 'h' && block.Type[1] >= '1' && block.Type[1] <= '6' {
-				level := int(block.Type[1] - '0')
+			if level := models.HeadingLevel(block.Type); level > 0 {
 				indent := strings.Repeat("  ", level-1)
-				headingsSB.WriteString(fmt.Sprintf("%s- %s (%s)\n", indent, block.Text, block.Type))
+				headingsSB.WriteString(fmt.Sprintf("%s- %s (%s) [%s]\n", indent, block.Text, block.Type, block.ID))
 				headingCount++
 			}
 		}
@@ -108,7 +107,7 @@ func filterOutline(page *models.Page, urlID int64) string {
 			for _, section := range sections {
 				if section.Heading != nil && section.Heading.Text != "" {
 					indent := strings.Repeat("  ", section.Level-1)
-					headingsSB.WriteString(fmt.Sprintf("%s- %s (%s)\n", indent, section.Heading.Text, section.Heading.Type))
+					headingsSB.WriteString(fmt.Sprintf("%s- %s (%s) [%s]\n", indent, section.Heading.Text, section.Heading.Type, section.ID))
 					headingCount++
 				}
 				// Recurse into children
@@ -139,6 +138,9 @@ func filterOutline(page *models.Page, urlID int64) string {
 		sb.WriteString("  llm-web-parser db show --only=li <id>           # Show list items only\n")
 	}
 	sb.WriteString("  llm-web-parser db show --grep \"keyword\" <id>    # Search for keyword\n")
+	if headingCount > 0 {
+		sb.WriteString("  llm-web-parser db show --section=<section> <id> # Show one section [id in brackets]\n")
+	}
 	sb.WriteString("  llm-web-parser db show --format json <id>       # Output as JSON for jq\n")
 
 	return sb.String()
@@ -233,6 +235,114 @@ func filterByType(page *models.Page, types string) (*models.Page, error) {
 	return filtered, nil
 }
 
+// filterBySection narrows a page to a single section and its children.
+// Full-parse pages are matched on section ID ("section-3", or just "3").
+// Flat pages have no sections, so the ID names a heading block ("block-7") and
+// the section runs until the next heading of the same or higher level.
+func filterBySection(page *models.Page, sectionID string) (*models.Page, error) {
+	sectionID = strings.TrimSpace(sectionID)
+	if sectionID == "" {
+		return page, nil
+	}
+
+	filtered := &models.Page{
+		URL:      page.URL,
+		Title:    page.Title,
+		Metadata: page.Metadata,
+	}
+
+	if len(page.FlatContent) > 0 {
+		blockID := sectionID
+		if !strings.HasPrefix(blockID, "block-") {
+			blockID = "block-" + blockID
+		}
+
+		start := -1
+		for i, block := range page.FlatContent {
+			if block.ID == blockID {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return nil, fmt.Errorf("section %q not found (use --outline to list headings)", sectionID)
+		}
+
+		level := models.HeadingLevel(page.FlatContent[start].Type)
+		if level == 0 {
+			return nil, fmt.Errorf("block %q is not a heading (type %s)", blockID, page.FlatContent[start].Type)
+		}
+
+		end := len(page.FlatContent)
+		for i := start + 1; i < len(page.FlatContent); i++ {
+			if l := models.HeadingLevel(page.FlatContent[i].Type); l > 0 && l <= level {
+				end = i
+				break
+			}
+		}
+		filtered.FlatContent = page.FlatContent[start:end]
+		return filtered, nil
+	}
+
+	if !strings.HasPrefix(sectionID, "section-") {
+		sectionID = "section-" + sectionID
+	}
+
+	var findSection func(sections []models.Section) *models.Section
+	findSection = func(sections []models.Section) *models.Section {
+		for i := range sections {
+			if sections[i].ID == sectionID {
+				return &sections[i]
+			}
+			if found := findSection(sections[i].Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+
+	section := findSection(page.Content)
+	if section == nil {
+		return nil, fmt.Errorf("section %q not found (use --outline to list headings)", sectionID)
+	}
+	filtered.Content = []models.Section{*section}
+	return filtered, nil
+}
+
+// filterByLanguage keeps the sections detected in lang (fetch --detect-section-lang).
+// A section in another language is dropped, but any children in lang take its place.
+func filterByLanguage(page *models.Page, lang string) (*models.Page, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return page, nil
+	}
+	if page.Metadata.LanguageDistribution == nil {
+		return nil, fmt.Errorf("no section languages stored for this page (re-fetch with --features full-parse --detect-section-lang)")
+	}
+
+	var keep func(sections []models.Section) []models.Section
+	keep = func(sections []models.Section) []models.Section {
+		var kept []models.Section
+		for _, s := range sections {
+			children := keep(s.Children)
+			if s.Language == lang {
+				s.Children = children
+				kept = append(kept, s)
+				continue
+			}
+			kept = append(kept, children...)
+		}
+		return kept
+	}
+
+	return &models.Page{
+		URL:      page.URL,
+		Title:    page.Title,
+		Metadata: page.Metadata,
+		Content:  keep(page.Content),
+	}, nil
+}
+
 // filterByGrep searches for a pattern in ContentBlocks and includes context.
 func filterByGrep(page *models.Page, pattern string, context int) (*models.Page, error) {
 	if pattern == "" {
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/urfave/cli/v2"
+)
+
+// ExportKeywordsAction writes the stored keyword counts of a session's URLs matching
+// --filter, as sparse url_id,keyword,count rows or (--layout matrix) a dense URL x
+// keyword matrix, for analysis outside the tool.
+func ExportKeywordsAction(c *cli.Context) error {
+	layout := strings.ToLower(c.String("layout"))
+	if layout != "long" && layout != "matrix" {
+		return fmt.Errorf("unknown --layout %q (use long or matrix)", c.String("layout"))
+	}
+	format := strings.ToLower(c.String("format"))
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown --format %q (use csv or json)", c.String("format"))
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetSessionByID(sessionID); err != nil {
+		return fmt.Errorf("session %d not found: %w", sessionID, err)
+	}
+
+	entries, err := corpus.ExportKeywords(database, c.String("filter"), sessionID)
+	if err != nil {
+		return err
+	}
+
+	if layout == "matrix" {
+		matrix := corpus.BuildKeywordMatrix(entries, c.Int("vocab-size"))
+		if format == "json" {
+			return writeKeywordsJSON(c.App.Writer, matrix)
+		}
+		return writeKeywordMatrixCSV(c.App.Writer, matrix)
+	}
+	if format == "json" {
+		return writeKeywordsJSON(c.App.Writer, entries)
+	}
+	return writeKeywordEntriesCSV(c.App.Writer, entries)
+}
+
+func writeKeywordsJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// writeKeywordEntriesCSV writes url_id,keyword,count rows with a header.
+func writeKeywordEntriesCSV(w io.Writer, entries []corpus.KeywordEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"url_id", "keyword", "count"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, e := range entries {
+		if err := writer.Write([]string{strconv.FormatInt(e.URLID, 10), e.Keyword, strconv.Itoa(e.Count)}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeKeywordMatrixCSV writes a url_id column followed by one column per vocabulary keyword.
+func writeKeywordMatrixCSV(w io.Writer, matrix corpus.KeywordMatrix) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"url_id"}, matrix.Vocabulary...)); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for i, urlID := range matrix.URLIDs {
+		record := make([]string, 0, len(matrix.Vocabulary)+1)
+		record = append(record, strconv.FormatInt(urlID, 10))
+		for _, count := range matrix.Counts[i] {
+			record = append(record, strconv.Itoa(count))
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,220 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// LinkGraph is the inter-page link graph for a session.
+type LinkGraph struct {
+	SessionID     int64      `json:"session_id" yaml:"session_id"`
+	NodeCount     int        `json:"node_count" yaml:"node_count"`
+	EdgeCount     int        `json:"edge_count" yaml:"edge_count"`
+	ExternalLinks int        `json:"external_links" yaml:"external_links"`
+	Nodes         []LinkNode `json:"nodes" yaml:"nodes"`
+	Edges         []LinkEdge `json:"edges" yaml:"edges"`
+}
+
+// LinkNode is a fetched URL in the link graph.
+type LinkNode struct {
+	URLID         int64  `json:"url_id" yaml:"url_id"`
+	URL           string `json:"url" yaml:"url"`
+	OutDegree     int    `json:"out_degree" yaml:"out_degree"`
+	InDegree      int    `json:"in_degree" yaml:"in_degree"`
+	ExternalLinks int    `json:"external_links" yaml:"external_links"` // Links to URLs not in the database
+	Parsed        bool   `json:"parsed" yaml:"parsed"`
+}
+
+// LinkEdge is a link from one known URL to another.
+type LinkEdge struct {
+	From  int64 `json:"from" yaml:"from"`
+	To    int64 `json:"to" yaml:"to"`
+	Count int   `json:"count" yaml:"count"` // Number of anchors pointing at the target
+}
+
+// LinksAction exports the link graph between URLs in a session.
+func LinksAction(c *cli.Context) error {
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+
+	sessionURLs, err := database.GetSessionURLs(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session URLs: %w", err)
+	}
+
+	if len(sessionURLs) == 0 {
+		fmt.Printf("No URLs found for session %d\n", sessionID)
+		return nil
+	}
+
+	canonicalIndex, err := database.GetCanonicalURLIndex()
+	if err != nil {
+		return err
+	}
+
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, 0)
+	if err != nil {
+		return fmt.Errorf("failed to initialize artifact manager: %w", err)
+	}
+
+	graph := buildLinkGraph(sessionID, sessionURLs, canonicalIndex, func(urlID int64) (*models.Page, bool) {
+		page, found, err := manager.GetParsedPageByID(urlID)
+		if err != nil || !found {
+			return nil, false
+		}
+		return page, true
+	})
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		output, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(output))
+	case "yaml":
+		output, err := yaml.Marshal(graph)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Print(string(output))
+	default:
+		printLinkGraph(graph)
+	}
+
+	return nil
+}
+
+// buildLinkGraph resolves every link on each session page against the known canonical URLs.
+// loadPage returns the parsed page for a URL ID, or false if it has not been parsed.
+func buildLinkGraph(sessionID int64, sessionURLs []dbpkg.URLInfo, canonicalIndex map[string]int64, loadPage func(int64) (*models.Page, bool)) *LinkGraph {
+	graph := &LinkGraph{
+		SessionID: sessionID,
+		Nodes:     make([]LinkNode, 0, len(sessionURLs)),
+		Edges:     []LinkEdge{},
+	}
+
+	nodeIndex := make(map[int64]int, len(sessionURLs))
+	for _, u := range sessionURLs {
+		nodeIndex[u.URLID] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, LinkNode{URLID: u.URLID, URL: u.OriginalURL})
+	}
+
+	edgeCounts := make(map[[2]int64]int)
+	for i := range graph.Nodes {
+		node := &graph.Nodes[i]
+
+		page, ok := loadPage(node.URLID)
+		if !ok {
+			continue
+		}
+		node.Parsed = true
+
+		base, err := url.Parse(node.URL)
+		if err != nil {
+			continue
+		}
+
+		for _, block := range page.AllTextBlocks() {
+			for _, link := range block.Links {
+				target, ok := resolveLinkTarget(base, link.Href)
+				if !ok {
+					continue
+				}
+
+				targetID, known := canonicalIndex[dbpkg.CanonicalURL(target)]
+				if !known {
+					node.ExternalLinks++
+					graph.ExternalLinks++
+					continue
+				}
+
+				// Same-page anchors are not edges
+				if targetID == node.URLID {
+					continue
+				}
+
+				edgeCounts[[2]int64{node.URLID, targetID}]++
+			}
+		}
+	}
+
+	for key, count := range edgeCounts {
+		graph.Edges = append(graph.Edges, LinkEdge{From: key[0], To: key[1], Count: count})
+		graph.Nodes[nodeIndex[key[0]]].OutDegree++
+		if idx, ok := nodeIndex[key[1]]; ok {
+			graph.Nodes[idx].InDegree++
+		}
+	}
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+
+	graph.NodeCount = len(graph.Nodes)
+	graph.EdgeCount = len(graph.Edges)
+
+	return graph
+}
+
+// resolveLinkTarget resolves an href against the page URL, skipping non-HTTP schemes.
+func resolveLinkTarget(base *url.URL, href string) (*url.URL, bool) {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return nil, false
+	}
+
+	target := base.ResolveReference(ref)
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, false
+	}
+
+	return target, true
+}
+
+// printLinkGraph prints the link graph in compact text format.
+func printLinkGraph(graph *LinkGraph) {
+	fmt.Printf("Session: %d\n", graph.SessionID)
+	fmt.Printf("%d URLs, %d edges, %d external links\n\n", graph.NodeCount, graph.EdgeCount, graph.ExternalLinks)
+
+	for _, node := range graph.Nodes {
+		if !node.Parsed {
+			fmt.Printf(" #%-3d  (not parsed)  %s\n", node.URLID, node.URL)
+			continue
+		}
+		fmt.Printf(" #%-3d  in:%-3d out:%-3d ext:%-4d  %s\n",
+			node.URLID, node.InDegree, node.OutDegree, node.ExternalLinks, node.URL)
+	}
+
+	if len(graph.Edges) > 0 {
+		fmt.Println()
+		fmt.Println("Edges:")
+		for _, edge := range graph.Edges {
+			fmt.Printf("  #%d → #%d (%d)\n", edge.From, edge.To, edge.Count)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Tip: Use --format json or --format yaml for machine-readable output")
+}
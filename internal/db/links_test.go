@@ -0,0 +1,73 @@
+package db
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestBuildLinkGraph(t *testing.T) {
+	key := func(raw string) string {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) error = %v", raw, err)
+		}
+		return dbpkg.CanonicalURL(parsed)
+	}
+	// URL 9 is known to the database but was fetched in another session
+	canonicalIndex := map[string]int64{
+		key("https://example.com/a"):     1,
+		key("https://example.com/b"):     2,
+		key("https://example.com/c"):     3,
+		key("https://example.com/other"): 9,
+	}
+	sessionURLs := []dbpkg.URLInfo{
+		{URLID: 1, OriginalURL: "https://example.com/a"},
+		{URLID: 2, OriginalURL: "https://example.com/b"},
+		{URLID: 3, OriginalURL: "https://example.com/c"},
+	}
+	links := func(hrefs ...string) *models.Page {
+		block := models.ContentBlock{Type: "p", Text: "Links."}
+		for _, href := range hrefs {
+			block.Links = append(block.Links, models.Link{Href: href})
+		}
+		return &models.Page{FlatContent: []models.ContentBlock{block}}
+	}
+	pages := map[int64]*models.Page{
+		1: links(
+			"/b",                       // Relative, resolved against the page URL
+			"http://example.com/b#top", // Same key as /b
+			"/a#section",               // Self-link
+			"https://example.com/other",
+			"https://elsewhere.org/",
+			"mailto:someone@example.com",
+		),
+		2: links("/a"),
+		// 3 has not been parsed
+	}
+
+	graph := buildLinkGraph(7, sessionURLs, canonicalIndex, func(urlID int64) (*models.Page, bool) {
+		page, ok := pages[urlID]
+		return page, ok
+	})
+
+	wantEdges := []LinkEdge{{From: 1, To: 2, Count: 2}, {From: 1, To: 9, Count: 1}, {From: 2, To: 1, Count: 1}}
+	if !reflect.DeepEqual(graph.Edges, wantEdges) {
+		t.Errorf("Edges = %+v, want %+v", graph.Edges, wantEdges)
+	}
+	wantNodes := []LinkNode{
+		{URLID: 1, URL: "https://example.com/a", OutDegree: 2, InDegree: 1, ExternalLinks: 1, Parsed: true},
+		{URLID: 2, URL: "https://example.com/b", OutDegree: 1, InDegree: 1, Parsed: true},
+		{URLID: 3, URL: "https://example.com/c"},
+	}
+	if !reflect.DeepEqual(graph.Nodes, wantNodes) {
+		t.Errorf("Nodes = %+v, want %+v", graph.Nodes, wantNodes)
+	}
+	if graph.SessionID != 7 || graph.NodeCount != 3 || graph.EdgeCount != 3 || graph.ExternalLinks != 1 {
+		t.Errorf("graph totals = session %d, %d nodes, %d edges, %d external; want 7, 3, 3, 1",
+			graph.SessionID, graph.NodeCount, graph.EdgeCount, graph.ExternalLinks)
+	}
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"gopkg.in/yaml.v3"
+)
+
+// extractionFiles are the per-URL extraction artifacts fetch may write next to
+// generic.yaml, in the order db get --file=extractions lists them.
+var extractionFiles = []string{"academic.yaml", "docs.yaml", "wiki.yaml", "glossary.yaml", "social.yaml", "links.yaml", "images.yaml"}
+
+// SessionKeywords is keywords.yaml: the session's stored top keywords aggregated across URLs.
+type SessionKeywords struct {
+	SessionID int64                    `yaml:"session_id"`
+	URLCount  int                      `yaml:"url_count"`
+	Keywords  []dbpkg.KeywordFrequency `yaml:"keywords"`
+}
+
+// ExtractionManifest is extractions.yaml: which extraction artifacts exist for each URL.
+type ExtractionManifest struct {
+	SessionID int64            `yaml:"session_id"`
+	URLs      []URLExtractions `yaml:"urls"`
+}
+
+// URLExtractions lists one URL's extraction files under lwp-results/<url_id>/.
+type URLExtractions struct {
+	URLID       int64    `yaml:"url_id"`
+	URL         string   `yaml:"url"`
+	Extractions []string `yaml:"extractions"`
+}
+
+// generateSessionKeywords aggregates the top_keywords stored for the session's URLs.
+func generateSessionKeywords(database *dbpkg.DB, sessionID int64) (interface{}, error) {
+	urls, err := database.GetSessionURLs(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session URLs: %w", err)
+	}
+	keywords, err := database.GetKeywordDocFrequency(sessionID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if keywords == nil {
+		keywords = []dbpkg.KeywordFrequency{}
+	}
+	return SessionKeywords{SessionID: sessionID, URLCount: len(urls), Keywords: keywords}, nil
+}
+
+// generateExtractionManifest checks the artifact store for each session URL's extraction files.
+func generateExtractionManifest(database *dbpkg.DB, sessionID int64) (interface{}, error) {
+	urls, err := database.GetSessionURLs(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session URLs: %w", err)
+	}
+	store := artifact_manager.NewFileStore(artifact_manager.DefaultBaseDir)
+	manifest := ExtractionManifest{SessionID: sessionID, URLs: make([]URLExtractions, 0, len(urls))}
+	for _, u := range urls {
+		entry := URLExtractions{URLID: u.URLID, URL: u.OriginalURL, Extractions: []string{}}
+		for _, name := range extractionFiles {
+			if _, err := store.Stat(artifact_manager.URLArtifactKey(u.URLID, name)); err == nil {
+				entry.Extractions = append(entry.Extractions, name)
+			}
+		}
+		manifest.URLs = append(manifest.URLs, entry)
+	}
+	return manifest, nil
+}
+
+// writeGeneratedSessionFile marshals a generated session file and saves it under the
+// session directory so later reads find it. A failed save is reported but the data is
+// still returned for printing.
+func writeGeneratedSessionFile(path string, v interface{}) ([]byte, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err == nil {
+		err = artifact_manager.WriteFileAtomic(path, data, 0600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save %s: %v\n", path, err)
+	}
+	return data, nil
+}
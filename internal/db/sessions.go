@@ -1,12 +1,16 @@
 package db
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dtnitsch/llm-web-parser/internal/common"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 	"github.com/urfave/cli/v2"
@@ -14,7 +18,7 @@ import (
 )
 
 func SessionsAction(c *cli.Context) error {
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -22,25 +26,38 @@ func SessionsAction(c *cli.Context) error {
 
 	limit := c.Int("limit")
 	verbose := c.Bool("verbose")
+	tag := c.String("tag")
 
-	sessions, err := database.ListSessions(limit)
+	sessions, err := database.ListSessionsByTag(tag, limit)
 	if err != nil {
 		return fmt.Errorf("failed to list sessions: %w", err)
 	}
 
+	// Get active session
+	activeSessionID := getActiveSession()
+
+	switch format := strings.ToLower(c.String("format")); format {
+	case "json", "yaml":
+		return printSessionListing(sessions, activeSessionID, format)
+	case "", "text":
+	default:
+		return fmt.Errorf("unknown format: %s (use: text, json, or yaml)", format)
+	}
+
 	if len(sessions) == 0 {
+		if tag != "" {
+			fmt.Printf("No sessions tagged %q\n", tag)
+			return nil
+		}
 		fmt.Println("No sessions found")
 		return nil
 	}
 
-	// Get active session
-	activeSessionID := getActiveSession()
-
 	if verbose {
 		// Verbose mode: show aggregated metadata
-		fmt.Printf("%-4s %-12s %-6s %-8s %-45s %-25s %-8s\n",
-			"ID", "Date", "URLs", "Status", "Keywords", "Types", "Code")
-		fmt.Println(strings.Repeat("-", 120))
+		fmt.Printf("%-4s %-12s %-6s %-8s %-45s %-25s %-8s %s\n",
+			"ID", "Date", "URLs", "Status", "Keywords", "Types", "Code", "Tag")
+		fmt.Println(strings.Repeat("-", 132))
 
 		for _, s := range sessions {
 			// Get aggregated metadata for this session
@@ -55,7 +72,7 @@ func SessionsAction(c *cli.Context) error {
 				idStr = fmt.Sprintf("%d*", s.SessionID)
 			}
 
-			fmt.Printf("%-4s %-12s %-6d %-8s %-45s %-25s %-8s\n",
+			fmt.Printf("%-4s %-12s %-6d %-8s %-45s %-25s %-8s %s\n",
 				idStr,
 				s.CreatedAt.Format("2006-01-02"),
 				s.URLCount,
@@ -63,6 +80,7 @@ func SessionsAction(c *cli.Context) error {
 				meta.Keywords,
 				meta.Types,
 				meta.CodePercent,
+				s.Tag,
 			)
 		}
 
@@ -73,9 +91,9 @@ func SessionsAction(c *cli.Context) error {
 		fmt.Println()
 	} else {
 		// Compact mode: original format
-		fmt.Printf("%-4s %-12s %-6s %-8s %-10s\n",
-			"ID", "Date", "URLs", "Status", "Parse")
-		fmt.Println(strings.Repeat("-", 50))
+		fmt.Printf("%-4s %-12s %-6s %-8s %-10s %s\n",
+			"ID", "Date", "URLs", "Status", "Parse", "Tag")
+		fmt.Println(strings.Repeat("-", 60))
 
 		for _, s := range sessions {
 			status := fmt.Sprintf("%d/%d", s.SuccessCount, s.FailedCount)
@@ -86,12 +104,13 @@ func SessionsAction(c *cli.Context) error {
 				idStr = fmt.Sprintf("%d*", s.SessionID)
 			}
 
-			fmt.Printf("%-4s %-12s %-6d %-8s %-10s\n",
+			fmt.Printf("%-4s %-12s %-6d %-8s %-10s %s\n",
 				idStr,
 				s.CreatedAt.Format("2006-01-02"),
 				s.URLCount,
 				status,
 				s.ParseMode,
+				s.Tag,
 			)
 		}
 
@@ -106,9 +125,86 @@ func SessionsAction(c *cli.Context) error {
 	return nil
 }
 
+// SessionListing is one session in 'db sessions --format json|yaml' output.
+type SessionListing struct {
+	SessionID    int64     `json:"session_id" yaml:"session_id"`
+	Created      time.Time `json:"created" yaml:"created"`
+	URLCount     int       `json:"url_count" yaml:"url_count"`
+	SuccessCount int       `json:"success_count" yaml:"success_count"`
+	FailedCount  int       `json:"failed_count" yaml:"failed_count"`
+	Features     string    `json:"features" yaml:"features"`
+	ParseMode    string    `json:"parse_mode" yaml:"parse_mode"`
+	Tag          string    `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Active       bool      `json:"active,omitempty" yaml:"active,omitempty"`
+}
+
+// printSessionListing writes sessions as a JSON or YAML list.
+func printSessionListing(sessions []dbpkg.Session, activeSessionID int64, format string) error {
+	listing := make([]SessionListing, 0, len(sessions))
+	for _, s := range sessions {
+		listing = append(listing, SessionListing{
+			SessionID:    s.SessionID,
+			Created:      s.CreatedAt,
+			URLCount:     s.URLCount,
+			SuccessCount: s.SuccessCount,
+			FailedCount:  s.FailedCount,
+			Features:     s.Features,
+			ParseMode:    s.ParseMode,
+			Tag:          s.Tag,
+			Active:       s.SessionID == activeSessionID,
+		})
+	}
+
+	if format == "yaml" {
+		data, err := yaml.Marshal(listing)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(listing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// TagAction labels a session, or clears its label when the tag is empty.
+func TagAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: llm-web-parser db tag <session_id> <tag>")
+	}
+
+	sessionID, err := strconv.ParseInt(c.Args().Get(0), 10, 64)
+	if err != nil || sessionID <= 0 {
+		return fmt.Errorf("invalid session ID: %s", c.Args().Get(0))
+	}
+	tag := strings.TrimSpace(c.Args().Get(1))
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	if err := database.SetSessionTag(sessionID, tag); err != nil {
+		return err
+	}
+
+	if tag == "" {
+		fmt.Printf("Session %d: tag removed\n", sessionID)
+	} else {
+		fmt.Printf("Session %d tagged %q\n", sessionID, tag)
+	}
+	return nil
+}
+
 // sessionAction shows details for a specific session
 func SessionAction(c *cli.Context) error {
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -146,6 +242,12 @@ func SessionAction(c *cli.Context) error {
 		session.URLCount, session.SuccessCount, session.FailedCount)
 	fmt.Printf("Features:    %s\n", session.Features)
 	fmt.Printf("Parse Mode:  %s\n", session.ParseMode)
+	if session.Tag != "" {
+		fmt.Printf("Tag:         %s\n", session.Tag)
+	}
+	if !session.RawStored {
+		fmt.Printf("Raw HTML:    not stored (--no-store-raw; db raw and refresh unavailable)\n")
+	}
 
 	// Print URLs
 	fmt.Printf("\nURLs (%d):\n", len(urls))
@@ -181,7 +283,7 @@ func SessionAction(c *cli.Context) error {
 
 // getSessionAction retrieves and prints session content files
 func GetSessionAction(c *cli.Context) error {
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -199,8 +301,10 @@ func GetSessionAction(c *cli.Context) error {
 	}
 
 	// Determine which file to read
+	// keywords and extractions are built from the database when the session lacks them
 	fileType := strings.ToLower(c.String("file"))
 	var fileName string
+	var generate func(*dbpkg.DB, int64) (interface{}, error)
 	switch fileType {
 	case "index":
 		fileName = "summary-index.yaml"
@@ -208,8 +312,12 @@ func GetSessionAction(c *cli.Context) error {
 		fileName = "summary-details.yaml"
 	case "failed":
 		fileName = "failed-urls.yaml"
+	case "keywords":
+		fileName, generate = "keywords.yaml", generateSessionKeywords
+	case "extractions":
+		fileName, generate = "extractions.yaml", generateExtractionManifest
 	default:
-		return fmt.Errorf("unknown file type: %s (use: index, details, or failed)", fileType)
+		return fmt.Errorf("unknown file type: %s (use: index, details, failed, keywords, or extractions)", fileType)
 	}
 
 	// Build full path (session_dir is relative to output dir)
@@ -218,6 +326,15 @@ func GetSessionAction(c *cli.Context) error {
 
 	// Read and print file
 	data, err := os.ReadFile(filepath.Clean(filePath))
+	if os.IsNotExist(err) && generate != nil {
+		var content interface{}
+		if content, err = generate(database, sessionID); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", fileName, err)
+		}
+		if data, err = writeGeneratedSessionFile(filePath, content); err != nil {
+			return err
+		}
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("file not found: %s\nSession directory: %s", fileName, session.SessionDir)
@@ -234,43 +351,70 @@ func GetSessionAction(c *cli.Context) error {
 
 // querySessionsAction queries sessions with filters
 func QuerySessionsAction(c *cli.Context) error {
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 	defer database.Close()
 
-	todayOnly := c.Bool("today")
-	failedOnly := c.Bool("failed")
-	urlPattern := c.String("url")
+	filter := dbpkg.SessionFilter{
+		TodayOnly:   c.Bool("today"),
+		FailedOnly:  c.Bool("failed"),
+		URLPattern:  c.String("url"),
+		Tag:         c.String("tag"),
+		ContentType: strings.ToLower(c.String("content-type")),
+	}
+	now := time.Now()
+	if since := c.String("since"); since != "" {
+		if filter.Since, err = parseSessionTime(since, now, false); err != nil {
+			return fmt.Errorf("--since: %w", err)
+		}
+	}
+	if until := c.String("until"); until != "" {
+		if filter.Until, err = parseSessionTime(until, now, true); err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+	}
 
-	sessions, err := database.QuerySessions(todayOnly, failedOnly, urlPattern)
+	sessions, err := database.QuerySessions(filter)
 	if err != nil {
 		return fmt.Errorf("failed to query sessions: %w", err)
 	}
 
 	if len(sessions) == 0 {
 		fmt.Println("No sessions found matching filters")
-		if todayOnly {
+		if filter.TodayOnly {
 			fmt.Println("  - Filter: today only")
 		}
-		if failedOnly {
+		if filter.FailedOnly {
 			fmt.Println("  - Filter: with failures")
 		}
-		if urlPattern != "" {
-			fmt.Printf("  - Filter: URL pattern '%s'\n", urlPattern)
+		if filter.URLPattern != "" {
+			fmt.Printf("  - Filter: URL pattern '%s'\n", filter.URLPattern)
+		}
+		if filter.Tag != "" {
+			fmt.Printf("  - Filter: tag '%s'\n", filter.Tag)
+		}
+		if filter.ContentType != "" {
+			fmt.Printf("  - Filter: content type '%s'\n", filter.ContentType)
+		}
+		if !filter.Since.IsZero() {
+			fmt.Printf("  - Filter: created since %s\n", filter.Since.Format("2006-01-02 15:04"))
+		}
+		if !filter.Until.IsZero() {
+			fmt.Printf("  - Filter: created before %s\n", filter.Until.Format("2006-01-02 15:04"))
 		}
 		return nil
 	}
 
 	// Print table header
-	fmt.Printf("%-6s %-20s %-8s %-8s %-8s %-15s %-30s\n",
-		"ID", "Created", "URLs", "Success", "Failed", "Parse Mode", "Session Dir")
-	fmt.Println(strings.Repeat("-", 120))
+	fmt.Printf("%-6s %-20s %-8s %-8s %-8s %-15s %-30s %s\n",
+		"ID", "Created", "URLs", "Success", "Failed", "Parse Mode", "Session Dir", "Tag")
+	fmt.Println(strings.Repeat("-", 130))
 
 	// Print each session
 	for _, s := range sessions {
-		fmt.Printf("%-6d %-20s %-8d %-8d %-8d %-15s %-30s\n",
+		fmt.Printf("%-6d %-20s %-8d %-8d %-8d %-15s %-30s %s\n",
 			s.SessionID,
 			s.CreatedAt.Format("2006-01-02 15:04:05"),
 			s.URLCount,
@@ -278,6 +422,7 @@ func QuerySessionsAction(c *cli.Context) error {
 			s.FailedCount,
 			s.ParseMode,
 			s.SessionDir,
+			s.Tag,
 		)
 	}
 
@@ -424,7 +569,7 @@ func setActiveSession(sessionID int64) error {
 	}
 
 	configPath := ".lwp/config"
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := artifact_manager.WriteFileAtomic(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
@@ -465,7 +610,7 @@ func UseAction(c *cli.Context) error {
 			return nil
 		}
 
-		database, err := dbpkg.Open()
+		database, err := common.OpenDB(c)
 		if err != nil {
 			return fmt.Errorf("failed to open database: %w", err)
 		}
@@ -492,7 +637,7 @@ func UseAction(c *cli.Context) error {
 	}
 
 	// Open database
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
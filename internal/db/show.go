@@ -2,9 +2,11 @@ package db
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/dtnitsch/llm-web-parser/internal/common"
 	"github.com/dtnitsch/llm-web-parser/models"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
@@ -20,7 +22,7 @@ func ShowAction(c *cli.Context) error {
 		return nil
 	}
 
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -38,6 +40,13 @@ func ShowAction(c *cli.Context) error {
 
 	// Check if argument contains comma (batch mode)
 	if strings.Contains(arg, ",") {
+		if c.String("section") != "" {
+			return fmt.Errorf("--section requires a single URL ID (section IDs are per page)")
+		}
+		if c.String("lang") != "" {
+			return fmt.Errorf("--lang requires a single URL ID")
+		}
+
 		ids := strings.Split(arg, ",")
 		results := make([]string, 0, len(ids))
 
@@ -48,13 +57,28 @@ func ShowAction(c *cli.Context) error {
 				return fmt.Errorf("failed to resolve ID %s: %w", id, err)
 			}
 
-			data, found, err := manager.GetParsedJSONByID(urlID)
+			data, err := readParsedYAML(manager, database, urlID, c.Bool("quarantine"))
 			if err != nil {
-				return fmt.Errorf("failed to read parsed content for URL ID %d: %w", urlID, err)
+				return err
 			}
-			if !found {
-				url, _ := database.GetURLByID(urlID)
-				return fmt.Errorf("parsed content not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet. Try:\n  llm-web-parser fetch --urls \"%s\"", urlID, url, url)
+
+			// Storage is YAML; convert each page so the batch is a valid JSON array
+			if outputFormat == "json" {
+				var page models.Page
+				if err := yaml.Unmarshal(data, &page); err != nil {
+					return fmt.Errorf("failed to parse YAML for URL ID %d: %w", urlID, err)
+				}
+				jsonBytes, err := json.MarshalIndent(pageJSON{
+					URLID:       urlID,
+					URL:         page.URL,
+					Title:       page.Title,
+					Content:     page.Content,
+					FlatContent: page.FlatContent,
+				}, "  ", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal content as JSON: %w", err)
+				}
+				data = jsonBytes
 			}
 
 			results = append(results, string(data))
@@ -68,7 +92,7 @@ func ShowAction(c *cli.Context) error {
 				if i > 0 {
 					fmt.Println(",")
 				}
-				fmt.Print(result)
+				fmt.Print("  " + result)
 			}
 			fmt.Println("\n]")
 		} else {
@@ -90,13 +114,9 @@ func ShowAction(c *cli.Context) error {
 		return err
 	}
 
-	data, found, err := manager.GetParsedJSONByID(urlID)
+	data, err := readParsedYAML(manager, database, urlID, c.Bool("quarantine"))
 	if err != nil {
-		return fmt.Errorf("failed to read parsed content: %w", err)
-	}
-	if !found {
-		url, _ := database.GetURLByID(urlID)
-		return fmt.Errorf("parsed content not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet. Try:\n  lwp fetch --urls \"%s\"", urlID, url, url)
+		return err
 	}
 
 	// Always parse YAML to apply compact marshaling and enable filters
@@ -114,6 +134,22 @@ func ShowAction(c *cli.Context) error {
 	showMetadataFull := c.Bool("metadata-full")
 	// outputFormat already declared above
 
+	// Narrow to a single section before any other filter
+	if sectionID := c.String("section"); sectionID != "" {
+		filtered, err := filterBySection(&page, sectionID)
+		if err != nil {
+			return err
+		}
+		page = *filtered
+	}
+	if lang := c.String("lang"); lang != "" {
+		filtered, err := filterByLanguage(&page, lang)
+		if err != nil {
+			return err
+		}
+		page = *filtered
+	}
+
 	// Apply outline filter (special output)
 	if outlineMode {
 		fmt.Print(filterOutline(&page, urlID))
@@ -181,6 +217,15 @@ func ShowAction(c *cli.Context) error {
 	}
 	// else: no metadata (metadataToShow stays nil)
 
+	// --verbose adds which parser version and mode produced the parsed page, and when
+	var provenance map[string]string
+	if c.Bool("verbose") {
+		provenance, err = database.GetParsedArtifactMetadata(urlID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Re-marshal based on requested format
 	var output []byte
 	if outputFormat == "markdown" {
@@ -194,8 +239,8 @@ func ShowAction(c *cli.Context) error {
 		fmt.Print(string(output))
 		return nil
 	} else if outputFormat == "json" {
-		if metadataToShow != nil {
-			// With metadata
+		if metadataToShow != nil || provenance != nil {
+			// With metadata and/or provenance
 			outputStruct := struct {
 				URLID       int64                  `json:"url_id"`
 				URL         string                 `json:"url"`
@@ -203,6 +248,7 @@ func ShowAction(c *cli.Context) error {
 				Content     []models.Section       `json:"content,omitempty"`
 				FlatContent []models.ContentBlock  `json:"flat_content,omitempty"`
 				Metadata    interface{}            `json:"metadata,omitempty"`
+				Provenance  map[string]string      `json:"provenance,omitempty"`
 			}{
 				URLID:       urlID,
 				URL:         page.URL,
@@ -210,24 +256,18 @@ func ShowAction(c *cli.Context) error {
 				Content:     page.Content,
 				FlatContent: page.FlatContent,
 				Metadata:    metadataToShow,
+				Provenance:  provenance,
 			}
 			output, err = json.MarshalIndent(&outputStruct, "", "  ")
 		} else {
 			// Without metadata
-			outputStruct := struct {
-				URLID       int64                  `json:"url_id"`
-				URL         string                 `json:"url"`
-				Title       string                 `json:"title"`
-				Content     []models.Section       `json:"content,omitempty"`
-				FlatContent []models.ContentBlock  `json:"flat_content,omitempty"`
-			}{
+			output, err = json.MarshalIndent(pageJSON{
 				URLID:       urlID,
 				URL:         page.URL,
 				Title:       page.Title,
 				Content:     page.Content,
 				FlatContent: page.FlatContent,
-			}
-			output, err = json.MarshalIndent(&outputStruct, "", "  ")
+			}, "", "  ")
 		}
 		if err != nil {
 			return fmt.Errorf("failed to marshal content as JSON: %w", err)
@@ -277,6 +317,9 @@ func ShowAction(c *cli.Context) error {
 			fmt.Println("# YAML compact mode: Only non-null/non-default fields shown")
 		}
 		fmt.Printf("# url_id: %d\n", urlID)
+		if provenance != nil {
+			fmt.Println(formatProvenance(provenance))
+		}
 
 		// Show keywords if available
 		if len(page.Metadata.MetaKeywords) > 0 {
@@ -306,6 +349,114 @@ func ShowAction(c *cli.Context) error {
 	return nil
 }
 
+// formatProvenance renders parse provenance as a YAML comment line.
+func formatProvenance(provenance map[string]string) string {
+	if len(provenance) == 0 {
+		return "# parsed_by: unknown (stored before parse provenance was recorded)"
+	}
+	return fmt.Sprintf("# parsed_by: parser %s, %s mode, at %s",
+		provenance["parser_version"], provenance["parse_mode"], provenance["parsed_at"])
+}
+
+// readParsedYAML loads the parsed page for urlID as YAML. It reads the URL-centric
+// lwp-results/{url_id}/generic.yaml written by fetch, then generic.json, then the legacy
+// parsed/ JSON artifact, converting JSON to YAML. A stored page that does not parse is
+// reported as corrupt, and moved aside first with quarantine.
+func readParsedYAML(manager *artifact_manager.Manager, database *dbpkg.DB, urlID int64, quarantine bool) ([]byte, error) {
+	data, found, err := manager.GetParsedJSONByID(urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parsed content for URL ID %d: %w", urlID, err)
+	}
+	if found {
+		path := artifact_manager.Location(manager.Store(), artifact_manager.URLArtifactKey(urlID, "generic.yaml"))
+		if _, err := artifact_manager.DecodeParsedPage(path, data, yaml.Unmarshal); err != nil {
+			return nil, reportCorruptArtifact(err, database, urlID, quarantine)
+		}
+		return data, nil
+	}
+
+	// Pages stored with fetch --parsed-format json have only generic.json
+	page, found, err := manager.GetParsedPageByID(urlID)
+	if err != nil {
+		return nil, reportCorruptArtifact(err, database, urlID, quarantine)
+	}
+	if found {
+		return yaml.Marshal(page)
+	}
+
+	url, _ := database.GetURLByID(urlID)
+	if url != "" {
+		legacy, found, err := manager.GetParsedJSON(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read legacy parsed content for URL ID %d: %w", urlID, err)
+		}
+		if found {
+			var page models.Page
+			if err := json.Unmarshal(legacy, &page); err != nil {
+				return nil, fmt.Errorf("failed to parse legacy JSON for URL ID %d: %w", urlID, err)
+			}
+			return yaml.Marshal(&page)
+		}
+	}
+
+	return nil, fmt.Errorf("parsed content not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet. Try:\n  lwp fetch --urls \"%s\"", urlID, url, url)
+}
+
+// reportCorruptArtifact turns a *artifact_manager.CorruptArtifactError into the steps to
+// recover urlID, quarantining the file first when asked. Other errors pass through.
+func reportCorruptArtifact(err error, database *dbpkg.DB, urlID int64, quarantine bool) error {
+	var corrupt *artifact_manager.CorruptArtifactError
+	if !errors.As(err, &corrupt) {
+		return fmt.Errorf("failed to read parsed content for URL ID %d: %w", urlID, err)
+	}
+
+	url, _ := database.GetURLByID(urlID)
+	next := fmt.Sprintf("Move it aside with: lwp db show %d --quarantine", urlID)
+	if quarantine {
+		moved, qerr := artifact_manager.QuarantineArtifact(corrupt.Path)
+		if qerr != nil {
+			next = qerr.Error()
+		} else {
+			next = "Moved it to " + moved
+		}
+	}
+	return fmt.Errorf("%w\n\nThe stored page for URL ID %d is truncated or damaged. %s, then re-fetch it:\n  lwp fetch --force-fetch --urls %q", err, urlID, next, url)
+}
+
+// readRawHTML loads raw HTML for urlID from lwp-results/{url_id}/raw.html,
+// falling back to the legacy raw/ slug-hash layout.
+func readRawHTML(manager *artifact_manager.Manager, database *dbpkg.DB, urlID int64) ([]byte, error) {
+	data, found, err := manager.GetRawHTMLByID(urlID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw HTML for URL ID %d: %w", urlID, err)
+	}
+	if found {
+		return data, nil
+	}
+
+	url, _ := database.GetURLByID(urlID)
+	if url != "" {
+		legacy, found, err := manager.GetRawHTML(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read legacy raw HTML for URL ID %d: %w", urlID, err)
+		}
+		if found {
+			return legacy, nil
+		}
+	}
+
+	return nil, fmt.Errorf("raw HTML not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet, or was fetched with --no-store-raw. Try:\n  lwp fetch --urls \"%s\"", urlID, url, url)
+}
+
+// pageJSON is the JSON shape of 'db show' output without metadata.
+type pageJSON struct {
+	URLID       int64                 `json:"url_id"`
+	URL         string                `json:"url"`
+	Title       string                `json:"title"`
+	Content     []models.Section      `json:"content,omitempty"`
+	FlatContent []models.ContentBlock `json:"flat_content,omitempty"`
+}
+
 // rawAction shows raw HTML for a URL by ID or URL
 func RawAction(c *cli.Context) error {
 	if c.NArg() == 0 {
@@ -315,7 +466,7 @@ func RawAction(c *cli.Context) error {
 		return nil
 	}
 
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -339,13 +490,9 @@ func RawAction(c *cli.Context) error {
 				return fmt.Errorf("failed to resolve ID %s: %w", id, err)
 			}
 
-			data, found, err := manager.GetRawHTMLByID(urlID)
+			data, err := readRawHTML(manager, database, urlID)
 			if err != nil {
-				return fmt.Errorf("failed to read raw HTML for URL ID %d: %w", urlID, err)
-			}
-			if !found {
-				url, _ := database.GetURLByID(urlID)
-				return fmt.Errorf("raw HTML not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet. Try:\n  lwp fetch --urls \"%s\"", urlID, url, url)
+				return err
 			}
 
 			if i > 0 {
@@ -362,13 +509,9 @@ func RawAction(c *cli.Context) error {
 		return err
 	}
 
-	data, found, err := manager.GetRawHTMLByID(urlID)
+	data, err := readRawHTML(manager, database, urlID)
 	if err != nil {
-		return fmt.Errorf("failed to read raw HTML: %w", err)
-	}
-	if !found {
-		url, _ := database.GetURLByID(urlID)
-		return fmt.Errorf("raw HTML not found for URL ID %d (%s)\n\nThis URL may not have been fetched yet. Try:\n  lwp fetch --urls \"%s\"", urlID, url, url)
+		return err
 	}
 
 	fmt.Print(string(data))
@@ -383,7 +526,7 @@ func FindURLAction(c *cli.Context) error {
 		return nil
 	}
 
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfidenceHistogram is the output of db stats --histogram confidence.
+type ConfidenceHistogram struct {
+	SessionID    int64                  `json:"session_id" yaml:"session_id"`
+	Histogram    string                 `json:"histogram" yaml:"histogram"`
+	Total        int                    `json:"total" yaml:"total"`
+	All          []HistogramBucket      `json:"all" yaml:"all"`
+	ContentTypes []ContentTypeHistogram `json:"content_types" yaml:"content_types"`
+}
+
+// ContentTypeHistogram is the confidence distribution of one content type.
+type ContentTypeHistogram struct {
+	ContentType string            `json:"content_type" yaml:"content_type"`
+	Total       int               `json:"total" yaml:"total"`
+	Buckets     []HistogramBucket `json:"buckets" yaml:"buckets"`
+}
+
+// HistogramBucket is a confidence range with its URL count and share of the row's URLs.
+type HistogramBucket struct {
+	Range   string  `json:"range" yaml:"range"`
+	Count   int     `json:"count" yaml:"count"`
+	Percent float64 `json:"percent" yaml:"percent"`
+}
+
+// StatsAction reports session statistics; --histogram confidence buckets each content
+// type's detection_confidence to find classifications worth reviewing.
+func StatsAction(c *cli.Context) error {
+	if histogram := strings.ToLower(c.String("histogram")); histogram != "confidence" {
+		return fmt.Errorf("unknown --histogram %q (supported: confidence)", c.String("histogram"))
+	}
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+	if _, err := database.GetSessionByID(sessionID); err != nil {
+		return fmt.Errorf("session %d not found: %w", sessionID, err)
+	}
+
+	counts, err := database.GetConfidenceHistogram(sessionID)
+	if err != nil {
+		return err
+	}
+	histogram := buildConfidenceHistogram(sessionID, counts)
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		output, err := json.MarshalIndent(histogram, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(output))
+	case "yaml":
+		output, err := yaml.Marshal(histogram)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(output))
+	default:
+		printConfidenceHistogram(c.App.Writer, histogram)
+	}
+	return nil
+}
+
+// buildConfidenceHistogram fills every range for each content type (zeros included, so
+// rows line up) plus an all-types row. The unscored range appears only when non-empty.
+func buildConfidenceHistogram(sessionID int64, counts []dbpkg.ConfidenceBucketCount) ConfidenceHistogram {
+	histogram := ConfidenceHistogram{SessionID: sessionID, Histogram: "confidence", ContentTypes: []ContentTypeHistogram{}}
+
+	byType := make(map[string]map[string]int)
+	allCounts := make(map[string]int)
+	var order []string
+	for _, c := range counts {
+		if byType[c.ContentType] == nil {
+			byType[c.ContentType] = make(map[string]int)
+			order = append(order, c.ContentType)
+		}
+		byType[c.ContentType][c.Bucket] += c.Count
+		allCounts[c.Bucket] += c.Count
+		histogram.Total += c.Count
+	}
+
+	ranges := dbpkg.ConfidenceBuckets
+	if allCounts[dbpkg.UnscoredBucket] > 0 {
+		ranges = append(append([]string{}, ranges...), dbpkg.UnscoredBucket)
+	}
+	histogram.All = histogramBuckets(ranges, allCounts)
+	for _, contentType := range order {
+		total := 0
+		for _, n := range byType[contentType] {
+			total += n
+		}
+		histogram.ContentTypes = append(histogram.ContentTypes, ContentTypeHistogram{
+			ContentType: contentType,
+			Total:       total,
+			Buckets:     histogramBuckets(ranges, byType[contentType]),
+		})
+	}
+	return histogram
+}
+
+// histogramBuckets returns counts for ranges with percentages of their sum, to one decimal.
+func histogramBuckets(ranges []string, counts map[string]int) []HistogramBucket {
+	total := 0
+	for _, r := range ranges {
+		total += counts[r]
+	}
+	buckets := make([]HistogramBucket, 0, len(ranges))
+	for _, r := range ranges {
+		bucket := HistogramBucket{Range: r, Count: counts[r]}
+		if total > 0 {
+			bucket.Percent = math.Round(float64(counts[r])*1000/float64(total)) / 10
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// printConfidenceHistogram writes one row per content type with "count (percent)" cells.
+func printConfidenceHistogram(w io.Writer, histogram ConfidenceHistogram) {
+	if histogram.Total == 0 {
+		fmt.Fprintf(w, "Session %d has no URLs\n", histogram.SessionID)
+		return
+	}
+
+	fmt.Fprintf(w, "Session %d: detection_confidence by content type (%d URLs)\n\n", histogram.SessionID, histogram.Total)
+	fmt.Fprintf(w, "%-16s %6s", "CONTENT TYPE", "TOTAL")
+	for _, b := range histogram.All {
+		fmt.Fprintf(w, " %12s", b.Range)
+	}
+	fmt.Fprintln(w)
+
+	row := func(name string, total int, buckets []HistogramBucket) {
+		fmt.Fprintf(w, "%-16s %6d", name, total)
+		for _, b := range buckets {
+			fmt.Fprintf(w, " %12s", fmt.Sprintf("%d (%.0f%%)", b.Count, b.Percent))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, ct := range histogram.ContentTypes {
+		row(ct.ContentType, ct.Total, ct.Buckets)
+	}
+	row("all", histogram.Total, histogram.All)
+
+	fmt.Fprintf(w, "\nReview low scores: llm-web-parser corpus query --session=%d --filter=\"content_type=<type> AND detection_confidence<4\"\n", histogram.SessionID)
+}
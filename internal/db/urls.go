@@ -2,15 +2,17 @@ package db
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/dtnitsch/llm-web-parser/internal/common"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 	"github.com/urfave/cli/v2"
 )
 
 // urlsAction shows URLs for a session with sanitization tracking and metadata
 func UrlsAction(c *cli.Context) error {
-	database, err := dbpkg.Open()
+	database, err := common.OpenDB(c)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
@@ -32,6 +34,15 @@ func UrlsAction(c *cli.Context) error {
 		return nil
 	}
 
+	sortBy := c.String("sort")
+	switch sortBy {
+	case "":
+	case "date":
+		sortByContentDate(urls)
+	default:
+		return fmt.Errorf("unsupported --sort %q (supported: date)", sortBy)
+	}
+
 	verbose := c.Bool("verbose")
 
 	if verbose {
@@ -46,8 +57,13 @@ func UrlsAction(c *cli.Context) error {
 			if u.HasCodeExamples {
 				codeFlag = "has_code"
 			}
-			fmt.Printf("    %s | %s | conf:%.1f\n",
-				u.ContentType, codeFlag, u.DetectionConfidence)
+			if u.ContentDate != "" {
+				fmt.Printf("    %s | %s | conf:%.1f | %s %s\n",
+					u.ContentType, codeFlag, u.DetectionConfidence, u.ContentDateKind, u.ContentDate)
+			} else {
+				fmt.Printf("    %s | %s | conf:%.1f\n",
+					u.ContentType, codeFlag, u.DetectionConfidence)
+			}
 
 			// Line 3: Keywords (prefer meta keywords, fallback to extracted)
 			if len(u.MetaKeywords) > 0 {
@@ -83,6 +99,15 @@ func UrlsAction(c *cli.Context) error {
 				keywords = u.TopKeywords
 			}
 
+			// Sorting by date shows the date the order comes from
+			if sortBy == "date" {
+				date := "undated"
+				if u.ContentDate != "" {
+					date = u.ContentDate[:min(len(u.ContentDate), len("2006-01-02"))]
+				}
+				fmt.Printf(" %-10s", date)
+			}
+
 			if len(keywords) > 0 {
 				fmt.Printf(" #%-3d  %-10s  %s  →  %s\n",
 					u.URLID,
@@ -100,3 +125,15 @@ func UrlsAction(c *cli.Context) error {
 
 	return nil
 }
+
+// sortByContentDate orders URLs by content date, newest first, with undated URLs last
+// in their session order.
+func sortByContentDate(urls []dbpkg.URLWithMetadata) {
+	sort.SliceStable(urls, func(i, j int) bool {
+		a, b := urls[i].ContentDate, urls[j].ContentDate
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a > b
+	})
+}
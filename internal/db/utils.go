@@ -3,7 +3,9 @@ package db
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 	"github.com/urfave/cli/v2"
@@ -119,3 +121,31 @@ func getActiveSessionFromConfig() int64 {
 
 	return config.ActiveSession
 }
+
+// parseSessionTime reads a --since/--until bound: a date (2006-01-02), an RFC 3339
+// time, or an age back from now such as 7d or 36h. A bare --until date includes that
+// whole day.
+func parseSessionTime(value string, now time.Time, until bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		if until {
+			return t.AddDate(0, 0, 1), nil
+		}
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (use 2006-01-02, an RFC 3339 time, or an age like 7d or 36h)", value)
+}
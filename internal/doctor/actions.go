@@ -0,0 +1,227 @@
+// Package doctor implements the doctor command, which checks that the environment
+// lwp runs in is usable: database, results directory, SQLite features and network.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/urfave/cli/v2"
+)
+
+// Check statuses
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn" // Works, but something needs attention
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// DefaultNetworkURL is fetched by the opt-in network check.
+const DefaultNetworkURL = "https://example.com/"
+
+// Check is the outcome of one self-check.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is what the doctor command prints.
+type Report struct {
+	SchemaVersion         int     `json:"schema_version"`          // Detected in the database (0 = missing or unversioned)
+	ExpectedSchemaVersion int     `json:"expected_schema_version"` // The version this build migrates to
+	SQLiteVersion         string  `json:"sqlite_version,omitempty"`
+	Checks                []Check `json:"checks"`
+	Failed                int     `json:"failed"`
+}
+
+// Options select what RunChecks inspects.
+type Options struct {
+	DBPath         string // Database file; only read, never created
+	ResultsDir     string // Artifact directory whose writability is checked
+	Network        bool   // Also fetch NetworkURL
+	NetworkURL     string
+	NetworkTimeout time.Duration
+}
+
+// RunChecks runs every check in order. A check that fails doesn't stop the rest.
+func RunChecks(opts Options) *Report {
+	report := &Report{ExpectedSchemaVersion: dbpkg.SchemaVersion}
+	add := func(name, status, detail string) {
+		report.Checks = append(report.Checks, Check{Name: name, Status: status, Detail: detail})
+		if status == StatusFail {
+			report.Failed++
+		}
+	}
+
+	status, err := dbpkg.InspectSchema(opts.DBPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		add("database", StatusPass, fmt.Sprintf("%s not created yet; the first fetch creates it", opts.DBPath))
+		add("schema", StatusSkip, "no database")
+	case err != nil:
+		add("database", StatusFail, fmt.Sprintf("cannot open %s: %v", opts.DBPath, err))
+		add("schema", StatusSkip, "no database")
+	default:
+		add("database", StatusPass, fmt.Sprintf("%s opens", opts.DBPath))
+		report.SchemaVersion = status.Version
+		checkStatus, detail := schemaCheck(status)
+		add("schema", checkStatus, detail)
+	}
+
+	dirStatus, detail := checkWritable(opts.ResultsDir)
+	add("results-dir", dirStatus, detail)
+
+	version, fts5, err := dbpkg.SQLiteInfo()
+	switch {
+	case err != nil:
+		add("sqlite-fts5", StatusFail, err.Error())
+	case fts5:
+		report.SQLiteVersion = version
+		add("sqlite-fts5", StatusPass, fmt.Sprintf("SQLite %s with FTS5", version))
+	default:
+		report.SQLiteVersion = version
+		add("sqlite-fts5", StatusWarn, fmt.Sprintf("SQLite %s was built without FTS5; full-text search is unavailable", version))
+	}
+
+	if opts.Network {
+		netStatus, detail := checkNetwork(opts.NetworkURL, opts.NetworkTimeout)
+		add("network", netStatus, detail)
+	} else {
+		add("network", StatusSkip, "not requested (use --network)")
+	}
+	return report
+}
+
+// schemaCheck compares the database's schema with this build's. An older schema is
+// only a warning: the next command that opens the database migrates it.
+func schemaCheck(s *dbpkg.SchemaStatus) (string, string) {
+	switch {
+	case !s.Initialized:
+		return StatusWarn, "database has no tables; the next command initializes it"
+	case s.Version > dbpkg.SchemaVersion:
+		return StatusFail, fmt.Sprintf("version %d is newer than this build's %d; upgrade llm-web-parser", s.Version, dbpkg.SchemaVersion)
+	case len(s.MissingColumns) > 0:
+		return StatusWarn, fmt.Sprintf("version %d, missing %s; the next command migrates it to %d",
+			s.Version, strings.Join(s.MissingColumns, ", "), dbpkg.SchemaVersion)
+	case s.Version < dbpkg.SchemaVersion:
+		return StatusWarn, fmt.Sprintf("version %d is unrecorded or older; the next command stamps it %d", s.Version, dbpkg.SchemaVersion)
+	}
+	return StatusPass, fmt.Sprintf("version %d (current)", s.Version)
+}
+
+// checkWritable creates and removes a file in dir, or checks the closest existing
+// parent when dir doesn't exist yet (fetch creates it).
+func checkWritable(dir string) (string, string) {
+	target := dir
+	for {
+		info, err := os.Stat(target)
+		if err == nil {
+			if !info.IsDir() {
+				return StatusFail, fmt.Sprintf("%s is not a directory", target)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return StatusFail, err.Error()
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			return StatusFail, fmt.Sprintf("no existing parent of %s", dir)
+		}
+		target = parent
+	}
+
+	f, err := os.CreateTemp(target, ".lwp-doctor-*")
+	if err != nil {
+		return StatusFail, fmt.Sprintf("%s is not writable: %v", target, err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	_ = os.Remove(name)
+
+	if target != dir {
+		return StatusPass, fmt.Sprintf("%s doesn't exist yet; %s is writable", dir, target)
+	}
+	return StatusPass, fmt.Sprintf("%s is writable", dir)
+}
+
+func checkNetwork(url string, timeout time.Duration) (string, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("invalid --network-url: %v", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if resp.StatusCode >= 400 {
+		return StatusFail, fmt.Sprintf("GET %s: HTTP %d in %s", url, resp.StatusCode, elapsed)
+	}
+	return StatusPass, fmt.Sprintf("GET %s: HTTP %d in %s", url, resp.StatusCode, elapsed)
+}
+
+// DoctorAction runs the self-checks, prints one line per check and exits 1 when any
+// check failed.
+func DoctorAction(c *cli.Context) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	report := RunChecks(Options{
+		DBPath:         filepath.Join(cwd, dbpkg.DefaultDBName),
+		ResultsDir:     c.String("output-dir"),
+		Network:        c.Bool("network"),
+		NetworkURL:     c.String("network-url"),
+		NetworkTimeout: c.Duration("network-timeout"),
+	})
+
+	switch strings.ToLower(c.String("format")) {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+	case "text", "":
+		printReport(c.App.Writer, report)
+	default:
+		return fmt.Errorf("unknown --format %q (supported: text, json)", c.String("format"))
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func printReport(w io.Writer, report *Report) {
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "%-4s  %-12s %s\n", strings.ToUpper(check.Status), check.Name, check.Detail)
+	}
+	fmt.Fprintf(w, "\nSchema version: %d (this build: %d)\n", report.SchemaVersion, report.ExpectedSchemaVersion)
+	if report.Failed > 0 {
+		fmt.Fprintf(w, "%d check(s) failed\n", report.Failed)
+		return
+	}
+	fmt.Fprintln(w, "All checks passed")
+}
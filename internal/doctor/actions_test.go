@@ -0,0 +1,76 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func checkStatus(t *testing.T, report *Report, name string) string {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, report.Checks)
+	return ""
+}
+
+func TestRunChecks_FreshDirectory(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, dbpkg.DefaultDBName)
+	report := RunChecks(Options{DBPath: dbPath, ResultsDir: filepath.Join(dir, "lwp-results")})
+
+	if report.Failed != 0 {
+		t.Errorf("Failed = %d, want 0: %+v", report.Failed, report.Checks)
+	}
+	if got := checkStatus(t, report, "schema"); got != StatusSkip {
+		t.Errorf("schema = %s, want skip", got)
+	}
+	if got := checkStatus(t, report, "network"); got != StatusSkip {
+		t.Errorf("network = %s, want skip without --network", got)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("doctor created the database")
+	}
+}
+
+func TestRunChecks_CurrentDatabase(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	database.Close()
+
+	report := RunChecks(Options{DBPath: filepath.Join(dir, dbpkg.DefaultDBName), ResultsDir: dir})
+	if got := checkStatus(t, report, "schema"); got != StatusPass {
+		t.Errorf("schema = %s, want pass: %+v", got, report.Checks)
+	}
+	if report.SchemaVersion != dbpkg.SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", report.SchemaVersion, dbpkg.SchemaVersion)
+	}
+	if got := checkStatus(t, report, "results-dir"); got != StatusPass {
+		t.Errorf("results-dir = %s, want pass", got)
+	}
+}
+
+func TestRunChecks_ResultsDirIsFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lwp-results")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	report := RunChecks(Options{DBPath: filepath.Join(dir, dbpkg.DefaultDBName), ResultsDir: file})
+	if got := checkStatus(t, report, "results-dir"); got != StatusFail {
+		t.Errorf("results-dir = %s, want fail", got)
+	}
+	if report.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", report.Failed)
+	}
+}
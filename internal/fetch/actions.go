@@ -1,10 +1,15 @@
 package fetch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
 	"os"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,9 +17,15 @@ import (
 	internaldb "github.com/dtnitsch/llm-web-parser/internal/db"
 	"github.com/dtnitsch/llm-web-parser/models"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
 	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/detector"
 	"github.com/dtnitsch/llm-web-parser/pkg/extractor"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
 	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"github.com/dtnitsch/llm-web-parser/pkg/schema"
 	"github.com/dtnitsch/llm-web-parser/pkg/session"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -27,8 +38,47 @@ func FetchAction(c *cli.Context) error {
 	}
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
 	startTime := time.Now()
+
+	// Expand --preset before any other flag is read
+	if preset := c.String("preset"); preset != "" {
+		if err := applyPreset(c, preset); err != nil {
+			logger.Error("invalid --preset", "error", err)
+			os.Exit(2)
+		}
+	}
 	finalOutput := &FinalOutput{}
 
+	// Structured output can go to stdout, --output-file, or both
+	outputFile := c.String("output-file")
+	failedURLsFile := c.String("failed-urls-file")
+	var stdout io.Writer = os.Stdout
+	if c.Bool("no-stdout") {
+		if outputFile == "" {
+			logger.Error("--no-stdout requires --output-file")
+			os.Exit(2)
+		}
+		stdout = io.Discard
+	}
+	// --print-session-only collapses stdout to the session ID for $(...) capture
+	sessionOnly := c.Bool("print-session-only")
+	if sessionOnly {
+		if c.Bool("no-db") {
+			logger.Error("--print-session-only needs a session; it cannot be used with --no-db")
+			os.Exit(2)
+		}
+		stdout = io.Discard
+	}
+	// --quiet-json keeps stdout to the one final document; human-facing notes go to stderr
+	quietJSON := c.Bool("quiet-json")
+	notes := stdout
+	if quietJSON {
+		if sessionOnly {
+			logger.Error("--quiet-json and --print-session-only both claim stdout; pick one")
+			os.Exit(2)
+		}
+		notes = os.Stderr
+	}
+
 	var maxAge time.Duration
 	var err error
 	if c.Bool("force-fetch") {
@@ -47,18 +97,226 @@ func FetchAction(c *cli.Context) error {
 		os.Exit(2)
 	}
 
-	// Open database for metadata storage
-	database, err := db.Open()
+	// Open database for metadata storage. --no-db skips it entirely: artifacts go to the
+	// slug-based raw/ and parsed/ layout and nothing is tracked in sessions or url rows.
+	noDB := c.Bool("no-db")
+	var database *db.DB
+	if noDB {
+		switch {
+		case c.IsSet("session"):
+			logger.Error("--session reads URLs from the database and cannot be used with --no-db")
+			os.Exit(2)
+		case c.IsSet("append-session"):
+			logger.Error("--append-session adds URLs to a database session and cannot be used with --no-db")
+			os.Exit(2)
+		case c.Bool("revalidate"):
+			logger.Error("--revalidate needs validators stored in the database and cannot be used with --no-db")
+			os.Exit(2)
+		case c.String("tag") != "":
+			logger.Error("--tag labels a database session and cannot be used with --no-db")
+			os.Exit(2)
+		case c.IsSet("output-mode") && strings.EqualFold(c.String("output-mode"), "tier2"):
+			logger.Error("--output-mode=tier2 writes session summaries and cannot be used with --no-db")
+			os.Exit(2)
+		}
+	} else {
+		database, err = common.OpenDB(c)
+		if err != nil {
+			logger.Error("failed to open database", "error", err)
+			os.Exit(2)
+		}
+		defer database.Close()
+	}
+
+	retryDelay, err := time.ParseDuration(c.String("retry-delay"))
+	if err != nil {
+		logger.Error("invalid retry-delay duration", "error", err)
+		os.Exit(2)
+	}
+	maxRetryAfter, err := time.ParseDuration(c.String("max-retry-after"))
+	if err != nil || maxRetryAfter < 0 {
+		logger.Error("invalid max-retry-after duration", "value", c.String("max-retry-after"))
+		os.Exit(2)
+	}
+	breakerCooldown, err := time.ParseDuration(c.String("breaker-cooldown"))
+	if err != nil {
+		logger.Error("invalid breaker-cooldown duration", "error", err)
+		os.Exit(2)
+	}
+	var timeoutOverall time.Duration
+	if value := c.String("timeout-overall"); value != "" {
+		timeoutOverall, err = time.ParseDuration(value)
+		if err != nil || timeoutOverall < 0 {
+			logger.Error("invalid timeout-overall duration", "value", value)
+			os.Exit(2)
+		}
+	}
+	if c.Int("store-keywords") < 0 {
+		logger.Error("invalid store-keywords value, must be >= 0", "value", c.Int("store-keywords"))
+		os.Exit(2)
+	}
+
+	// Optional User-Agent pool; without --user-agent-file requests keep Go's default agent
+	var userAgents []string
+	userAgentRotate := c.String("user-agent-rotate")
+	if path := c.String("user-agent-file"); path != "" {
+		userAgents, err = fetcher.LoadUserAgents(path)
+		if err == nil {
+			_, err = fetcher.NewUserAgentPool(userAgents, userAgentRotate)
+		}
+		if err != nil {
+			logger.Error("invalid user agent settings", "error", err)
+			os.Exit(2)
+		}
+	} else if c.IsSet("user-agent-rotate") {
+		logger.Error("--user-agent-rotate requires --user-agent-file")
+		os.Exit(2)
+	}
+
+	var trust *models.TrustConfig
+	if path := c.String("trust-config"); path != "" {
+		trust, err = detector.LoadTrustConfig(path)
+		if err != nil {
+			logger.Error("invalid trust config", "error", err)
+			os.Exit(2)
+		}
+	}
+
+	blockTags, err := parser.ParseBlockTags(c.String("block-tags"))
 	if err != nil {
-		logger.Error("failed to open database", "error", err)
+		logger.Error("invalid --block-tags", "error", err)
+		os.Exit(2)
+	}
+
+	if selector := c.String("content-selector"); selector != "" {
+		if err := parser.ValidateContentSelector(selector); err != nil {
+			logger.Error("invalid --content-selector", "error", err)
+			os.Exit(2)
+		}
+	}
+
+	var confidence *models.ConfidenceConfig
+	if path := c.String("confidence-config"); path != "" {
+		confidence, err = parser.LoadConfidenceConfig(path)
+		if err != nil {
+			logger.Error("invalid confidence config", "error", err)
+			os.Exit(2)
+		}
+	}
+
+	parsedFormat, err := ParseParsedFormat(c.String("parsed-format"))
+	if err != nil {
+		logger.Error("invalid --parsed-format", "error", err)
+		os.Exit(2)
+	}
+
+	splitSections, err := ParseSectionSplit(c.Bool("split-sections"), c.String("section-format"), c.Int("max-section-tokens"))
+	if err != nil {
+		logger.Error("invalid section splitting settings", "error", err)
+		os.Exit(2)
+	}
+
+	chunking, err := ParseChunking(c.Bool("chunk"), c.Int("chunk-size"), c.Int("chunk-overlap"))
+	if err != nil {
+		logger.Error("invalid chunking settings", "error", err)
+		os.Exit(2)
+	}
+
+	followDepth, err := parseFollowDepth(c.Bool("follow-internal"), c.IsSet("depth"), c.Int("depth"), c.Int("max-urls"))
+	if err != nil {
+		logger.Error("invalid link following settings", "error", err)
+		os.Exit(2)
+	}
+
+	workerCount, workersAuto, err := parseWorkers(c.String("workers"))
+	if err != nil {
+		logger.Error("invalid --workers", "error", err)
 		os.Exit(2)
 	}
-	defer database.Close()
 
 	// Initialize runtime config from CLI flags
 	config := &models.FetchConfig{
-		URLs:        []string{},
-		WorkerCount: c.Int("workers"),
+		URLs:             []string{},
+		WorkerCount:      workerCount,
+		WorkersAuto:      workersAuto,
+		CleanHTML:        c.Bool("clean-html"),
+		MinContentLength: c.Int("min-content-length"),
+		ContentSelector:  c.String("content-selector"),
+		StoreKeywords:    c.Int("store-keywords"),
+		Revalidate:       c.Bool("revalidate"),
+		Trust:            trust,
+		BlockTags:        blockTags,
+		KeepLineBreaks:   !c.Bool("canonicalize-whitespace"),
+		MaxSectionDepth:  c.Int("max-section-depth"),
+		SectionLanguages: c.Bool("detect-section-lang"),
+		ValidatePages:    c.Bool("validate"),
+		FailSoft404:      c.Bool("treat-soft-404-as-error"),
+		Confidence:       confidence,
+		ParsedFormat:     parsedFormat,
+		SplitSections:    splitSections,
+		MaxSectionTokens: c.Int("max-section-tokens"),
+		ChunkSize:        chunking.Size,
+		ChunkOverlap:     chunking.Overlap,
+		LLMSummary:       c.Bool("llm-summary"),
+		EnrichAcademic:   c.Bool("enrich-academic"),
+		MaxRetries:       c.Int("retries"),
+		RetryBaseDelay:   retryDelay,
+		MaxRetryAfter:    maxRetryAfter,
+		BreakerThreshold: c.Int("breaker-threshold"),
+		BreakerCooldown:  breakerCooldown,
+		UserAgents:       userAgents,
+		UserAgentRotate:  userAgentRotate,
+		TimeoutOverall:   timeoutOverall,
+		FollowDepth:      followDepth,
+		MaxURLs:          c.Int("max-urls"),
+		NoStoreRaw:       c.Bool("no-store-raw"),
+	}
+	config.HostConcurrency = c.Int("host-concurrency")
+	config.HostConcurrencyMin = c.Int("host-concurrency-min")
+	config.HostConcurrencyMax = c.Int("host-concurrency-max")
+	if config.HostConcurrency < 0 || config.HostConcurrencyMin < 0 || config.HostConcurrencyMax < 0 ||
+		(config.HostConcurrencyMax > 0 && (config.HostConcurrencyMin > config.HostConcurrencyMax || config.HostConcurrency > config.HostConcurrencyMax)) {
+		logger.Error("invalid host concurrency bounds, want 0 <= --host-concurrency-min <= --host-concurrency <= --host-concurrency-max",
+			"initial", config.HostConcurrency, "min", config.HostConcurrencyMin, "max", config.HostConcurrencyMax)
+		os.Exit(2)
+	}
+	if c.Bool("dedupe-output") {
+		config.DedupeThreshold = c.Float64("dedupe-threshold")
+		if config.DedupeThreshold <= 0 || config.DedupeThreshold > 1 {
+			logger.Error("invalid dedupe-threshold, want a similarity in (0, 1]", "value", config.DedupeThreshold)
+			os.Exit(2)
+		}
+	}
+	if value := c.String("stopwords"); value != "" {
+		config.Stopwords = strings.Split(value, ",")
+	}
+	if value := c.String("skip-if-fetched-within"); value != "" {
+		config.SkipIfFetchedWithin, err = time.ParseDuration(value)
+		if err != nil || config.SkipIfFetchedWithin < 0 {
+			logger.Error("invalid skip-if-fetched-within duration", "value", value)
+			os.Exit(2)
+		}
+		if noDB {
+			logger.Warn("--skip-if-fetched-within reads access history from the database; ignored with --no-db")
+		}
+	}
+
+	// --append-session adds the --urls to an existing session instead of starting one
+	var appendTo *db.Session
+	if c.IsSet("append-session") {
+		if c.IsSet("session") {
+			fmt.Fprintln(os.Stderr, "Error: Cannot use both --append-session and --session flags")
+			os.Exit(1)
+		}
+		if !c.IsSet("urls") {
+			fmt.Fprintln(os.Stderr, "Error: --append-session needs --urls with the URLs to add")
+			os.Exit(1)
+		}
+		appendTo, err = database.GetSessionByID(int64(c.Int("append-session")))
+		if err != nil {
+			logger.Error("session to append to not found", "error", err, "session_id", c.Int("append-session"))
+			os.Exit(2)
+		}
 	}
 
 	// Load URLs from session if --session is provided
@@ -88,7 +346,7 @@ func FetchAction(c *cli.Context) error {
 			}
 
 			if len(failedURLs) == 0 {
-				fmt.Printf("Session %d has no failed URLs to retry\n", sessionID)
+				fmt.Fprintf(notes, "Session %d has no failed URLs to retry\n", sessionID)
 				os.Exit(0)
 			}
 
@@ -103,7 +361,7 @@ func FetchAction(c *cli.Context) error {
 			}
 
 			if len(urls) == 0 {
-				fmt.Printf("Session %d has no URLs\n", sessionID)
+				fmt.Fprintf(notes, "Session %d has no URLs\n", sessionID)
 				os.Exit(0)
 			}
 
@@ -116,13 +374,19 @@ func FetchAction(c *cli.Context) error {
 		}
 	}
 
+	// "url|mode" entries pick a parse mode for that URL
+	var urlModes []*models.ParseMode
 	if c.IsSet("urls") {
-		config.URLs = strings.Split(c.String("urls"), ",")
+		config.URLs, urlModes, err = splitURLModes(strings.Split(c.String("urls"), ","))
+		if err != nil {
+			logger.Error("invalid --urls", "error", err)
+			os.Exit(2)
+		}
 	}
-	// WorkerCount is already set during config initialization from CLI flag
+	// WorkerCount is already set during config initialization from CLI flag, except for auto
 
 	if len(config.URLs) == 0 {
-		printFetchHelp()
+		printFetchHelp(notes)
 		os.Exit(1)
 	}
 
@@ -145,19 +409,59 @@ func FetchAction(c *cli.Context) error {
 
 	// Replace with sanitized URLs
 	config.URLs = sanitizedURLs
+	for i, mode := range urlModes {
+		if mode != nil {
+			if config.URLParseModes == nil {
+				config.URLParseModes = make(map[string]models.ParseMode)
+			}
+			config.URLParseModes[config.URLs[i]] = *mode
+		}
+	}
+
+	// Narrow to a subset for quick iteration (--limit-urls / --sample).
+	// Applied to both lists so the session key reflects only the processed URLs.
+	if c.IsSet("limit-urls") && c.IsSet("sample") {
+		fmt.Fprintln(os.Stderr, "Error: Cannot use both --limit-urls and --sample flags")
+		os.Exit(1)
+	}
+	if c.Int("limit-urls") > 0 || c.Int("sample") > 0 {
+		seed := c.Int64("seed")
+		if c.Int("sample") > 0 && !c.IsSet("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		totalURLs := len(config.URLs)
+		indices := SelectURLSubset(totalURLs, c.Int("limit-urls"), c.Int("sample"), seed)
+		subsetURLs := make([]string, len(indices))
+		subsetOriginals := make([]string, len(indices))
+		for i, idx := range indices {
+			subsetURLs[i] = config.URLs[idx]
+			subsetOriginals[i] = originalURLs[idx]
+		}
+		config.URLs = subsetURLs
+		originalURLs = subsetOriginals
+
+		if skipped := totalURLs - len(config.URLs); skipped > 0 {
+			if c.Int("sample") > 0 {
+				fmt.Fprintf(os.Stderr, "Sampling %d of %d URLs (skipped %d, --seed %d to reproduce)\n", len(config.URLs), totalURLs, skipped, seed)
+			} else {
+				fmt.Fprintf(os.Stderr, "Processing first %d of %d URLs (skipped %d)\n", len(config.URLs), totalURLs, skipped)
+			}
+		}
+	}
+
+	if config.WorkersAuto {
+		config.WorkerCount = autoWorkerCount(config.URLs, runtime.GOMAXPROCS(0))
+		logger.Info("Auto-tuned worker count", "workers", config.WorkerCount, "gomaxprocs", runtime.GOMAXPROCS(0))
+	}
 
 	// Parse features flag to determine ParseMode (needed for session lookup)
 	parseMode := ParseFeaturesFlag(c.String("features"))
-	parseModeStr := ""
-	switch parseMode {
-	case models.ParseModeMinimal:
-		parseModeStr = "minimal"
-	case models.ParseModeCheap:
-		parseModeStr = "cheap"
-	case models.ParseModeFull:
-		parseModeStr = "full"
-	}
+	parseModeStr := parseModeName(parseMode, config.URLParseModes)
 	logger.Info("Parse mode determined", "mode", parseModeStr, "features", c.String("features"))
+	if config.FollowDepth > 0 && parseMode == models.ParseModeMinimal {
+		logger.Warn("--follow-internal finds links in parsed content, which minimal mode skips; only URLs parsed with |cheap or |full are followed")
+	}
 
 	// Find or create session in database
 	outputDir := c.String("output-dir")
@@ -165,33 +469,145 @@ func FetchAction(c *cli.Context) error {
 	if c.Bool("force-fetch") {
 		sessionMaxAge = 0 // Force new session
 	}
-	sessionID, cacheHit, err := database.FindOrCreateSession(originalURLs, config.URLs, c.String("features"), parseModeStr, sessionMaxAge)
-	if err != nil {
-		logger.Error("failed to find or create session", "error", err)
-		os.Exit(2)
+	var sessionID int64
+	var cacheHit bool
+	if appendTo != nil {
+		sessionID = appendTo.SessionID
+		added, duplicates, err := database.AppendSessionURLs(sessionID, originalURLs, config.URLs)
+		if err != nil {
+			logger.Error("failed to add URLs to session", "error", err, "session_id", sessionID)
+			os.Exit(2)
+		}
+		if len(duplicates) > 0 {
+			fmt.Fprintf(os.Stderr, "Skipping %d URL(s) already in session %d:\n", len(duplicates), sessionID)
+			for _, idx := range duplicates {
+				fmt.Fprintf(os.Stderr, "  - %s\n", originalURLs[idx])
+			}
+		}
+		if len(added) == 0 {
+			fmt.Fprintf(notes, "No new URLs to add to session %d\n", sessionID)
+			os.Exit(0)
+		}
+
+		addedURLs := make([]string, len(added))
+		addedOriginals := make([]string, len(added))
+		for i, idx := range added {
+			addedURLs[i] = config.URLs[idx]
+			addedOriginals[i] = originalURLs[idx]
+		}
+		config.URLs = addedURLs
+		originalURLs = addedOriginals
+		fmt.Fprintf(os.Stderr, "Adding %d URL(s) to session %d\n", len(config.URLs), sessionID)
+	} else if !noDB {
+		kept, duplicates, err := database.DedupeURLs(config.URLs)
+		if err != nil {
+			logger.Error("failed to check URLs for duplicates", "error", err)
+			os.Exit(2)
+		}
+		if len(duplicates) > 0 {
+			fmt.Fprintf(os.Stderr, "Skipping %d URL(s) that repeat an earlier URL:\n", len(duplicates))
+			for _, idx := range duplicates {
+				fmt.Fprintf(os.Stderr, "  - %s\n", originalURLs[idx])
+			}
+			keptURLs := make([]string, len(kept))
+			keptOriginals := make([]string, len(kept))
+			for i, idx := range kept {
+				keptURLs[i] = config.URLs[idx]
+				keptOriginals[i] = originalURLs[idx]
+			}
+			config.URLs = keptURLs
+			originalURLs = keptOriginals
+		}
+
+		sessionID, cacheHit, err = database.FindOrCreateSession(originalURLs, config.URLs, c.String("features"), parseModeStr, sessionMaxAge)
+		if err != nil {
+			logger.Error("failed to find or create session", "error", err)
+			os.Exit(2)
+		}
+		logger.Info("Session", "session_id", sessionID, "cache_hit", cacheHit)
+	}
+	if !noDB {
+
+		// Tag cache hits too, so re-running with a new --tag relabels the session
+		if tag := c.String("tag"); tag != "" {
+			if err := database.SetSessionTag(sessionID, tag); err != nil {
+				logger.Warn("Failed to tag session", "session_id", sessionID, "tag", tag, "error", err)
+			}
+		}
+		// An appended run only changes the session's retention when it drops raw HTML
+		if !cacheHit && (appendTo == nil || config.NoStoreRaw) {
+			if err := database.SetSessionRawStored(sessionID, !config.NoStoreRaw); err != nil {
+				logger.Warn("Failed to record raw HTML retention", "session_id", sessionID, "error", err)
+			}
+		}
+	}
+
+	outputFormat := strings.ToLower(c.String("format"))
+	if quietJSON && !c.IsSet("format") {
+		outputFormat = "json"
 	}
-	logger.Info("Session", "session_id", sessionID, "cache_hit", cacheHit)
+	summaryVersion := strings.ToLower(c.String("summary-version"))
 
 	// If cache hit, return early
+	if cacheHit && quietJSON {
+		// The payload comes from the session's stored results; nothing is re-fetched
+		summaryResults, cachedStats, err := cachedSessionSummary(database, sessionID)
+		if err != nil {
+			logger.Error("failed to read cached session results", "error", err, "session_id", sessionID)
+			os.Exit(2)
+		}
+		outputData, marshalErr := marshalFinalOutput(&FinalOutput{Status: "success", Results: summaryResults, Stats: cachedStats},
+			summaryResults, cachedStats, "summary", outputFormat, summaryVersion, c.String("summary-fields"))
+		if marshalErr != nil {
+			logger.Error("failed to marshal final output", "error", marshalErr)
+			os.Exit(2)
+		}
+		fmt.Fprintln(stdout, string(outputData))
+		if outputFile != "" {
+			fmt.Fprintf(os.Stderr, "Note: --output-file not written for a cached session; use --force-fetch to re-run\n")
+		}
+		if failedURLsFile != "" {
+			fmt.Fprintf(os.Stderr, "Note: --failed-urls-file not written for a cached session; use --force-fetch to re-run\n")
+		}
+		if cachedStats.TotalURLs > 0 && cachedStats.Failed == cachedStats.TotalURLs {
+			os.Exit(2)
+		}
+		if cachedStats.Failed > 0 {
+			os.Exit(1)
+		}
+		return nil
+	}
 	if cacheHit {
 		logger.Info("Session cache hit - returning cached summaries", "session_id", sessionID)
 		sessionTimestamp := time.Now() // For display purposes
 		sessionDir := session.GetSessionDir(sessionID, sessionTimestamp)
 
 		// Show cache hit with helpful next steps
-		fmt.Printf("Session %d cache hit! Results at: %s\n\n", sessionID, sessionDir)
-		fmt.Printf("💡 Quick start:\n")
-		fmt.Printf("  llm-web-parser corpus extract --session=%d               # See top keywords across all URLs\n", sessionID)
-		fmt.Printf("  llm-web-parser corpus suggest --session=%d               # Get query suggestions\n", sessionID)
-		fmt.Printf("\n  Query examples:\n")
-		fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"has_code_examples\"       # URLs with code blocks\n", sessionID)
-		fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"content_type=academic\"  # Academic papers only\n", sessionID)
-		fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"keyword:api\"            # URLs about 'api'\n", sessionID)
-		fmt.Printf("\nCommands:\n")
-		fmt.Printf("  llm-web-parser db get --file=details %d  # Full session YAML\n", sessionID)
-		fmt.Printf("  llm-web-parser db urls %d                # List URL IDs\n", sessionID)
-		fmt.Printf("  llm-web-parser db show <id>              # Get parsed content\n")
-		fmt.Printf("  llm-web-parser db raw <id>               # Get raw HTML\n")
+		fmt.Fprintf(stdout, "Session %d cache hit! Results at: %s\n\n", sessionID, sessionDir)
+		fmt.Fprintf(stdout, "💡 Quick start:\n")
+		fmt.Fprintf(stdout, "  llm-web-parser corpus extract --session=%d               # See top keywords across all URLs\n", sessionID)
+		fmt.Fprintf(stdout, "  llm-web-parser corpus suggest --session=%d               # Get query suggestions\n", sessionID)
+		fmt.Fprintf(stdout, "\n  Query examples:\n")
+		fmt.Fprintf(stdout, "  llm-web-parser corpus query --session=%d --filter=\"has_code_examples\"       # URLs with code blocks\n", sessionID)
+		fmt.Fprintf(stdout, "  llm-web-parser corpus query --session=%d --filter=\"content_type=academic\"  # Academic papers only\n", sessionID)
+		fmt.Fprintf(stdout, "  llm-web-parser corpus query --session=%d --filter=\"keyword:api\"            # URLs about 'api'\n", sessionID)
+		fmt.Fprintf(stdout, "\nCommands:\n")
+		fmt.Fprintf(stdout, "  llm-web-parser db get --file=details %d  # Full session YAML\n", sessionID)
+		fmt.Fprintf(stdout, "  llm-web-parser db urls %d                # List URL IDs\n", sessionID)
+		fmt.Fprintf(stdout, "  llm-web-parser db show <id>              # Get parsed content\n")
+		fmt.Fprintf(stdout, "  llm-web-parser db raw <id>               # Get raw HTML\n")
+		if outputFile != "" {
+			fmt.Fprintf(os.Stderr, "Note: --output-file not written for a cached session; use --force-fetch to re-run\n")
+		}
+		if failedURLsFile != "" {
+			fmt.Fprintf(os.Stderr, "Note: --failed-urls-file not written for a cached session; use --force-fetch to re-run\n")
+		}
+		if sessionOnly {
+			fmt.Println(sessionID)
+			if cached, err := database.GetSessionByID(sessionID); err == nil && cached.URLCount > 0 && cached.SuccessCount == 0 {
+				os.Exit(2)
+			}
+		}
 		return nil
 	}
 
@@ -207,22 +623,81 @@ func FetchAction(c *cli.Context) error {
 		logger.Info("Filter strategy parsed", "filter", filterStr)
 	}
 
-	allResults, finalWordCounts, runErr := run(logger, config, manager, c.Bool("force-fetch"), parseMode, filterStrategy, database)
+	// Snapshot the previous run of this URL set before this one overwrites its artifacts
+	var baseline *sessionBaseline
+	if c.Bool("diff-previous") && !noDB {
+		baseline, err = captureSessionBaseline(database, sessionID)
+		if err != nil {
+			logger.Warn("Failed to read previous session for --diff-previous", "session_id", sessionID, "error", err)
+		}
+	}
+
+	// --timeout-overall counts from the start of the command, not of the fetch phase
+	ctx := context.Background()
+	if config.TimeoutOverall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, startTime.Add(config.TimeoutOverall))
+		defer cancel()
+	}
+	f := newFetcher(ctx, logger, config)
+
+	allResults, finalWordCounts, runErr := run(ctx, logger, config, manager, f, c.Bool("force-fetch"), parseMode, filterStrategy, database)
+	timedOut := ctx.Err() != nil
+	finalOutput.Warnings = collectWarnings(allResults)
+	if config.FollowDepth > 0 && !noDB {
+		recordDiscoveredURLs(logger, database, sessionID, allResults)
+	}
+
+	// Per-host failure report goes to stderr so it never corrupts structured stdout
+	printHostFailures(allResults, f.HostFailures())
+	if timedOut {
+		printTimeoutReport(allResults, config.TimeoutOverall, sessionID)
+	}
 
 	stats := Stats{
-		TotalURLs:        len(config.URLs),
+		TotalURLs:        len(allResults), // Seeds plus any --follow-internal discoveries
 		TotalTimeSeconds: time.Since(startTime).Seconds(),
 		TopKeywords:      mapreduce.TopKeywords(finalWordCounts, 25),
+		SkippedRecent:    countSkippedRecent(allResults),
+		Duplicates:       countDuplicates(allResults),
+	}
+	if stats.SkippedRecent > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d URL(s) fetched successfully within %s; parsed from stored HTML\n", stats.SkippedRecent, config.SkipIfFetchedWithin)
+	}
+
+	// Failures get the same report in every output mode, written even when there are none
+	if failedURLsFile != "" {
+		if err := writeFailedURLsFile(failedURLsFile, collectFailedURLs(allResults)); err != nil {
+			logger.Error("failed to write failed URLs file", "path", failedURLsFile, "error", err)
+			os.Exit(2)
+		}
 	}
 
 	var summaryResults []ResultSummary
 	outputMode := strings.ToLower(c.String("output-mode"))
+	if noDB && outputMode == "tier2" {
+		// The default tier2 output lives in session directories; without sessions print the summary
+		outputMode = "summary"
+	}
 	switch outputMode {
 	case "tier2":
 		// Two-tier summary system: write to session directory, print concise stats
+		// An appended run rewrites the summaries over the session's earlier results too
+		sessionResults := allResults
+		sessionTimestamp := time.Now()
+		if appendTo != nil {
+			prior, err := priorSessionResults(database, manager, sessionID)
+			if err != nil {
+				logger.Warn("Failed to read earlier session results; summaries cover this run only", "session_id", sessionID, "error", err)
+			} else {
+				sessionResults = append(prior, allResults...)
+			}
+			sessionTimestamp = appendTo.CreatedAt
+		}
+
 		// Count success/failed
 		var successCount, failedCount int
-		for _, r := range allResults {
+		for _, r := range sessionResults {
 			if r.Error != nil {
 				failedCount++
 			} else {
@@ -231,27 +706,26 @@ func FetchAction(c *cli.Context) error {
 		}
 
 		// Create session directory
-		sessionTimestamp := time.Now()
 		if err := session.EnsureSessionDir(sessionID, sessionTimestamp); err != nil {
 			return fmt.Errorf("failed to create session directory: %w", err)
 		}
 
 		// Generate FIELDS.yaml reference (only if it doesn't exist)
-		if err := session.GenerateFieldsReference(outputDir); err != nil {
+		if err := session.GenerateFieldsReference(outputDir, schema.Fields(SummaryDetails{}, "yaml")); err != nil {
 			logger.Warn("Failed to generate FIELDS.yaml reference", "error", err)
 		}
 
 		// Write summaries to session directory
 		sessionDir := session.GetSessionDir(sessionID, sessionTimestamp)
-		if err := WriteSummaryIndexToSession(allResults, sessionDir); err != nil {
+		if err := WriteSummaryIndexToSession(sessionResults, sessionDir); err != nil {
 			return fmt.Errorf("failed to write summary index: %w", err)
 		}
-		if err := WriteSummaryDetailsToSession(allResults, sessionDir, database); err != nil {
+		if err := WriteSummaryDetailsToSession(sessionResults, sessionDir, database); err != nil {
 			return fmt.Errorf("failed to write summary details: %w", err)
 		}
 
 		// Collect and write failed URLs if any
-		failedURLs := collectFailedURLs(allResults)
+		failedURLs := collectFailedURLs(sessionResults)
 		if err := WriteFailedURLsToSession(failedURLs, sessionDir); err != nil {
 			logger.Warn("Failed to write failed URLs file", "error", err)
 		}
@@ -261,7 +735,7 @@ func FetchAction(c *cli.Context) error {
 			logger.Warn("Failed to update session stats in DB", "error", err)
 		}
 
-		// Insert session results for each URL
+		// Insert session results for each URL fetched in this run
 		for _, result := range allResults {
 			urlID, getErr := database.GetURLID(result.URL)
 			if getErr != nil {
@@ -269,12 +743,11 @@ func FetchAction(c *cli.Context) error {
 				continue
 			}
 
-			status := "success"
+			status := result.status()
 			statusCode := 200
 			errorType := ""
 			errorMessage := ""
 			if result.Error != nil {
-				status = "failed"
 				statusCode = 0
 				errorType = result.ErrorType
 				errorMessage = result.Error.Error()
@@ -282,57 +755,77 @@ func FetchAction(c *cli.Context) error {
 
 			estimatedTokens := 0
 			if result.Page != nil && result.Page.Metadata.WordCount > 0 {
-				estimatedTokens = result.Page.Metadata.WordCount / 2 // Rough estimate
+				estimatedTokens = chunk.TokensForWords(result.Page.Metadata.WordCount)
+			}
+			contentHash := ""
+			if result.Error == nil && result.Page != nil {
+				contentHash = corpus.PageContentHash(result.Page)
 			}
 
-			if err := database.InsertSessionResult(sessionID, urlID, status, statusCode, errorType, errorMessage, result.FileSizeBytes, estimatedTokens); err != nil {
+			if err := database.InsertSessionResult(sessionID, urlID, status, statusCode, errorType, errorMessage, result.FileSizeBytes, estimatedTokens, contentHash); err != nil {
 				logger.Warn("Failed to insert session result", "url", result.URL, "error", err)
 			}
 		}
 
 		// Update sessions index
+		previewURLs := config.URLs
+		if appendTo != nil {
+			previewURLs = make([]string, len(sessionResults))
+			for i, r := range sessionResults {
+				previewURLs[i] = r.URL
+			}
+		}
 		sessionInfo := session.Info{
 			SessionID:   sessionID,
-			Created:     time.Now(),
-			URLCount:    len(config.URLs),
+			Created:     sessionTimestamp,
+			URLCount:    len(sessionResults),
 			Success:     successCount,
 			Failed:      failedCount,
 			Features:    session.FormatFeatures(c.String("features")),
-			URLsPreview: session.GetURLsPreview(config.URLs, 3),
+			Tag:         c.String("tag"),
+			URLsPreview: session.GetURLsPreview(previewURLs, 3),
 		}
 		if err := session.UpdateSessionIndex(sessionInfo); err != nil {
 			logger.Warn("Failed to update sessions index", "error", err)
 		}
 
 		// Print simplified stats to stdout
-		fmt.Printf("Session %d: %d/%d URLs successful\nResults: %s\n", sessionID, successCount, len(config.URLs), sessionDir)
+		fmt.Fprintf(notes, "Session %d: %d/%d URLs successful\nResults: %s\n", sessionID, successCount, len(sessionResults), sessionDir)
+
+		if baseline != nil {
+			if diff, err := baseline.diffSession(database, sessionID); err != nil {
+				logger.Warn("Failed to diff against previous session", "session_id", sessionID, "error", err)
+			} else {
+				printSessionDiff(notes, diff)
+			}
+		}
 
 		// Auto-switch active session to the new session
 		if err := internaldb.SetActiveSession(sessionID); err != nil {
 			logger.Warn("Failed to set active session", "session_id", sessionID, "error", err)
 		} else {
-			fmt.Printf("Active session: %d\n", sessionID)
+			fmt.Fprintf(notes, "Active session: %d\n", sessionID)
 		}
 
 		// Show quick start commands for corpus API
 		if successCount > 0 {
-			fmt.Printf("\n💡 Quick start:\n")
-			fmt.Printf("  llm-web-parser corpus extract --session=%d               # See top keywords across all URLs\n", sessionID)
-			fmt.Printf("  llm-web-parser corpus suggest --session=%d               # Get query suggestions\n", sessionID)
-			fmt.Printf("\n  Query examples:\n")
-			fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"has_code_examples\"       # URLs with code blocks\n", sessionID)
-			fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"content_type=academic\"  # Academic papers only\n", sessionID)
-			fmt.Printf("  llm-web-parser corpus query --session=%d --filter=\"keyword:api\"            # URLs about 'api'\n", sessionID)
+			fmt.Fprintf(notes, "\n💡 Quick start:\n")
+			fmt.Fprintf(notes, "  llm-web-parser corpus extract --session=%d               # See top keywords across all URLs\n", sessionID)
+			fmt.Fprintf(notes, "  llm-web-parser corpus suggest --session=%d               # Get query suggestions\n", sessionID)
+			fmt.Fprintf(notes, "\n  Query examples:\n")
+			fmt.Fprintf(notes, "  llm-web-parser corpus query --session=%d --filter=\"has_code_examples\"       # URLs with code blocks\n", sessionID)
+			fmt.Fprintf(notes, "  llm-web-parser corpus query --session=%d --filter=\"content_type=academic\"  # Academic papers only\n", sessionID)
+			fmt.Fprintf(notes, "  llm-web-parser corpus query --session=%d --filter=\"keyword:api\"            # URLs about 'api'\n", sessionID)
 		}
 
 		// Show enhanced URL display unless --quiet flag is set
 		if !c.Bool("quiet") {
 			urlsWithMetadata, err := database.GetSessionURLsWithMetadata(sessionID)
 			if err == nil && len(urlsWithMetadata) > 0 {
-				fmt.Printf("\n")
+				fmt.Fprintf(notes, "\n")
 				for _, u := range urlsWithMetadata {
 					// Line 1: URL ID and URL
-					fmt.Printf("[#%d] %s\n", u.URLID, u.URL)
+					fmt.Fprintf(notes, "[#%d] %s\n", u.URLID, u.URL)
 
 					// Line 2: Metadata (subtype | code:N conf:X.X tokens:Nk [cites:N])
 					metadata := u.ContentSubtype
@@ -356,7 +849,7 @@ func FetchAction(c *cli.Context) error {
 					if u.CitationCount > 0 {
 						metadata += fmt.Sprintf(" cites:%d", u.CitationCount)
 					}
-					fmt.Printf("      %s\n", metadata)
+					fmt.Fprintf(notes, "      %s\n", metadata)
 
 					// Line 3: Top 5 keywords (comma-separated)
 					if len(u.TopKeywords) > 0 {
@@ -364,38 +857,66 @@ func FetchAction(c *cli.Context) error {
 						if len(keywords) > 5 {
 							keywords = keywords[:5]
 						}
-						fmt.Printf("      %s\n", strings.Join(keywords, ", "))
+						fmt.Fprintf(notes, "      %s\n", strings.Join(keywords, ", "))
 					}
 
 					// Line 4: Copy-paste ready command
-					fmt.Printf("      → llm-web-parser db show %d\n", u.URLID)
-					fmt.Printf("\n")
+					fmt.Fprintf(notes, "      → llm-web-parser db show %d\n", u.URLID)
+					fmt.Fprintf(notes, "\n")
 				}
 
 				// Link to development docs
-				fmt.Printf("📖 Command reference: docs/development/index.yaml\n")
+				fmt.Fprintf(notes, "📖 Command reference: docs/development/index.yaml\n")
 			}
 		}
 
 		// Show sanitization info if any URLs were cleaned
 		sanitizedCount, err := database.CountSanitizedURLs(sessionID)
 		if err == nil && sanitizedCount > 0 {
-			fmt.Printf("\nNote: %d URL(s) were auto-cleaned\n", sanitizedCount)
-			fmt.Printf("  To see what changed: llm-web-parser db urls %d --sanitized\n", sessionID)
+			fmt.Fprintf(notes, "\nNote: %d URL(s) were auto-cleaned\n", sanitizedCount)
+			fmt.Fprintf(notes, "  To see what changed: llm-web-parser db urls %d --sanitized\n", sessionID)
 		}
 
-		return nil
-	case "summary":
-		summaryResults = []ResultSummary{}
-		for _, r := range allResults {
-			summary := BuildSummary(r)
-			summaryResults = append(summaryResults, summary)
-			if r.Error != nil {
-				stats.Failed++
-			} else {
-				stats.Successful++
+		// Tier2's stdout is a human digest, so the file (and --quiet-json's stdout) gets the
+		// summary-mode payload instead
+		if outputFile != "" || quietJSON {
+			summaryResults = buildSummaryResults(allResults, &stats)
+			finalOutput.Results = summaryResults
+			finalOutput.Stats = stats
+			finalOutput.Status = "success"
+			if runErr != nil {
+				finalOutput.Status = "partial_failure"
+			}
+			outputData, err := marshalFinalOutput(finalOutput, summaryResults, stats, "summary",
+				outputFormat, summaryVersion, c.String("summary-fields"))
+			if err != nil {
+				return fmt.Errorf("failed to marshal output file payload: %w", err)
 			}
+			if quietJSON {
+				fmt.Fprintln(stdout, string(outputData))
+			}
+			if outputFile != "" {
+				if err := writeOutputFile(outputFile, outputData); err != nil {
+					return fmt.Errorf("failed to write output file: %w", err)
+				}
+			}
+		}
+
+		if sessionOnly {
+			fmt.Println(sessionID)
+		}
+		if timedOut {
+			os.Exit(exitTimeout)
+		}
+		if (sessionOnly || quietJSON) && successCount == 0 {
+			os.Exit(2)
+		}
+		if quietJSON && failedCount > 0 {
+			os.Exit(1)
 		}
+		return nil
+	case "summary":
+		summaryResults = buildSummaryResults(allResults, &stats)
 		finalOutput.Results = summaryResults
 	default:
 		legacyResults := []ResultOutput{}
@@ -408,7 +929,8 @@ func FetchAction(c *cli.Context) error {
 				legacy.ErrorType = r.ErrorType
 			} else {
 				stats.Successful++
-				legacy.Status = "success"
+				legacy.Status = r.status()
+				legacy.DuplicateOf = r.DuplicateOf
 			}
 			legacyResults = append(legacyResults, legacy)
 		}
@@ -422,11 +944,84 @@ func FetchAction(c *cli.Context) error {
 		finalOutput.Status = "success"
 	}
 
+	outputData, marshalErr := marshalFinalOutput(finalOutput, summaryResults, stats, outputMode, outputFormat, summaryVersion, c.String("summary-fields"))
+	if marshalErr != nil {
+		logger.Error("failed to marshal final output", "error", marshalErr)
+		os.Exit(2)
+	}
+	fmt.Fprintln(stdout, string(outputData))
+	if outputFile != "" {
+		if err := writeOutputFile(outputFile, outputData); err != nil {
+			logger.Error("failed to write output file", "path", outputFile, "error", err)
+			os.Exit(2)
+		}
+	}
+	if sessionOnly {
+		fmt.Println(sessionID)
+	}
+
+	if timedOut {
+		os.Exit(exitTimeout)
+	}
+	if stats.Failed == stats.TotalURLs {
+		os.Exit(2)
+	}
+	if stats.Failed > 0 {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// buildSummaryResults summarizes each result and tallies successes and failures into stats.
+func buildSummaryResults(allResults []Result, stats *Stats) []ResultSummary {
+	summaryResults := []ResultSummary{}
+	for _, r := range allResults {
+		summaryResults = append(summaryResults, BuildSummary(r))
+		if r.Error != nil {
+			stats.Failed++
+		} else {
+			stats.Successful++
+		}
+	}
+	return summaryResults
+}
+
+// cachedSessionSummary rebuilds summary results from what a session recorded, for
+// cache hits that must still print the payload (--quiet-json).
+func cachedSessionSummary(database *db.DB, sessionID int64) ([]ResultSummary, Stats, error) {
+	results, err := database.GetSessionResults(sessionID)
+	if err != nil {
+		return nil, Stats{}, err
+	}
+	summaryResults := make([]ResultSummary, 0, len(results))
+	stats := Stats{TotalURLs: len(results)}
+	for _, r := range results {
+		summaryResults = append(summaryResults, ResultSummary{
+			URL:             r.URL,
+			Status:          r.Status,
+			Error:           r.ErrorMessage,
+			FileSizeBytes:   r.FileSizeBytes,
+			EstimatedTokens: r.EstimatedTokens,
+		})
+		switch r.Status {
+		case "failed":
+			stats.Failed++
+		case "skipped_recent":
+			stats.Successful++
+			stats.SkippedRecent++
+		default:
+			stats.Successful++
+		}
+	}
+	return summaryResults, stats, nil
+}
+
+// marshalFinalOutput renders the structured fetch payload, honoring --summary-version and
+// --summary-fields in summary mode.
+func marshalFinalOutput(finalOutput *FinalOutput, summaryResults []ResultSummary, stats Stats, outputMode, outputFormat, summaryVersion, summaryFields string) ([]byte, error) {
 	var outputData []byte
 	var marshalErr error
-	outputFormat := strings.ToLower(c.String("format"))
-	summaryVersion := strings.ToLower(c.String("summary-version"))
-	summaryFields := c.String("summary-fields")
 
 	// Apply field filtering if requested
 	if summaryFields != "" && outputMode == "summary" {
@@ -460,6 +1055,13 @@ func FetchAction(c *cli.Context) error {
 			"results": filteredResults,
 			"stats":   ToTerseStats(stats),
 		}
+		if len(finalOutput.Warnings) > 0 {
+			if isTerse {
+				customOutput["warnings"] = toTerseWarnings(finalOutput.Warnings)
+			} else {
+				customOutput["warnings"] = finalOutput.Warnings
+			}
+		}
 
 		if outputFormat == "yaml" {
 			outputData, marshalErr = yaml.Marshal(customOutput)
@@ -474,9 +1076,10 @@ func FetchAction(c *cli.Context) error {
 		}
 
 		terseFinalOutput := FinalOutputTerse{
-			Status:  finalOutput.Status,
-			Results: terseResults,
-			Stats:   ToTerseStats(stats),
+			Status:   finalOutput.Status,
+			Results:  terseResults,
+			Stats:    ToTerseStats(stats),
+			Warnings: toTerseWarnings(finalOutput.Warnings),
 		}
 
 		if outputFormat == "yaml" {
@@ -493,31 +1096,69 @@ func FetchAction(c *cli.Context) error {
 		}
 	}
 
-	if marshalErr != nil {
-		logger.Error("failed to marshal final output", "error", marshalErr)
-		os.Exit(2)
+	return outputData, marshalErr
+}
+
+// printHostFailures reports failed URLs grouped by host, noting hosts whose circuit breaker opened.
+func printHostFailures(results []Result, breakerStats []fetcher.HostFailureStats) {
+	failuresByHost := make(map[string]int)
+	circuitOpenByHost := make(map[string]int)
+	for _, r := range results {
+		// Cut off by --timeout-overall says nothing about the host
+		if r.Error == nil || r.ErrorType == "timeout" {
+			continue
+		}
+		host := r.URL
+		if parsed, err := url.Parse(r.URL); err == nil && parsed.Host != "" {
+			host = strings.ToLower(parsed.Host)
+		}
+		failuresByHost[host]++
+		if r.ErrorType == "circuit_open" {
+			circuitOpenByHost[host]++
+		}
 	}
-	fmt.Println(string(outputData))
 
-	if stats.Failed == stats.TotalURLs {
-		os.Exit(2)
+	if len(failuresByHost) == 0 {
+		return
 	}
-	if stats.Failed > 0 {
-		os.Exit(1)
+
+	opened := make(map[string]bool)
+	for _, s := range breakerStats {
+		if s.CircuitOpened {
+			opened[s.Host] = true
+		}
 	}
 
-	return nil
+	hosts := make([]string, 0, len(failuresByHost))
+	for host := range failuresByHost {
+		hosts = append(hosts, host)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if failuresByHost[hosts[i]] != failuresByHost[hosts[j]] {
+			return failuresByHost[hosts[i]] > failuresByHost[hosts[j]]
+		}
+		return hosts[i] < hosts[j]
+	})
+
+	fmt.Fprintln(os.Stderr, "Failures by host:")
+	for _, host := range hosts {
+		line := fmt.Sprintf("  %s: %d failed", host, failuresByHost[host])
+		if opened[host] {
+			line += fmt.Sprintf(" (circuit opened, %d skipped)", circuitOpenByHost[host])
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
 }
 
 // printFetchHelp prints LLM-friendly examples when no URLs are provided.
-func printFetchHelp() {
+func printFetchHelp(w io.Writer) {
 	// Get current working directory for context
 	cwd, err := os.Getwd()
 	if err != nil {
 		cwd = "{current-directory}"
 	}
 
-	fmt.Print(`💡 No URLs specified. Here's how to use fetch:
+	fmt.Fprint(w, `💡 No URLs specified. Here's how to use fetch:
 
 Basic usage (metadata + keywords extracted):
   llm-web-parser fetch --urls "https://example.com,https://example.org"
@@ -527,6 +1168,10 @@ Parse modes (minimal, wordcount, full-parse):
   llm-web-parser fetch --urls "..." --features wordcount     # Metadata + keywords (default, recommended)
   llm-web-parser fetch --urls "..." --features full-parse    # Full content extraction
 
+Presets (bundles of the flags above; explicit flags win):
+  llm-web-parser fetch --preset llm-ingest --urls "..."      # full-parse, clean-html, --filter conf:>=0.5
+  llm-web-parser fetch --preset research --urls "..."        # full-parse, clean-html, --store-keywords 100
+
 Two-stage workflow (recommended for 30+ URLs):
   llm-web-parser fetch --urls "url1,url2,...,url30"          # Step 1: Quick scan with keywords
   llm-web-parser corpus query --session 1 --filter="..."     # Step 2: Filter to relevant URLs
@@ -536,10 +1181,14 @@ Session operations (refetch same URLs with different modes):
   llm-web-parser fetch --session 5 --features full-parse     # Refetch session 5 with full parsing
   llm-web-parser fetch --session 5 --failed-only             # Retry only failed URLs
 
+Try a subset first (large URL lists, sitemaps):
+  llm-web-parser fetch --urls "..." --limit-urls 20          # Only the first 20 URLs
+  llm-web-parser fetch --urls "..." --sample 20 --seed 42    # Reproducible random 20
+
 Where data is stored:
-  - Database: ` + cwd + `/llm-web-parser.db
-  - Sessions: ` + cwd + `/lwp-sessions/YYYY-MM-DD-{id}/
-  - Results:  ` + cwd + `/lwp-results/
+  - Database: `+cwd+`/llm-web-parser.db
+  - Sessions: `+cwd+`/lwp-sessions/YYYY-MM-DD-{id}/
+  - Results:  `+cwd+`/lwp-results/
 
 What you get:
   - Metadata: title, excerpt, domain type, confidence score
@@ -0,0 +1,52 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+// priorSessionResults rebuilds the results a session already recorded, so a fetch with
+// --append-session can rewrite the session summaries over the whole URL set rather than
+// only the URLs it added. Successful results get their stored parsed page back; a page
+// that is gone leaves an empty one, so the URL still counts and is listed.
+func priorSessionResults(database *db.DB, manager *artifact_manager.Manager, sessionID int64) ([]Result, error) {
+	recorded, err := database.GetSessionResults(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(recorded))
+	for _, r := range recorded {
+		result := Result{
+			URL:           r.URL,
+			FileSizeBytes: r.FileSizeBytes,
+			SkippedRecent: r.Status == statusSkippedRecent,
+		}
+		if !succeeded(r.Status) {
+			result.Error = errors.New(r.ErrorMessage)
+			result.ErrorType = r.ErrorType
+			results = append(results, result)
+			continue
+		}
+
+		result.Page = &models.Page{URL: r.URL}
+		urlID, err := database.GetURLID(r.URL)
+		if err != nil {
+			return nil, err
+		}
+		page, found, err := manager.GetParsedPageByID(urlID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parsed page of %s: %w", r.URL, err)
+		}
+		if found {
+			result.Page = page
+			result.FilePath = artifact_manager.GetURLArtifactPath("", urlID, "generic.yaml")
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,78 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher/fetchertest"
+)
+
+func TestPriorSessionResults(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		guideURL   = "https://example.com/guide"
+		missingURL = "https://example.com/missing"
+	)
+	urls := []string{guideURL, missingURL}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "", "cheap", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+
+	fake := fetchertest.New(map[string]string{guideURL: guidePage})
+	config := &models.FetchConfig{URLs: urls, WorkerCount: 1}
+	results, _, _ := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	for _, r := range results {
+		urlID, err := database.GetURLID(r.URL)
+		if err != nil {
+			t.Fatalf("GetURLID(%s) error = %v", r.URL, err)
+		}
+		errorType, errorMessage := "", ""
+		if r.Error != nil {
+			errorType, errorMessage = r.ErrorType, r.Error.Error()
+		}
+		if err := database.InsertSessionResult(sessionID, urlID, r.status(), 200, errorType, errorMessage, r.FileSizeBytes, 0, ""); err != nil {
+			t.Fatalf("InsertSessionResult() error = %v", err)
+		}
+	}
+
+	prior, err := priorSessionResults(database, manager, sessionID)
+	if err != nil {
+		t.Fatalf("priorSessionResults() error = %v", err)
+	}
+	if len(prior) != 2 {
+		t.Fatalf("priorSessionResults() returned %d results, want 2", len(prior))
+	}
+
+	byURL := make(map[string]Result, len(prior))
+	for _, r := range prior {
+		byURL[r.URL] = r
+	}
+
+	guide := byURL[guideURL]
+	if guide.Error != nil {
+		t.Errorf("guide error = %v, want none", guide.Error)
+	}
+	if guide.Page == nil || guide.Page.Title != "Widget Guide" {
+		t.Errorf("guide page = %+v, want the stored parsed page", guide.Page)
+	}
+	if guide.FilePath == "" {
+		t.Error("guide FilePath is empty, want the parsed artifact path")
+	}
+
+	missing := byURL[missingURL]
+	if missing.Error == nil || missing.ErrorType != "fetch_error" {
+		t.Errorf("missing = error %v, type %q; want the recorded fetch_error", missing.Error, missing.ErrorType)
+	}
+
+	// Summaries built from the rebuilt results list the earlier success
+	if entry := BuildSummaryIndex(guide); entry == nil || entry.Title != "Widget Guide" {
+		t.Errorf("BuildSummaryIndex(guide) = %+v, want the guide's title", entry)
+	}
+}
@@ -0,0 +1,104 @@
+package fetch
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Fetching is I/O bound, so auto mode runs several workers per CPU
+	autoWorkersPerProc = 4
+	// More workers than this on one host mostly earns 429s and tripped breakers
+	autoWorkersPerHost = 4
+	autoWorkersMax     = 64
+
+	// The tuner judges the error rate over this many recent network outcomes
+	tunerWindow = 10
+	// and halves the active workers when at least this share of them failed
+	tunerMaxErrorRate = 0.5
+)
+
+// parseWorkers reads --workers: a positive count, or "auto" (returns 0, true).
+func parseWorkers(value string) (count int, auto bool, err error) {
+	if strings.EqualFold(strings.TrimSpace(value), "auto") {
+		return 0, true, nil
+	}
+	count, err = strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 1 {
+		return 0, false, fmt.Errorf("--workers must be a positive number or auto, got %q", value)
+	}
+	return count, false, nil
+}
+
+// autoWorkerCount picks a worker count for urls on procs CPUs: autoWorkersPerProc per
+// CPU, but no more than autoWorkersPerHost per distinct host, one per URL, or autoWorkersMax.
+func autoWorkerCount(urls []string, procs int) int {
+	hosts := make(map[string]bool)
+	for _, rawURL := range urls {
+		host := rawURL
+		if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+			host = strings.ToLower(u.Host)
+		}
+		hosts[host] = true
+	}
+
+	count := min(procs*autoWorkersPerProc, len(hosts)*autoWorkersPerHost, len(urls), autoWorkersMax)
+	return max(count, 1)
+}
+
+// workerTuner shrinks the pool of active workers when fetches start failing in bulk,
+// as they do once a host rate-limits or its circuit breaker opens. Workers above the
+// allowed count retire before taking their next job; worker 1 always stays.
+type workerTuner struct {
+	mu       sync.Mutex
+	allowed  int
+	outcomes []bool // Recent network outcomes, true = failed
+}
+
+func newWorkerTuner(workers int) *workerTuner {
+	return &workerTuner{allowed: workers}
+}
+
+// retire reports whether worker id should stop taking jobs. A nil tuner never retires workers.
+func (t *workerTuner) retire(id int) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return id > t.allowed
+}
+
+// observe records a finished job. Only fetch failures count against the error rate;
+// parse problems say nothing about the server. It returns the new worker count when
+// this outcome made the tuner ramp down.
+func (t *workerTuner) observe(r Result) (allowed int, rampedDown bool) {
+	if t == nil {
+		return 0, false
+	}
+	failed := r.ErrorType == "fetch_error" || r.ErrorType == "circuit_open"
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.outcomes = append(t.outcomes, failed)
+	if len(t.outcomes) < tunerWindow {
+		return t.allowed, false
+	}
+	t.outcomes = t.outcomes[len(t.outcomes)-tunerWindow:]
+
+	failures := 0
+	for _, f := range t.outcomes {
+		if f {
+			failures++
+		}
+	}
+	if float64(failures)/tunerWindow < tunerMaxErrorRate || t.allowed == 1 {
+		return t.allowed, false
+	}
+	t.allowed = max(1, t.allowed/2)
+	t.outcomes = t.outcomes[:0] // Judge the smaller pool on its own results
+	return t.allowed, true
+}
@@ -0,0 +1,75 @@
+package fetch
+
+import "testing"
+
+func TestParseWorkers(t *testing.T) {
+	if count, auto, err := parseWorkers("12"); err != nil || auto || count != 12 {
+		t.Errorf("parseWorkers(12) = %d, %v, %v", count, auto, err)
+	}
+	if _, auto, err := parseWorkers("Auto"); err != nil || !auto {
+		t.Errorf("parseWorkers(Auto) = %v, %v; want auto", auto, err)
+	}
+	for _, bad := range []string{"0", "-3", "many"} {
+		if _, _, err := parseWorkers(bad); err == nil {
+			t.Errorf("parseWorkers(%q) error = nil", bad)
+		}
+	}
+}
+
+func TestAutoWorkerCount(t *testing.T) {
+	oneHost := make([]string, 100)
+	manyHosts := make([]string, 100)
+	for i := range oneHost {
+		oneHost[i] = "https://example.com/page"
+		manyHosts[i] = "https://host" + string(rune('a'+i%26)) + string(rune('a'+i/26)) + ".example/"
+	}
+
+	tests := []struct {
+		name  string
+		urls  []string
+		procs int
+		want  int
+	}{
+		{"capped per host", oneHost, 8, autoWorkersPerHost},
+		{"scales with CPUs", manyHosts, 2, 2 * autoWorkersPerProc},
+		{"no more than URLs", manyHosts[:3], 8, 3},
+		{"overall cap", manyHosts, 64, autoWorkersMax},
+	}
+	for _, tt := range tests {
+		if got := autoWorkerCount(tt.urls, tt.procs); got != tt.want {
+			t.Errorf("%s: autoWorkerCount() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWorkerTunerRampsDownOnErrors(t *testing.T) {
+	tuner := newWorkerTuner(8)
+
+	// Parse failures say nothing about the server
+	for i := 0; i < tunerWindow; i++ {
+		if _, ramped := tuner.observe(Result{ErrorType: "parse_error"}); ramped {
+			t.Fatal("observe(parse_error) ramped down")
+		}
+	}
+
+	var allowed int
+	var ramped bool
+	for i := 0; i < tunerWindow && !ramped; i++ {
+		errorType := "fetch_error"
+		if i%2 == 0 {
+			errorType = "circuit_open"
+		}
+		allowed, ramped = tuner.observe(Result{ErrorType: errorType})
+	}
+	if !ramped || allowed != 4 {
+		t.Fatalf("after failures: allowed = %d, ramped = %v; want 4, true", allowed, ramped)
+	}
+	if !tuner.retire(5) || tuner.retire(4) {
+		t.Error("retire() should stop workers above the allowed count only")
+	}
+
+	var nilTuner *workerTuner
+	if nilTuner.retire(100) {
+		t.Error("nil tuner retired a worker")
+	}
+}
@@ -0,0 +1,157 @@
+package fetch
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+)
+
+const (
+	// dedupeShingleWords is the length of the word runs compared between pages.
+	dedupeShingleWords = 3
+	// dedupeSignatureSize is the number of MinHash values kept per page; the similarity
+	// estimate is off by about 1/sqrt(dedupeSignatureSize).
+	dedupeSignatureSize = 128
+)
+
+// contentDeduper spots pages whose content repeats an earlier page's, for --dedupe-output.
+// Pages are compared on the boilerplate-free text corpus.PageContentHash uses: identical text
+// always matches, and with a threshold below 1 so does text whose word shingles overlap
+// by at least that Jaccard similarity, estimated with MinHash.
+type contentDeduper struct {
+	threshold float64
+	byHash    map[string]string // Content hash -> URL of the first page with it
+	canonical []dedupeSignature
+}
+
+type dedupeSignature struct {
+	url       string
+	signature [dedupeSignatureSize]uint64
+}
+
+func newContentDeduper(threshold float64) *contentDeduper {
+	return &contentDeduper{threshold: threshold, byHash: make(map[string]string)}
+}
+
+// duplicateOf returns the URL of an earlier page whose content page duplicates, or ""
+// after remembering page as canonical. Pages without any text are never duplicates.
+func (d *contentDeduper) duplicateOf(url string, page *models.Page) string {
+	text := corpus.PageContentText(page)
+	if text == "" {
+		return ""
+	}
+	hash := artifact_manager.ContentHash([]byte(text))
+	if canonical, ok := d.byHash[hash]; ok {
+		return canonical
+	}
+	d.byHash[hash] = url
+	if d.threshold >= 1 {
+		return ""
+	}
+
+	signature := minHashSignature(text)
+	for _, c := range d.canonical {
+		if signatureSimilarity(&signature, &c.signature) >= d.threshold {
+			d.byHash[hash] = c.url
+			return c.url
+		}
+	}
+	d.canonical = append(d.canonical, dedupeSignature{url: url, signature: signature})
+	return ""
+}
+
+// dedupeInOrder marks the duplicates among results and folds the word counts of the
+// rest into reducer, dropping each page's map once counted. Pages are compared in the
+// order of urls, then any other result (a followed link) in arrival order, so which of
+// two duplicates stays canonical doesn't depend on which worker finished first.
+func dedupeInOrder(d *contentDeduper, results []Result, urls []string, reducer *mapreduce.Reducer) {
+	position := make(map[string]int, len(urls))
+	for i, u := range urls {
+		if _, ok := position[u]; !ok {
+			position[u] = i
+		}
+	}
+	rank := func(r Result) int {
+		if i, ok := position[r.URL]; ok {
+			return i
+		}
+		return len(urls)
+	}
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rank(results[order[a]]) < rank(results[order[b]]) })
+
+	for _, i := range order {
+		r := &results[i]
+		if r.Error == nil && r.Page != nil {
+			r.DuplicateOf = d.duplicateOf(r.URL, r.Page)
+		}
+		if r.DuplicateOf == "" {
+			reducer.Add(r.WordCounts)
+		}
+		r.WordCounts, r.DisplayForms = nil, nil
+	}
+}
+
+// minHashSignature keeps, per seed, the lowest hash over the text's lowercase word shingles.
+func minHashSignature(text string) [dedupeSignatureSize]uint64 {
+	var signature [dedupeSignatureSize]uint64
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+
+	words := strings.Fields(strings.ToLower(text))
+	shingles := max(len(words)-dedupeShingleWords+1, 1)
+	for i := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+dedupeShingleWords, len(words))], " ")))
+		shingle := h.Sum64()
+		for j := range signature {
+			if v := mix64(shingle ^ mix64(uint64(j)+1)); v < signature[j] {
+				signature[j] = v
+			}
+		}
+	}
+	return signature
+}
+
+// signatureSimilarity estimates the Jaccard similarity of two pages' shingles as the
+// share of MinHash values they agree on.
+func signatureSimilarity(a, b *[dedupeSignatureSize]uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / dedupeSignatureSize
+}
+
+// mix64 is the splitmix64 finalizer, turning one shingle hash into independent-looking
+// hashes per signature slot.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// countDuplicates returns how many successful results --dedupe-output collapsed.
+func countDuplicates(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Error == nil && r.DuplicateOf != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,130 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher/fetchertest"
+	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+)
+
+func TestContentDeduper(t *testing.T) {
+	page := func(text string) *models.Page {
+		return &models.Page{FlatContent: []models.ContentBlock{{Type: "p", Text: text, Confidence: 0.8}}}
+	}
+	words := strings.Fields(strings.Repeat("widgets are small components that compose well with other widgets in a toolkit ", 8))
+	base := strings.Join(words, " ")
+	edited := strings.Join(append(words[:len(words)-1], "gadgets"), " ")
+
+	d := newContentDeduper(0.8)
+	if got := d.duplicateOf("https://a.example/guide", page(base)); got != "" {
+		t.Fatalf("first page duplicateOf() = %q, want canonical", got)
+	}
+	if got := d.duplicateOf("https://mirror.example/guide", page(base)); got != "https://a.example/guide" {
+		t.Errorf("identical page duplicateOf() = %q, want the first page", got)
+	}
+	if got := d.duplicateOf("https://b.example/guide", page(edited)); got != "https://a.example/guide" {
+		t.Errorf("near-identical page duplicateOf() = %q, want the first page", got)
+	}
+	if got := d.duplicateOf("https://a.example/release", page("This release improves parser performance and fixes parser bugs.")); got != "" {
+		t.Errorf("different page duplicateOf() = %q, want canonical", got)
+	}
+	if got := d.duplicateOf("https://a.example/empty", &models.Page{}); got != "" {
+		t.Errorf("page without text duplicateOf() = %q, want canonical", got)
+	}
+
+	// A threshold of 1 only matches identical text
+	exact := newContentDeduper(1)
+	exact.duplicateOf("https://a.example/guide", page(base))
+	if got := exact.duplicateOf("https://b.example/guide", page(edited)); got != "" {
+		t.Errorf("threshold 1: near-identical page duplicateOf() = %q, want canonical", got)
+	}
+}
+
+func TestRun_DedupeOutput(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		guideURL   = "https://example.com/guide"
+		mirrorURL  = "https://mirror.example.com/guide"
+		releaseURL = "https://example.com/release"
+	)
+	fake := fetchertest.New(map[string]string{
+		guideURL:   guidePage,
+		mirrorURL:  guidePage,
+		releaseURL: releasePage,
+	})
+	config := &models.FetchConfig{
+		URLs:            []string{guideURL, mirrorURL, releaseURL},
+		WorkerCount:     3,
+		DedupeThreshold: 0.9,
+	}
+
+	results, wordCounts, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	duplicates := map[string]string{}
+	for _, r := range results {
+		if r.DuplicateOf != "" {
+			duplicates[r.URL] = r.DuplicateOf
+		}
+	}
+	// The guide listed first is canonical whichever worker finished first
+	if len(duplicates) != 1 || duplicates[mirrorURL] != guideURL {
+		t.Fatalf("duplicates = %v, want the mirror pointing at %s", duplicates, guideURL)
+	}
+	if n := countDuplicates(results); n != 1 {
+		t.Errorf("countDuplicates() = %d, want 1", n)
+	}
+
+	// The mirror's words are not counted again
+	config.DedupeThreshold = 0
+	_, allCounts, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	if err != nil {
+		t.Fatalf("run() without dedupe error = %v", err)
+	}
+	if wordCounts["widget"] == 0 || allCounts["widget"] != 2*wordCounts["widget"] {
+		t.Errorf("widget counted %d times deduped and %d without, want half", wordCounts["widget"], allCounts["widget"])
+	}
+
+	for _, r := range results {
+		if r.URL != mirrorURL {
+			continue
+		}
+		summary := BuildSummary(r)
+		if summary.DuplicateOf != guideURL || summary.Status != "success" || summary.ContentType != "" || summary.EstimatedTokens != 0 {
+			t.Errorf("duplicate summary = %+v, want only the status and duplicate_of", summary)
+		}
+	}
+}
+
+func TestDedupeInOrder(t *testing.T) {
+	page := &models.Page{FlatContent: []models.ContentBlock{{Type: "p", Text: "Widgets compose well with other widgets.", Confidence: 0.8}}}
+	urls := []string{"https://example.com/guide", "https://mirror.example.com/guide"}
+	// The mirror arrived first, and a followed link outside urls before both
+	results := []Result{
+		{URL: "https://example.com/copy", Page: page, WordCounts: map[string]int{"widgets": 2}},
+		{URL: urls[1], Page: page, WordCounts: map[string]int{"widgets": 2}},
+		{URL: urls[0], Page: page, WordCounts: map[string]int{"widgets": 2}},
+	}
+
+	reducer := mapreduce.NewReducer()
+	dedupeInOrder(newContentDeduper(1), results, urls, reducer)
+	for _, r := range results[:2] {
+		if r.DuplicateOf != urls[0] {
+			t.Errorf("%s DuplicateOf = %q, want %s", r.URL, r.DuplicateOf, urls[0])
+		}
+	}
+	if results[2].DuplicateOf != "" || results[2].WordCounts != nil {
+		t.Errorf("first listed URL = %+v, want canonical with its counts folded", results[2])
+	}
+	if got := reducer.Result()["widgets"]; got != 2 {
+		t.Errorf("widgets counted %d times, want 2", got)
+	}
+}
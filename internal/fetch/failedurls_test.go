@@ -0,0 +1,75 @@
+package fetch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCollectFailedURLs_Category(t *testing.T) {
+	results := []Result{
+		{URL: "https://example.com/ok"},
+		{URL: "https://example.com/gone", Error: &fetcher.StatusError{StatusCode: 404}, ErrorType: "fetch_error"},
+		{URL: "https://example.com/slow", Error: errTimeoutOverall, ErrorType: "timeout"},
+		{URL: "https://example.com/empty", Error: errors.New("no content extracted"), ErrorType: "no_content"},
+	}
+	failed := collectFailedURLs(results)
+	if len(failed) != 3 {
+		t.Fatalf("len(failed) = %d, want 3", len(failed))
+	}
+	want := []struct {
+		status   int
+		category string
+	}{{404, fetcher.CategoryHTTPClient}, {0, fetcher.CategoryTimeout}, {0, "no_content"}}
+	for i, w := range want {
+		if failed[i].StatusCode != w.status || failed[i].ErrorCategory != w.category {
+			t.Errorf("failed[%d] = %+v, want status %d category %s", i, failed[i], w.status, w.category)
+		}
+	}
+}
+
+func TestWriteFailedURLsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Nothing failed: the file still exists, with an empty list
+	emptyPath := filepath.Join(dir, "reports", "failed.yaml")
+	if err := writeFailedURLsFile(emptyPath, nil); err != nil {
+		t.Fatalf("writeFailedURLsFile() error = %v", err)
+	}
+	data, err := os.ReadFile(emptyPath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "failed_urls: []" {
+		t.Errorf("empty file = %q, want \"failed_urls: []\"", data)
+	}
+
+	failed := []FailedURL{{URL: "https://example.com/gone", StatusCode: 404, ErrorType: "fetch_error", ErrorCategory: fetcher.CategoryHTTPClient, ErrorMessage: "failed to fetch HTML, status code: 404"}}
+	for _, name := range []string{"failed.json", "failed.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := writeFailedURLsFile(path, failed); err != nil {
+			t.Fatalf("writeFailedURLsFile(%s) error = %v", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		var got FailedURLs
+		unmarshal := yaml.Unmarshal
+		if strings.HasSuffix(name, ".json") {
+			unmarshal = json.Unmarshal
+		}
+		if err := unmarshal(data, &got); err != nil {
+			t.Fatalf("%s does not decode: %v\n%s", name, err, data)
+		}
+		if len(got.FailedURLs) != 1 || got.FailedURLs[0] != failed[0] {
+			t.Errorf("%s = %+v, want %+v", name, got.FailedURLs, failed)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package fetch
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/extractors"
+)
+
+// crawlNamespace holds url_metadata recorded for URLs found by --follow-internal.
+const crawlNamespace = "crawl"
+
+// parseFollowDepth reads --follow-internal, --depth and --max-urls into the number of
+// link hops to follow (0 = fetch only the given URLs). --depth defaults to 1.
+func parseFollowDepth(follow, depthSet bool, depth, maxURLs int) (int, error) {
+	switch {
+	case depth < 0:
+		return 0, fmt.Errorf("--depth must be >= 0, got %d", depth)
+	case !follow && depthSet && depth > 0:
+		return 0, fmt.Errorf("--depth requires --follow-internal")
+	case !follow:
+		return 0, nil
+	case maxURLs < 1:
+		return 0, fmt.Errorf("--max-urls must be positive, got %d", maxURLs)
+	case !depthSet:
+		return 1, nil
+	}
+	return depth, nil
+}
+
+// crawlOrigin is how a URL entered the run: seeds have depth 0 and no referrer.
+type crawlOrigin struct {
+	depth    int
+	referrer string
+}
+
+// crawler decides which internal links a --follow-internal run fetches next. It is only
+// used from run's result loop, so it needs no locking.
+type crawler struct {
+	maxDepth int
+	maxURLs  int
+	urls     []string // Every URL queued so far, seeds first
+	origins  map[string]crawlOrigin
+}
+
+func newCrawler(seeds []string, maxDepth, maxURLs int) *crawler {
+	c := &crawler{maxDepth: maxDepth, maxURLs: maxURLs, origins: make(map[string]crawlOrigin, len(seeds))}
+	for _, seed := range seeds {
+		if _, ok := c.origins[seed]; !ok {
+			c.origins[seed] = crawlOrigin{}
+		}
+	}
+	c.urls = append(c.urls, seeds...)
+	return c
+}
+
+// follows reports whether links on rawURL's page should be followed.
+func (c *crawler) follows(rawURL string) bool {
+	return c != nil && c.origins[rawURL].depth < c.maxDepth
+}
+
+// discover returns the links from r's page that have not been seen yet, up to maxURLs
+// in total, and records r.URL as their referrer.
+func (c *crawler) discover(r Result) []string {
+	if c == nil || len(r.links) == 0 {
+		return nil
+	}
+	depth := c.origins[r.URL].depth + 1
+	var found []string
+	for _, link := range r.links {
+		if len(c.urls) >= c.maxURLs {
+			break
+		}
+		if _, seen := c.origins[link]; seen {
+			continue
+		}
+		c.origins[link] = crawlOrigin{depth: depth, referrer: r.URL}
+		c.urls = append(c.urls, link)
+		found = append(found, link)
+	}
+	return found
+}
+
+// origin returns the depth and referrer recorded for rawURL.
+func (c *crawler) origin(rawURL string) (depth int, referrer string) {
+	if c == nil {
+		return 0, ""
+	}
+	o := c.origins[rawURL]
+	return o.depth, o.referrer
+}
+
+// followableLinks returns the links on page that stay on its host, unless the page asks
+// crawlers not to follow its links (meta robots or X-Robots-Tag nofollow).
+func followableLinks(page *models.Page, links *extractors.LinksExtraction) []string {
+	if page == nil || links == nil || (page.Metadata.Robots != nil && page.Metadata.Robots.NoFollow) {
+		return nil
+	}
+	base, err := url.Parse(page.URL)
+	if err != nil {
+		return nil
+	}
+	var followable []string
+	for _, link := range links.Links {
+		u, err := url.Parse(link.URL)
+		if err != nil || !strings.EqualFold(u.Host, base.Host) {
+			continue
+		}
+		followable = append(followable, link.URL)
+	}
+	return followable
+}
+
+// recordDiscoveredURLs adds URLs found by --follow-internal to the session and stores
+// the page that linked to each, and its depth, under the crawl url_metadata namespace.
+func recordDiscoveredURLs(logger *slog.Logger, database *db.DB, sessionID int64, results []Result) {
+	for _, r := range results {
+		if r.Referrer == "" {
+			continue
+		}
+		urlID, err := database.GetURLID(r.URL)
+		if err != nil {
+			logger.Warn("Failed to get URL ID for discovered URL", "url", r.URL, "error", err)
+			continue
+		}
+		if err := database.InsertSessionURL(sessionID, urlID, r.URL, r.URL); err != nil {
+			logger.Warn("Failed to add discovered URL to session", "url", r.URL, "session_id", sessionID, "error", err)
+		}
+		if err := database.SetURLMetadata(urlID, crawlNamespace, "referrer", r.Referrer); err != nil {
+			logger.Warn("Failed to store referrer", "url", r.URL, "error", err)
+		}
+		if err := database.SetURLMetadata(urlID, crawlNamespace, "depth", strconv.Itoa(r.Depth)); err != nil {
+			logger.Warn("Failed to store crawl depth", "url", r.URL, "error", err)
+		}
+	}
+}
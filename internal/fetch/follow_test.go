@@ -0,0 +1,124 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher/fetchertest"
+)
+
+// linkPage is a page whose article links to each of hrefs.
+func linkPage(title string, hrefs ...string) string {
+	var links strings.Builder
+	for _, href := range hrefs {
+		links.WriteString(`<li><a href="` + href + `">` + href + `</a></li>`)
+	}
+	return `<!DOCTYPE html><html><head><title>` + title + `</title></head><body><article>
+<h1>` + title + `</h1>
+<p>This page covers widgets in some depth, with links to related widget pages below.</p>
+<ul>` + links.String() + `</ul>
+</article></body></html>`
+}
+
+func TestRun_FollowInternalLinks(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const seedURL = "https://example.com/"
+	fake := fetchertest.New(map[string]string{
+		seedURL:                 linkPage("Home", "/a", "/b", "https://other.example.org/x", "/a#install"),
+		"https://example.com/a": linkPage("A", "/", "/c"), // Depth 1: its links are not followed
+		"https://example.com/b": linkPage("B"),
+	})
+	config := &models.FetchConfig{
+		URLs:        []string{seedURL},
+		WorkerCount: 2,
+		FollowDepth: 1,
+		MaxURLs:     10,
+	}
+
+	results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	var urls []string
+	for _, r := range results {
+		urls = append(urls, r.URL)
+		wantReferrer, wantDepth := seedURL, 1
+		if r.URL == seedURL {
+			wantReferrer, wantDepth = "", 0
+		}
+		if r.Referrer != wantReferrer || r.Depth != wantDepth {
+			t.Errorf("%s: referrer %q depth %d, want %q depth %d", r.URL, r.Referrer, r.Depth, wantReferrer, wantDepth)
+		}
+	}
+	sort.Strings(urls)
+	if got := strings.Join(urls, " "); got != "https://example.com/ https://example.com/a https://example.com/b" {
+		t.Errorf("fetched %s, want the seed and its two same-host links", got)
+	}
+}
+
+func TestRun_FollowInternalLinksLimits(t *testing.T) {
+	const seedURL = "https://example.com/"
+	pages := map[string]string{
+		seedURL:                 linkPage("Home", "/a", "/b", "/c"),
+		"https://example.com/a": linkPage("A"),
+		"https://example.com/b": linkPage("B"),
+		"https://example.com/c": linkPage("C"),
+	}
+
+	t.Run("max urls", func(t *testing.T) {
+		manager, database := setupRun(t)
+		config := &models.FetchConfig{URLs: []string{seedURL}, WorkerCount: 1, FollowDepth: 1, MaxURLs: 2}
+		results, _, err := run(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), config, manager, fetchertest.New(pages), false, models.ParseModeCheap, nil, database)
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if len(results) != 2 {
+			t.Errorf("run() fetched %d URLs, want 2 (--max-urls)", len(results))
+		}
+	})
+
+	t.Run("nofollow", func(t *testing.T) {
+		manager, database := setupRun(t)
+		fake := fetchertest.New(pages)
+		fake.SetRobotsTag(seedURL, "nofollow")
+		config := &models.FetchConfig{URLs: []string{seedURL}, WorkerCount: 1, FollowDepth: 1, MaxURLs: 10}
+		results, _, err := run(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)), config, manager, fake, false, models.ParseModeCheap, nil, database)
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if len(results) != 1 {
+			t.Errorf("run() fetched %d URLs, want only the nofollow seed", len(results))
+		}
+	})
+}
+
+func TestParseFollowDepth(t *testing.T) {
+	tests := []struct {
+		follow, depthSet bool
+		depth, maxURLs   int
+		want             int
+		wantErr          bool
+	}{
+		{follow: false, maxURLs: 100, want: 0},
+		{follow: true, maxURLs: 100, want: 1},
+		{follow: true, depthSet: true, depth: 2, maxURLs: 100, want: 2},
+		{follow: true, depthSet: true, depth: 0, maxURLs: 100, want: 0},
+		{follow: false, depthSet: true, depth: 1, maxURLs: 100, wantErr: true},
+		{follow: true, depthSet: true, depth: -1, maxURLs: 100, wantErr: true},
+		{follow: true, maxURLs: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseFollowDepth(tt.follow, tt.depthSet, tt.depth, tt.maxURLs)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseFollowDepth(%v, %v, %d, %d) = %d, %v; want %d, error %v", tt.follow, tt.depthSet, tt.depth, tt.maxURLs, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -1,12 +1,40 @@
 package fetch
 
 import (
+	"time"
+
 	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/enrich"
 )
 
 type Job struct {
-	URL       string
-	ParseMode models.ParseMode
+	URL              string
+	ParseMode        models.ParseMode
+	CleanHTML        bool
+	MinContentLength int
+	ContentSelector  string              // --content-selector override for readability's content root
+	StoreKeywords    int                 // Keywords written to urls.top_keywords (0 = all)
+	Revalidate       bool                // Check cache freshness with a HEAD request instead of modtime
+	Trust            *models.TrustConfig // --trust-config overrides for detector confidence
+	BlockTags        []string            // --block-tags elements captured as content blocks
+	KeepLineBreaks   bool                // --canonicalize-whitespace=false keeps <br> line breaks
+	MaxSectionDepth  int                 // --max-section-depth (0 = unlimited)
+	SectionLanguages bool                // --detect-section-lang: detect each section's language
+	ValidatePages    bool                // --validate: log Page.Validate violations
+	FailSoft404      bool                // --treat-soft-404-as-error: fail pages flagged soft_404
+	ParsedFormat     string              // --parsed-format: yaml, json or both (empty = yaml)
+	SplitSections    string              // --split-sections: section file format, md or yaml (empty = off)
+	MaxSectionTokens int                 // --max-section-tokens: split larger sections into parts (0 = no limit)
+	ChunkSize        int                 // --chunk --chunk-size: tokens per chunks.jsonl chunk (0 = off)
+	ChunkOverlap     int                 // --chunk-overlap: tokens repeated from the previous chunk
+	LLMSummary       bool                // --llm-summary: write a content-type digest, llm-summary.md
+	Enricher         *enrich.Client      // --enrich-academic publication lookups (nil = off)
+	RobotsTags       []string            // X-Robots-Tag lines of the response the HTML came from
+	FollowLinks      bool                // --follow-internal: report the page's same-host links
+	NoStoreRaw       bool                // --no-store-raw: parse fetched HTML without persisting it
+	SkipRecent       time.Duration       // --skip-if-fetched-within: reuse stored HTML fetched this recently
+
+	skippedRecent bool // Set by the worker when SkipRecent served the page from storage
 }
 
 // Result holds the outcome of a processed job.
@@ -16,8 +44,17 @@ type Result struct {
 	Page          *models.Page
 	Error         error
 	ErrorType     string
-	WordCounts    map[string]int
+	WordCounts    map[string]int    // Cleared by run once folded into the aggregate counts
+	DisplayForms  map[string]string // Dominant original-case spelling of WordCounts keys, when not lowercase
 	FileSizeBytes int64
+	Referrer      string // Page whose link led here (--follow-internal); empty for seed URLs
+	Depth         int    // Links followed from a seed URL to reach this one
+	SkippedRecent bool   // Served from storage by --skip-if-fetched-within, not fetched
+	DuplicateOf   string // Earlier result with the same content (--dedupe-output); its words are not aggregated
+
+	Warnings []Warning // Non-fatal problems logged while processing the URL, any status
+
+	links []string // Same-host links to follow, set when the job asked for them
 }
 
 // ResultOutput is the structured output for a single URL.
@@ -27,27 +64,43 @@ type ResultOutput struct {
 	Status    string `json:"status"`
 	Error     string `json:"error,omitempty"`
 	ErrorType string `json:"error_type,omitempty"`
+
+	DuplicateOf string `json:"duplicate_of,omitempty"` // URL of the result with the same content (--dedupe-output)
 }
 
 // ResultSummary holds detailed summary data for a single processed URL.
 type ResultSummary struct {
 	URL               string         `json:"url"`
 	FilePath          string         `json:"file_path,omitempty"`
-	Status            string         `json:"status"`
+	Status            string         `json:"status" enum:"success,skipped_recent,failed"`
 	Error             string         `json:"error,omitempty"`
 	FileSizeBytes     int64          `json:"file_size_bytes,omitempty"`
 	EstimatedTokens   int            `json:"estimated_tokens,omitempty"`
-	ContentType       string         `json:"content_type,omitempty"`
-	ExtractionQuality string         `json:"extraction_quality,omitempty"`
+	ContentType       string         `json:"content_type,omitempty" enum:"academic,docs,wiki,news,repo,blog,landing,unknown"`
+	ExtractionQuality string         `json:"extraction_quality,omitempty" enum:"ok,low,degraded,minimal"`
+	Paywalled         bool           `json:"paywalled,omitempty"`
+	Soft404           bool           `json:"soft_404,omitempty"`
+	NoIndex           bool           `json:"noindex,omitempty"`
+	NoFollow          bool           `json:"nofollow,omitempty"`
+	DuplicateOf       string         `json:"duplicate_of,omitempty"` // --dedupe-output: same content as this URL's result, other fields omitted
 	ConfidenceDist    map[string]int `json:"confidence_distribution,omitempty"`
 	BlockTypeDist     map[string]int `json:"block_type_distribution,omitempty"`
 }
 
 // FinalOutput is the structured output for the entire run.
 type FinalOutput struct {
-	Status  string      `json:"status"`
-	Results interface{} `json:"results"`
-	Stats   Stats       `json:"stats"`
+	Status   string      `json:"status"`
+	Results  interface{} `json:"results"`
+	Stats    Stats       `json:"stats"`
+	Warnings []Warning   `json:"warnings,omitempty"`
+}
+
+// Warning is a non-fatal problem with one URL, such as an artifact that failed to write.
+// The URL's status is unaffected, so a success with warnings is degraded but usable.
+type Warning struct {
+	URL     string `json:"url"`
+	Stage   string `json:"stage" enum:"fetch,parse,store"`
+	Message string `json:"message"`
 }
 
 // Stats provides summary statistics for the run.
@@ -55,6 +108,8 @@ type Stats struct {
 	TotalURLs        int      `json:"total_urls"`
 	Successful       int      `json:"successful"`
 	Failed           int      `json:"failed"`
+	SkippedRecent    int      `json:"skipped_recent,omitempty"` // Successes served from storage, not fetched
+	Duplicates       int      `json:"duplicates,omitempty"`     // Successes collapsed by --dedupe-output
 	TotalTimeSeconds float64  `json:"total_time_seconds"`
 	TopKeywords      []string `json:"top_keywords,omitempty"`
 }
@@ -71,6 +126,7 @@ type ResultSummaryTerse struct {
 	ExtractionQuality int            `json:"q,omitempty"`  // 1=ok, 0=low, -1=degraded
 	ConfidenceDist    [3]int         `json:"cd,omitempty"` // [high, medium, low] fixed order
 	BlockTypeDist     map[string]int `json:"bd,omitempty"`
+	DuplicateOf       string         `json:"do,omitempty"`
 }
 
 // StatsTerse is the token-optimized v2 stats format.
@@ -80,13 +136,22 @@ type StatsTerse struct {
 	Failed   int      `json:"f"`
 	Time     float64  `json:"ts"`
 	Keywords []string `json:"kw,omitempty"`
+	Dupes    int      `json:"dup,omitempty"`
 }
 
 // FinalOutputTerse is the v2 terse output wrapper.
 type FinalOutputTerse struct {
-	Status  string               `json:"s"`
-	Results []ResultSummaryTerse `json:"r"`
-	Stats   StatsTerse           `json:"st"`
+	Status   string               `json:"s"`
+	Results  []ResultSummaryTerse `json:"r"`
+	Stats    StatsTerse           `json:"st"`
+	Warnings []WarningTerse       `json:"w,omitempty"`
+}
+
+// WarningTerse is the v2 Warning format.
+type WarningTerse struct {
+	URL     string `json:"u"`
+	Stage   string `json:"sg"`
+	Message string `json:"m"`
 }
 
 // SummaryIndex is the ultra-minimal, scannable index format (~150 bytes/URL).
@@ -98,6 +163,11 @@ type SummaryIndex struct {
 	Title  string  `yaml:"title,omitempty"`
 	Desc   string  `yaml:"desc,omitempty"`   // excerpt
 	Tokens int     `yaml:"tokens,omitempty"` // estimated_tokens
+
+	Paywalled bool `yaml:"paywalled,omitempty"` // Teaser only; see metadata.paywalled
+	Soft404   bool `yaml:"soft_404,omitempty"`  // Error or placeholder page served with 200
+	NoIndex   bool `yaml:"noindex,omitempty"`   // Page asks not to be indexed; see metadata.robots
+	NoFollow  bool `yaml:"nofollow,omitempty"`
 }
 
 // SummaryDetails contains full enriched metadata for decision making (~400 bytes/URL).
@@ -106,19 +176,21 @@ type SummaryDetails struct {
 	URL        string `yaml:"url"`
 	URLID      int64  `yaml:"url_id,omitempty"`
 	FilePath   string `yaml:"file_path,omitempty"`
-	Status     string `yaml:"status"` // success, failed
+	Status     string `yaml:"status" enum:"success,skipped_recent,failed"`
 	StatusCode int    `yaml:"status_code,omitempty"`
 	Error      string `yaml:"error,omitempty"`
+	Referrer   string `yaml:"referrer,omitempty"` // Page that linked here (--follow-internal)
 
 	// Basic metadata
 	Title       string `yaml:"title,omitempty"`
+	TitleSource string `yaml:"title_source,omitempty" enum:"readability,og_title,h1,title_tag"`
 	Excerpt     string `yaml:"excerpt,omitempty"`
 	SiteName    string `yaml:"site_name,omitempty"`
 	Author      string `yaml:"author,omitempty"`
 	PublishedAt string `yaml:"published_at,omitempty"`
 
 	// Smart detection
-	DomainType     string  `yaml:"domain_type,omitempty"`
+	DomainType     string  `yaml:"domain_type,omitempty" enum:"gov,edu,academic,commercial,mobile,unknown"`
 	DomainCategory string  `yaml:"domain_category,omitempty"`
 	Country        string  `yaml:"country,omitempty"`
 	Confidence     float64 `yaml:"confidence,omitempty"`
@@ -140,10 +212,19 @@ type SummaryDetails struct {
 	ReadTimeMin        float64 `yaml:"read_time_min,omitempty"`
 	Language           string  `yaml:"language,omitempty"`
 	LanguageConfidence float64 `yaml:"language_confidence,omitempty"`
-	ContentType        string  `yaml:"content_type,omitempty"`
-	ExtractionMode     string  `yaml:"extraction_mode,omitempty"`
+	ContentType        string  `yaml:"content_type,omitempty" enum:"academic,docs,wiki,news,repo,blog,landing,unknown"`
+	ExtractionMode     string  `yaml:"extraction_mode,omitempty" enum:"minimal,cheap,full"`
+	ContentSource      string  `yaml:"content_source,omitempty" enum:"readability,body_fallback,selector"`
+	DocumentKind       string  `yaml:"document_kind,omitempty" enum:"html,xhtml,fragment"`
 	SectionCount       int     `yaml:"section_count,omitempty"`
 	BlockCount         int     `yaml:"block_count,omitempty"`
+	Paywalled          bool    `yaml:"paywalled,omitempty"`
+	Soft404            bool    `yaml:"soft_404,omitempty"`
+
+	// Robots directives (meta robots / X-Robots-Tag)
+	NoIndex      bool   `yaml:"noindex,omitempty"`
+	NoFollow     bool   `yaml:"nofollow,omitempty"`
+	RobotsSource string `yaml:"robots_source,omitempty" enum:"meta,header,meta+header"`
 
 	// Visual metadata (boolean/count only, not URLs)
 	HasFavicon bool `yaml:"has_favicon,omitempty"`
@@ -153,19 +234,27 @@ type SummaryDetails struct {
 	FinalURL        string   `yaml:"final_url,omitempty"`
 	RedirectChain   []string `yaml:"redirect_chain,omitempty"`
 	HTTPContentType string   `yaml:"http_content_type,omitempty"`
+
+	// Canonical identity
+	CanonicalURL string `yaml:"canonical_url,omitempty"` // declared via <link rel="canonical">
+	DuplicateOf  int64  `yaml:"duplicate_of,omitempty"`  // url_id of an earlier URL with the same declared canonical
+
+	// Social previews (OpenGraph / Twitter card)
+	Social *models.SocialMetadata `yaml:"social,omitempty"`
 }
 
 // FailedURL represents a URL that failed during processing.
 type FailedURL struct {
-	URL          string `yaml:"url"`
-	StatusCode   int    `yaml:"status_code"` // 0 for network errors
-	ErrorType    string `yaml:"error_type"`  // http_error, network_error, parse_error, timeout
-	ErrorMessage string `yaml:"error_message"`
+	URL           string `yaml:"url" json:"url"`
+	StatusCode    int    `yaml:"status_code" json:"status_code"`       // 0 for network errors
+	ErrorType     string `yaml:"error_type" json:"error_type"`         // Pipeline stage: fetch_error, circuit_open, timeout, parse_error, no_content, soft_404, marshal_error
+	ErrorCategory string `yaml:"error_category" json:"error_category"` // Stable cause: fetcher.Category* values, or parse_error, no_content, soft_404, marshal_error
+	ErrorMessage  string `yaml:"error_message" json:"error_message"`
 }
 
 // FailedURLs wraps the list of failed URLs for YAML output.
 type FailedURLs struct {
-	FailedURLs []FailedURL `yaml:"failed_urls"`
+	FailedURLs []FailedURL `yaml:"failed_urls" json:"failed_urls"`
 }
 
 // toTerseStatus converts status string to int (0=success, 1=failed).
@@ -0,0 +1,20 @@
+package fetch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+)
+
+// ParseParsedFormat validates a --parsed-format value; empty means yaml.
+func ParseParsedFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", corpus.ParsedFormatYAML:
+		return corpus.ParsedFormatYAML, nil
+	case corpus.ParsedFormatJSON, corpus.ParsedFormatBoth:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown parsed format %q (use yaml, json or both)", value)
+	}
+}
@@ -0,0 +1,61 @@
+package fetch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// fetchPreset is a named bundle of fetch flag values for a common workflow.
+type fetchPreset struct {
+	name        string
+	description string
+	flags       [][2]string // flag name, value; applied in order
+}
+
+// fetchPresets are the values --preset expands to. Explicit flags always win.
+var fetchPresets = []fetchPreset{
+	{
+		name:        "llm-ingest",
+		description: "Full content for feeding to an LLM: full parse, script/style stripped, blocks below 0.5 confidence (navigation, footers) dropped",
+		flags: [][2]string{
+			{"features", "full-parse"},
+			{"clean-html", "true"},
+			{"filter", "conf:>=0.5"},
+		},
+	},
+	{
+		name:        "research",
+		description: "Papers and reference material: full parse (academic.yaml, docs.yaml and citation signals), script/style stripped, 100 keywords per URL stored for corpus queries",
+		flags: [][2]string{
+			{"features", "full-parse"},
+			{"clean-html", "true"},
+			{"store-keywords", "100"},
+		},
+	},
+}
+
+// applyPreset sets each of the named preset's flags the user didn't pass explicitly.
+func applyPreset(c *cli.Context, name string) error {
+	for _, preset := range fetchPresets {
+		if preset.name != name {
+			continue
+		}
+		for _, flag := range preset.flags {
+			if c.IsSet(flag[0]) {
+				continue
+			}
+			if err := c.Set(flag[0], flag[1]); err != nil {
+				return fmt.Errorf("preset %s: failed to set --%s: %w", name, flag[0], err)
+			}
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(fetchPresets))
+	for _, preset := range fetchPresets {
+		names = append(names, preset.name)
+	}
+	return fmt.Errorf("unknown preset %q (available: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,54 @@
+package fetch
+
+import (
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// runPreset parses args with the preset-related fetch flags and returns the
+// flag values after applyPreset.
+func runPreset(t *testing.T, args ...string) (features, filter string, keywords int, err error) {
+	t.Helper()
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "preset"},
+			&cli.StringFlag{Name: "features", Value: "wordcount"},
+			&cli.StringFlag{Name: "filter"},
+			&cli.BoolFlag{Name: "clean-html"},
+			&cli.IntFlag{Name: "store-keywords", Value: 25},
+		},
+		Action: func(c *cli.Context) error {
+			err = applyPreset(c, c.String("preset"))
+			features, filter, keywords = c.String("features"), c.String("filter"), c.Int("store-keywords")
+			return nil
+		},
+	}
+	if runErr := app.Run(append([]string{"lwp"}, args...)); runErr != nil {
+		t.Fatalf("app.Run() error = %v", runErr)
+	}
+	return features, filter, keywords, err
+}
+
+func TestApplyPreset(t *testing.T) {
+	features, filter, keywords, err := runPreset(t, "--preset", "research")
+	if err != nil {
+		t.Fatalf("applyPreset(research) error = %v", err)
+	}
+	if features != "full-parse" || filter != "" || keywords != 100 {
+		t.Errorf("research = features %q, filter %q, keywords %d", features, filter, keywords)
+	}
+
+	// Explicit flags win over the preset
+	features, filter, _, err = runPreset(t, "--preset", "llm-ingest", "--features", "wordcount")
+	if err != nil {
+		t.Fatalf("applyPreset(llm-ingest) error = %v", err)
+	}
+	if features != "wordcount" || filter != "conf:>=0.5" {
+		t.Errorf("llm-ingest with --features = features %q, filter %q", features, filter)
+	}
+
+	if _, _, _, err := runPreset(t, "--preset", "everything"); err == nil {
+		t.Error("applyPreset(everything) error = nil, want unknown preset")
+	}
+}
@@ -0,0 +1,17 @@
+package fetch
+
+import (
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+)
+
+// parsedByOlderParser reports whether a URL's parsed page was written by a parser
+// version other than the current one, including pages stored before provenance existed.
+func parsedByOlderParser(database *db.DB, urlID int64) (bool, error) {
+	provenance, err := database.GetParsedArtifactMetadata(urlID)
+	if err != nil {
+		return false, err
+	}
+	return provenance[corpus.ProvenanceParserVersion] != parser.Version, nil
+}
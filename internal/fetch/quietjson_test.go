@@ -0,0 +1,108 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// runQuietJSONFetch runs FetchAction with --quiet-json and returns everything it wrote to stdout.
+func runQuietJSONFetch(t *testing.T, url string) []byte {
+	t.Helper()
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "urls"},
+			&cli.BoolFlag{Name: "quiet-json"},
+			&cli.BoolFlag{Name: "quiet", Value: true},
+			&cli.StringFlag{Name: "features", Value: "wordcount"},
+			&cli.StringFlag{Name: "format", Value: "yaml"},
+			&cli.StringFlag{Name: "output-mode", Value: "tier2"},
+			&cli.StringFlag{Name: "summary-version", Value: "v1"},
+			&cli.StringFlag{Name: "parsed-format", Value: "yaml"},
+			&cli.StringFlag{Name: "max-age", Value: "1h"},
+			&cli.StringFlag{Name: "workers", Value: "1"},
+			&cli.StringFlag{Name: "retry-delay", Value: "10ms"},
+			&cli.StringFlag{Name: "max-retry-after", Value: "1s"},
+			&cli.StringFlag{Name: "breaker-cooldown", Value: "1m"},
+			&cli.IntFlag{Name: "breaker-threshold", Value: 5},
+			&cli.IntFlag{Name: "min-content-length", Value: 50},
+			&cli.IntFlag{Name: "store-keywords", Value: 25},
+			&cli.StringFlag{Name: "output-dir", Value: "lwp-results"},
+		},
+		Action: FetchAction,
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	realStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = realStdout }()
+
+	output := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- data
+	}()
+	runErr := app.Run([]string{"lwp", "--quiet-json", "--urls", url})
+	w.Close()
+	if runErr != nil {
+		t.Fatalf("FetchAction() error = %v", runErr)
+	}
+	return <-output
+}
+
+// assertSingleJSONValue fails unless stdout holds exactly one JSON value.
+func assertSingleJSONValue(t *testing.T, stdout []byte) map[string]any {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(stdout))
+	var payload map[string]any
+	if err := dec.Decode(&payload); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	var extra any
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		t.Fatalf("stdout has more than one value (next: %v, err %v):\n%s", extra, err, stdout)
+	}
+	return payload
+}
+
+func TestFetchQuietJSON_StdoutIsOneDocument(t *testing.T) {
+	t.Chdir(t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><head><title>Quiet</title></head><body><article><h1>Quiet</h1><p>"+
+			strings.Repeat("Structured output stays machine readable. ", 20)+"</p></article></body></html>")
+	}))
+	defer server.Close()
+
+	// --urls rejects host:port, so a portless host is dialed to the test server instead
+	realTransport := http.DefaultTransport
+	http.DefaultTransport = &http.Transport{DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}}
+	defer func() { http.DefaultTransport = realTransport }()
+
+	// The first run fetches; the second is a session cache hit answered from stored results
+	for _, run := range []string{"fetch", "cache hit"} {
+		payload := assertSingleJSONValue(t, runQuietJSONFetch(t, "http://quiet.example/page"))
+		results, ok := payload["results"].([]any)
+		if !ok || len(results) != 1 {
+			t.Fatalf("%s: results = %v, want one entry", run, payload["results"])
+		}
+		if status := results[0].(map[string]any)["status"]; status != "success" {
+			t.Errorf("%s: result status = %v, want success", run, status)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package fetch
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+// statusSkippedRecent is the status of a URL that --skip-if-fetched-within served from
+// storage because some earlier run, in any session, fetched it successfully. Its page is
+// parsed like any other, so it counts as a success.
+const statusSkippedRecent = "skipped_recent"
+
+// status is the outcome recorded for r in summaries and session results.
+func (r Result) status() string {
+	switch {
+	case r.Error != nil:
+		return "failed"
+	case r.SkippedRecent:
+		return statusSkippedRecent
+	}
+	return "success"
+}
+
+// succeeded reports whether a recorded status means the URL's page is available.
+func succeeded(status string) bool {
+	return status == "success" || status == statusSkippedRecent
+}
+
+// recentRawHTML returns a URL's stored raw HTML when its last access succeeded within
+// window, whatever --max-age says. It returns false when the URL is due for a fetch or
+// nothing was stored for it (e.g. that run used --no-store-raw).
+func recentRawHTML(logger *slog.Logger, database *db.DB, manager *artifact_manager.Manager, urlID int64, window time.Duration) ([]byte, bool) {
+	if database == nil || urlID <= 0 || window <= 0 {
+		return nil, false
+	}
+	last, err := database.GetLastAccess(urlID)
+	if err != nil {
+		logger.Warn("Failed to read last access, fetching", "url_id", urlID, "error", err)
+		return nil, false
+	}
+	if last == nil || !last.Success || time.Since(last.AccessedAt) > window {
+		return nil, false
+	}
+	rawHTML, found, err := manager.GetCachedRawHTMLByID(urlID)
+	if err != nil {
+		logger.Warn("Failed to read stored raw HTML, fetching", "url_id", urlID, "error", err)
+		return nil, false
+	}
+	return rawHTML, found
+}
+
+// countSkippedRecent returns how many results --skip-if-fetched-within served from storage.
+func countSkippedRecent(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Error == nil && r.SkippedRecent {
+			n++
+		}
+	}
+	return n
+}
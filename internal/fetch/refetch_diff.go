@@ -0,0 +1,126 @@
+package fetch
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	internaldb "github.com/dtnitsch/llm-web-parser/internal/db"
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/analytics"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
+	"github.com/dtnitsch/llm-web-parser/pkg/pagediff"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// RefetchDiffAction fetches a stored URL live, parses it the same way as the stored
+// version and reports what changed. The stored version is replaced only with --update.
+func RefetchDiffAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		fmt.Println("Error: URL ID or URL required")
+		fmt.Println()
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	urlID, err := internaldb.ResolveURLID(c.Args().First(), database)
+	if err != nil {
+		return err
+	}
+	url, err := database.GetURLByID(urlID)
+	if err != nil {
+		return fmt.Errorf("failed to get URL: %w", err)
+	}
+
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, 0)
+	if err != nil {
+		return fmt.Errorf("failed to initialize artifact manager: %w", err)
+	}
+
+	oldPage, found, err := manager.GetParsedPageByID(urlID)
+	if err != nil {
+		return fmt.Errorf("failed to read stored version: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("no stored version for URL ID %d; fetch it first with: llm-web-parser fetch --urls %q", urlID, url)
+	}
+
+	// Compare like with like: a minimal parse has no sections to diff against a full one
+	parseMode := extractionParseMode(oldPage.Metadata.ExtractionMode)
+	if c.IsSet("features") {
+		parseMode = ParseFeaturesFlag(c.String("features"))
+	}
+
+	rawHTML, meta, err := fetcher.NewFetcher().GetHtmlBytes(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+
+	job := Job{
+		URL:              url,
+		ParseMode:        parseMode,
+		CleanHTML:        c.Bool("clean-html"),
+		MinContentLength: c.Int("min-content-length"),
+		StoreKeywords:    c.Int("store-keywords"),
+	}
+	parsed := parseHTML(0, logger, job, rawHTML, &parser.Parser{}, &analytics.Analytics{}, nil)
+	if parsed.result.Error != nil {
+		return fmt.Errorf("failed to parse live page: %w", parsed.result.Error)
+	}
+
+	diff := pagediff.Compare(oldPage, parsed.result.Page)
+
+	var out []byte
+	if strings.ToLower(c.String("format")) == "json" {
+		out, err = json.MarshalIndent(diff, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(diff)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal diff: %w", err)
+	}
+	fmt.Print(string(out))
+
+	if !c.Bool("update") {
+		if diff.HasChanges() {
+			fmt.Fprintf(os.Stderr, "Stored version left unchanged; re-run with --update to save the live page\n")
+		}
+		return nil
+	}
+
+	storeRawHTML(logger, url, rawHTML, manager, database, urlID)
+	storeValidators(logger, database, urlID, meta)
+	if err := database.RecordAccess(urlID, meta.StatusCode, "", true); err != nil {
+		logger.Warn("Failed to record access to DB", "url", url, "error", err)
+	}
+	persistParsed(logger, &parsed, manager, database, urlID)
+	fmt.Fprintf(os.Stderr, "Stored live version of URL ID %d\n", urlID)
+	return nil
+}
+
+// extractionParseMode maps a stored page's extraction_mode back to the parse mode that produced it.
+func extractionParseMode(extractionMode string) models.ParseMode {
+	switch extractionMode {
+	case "full":
+		return models.ParseModeFull
+	case "cheap":
+		return models.ParseModeCheap
+	default:
+		return models.ParseModeMinimal
+	}
+}
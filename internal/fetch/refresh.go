@@ -0,0 +1,232 @@
+package fetch
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/dtnitsch/llm-web-parser/internal/common"
+	internaldb "github.com/dtnitsch/llm-web-parser/internal/db"
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/analytics"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/detector"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"github.com/urfave/cli/v2"
+)
+
+// RefreshStats counts the outcome of re-deriving a session from cached HTML.
+type RefreshStats struct {
+	Refreshed int
+	Skipped   int // No cached raw HTML
+	Failed    int
+}
+
+// RefreshAction re-parses every URL in a session from cached raw HTML and rewrites
+// generic.yaml, metadata and extractions without touching the network.
+func RefreshAction(c *cli.Context) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	database, err := common.OpenDB(c)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	sessionID, err := internaldb.GetSessionIDOrLatest(c, database)
+	if err != nil {
+		return err
+	}
+
+	sess, err := database.GetSessionByID(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	urls, err := database.GetSessionURLs(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session URLs: %w", err)
+	}
+
+	// --outdated narrows to pages whose generic.yaml predates the current parser
+	var current int
+	if c.Bool("outdated") {
+		outdated := urls[:0]
+		for _, u := range urls {
+			older, err := parsedByOlderParser(database, u.URLID)
+			if err != nil {
+				return fmt.Errorf("failed to read parse provenance for URL ID %d: %w", u.URLID, err)
+			}
+			if older {
+				outdated = append(outdated, u)
+			}
+		}
+		current = len(urls) - len(outdated)
+		urls = outdated
+	}
+
+	// Re-parse with the session's original features unless overridden
+	features := sess.Features
+	if c.IsSet("features") {
+		features = c.String("features")
+	}
+	parseMode := ParseFeaturesFlag(features)
+
+	// maxAge 0: cached HTML never counts as stale for a refresh
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, 0)
+	if err != nil {
+		return fmt.Errorf("failed to initialize artifact manager: %w", err)
+	}
+
+	workers := c.Int("workers")
+	if workers < 1 {
+		workers = 1
+	}
+
+	if c.Int("store-keywords") < 0 {
+		return fmt.Errorf("--store-keywords must be >= 0")
+	}
+
+	var trust *models.TrustConfig
+	if path := c.String("trust-config"); path != "" {
+		if trust, err = detector.LoadTrustConfig(path); err != nil {
+			return err
+		}
+	}
+
+	blockTags, err := parser.ParseBlockTags(c.String("block-tags"))
+	if err != nil {
+		return err
+	}
+
+	if selector := c.String("content-selector"); selector != "" {
+		if err := parser.ValidateContentSelector(selector); err != nil {
+			return err
+		}
+	}
+
+	parsedFormat, err := ParseParsedFormat(c.String("parsed-format"))
+	if err != nil {
+		return err
+	}
+
+	splitSections, err := ParseSectionSplit(c.Bool("split-sections"), c.String("section-format"), c.Int("max-section-tokens"))
+	if err != nil {
+		return err
+	}
+
+	chunking, err := ParseChunking(c.Bool("chunk"), c.Int("chunk-size"), c.Int("chunk-overlap"))
+	if err != nil {
+		return err
+	}
+
+	p := &parser.Parser{}
+	if path := c.String("confidence-config"); path != "" {
+		if p.Confidence, err = parser.LoadConfidenceConfig(path); err != nil {
+			return err
+		}
+	}
+
+	job := Job{
+		ParseMode:        parseMode,
+		CleanHTML:        c.Bool("clean-html"),
+		MinContentLength: c.Int("min-content-length"),
+		ContentSelector:  c.String("content-selector"),
+		StoreKeywords:    c.Int("store-keywords"),
+		Trust:            trust,
+		BlockTags:        blockTags,
+		KeepLineBreaks:   !c.Bool("canonicalize-whitespace"),
+		MaxSectionDepth:  c.Int("max-section-depth"),
+		SectionLanguages: c.Bool("detect-section-lang"),
+		ValidatePages:    c.Bool("validate"),
+		ParsedFormat:     parsedFormat,
+		SplitSections:    splitSections,
+		MaxSectionTokens: c.Int("max-section-tokens"),
+		ChunkSize:        chunking.Size,
+		ChunkOverlap:     chunking.Overlap,
+		LLMSummary:       c.Bool("llm-summary"),
+	}
+	outcomes := refreshParse(logger, manager, p, urls, job, workers)
+
+	// Parsing runs in the pool; all disk and DB writes happen here, one URL at a time
+	var stats RefreshStats
+	for outcome := range outcomes {
+		switch {
+		case outcome.skipped:
+			stats.Skipped++
+		case outcome.parsed.result.Error != nil:
+			fmt.Fprintf(os.Stderr, "Failed to refresh URL ID %d (%s): %v\n", outcome.url.URLID, outcome.url.OriginalURL, outcome.parsed.result.Error)
+			stats.Failed++
+		default:
+			persistParsed(logger, &outcome.parsed, manager, database, outcome.url.URLID)
+			stats.Refreshed++
+		}
+	}
+
+	fmt.Printf("Session %d refreshed from cache: %d refreshed, %d skipped (no cache), %d failed\n",
+		sessionID, stats.Refreshed, stats.Skipped, stats.Failed)
+	if c.Bool("outdated") {
+		fmt.Printf("%d URLs already parsed by parser %s were left as is\n", current, parser.Version)
+	}
+	if stats.Skipped > 0 {
+		if !sess.RawStored {
+			fmt.Printf("Session %d was fetched with --no-store-raw, so its raw HTML was never kept\n", sessionID)
+		}
+		fmt.Printf("Re-fetch skipped URLs with: llm-web-parser fetch --force-fetch --urls \"...\"\n")
+	}
+	return nil
+}
+
+// refreshOutcome is one URL's cached HTML after parsing, or a skip when nothing is cached.
+type refreshOutcome struct {
+	url     db.URLInfo
+	parsed  parsedHTML
+	skipped bool
+}
+
+// refreshParse loads cached HTML and parses it across workers goroutines. Parsing is
+// CPU-bound and independent per URL; the returned channel is closed once every URL is done.
+func refreshParse(logger *slog.Logger, manager *artifact_manager.Manager, p *parser.Parser, urls []db.URLInfo, job Job, workers int) <-chan refreshOutcome {
+	a := &analytics.Analytics{}
+
+	pending := make(chan db.URLInfo, len(urls))
+	for _, u := range urls {
+		pending <- u
+	}
+	close(pending)
+
+	outcomes := make(chan refreshOutcome, workers)
+	var wg sync.WaitGroup
+	for w := 1; w <= workers; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for u := range pending {
+				rawHTML, found, err := manager.GetRawHTMLByID(u.URLID)
+				if err == nil && !found {
+					rawHTML, found, err = manager.GetRawHTML(u.OriginalURL)
+				}
+				if err != nil {
+					logger.Warn("Failed to read cached HTML", "url_id", u.URLID, "url", u.OriginalURL, "error", err)
+				}
+				if !found {
+					outcomes <- refreshOutcome{url: u, skipped: true}
+					continue
+				}
+
+				urlJob := job
+				urlJob.URL = u.OriginalURL
+				outcomes <- refreshOutcome{url: u, parsed: parseHTML(id, logger, urlJob, rawHTML, p, a, nil)}
+			}
+		}(w)
+	}
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	return outcomes
+}
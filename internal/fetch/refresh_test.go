@@ -0,0 +1,51 @@
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+)
+
+// BenchmarkRefresh measures refreshParse over 200 cached pages, serially and with one
+// worker per CPU (at least two).
+func BenchmarkRefresh(b *testing.B) {
+	manager, err := artifact_manager.NewManager(b.TempDir(), 0)
+	if err != nil {
+		b.Fatalf("NewManager() error = %v", err)
+	}
+	urls := make([]db.URLInfo, 200)
+	for i := range urls {
+		page := guidePage
+		if i%2 == 1 {
+			page = releasePage
+		}
+		// Vary each page so no two parse to the same content
+		page = strings.Replace(page, "</body>", fmt.Sprintf("<p>Page %d of the benchmark corpus.</p></body>", i), 1)
+		urls[i] = db.URLInfo{URLID: int64(i + 1), OriginalURL: fmt.Sprintf("https://example.com/page/%d", i)}
+		if err := manager.SetRawHTMLByID(urls[i].URLID, []byte(page)); err != nil {
+			b.Fatalf("SetRawHTMLByID() error = %v", err)
+		}
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	job := Job{ParseMode: models.ParseModeFull}
+
+	for _, workers := range []int{1, max(runtime.NumCPU(), 2)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for outcome := range refreshParse(logger, manager, &parser.Parser{}, urls, job, workers) {
+					if outcome.skipped || outcome.parsed.result.Error != nil {
+						b.Fatalf("refreshParse() URL ID %d: skipped %v, error %v", outcome.url.URLID, outcome.skipped, outcome.parsed.result.Error)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package fetch
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
+)
+
+// Revalidator is implemented by fetchers that can check a URL's validators with a HEAD
+// request. Fetchers without it always fall back to modtime-based freshness.
+type Revalidator interface {
+	Head(url, userAgent string) (*fetcher.HTTPMetadata, error)
+}
+
+// validatorsNamespace is the url_metadata namespace holding the validators of the
+// response raw.html was stored from.
+const validatorsNamespace = "http"
+
+// storeValidators records the ETag, Last-Modified and Content-Length of the response just
+// stored, the User-Agent it was requested with, so revalidation asks the same way, and its
+// X-Robots-Tag lines, which a later cache hit cannot get from the HTML.
+func storeValidators(logger *slog.Logger, database *db.DB, urlID int64, meta *fetcher.HTTPMetadata) {
+	if database == nil || urlID <= 0 || meta == nil {
+		return
+	}
+
+	v := meta.Validators
+	values := map[string]string{
+		"etag":           v.ETag,
+		"last_modified":  v.LastModified,
+		"content_length": strconv.FormatInt(v.ContentLength, 10),
+		"user_agent":     meta.UserAgent,
+		"x_robots_tag":   strings.Join(meta.RobotsTags, "\n"),
+	}
+	// Write every key, even empty ones, so validators from an older response never linger
+	for key, value := range values {
+		if err := database.SetURLMetadata(urlID, validatorsNamespace, key, value); err != nil {
+			logger.Warn("Failed to store HTTP validator", "url_id", urlID, "key", key, "error", err)
+		}
+	}
+}
+
+// loadValidators returns the stored validators for a URL and the User-Agent they were
+// fetched with ("" when none was set). ContentLength is -1 when unknown.
+func loadValidators(database *db.DB, urlID int64) (fetcher.Validators, string, error) {
+	values, err := database.GetURLMetadata(urlID, validatorsNamespace)
+	if err != nil {
+		return fetcher.Validators{ContentLength: -1}, "", err
+	}
+
+	v := fetcher.Validators{ETag: values["etag"], LastModified: values["last_modified"], ContentLength: -1}
+	if n, err := strconv.ParseInt(values["content_length"], 10, 64); err == nil {
+		v.ContentLength = n
+	}
+	return v, values["user_agent"], nil
+}
+
+// loadRobotsTags returns the stored X-Robots-Tag lines of the response raw.html came from.
+func loadRobotsTags(database *db.DB, urlID int64) []string {
+	if database == nil || urlID <= 0 {
+		return nil
+	}
+	values, err := database.GetURLMetadata(urlID, validatorsNamespace)
+	if err != nil || values["x_robots_tag"] == "" {
+		return nil
+	}
+	return strings.Split(values["x_robots_tag"], "\n")
+}
+
+// revalidateCache decides whether cached raw HTML is current by comparing its stored
+// validators with a HEAD response, ignoring the file's modtime. decided is false when
+// there is nothing to compare (no cache, no stored or returned validators, HEAD failed);
+// the caller then falls back to maxAge.
+func revalidateCache(logger *slog.Logger, f Fetcher, manager *artifact_manager.Manager, database *db.DB, urlID int64, url string) (rawHTML []byte, fresh, decided bool) {
+	rv, ok := f.(Revalidator)
+	if !ok || database == nil || urlID <= 0 {
+		return nil, false, false
+	}
+
+	cached, found, err := manager.GetCachedRawHTMLByID(urlID)
+	if err != nil || !found {
+		return nil, false, false
+	}
+
+	stored, userAgent, err := loadValidators(database, urlID)
+	if err != nil {
+		logger.Warn("Failed to load HTTP validators", "url", url, "error", err)
+		return nil, false, false
+	}
+	if stored.Empty() {
+		return nil, false, false
+	}
+
+	meta, err := rv.Head(url, userAgent)
+	if err != nil {
+		logger.Info("HEAD revalidation failed, falling back to max-age", "url", url, "error", err)
+		return nil, false, false
+	}
+
+	match, ok := stored.Matches(meta.Validators)
+	if !ok {
+		return nil, false, false
+	}
+	if !match {
+		logger.Info("Cached HTML changed upstream", "url", url)
+		return nil, false, true
+	}
+	return cached, true, true
+}
@@ -0,0 +1,84 @@
+package fetch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
+	"gopkg.in/yaml.v3"
+)
+
+// sectionsDir holds the per-section files under a URL's directory.
+const sectionsDir = "sections/"
+
+// ParseSectionSplit validates --split-sections, --section-format and --max-section-tokens.
+// It returns the section file format, or "" when sections are not split.
+func ParseSectionSplit(split bool, format string, maxTokens int) (string, error) {
+	if maxTokens < 0 {
+		return "", fmt.Errorf("--max-section-tokens must be 0 or more, got %d", maxTokens)
+	}
+	if !split {
+		if maxTokens > 0 {
+			return "", fmt.Errorf("--max-section-tokens requires --split-sections")
+		}
+		return "", nil
+	}
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case "", chunk.FormatMarkdown:
+		return chunk.FormatMarkdown, nil
+	case chunk.FormatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown section format %q (use md or yaml)", format)
+	}
+}
+
+// ParseChunking validates --chunk, --chunk-size and --chunk-overlap. It returns a zero
+// size when pages are not chunked.
+func ParseChunking(enabled bool, size, overlap int) (chunk.ChunkOptions, error) {
+	if !enabled {
+		return chunk.ChunkOptions{}, nil
+	}
+	opts := chunk.ChunkOptions{Size: size, Overlap: overlap}
+	if err := opts.Validate(); err != nil {
+		return chunk.ChunkOptions{}, err
+	}
+	return opts, nil
+}
+
+// writeSections stores a page's section files and their manifest under
+// <url_id>/sections/, then removes files an earlier split wrote that this one did not.
+func writeSections(manager *artifact_manager.Manager, urlID int64, sections *chunk.Manifest) error {
+	sections.URLID = urlID
+	written := make(map[string]bool, len(sections.Sections))
+	for _, s := range sections.Sections {
+		data, err := sections.Render(s)
+		if err != nil {
+			return fmt.Errorf("failed to render section %s: %w", s.File, err)
+		}
+		if err := manager.SetURLArtifact(urlID, sectionsDir+s.File, data); err != nil {
+			return err
+		}
+		written[s.File] = true
+	}
+
+	if previous, ok, err := manager.GetURLArtifact(urlID, sectionsDir+chunk.ManifestFile); err == nil && ok {
+		var old chunk.Manifest
+		if yaml.Unmarshal(previous, &old) == nil {
+			for _, s := range old.Sections {
+				if !written[s.File] {
+					if err := manager.RemoveURLArtifact(urlID, sectionsDir+s.File); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	data, err := sections.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode section manifest: %w", err)
+	}
+	return manager.SetURLArtifact(urlID, sectionsDir+chunk.ManifestFile, data)
+}
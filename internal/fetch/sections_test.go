@@ -0,0 +1,71 @@
+package fetch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
+)
+
+func TestParseSectionSplit(t *testing.T) {
+	tests := []struct {
+		split     bool
+		format    string
+		maxTokens int
+		want      string
+		wantErr   bool
+	}{
+		{false, "md", 0, "", false},
+		{true, "", 0, chunk.FormatMarkdown, false},
+		{true, "YAML", 500, chunk.FormatYAML, false},
+		{true, "json", 0, "", true},
+		{false, "md", 500, "", true},
+		{true, "md", -1, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSectionSplit(tt.split, tt.format, tt.maxTokens)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseSectionSplit(%v, %q, %d) = %q, %v", tt.split, tt.format, tt.maxTokens, got, err)
+		}
+	}
+}
+
+func TestWriteSections_RemovesStaleFiles(t *testing.T) {
+	manager, err := artifact_manager.NewManager(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	section := func(heading string) models.Section {
+		return models.Section{Level: 2, Heading: &models.ContentBlock{Type: "h2", Text: heading},
+			Blocks: []models.ContentBlock{{Type: "p", Text: heading + " text."}}}
+	}
+	page := &models.Page{URL: "https://example.com/", Content: []models.Section{section("Old"), section("Kept")}}
+
+	if err := writeSections(manager, 3, chunk.SplitSections(page, 0, chunk.FormatMarkdown, 0)); err != nil {
+		t.Fatalf("writeSections() error = %v", err)
+	}
+	page.Content = page.Content[1:]
+	if err := writeSections(manager, 3, chunk.SplitSections(page, 0, chunk.FormatMarkdown, 0)); err != nil {
+		t.Fatalf("writeSections() error = %v", err)
+	}
+
+	for file, want := range map[string]bool{"old.md": false, "kept.md": true, chunk.ManifestFile: true} {
+		if _, ok, err := manager.GetURLArtifact(3, sectionsDir+file); err != nil || ok != want {
+			t.Errorf("sections/%s exists = %v (err %v), want %v", file, ok, err, want)
+		}
+	}
+}
+
+func TestParseChunking(t *testing.T) {
+	if opts, err := ParseChunking(false, 0, 99); err != nil || opts.Size != 0 {
+		t.Errorf("ParseChunking(off) = %+v, %v, want a zero size", opts, err)
+	}
+	if opts, err := ParseChunking(true, 512, 64); err != nil || opts != (chunk.ChunkOptions{Size: 512, Overlap: 64}) {
+		t.Errorf("ParseChunking(512, 64) = %+v, %v", opts, err)
+	}
+	if _, err := ParseChunking(true, 64, 64); err == nil {
+		t.Error("ParseChunking() with overlap == size: want an error")
+	}
+}
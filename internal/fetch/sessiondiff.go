@@ -0,0 +1,112 @@
+package fetch
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+// SessionDiff summarizes how a re-run session differs from the previous session of the
+// same URL set (fetch --diff-previous).
+type SessionDiff struct {
+	PreviousSessionID int64
+	NewlySucceeded    []string // Failed (or absent) before, succeeded now
+	NewlyFailed       []string // Succeeded before, failed now
+	ContentChanged    []string // Succeeded both times with a different parsed page
+}
+
+// sessionBaseline is the previous session's outcome, captured before a re-run
+// overwrites the shared per-URL artifacts.
+type sessionBaseline struct {
+	previousSessionID int64
+	statuses          map[int64]string
+	parsedHashes      map[int64]map[string]string
+}
+
+// captureSessionBaseline records the previous session's results and the parsed page
+// hashes it left behind. It returns nil when sessionID is the first run of its URL set.
+func captureSessionBaseline(database *db.DB, sessionID int64) (*sessionBaseline, error) {
+	previousID, found, err := database.FindPreviousSession(sessionID)
+	if err != nil || !found {
+		return nil, err
+	}
+	statuses, err := database.GetSessionStatuses(previousID)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := database.GetSessionParsedHashes(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionBaseline{previousSessionID: previousID, statuses: statuses, parsedHashes: hashes}, nil
+}
+
+// diffSession compares the finished session's results with the baseline.
+func (b *sessionBaseline) diffSession(database *db.DB, sessionID int64) (*SessionDiff, error) {
+	urls, err := database.GetSessionURLs(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := database.GetSessionStatuses(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := database.GetSessionParsedHashes(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	urlsByID := make(map[int64]string, len(urls))
+	for _, u := range urls {
+		urlsByID[u.URLID] = u.OriginalURL
+	}
+	diff := compareSessions(urlsByID, b.statuses, statuses, b.parsedHashes, hashes)
+	diff.PreviousSessionID = b.previousSessionID
+	return &diff, nil
+}
+
+// compareSessions classifies each URL by its status before and after, and, for URLs
+// that succeeded both times, by whether any parsed page encoding stored both times changed.
+func compareSessions(urls map[int64]string, before, after map[int64]string, beforeHashes, afterHashes map[int64]map[string]string) SessionDiff {
+	var diff SessionDiff
+	for urlID, url := range urls {
+		wasOK, isOK := succeeded(before[urlID]), succeeded(after[urlID])
+		switch {
+		case isOK && !wasOK:
+			diff.NewlySucceeded = append(diff.NewlySucceeded, url)
+		case wasOK && !isOK && after[urlID] != "":
+			diff.NewlyFailed = append(diff.NewlyFailed, url)
+		case wasOK && isOK:
+			for typeName, hash := range afterHashes[urlID] {
+				if old, ok := beforeHashes[urlID][typeName]; ok && old != hash {
+					diff.ContentChanged = append(diff.ContentChanged, url)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(diff.NewlySucceeded)
+	sort.Strings(diff.NewlyFailed)
+	sort.Strings(diff.ContentChanged)
+	return diff
+}
+
+// printSessionDiff writes the counts and, under each, the URLs it covers.
+func printSessionDiff(w io.Writer, diff *SessionDiff) {
+	fmt.Fprintf(w, "Changes since session %d: %d newly succeeded, %d newly failed, %d content changed\n",
+		diff.PreviousSessionID, len(diff.NewlySucceeded), len(diff.NewlyFailed), len(diff.ContentChanged))
+	for _, group := range []struct {
+		label string
+		urls  []string
+	}{
+		{"+", diff.NewlySucceeded},
+		{"-", diff.NewlyFailed},
+		{"~", diff.ContentChanged},
+	} {
+		for _, url := range group.urls {
+			fmt.Fprintf(w, "  %s %s\n", group.label, url)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package fetch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSessions(t *testing.T) {
+	urls := map[int64]string{1: "https://a.example", 2: "https://b.example", 3: "https://c.example", 4: "https://d.example", 5: "https://e.example"}
+	before := map[int64]string{1: "failed", 2: "success", 3: "success", 4: "success"}
+	after := map[int64]string{1: "success", 2: "failed", 3: "success", 4: "success", 5: "success"}
+	beforeHashes := map[int64]map[string]string{
+		3: {"yaml_parsed": "old"},
+		4: {"yaml_parsed": "same"},
+	}
+	afterHashes := map[int64]map[string]string{
+		3: {"yaml_parsed": "new"},
+		// Switching encodings is not a content change
+		4: {"yaml_parsed": "same", "json_parsed": "other"},
+	}
+
+	got := compareSessions(urls, before, after, beforeHashes, afterHashes)
+	want := SessionDiff{
+		NewlySucceeded: []string{"https://a.example", "https://e.example"},
+		NewlyFailed:    []string{"https://b.example"},
+		ContentChanged: []string{"https://c.example"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compareSessions() = %+v, want %+v", got, want)
+	}
+}
@@ -1,18 +1,25 @@
 package fetch
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"math"
-	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
 	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
 	"gopkg.in/yaml.v3"
 )
 
 func BuildSummary(r Result) ResultSummary {
+	if r.Error == nil && r.DuplicateOf != "" {
+		// The canonical result carries the details; a duplicate only points at it
+		return ResultSummary{URL: r.URL, Status: r.status(), DuplicateOf: r.DuplicateOf}
+	}
 	summary := ResultSummary{
 		URL:           r.URL,
 		FilePath:      r.FilePath,
@@ -22,10 +29,15 @@ func BuildSummary(r Result) ResultSummary {
 		summary.Status = "failed"
 		summary.Error = r.Error.Error()
 	} else {
-		summary.Status = "success"
-		summary.EstimatedTokens = int(math.Round(float64(r.Page.Metadata.WordCount) / 2.5))
+		summary.Status = r.status()
+		summary.EstimatedTokens = chunk.TokensForWords(r.Page.Metadata.WordCount)
 		summary.ContentType = r.Page.Metadata.ContentType
 		summary.ExtractionQuality = r.Page.Metadata.ExtractionQuality
+		summary.Paywalled = r.Page.Metadata.Paywalled
+		summary.Soft404 = r.Page.Metadata.Soft404
+		if robots := r.Page.Metadata.Robots; robots != nil {
+			summary.NoIndex, summary.NoFollow = robots.NoIndex, robots.NoFollow
+		}
 		summary.ConfidenceDist = ComputeConfidenceDist(r.Page)
 		summary.BlockTypeDist = ComputeBlockTypeDist(r.Page)
 	}
@@ -38,14 +50,21 @@ func BuildSummaryIndex(r Result) *SummaryIndex {
 		return nil // Only include successful fetches
 	}
 
-	return &SummaryIndex{
+	index := &SummaryIndex{
 		URL:    r.URL,
 		Cat:    r.Page.Metadata.DomainCategory,
 		Conf:   r.Page.Metadata.Confidence,
 		Title:  r.Page.Title,
 		Desc:   r.Page.Metadata.Excerpt,
-		Tokens: int(math.Round(float64(r.Page.Metadata.WordCount) / 2.5)),
+		Tokens: chunk.TokensForWords(r.Page.Metadata.WordCount),
+
+		Paywalled: r.Page.Metadata.Paywalled,
+		Soft404:   r.Page.Metadata.Soft404,
 	}
+	if robots := r.Page.Metadata.Robots; robots != nil {
+		index.NoIndex, index.NoFollow = robots.NoIndex, robots.NoFollow
+	}
+	return index
 }
 
 // buildSummaryDetails creates full details entry (all URLs)
@@ -53,6 +72,7 @@ func BuildSummaryDetails(r Result) SummaryDetails {
 	details := SummaryDetails{
 		URL:      r.URL,
 		FilePath: r.FilePath,
+		Referrer: r.Referrer,
 	}
 
 	if r.Error != nil {
@@ -61,11 +81,12 @@ func BuildSummaryDetails(r Result) SummaryDetails {
 		return details
 	}
 
-	details.Status = "success"
+	details.Status = r.status()
 	meta := r.Page.Metadata
 
 	// Basic metadata
 	details.Title = r.Page.Title
+	details.TitleSource = r.Page.Metadata.TitleSource
 	details.Excerpt = meta.Excerpt
 	details.SiteName = meta.SiteName
 	details.Author = meta.Author
@@ -90,21 +111,29 @@ func BuildSummaryDetails(r Result) SummaryDetails {
 
 	// Content metrics
 	details.WordCount = meta.WordCount
-	details.EstimatedTokens = int(math.Round(float64(meta.WordCount) / 2.5))
+	details.EstimatedTokens = chunk.TokensForWords(meta.WordCount)
 	details.ReadTimeMin = meta.EstimatedReadMin
 	details.Language = meta.Language
 	details.LanguageConfidence = meta.LanguageConfidence
 	details.ContentType = meta.ContentType
 	details.ExtractionMode = string(meta.ExtractionMode)
+	details.ContentSource = meta.ContentSource
+	details.DocumentKind = meta.DocumentKind
 	details.SectionCount = meta.SectionCount
 	details.BlockCount = meta.BlockCount
+	details.Paywalled = meta.Paywalled
+	details.Soft404 = meta.Soft404
+	if meta.Robots != nil {
+		details.NoIndex = meta.Robots.NoIndex
+		details.NoFollow = meta.Robots.NoFollow
+		details.RobotsSource = meta.Robots.Source
+	}
 
 	// Visual metadata (boolean/count only)
 	details.HasFavicon = meta.Favicon != ""
-	// NOTE: Image counting currently limited to featured/main image from metadata.
-	// Future enhancement: Parse and count <img> tags from content blocks in full-parse mode.
-	details.ImageCount = 0
-	if meta.Image != "" {
+	// Content images are counted in cheap/full modes; minimal mode only knows the featured image
+	details.ImageCount = meta.ImageCount
+	if details.ImageCount == 0 && meta.Image != "" {
 		details.ImageCount = 1 // At minimum, we have the main image
 	}
 
@@ -113,6 +142,8 @@ func BuildSummaryDetails(r Result) SummaryDetails {
 	details.FinalURL = meta.FinalURL
 	details.RedirectChain = meta.RedirectChain
 	details.HTTPContentType = meta.HTTPContentType
+	details.CanonicalURL = meta.CanonicalURL
+	details.Social = meta.Social
 
 	return details
 }
@@ -137,7 +168,7 @@ func WriteSummaryIndexToSession(results []Result, sessionDir string) error {
 	}
 
 	// Write to file
-	if err := os.WriteFile(outputPath, yamlBytes, 0600); err != nil {
+	if err := artifact_manager.WriteFileAtomic(outputPath, yamlBytes, 0600); err != nil {
 		return fmt.Errorf("failed to write index file: %w", err)
 	}
 
@@ -153,6 +184,9 @@ func WriteSummaryDetailsToSession(results []Result, sessionDir string, database
 		// Add URL ID from database
 		if urlID, err := database.GetURLID(r.URL); err == nil {
 			detail.URLID = urlID
+			if duplicateOf, err := database.GetDuplicateOf(urlID); err == nil {
+				detail.DuplicateOf = duplicateOf
+			}
 		}
 		details = append(details, detail)
 	}
@@ -167,7 +201,7 @@ func WriteSummaryDetailsToSession(results []Result, sessionDir string, database
 	}
 
 	// Write to file
-	if err := os.WriteFile(outputPath, yamlBytes, 0600); err != nil {
+	if err := artifact_manager.WriteFileAtomic(outputPath, yamlBytes, 0600); err != nil {
 		return fmt.Errorf("failed to write details file: %w", err)
 	}
 
@@ -210,10 +244,11 @@ func collectFailedURLs(results []Result) []FailedURL {
 	for _, r := range results {
 		if r.Error != nil {
 			failedURL := FailedURL{
-				URL:          r.URL,
-				StatusCode:   0, // Default to 0 for network errors
-				ErrorType:    r.ErrorType,
-				ErrorMessage: r.Error.Error(),
+				URL:           r.URL,
+				StatusCode:    fetcher.StatusCode(r.Error), // 0 for network errors
+				ErrorType:     r.ErrorType,
+				ErrorCategory: errorCategory(r),
+				ErrorMessage:  r.Error.Error(),
 			}
 
 			// Try to get status code if available from page metadata
@@ -245,6 +280,42 @@ func collectFailedURLs(results []Result) []FailedURL {
 	return failed
 }
 
+// errorCategory classifies a failed result: failures after the fetch by their pipeline
+// stage, fetch failures by their typed cause (fetcher.Categorize).
+func errorCategory(r Result) string {
+	switch r.ErrorType {
+	case "timeout":
+		return fetcher.CategoryTimeout
+	case "parse_error", "no_content", "soft_404", "marshal_error":
+		return r.ErrorType
+	}
+	return fetcher.Categorize(r.Error)
+}
+
+// writeFailedURLsFile writes failed URLs to path for --failed-urls-file: JSON when the
+// path ends in .json, YAML otherwise. Unlike the session's failed-urls.yaml it is written
+// even when nothing failed, with an empty list, so a file left by an earlier run is never
+// mistaken for this one's failures.
+func writeFailedURLsFile(path string, failed []FailedURL) error {
+	failedURLs := FailedURLs{FailedURLs: failed}
+	if failedURLs.FailedURLs == nil {
+		failedURLs.FailedURLs = []FailedURL{}
+	}
+
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(&failedURLs, "", "  ")
+	} else {
+		data, err = yaml.Marshal(&failedURLs)
+		data = bytes.TrimSuffix(data, []byte("\n"))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal failed URLs: %w", err)
+	}
+	return writeOutputFile(path, data)
+}
+
 // writeFailedURLsToSession writes failed URLs to failed-urls.yaml in the session directory.
 func WriteFailedURLsToSession(failed []FailedURL, sessionDir string) error {
 	if len(failed) == 0 {
@@ -262,7 +333,7 @@ func WriteFailedURLsToSession(failed []FailedURL, sessionDir string) error {
 		return fmt.Errorf("failed to marshal failed URLs to YAML: %w", err)
 	}
 
-	if err := os.WriteFile(outputPath, yamlBytes, 0600); err != nil {
+	if err := artifact_manager.WriteFileAtomic(outputPath, yamlBytes, 0600); err != nil {
 		return fmt.Errorf("failed to write failed URLs file: %w", err)
 	}
 
@@ -2,7 +2,7 @@ package fetch
 
 
 func ToTerseStatus(status string) int {
-	if status == "success" {
+	if succeeded(status) {
 		return 0
 	}
 	return 1
@@ -49,6 +49,7 @@ func ToTerseResult(r ResultSummary) ResultSummaryTerse {
 		ExtractionQuality: ToTerseQuality(r.ExtractionQuality),
 		ConfidenceDist:    [3]int{r.ConfidenceDist["high"], r.ConfidenceDist["medium"], r.ConfidenceDist["low"]},
 		BlockTypeDist:     r.BlockTypeDist,
+		DuplicateOf:       r.DuplicateOf,
 	}
 }
 
@@ -60,6 +61,7 @@ func ToTerseStats(s Stats) StatsTerse {
 		Failed:   s.Failed,
 		Time:     s.TotalTimeSeconds,
 		Keywords: s.TopKeywords,
+		Dupes:    s.Duplicates,
 	}
 }
 
@@ -0,0 +1,49 @@
+package fetch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// exitTimeout is the exit code when --timeout-overall cuts a run short, as with timeout(1).
+const exitTimeout = 124
+
+// errTimeoutOverall marks URLs the run never got to before its budget ran out.
+var errTimeoutOverall = errors.New("not processed: --timeout-overall budget exceeded")
+
+// unfinishedResults returns a timeout failure for each URL in urls without a result.
+func unfinishedResults(urls []string, results []Result) []Result {
+	done := make(map[string]int, len(results))
+	for _, r := range results {
+		done[r.URL]++
+	}
+	var unfinished []Result
+	for _, url := range urls {
+		if done[url] > 0 {
+			done[url]--
+			continue
+		}
+		unfinished = append(unfinished, Result{URL: url, Error: errTimeoutOverall, ErrorType: "timeout"})
+	}
+	return unfinished
+}
+
+// printTimeoutReport tells how far a run got before --timeout-overall stopped it and,
+// for a database session (sessionID > 0), how to finish the rest.
+func printTimeoutReport(results []Result, budget time.Duration, sessionID int64) {
+	completed, cutOff := 0, 0
+	for _, r := range results {
+		if r.ErrorType == "timeout" {
+			cutOff++
+		} else {
+			completed++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Timed out after %s: %d of %d URLs completed, %d cut off\n",
+		budget, completed, len(results), cutOff)
+	if sessionID > 0 && cutOff > 0 {
+		fmt.Fprintf(os.Stderr, "Retry the rest with: llm-web-parser fetch --session %d --failed-only\n", sessionID)
+	}
+}
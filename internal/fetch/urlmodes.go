@@ -0,0 +1,62 @@
+package fetch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// inlineParseModes are the modes a --urls entry may pick with a "|mode" suffix.
+var inlineParseModes = map[string]models.ParseMode{
+	"minimal": models.ParseModeMinimal,
+	"cheap":   models.ParseModeCheap,
+	"full":    models.ParseModeFull,
+}
+
+// modeWord matches a suffix meant as a mode name, as opposed to a "|" inside the URL itself.
+var modeWord = regexp.MustCompile(`^[A-Za-z-]+$`)
+
+// splitURLModes strips inline parse modes from --urls entries such as
+// "https://example.com/docs|full". It returns the bare URLs and, aligned with them,
+// each entry's mode (nil where the entry has none and the --features mode applies).
+func splitURLModes(entries []string) ([]string, []*models.ParseMode, error) {
+	urls := make([]string, len(entries))
+	modes := make([]*models.ParseMode, len(entries))
+	for i, entry := range entries {
+		urls[i] = entry
+		sep := strings.LastIndex(entry, "|")
+		if sep < 0 {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(entry[sep+1:]))
+		mode, ok := inlineParseModes[name]
+		if !ok {
+			if modeWord.MatchString(name) {
+				return nil, nil, fmt.Errorf("unknown parse mode %q in %q (supported: minimal, cheap, full)", name, entry)
+			}
+			continue
+		}
+		urls[i] = entry[:sep]
+		modes[i] = &mode
+	}
+	return urls, modes, nil
+}
+
+// parseModeName returns the session's parse_mode label: the mode's name, or "mixed"
+// when per-URL modes differ from it.
+func parseModeName(mode models.ParseMode, urlModes map[string]models.ParseMode) string {
+	for _, m := range urlModes {
+		if m != mode {
+			return "mixed"
+		}
+	}
+	switch mode {
+	case models.ParseModeCheap:
+		return "cheap"
+	case models.ParseModeFull:
+		return "full"
+	}
+	return "minimal"
+}
@@ -0,0 +1,82 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher/fetchertest"
+)
+
+func TestSplitURLModes(t *testing.T) {
+	urls, modes, err := splitURLModes([]string{
+		"https://example.com/docs|full",
+		"https://example.com/landing|Cheap",
+		"https://example.com/plain",
+		"https://example.com/search?ids=1|2", // A pipe in the URL, not a mode
+	})
+	if err != nil {
+		t.Fatalf("splitURLModes() error = %v", err)
+	}
+	wantURLs := []string{"https://example.com/docs", "https://example.com/landing", "https://example.com/plain", "https://example.com/search?ids=1|2"}
+	if !reflect.DeepEqual(urls, wantURLs) {
+		t.Errorf("urls = %v, want %v", urls, wantURLs)
+	}
+	if modes[0] == nil || *modes[0] != models.ParseModeFull || modes[1] == nil || *modes[1] != models.ParseModeCheap {
+		t.Errorf("modes = %v, want full and cheap for the first two entries", modes)
+	}
+	if modes[2] != nil || modes[3] != nil {
+		t.Errorf("modes = %v, want none for entries without a mode", modes)
+	}
+
+	if _, _, err := splitURLModes([]string{"https://example.com/docs|fulll"}); err == nil {
+		t.Error("splitURLModes(unknown mode) error = nil, want an error")
+	}
+}
+
+func TestParseModeName(t *testing.T) {
+	if got := parseModeName(models.ParseModeCheap, nil); got != "cheap" {
+		t.Errorf("parseModeName(cheap) = %q", got)
+	}
+	same := map[string]models.ParseMode{"https://example.com/": models.ParseModeFull}
+	if got := parseModeName(models.ParseModeFull, same); got != "full" {
+		t.Errorf("parseModeName(full, all full) = %q, want full", got)
+	}
+	if got := parseModeName(models.ParseModeMinimal, same); got != "mixed" {
+		t.Errorf("parseModeName(minimal, one full) = %q, want mixed", got)
+	}
+}
+
+func TestRun_PerURLParseMode(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		guideURL   = "https://example.com/guide"
+		releaseURL = "https://example.com/release"
+	)
+	fake := fetchertest.New(map[string]string{guideURL: guidePage, releaseURL: releasePage})
+	config := &models.FetchConfig{
+		URLs:          []string{guideURL, releaseURL},
+		URLParseModes: map[string]models.ParseMode{guideURL: models.ParseModeFull},
+		WorkerCount:   2,
+	}
+
+	results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeMinimal, nil, database)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	modes := make(map[string]string)
+	for _, r := range results {
+		if r.Page == nil {
+			t.Fatalf("%s: no page (error %v)", r.URL, r.Error)
+		}
+		modes[r.URL] = r.Page.Metadata.ExtractionMode
+	}
+	if modes[guideURL] != "full" || modes[releaseURL] != "minimal" {
+		t.Errorf("extraction modes = %v, want guide full and release minimal (--features default)", modes)
+	}
+}
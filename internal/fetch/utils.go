@@ -1,9 +1,15 @@
 package fetch
 
 import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 )
 
 func ParseFeaturesFlag(features string) models.ParseMode {
@@ -27,3 +33,40 @@ func ParseFeaturesFlag(features string) models.ParseMode {
 	// If no recognized features, default to minimal
 	return models.ParseModeMinimal
 }
+
+// SelectURLSubset returns the indices of the URLs to process out of n.
+// limit keeps the first N; sample keeps a random N (reproducible for a given seed),
+// returned in original order. Zero values mean "no restriction".
+func SelectURLSubset(n, limit, sample int, seed int64) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	if sample > 0 && sample < n {
+		// #nosec G404 -- sampling for test runs, not security sensitive
+		rng := rand.New(rand.NewSource(seed))
+		indices = rng.Perm(n)[:sample]
+		sort.Ints(indices)
+	}
+
+	if limit > 0 && limit < len(indices) {
+		indices = indices[:limit]
+	}
+
+	return indices
+}
+
+// writeOutputFile writes the final output payload to path, creating parent directories.
+func writeOutputFile(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	// #nosec G306 -- output is the same payload printed to stdout
+	if err := artifact_manager.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
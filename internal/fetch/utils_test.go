@@ -0,0 +1,58 @@
+package fetch
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSelectURLSubset(t *testing.T) {
+	tests := []struct {
+		name             string
+		n, limit, sample int
+		want             []int
+	}{
+		{"no restriction", 4, 0, 0, []int{0, 1, 2, 3}},
+		{"limit keeps the first", 5, 2, 0, []int{0, 1}},
+		{"limit at the length", 3, 3, 0, []int{0, 1, 2}},
+		{"limit over the length", 3, 10, 0, []int{0, 1, 2}},
+		{"sample at the length", 3, 0, 3, []int{0, 1, 2}},
+		{"sample over the length", 3, 0, 10, []int{0, 1, 2}},
+		{"no URLs", 0, 2, 2, []int{}},
+	}
+	for _, tt := range tests {
+		if got := SelectURLSubset(tt.n, tt.limit, tt.sample, 1); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SelectURLSubset(%d, %d, %d) = %v, want %v", tt.name, tt.n, tt.limit, tt.sample, got, tt.want)
+		}
+	}
+}
+
+func TestSelectURLSubset_SeededSample(t *testing.T) {
+	first := SelectURLSubset(100, 0, 10, 42)
+	if len(first) != 10 {
+		t.Fatalf("sample of 10 returned %d indices", len(first))
+	}
+	if !sort.IntsAreSorted(first) {
+		t.Errorf("sample %v is not in original order", first)
+	}
+	seen := make(map[int]bool)
+	for _, i := range first {
+		if i < 0 || i >= 100 || seen[i] {
+			t.Fatalf("sample %v has an out-of-range or repeated index", first)
+		}
+		seen[i] = true
+	}
+
+	// The same seed picks the same URLs; another seed (almost surely) does not
+	if again := SelectURLSubset(100, 0, 10, 42); !reflect.DeepEqual(again, first) {
+		t.Errorf("same seed sampled %v, then %v", first, again)
+	}
+	if other := SelectURLSubset(100, 0, 10, 7); reflect.DeepEqual(other, first) {
+		t.Errorf("seeds 42 and 7 sampled the same URLs %v", first)
+	}
+
+	// --limit-urls applies after sampling
+	if got := SelectURLSubset(100, 3, 10, 42); !reflect.DeepEqual(got, first[:3]) {
+		t.Errorf("sample 10 then limit 3 = %v, want %v", got, first[:3])
+	}
+}
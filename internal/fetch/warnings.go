@@ -0,0 +1,119 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Stages a Warning can come from.
+const (
+	stageFetch = "fetch" // URL tracking, cache checks, access records
+	stageParse = "parse" // Parsing, validation and enrichment
+	stageStore = "store" // Artifact writes and database metadata
+)
+
+// warningRecorder keeps the warnings logged while processing one URL, so they reach the
+// output as well as the log.
+type warningRecorder struct {
+	mu       sync.Mutex
+	url      string
+	warnings []Warning
+}
+
+// recordWarnings returns a logger that also records every Warn-level message as a Warning
+// for url, starting in the fetch stage, even when the log level hides them.
+func recordWarnings(logger *slog.Logger, url string) (*slog.Logger, *warningRecorder) {
+	rec := &warningRecorder{url: url}
+	return slog.New(&warningHandler{Handler: logger.Handler(), rec: rec, stage: stageFetch}), rec
+}
+
+// attach returns r carrying the warnings recorded so far.
+func (rec *warningRecorder) attach(r Result) Result {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	r.Warnings = append(r.Warnings, rec.warnings...)
+	return r
+}
+
+// withStage attributes the warnings logger records to stage. Loggers that don't record
+// warnings (refresh, refetch-diff) are returned unchanged.
+func withStage(logger *slog.Logger, stage string) *slog.Logger {
+	h, ok := logger.Handler().(*warningHandler)
+	if !ok {
+		return logger
+	}
+	return slog.New(&warningHandler{Handler: h.Handler, rec: h.rec, stage: stage, attrs: h.attrs})
+}
+
+// warningHandler passes records on to the wrapped handler and records Warn-level ones.
+type warningHandler struct {
+	slog.Handler
+	rec   *warningRecorder
+	stage string
+	attrs []slog.Attr // From With, searched for the error like the record's own
+}
+
+func (h *warningHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level == slog.LevelWarn || h.Handler.Enabled(ctx, level)
+}
+
+func (h *warningHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelWarn {
+		h.record(r)
+	}
+	if !h.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *warningHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warningHandler{Handler: h.Handler.WithAttrs(attrs), rec: h.rec, stage: h.stage, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *warningHandler) WithGroup(name string) slog.Handler {
+	return &warningHandler{Handler: h.Handler.WithGroup(name), rec: h.rec, stage: h.stage, attrs: h.attrs}
+}
+
+// record adds r as a Warning, with the logged error appended to its message.
+func (h *warningHandler) record(r slog.Record) {
+	message := r.Message
+	errorAttr := func(a slog.Attr) bool {
+		if a.Key == "error" {
+			message = fmt.Sprintf("%s: %s", r.Message, a.Value.String())
+			return false
+		}
+		return true
+	}
+	for _, a := range h.attrs {
+		errorAttr(a)
+	}
+	r.Attrs(errorAttr)
+
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+	h.rec.warnings = append(h.rec.warnings, Warning{URL: h.rec.url, Stage: h.stage, Message: message})
+}
+
+// collectWarnings gathers every result's warnings, in result order.
+func collectWarnings(results []Result) []Warning {
+	var warnings []Warning
+	for _, r := range results {
+		warnings = append(warnings, r.Warnings...)
+	}
+	return warnings
+}
+
+// toTerseWarnings converts warnings to the v2 format.
+func toTerseWarnings(warnings []Warning) []WarningTerse {
+	if len(warnings) == 0 {
+		return nil
+	}
+	terse := make([]WarningTerse, len(warnings))
+	for i, w := range warnings {
+		terse[i] = WarningTerse{URL: w.URL, Stage: w.Stage, Message: w.Message}
+	}
+	return terse
+}
@@ -0,0 +1,65 @@
+package fetch
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordWarnings(t *testing.T) {
+	var logged bytes.Buffer
+	base := slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelError}))
+	logger, rec := recordWarnings(base, "https://example.com/a")
+
+	logger.Info("Worker started job")
+	logger.Warn("Failed to record access to DB", "url", "https://example.com/a", "error", errors.New("database is locked"))
+	withStage(logger, stageStore).With("error", "disk full").Warn("Failed to write wordcount.txt")
+	withStage(logger, stageParse).Warn("Parsed page failed validation")
+	logger.Error("Error fetching HTML", "error", errors.New("timeout"))
+
+	got := rec.attach(Result{URL: "https://example.com/a"}).Warnings
+	want := []Warning{
+		{URL: "https://example.com/a", Stage: stageFetch, Message: "Failed to record access to DB: database is locked"},
+		{URL: "https://example.com/a", Stage: stageStore, Message: "Failed to write wordcount.txt: disk full"},
+		{URL: "https://example.com/a", Stage: stageParse, Message: "Parsed page failed validation"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("warnings = %+v, want %+v", got, want)
+	}
+
+	// The wrapped handler's level still decides what is logged
+	if strings.Contains(logged.String(), "Failed to") {
+		t.Errorf("warnings were logged below the handler's level: %s", logged.String())
+	}
+	if !strings.Contains(logged.String(), "Error fetching HTML") {
+		t.Errorf("error was not logged: %s", logged.String())
+	}
+}
+
+func TestWithStage_PlainLogger(t *testing.T) {
+	logger := slog.Default()
+	if got := withStage(logger, stageStore); got != logger {
+		t.Error("withStage() changed a logger that records no warnings")
+	}
+}
+
+func TestCollectWarnings(t *testing.T) {
+	results := []Result{
+		{URL: "https://example.com/a", Warnings: []Warning{{URL: "https://example.com/a", Stage: stageStore, Message: "a"}}},
+		{URL: "https://example.com/b"},
+		{URL: "https://example.com/c", Warnings: []Warning{{URL: "https://example.com/c", Stage: stageFetch, Message: "c"}}},
+	}
+	got := collectWarnings(results)
+	if len(got) != 2 || got[0].Message != "a" || got[1].Message != "c" {
+		t.Errorf("collectWarnings() = %+v, want the warnings of a then c", got)
+	}
+	if terse := toTerseWarnings(got); len(terse) != 2 || terse[1].URL != "https://example.com/c" {
+		t.Errorf("toTerseWarnings() = %+v", terse)
+	}
+	if toTerseWarnings(nil) != nil {
+		t.Error("toTerseWarnings(nil) should be nil so the field is omitted")
+	}
+}
@@ -1,21 +1,22 @@
 package fetch
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/dtnitsch/llm-web-parser/internal/common"
 	"github.com/dtnitsch/llm-web-parser/models"
 	"github.com/dtnitsch/llm-web-parser/pkg/analytics"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
 	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
 	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/enrich"
 	"github.com/dtnitsch/llm-web-parser/pkg/extractor"
 	"github.com/dtnitsch/llm-web-parser/pkg/extractors"
 	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
@@ -24,115 +25,284 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// formatKeywordsAsJSON formats word counts as JSON array for database storage.
-// Uses existing mapreduce.TopKeywords() to get top N keywords.
-func formatKeywordsAsJSON(counts map[string]int, limit int) string {
-	keywords := mapreduce.TopKeywords(counts, limit)
-	jsonBytes, err := json.Marshal(keywords)
-	if err != nil {
-		return ""
-	}
-	return string(jsonBytes)
+// Fetcher retrieves raw HTML for a URL. *fetcher.Fetcher is the network implementation;
+// tests pass fetchertest.Fetcher to run the pipeline on canned pages.
+type Fetcher interface {
+	GetHtmlBytes(url string) ([]byte, *fetcher.HTTPMetadata, error)
 }
 
-// formatMetaKeywordsAsJSON formats meta keywords as JSON array for database storage.
-func formatMetaKeywordsAsJSON(keywords []string) string {
-	if len(keywords) == 0 {
-		return ""
-	}
-	jsonBytes, err := json.Marshal(keywords)
-	if err != nil {
-		return ""
+// newFetcher builds the network fetcher from the retry, circuit breaker, per-host
+// concurrency and User-Agent settings in config, logging each host's concurrency changes.
+// Its requests are cancelled when ctx is done. FetchAction validates the rotation mode
+// and concurrency bounds before calling it.
+func newFetcher(ctx context.Context, logger *slog.Logger, config *models.FetchConfig) *fetcher.Fetcher {
+	var agents *fetcher.UserAgentPool
+	if len(config.UserAgents) > 0 {
+		agents, _ = fetcher.NewUserAgentPool(config.UserAgents, config.UserAgentRotate)
 	}
-	return string(jsonBytes)
+	return fetcher.NewFetcherWithOptions(fetcher.Options{
+		Retry: fetcher.RetryPolicy{
+			MaxRetries:    config.MaxRetries,
+			BaseDelay:     config.RetryBaseDelay,
+			MaxDelay:      30 * time.Second,
+			MaxRetryAfter: config.MaxRetryAfter,
+		},
+		BreakerThreshold: config.BreakerThreshold,
+		BreakerCooldown:  config.BreakerCooldown,
+		UserAgents:       agents,
+		HostConcurrency:  hostConcurrency(logger, config),
+		Context:          ctx,
+	})
 }
 
-// formatWordCountsSorted formats word counts as sorted plain text.
-// Format: "word:count\n" sorted by count descending for easy parsing.
-func formatWordCountsSorted(counts map[string]int) string {
-	type kv struct {
-		word  string
-		count int
+// hostConcurrency returns the per-host concurrency bounds from config. Unset initial and
+// maximum limits default to the worker count, so a host is only held back once it throttles.
+func hostConcurrency(logger *slog.Logger, config *models.FetchConfig) fetcher.HostConcurrency {
+	initial := config.HostConcurrency
+	if initial <= 0 {
+		initial = max(config.WorkerCount, 1)
 	}
-
-	sorted := make([]kv, 0, len(counts))
-	for w, c := range counts {
-		sorted = append(sorted, kv{word: w, count: c})
+	maxLimit := config.HostConcurrencyMax
+	if maxLimit <= 0 {
+		maxLimit = max(initial, config.WorkerCount)
 	}
-
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].count > sorted[j].count
-	})
-
-	var sb strings.Builder
-	for _, item := range sorted {
-		fmt.Fprintf(&sb, "%s:%d\n", item.word, item.count)
+	return fetcher.HostConcurrency{
+		Initial: initial,
+		Min:     config.HostConcurrencyMin,
+		Max:     maxLimit,
+		OnChange: func(change fetcher.HostLimitChange) {
+			if change.StatusCode != 0 {
+				logger.Warn("Host throttled, reducing its concurrency", "host", change.Host, "status", change.StatusCode,
+					"concurrency", change.Limit, "previous_concurrency", change.PrevLimit, "crawl_delay", change.Delay)
+				return
+			}
+			logger.Info("Host recovering, restoring its concurrency", "host", change.Host,
+				"concurrency", change.Limit, "previous_concurrency", change.PrevLimit, "crawl_delay", change.Delay)
+		},
 	}
-	return sb.String()
 }
 
-func run(logger *slog.Logger, config *models.FetchConfig, manager *artifact_manager.Manager, forceFetch bool, parseMode models.ParseMode, filterStrategy *extractor.Strategy, database *db.DB) ([]Result, map[string]int, error) {
-	f := fetcher.NewFetcher()
-	p := &parser.Parser{}
-	a := &analytics.Analytics{}
+// run fetches and processes every URL in config. A nil f uses the network fetcher.
+// Once ctx is done, workers stop taking jobs and every URL left without a result
+// fails as a timeout; f should share ctx so that in-flight fetches stop too.
+func run(ctx context.Context, logger *slog.Logger, config *models.FetchConfig, manager *artifact_manager.Manager, f Fetcher, forceFetch bool, parseMode models.ParseMode, filterStrategy *extractor.Strategy, database *db.DB) ([]Result, map[string]int, error) {
+	if f == nil {
+		f = newFetcher(ctx, logger, config)
+	}
+	p := &parser.Parser{Confidence: config.Confidence}
+	a := &analytics.Analytics{Stopwords: config.Stopwords}
 
 	logger.Info("Starting concurrent fetch phase", "url_count", len(config.URLs), "workers", config.WorkerCount, "force_fetch", forceFetch, "max_age", manager.MaxAge())
 	var wg sync.WaitGroup
-	jobs := make(chan Job, len(config.URLs))
-	results := make(chan Result, len(config.URLs))
+	// Room for every job up front, so queueing discovered links never blocks the result loop
+	queueSize := len(config.URLs)
+	if config.FollowDepth > 0 {
+		queueSize = max(queueSize, config.MaxURLs)
+	}
+	jobs := make(chan Job, queueSize)
+	results := make(chan Result, queueSize)
+
+	// --workers auto also sheds workers when fetches fail in bulk
+	var tuner *workerTuner
+	if config.WorkersAuto {
+		tuner = newWorkerTuner(config.WorkerCount)
+	}
 
 	for w := 1; w <= config.WorkerCount; w++ {
 		wg.Add(1)
-		go worker(w, logger, manager, f, p, a, &wg, jobs, results, forceFetch, filterStrategy, database)
+		go worker(ctx, w, logger, manager, f, p, a, &wg, jobs, results, forceFetch, filterStrategy, database, tuner)
+	}
+
+	// One enrichment client for all workers, so a paper cited by many pages is looked up once
+	var enricher *enrich.Client
+	if config.EnrichAcademic {
+		enricher = enrich.NewClient(manager.EnrichCacheDir())
 	}
 
+	// --follow-internal queues links from each parsed page; the queue closes once every
+	// queued URL has a result. Without it every job is known up front.
+	var crawl *crawler
+	if config.FollowDepth > 0 {
+		crawl = newCrawler(config.URLs, config.FollowDepth, max(config.MaxURLs, len(config.URLs)))
+	}
+	newJob := func(rawURL string) Job {
+		mode := parseMode
+		if m, ok := config.URLParseModes[rawURL]; ok {
+			mode = m
+		}
+		return Job{URL: rawURL, ParseMode: mode, CleanHTML: config.CleanHTML, MinContentLength: config.MinContentLength, ContentSelector: config.ContentSelector, StoreKeywords: config.StoreKeywords, ParsedFormat: config.ParsedFormat, SplitSections: config.SplitSections, MaxSectionTokens: config.MaxSectionTokens, ChunkSize: config.ChunkSize, ChunkOverlap: config.ChunkOverlap, LLMSummary: config.LLMSummary, Revalidate: config.Revalidate, Trust: config.Trust, BlockTags: config.BlockTags, KeepLineBreaks: config.KeepLineBreaks, MaxSectionDepth: config.MaxSectionDepth, SectionLanguages: config.SectionLanguages, ValidatePages: config.ValidatePages, FailSoft404: config.FailSoft404, Enricher: enricher, FollowLinks: crawl.follows(rawURL), NoStoreRaw: config.NoStoreRaw, SkipRecent: config.SkipIfFetchedWithin}
+	}
 	for _, rawURL := range config.URLs {
-		jobs <- Job{URL: rawURL, ParseMode: parseMode}
+		jobs <- newJob(rawURL)
+	}
+	pending := len(config.URLs)
+	if crawl == nil {
+		close(jobs)
 	}
-	close(jobs)
 
-	wg.Wait()
-	close(results)
-	logger.Info("All fetch workers finished")
+	go func() {
+		wg.Wait()
+		close(results)
+		logger.Info("All fetch workers finished")
+	}()
 
+	// Fold word counts as results arrive and drop each page's map once counted, so
+	// peak memory holds one aggregate rather than every page's vocabulary
 	allResults := make([]Result, 0, len(config.URLs))
+	reducer := mapreduce.NewReducer()
+	// --dedupe-output counts each distinct content's words once, under its earliest URL
+	// in the input. Which pages are duplicates is only known once all are in, so their
+	// counts are kept until then.
+	var deduper *contentDeduper
+	if config.DedupeThreshold > 0 {
+		deduper = newContentDeduper(config.DedupeThreshold)
+	}
 	var runErr error
 	for result := range results {
-		allResults = append(allResults, result)
 		if result.Error != nil {
 			runErr = fmt.Errorf("one or more jobs failed")
 		}
+		if allowed, rampedDown := tuner.observe(result); rampedDown {
+			logger.Warn("High fetch error rate, reducing workers", "workers", allowed)
+		}
 		if result.Page != nil && !result.Page.Metadata.Computed {
 			result.Page.ComputeMetadata()
 		}
+		if deduper == nil {
+			reducer.Add(result.WordCounts)
+			result.WordCounts, result.DisplayForms = nil, nil
+		}
+
+		if crawl != nil {
+			pending--
+			if ctx.Err() == nil {
+				for _, link := range crawl.discover(result) {
+					jobs <- newJob(link)
+					pending++
+				}
+			}
+			if pending == 0 {
+				close(jobs)
+			}
+			result.Depth, result.Referrer = crawl.origin(result.URL)
+			result.links = nil
+		}
+		allResults = append(allResults, result)
 	}
+	if deduper != nil {
+		dedupeInOrder(deduper, allResults, config.URLs, reducer)
+	}
+	finalWordCounts := reducer.Result()
 
-	logger.Info("Starting MapReduce phase")
-	intermediateResults := []map[string]int{}
-	for _, result := range allResults {
-		if result.WordCounts != nil {
-			intermediateResults = append(intermediateResults, result.WordCounts)
+	if ctx.Err() != nil {
+		urls := config.URLs
+		if crawl != nil {
+			urls = crawl.urls
+		}
+		unfinished := unfinishedResults(urls, allResults)
+		for i := range unfinished {
+			unfinished[i].Depth, unfinished[i].Referrer = crawl.origin(unfinished[i].URL)
+		}
+		if len(unfinished) > 0 {
+			logger.Warn("Run cancelled before every URL was processed", "unfinished", len(unfinished), "error", ctx.Err())
+			allResults = append(allResults, unfinished...)
+			runErr = fmt.Errorf("one or more jobs failed")
 		}
 	}
-	finalWordCounts := mapreduce.Reduce(intermediateResults)
 
 	return allResults, finalWordCounts, runErr
 }
 
-func processHTML(id int, logger *slog.Logger, url string, rawHTML []byte, manager *artifact_manager.Manager, p *parser.Parser, a *analytics.Analytics, results chan<- Result, parseMode models.ParseMode, filterStrategy *extractor.Strategy, database *db.DB, urlID int64) {
-	result := Result{URL: url}
+func processHTML(id int, logger *slog.Logger, job Job, rawHTML []byte, manager *artifact_manager.Manager, p *parser.Parser, a *analytics.Analytics, filterStrategy *extractor.Strategy, database *db.DB, urlID int64) Result {
+	parsed := parseHTML(id, withStage(logger, stageParse), job, rawHTML, p, a, filterStrategy)
+	if parsed.result.Error == nil {
+		persistParsed(withStage(logger, stageStore), &parsed, manager, database, urlID)
+		if job.FollowLinks {
+			parsed.result.links = followableLinks(parsed.result.Page, parsed.links)
+		}
+	}
+	logger.Info("Worker finished processing", "worker_id", id, "url", job.URL)
+	return parsed.result
+}
+
+// errNoContent marks a page that parsed but yielded no title and no text blocks.
+var errNoContent = errors.New("no content extracted: page has no title or text (it may need JavaScript to render)")
+
+// errSoft404 marks a page served with 200 that reads as an error page, under --treat-soft-404-as-error.
+var errSoft404 = errors.New("soft 404: page was served with status 200 but looks like an error or placeholder page")
+
+// parsedHTML is the CPU-bound half of processing a page, ready to be persisted.
+type parsedHTML struct {
+	result        Result
+	links         *extractors.LinksExtraction
+	yamlData      []byte
+	storeKeywords int
+	parsedFormat  string
+	sections      *chunk.Manifest // --split-sections files, nil when off
+	chunked       bool            // --chunk: write chunks (none for a page without text)
+	chunks        []chunk.Chunk
+	summary       string // --llm-summary digest ("" when off or the page has no text)
+}
+
+// parseHTML parses, filters and counts words for a page. It touches neither disk
+// nor the database (only the enrichment API, with --enrich-academic), so callers
+// may run it concurrently and persist serially.
+func parseHTML(id int, logger *slog.Logger, job Job, rawHTML []byte, p *parser.Parser, a *analytics.Analytics, filterStrategy *extractor.Strategy) parsedHTML {
+	url := job.URL
+	result := Result{URL: url, SkippedRecent: job.skippedRecent}
 
 	page, parseErr := p.Parse(models.ParseRequest{
-		URL:  url,
-		HTML: string(rawHTML),
-		Mode: parseMode,
+		URL:              url,
+		HTML:             string(rawHTML),
+		Mode:             job.ParseMode,
+		CleanHTML:        job.CleanHTML,
+		MinContentLength: job.MinContentLength,
+		ContentSelector:  job.ContentSelector,
+		Trust:            job.Trust,
+		BlockTags:        job.BlockTags,
+		KeepLineBreaks:   job.KeepLineBreaks,
+		MaxSectionDepth:  job.MaxSectionDepth,
+		SectionLanguages: job.SectionLanguages,
+		RobotsTags:       job.RobotsTags,
 	})
 	if parseErr != nil {
 		logger.Error("Error parsing HTML", "worker_id", id, "url", url, "error", parseErr)
 		result.Error = parseErr
 		result.ErrorType = "parse_error"
-		results <- result
-		return
+		return parsedHTML{result: result}
+	}
+
+	// A page with neither title nor text (typically a JavaScript-rendered shell) is a
+	// failure, not an empty success, so it shows in failed URLs and can be retried
+	if strings.TrimSpace(page.Title) == "" && len(page.AllTextBlocks()) == 0 {
+		logger.Warn("No content extracted", "worker_id", id, "url", url)
+		result.Error = errNoContent
+		result.ErrorType = "no_content"
+		return parsedHTML{result: result}
+	}
+
+	if page.Metadata.Soft404 && job.FailSoft404 {
+		logger.Warn("Soft 404: page looks like an error page", "worker_id", id, "url", url, "title", page.Title)
+		result.Error = errSoft404
+		result.ErrorType = "soft_404"
+		return parsedHTML{result: result}
+	}
+
+	if job.ValidatePages {
+		for _, violation := range page.Validate() {
+			logger.Warn("Parsed page failed validation", "worker_id", id, "url", url, "error", violation)
+		}
+	}
+
+	// Collect links before filtering so low-confidence navigation blocks still count as references
+	links := extractors.ExtractLinks(page)
+
+	if job.Enricher != nil {
+		publication, err := job.Enricher.Lookup(page)
+		if err != nil {
+			logger.Warn("Publication metadata lookup failed", "worker_id", id, "url", url, "error", err)
+		}
+		page.Metadata.Publication = publication
 	}
 
 	// Apply filter if provided
@@ -140,8 +310,19 @@ func processHTML(id int, logger *slog.Logger, url string, rawHTML []byte, manage
 		page = extractor.FilterPage(page, filterStrategy)
 	}
 
-	wordCounts := mapreduce.Map(page.ToPlainText(), a)
+	var sections *chunk.Manifest
+	if job.SplitSections != "" {
+		sections = chunk.SplitSections(page, 0, job.SplitSections, job.MaxSectionTokens)
+	}
+	var chunks []chunk.Chunk
+	if job.ChunkSize > 0 {
+		chunks = chunk.ChunkPage(page, chunk.ChunkOptions{Size: job.ChunkSize, Overlap: job.ChunkOverlap})
+	}
+
+	text := page.ToPlainText()
+	wordCounts := mapreduce.Map(text, page.Metadata.Language, a)
 	result.WordCounts = wordCounts
+	result.DisplayForms = a.DisplayFormsIn(text, page.Metadata.Language)
 
 	// Add top keywords to metadata (for YAML artifact)
 	if len(wordCounts) > 0 {
@@ -158,83 +339,180 @@ func processHTML(id int, logger *slog.Logger, url string, rawHTML []byte, manage
 		page.Metadata.TopKeywords = keywordNames
 	}
 
+	var summary string
+	if job.LLMSummary {
+		summary = extractors.Summarize(page)
+	}
+
 	// Marshal to YAML for generic.yaml
 	yamlData, marshalErr := yaml.Marshal(page)
+	result.Page = page
 	if marshalErr != nil {
 		logger.Error("Error marshalling YAML", "worker_id", id, "url", url, "error", marshalErr)
 		result.Error = marshalErr
 		result.ErrorType = "marshal_error"
-		result.Page = page
-		results <- result
-		return
+		return parsedHTML{result: result}
 	}
 
-	// Store parsed YAML using URL-centric storage
-	if database != nil && urlID > 0 {
-		if setParsedErr := manager.SetParsedYAMLByID(urlID, yamlData); setParsedErr != nil {
-			logger.Warn("Failed to store parsed YAML artifact", "url", url, "error", setParsedErr)
-		}
+	result.FileSizeBytes = int64(len(yamlData))
+	return parsedHTML{result: result, links: links, yamlData: yamlData, storeKeywords: job.StoreKeywords, parsedFormat: job.ParsedFormat, sections: sections, chunked: job.ChunkSize > 0, chunks: chunks, summary: summary}
+}
 
-		// Write full wordcount as sorted text file
-		// Word counts are public data, standard file permissions (0644) are appropriate
-		wordcountPath := filepath.Join(artifact_manager.GetURLDir(artifact_manager.DefaultBaseDir, urlID), "wordcount.txt")
-		sortedWordcounts := formatWordCountsSorted(result.WordCounts)
-		// #nosec G306
-		if err := os.WriteFile(wordcountPath, []byte(sortedWordcounts), 0644); err != nil {
-			logger.Warn("Failed to write wordcount.txt", "url", url, "error", err)
+// storeRawHTML writes freshly fetched HTML to URL-centric storage and records the artifact.
+// Without a database (--no-db) it falls back to the slug-based raw/ layout.
+func storeRawHTML(logger *slog.Logger, url string, rawHTML []byte, manager *artifact_manager.Manager, database *db.DB, urlID int64) {
+	if database == nil {
+		if err := manager.SetRawHTML(url, rawHTML); err != nil {
+			logger.Warn("Failed to store raw HTML artifact", "url", url, "error", err)
 		}
+		return
+	}
+	if urlID <= 0 {
+		return
+	}
 
-		// Insert parsed YAML artifact into database
-		parsedTypeID, err := database.GetArtifactTypeID("yaml_parsed")
-		if err != nil {
-			logger.Warn("Failed to get yaml_parsed type ID", "url", url, "error", err)
-		} else {
-			hash := common.ContentHash(yamlData)
-			parsedPath := artifact_manager.GetURLArtifactPath("", urlID, "generic.yaml")
-			result.FilePath = parsedPath
-			_, err = database.InsertArtifact(urlID, parsedTypeID, hash, parsedPath, int64(len(yamlData)))
-			if err != nil {
-				logger.Warn("Failed to insert parsed artifact to DB", "url", url, "error", err)
-			}
-		}
+	if err := manager.SetRawHTMLByID(urlID, rawHTML); err != nil {
+		logger.Warn("Failed to store raw HTML artifact", "url", url, "error", err)
+	}
+
+	// Insert raw HTML artifact into database
+	rawTypeID, err := database.GetArtifactTypeID("html_raw")
+	if err != nil {
+		logger.Warn("Failed to get html_raw type ID", "url", url, "error", err)
+		return
+	}
+	hash := artifact_manager.ContentHash(rawHTML)
+	rawPath := artifact_manager.GetURLArtifactPath("", urlID, "raw.html")
+	if _, err := database.InsertArtifact(urlID, rawTypeID, hash, rawPath, int64(len(rawHTML))); err != nil {
+		logger.Warn("Failed to insert raw artifact to DB", "url", url, "error", err)
+	}
+}
+
+// persistParsed writes a parsed page's artifacts to URL-centric storage and updates its DB row.
+func persistParsed(logger *slog.Logger, parsed *parsedHTML, manager *artifact_manager.Manager, database *db.DB, urlID int64) {
+	if database == nil {
+		persistParsedFiles(logger, parsed, manager)
+		return
+	}
+	if urlID <= 0 {
+		return
+	}
 
-		// Update content type metadata in database
-		contentInfo := db.ContentTypeInfo{
-			ContentType:         db.NewNullString(page.Metadata.ContentType),
-			ContentSubtype:      db.NewNullString(page.Metadata.ContentSubtype),
-			DetectionConfidence: db.NewNullFloat64(page.Metadata.Confidence),
-			HasAbstract:         page.Metadata.HasAbstract,
-			HasInfobox:          page.Metadata.HasInfobox,
-			HasTOC:              page.Metadata.HasTOC,
-			HasCodeExamples:     page.Metadata.HasCodeExamples,
-			SectionCount:        page.Metadata.SectionCount,
-			CitationCount:       page.Metadata.CitationCount,
-			CodeBlockCount:      page.Metadata.CodeBlockCount,
-			TopKeywords:         db.NewNullString(formatKeywordsAsJSON(result.WordCounts, 25)),
-			MetaKeywords:        db.NewNullString(formatMetaKeywordsAsJSON(page.Metadata.MetaKeywords)),
+	result := &parsed.result
+	url := result.URL
+
+	stored, err := corpus.StorePage(database, manager, artifact_manager.DefaultBaseDir, corpus.StoredPage{
+		URLID:         urlID,
+		Page:          result.Page,
+		YAML:          parsed.yamlData,
+		ParsedFormat:  parsed.parsedFormat,
+		WordCounts:    result.WordCounts,
+		DisplayForms:  result.DisplayForms,
+		StoreKeywords: parsed.storeKeywords,
+		Links:         parsed.links,
+	})
+	if err != nil {
+		logger.Warn("Failed to store parsed page", "url", url, "error", err)
+	}
+	result.FilePath = stored.FilePath
+	if stored.FilePath != "" {
+		result.FileSizeBytes = stored.SizeBytes
+	}
+	if stored.DuplicateOf != 0 {
+		logger.Info("URL shares declared canonical with an earlier URL", "url", url, "canonical", result.Page.Metadata.CanonicalURL, "duplicate_of", stored.DuplicateOf)
+	}
+
+	if parsed.sections != nil {
+		if err := writeSections(manager, urlID, parsed.sections); err != nil {
+			logger.Warn("Failed to write section files", "url", url, "error", err)
 		}
-		if err := database.UpdateURLContentType(urlID, contentInfo); err != nil {
-			logger.Warn("Failed to update content type metadata", "url", url, "error", err)
+	}
+	if parsed.chunked {
+		if data, err := chunk.MarshalJSONL(parsed.chunks); err != nil {
+			logger.Warn("Failed to encode chunks", "url", url, "error", err)
+		} else if err := manager.SetURLArtifact(urlID, chunk.ChunksFile, data); err != nil {
+			logger.Warn("Failed to write chunks.jsonl", "url", url, "error", err)
 		}
-
-		// Write metadata.yaml file for corpus queries
-		if err := corpus.WriteMetadataFile(database, urlID, artifact_manager.DefaultBaseDir); err != nil {
-			logger.Warn("Failed to write metadata file", "url", url, "error", err)
+	}
+	if parsed.summary != "" {
+		if err := manager.SetURLArtifact(urlID, extractors.SummaryFile, []byte(parsed.summary)); err != nil {
+			logger.Warn("Failed to write llm-summary.md", "url", url, "error", err)
 		}
+	}
+}
 
-		// Run specialized extractors based on content type
-		runSpecializedExtractors(logger, page, urlID, manager)
+// persistParsedFiles writes a parsed page's artifacts to the slug-based layout used when
+// there is no database: parsed/<slug>-<hash>.json plus sibling .wordcount.txt, .links.yaml,
+// .images.yaml and specialized extraction files. Nothing is recorded anywhere else, so
+// these pages are invisible to sessions, corpus queries and db commands.
+func persistParsedFiles(logger *slog.Logger, parsed *parsedHTML, manager *artifact_manager.Manager) {
+	result := &parsed.result
+	url := result.URL
+	page := result.Page
+
+	jsonData, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		logger.Warn("Failed to marshal parsed JSON", "url", url, "error", err)
+		return
+	}
+	if err := manager.SetParsedJSON(url, jsonData); err != nil {
+		logger.Warn("Failed to store parsed JSON artifact", "url", url, "error", err)
+		return
+	}
+	if parsedPath, err := manager.GetArtifactPath(artifact_manager.ParsedJSONDir, url, ".json"); err == nil {
+		result.FilePath = parsedPath
 	}
 
-	result.FileSizeBytes = int64(len(yamlData))
-	result.Page = page
-	results <- result
-	logger.Info("Worker finished processing", "worker_id", id, "url", url)
+	writeSlugArtifact(logger, manager, url, ".wordcount.txt", []byte(corpus.FormatWordCounts(result.WordCounts, result.DisplayForms)))
+
+	if parsed.summary != "" {
+		writeSlugArtifact(logger, manager, url, "."+extractors.SummaryFile, []byte(parsed.summary))
+	}
+	if parsed.links != nil && parsed.links.Total > 0 {
+		writeSlugYAML(logger, manager, url, ".links.yaml", parsed.links)
+	}
+	if images := extractors.ExtractImages(page); images != nil {
+		writeSlugYAML(logger, manager, url, ".images.yaml", images)
+	}
+	for _, result := range corpus.SpecializedExtractions(page) {
+		writeSlugYAML(logger, manager, url, "."+result.FileName, result.Extraction)
+	}
+}
+
+// writeSlugYAML marshals v and writes it next to the URL's parsed JSON.
+func writeSlugYAML(logger *slog.Logger, manager *artifact_manager.Manager, url, ext string, v interface{}) {
+	yamlData, err := yaml.Marshal(v)
+	if err != nil {
+		logger.Warn("Failed to marshal artifact", "url", url, "artifact", ext, "error", err)
+		return
+	}
+	writeSlugArtifact(logger, manager, url, ext, yamlData)
 }
 
-func worker(id int, logger *slog.Logger, manager *artifact_manager.Manager, f *fetcher.Fetcher, p *parser.Parser, a *analytics.Analytics, wg *sync.WaitGroup, jobs <-chan Job, results chan<- Result, forceFetch bool, filterStrategy *extractor.Strategy, database *db.DB) {
+// writeSlugArtifact writes data to parsed/<slug>-<hash><ext>.
+func writeSlugArtifact(logger *slog.Logger, manager *artifact_manager.Manager, url, ext string, data []byte) {
+	if err := manager.SetSlugArtifact(url, ext, data); err != nil {
+		logger.Warn("Failed to write artifact", "url", url, "artifact", ext, "error", err)
+	}
+}
+
+func worker(ctx context.Context, id int, logger *slog.Logger, manager *artifact_manager.Manager, f Fetcher, p *parser.Parser, a *analytics.Analytics, wg *sync.WaitGroup, jobs <-chan Job, results chan<- Result, forceFetch bool, filterStrategy *extractor.Strategy, database *db.DB, tuner *workerTuner) {
 	defer wg.Done()
-	for job := range jobs {
+	for {
+		if tuner.retire(id) {
+			logger.Info("Worker retired by auto-tuning", "worker_id", id)
+			return
+		}
+		if ctx.Err() != nil {
+			// Out of time: run reports the jobs left in the queue
+			return
+		}
+		job, ok := <-jobs
+		if !ok {
+			return
+		}
+		// Warnings logged for this job also go on its result
+		logger, warnings := recordWarnings(logger, job.URL)
 		logger.Info("Worker started job", "worker_id", id, "url", job.URL)
 
 		var rawHTML []byte
@@ -251,55 +529,86 @@ func worker(id int, logger *slog.Logger, manager *artifact_manager.Manager, f *f
 			}
 		}
 
-		if !forceFetch {
-			rawHTML, fresh, err = manager.GetRawHTML(job.URL)
-			if err != nil {
-				logger.Warn("Error checking artifact storage, fetching fresh", "url", job.URL, "error", err)
+		// --skip-if-fetched-within trusts any recent successful fetch, not just this URL set's
+		if !forceFetch && job.SkipRecent > 0 {
+			rawHTML, job.skippedRecent = recentRawHTML(logger, database, manager, urlID, job.SkipRecent)
+		}
+
+		if !forceFetch && !job.skippedRecent {
+			// With --revalidate, the server's validators decide freshness when it sent any
+			var decided bool
+			if job.Revalidate {
+				rawHTML, fresh, decided = revalidateCache(logger, f, manager, database, urlID, job.URL)
+			}
+
+			// Fetch writes URL-centric storage; older runs may only have the legacy raw/ copy
+			var cacheErr error
+			if !decided && urlID > 0 {
+				rawHTML, fresh, cacheErr = manager.GetRawHTMLByID(urlID)
+			}
+			if !decided && !fresh && cacheErr == nil {
+				rawHTML, fresh, cacheErr = manager.GetRawHTML(job.URL)
 			}
+			if cacheErr != nil {
+				logger.Warn("Error checking artifact storage, fetching fresh", "url", job.URL, "error", cacheErr)
+			}
+		}
+
+		if job.skippedRecent {
+			logger.Info("Fetched recently, using stored raw HTML", "worker_id", id, "url", job.URL)
+			job.RobotsTags = loadRobotsTags(database, urlID)
+			// Not an access: recording one would keep sliding the window forward
+			results <- warnings.attach(processHTML(id, logger, job, rawHTML, manager, p, a, filterStrategy, database, urlID))
+			continue
 		}
 
 		if fresh {
 			logger.Info("Raw HTML found in storage, using it", "worker_id", id, "url", job.URL)
 			statusCode = 200 // Assume success from cache
+			job.RobotsTags = loadRobotsTags(database, urlID)
 		} else {
 			logger.Info("Raw HTML not found or stale, fetching from network", "worker_id", id, "url", job.URL)
-			rawHTML, err = f.GetHtmlBytes(job.URL)
+			var meta *fetcher.HTTPMetadata
+			rawHTML, meta, err = f.GetHtmlBytes(job.URL)
+			if meta != nil {
+				statusCode = meta.StatusCode
+			}
 			if err != nil {
 				result := Result{URL: job.URL}
-				logger.Error("Error fetching HTML", "worker_id", id, "url", job.URL, "error", err)
+				if meta != nil && meta.UserAgent != "" {
+					logger.Error("Error fetching HTML", "worker_id", id, "url", job.URL, "user_agent", meta.UserAgent, "error", err)
+				} else {
+					logger.Error("Error fetching HTML", "worker_id", id, "url", job.URL, "error", err)
+				}
 				result.Error = err
 				result.ErrorType = "fetch_error"
+				if errors.Is(err, fetcher.ErrCircuitOpen) {
+					result.ErrorType = "circuit_open"
+				}
+				if ctx.Err() != nil {
+					result.ErrorType = "timeout"
+				}
 
-				// Record failed access in database
-				if database != nil && urlID > 0 {
-					if dbErr := database.RecordAccess(urlID, 0, "fetch_error", false); dbErr != nil {
+				// Record failed access in database; a cancelled fetch says nothing about the URL
+				if database != nil && urlID > 0 && result.ErrorType != "timeout" {
+					if dbErr := database.RecordAccess(urlID, statusCode, result.ErrorType, false); dbErr != nil {
 						logger.Warn("Failed to record failed access to DB", "url", job.URL, "error", dbErr)
 					}
 				}
 
-				results <- result
+				results <- warnings.attach(result)
 				continue
 			}
-			statusCode = 200 // Successful fetch
-
-			// Store raw HTML using URL-centric storage
-			if database != nil && urlID > 0 {
-				if err := manager.SetRawHTMLByID(urlID, rawHTML); err != nil {
-					logger.Warn("Failed to store raw HTML artifact", "url", job.URL, "error", err)
-				}
+			if statusCode == 0 {
+				statusCode = 200 // Successful fetch without response metadata
+			}
 
-				// Insert raw HTML artifact into database
-				rawTypeID, err := database.GetArtifactTypeID("html_raw")
-				if err != nil {
-					logger.Warn("Failed to get html_raw type ID", "url", job.URL, "error", err)
-				} else {
-					hash := common.ContentHash(rawHTML)
-					rawPath := artifact_manager.GetURLArtifactPath("", urlID, "raw.html")
-					_, err = database.InsertArtifact(urlID, rawTypeID, hash, rawPath, int64(len(rawHTML)))
-					if err != nil {
-						logger.Warn("Failed to insert raw artifact to DB", "url", job.URL, "error", err)
-					}
-				}
+			if !job.NoStoreRaw {
+				storeRawHTML(withStage(logger, stageStore), job.URL, rawHTML, manager, database, urlID)
+			}
+			storeValidators(logger, database, urlID, meta)
+			if meta != nil {
+				job.RobotsTags = meta.RobotsTags
 			}
 		}
 
@@ -310,112 +619,8 @@ func worker(id int, logger *slog.Logger, manager *artifact_manager.Manager, f *f
 			}
 		}
 
-		processHTML(id, logger, job.URL, rawHTML, manager, p, a, results, job.ParseMode, filterStrategy, database, urlID)
+		results <- warnings.attach(processHTML(id, logger, job, rawHTML, manager, p, a, filterStrategy, database, urlID))
 	}
 }
 
 // parseFeaturesFlag converts features string to ParseMode
-
-// runSpecializedExtractors runs content-type-specific extractors and saves results.
-func runSpecializedExtractors(logger *slog.Logger, page *models.Page, urlID int64, manager *artifact_manager.Manager) {
-	if page == nil || page.Metadata.ContentType == "" {
-		return
-	}
-
-	// Only run for full parse mode (skip minimal mode)
-	if page.Metadata.ExtractionMode != "full" {
-		return
-	}
-
-	contentType := page.Metadata.ContentType
-
-	switch contentType {
-	case "academic":
-		extractAcademicContent(logger, page, urlID, manager)
-	case "docs":
-		extractDocsContent(logger, page, urlID, manager)
-	case "wiki":
-		extractWikiContent(logger, page, urlID, manager)
-	}
-}
-
-// extractAcademicContent runs academic extractor and saves results.
-func extractAcademicContent(logger *slog.Logger, page *models.Page, urlID int64, manager *artifact_manager.Manager) {
-	extraction := extractors.ExtractAcademic(page)
-	if extraction == nil {
-		return
-	}
-
-	// Save to lwp-results/{url_id}/academic.yaml
-	yamlData, err := yaml.Marshal(extraction)
-	if err != nil {
-		logger.Warn("Failed to marshal academic extraction", "url_id", urlID, "error", err)
-		return
-	}
-
-	if err := manager.EnsureURLDir(urlID); err != nil {
-		logger.Warn("Failed to ensure URL directory", "url_id", urlID, "error", err)
-		return
-	}
-
-	filePath := artifact_manager.GetURLArtifactPath("", urlID, "academic.yaml")
-	if err := os.WriteFile(filePath, yamlData, 0600); err != nil {
-		logger.Warn("Failed to write academic extraction", "url_id", urlID, "error", err)
-	} else {
-		logger.Info("Saved academic extraction", "url_id", urlID, "file", filePath)
-	}
-}
-
-// extractDocsContent runs docs extractor and saves results.
-func extractDocsContent(logger *slog.Logger, page *models.Page, urlID int64, manager *artifact_manager.Manager) {
-	extraction := extractors.ExtractDocs(page)
-	if extraction == nil {
-		return
-	}
-
-	// Save to lwp-results/{url_id}/docs.yaml
-	yamlData, err := yaml.Marshal(extraction)
-	if err != nil {
-		logger.Warn("Failed to marshal docs extraction", "url_id", urlID, "error", err)
-		return
-	}
-
-	if err := manager.EnsureURLDir(urlID); err != nil {
-		logger.Warn("Failed to ensure URL directory", "url_id", urlID, "error", err)
-		return
-	}
-
-	filePath := artifact_manager.GetURLArtifactPath("", urlID, "docs.yaml")
-	if err := os.WriteFile(filePath, yamlData, 0600); err != nil {
-		logger.Warn("Failed to write docs extraction", "url_id", urlID, "error", err)
-	} else {
-		logger.Info("Saved docs extraction", "url_id", urlID, "file", filePath)
-	}
-}
-
-// extractWikiContent runs wiki extractor and saves results.
-func extractWikiContent(logger *slog.Logger, page *models.Page, urlID int64, manager *artifact_manager.Manager) {
-	extraction := extractors.ExtractWiki(page)
-	if extraction == nil {
-		return
-	}
-
-	// Save to lwp-results/{url_id}/wiki.yaml
-	yamlData, err := yaml.Marshal(extraction)
-	if err != nil {
-		logger.Warn("Failed to marshal wiki extraction", "url_id", urlID, "error", err)
-		return
-	}
-
-	if err := manager.EnsureURLDir(urlID); err != nil {
-		logger.Warn("Failed to ensure URL directory", "url_id", urlID, "error", err)
-		return
-	}
-
-	filePath := artifact_manager.GetURLArtifactPath("", urlID, "wiki.yaml")
-	if err := os.WriteFile(filePath, yamlData, 0600); err != nil {
-		logger.Warn("Failed to write wiki extraction", "url_id", urlID, "error", err)
-	} else {
-		logger.Info("Saved wiki extraction", "url_id", urlID, "file", filePath)
-	}
-}
@@ -0,0 +1,585 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher/fetchertest"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+)
+
+const guidePage = `<!DOCTYPE html>
+<html><head><title>Widget Guide</title></head>
+<body><article>
+<h1>Widget Guide</h1>
+<p>Widgets are small components. Every widget does one thing well, and a widget composes with other widgets.</p>
+<h2>Install</h2>
+<p>Install the widget toolkit with your package manager before building any widget.</p>
+</article></body></html>`
+
+const releasePage = `<!DOCTYPE html>
+<html><head><title>Release Notes</title></head>
+<body><article>
+<h1>Release Notes</h1>
+<p>This release improves parser performance and fixes several parser bugs reported by users.</p>
+</article></body></html>`
+
+// setupRun points storage and the database at a temp directory, as a fetch in that
+// directory would.
+func setupRun(t *testing.T) (*artifact_manager.Manager, *db.DB) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	database, err := db.Open()
+	if err != nil {
+		t.Fatalf("db.Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	return manager, database
+}
+
+func TestRun_WithCannedPages(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		guideURL   = "https://example.com/guide"
+		releaseURL = "https://example.com/release"
+		missingURL = "https://example.com/missing"
+	)
+	fake := fetchertest.New(map[string]string{
+		guideURL:   guidePage,
+		releaseURL: releasePage,
+	})
+	config := &models.FetchConfig{
+		URLs:          []string{guideURL, releaseURL, missingURL},
+		WorkerCount:   2,
+		StoreKeywords: 25,
+	}
+
+	results, wordCounts, runErr := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	if runErr == nil {
+		t.Error("run() error = nil, want an error for the missing page")
+	}
+	if len(results) != 3 {
+		t.Fatalf("run() returned %d results, want 3", len(results))
+	}
+	if wordCounts["widget"] == 0 {
+		t.Errorf("aggregate word counts missing %q: %v", "widget", wordCounts)
+	}
+
+	byURL := make(map[string]Result, len(results))
+	for _, r := range results {
+		byURL[r.URL] = r
+	}
+
+	// Failed fetch: typed error and a failed access with the HTTP status
+	missing := byURL[missingURL]
+	var statusErr *fetcher.StatusError
+	if !errors.As(missing.Error, &statusErr) || statusErr.StatusCode != 404 {
+		t.Errorf("missing page error = %v, want 404 StatusError", missing.Error)
+	}
+	if missing.ErrorType != "fetch_error" {
+		t.Errorf("missing page ErrorType = %q, want fetch_error", missing.ErrorType)
+	}
+	missingID, err := database.GetURLID(missingURL)
+	if err != nil {
+		t.Fatalf("GetURLID(%s) error = %v", missingURL, err)
+	}
+	access, err := database.GetLastAccess(missingID)
+	if err != nil {
+		t.Fatalf("GetLastAccess() error = %v", err)
+	}
+	if access.Success || access.StatusCode != 404 {
+		t.Errorf("missing page access = %+v, want failed with status 404", access)
+	}
+
+	// Successful fetches: parsed page, stored artifacts and DB metadata
+	for _, url := range []string{guideURL, releaseURL} {
+		r := byURL[url]
+		if r.Error != nil {
+			t.Fatalf("%s: unexpected error %v", url, r.Error)
+		}
+		if r.Page == nil || r.Page.Metadata.WordCount == 0 {
+			t.Errorf("%s: page = %+v, want parsed content", url, r.Page)
+		}
+
+		urlID, err := database.GetURLID(url)
+		if err != nil {
+			t.Fatalf("GetURLID(%s) error = %v", url, err)
+		}
+		if _, found, err := manager.GetRawHTMLByID(urlID); err != nil || !found {
+			t.Errorf("%s: raw.html found=%v err=%v, want stored", url, found, err)
+		}
+		if _, found, err := manager.GetParsedJSONByID(urlID); err != nil || !found {
+			t.Errorf("%s: generic.yaml found=%v err=%v, want stored", url, found, err)
+		}
+		access, err := database.GetLastAccess(urlID)
+		if err != nil {
+			t.Fatalf("GetLastAccess() error = %v", err)
+		}
+		if !access.Success || access.StatusCode != 200 {
+			t.Errorf("%s: access = %+v, want success with status 200", url, access)
+		}
+	}
+
+	guideID, _ := database.GetURLID(guideURL)
+	info, err := database.GetURLContentInfo(guideID)
+	if err != nil {
+		t.Fatalf("GetURLContentInfo() error = %v", err)
+	}
+	if !strings.Contains(info.TopKeywords.String, `"widget:`) {
+		t.Errorf("top_keywords = %q, want it to include widget", info.TopKeywords.String)
+	}
+
+	// A second run is served from storage; only the page that failed is requested again
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database); err == nil {
+		t.Error("second run() error = nil, want an error for the missing page")
+	}
+	requests := fake.Requests()
+	if len(requests) != 4 || requests[3] != missingURL {
+		t.Errorf("requests = %v, want the three URLs once plus %s again", requests, missingURL)
+	}
+}
+
+func TestRun_FetchErrorTypes(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://down.example.com/"
+	fake := fetchertest.New(nil).FailWith(url, fetcher.ErrCircuitOpen)
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+
+	results, _, _ := run(context.Background(), logger, config, manager, fake, false, models.ParseModeMinimal, nil, database)
+	if len(results) != 1 || results[0].ErrorType != "circuit_open" {
+		t.Errorf("results = %+v, want one circuit_open failure", results)
+	}
+}
+
+func TestRun_RevalidateUsesValidatorsNotModtime(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := db.Open()
+	if err != nil {
+		t.Fatalf("db.Open() error = %v", err)
+	}
+	defer database.Close()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1, Revalidate: true}
+
+	// Every stored file is immediately stale by modtime
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeMinimal, nil, database); err != nil {
+		t.Fatalf("first run() error = %v", err)
+	}
+
+	// Unchanged upstream: the ETag matches, so the "stale" file is reused
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeMinimal, nil, database); err != nil {
+		t.Fatalf("second run() error = %v", err)
+	}
+	if got := len(fake.Requests()); got != 1 {
+		t.Errorf("page requests after unchanged revalidation = %d, want 1", got)
+	}
+
+	// Changed upstream: the ETag differs, so the page is fetched again
+	fake.SetPage(url, releasePage)
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeMinimal, nil, database); err != nil {
+		t.Fatalf("third run() error = %v", err)
+	}
+	if got := len(fake.Requests()); got != 2 {
+		t.Errorf("page requests after changed revalidation = %d, want 2", got)
+	}
+	if got := len(fake.Heads()); got != 2 {
+		t.Errorf("HEAD requests = %d, want 2", got)
+	}
+}
+
+func TestRun_NoDatabaseWritesSlugFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	manager, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+
+	results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, nil)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Page == nil {
+		t.Fatalf("results = %+v, want one parsed page", results)
+	}
+
+	if _, found, err := manager.GetRawHTML(url); err != nil || !found {
+		t.Errorf("raw HTML found=%v err=%v, want stored in raw/", found, err)
+	}
+	if _, found, err := manager.GetParsedJSON(url); err != nil || !found {
+		t.Errorf("parsed JSON found=%v err=%v, want stored in parsed/", found, err)
+	}
+	wantPath, _ := manager.GetArtifactPath(artifact_manager.ParsedJSONDir, url, ".json")
+	if results[0].FilePath != wantPath {
+		t.Errorf("FilePath = %q, want %q", results[0].FilePath, wantPath)
+	}
+	if _, err := os.Stat(db.DefaultDBName); !os.IsNotExist(err) {
+		t.Errorf("database file stat error = %v, want it never created", err)
+	}
+
+	// The slug layout doubles as the cache
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, nil); err != nil {
+		t.Fatalf("second run() error = %v", err)
+	}
+	if got := len(fake.Requests()); got != 1 {
+		t.Errorf("page requests = %d, want 1", got)
+	}
+}
+
+func TestRun_RecordsParseProvenance(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeFull, nil, database); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	urlID, err := database.GetURLID(url)
+	if err != nil {
+		t.Fatalf("GetURLID() error = %v", err)
+	}
+	provenance, err := database.GetArtifactMetadata(urlID, "yaml_parsed")
+	if err != nil {
+		t.Fatalf("GetArtifactMetadata() error = %v", err)
+	}
+	if provenance[corpus.ProvenanceParserVersion] != parser.Version {
+		t.Errorf("parser_version = %q, want %q", provenance[corpus.ProvenanceParserVersion], parser.Version)
+	}
+	if provenance[corpus.ProvenanceParseMode] != "full" {
+		t.Errorf("parse_mode = %q, want full", provenance[corpus.ProvenanceParseMode])
+	}
+	if _, err := time.Parse(time.RFC3339, provenance[corpus.ProvenanceParsedAt]); err != nil {
+		t.Errorf("parsed_at = %q, want an RFC 3339 timestamp", provenance[corpus.ProvenanceParsedAt])
+	}
+
+	older, err := parsedByOlderParser(database, urlID)
+	if err != nil {
+		t.Fatalf("parsedByOlderParser() error = %v", err)
+	}
+	if older {
+		t.Error("parsedByOlderParser() = true, want false right after parsing")
+	}
+}
+
+func TestRun_ParsedFormatJSON(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1, ParsedFormat: corpus.ParsedFormatJSON}
+
+	results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeFull, nil, database)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	urlID, err := database.GetURLID(url)
+	if err != nil {
+		t.Fatalf("GetURLID() error = %v", err)
+	}
+	if want := artifact_manager.GetURLArtifactPath("", urlID, "generic.json"); results[0].FilePath != want {
+		t.Errorf("FilePath = %q, want %q", results[0].FilePath, want)
+	}
+	if _, found, _ := manager.GetParsedJSONByID(urlID); found {
+		t.Error("generic.yaml written, want only generic.json")
+	}
+
+	page, found, err := manager.GetParsedPageByID(urlID)
+	if err != nil || !found {
+		t.Fatalf("GetParsedPageByID() found=%v err=%v, want the JSON page", found, err)
+	}
+	if page.Metadata.WordCount == 0 || page.Metadata.ExtractionMode != "full" {
+		t.Errorf("decoded metadata = %+v, want word count and full mode from generic.json", page.Metadata)
+	}
+
+	provenance, err := database.GetParsedArtifactMetadata(urlID)
+	if err != nil {
+		t.Fatalf("GetParsedArtifactMetadata() error = %v", err)
+	}
+	if provenance[corpus.ProvenanceParserVersion] != parser.Version {
+		t.Errorf("json_parsed parser_version = %q, want %q", provenance[corpus.ProvenanceParserVersion], parser.Version)
+	}
+}
+
+func TestRun_NoStoreRaw(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1, NoStoreRaw: true}
+
+	results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if results[0].Page == nil || results[0].Page.Metadata.WordCount == 0 {
+		t.Fatalf("page = %+v, want parsed content", results[0].Page)
+	}
+
+	urlID, err := database.GetURLID(url)
+	if err != nil {
+		t.Fatalf("GetURLID() error = %v", err)
+	}
+	if _, found, _ := manager.GetCachedRawHTMLByID(urlID); found {
+		t.Error("raw.html written with NoStoreRaw")
+	}
+	if _, found, _ := manager.GetParsedJSONByID(urlID); !found {
+		t.Error("generic.yaml missing, want parsed output without raw HTML")
+	}
+	artifacts, err := database.ListArtifacts(urlID)
+	if err != nil {
+		t.Fatalf("ListArtifacts() error = %v", err)
+	}
+	for _, a := range artifacts {
+		if a.TypeName == "html_raw" {
+			t.Errorf("html_raw artifact recorded at %s", a.FilePath)
+		}
+	}
+}
+
+func TestRun_SkipIfFetchedWithin(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const fetched, unseen = "https://example.com/guide", "https://example.com/other"
+	fake := fetchertest.New(map[string]string{fetched: guidePage, unseen: guidePage})
+	config := &models.FetchConfig{URLs: []string{fetched}, WorkerCount: 1}
+	if _, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database); err != nil {
+		t.Fatalf("first run() error = %v", err)
+	}
+
+	// A new URL set with a zero max-age: only the recent fetch keeps the first URL off the network
+	stale, err := artifact_manager.NewManager(artifact_manager.DefaultBaseDir, time.Nanosecond)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	config = &models.FetchConfig{URLs: []string{fetched, unseen}, WorkerCount: 1, SkipIfFetchedWithin: time.Hour}
+	results, _, err := run(context.Background(), logger, config, stale, fake, false, models.ParseModeCheap, nil, database)
+	if err != nil {
+		t.Fatalf("second run() error = %v", err)
+	}
+
+	if got := fake.Requests(); len(got) != 2 || got[1] != unseen {
+		t.Errorf("requests = %v, want %s fetched once and %s fetched", got, fetched, unseen)
+	}
+	status := make(map[string]string)
+	for _, r := range results {
+		if r.Page == nil {
+			t.Fatalf("%s has no page", r.URL)
+		}
+		status[r.URL] = r.status()
+	}
+	if status[fetched] != statusSkippedRecent || status[unseen] != "success" {
+		t.Errorf("statuses = %v, want %s skipped_recent and %s success", status, fetched, unseen)
+	}
+	if got := countSkippedRecent(results); got != 1 {
+		t.Errorf("countSkippedRecent() = %d, want 1", got)
+	}
+
+	// Serving from storage is not an access, so it must not extend the window
+	urlID, err := database.GetURLID(fetched)
+	if err != nil {
+		t.Fatalf("GetURLID() error = %v", err)
+	}
+	history, err := database.GetAccessHistory(urlID, 0)
+	if err != nil {
+		t.Fatalf("GetAccessHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("access records = %d, want 1", len(history))
+	}
+}
+
+func TestParseParsedFormat(t *testing.T) {
+	if _, err := ParseParsedFormat("xml"); err == nil {
+		t.Error("ParseParsedFormat(xml) error = nil, want unknown format")
+	}
+}
+
+func TestRun_NoContentIsFailure(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// A single-page app shell: everything is rendered by script
+	const url = "https://app.example.com/"
+	fake := fetchertest.New(map[string]string{
+		url: `<!DOCTYPE html><html><head><script>window.__STATE__ = {"page": "home"};</script></head>
+<body><div id="root"></div><script src="/static/app.js"></script></body></html>`,
+	})
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+
+	for _, mode := range []models.ParseMode{models.ParseModeMinimal, models.ParseModeCheap, models.ParseModeFull} {
+		results, _, runErr := run(context.Background(), logger, config, manager, fake, true, mode, nil, database)
+		if runErr == nil {
+			t.Errorf("run(mode %v) error = nil, want a failure for the empty page", mode)
+		}
+		if len(results) != 1 || results[0].ErrorType != "no_content" || !errors.Is(results[0].Error, errNoContent) {
+			t.Errorf("run(mode %v) results = %+v, want one no_content failure", mode, results)
+		}
+	}
+}
+
+func TestRun_Soft404(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://shop.example.com/p/retired"
+	fake := fetchertest.New(map[string]string{
+		url: `<html><head><title>404 - Page not found</title></head><body><main><h1>Sorry</h1>
+<p>The product you are looking for is no longer available.</p></main></body></html>`,
+	})
+
+	// Flagged but stored by default
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+	results, _, err := run(context.Background(), logger, config, manager, fake, true, models.ParseModeFull, nil, database)
+	if err != nil || len(results) != 1 || results[0].Error != nil {
+		t.Fatalf("run() = %+v, %v; want one success", results, err)
+	}
+	if !results[0].Page.Metadata.Soft404 || !BuildSummaryDetails(results[0]).Soft404 {
+		t.Error("soft_404 not flagged in the page metadata and summary details")
+	}
+
+	config.FailSoft404 = true
+	results, _, _ = run(context.Background(), logger, config, manager, fake, true, models.ParseModeFull, nil, database)
+	if len(results) != 1 || results[0].ErrorType != "soft_404" || !errors.Is(results[0].Error, errSoft404) {
+		t.Fatalf("run(FailSoft404) results = %+v, want one soft_404 failure", results)
+	}
+	if failed := collectFailedURLs(results); len(failed) != 1 || failed[0].ErrorCategory != "soft_404" {
+		t.Errorf("failed URLs = %+v, want error_category soft_404", failed)
+	}
+}
+
+// stallingFetcher serves canned pages but hangs on stall until ctx is done, like a
+// network fetcher sharing the run's context.
+type stallingFetcher struct {
+	*fetchertest.Fetcher
+	ctx   context.Context
+	stall string
+}
+
+func (f *stallingFetcher) GetHtmlBytes(url string) ([]byte, *fetcher.HTTPMetadata, error) {
+	if url == f.stall {
+		<-f.ctx.Done()
+		return nil, nil, f.ctx.Err()
+	}
+	return f.Fetcher.GetHtmlBytes(url)
+}
+
+func TestRun_TimeoutOverall(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const (
+		guideURL   = "https://example.com/guide"
+		stallURL   = "https://slow.example.com/"
+		releaseURL = "https://example.com/release"
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	fake := &stallingFetcher{
+		Fetcher: fetchertest.New(map[string]string{guideURL: guidePage, releaseURL: releasePage}),
+		ctx:     ctx,
+		stall:   stallURL,
+	}
+	// One worker takes the URLs in order, so the stall leaves the release page unstarted
+	config := &models.FetchConfig{URLs: []string{guideURL, stallURL, releaseURL}, WorkerCount: 1}
+
+	// run returns only once every worker has exited
+	results, _, runErr := run(ctx, logger, config, manager, fake, true, models.ParseModeMinimal, nil, database)
+	if runErr == nil {
+		t.Error("run() error = nil, want a failure for the URLs cut off")
+	}
+	byURL := make(map[string]Result)
+	for _, r := range results {
+		byURL[r.URL] = r
+	}
+	if len(results) != 3 {
+		t.Fatalf("run() returned %d results, want 3", len(results))
+	}
+	if r := byURL[guideURL]; r.Error != nil {
+		t.Errorf("guide result error = %v, want success before the cutoff", r.Error)
+	}
+	if r := byURL[stallURL]; r.ErrorType != "timeout" || !errors.Is(r.Error, context.DeadlineExceeded) {
+		t.Errorf("stalled result = %+v, want a cancelled fetch marked timeout", r)
+	}
+	if r := byURL[releaseURL]; r.ErrorType != "timeout" || !errors.Is(r.Error, errTimeoutOverall) {
+		t.Errorf("release result = %+v, want an unstarted URL marked timeout", r)
+	}
+	if got := fake.Requests(); len(got) != 1 || got[0] != guideURL {
+		t.Errorf("fetched %v, want only the guide page", got)
+	}
+}
+
+func TestRun_RobotsHeaderSurvivesCache(t *testing.T) {
+	manager, database := setupRun(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	const url = "https://example.com/guide"
+	fake := fetchertest.New(map[string]string{url: guidePage})
+	fake.SetRobotsTag(url, "noindex", "googlebot: nofollow")
+	config := &models.FetchConfig{URLs: []string{url}, WorkerCount: 1}
+
+	// The second run parses the cached HTML, which no longer has the response headers
+	for _, name := range []string{"fetched", "cached"} {
+		results, _, err := run(context.Background(), logger, config, manager, fake, false, models.ParseModeCheap, nil, database)
+		if err != nil {
+			t.Fatalf("%s run() error = %v", name, err)
+		}
+		robots := results[0].Page.Metadata.Robots
+		if robots == nil || !robots.NoIndex || robots.NoFollow || robots.Source != "header" {
+			t.Errorf("%s robots = %+v, want header noindex only", name, robots)
+		}
+	}
+	if got := len(fake.Requests()); got != 1 {
+		t.Errorf("page requests = %d, want 1", got)
+	}
+
+	urlID, err := database.GetURLID(url)
+	if err != nil {
+		t.Fatalf("GetURLID() error = %v", err)
+	}
+	info, err := database.GetURLContentInfo(urlID)
+	if err != nil {
+		t.Fatalf("GetURLContentInfo() error = %v", err)
+	}
+	if !info.NoIndex || info.NoFollow {
+		t.Errorf("stored noindex=%v nofollow=%v, want true, false", info.NoIndex, info.NoFollow)
+	}
+}
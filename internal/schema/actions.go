@@ -0,0 +1,84 @@
+// Package schema implements the schema command, which describes the output formats.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/internal/fetch"
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/corpus"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"github.com/dtnitsch/llm-web-parser/pkg/schema"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Output is what the schema command prints: the tool's format versions and a JSON
+// Schema per output format.
+type Output struct {
+	ParserVersion string                    `json:"parser_version" yaml:"parser_version"`
+	Schemas       map[string]*schema.Schema `json:"schemas" yaml:"schemas"`
+}
+
+// schemaNames are the formats the schema command knows, for --type.
+var schemaNames = []string{"summary_details", "result_summary", "corpus_response"}
+
+// Schemas builds the schema of each output format from its Go struct.
+func Schemas() map[string]*schema.Schema {
+	details := schema.Generate("summary-details.yaml entry", fetch.SummaryDetails{}, "yaml")
+	details.Description = "One URL in a session's summary-details.yaml (db get --file=details)"
+
+	summary := schema.Generate("fetch --output-mode summary result", fetch.ResultSummary{}, "json")
+	summary.Description = "One entry of results in fetch --output-mode summary JSON output (--summary-version v1)"
+
+	response := schema.Generate("Corpus API response", models.Response{}, "json")
+	response.Description = "Envelope printed by corpus commands; data depends on the verb (see $defs)"
+	response.Properties["verb"].Enum = corpus.AllVerbs()
+	response.Defs = map[string]*schema.Schema{
+		corpus.VerbQUERY:   schema.Generate("query data", corpus.QueryResponse{}, "json"),
+		corpus.VerbEXTRACT: schema.Generate("extract data", corpus.ExtractResponse{}, "json"),
+		corpus.VerbDETECT:  schema.Generate("detect data", corpus.DetectResponse{}, "json"),
+	}
+	for _, def := range response.Defs {
+		def.Schema = ""
+	}
+
+	return map[string]*schema.Schema{
+		"summary_details": details,
+		"result_summary":  summary,
+		"corpus_response": response,
+	}
+}
+
+// SchemaAction prints JSON Schemas for the summary and corpus output formats.
+func SchemaAction(c *cli.Context) error {
+	schemas := Schemas()
+	if name := strings.ToLower(c.String("type")); name != "" {
+		s, ok := schemas[name]
+		if !ok {
+			return fmt.Errorf("unknown --type %q (supported: %s)", c.String("type"), strings.Join(schemaNames, ", "))
+		}
+		schemas = map[string]*schema.Schema{name: s}
+	}
+	output := Output{ParserVersion: parser.Version, Schemas: schemas}
+
+	switch strings.ToLower(c.String("format")) {
+	case "yaml":
+		data, err := yaml.Marshal(output)
+		if err != nil {
+			return fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+		fmt.Fprint(c.App.Writer, string(data))
+	case "json", "":
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(c.App.Writer, string(data))
+	default:
+		return fmt.Errorf("unknown --format %q (supported: json, yaml)", c.String("format"))
+	}
+	return nil
+}
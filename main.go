@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/dtnitsch/llm-web-parser/internal/analyze"
+	"github.com/dtnitsch/llm-web-parser/internal/bench"
+	"github.com/dtnitsch/llm-web-parser/internal/common"
 	corpusactions "github.com/dtnitsch/llm-web-parser/internal/corpus"
 	"github.com/dtnitsch/llm-web-parser/internal/db"
+	"github.com/dtnitsch/llm-web-parser/internal/doctor"
 	"github.com/dtnitsch/llm-web-parser/internal/fetch"
+	"github.com/dtnitsch/llm-web-parser/internal/schema"
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 	"github.com/dtnitsch/llm-web-parser/pkg/help"
+	"github.com/dtnitsch/llm-web-parser/pkg/urlnorm"
 
 	"github.com/urfave/cli/v2"
 )
@@ -29,13 +35,35 @@ func main() {
 				Name:  "coldstart",
 				Usage: "Show quick start guide with concepts, examples, and invariants",
 			},
+			&cli.StringFlag{
+				Name:  "url-key",
+				Usage: "How URLs are normalized into storage keys, comma-separated: keep-fragment, keep-scheme, keep-query-order, and a query parameter policy: drop-tracking (drop utm_*, gclid, fbclid, ...), significant-params (keep all) or drop-params (drop the query) (default: force https, drop-tracking, sort query, drop fragment)",
+			},
+			&cli.IntFlag{
+				Name:  "db-retries",
+				Usage: "Retries for database statements that find the database locked by another writer (0 = fail at once)",
+				Value: dbpkg.DefaultRetryPolicy.MaxRetries,
+			},
+			&cli.StringFlag{
+				Name:  "db-retry-delay",
+				Usage: "Delay before the first database retry; doubles each retry with jitter, up to 250ms",
+				Value: "20ms",
+			},
 		},
 		Before: func(c *cli.Context) error {
 			if c.Bool("coldstart") {
 				fmt.Print(help.ColdstartYAML)
 				os.Exit(0)
 			}
-			return nil
+			policy, err := urlnorm.ParsePolicy(c.String("url-key"))
+			if err != nil {
+				return err
+			}
+			urlnorm.SetPolicy(policy)
+
+			// Commands open the database with these; fail before any of them runs
+			_, err = common.DBRetryPolicy(c)
+			return err
 		},
 		Commands: []*cli.Command{
 			{
@@ -61,7 +89,7 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 					},
 					&cli.StringFlag{
 						Name:    "urls",
-						Usage:   "Comma-separated list of URLs to process",
+						Usage:   "Comma-separated list of URLs to process; append |minimal, |cheap or |full to set one URL's parse mode",
 						Aliases: []string{"u"},
 					},
 					&cli.IntFlag{
@@ -73,10 +101,14 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 						Usage: "Only refetch failed URLs (requires --session)",
 					},
 					&cli.IntFlag{
+						Name:  "append-session",
+						Usage: "Add the --urls to this existing session and fetch them, instead of starting a new session; URLs already in it are skipped",
+					},
+					&cli.StringFlag{
 						Name:    "workers",
-						Usage:   "Number of concurrent workers",
+						Usage:   "Number of concurrent workers, or auto (4 per CPU, at most 4 per host, halved while fetches fail in bulk)",
 						Aliases: []string{"w"},
-						Value:   8,
+						Value:   "8",
 					},
 					&cli.StringFlag{
 						Name:    "format",
@@ -89,15 +121,55 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 						Usage: "Output mode (tier2, summary, full, minimal). Default: tier2 (index to stdout + details file)",
 						Value: "tier2",
 					},
+					&cli.StringFlag{
+						Name:  "output-file",
+						Usage: "Also write the output payload to this file in --format (parent dirs are created; tier2 writes the summary-mode payload)",
+					},
+					&cli.StringFlag{
+						Name:  "failed-urls-file",
+						Usage: "Write failed URLs with error_type and error_category to this file in any output mode (JSON if it ends in .json, else YAML; written with an empty list when nothing failed)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-stdout",
+						Usage: "Don't print to stdout; requires --output-file",
+					},
+					&cli.BoolFlag{
+						Name:  "print-session-only",
+						Usage: "Print only the session ID to stdout, for scripts: S=$(llm-web-parser fetch --print-session-only --urls ...). Logs stay on stderr; exits 2 when every URL failed",
+					},
+					&cli.BoolFlag{
+						Name:  "quiet-json",
+						Usage: "Keep stdout to the single final payload (JSON unless --format is set; tier2 prints the summary-mode payload). Notes, hints and reports go to stderr; cache hits print the stored results",
+					},
 					&cli.StringFlag{
 						Name:  "max-age",
 						Usage: "Maximum age for raw HTML artifacts (e.g., '1h', '0s' to always fetch fresh)",
 						Value: "1h",
 					},
+					&cli.BoolFlag{
+						Name:  "revalidate",
+						Usage: "Check cached HTML with a HEAD request (ETag, Last-Modified, Content-Length) instead of file age; falls back to --max-age when the server sends no validators",
+					},
 					&cli.BoolFlag{
 						Name:  "force-fetch",
 						Usage: "Force fetching all URLs, ignoring max-age and existing artifacts",
 					},
+					&cli.BoolFlag{
+						Name:  "no-store-raw",
+						Usage: "Parse fetched HTML in memory without saving raw.html (privacy, disk); the next run refetches, and db raw and refresh have nothing to read",
+					},
+					&cli.StringFlag{
+						Name:  "stopwords",
+						Usage: "Comma-separated words to leave out of keyword counts, on top of the stopwords bundled for each page's language (en, da, de, es, fr, it, nl, pl, pt, ru, sv; others use en)",
+					},
+					&cli.StringFlag{
+						Name:  "skip-if-fetched-within",
+						Usage: "Don't refetch URLs whose last access in any session succeeded this recently (e.g. '24h'); they parse from stored HTML with status skipped_recent. Ignored with --force-fetch",
+					},
+					&cli.BoolFlag{
+						Name:  "no-db",
+						Usage: "Skip the database and write only files (raw/ and parsed/ slug layout) plus the summary output; sessions, corpus and db commands won't see these pages",
+					},
 					&cli.StringFlag{
 						Name:  "output-dir",
 						Usage: "Base directory for storing raw and parsed artifacts",
@@ -118,6 +190,194 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 						Usage: "Filter parsed content by confidence/type (e.g., 'conf:>=0.7', 'type:code', 'conf:>=0.8,type:p|code')",
 						Value: "",
 					},
+					&cli.BoolFlag{
+						Name:  "clean-html",
+						Usage: "Strip <script>, <style>, <noscript> and comments before and after readability (fixes CSS/JS leaking into text)",
+					},
+					&cli.IntFlag{
+						Name:  "min-content-length",
+						Usage: "Re-extract from the full <body> when readability finds fewer characters of text (0 disables; marks extraction_quality=degraded)",
+						Value: 250,
+					},
+					&cli.StringFlag{
+						Name:  "content-selector",
+						Usage: "CSS selector (e.g. \"article.main\") whose first match replaces readability's content; readability is used when it matches nothing (records content_source=selector)",
+					},
+					&cli.IntFlag{
+						Name:  "store-keywords",
+						Usage: "Top keywords stored per URL in the DB for keyword: filters (0 = all counted words; larger values grow the DB roughly 15 bytes per keyword per URL)",
+						Value: 25,
+					},
+					&cli.StringFlag{
+						Name:  "trust-config",
+						Usage: "YAML file of per-domain confidence overrides (rules: [{domain: \"*.gov\", set: 9}, {domain: medium.com, adjust: -2}]); default is the built-in heuristic",
+					},
+					&cli.StringFlag{
+						Name:  "block-tags",
+						Usage: "Comma-separated elements to capture as content blocks, e.g. 'h1,h2,p' for prose or 'pre,code' for code (supported: h1-h6,p,li,pre,code,table,blockquote,math); default is each mode's full set",
+					},
+					&cli.BoolFlag{
+						Name:  "canonicalize-whitespace",
+						Usage: "Collapse line breaks inside text blocks to spaces; --canonicalize-whitespace=false keeps <br> breaks for poetry, addresses and other formatted text (code blocks always keep their layout)",
+						Value: true,
+					},
+					&cli.IntFlag{
+						Name:  "max-section-depth",
+						Usage: "Flatten sections nested deeper than N into their ancestor (headings become blocks), shrinking output when only top-level structure matters (0 = unlimited)",
+					},
+					&cli.BoolFlag{
+						Name:  "detect-section-lang",
+						Usage: "Detect the language of each section (full-parse) and record the page's language distribution; slower, for multilingual pages (see db show --lang)",
+					},
+					&cli.BoolFlag{
+						Name:  "validate",
+						Usage: "Check each parsed page's structure (section headings and nesting, unique IDs, one payload per block, confidences in [0, 1]) and log violations as warnings; off by default for speed",
+					},
+					&cli.BoolFlag{
+						Name:  "dedupe-output",
+						Usage: "Collapse results whose content duplicates an earlier result's (mirrors, redirects to the same page): the first keeps its details, the others list it as duplicate_of, and their words are left out of top_keywords",
+					},
+					&cli.Float64Flag{
+						Name:  "dedupe-threshold",
+						Usage: "Estimated similarity (0-1, Jaccard over 3-word shingles of the main text) at or above which --dedupe-output treats two results as duplicates; 1 = identical text only",
+						Value: 0.9,
+					},
+					&cli.BoolFlag{
+						Name:  "treat-soft-404-as-error",
+						Usage: "Fail pages served with 200 that look like error pages (metadata.soft_404) instead of storing them; they show in failed URLs as error_type soft_404",
+					},
+					&cli.StringFlag{
+						Name:  "parsed-format",
+						Usage: "Encoding of each stored parsed page: yaml (generic.yaml), json (generic.json) or both",
+						Value: "yaml",
+					},
+					&cli.BoolFlag{
+						Name:  "split-sections",
+						Usage: "Also write each top-level section as its own file, sections/<slug>.md, with a token estimate, a back-reference to the page and a sections/manifest.yaml giving their order (ready-to-embed chunks)",
+					},
+					&cli.StringFlag{
+						Name:  "section-format",
+						Usage: "Format of --split-sections files: md (Markdown with YAML front matter) or yaml",
+						Value: "md",
+					},
+					&cli.IntFlag{
+						Name:  "max-section-tokens",
+						Usage: "With --split-sections, split sections estimated above this many tokens into parts at block boundaries (0 = no limit)",
+					},
+					&cli.BoolFlag{
+						Name:  "chunk",
+						Usage: "Also write chunks.jsonl: the page text in fixed-size chunks with overlap (index, tokens, source section, text per line) for RAG ingestion",
+					},
+					&cli.IntFlag{
+						Name:  "chunk-size",
+						Usage: "With --chunk, the most estimated tokens per chunk",
+						Value: 512,
+					},
+					&cli.IntFlag{
+						Name:  "chunk-overlap",
+						Usage: "With --chunk, tokens from the end of each chunk repeated at the start of the next",
+						Value: 64,
+					},
+					&cli.BoolFlag{
+						Name:  "llm-summary",
+						Usage: "Also write llm-summary.md, a short Markdown digest templated by content type: abstract and key findings (academic), purpose and key APIs (docs), who/what/when (news), overview and outline (others). Pure extraction, no LLM call",
+					},
+					&cli.BoolFlag{
+						Name:  "diff-previous",
+						Usage: "When a stale session is re-run (tier2 output), report URLs newly succeeded, newly failed or with changed content since the previous session of the same URLs",
+					},
+					&cli.BoolFlag{
+						Name:  "enrich-academic",
+						Usage: "Look up arXiv IDs and DOIs found on pages via the arXiv API / Crossref for canonical title, authors, abstract and date (academic.yaml with full-parse); cached in <output-dir>/enrich",
+					},
+					&cli.StringFlag{
+						Name:  "tag",
+						Usage: "Label the session (e.g. research) for 'db sessions --tag'; relabels a cached session",
+					},
+					&cli.StringFlag{
+						Name:  "preset",
+						Usage: "Named flag bundle: llm-ingest (full-parse, clean-html, filter conf:>=0.5) or research (full-parse, clean-html, store-keywords 100); explicit flags override it",
+					},
+					&cli.StringFlag{
+						Name:  "confidence-config",
+						Usage: "YAML file of block confidence weights for full-parse (base, structured, density: [{min_words, bonus}], link_penalty); omitted fields keep the defaults",
+					},
+					&cli.IntFlag{
+						Name:  "limit-urls",
+						Usage: "Only process the first N URLs (after sanitization); the rest are skipped",
+					},
+					&cli.IntFlag{
+						Name:  "sample",
+						Usage: "Only process a random subset of N URLs (use --seed to reproduce)",
+					},
+					&cli.Int64Flag{
+						Name:  "seed",
+						Usage: "Random seed for --sample (default: time-based, printed to stderr)",
+					},
+					&cli.IntFlag{
+						Name:  "retries",
+						Usage: "Retries per URL for network errors, 429 and 5xx responses (jittered exponential backoff)",
+						Value: 2,
+					},
+					&cli.StringFlag{
+						Name:  "retry-delay",
+						Usage: "Base delay before the first retry; doubles on each further retry",
+						Value: "500ms",
+					},
+					&cli.StringFlag{
+						Name:  "max-retry-after",
+						Usage: "Longest Retry-After wait a 429/503 response may ask for; the request is retried after it and the host paused meanwhile. Longer waits fail the URL and skip the host's remaining URLs until then (0 = no limit)",
+						Value: "1m",
+					},
+					&cli.IntFlag{
+						Name:  "breaker-threshold",
+						Usage: "Consecutive failed URLs on a host before its remaining URLs are skipped as circuit_open (0 = disabled)",
+						Value: 5,
+					},
+					&cli.StringFlag{
+						Name:  "breaker-cooldown",
+						Usage: "How long a host stays skipped after its circuit opens",
+						Value: "1m",
+					},
+					&cli.IntFlag{
+						Name:  "host-concurrency",
+						Usage: "Concurrent requests each host starts with; every 429/503 halves it and raises the host's crawl delay, and sustained successes restore it (0 = --workers)",
+					},
+					&cli.IntFlag{
+						Name:  "host-concurrency-min",
+						Usage: "Lowest concurrency a throttled host is reduced to",
+						Value: 1,
+					},
+					&cli.IntFlag{
+						Name:  "host-concurrency-max",
+						Usage: "Highest concurrency a host is restored to after sustained successes (0 = --host-concurrency or --workers, whichever is larger)",
+					},
+					&cli.BoolFlag{
+						Name:  "follow-internal",
+						Usage: "Also fetch same-host links found in each page's content (not in minimal mode), deduped, for a small crawl from the seed URLs; pages marked nofollow are not followed",
+					},
+					&cli.IntFlag{
+						Name:  "depth",
+						Usage: "Link hops to follow from the seed URLs with --follow-internal (default 1; 0 = seeds only)",
+					},
+					&cli.IntFlag{
+						Name:  "max-urls",
+						Usage: "Stop queueing discovered links once the run holds this many URLs, seeds included (--follow-internal)",
+						Value: 100,
+					},
+					&cli.StringFlag{
+						Name:  "timeout-overall",
+						Usage: "Wall-clock budget for the whole run (e.g. 10m); when it runs out, in-flight fetches are cancelled, partial results are written and the exit code is 124",
+					},
+					&cli.StringFlag{
+						Name:  "user-agent-file",
+						Usage: "File of User-Agent strings, one per line (# comments allowed), to rotate through instead of the default agent",
+					},
+					&cli.StringFlag{
+						Name:  "user-agent-rotate",
+						Usage: "How to rotate --user-agent-file agents: 'request' (every request, retries included) or 'host' (one agent per host)",
+						Value: "request",
+					},
 				},
 			},
 			{
@@ -171,6 +431,121 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 					},
 				},
 			},
+			{
+				Name:  "schema",
+				Usage: "Print JSON Schemas of the output formats (summary-details.yaml, summary JSON, corpus responses)",
+				Description: `Generated from the Go structs, so it always matches this build.
+
+EXAMPLES:
+   llm-web-parser schema                              # Every format, JSON
+   llm-web-parser schema --type summary_details       # Just summary-details.yaml
+   llm-web-parser schema --format yaml`,
+				Action: schema.SchemaAction,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "type",
+						Usage: "Only this format: summary_details, result_summary or corpus_response",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: json or yaml",
+						Value: "json",
+					},
+				},
+			},
+			{
+				Name:  "doctor",
+				Usage: "Check the environment: database and schema version, results directory, SQLite FTS5, network",
+				Description: `Prints PASS, WARN, FAIL or SKIP per check and exits 1 when any check fails.
+Checks the database in the current directory without creating or migrating it;
+an older schema is a warning, since the next command migrates it. The network
+check fetches one URL and only runs with --network.
+
+EXAMPLES:
+   llm-web-parser doctor                              # Local checks
+   llm-web-parser doctor --network                    # Also test a fetch
+   llm-web-parser doctor --format json                # Machine-readable, includes schema_version`,
+				Action: doctor.DoctorAction,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "output-dir",
+						Usage: "Results directory to check for writability",
+						Value: artifact_manager.DefaultBaseDir,
+					},
+					&cli.BoolFlag{
+						Name:  "network",
+						Usage: "Also check network egress with a test fetch",
+					},
+					&cli.StringFlag{
+						Name:  "network-url",
+						Usage: "URL the network check fetches",
+						Value: doctor.DefaultNetworkURL,
+					},
+					&cli.DurationFlag{
+						Name:  "network-timeout",
+						Usage: "Timeout for the network check",
+						Value: 10 * time.Second,
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: text or json",
+						Value: "text",
+					},
+				},
+			},
+			{
+				Name:      "bench",
+				Usage:     "Benchmark the parser on cached HTML: throughput, p50/p95 latency and allocations per page",
+				ArgsUsage: "[files or globs...]",
+				Description: `Parses each HTML file --iterations times (after --warmup untimed parses) and
+prints one row per file, slowest p95 first, then totals: pages/s, MB/s and the
+p50/p95 over every parse. Allocations are per parse, measured per file.
+Nothing is fetched or stored. Cached pages are lwp-results/<url_id>/raw.html.
+
+EXAMPLES:
+   llm-web-parser bench --from 'raw/*.html' --mode full
+   llm-web-parser bench --from 'lwp-results/*/raw.html' --mode cheap --iterations 50
+   llm-web-parser bench --from 'raw/*.html' --cpuprofile cpu.out && go tool pprof cpu.out`,
+				Action: bench.BenchAction,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "from",
+						Usage: "Path or glob pattern of HTML files (repeatable)",
+					},
+					&cli.StringFlag{
+						Name:  "mode",
+						Usage: "Parse mode: minimal, cheap or full",
+						Value: "full",
+					},
+					&cli.IntFlag{
+						Name:  "iterations",
+						Usage: "Timed parses per file",
+						Value: 10,
+					},
+					&cli.IntFlag{
+						Name:  "warmup",
+						Usage: "Untimed parses per file before timing (at least 1)",
+						Value: 1,
+					},
+					&cli.BoolFlag{
+						Name:  "clean-html",
+						Usage: "Parse as fetch --clean-html does",
+					},
+					&cli.StringFlag{
+						Name:  "cpuprofile",
+						Usage: "Write a pprof CPU profile of the run to this file",
+					},
+					&cli.StringFlag{
+						Name:  "memprofile",
+						Usage: "Write a pprof allocation profile of the run to this file",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: text or json",
+						Value: "text",
+					},
+				},
+			},
 			{
 				Name:  "db",
 				Usage: "Database operations",
@@ -187,7 +562,7 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 						Name:  "init",
 						Usage: "Initialize database schema",
 						Action: func(c *cli.Context) error {
-							database, err := dbpkg.Open()
+							database, err := common.OpenDB(c)
 							if err != nil {
 								return fmt.Errorf("failed to open database: %w", err)
 							}
@@ -205,7 +580,7 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 						Name:  "path",
 						Usage: "Show database file location",
 						Action: func(c *cli.Context) error {
-							database, err := dbpkg.Open()
+							database, err := common.OpenDB(c)
 							if err != nil {
 								return fmt.Errorf("failed to open database: %w", err)
 							}
@@ -228,9 +603,28 @@ Run 'llm-web-parser fetch' (no args) for examples.`,
 								Name:  "verbose",
 								Usage: "Show aggregated keywords, content types, and code percentage",
 							},
+							&cli.StringFlag{
+								Name:  "tag",
+								Usage: "Only sessions with this tag",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format: text, json, yaml",
+								Value: "text",
+							},
 						},
 						Action: db.SessionsAction,
 					},
+					{
+						Name:      "tag",
+						Usage:     "Label a session, or clear its label with an empty tag",
+						ArgsUsage: "<session_id> <tag>",
+						Description: `EXAMPLES:
+   llm-web-parser db tag 12 research      # Label session 12
+   llm-web-parser db sessions --tag research
+   llm-web-parser db tag 12 ""            # Remove the label`,
+						Action: db.TagAction,
+					},
 					{
 						Name:      "session",
 						Usage:     "Show session details (defaults to latest)",
@@ -258,6 +652,7 @@ NOTE: Use --session 5 (space, not equals)`,
    llm-web-parser db get 5                   # Session 5 (positional)
    llm-web-parser db get --session 5         # Session 5 (flag)
    llm-web-parser db get --session 5 --file=index
+   llm-web-parser db get --file=keywords     # Keywords across the latest session
 
 NOTE: Use --session 5 (space, not equals)`,
 						Flags: []cli.Flag{
@@ -267,7 +662,7 @@ NOTE: Use --session 5 (space, not equals)`,
 							},
 							&cli.StringFlag{
 								Name:  "file",
-								Usage: "Which file to show: index, details, failed (default: details)",
+								Usage: "Which file to show: index, details, failed, keywords (session-wide keyword counts) or extractions (extraction files per URL); keywords and extractions are generated from the database if missing (default: details)",
 								Value: "details",
 							},
 						},
@@ -282,6 +677,7 @@ NOTE: Use --session 5 (space, not equals)`,
    llm-web-parser db urls 7           # Session 7 (positional)
    llm-web-parser db urls --session 7 # Session 7 (flag)
    llm-web-parser db urls --session 7 --verbose
+   llm-web-parser db urls --session 7 --sort date  # Freshest pages first, undated last
 
 NOTE: Use --session 7 (space, not equals)`,
 						Flags: []cli.Flag{
@@ -293,9 +689,200 @@ NOTE: Use --session 7 (space, not equals)`,
 								Name:  "verbose",
 								Usage: "Show detailed 3-line format with metadata (default: compact 1-line format)",
 							},
+							&cli.StringFlag{
+								Name:  "sort",
+								Usage: "Order URLs (supported: date, freshest publication/updated date first, undated last)",
+							},
 						},
 						Action: db.UrlsAction,
 					},
+					{
+						Name:      "links",
+						Usage:     "Export the link graph between URLs in a session (defaults to latest)",
+						ArgsUsage: "[session_id]",
+						Description: `Edges connect URL IDs whose resolved hrefs match a known canonical URL.
+Links to URLs not in the database are counted separately as external links.
+
+EXAMPLES:
+   llm-web-parser db links                          # Latest session, compact text
+   llm-web-parser db links 7 --format json          # Session 7 as JSON
+   llm-web-parser db links --session 7 --format yaml`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (alternative to positional arg)",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.LinksAction,
+					},
+					{
+						Name:      "refresh",
+						Usage:     "Re-parse a session from cached HTML and rewrite metadata/extractions (no network)",
+						ArgsUsage: "[session_id]",
+						Description: `Loads each URL's cached raw.html, re-runs the parser, detector and extractors,
+and rewrites generic.yaml, metadata.yaml, extraction artifacts and the DB row.
+URLs without cached HTML are skipped and reported.
+
+EXAMPLES:
+   llm-web-parser db refresh                        # Latest session
+   llm-web-parser db refresh --session 7            # Session 7
+   llm-web-parser db refresh --features full-parse 7  # Re-parse with a different mode
+   llm-web-parser db refresh --workers 16 7         # More parse workers for large sessions
+   llm-web-parser db refresh --outdated 7           # Only pages parsed by an older parser version`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (alternative to positional arg)",
+							},
+							&cli.StringFlag{
+								Name:  "features",
+								Usage: "Parse features to use instead of the session's original features",
+							},
+							&cli.BoolFlag{
+								Name:  "outdated",
+								Usage: "Only re-parse URLs whose stored parse was made by an older parser version (per artifact provenance)",
+							},
+							&cli.IntFlag{
+								Name:    "workers",
+								Usage:   "Number of concurrent parse workers (DB writes stay serialized)",
+								Aliases: []string{"w"},
+								Value:   8,
+							},
+							&cli.BoolFlag{
+								Name:  "clean-html",
+								Usage: "Strip <script>, <style>, <noscript> and comments before parsing",
+							},
+							&cli.IntFlag{
+								Name:  "min-content-length",
+								Usage: "Re-extract from the full <body> when readability finds fewer characters of text (0 disables)",
+								Value: 250,
+							},
+							&cli.StringFlag{
+								Name:  "content-selector",
+								Usage: "CSS selector whose first match replaces readability's content (see fetch --content-selector)",
+							},
+							&cli.IntFlag{
+								Name:  "store-keywords",
+								Usage: "Top keywords stored per URL in the DB (0 = all counted words)",
+								Value: 25,
+							},
+							&cli.StringFlag{
+								Name:  "trust-config",
+								Usage: "YAML file of per-domain confidence overrides applied while re-parsing (see fetch --trust-config)",
+							},
+							&cli.StringFlag{
+								Name:  "block-tags",
+								Usage: "Elements to capture as content blocks while re-parsing (see fetch --block-tags)",
+							},
+							&cli.BoolFlag{
+								Name:  "canonicalize-whitespace",
+								Usage: "Collapse line breaks inside text blocks while re-parsing (see fetch --canonicalize-whitespace)",
+								Value: true,
+							},
+							&cli.IntFlag{
+								Name:  "max-section-depth",
+								Usage: "Flatten sections nested deeper than N while re-parsing (see fetch --max-section-depth)",
+							},
+							&cli.BoolFlag{
+								Name:  "detect-section-lang",
+								Usage: "Detect each section's language while re-parsing (see fetch --detect-section-lang)",
+							},
+							&cli.BoolFlag{
+								Name:  "validate",
+								Usage: "Log structural problems in re-parsed pages as warnings (see fetch --validate)",
+							},
+							&cli.StringFlag{
+								Name:  "confidence-config",
+								Usage: "Block confidence weights applied while re-parsing (see fetch --confidence-config)",
+							},
+							&cli.StringFlag{
+								Name:  "parsed-format",
+								Usage: "Encoding of each rewritten parsed page: yaml, json or both (see fetch --parsed-format)",
+								Value: "yaml",
+							},
+							&cli.BoolFlag{
+								Name:  "split-sections",
+								Usage: "Also rewrite each page's per-section files (see fetch --split-sections)",
+							},
+							&cli.StringFlag{
+								Name:  "section-format",
+								Usage: "Format of --split-sections files: md or yaml",
+								Value: "md",
+							},
+							&cli.IntFlag{
+								Name:  "max-section-tokens",
+								Usage: "With --split-sections, split sections estimated above this many tokens (0 = no limit)",
+							},
+							&cli.BoolFlag{
+								Name:  "chunk",
+								Usage: "Also rewrite each page's chunks.jsonl (see fetch --chunk)",
+							},
+							&cli.IntFlag{
+								Name:  "chunk-size",
+								Usage: "With --chunk, the most estimated tokens per chunk",
+								Value: 512,
+							},
+							&cli.IntFlag{
+								Name:  "chunk-overlap",
+								Usage: "With --chunk, tokens repeated from the end of the previous chunk",
+								Value: 64,
+							},
+							&cli.BoolFlag{
+								Name:  "llm-summary",
+								Usage: "Also rewrite each page's llm-summary.md (see fetch --llm-summary)",
+							},
+						},
+						Action: fetch.RefreshAction,
+					},
+					{
+						Name:      "refetch-diff",
+						Usage:     "Fetch a stored URL live and report what changed (sections, word count, title, code blocks)",
+						ArgsUsage: "<url_id_or_url>",
+						Description: `Fetches the live page, parses it in the stored version's extraction mode and
+diffs the two. Sections are matched by heading slug, so an inserted section is
+reported once instead of shifting every later one. The stored version is only
+replaced when --update is passed.
+
+EXAMPLES:
+   llm-web-parser db refetch-diff 42                  # Report changes, keep stored version
+   llm-web-parser db refetch-diff --update 42         # Report changes and store the live page
+   llm-web-parser db refetch-diff --format json 42    # Machine-readable diff`,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "update",
+								Usage: "Replace the stored raw HTML, generic.yaml and DB metadata with the live version",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format: yaml or json",
+								Value: "yaml",
+							},
+							&cli.StringFlag{
+								Name:  "features",
+								Usage: "Parse with these features instead of the stored version's extraction mode",
+							},
+							&cli.BoolFlag{
+								Name:  "clean-html",
+								Usage: "Strip script/style/noscript elements and HTML comments before extraction",
+							},
+							&cli.IntFlag{
+								Name:  "min-content-length",
+								Usage: "Re-extract from the full <body> when readability finds fewer characters of text (0 disables)",
+								Value: 250,
+							},
+							&cli.IntFlag{
+								Name:  "store-keywords",
+								Usage: "Top keywords stored per URL in the DB with --update (0 = all counted words)",
+								Value: 25,
+							},
+						},
+						Action: fetch.RefetchDiffAction,
+					},
 					{
 						Name:  "query",
 						Usage: "Query sessions with filters",
@@ -312,6 +899,22 @@ NOTE: Use --session 7 (space, not equals)`,
 								Name:  "url",
 								Usage: "Filter by URL pattern (LIKE match)",
 							},
+							&cli.StringFlag{
+								Name:  "tag",
+								Usage: "Filter by session tag",
+							},
+							&cli.StringFlag{
+								Name:  "since",
+								Usage: "Only sessions created at or after this time: 2006-01-02, RFC 3339, or an age like 7d or 36h",
+							},
+							&cli.StringFlag{
+								Name:  "until",
+								Usage: "Only sessions created before this time (a bare date includes that day); same formats as --since",
+							},
+							&cli.StringFlag{
+								Name:  "content-type",
+								Usage: "Only sessions with at least one URL of this content type (academic, docs, wiki, news, repo, blog, landing)",
+							},
 						},
 						Action: db.QuerySessionsAction,
 					},
@@ -363,6 +966,9 @@ NOTE: New fetches auto-switch to the new session.`,
    # Search for specific pattern with context
    llm-web-parser db show --grep="async" --context=3 42
 
+   # Show a single section of a large page (IDs from --outline)
+   llm-web-parser db show --section=section-4 42
+
    # Batch retrieve (comma-separated IDs)
    llm-web-parser db show 42,43,44
 
@@ -402,11 +1008,28 @@ NOTE: Flags must come BEFORE the ID/URL (urfave/cli requirement).`,
 								Name:  "metadata-full",
 								Usage: "Show all metadata fields (including empty)",
 							},
+							&cli.BoolFlag{
+								Name:    "verbose",
+								Aliases: []string{"v"},
+								Usage:   "Show parse provenance: parser version, mode and time that produced the stored page",
+							},
 							&cli.StringFlag{
 								Name:  "format",
 								Usage: "Output format: yaml (default), json, markdown, or csv",
 								Value: "yaml",
 							},
+							&cli.StringFlag{
+								Name:  "section",
+								Usage: "Show only one section by ID (see --outline, e.g. section-4 or block-12)",
+							},
+							&cli.StringFlag{
+								Name:  "lang",
+								Usage: "Show only sections in this language, e.g. ja (needs fetch --detect-section-lang)",
+							},
+							&cli.BoolFlag{
+								Name:  "quarantine",
+								Usage: "If the stored page is corrupt (e.g. truncated), move it aside to <file>.corrupt so the next fetch rewrites it",
+							},
 						},
 						Action: db.ShowAction,
 					},
@@ -424,6 +1047,214 @@ NOTE: Flags must come BEFORE the ID/URL (urfave/cli requirement).`,
 NOTE: This shows the cached HTML. Use 'llm-web-parser db urls' to find URL IDs.`,
 						Action:    db.RawAction,
 					},
+					{
+						Name:      "stats",
+						Usage:     "Show session statistics (detection confidence histogram per content type)",
+						ArgsUsage: "[session_id]",
+						Description: `Buckets each URL's detection_confidence (0-2, 2-4, 4-6, 6-8, 8-10) per content type,
+with counts and percentages, to find low-confidence classifications worth reviewing.
+Defaults to the active or latest session.
+
+EXAMPLES:
+   llm-web-parser db stats --session 5 --histogram confidence
+   llm-web-parser db stats --format json`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (default: active or latest session)",
+							},
+							&cli.StringFlag{
+								Name:  "histogram",
+								Usage: "Histogram to compute (confidence)",
+								Value: "confidence",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.StatsAction,
+					},
+					{
+						Name:      "budget",
+						Usage:     "Check a session's estimated tokens against an LLM context budget",
+						ArgsUsage: "[session_id]",
+						Description: `Sums the estimated tokens recorded for each URL of the session, lists URLs by
+tokens, and when the session does not fit --budget, suggests URLs to include:
+highest ranked first, skipping any that would overflow what is left.
+
+--rank confidence ranks by detection_confidence; --rank tfidf by how distinctive
+each URL's stored keywords are within the session.
+
+EXAMPLES:
+   llm-web-parser db budget --session 5 --budget 128000
+   llm-web-parser db budget --budget 32000 --rank tfidf --format json`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (default: active or latest session)",
+							},
+							&cli.IntFlag{
+								Name:  "budget",
+								Usage: "Context budget in tokens",
+								Value: 128000,
+							},
+							&cli.StringFlag{
+								Name:  "rank",
+								Usage: "What earns a place in the budget first (confidence, tfidf)",
+								Value: "confidence",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.BudgetAction,
+					},
+					{
+						Name:      "export-keywords",
+						Usage:     "Export stored keyword counts of a session's URLs matching a filter (CSV or JSON)",
+						ArgsUsage: "[session_id]",
+						Description: `Writes the keywords stored for each matching URL (urls.top_keywords, see fetch
+--store-keywords) for use outside the tool. --filter takes corpus query expressions.
+
+The default long layout is sparse: one url_id,keyword,count row per keyword a URL uses.
+--layout matrix is dense: one row per URL and one column per keyword of a shared
+vocabulary (most frequent first; --vocab-size keeps the top N), zero where unused.
+URLs without stored keywords are left out.
+
+EXAMPLES:
+   llm-web-parser db export-keywords --session 5 --filter "content_type=docs"
+   llm-web-parser db export-keywords --layout matrix --vocab-size 500 > matrix.csv
+   llm-web-parser db export-keywords --session 5 --format json`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (default: active or latest session)",
+							},
+							&cli.StringFlag{
+								Name:  "filter",
+								Usage: "Corpus query filter, e.g. 'content_type=docs AND has_code' (default: all URLs)",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (csv, json)",
+								Value: "csv",
+							},
+							&cli.StringFlag{
+								Name:  "layout",
+								Usage: "long (url_id,keyword,count rows) or matrix (URL x keyword counts)",
+								Value: "long",
+							},
+							&cli.IntFlag{
+								Name:  "vocab-size",
+								Usage: "Matrix layout: keep only the N most frequent keywords as columns (0 = all)",
+							},
+						},
+						Action: db.ExportKeywordsAction,
+					},
+					{
+						Name:      "code",
+						Usage:     "Export every code block extracted from a session's URLs (JSON Lines, JSON or CSV)",
+						ArgsUsage: "[session_id]",
+						Description: `Collects the code blocks of each URL in the session, with language, source URL
+and the heading of the section each block sits in, into one stream for building a
+code-example index. Blocks come from the URL's docs.yaml (written by full-parse fetches
+of docs pages) or, failing that, are re-extracted from its stored parsed page. URLs
+without code are skipped.
+
+EXAMPLES:
+   llm-web-parser db code --session 5 --lang python --format jsonl
+   llm-web-parser db code --min-lines 5 --format csv > code.csv`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "session",
+								Usage: "Session ID (default: active or latest session)",
+							},
+							&cli.StringFlag{
+								Name:  "lang",
+								Usage: "Only code blocks in this language, e.g. python (case-insensitive)",
+							},
+							&cli.IntFlag{
+								Name:  "min-lines",
+								Usage: "Only code blocks with at least this many lines",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (jsonl, json, csv)",
+								Value: "jsonl",
+							},
+						},
+						Action: db.CodeAction,
+					},
+					{
+						Name:  "changed",
+						Usage: "List URLs whose content changed since a session",
+						Description: `Compares each URL's content hash (normalized text with boilerplate blocks dropped)
+recorded in the --since session with its hash in the latest later session that
+fetched it, and lists the URLs that differ. URLs not fetched again are skipped.
+
+EXAMPLES:
+   llm-web-parser db changed --since 5
+   llm-web-parser db changed --since 5 --format json`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:     "since",
+								Usage:    "Session ID to compare against",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.ChangedAction,
+					},
+					{
+						Name:      "artifacts",
+						Usage:     "List the artifacts stored for a URL (by ID or URL)",
+						ArgsUsage: "<url_id_or_url>",
+						Description: `Shows each artifact's type, size, content hash, creation time and path.
+
+EXAMPLES:
+   llm-web-parser db artifacts 42
+   llm-web-parser db artifacts --format json https://golang.org`,
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.ArtifactsAction,
+					},
+					{
+						Name:      "accesses",
+						Usage:     "Show the fetch history of a URL (by ID or URL), newest first",
+						ArgsUsage: "<url_id_or_url>",
+						Description: `Lists every recorded fetch attempt across sessions, with HTTP status and
+error type, to diagnose URLs that fail intermittently.
+
+EXAMPLES:
+   llm-web-parser db accesses 42
+   llm-web-parser db accesses --limit 0 --format json https://golang.org`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "Show at most N attempts (0 = all)",
+								Value: 20,
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Output format (text, json, yaml)",
+								Value: "text",
+							},
+						},
+						Action: db.AccessesAction,
+					},
 					{
 						Name:      "find-url",
 						Usage:     "Find the URL ID for a given URL",
@@ -463,6 +1294,92 @@ NOTE: This shows the cached HTML. Use 'llm-web-parser db urls' to find URL IDs.`
 							&cli.StringFlag{Name: "format", Value: "text", Usage: "Output format (text, json, yaml, csv)"},
 						},
 					},
+					{
+						Name:   "vocab",
+						Usage:  "List every keyword in a session with its document frequency",
+						Action: corpusactions.VocabAction,
+						Description: `For each distinct keyword: total_count (summed across URLs) and doc_count (how
+many URLs it appears in). Useful for building a controlled vocabulary, TF-IDF
+weights, and spotting terms so pervasive they don't discriminate.
+
+Reads the per-URL keywords stored by fetch, so a word ranked below
+fetch --store-keywords on a page doesn't count toward that page.
+
+EXAMPLES:
+   llm-web-parser corpus vocab --session 7
+   llm-web-parser corpus vocab --min-doc-count 3 --format csv > vocab.csv
+   llm-web-parser corpus vocab --top 20 --format json | jq '.keywords[].word'`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{Name: "session", Usage: "Session ID (default: active session, fallback to latest)"},
+							&cli.IntFlag{Name: "min-doc-count", Value: 1, Usage: "Only keywords found in at least this many URLs"},
+							&cli.IntFlag{Name: "top", Usage: "Return the first N keywords by document frequency (0 for all)"},
+							&cli.StringFlag{Name: "format", Value: "text", Usage: "Output format (text, json, yaml, csv)"},
+						},
+					},
+					{
+						Name:   "similar",
+						Usage:  "Find the URLs in a session most similar to a given URL",
+						Action: corpusactions.SimilarAction,
+						Description: `Ranks the session's other URLs by the cosine similarity of their stored
+keywords (weighted by TF-IDF within the session) to --url-id's, plus a bonus
+for sharing its content type. Each match lists its score (0-1), the keyword
+similarity alone and the keywords contributing most to it. URLs with no
+keyword or content type in common are left out.
+
+Reads the per-URL keywords stored by fetch, like 'corpus vocab'.
+
+EXAMPLES:
+   llm-web-parser corpus similar --url-id=5
+   llm-web-parser corpus similar --url-id=5 --session=7 --top=3
+   llm-web-parser corpus similar --url-id=5 --format json | jq '.similar[].url_id'`,
+						Flags: []cli.Flag{
+							&cli.Int64Flag{Name: "url-id", Usage: "URL to find similar pages for (required)"},
+							&cli.IntFlag{Name: "session", Usage: "Session ID (default: active session, fallback to latest)"},
+							&cli.IntFlag{Name: "top", Value: 10, Usage: "Return the N most similar URLs (0 for all)"},
+							&cli.StringFlag{Name: "format", Value: "text", Usage: "Output format (text, json, yaml)"},
+						},
+					},
+					{
+						Name:   "tables",
+						Usage:  "Return tables from a session as header-keyed records",
+						Action: corpusactions.TablesAction,
+						Description: `Each table row becomes an object keyed by its column header. Missing headers
+are named col1, col2, ...; ragged rows keep only the cells they have.
+
+EXAMPLES:
+   llm-web-parser corpus tables --session 7
+   llm-web-parser corpus tables --urls 42 --format yaml
+   llm-web-parser corpus tables --min-rows 3 | jq '.tables[].records'`,
+						Flags: []cli.Flag{
+							&cli.IntFlag{Name: "session", Usage: "Session ID (default: active session, fallback to latest)"},
+							&cli.StringFlag{Name: "urls", Usage: "Comma-separated URL IDs or URLs (default: all URLs in session)"},
+							&cli.IntFlag{Name: "min-rows", Value: 1, Usage: "Skip tables with fewer data rows"},
+							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml)"},
+						},
+					},
+					{
+						Name:      "ingest",
+						Usage:     "Load pre-parsed page JSON into the corpus without fetching",
+						ArgsUsage: "[files or globs...]",
+						Description: `Reads models.Page JSON files (fetch's generic.json or parsed/*.json, or pages
+parsed elsewhere) and stores each one as if fetch had parsed it: the URL is
+added, its content type detected when the file has none, its keywords
+counted, and generic.yaml, wordcount.txt and metadata.yaml written. All
+ingested pages go into one new session. Files that aren't page JSON fail;
+pages without an http(s) url or content blocks, or repeating a URL, are
+skipped. Nothing is fetched, so the session has no raw HTML to refresh from.
+
+EXAMPLES:
+   llm-web-parser corpus ingest --from 'parsed/*.json' --session-tag imported
+   llm-web-parser corpus ingest export/a.json export/b.json
+   llm-web-parser corpus ingest --from 'dump/*.json' | jq '.data.errors'`,
+						Action: corpusactions.CorpusAction,
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{Name: "from", Usage: "Path or glob pattern of parsed page JSON files (repeatable)"},
+							&cli.StringFlag{Name: "session-tag", Usage: "Tag the new session (see 'lwp db sessions --tag')"},
+							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml)"},
+						},
+					},
 					{
 						Name:   "extract",
 						Usage:  "[WORKING] Extract and aggregate keywords from URLs",
@@ -473,16 +1390,22 @@ NOTE: This shows the cached HTML. Use 'llm-web-parser db urls' to find URL IDs.`
 							&cli.IntFlag{Name: "top", Value: 10, Usage: "Return top N keywords (0 for all)"},
 							&cli.IntFlag{Name: "limit", Value: 10, Usage: "Alias for --top", Hidden: true},
 							&cli.BoolFlag{Name: "verbose", Aliases: []string{"v"}, Usage: "Show full output (confidence, coverage, hints)"},
-							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml, csv)"},
+							&cli.StringFlag{Name: "format", Usage: "Keyword list as json, yaml or csv (word,count); default is a compact text list"},
 						},
 					},
 					{
 						Name:   "query",
-						Usage:  "[NOT IMPLEMENTED] Boolean filtering over metadata",
+						Usage:  "[WORKING] Boolean filtering over metadata",
 						Action: corpusactions.CorpusAction,
 						Flags: []cli.Flag{
 							&cli.StringFlag{Name: "filter", Usage: "Filter expression (e.g., 'has_code AND citations>50')"},
-							&cli.IntFlag{Name: "session", Usage: "Session ID"},
+							&cli.StringFlag{Name: "domain", Usage: "Only URLs whose domain contains this substring (e.g., golang.org)"},
+							&cli.StringFlag{Name: "facet", Usage: "Add match counts per value of a field (supported: domain)"},
+							&cli.BoolFlag{Name: "snippets", Usage: "Show the text around each keyword: match (reads every matching page's content)"},
+							&cli.BoolFlag{Name: "explain-sql", Usage: "Include the executed SQL, its bound args and the count query (debugging the filter syntax)"},
+							&cli.StringFlag{Name: "sort", Usage: "Order matches (supported: date, freshest publication/updated date first, undated last)"},
+							&cli.IntFlag{Name: "session", Usage: "Session ID (default: active or latest session)"},
+							&cli.BoolFlag{Name: "all", Usage: "Query every URL ever fetched, across all sessions; each match lists the sessions it appeared in"},
 							&cli.StringFlag{Name: "view", Usage: "View name"},
 							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml, csv)"},
 						},
@@ -498,33 +1421,73 @@ NOTE: This shows the cached HTML. Use 'llm-web-parser db urls' to find URL IDs.`
 						},
 					},
 					{
-						Name:   "detect",
-						Usage:  "[NOT IMPLEMENTED] Pattern recognition (clusters, warnings, gaps, anomalies, trends)",
-						Action: corpusactions.CorpusAction,
+						Name:      "detect",
+						Usage:     "[PARTIAL] Pattern recognition; 'classification' explains why pages got their content type",
+						ArgsUsage: "[pattern]",
+						Action:    corpusactions.CorpusAction,
+						Description: `Re-runs content-type detection on cached HTML and lists every detector's
+matched signals (host, path, title or content patterns) with their weights. A detector
+fires when its score reaches its threshold; the first to fire, in priority order
+(academic, docs, wiki, repo, blog, news, landing), decides the type.
+
+EXAMPLES:
+   llm-web-parser corpus detect --url-ids=42
+   llm-web-parser corpus detect --session=7 --format yaml classification
+   llm-web-parser corpus detect --url-ids=42 | jq '.data.urls[].detectors[] | select(.fired)'`,
 						Flags: []cli.Flag{
-							&cli.IntFlag{Name: "session", Usage: "Session ID"},
+							&cli.StringFlag{Name: "pattern", Value: "classification", Usage: "What to detect (supported: classification)"},
+							&cli.IntFlag{Name: "session", Usage: "Session ID (explain every URL in the session)"},
+							&cli.StringFlag{Name: "url-ids", Usage: "Comma-separated URL IDs (e.g., 1,3,5)"},
+							&cli.BoolFlag{Name: "clean-html", Usage: "Re-read pages the way fetch --clean-html did"},
+							&cli.IntFlag{Name: "min-content-length", Value: 250, Usage: "Body fallback threshold the pages were fetched with (see fetch --min-content-length)"},
 							&cli.StringFlag{Name: "view", Usage: "View name"},
-							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml, csv)"},
+							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml)"},
 						},
 					},
 					{
-						Name:   "normalize",
-						Usage:  "[NOT IMPLEMENTED] Canonicalize entities, dates, versions, code",
+						Name:  "normalize",
+						Usage: "Clean a session's stored keywords: merge case and quote variants, drop stopwords and entity residue",
+						Description: `Rewrites the keyword counts stored for each URL in the session, both
+urls.top_keywords and the wordcount.txt artifacts, as current keyword
+counting would have produced them: entities decoded, curly quotes
+straightened, lowercased, possessive 's trimmed. Entries that clean to the
+same keyword are merged by summing their counts; stopwords and leftover
+entity names (amp, nbsp, ...) are removed. Reports how many entries were
+rewritten, merged and removed.
+
+EXAMPLES:
+   llm-web-parser corpus normalize --session=7 --dry-run
+   llm-web-parser corpus normalize --session=7 --stem
+   llm-web-parser corpus normalize --session=7 --stopwords=cookie,consent`,
 						Action: corpusactions.CorpusAction,
 						Flags: []cli.Flag{
-							&cli.IntFlag{Name: "session", Usage: "Session ID"},
-							&cli.StringFlag{Name: "view", Usage: "View name"},
-							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml, csv)"},
+							&cli.IntFlag{Name: "session", Usage: "Session ID (required)"},
+							&cli.BoolFlag{Name: "stem", Usage: "Also merge English plurals into their singular (libraries -> library)"},
+							&cli.StringFlag{Name: "stopwords", Usage: "Comma-separated words to remove on top of the bundled English stopwords"},
+							&cli.BoolFlag{Name: "dry-run", Usage: "Report what would change without rewriting anything"},
+							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml)"},
 						},
 					},
 					{
-						Name:   "trace",
-						Usage:  "[NOT IMPLEMENTED] Citation graphs, authority scoring, provenance",
+						Name:  "trace",
+						Usage: "Locate a keyword or phrase in stored pages: which blocks and sections contain it, at what position",
+						Description: `Searches each page's stored parse (generic.yaml) for the term as whole
+words, ignoring case, and lists every block that contains it in document
+order: its position among the page's blocks, the element path in the
+readability content, the enclosing section and a snippet. Positions let an
+answer cite exactly where a fact came from. Pages parsed before positions
+were recorded are listed under unknowns; 'lwp db refresh --outdated' adds them.
+
+EXAMPLES:
+   llm-web-parser corpus trace --url-ids=5 --term=error
+   llm-web-parser corpus trace --session=7 "rate limit"
+   llm-web-parser corpus trace --url-ids=5,6 --term=timeout --format=yaml`,
 						Action: corpusactions.CorpusAction,
 						Flags: []cli.Flag{
-							&cli.IntFlag{Name: "session", Usage: "Session ID"},
-							&cli.StringFlag{Name: "view", Usage: "View name"},
-							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml, csv)"},
+							&cli.IntFlag{Name: "session", Usage: "Session ID (all its URLs)"},
+							&cli.StringFlag{Name: "url-ids", Usage: "Comma-separated URL IDs (e.g., 1,3,5)"},
+							&cli.StringFlag{Name: "term", Usage: "Keyword or phrase to locate (or pass it as an argument)"},
+							&cli.StringFlag{Name: "format", Value: "json", Usage: "Output format (json, yaml)"},
 						},
 					},
 					{
@@ -604,14 +1567,17 @@ Session management (sessions, session, get, urls):
   llm-web-parser db get --file=index 5             # Get session 5 summary-index.yaml
   llm-web-parser db urls                            # Show URL IDs for latest session
   llm-web-parser db urls --verbose                  # Show URLs with keywords and metadata
+  llm-web-parser db links --format json             # Link graph between URLs in latest session
+  llm-web-parser db refresh --session 7             # Re-parse session 7 from cached HTML
 
-URL content operations (show, raw, find-url):
+URL content operations (show, raw, find-url, refetch-diff):
   llm-web-parser db show 42                         # Show parsed content for URL ID 42
   llm-web-parser db show --outline 42               # Show document outline (headings only)
   llm-web-parser db show --only=h2,code 42          # Filter by block type
   llm-web-parser db show 42,43,44                   # Batch retrieve multiple URLs
   llm-web-parser db raw 42                          # Show raw HTML for URL ID 42
   llm-web-parser db find-url https://example.com    # Find URL ID for a URL
+  llm-web-parser db refetch-diff 42                 # What changed on the live page since it was stored
 
 Process with external tools (root path is .content[]):
   # YAML output (default) - use yq for YAML processing:
@@ -653,8 +1619,20 @@ Query metadata (filter URLs by detected properties):
   llm-web-parser corpus query --session=1 --filter="content_type=academic"
   llm-web-parser corpus query --session=1 --filter="has_code_examples"
   llm-web-parser corpus query --session=1 --filter="citation_count>=20"
-  llm-web-parser corpus query --session=1 --filter="keyword:api"
+  llm-web-parser corpus query --session=1 --filter="keyword:api"   # Matches each URL's stored top keywords (fetch --store-keywords, default 25)
   llm-web-parser corpus query --session=1 --filter="has_code_examples AND keyword:python"
+  llm-web-parser corpus query --session=1 --domain=golang.org --filter="content_type=docs"
+  llm-web-parser corpus query --session=1 --facet=domain     # Composition of the crawl by domain
+  llm-web-parser corpus query --session=1 --filter="keyword:api" --snippets  # Show where each URL uses "api"
+  llm-web-parser corpus query --session=1 --filter="citation_count>=20" --explain-sql  # Show the SQL that ran
+  llm-web-parser corpus query --session=1 --filter="content_type=docs" --sort=date  # Freshest docs first
+
+Tables as records (each row keyed by column header):
+  llm-web-parser corpus tables --session=1                   # All tables in session 1 as JSON
+  llm-web-parser corpus tables --urls=42 --format=yaml       # Tables from one URL
+
+More like this (URLs sharing keywords and content type):
+  llm-web-parser corpus similar --url-id=42 --top=5          # 5 URLs in the session most like URL 42
 
 Get query suggestions (see what's available in your session):
   llm-web-parser corpus suggest --session=1                  # Analyzes session and suggests queries
@@ -663,9 +1641,12 @@ Working commands:
   ✅ extract  - Aggregate keywords across URLs
   ✅ query    - Boolean filtering over metadata (has_code_examples, content_type, citations, etc.)
   ✅ suggest  - Smart query suggestions based on session content
+  ✅ tables   - Tables as header-keyed JSON/YAML records
+  ✅ similar  - URLs most like a given URL (keyword TF-IDF cosine + content type)
+  ✅ normalize - Clean stored keywords (merge variants, drop stopwords)
 
 Planned commands (not yet implemented):
-  ⏳ compare, detect, normalize, trace, score, delta, summarize, explain-failure
+  ⏳ compare, detect, trace, score, delta, summarize, explain-failure
 
 Tip: Run any command without arguments to see detailed examples:
   llm-web-parser corpus query           # Shows all available filters with examples
@@ -0,0 +1,17 @@
+package models
+
+// ConfidenceConfig holds the weights the full-mode parser uses to score text blocks,
+// loaded from the YAML file given to --confidence-config. Fields left out of the file
+// keep their defaults (see parser.DefaultConfidenceConfig).
+type ConfidenceConfig struct {
+	Base        float64       `yaml:"base"`         // Score of a text block before adjustments
+	Structured  float64       `yaml:"structured"`   // Fixed score of code and table blocks
+	Density     []DensityBand `yaml:"density"`      // Word-count bonuses; the highest band exceeded applies
+	LinkPenalty float64       `yaml:"link_penalty"` // Subtracted per link in the block
+}
+
+// DensityBand adds Bonus to blocks with more than MinWords words.
+type DensityBand struct {
+	MinWords int     `yaml:"min_words"`
+	Bonus    float64 `yaml:"bonus"`
+}
@@ -1,9 +1,111 @@
 // Package models defines data structures for configuration and parsing.
 package models
 
+import "time"
+
 // FetchConfig holds runtime configuration for fetch operations.
 // All values come from CLI flags, not external config files.
 type FetchConfig struct {
 	URLs        []string
 	WorkerCount int
+	WorkersAuto bool // --workers auto: WorkerCount derived from CPUs and hosts, shed on error spikes
+	CleanHTML   bool // Strip script/style/noscript/comments around readability
+
+	// Parse modes picked per URL with "url|mode" in --urls; other URLs use the --features mode
+	URLParseModes map[string]ParseMode
+
+	// Fall back to the full <body> when readability extracts fewer characters (0 = off)
+	MinContentLength int
+
+	// CSS selector used as the content root instead of readability's output when it matches
+	ContentSelector string
+
+	// Number of top keywords written to urls.top_keywords (0 = every counted word)
+	StoreKeywords int
+
+	// Decide cache freshness from ETag/Last-Modified/Content-Length via HEAD, not file modtime
+	Revalidate bool
+
+	// Per-domain confidence overrides loaded from --trust-config (nil = built-in heuristic)
+	Trust *TrustConfig
+
+	// Elements the parser captures as content blocks, from --block-tags (nil = defaults)
+	BlockTags []string
+
+	// Keep <br> line breaks in text blocks (--canonicalize-whitespace=false)
+	KeepLineBreaks bool
+
+	// Sections nested deeper than this are flattened into their ancestor (0 = unlimited)
+	MaxSectionDepth int
+
+	// Detect each section's language (--detect-section-lang; slower, full-parse only)
+	SectionLanguages bool
+
+	// Collapse results whose content repeats an earlier result's, at or above this estimated
+	// similarity of their text (--dedupe-output --dedupe-threshold; 0 = off, 1 = identical only)
+	DedupeThreshold float64
+
+	// Check parsed pages with Page.Validate and log violations (--validate)
+	ValidatePages bool
+
+	// Fail pages flagged as soft 404s instead of storing them (--treat-soft-404-as-error)
+	FailSoft404 bool
+
+	// Block confidence weights loaded from --confidence-config (nil = built-in weights)
+	Confidence *ConfidenceConfig
+
+	// Parsed page encodings stored per URL, from --parsed-format: yaml, json or both
+	ParsedFormat string
+
+	// Write each top-level section to <url_id>/sections/ in this format, md or yaml
+	// (--split-sections --section-format; "" = off), cutting sections estimated above
+	// MaxSectionTokens into parts (0 = no limit)
+	SplitSections    string
+	MaxSectionTokens int
+
+	// Write chunks.jsonl with ChunkSize-token chunks of the page text, each repeating
+	// ChunkOverlap tokens of the one before (--chunk --chunk-size --chunk-overlap; 0 = off)
+	ChunkSize    int
+	ChunkOverlap int
+
+	// Write llm-summary.md, a digest templated by content type (--llm-summary)
+	LLMSummary bool
+
+	// Look up arXiv/DOI identifiers for canonical publication metadata (--enrich-academic)
+	EnrichAcademic bool
+
+	// User-Agent pool and rotation (fetcher.RotatePerRequest or RotatePerHost); empty sends Go's default
+	UserAgents      []string
+	UserAgentRotate string
+
+	// Retry and per-host circuit breaker settings
+	MaxRetries       int
+	RetryBaseDelay   time.Duration
+	MaxRetryAfter    time.Duration // Longest Retry-After honored; longer fails the URL (0 = no limit)
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Concurrent requests per host, halved on each 429/503 down to the minimum and grown
+	// back after sustained successes up to the maximum (--host-concurrency*; 0 = WorkerCount)
+	HostConcurrency    int
+	HostConcurrencyMin int
+	HostConcurrencyMax int
+
+	// Follow same-host links this many hops from the seed URLs (--follow-internal --depth;
+	// 0 = fetch only the given URLs), stopping once MaxURLs have been queued
+	FollowDepth int
+	MaxURLs     int
+
+	// Wall-clock budget for the whole run, from --timeout-overall (0 = unlimited)
+	TimeoutOverall time.Duration
+
+	// Parse fetched HTML in memory without writing raw.html (--no-store-raw)
+	NoStoreRaw bool
+
+	// Words left out of keyword counts on top of each page language's stopwords (--stopwords)
+	Stopwords []string
+
+	// Serve URLs whose last access, in any session, succeeded this recently from
+	// storage instead of the network (--skip-if-fetched-within; 0 = off)
+	SkipIfFetchedWithin time.Duration
 }
@@ -2,7 +2,9 @@ package models
 
 import (
 	"math"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	lingua "github.com/pemistahl/lingua-go"
 )
@@ -34,6 +36,9 @@ type Page struct {
 
 	// Word counts, section counts, language, etc
 	Metadata PageMetadata   `json:"metadata"`
+
+	// Images from the main content; written to images.yaml rather than generic.yaml
+	Images []Image `json:"-" yaml:"-"`
 }
 
 // Section represents a logical section of a document,
@@ -44,6 +49,7 @@ type Section struct {
 	Level    int            `json:"level"` // h1 = 1, h2 = 2, etc
 	Blocks   []ContentBlock `json:"blocks"`
 	Children []Section      `json:"children,omitempty"`
+	Language string         `json:"language,omitempty"` // ISO-639-1 of the section's own prose, with --detect-section-lang
 }
 
 // Table represents a data table extracted from HTML.
@@ -58,6 +64,21 @@ type Code struct {
 	Content  string `json:"content"`
 }
 
+// Math is a formula found as MathML or between TeX delimiters in the page text.
+type Math struct {
+	Notation   string `json:"notation" yaml:"notation"`                   // "mathml" | "tex"
+	Display    bool   `json:"display,omitempty" yaml:"display,omitempty"` // Set on its own line rather than inline with text
+	Expression string `json:"expression" yaml:"expression"`               // <math> markup, or TeX without its delimiters
+}
+
+// Image represents an image found in the page's main content.
+type Image struct {
+	Src    string `json:"src" yaml:"src"` // absolute URL
+	Alt    string `json:"alt,omitempty" yaml:"alt,omitempty"`
+	Width  int    `json:"width,omitempty" yaml:"width,omitempty"`
+	Height int    `json:"height,omitempty" yaml:"height,omitempty"`
+}
+
 // Link represents a hyperlink found in a content block.
 type Link struct {
 	Href string `json:"href"`
@@ -68,18 +89,39 @@ type Link struct {
 // ContentBlock represents a semantic block of content on a page.
 type ContentBlock struct {
 	ID    string `json:"id"`
-	Type  string `json:"type"`           // "p", "li", "table", "code", etc
+	Type  string `json:"type"`           // "p", "li", "table", "code", "math", etc
 	Text  string `json:"text,omitempty"` // fallback text
 
 	// Optional structured content
 	Table *Table `json:"table,omitempty"`
 	Code  *Code  `json:"code,omitempty"`
+	Math  *Math  `json:"math,omitempty"`
 
 	// extracted links scoped to this block
 	Links []Link `json:"links,omitempty"`
 
 	// LLM confidence Scores
 	Confidence float64 `json:"confidence"`
+
+	// Provenance in the readability content, for citing where a fact came from
+	Position int    `json:"position,omitempty"` // 1-based document order among all blocks the parser emitted
+	Path     string `json:"path,omitempty"`     // Element below <body> in the readability content (not the original page), e.g. "div[1]/p[3]"
+}
+
+// HeadingLevel returns 1-6 for h1-h6 block types, 0 otherwise.
+func HeadingLevel(blockType string) int {
+	if len(blockType) == 2 && blockType[0] == 'h' && blockType[1] >= '1' && blockType[1] <= '6' {
+		return int(blockType[1] - '0')
+	}
+	return 0
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// HeadingSlug lowercases a heading and joins its alphanumeric runs with hyphens, giving
+// sections an identity that survives edits to punctuation and case.
+func HeadingSlug(heading string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(heading), "-"), "-")
 }
 
 // MarshalYAML creates a compact YAML representation by omitting null/empty/default fields.
@@ -106,6 +148,11 @@ func (cb ContentBlock) MarshalYAML() (interface{}, error) {
 		m["code"] = cb.Code
 	}
 
+	// Include math only if present
+	if cb.Math != nil {
+		m["math"] = cb.Math
+	}
+
 	// Include links only if non-empty
 	if len(cb.Links) > 0 {
 		m["links"] = cb.Links
@@ -115,6 +162,14 @@ func (cb ContentBlock) MarshalYAML() (interface{}, error) {
 	// Even though 0.5 is common, filtering it causes issues when re-parsing
 	m["confidence"] = cb.Confidence
 
+	// Position and path survive the round trip, so TRACE can cite blocks of stored pages
+	if cb.Position > 0 {
+		m["position"] = cb.Position
+	}
+	if cb.Path != "" {
+		m["path"] = cb.Path
+	}
+
 	// Note: ID is intentionally omitted (sequential IDs not useful for LLM reading)
 
 	return m, nil
@@ -217,6 +272,66 @@ func (p *Page) ComputeMetadata() {
 }
 
 
+// DetectSectionLanguages sets each section's Language from its own heading and text
+// blocks (code is left out: its comments and identifiers are usually English whatever
+// the prose), and rolls the text up into Metadata.LanguageDistribution. Sections too
+// short to judge inherit their parent's language, top-level ones the page's.
+func (p *Page) DetectSectionLanguages() {
+	if len(p.Content) == 0 {
+		return
+	}
+
+	chars := make(map[string]int)
+	p.detectSectionLanguages(p.Content, p.Metadata.Language, chars)
+
+	total := 0
+	for _, n := range chars {
+		total += n
+	}
+	if total == 0 {
+		return
+	}
+	p.Metadata.LanguageDistribution = make(map[string]float64, len(chars))
+	for lang, n := range chars {
+		p.Metadata.LanguageDistribution[lang] = math.Round(float64(n)*100/float64(total)) / 100
+	}
+}
+
+// detectSectionLanguages counts characters rather than words so languages written
+// without spaces between words are not undercounted.
+func (p *Page) detectSectionLanguages(sections []Section, inherited string, chars map[string]int) {
+	for i := range sections {
+		s := &sections[i]
+		text := sectionProse(*s)
+		lang, _ := p.detectLanguage(text)
+		if lang == "unknown" && inherited != "" {
+			lang = inherited
+		}
+		s.Language = lang
+		if lang != "unknown" {
+			chars[lang] += utf8.RuneCountInString(strings.Join(strings.Fields(text), ""))
+		}
+		p.detectSectionLanguages(s.Children, lang, chars)
+	}
+}
+
+// sectionProse joins a section's heading and non-code blocks, without its children.
+func sectionProse(s Section) string {
+	var sb strings.Builder
+	if s.Heading != nil {
+		sb.WriteString(s.Heading.Text)
+		sb.WriteString("\n")
+	}
+	for _, block := range s.Blocks {
+		if block.Type == "code" || block.Type == "math" {
+			continue
+		}
+		sb.WriteString(block.Text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
 func (p *Page) countSectionsRecursive(sections []Section) int {
 	count := 0
 	for _, s := range sections {
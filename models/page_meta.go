@@ -7,6 +7,7 @@ type PageMetadata struct{
 	ContentSubtype string  `json:"content_subtype,omitempty"` // arxiv-paper, api-docs, reference, etc.
 	Language       string  `json:"language"`                  // ISO-639-1 if possible (e.g. "en")
 	LanguageConfidence float64 `json:"language_confidence,omitempty"`
+	LanguageDistribution map[string]float64 `json:"language_distribution,omitempty"` // Share of section text per language, with --detect-section-lang
 
 	// Keywords
 	MetaKeywords []string `json:"meta_keywords,omitempty"` // From HTML <meta name="keywords"> tags (author-supplied)
@@ -31,7 +32,10 @@ type PageMetadata struct{
 
 	// LLM signals
 	ExtractionMode     string  `json:"extraction_mode"`     // "cheap" | "full"
-	ExtractionQuality  string  `json:"extraction_quality"`  // "ok" | "low"
+	ExtractionQuality  string  `json:"extraction_quality"`  // "ok" | "low" | "degraded"
+	ContentSource      string  `json:"content_source,omitempty"` // "readability" | "body_fallback" | "selector"
+	TitleSource        string  `json:"title_source,omitempty"`   // "readability" | "og_title" | "h1" | "title_tag"
+	DocumentKind       string  `json:"document_kind,omitempty"`  // "html" | "xhtml" | "fragment" (sniffed from the markup)
 
 	// Readability enrichment (from go-readability)
 	Author        string `json:"author,omitempty"`
@@ -40,12 +44,19 @@ type PageMetadata struct{
 	PublishedTime string `json:"published_time,omitempty"` // ISO-8601 date
 	Favicon       string `json:"favicon,omitempty"`
 	Image         string `json:"image,omitempty"` // main image URL
+	ImageCount    int    `json:"image_count,omitempty"` // images in main content (excludes data URIs and tracking pixels)
+
+	// Freshness: the best publication or last-updated date (JSON-LD, meta tags, <time>, visible text)
+	ContentDate       string `json:"content_date,omitempty"`        // ISO-8601: YYYY-MM-DD, or RFC 3339 (UTC) when the page gives a time
+	ContentDateKind   string `json:"content_date_kind,omitempty"`   // "published" | "updated"
+	ContentDateSource string `json:"content_date_source,omitempty"` // "json-ld" | "meta" | "time" | "text" | "readability"
 
 	// Smart detection (from pkg/detector)
 	DomainType     string  `json:"domain_type,omitempty"`     // gov, edu, academic, commercial, mobile
 	DomainCategory string  `json:"domain_category,omitempty"` // gov/health, academic/ai, news/tech, docs/api, etc
 	Country        string  `json:"country,omitempty"`         // TLD-based: us, uk, de, jp, etc
 	Confidence     float64 `json:"confidence,omitempty"`      // 0-10 scale
+	TrustRule      string  `json:"trust_rule,omitempty"`      // --trust-config domain pattern that set or adjusted Confidence
 
 	// Academic signals
 	HasDOI         bool    `json:"has_doi,omitempty"`
@@ -63,5 +74,23 @@ type PageMetadata struct{
 	HTTPContentType string   `json:"http_content_type,omitempty"`
 	FinalURL        string   `json:"final_url,omitempty"` // after redirects
 	RedirectChain   []string `json:"redirect_chain,omitempty"`
+	CanonicalURL    string   `json:"canonical_url,omitempty"` // from <link rel="canonical">
+
+	// Content looks like a teaser for paywalled content (schema.org isAccessibleForFree
+	// or a "subscribe to continue" style cutoff), not the full article
+	Paywalled bool `json:"paywalled,omitempty"`
+
+	// Served with 200 but looks like an error or placeholder page ("404", "page not
+	// found") with little content
+	Soft404 bool `json:"soft_404,omitempty"`
+
+	// Indexing intent from <meta name="robots"> and X-Robots-Tag (nil = nothing declared)
+	Robots *RobotsDirectives `json:"robots,omitempty"`
+
+	// Social previews (OpenGraph / Twitter card <meta> tags)
+	Social *SocialMetadata `json:"social,omitempty"`
+
+	// Canonical paper metadata from arXiv/Crossref (fetch --enrich-academic)
+	Publication *PublicationMetadata `json:"publication,omitempty"`
 }
 
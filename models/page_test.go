@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestHeadingLevel(t *testing.T) {
+	tests := map[string]int{"h1": 1, "h6": 6, "h7": 0, "h": 0, "hr": 0, "p": 0, "h10": 0}
+	for blockType, want := range tests {
+		if got := HeadingLevel(blockType); got != want {
+			t.Errorf("HeadingLevel(%q) = %d, want %d", blockType, got, want)
+		}
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	tests := map[string]string{
+		"Getting Started":     "getting-started",
+		"  API: v2 (beta)!  ": "api-v2-beta",
+		"C++ & Go":            "c-go",
+		"¿Qué?":               "qu",
+		"---":                 "",
+	}
+	for heading, want := range tests {
+		if got := HeadingSlug(heading); got != want {
+			t.Errorf("HeadingSlug(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
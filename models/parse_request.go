@@ -7,8 +7,40 @@ type ParseRequest struct {
 	// Optional hints
 	Mode ParseMode `json:"mode,omitempty"`
 
+	// Strip script/style/noscript/comments before and after readability
+	CleanHTML bool `json:"clean_html,omitempty"`
+
+	// Extra CSS selectors stripped wherever script/style/noscript are, e.g. a site's
+	// cookie banner or share bar
+	CleanSelectors []string `json:"clean_selectors,omitempty"`
+
+	// Re-extract from the full <body> when readability yields fewer than this
+	// many characters of text (0 = never fall back)
+	MinContentLength int `json:"min_content_length,omitempty"`
+
+	// CSS selector whose first match is used as the content root instead of
+	// readability's output; readability is used when it matches nothing
+	ContentSelector string `json:"content_selector,omitempty"`
+
+	// Per-domain overrides for detector confidence (nil = built-in heuristic)
+	Trust *TrustConfig `json:"-"`
+
+	// Elements captured as content blocks, e.g. ["h1","h2","p"] (nil = the mode's default set)
+	BlockTags []string `json:"block_tags,omitempty"`
+
+	// Keep <br> line breaks inside text blocks instead of collapsing them to spaces
+	KeepLineBreaks bool `json:"keep_line_breaks,omitempty"`
+
+	// Flatten sections nested deeper than this into their ancestor (0 = unlimited)
+	MaxSectionDepth int `json:"max_section_depth,omitempty"`
+
+	// Detect each section's language and roll them up into a page distribution (full mode)
+	SectionLanguages bool `json:"section_languages,omitempty"`
+
+	// X-Robots-Tag header values of the response, one per header line
+	RobotsTags []string `json:"robots_tags,omitempty"`
+
 	// Optional future knobs
-	MaxDepth        int  `json:"max_depth,omitempty"`
 	ExtractLinks    bool `json:"extract_links,omitempty"`
 	RequireCitations bool `json:"require_citations,omitempty"`
 }
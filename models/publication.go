@@ -0,0 +1,14 @@
+package models
+
+// PublicationMetadata is the authoritative record for a paper, looked up by its arXiv ID
+// or DOI (fetch --enrich-academic) rather than scraped from the page.
+type PublicationMetadata struct {
+	Source    string   `json:"source" yaml:"source"` // arxiv or crossref
+	ArXivID   string   `json:"arxiv_id,omitempty" yaml:"arxiv_id,omitempty"`
+	DOI       string   `json:"doi,omitempty" yaml:"doi,omitempty"`
+	Title     string   `json:"title" yaml:"title"`
+	Authors   []string `json:"authors,omitempty" yaml:"authors,omitempty"`
+	Abstract  string   `json:"abstract,omitempty" yaml:"abstract,omitempty"`
+	Published string   `json:"published,omitempty" yaml:"published,omitempty"` // 2006-01-02, or a shorter prefix when the source has no day
+	Venue     string   `json:"venue,omitempty" yaml:"venue,omitempty"`         // Journal or proceedings (Crossref)
+}
@@ -0,0 +1,11 @@
+package models
+
+// RobotsDirectives are the indexing directives a page declares for all crawlers, from
+// <meta name="robots"> and the X-Robots-Tag response header. Directives aimed at one
+// crawler (<meta name="googlebot">, "googlebot: noindex") are ignored.
+type RobotsDirectives struct {
+	NoIndex    bool     `json:"noindex,omitempty" yaml:"noindex,omitempty"`   // noindex or none
+	NoFollow   bool     `json:"nofollow,omitempty" yaml:"nofollow,omitempty"` // nofollow or none
+	Directives []string `json:"directives" yaml:"directives"`                 // Lowercased, deduplicated: noindex, max-snippet:50, ...
+	Source     string   `json:"source" yaml:"source"`                         // meta, header, or meta+header
+}
@@ -0,0 +1,42 @@
+package models
+
+// SocialMetadata holds the OpenGraph (og:*) and Twitter card (twitter:*) tags a page
+// declares for link previews. Image URLs are resolved against the page URL.
+type SocialMetadata struct {
+	Title       string `json:"og_title,omitempty" yaml:"og_title,omitempty"`
+	Description string `json:"og_description,omitempty" yaml:"og_description,omitempty"`
+	Image       string `json:"og_image,omitempty" yaml:"og_image,omitempty"`
+	Type        string `json:"og_type,omitempty" yaml:"og_type,omitempty"` // article, website, video.movie, ...
+	URL         string `json:"og_url,omitempty" yaml:"og_url,omitempty"`
+	SiteName    string `json:"og_site_name,omitempty" yaml:"og_site_name,omitempty"`
+	Locale      string `json:"og_locale,omitempty" yaml:"og_locale,omitempty"`
+
+	TwitterCard        string `json:"twitter_card,omitempty" yaml:"twitter_card,omitempty"` // summary, summary_large_image, player, app
+	TwitterTitle       string `json:"twitter_title,omitempty" yaml:"twitter_title,omitempty"`
+	TwitterDescription string `json:"twitter_description,omitempty" yaml:"twitter_description,omitempty"`
+	TwitterImage       string `json:"twitter_image,omitempty" yaml:"twitter_image,omitempty"`
+	TwitterSite        string `json:"twitter_site,omitempty" yaml:"twitter_site,omitempty"`       // @handle of the site
+	TwitterCreator     string `json:"twitter_creator,omitempty" yaml:"twitter_creator,omitempty"` // @handle of the author
+}
+
+// PreviewDescription returns the og:description, falling back to twitter:description.
+func (s *SocialMetadata) PreviewDescription() string {
+	if s == nil {
+		return ""
+	}
+	if s.Description != "" {
+		return s.Description
+	}
+	return s.TwitterDescription
+}
+
+// PreviewImage returns the og:image, falling back to twitter:image.
+func (s *SocialMetadata) PreviewImage() string {
+	if s == nil {
+		return ""
+	}
+	if s.Image != "" {
+		return s.Image
+	}
+	return s.TwitterImage
+}
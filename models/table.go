@@ -0,0 +1,55 @@
+package models
+
+import "fmt"
+
+// RecordKeys returns the key used for each column when converting rows to records.
+// Missing or empty headers become col1, col2, ... (1-based column index), and
+// repeated header names get a numeric suffix so no column overwrites another.
+func (t *Table) RecordKeys() []string {
+	width := len(t.Headers)
+	for _, row := range t.Rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	keys := make([]string, width)
+	seen := make(map[string]int, width)
+	for i := range keys {
+		key := ""
+		if i < len(t.Headers) {
+			key = t.Headers[i]
+		}
+		if key == "" {
+			key = fmt.Sprintf("col%d", i+1)
+		}
+		if n := seen[key]; n > 0 {
+			seen[key] = n + 1
+			key = fmt.Sprintf("%s_%d", key, n+1)
+		} else {
+			seen[key] = 1
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// ToRecords maps each row to a header-keyed record (see RecordKeys).
+// Ragged rows are tolerated: short rows omit the missing columns and long rows
+// get synthesized keys for the extra cells.
+func (t *Table) ToRecords() []map[string]string {
+	if t == nil {
+		return nil
+	}
+
+	keys := t.RecordKeys()
+	records := make([]map[string]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		record := make(map[string]string, len(row))
+		for i, cell := range row {
+			record[keys[i]] = cell
+		}
+		records = append(records, record)
+	}
+	return records
+}
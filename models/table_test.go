@@ -0,0 +1,59 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableToRecords(t *testing.T) {
+	tests := []struct {
+		name  string
+		table Table
+		want  []map[string]string
+	}{
+		{
+			name: "header keyed rows",
+			table: Table{
+				Headers: []string{"Name", "Version"},
+				Rows:    [][]string{{"go", "1.22"}, {"rust", "1.77"}},
+			},
+			want: []map[string]string{
+				{"Name": "go", "Version": "1.22"},
+				{"Name": "rust", "Version": "1.77"},
+			},
+		},
+		{
+			name:  "missing headers synthesize column keys",
+			table: Table{Rows: [][]string{{"a", "b"}}},
+			want:  []map[string]string{{"col1": "a", "col2": "b"}},
+		},
+		{
+			name: "ragged rows",
+			table: Table{
+				Headers: []string{"Name", "Version"},
+				Rows:    [][]string{{"go"}, {"rust", "1.77", "stable"}},
+			},
+			want: []map[string]string{
+				{"Name": "go"},
+				{"Name": "rust", "Version": "1.77", "col3": "stable"},
+			},
+		},
+		{
+			name: "empty and duplicate headers",
+			table: Table{
+				Headers: []string{"Value", "", "Value"},
+				Rows:    [][]string{{"1", "2", "3"}},
+			},
+			want: []map[string]string{{"Value": "1", "col2": "2", "Value_2": "3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.table.ToRecords()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToRecords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+// TrustConfig holds per-domain overrides for the detector's source confidence, loaded
+// from the YAML file given to --trust-config. The first rule matching a host applies.
+type TrustConfig struct {
+	Rules []TrustRule `yaml:"rules"`
+}
+
+// TrustRule sets or adjusts confidence for hosts matching Domain. "*.gov" matches any
+// host under gov; "medium.com" matches medium.com and its subdomains.
+type TrustRule struct {
+	Domain string   `yaml:"domain"`
+	Set    *float64 `yaml:"set,omitempty"`    // Replace the heuristic score (0-10)
+	Adjust float64  `yaml:"adjust,omitempty"` // Add to the heuristic score
+}
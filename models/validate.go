@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Validate checks the structural invariants the parser is meant to uphold and returns
+// one error per violation (nil for a well-formed page):
+//   - a section below the top level (Level > 0) has its heading, and nests deeper
+//     than its parent
+//   - section and block IDs are unique within the page
+//   - a block carries at most one of Table, Code and Math
+//   - block confidences are numbers within [0, 1]
+//
+// Violations point at parser bugs; the page is still usable, so callers log them
+// rather than drop it (fetch --validate).
+func (p *Page) Validate() []error {
+	v := pageValidator{seen: make(map[string]string)}
+	for i := range p.Content {
+		v.section(&p.Content[i], -1)
+	}
+	for i := range p.FlatContent {
+		v.block(&p.FlatContent[i], "flat_content")
+	}
+	return v.errs
+}
+
+// pageValidator walks a page collecting violations.
+type pageValidator struct {
+	errs []error
+	seen map[string]string // ID -> where it was first seen
+}
+
+func (v *pageValidator) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+// id records an ID, flagging it if another section or block already used it.
+func (v *pageValidator) id(id, where string) {
+	if id == "" {
+		return
+	}
+	if first, ok := v.seen[id]; ok {
+		v.addf("%s: duplicate id %q (first used by %s)", where, id, first)
+		return
+	}
+	v.seen[id] = where
+}
+
+func (v *pageValidator) section(s *Section, parentLevel int) {
+	where := fmt.Sprintf("section %q", s.ID)
+	v.id(s.ID, where)
+
+	switch {
+	case s.Level < 0:
+		v.addf("%s: negative level %d", where, s.Level)
+	case s.Level > 0 && s.Heading == nil:
+		v.addf("%s: level %d section has no heading", where, s.Level)
+	}
+	if parentLevel >= 0 && s.Level <= parentLevel {
+		v.addf("%s: level %d nested under a level %d section", where, s.Level, parentLevel)
+	}
+
+	if s.Heading != nil {
+		v.block(s.Heading, where+" heading")
+	}
+	for i := range s.Blocks {
+		v.block(&s.Blocks[i], where)
+	}
+	for i := range s.Children {
+		v.section(&s.Children[i], s.Level)
+	}
+}
+
+func (v *pageValidator) block(b *ContentBlock, container string) {
+	where := fmt.Sprintf("block %q in %s", b.ID, container)
+	v.id(b.ID, where)
+
+	payloads := 0
+	for _, set := range []bool{b.Table != nil, b.Code != nil, b.Math != nil} {
+		if set {
+			payloads++
+		}
+	}
+	if payloads > 1 {
+		v.addf("%s: %q block has %d of table, code and math set", where, b.Type, payloads)
+	}
+
+	if math.IsNaN(b.Confidence) || b.Confidence < 0 || b.Confidence > 1 {
+		v.addf("%s: confidence %v outside [0, 1]", where, b.Confidence)
+	}
+}
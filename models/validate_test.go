@@ -0,0 +1,67 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// validPage is a small well-formed page; each test case breaks one invariant.
+func validPage() *Page {
+	return &Page{
+		URL: "https://example.com/doc",
+		Content: []Section{
+			{
+				ID:      "section-1",
+				Level:   1,
+				Heading: &ContentBlock{ID: "block-1", Type: "h1", Text: "Guide", Confidence: 0.7},
+				Blocks: []ContentBlock{
+					{ID: "block-2", Type: "p", Text: "Intro", Confidence: 0.6},
+					{ID: "block-3", Type: "code", Code: &Code{Content: "go run ."}, Confidence: 0.9},
+				},
+				Children: []Section{{
+					ID:      "section-2",
+					Level:   2,
+					Heading: &ContentBlock{ID: "block-4", Type: "h2", Text: "Install", Confidence: 0.7},
+					Blocks:  []ContentBlock{{ID: "block-5", Type: "table", Table: &Table{Rows: [][]string{{"a"}}}, Confidence: 0.9}},
+				}},
+			},
+		},
+	}
+}
+
+func TestPageValidate(t *testing.T) {
+	if errs := validPage().Validate(); len(errs) != 0 {
+		t.Fatalf("Validate() on a well-formed page = %v, want none", errs)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *Page)
+		want   string
+	}{
+		{"heading section without heading", func(p *Page) { p.Content[0].Children[0].Heading = nil }, `section "section-2": level 2 section has no heading`},
+		{"child not deeper than parent", func(p *Page) { p.Content[0].Children[0].Level = 1 }, "level 1 nested under a level 1 section"},
+		{"negative level", func(p *Page) { p.Content[0].Level = -1 }, "negative level -1"},
+		{"duplicate block id", func(p *Page) { p.Content[0].Blocks[1].ID = "block-2" }, `duplicate id "block-2"`},
+		{"table and code on one block", func(p *Page) { p.Content[0].Blocks[1].Table = &Table{} }, `"code" block has 2 of table, code and math set`},
+		{"negative confidence", func(p *Page) { p.Content[0].Blocks[0].Confidence = -0.1 }, "confidence -0.1 outside [0, 1]"},
+		{"NaN confidence", func(p *Page) { p.Content[0].Children[0].Blocks[0].Confidence = math.NaN() }, "confidence NaN outside [0, 1]"},
+		{"flat content checked too", func(p *Page) {
+			p.FlatContent = []ContentBlock{{ID: "block-9", Type: "p", Confidence: 1.5}}
+		}, `block "block-9" in flat_content: confidence 1.5`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := validPage()
+			tt.mutate(page)
+			errs := page.Validate()
+			if len(errs) != 1 {
+				t.Fatalf("Validate() = %v, want exactly one violation", errs)
+			}
+			if !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("Validate() = %q, want it to contain %q", errs[0], tt.want)
+			}
+		})
+	}
+}
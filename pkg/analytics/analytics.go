@@ -3,12 +3,16 @@ package analytics
 import (
 	"sort"
 	"strings"
+	"unicode"
 )
 
-type Analytics struct{}
+type Analytics struct {
+	// Stopwords are user additions ignored on top of each language's list
+	Stopwords []string
+}
 
 // commonWords is a map of frequently occurring words that should be ignored in frequency analysis.
-// This list can be extended as needed.
+// It is the English list and the default; other languages are bundled in stopwords/.
 var commonWords = map[string]struct{}{
 	"a": {}, "about": {}, "above": {}, "across": {}, "after": {}, "afterwards": {},
 	"again": {}, "against": {}, "all": {}, "almost": {}, "alone": {}, "along": {},
@@ -145,19 +149,30 @@ func IsStopword(word string) bool {
 	return exists
 }
 
+// trimWord strips the punctuation around a word, keeping letters and digits in any script.
+func trimWord(word string) string {
+	return strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// WordFrequency counts the words in text, leaving out English stopwords.
 func (a *Analytics) WordFrequency(text string) map[string]int {
+	return a.WordFrequencyIn(text, "en")
+}
+
+// WordFrequencyIn counts the words in text, leaving out the stopwords of lang (an
+// ISO 639-1 code, see StopwordsFor) and a.Stopwords.
+func (a *Analytics) WordFrequencyIn(text, lang string) map[string]int {
+	stopwords := StopwordsFor(lang, a.Stopwords...)
 	words := strings.Fields(strings.ToLower(text)) // strings.Fields handles multiple spaces and newlines
 	frequencies := make(map[string]int)
 
 	for _, word := range words {
-		// Remove punctuation from words
-		word = strings.TrimFunc(word, func(r rune) bool {
-			// Keep only lowercase letters and numbers
-			return ('a' > r || r > 'z') && ('0' > r || r > '9')
-		})
+		word = trimWord(word)
 
 		// Skip if it's a common word or empty after cleaning
-		if _, exists := commonWords[word]; exists || word == "" {
+		if _, exists := stopwords[word]; exists || word == "" {
 			continue
 		}
 
@@ -196,3 +211,60 @@ func (a *Analytics) TopNWords(text string, n int) []string {
 
 	return topN
 }
+
+// WordForms counts the original-case spellings behind each word WordFrequency counts,
+// e.g. {"api": {"API": 9, "api": 1}}, so keyword lists can show "API" rather than "api".
+func (a *Analytics) WordForms(text string) map[string]map[string]int {
+	return a.wordForms(text, StopwordsFor("en", a.Stopwords...))
+}
+
+func (a *Analytics) wordForms(text string, stopwords map[string]struct{}) map[string]map[string]int {
+	forms := make(map[string]map[string]int)
+	for _, field := range strings.Fields(text) {
+		word := trimWord(strings.ToLower(field))
+		if _, exists := stopwords[word]; exists || word == "" {
+			continue
+		}
+		form := trimWord(field)
+		if strings.ToLower(form) != word {
+			form = word // Lowercasing changed how the word trims; keep the counted form
+		}
+		if forms[word] == nil {
+			forms[word] = make(map[string]int)
+		}
+		forms[word][form]++
+	}
+	return forms
+}
+
+// DominantForm returns the spelling of word used for more than half of its occurrences
+// in forms, or word itself (lowercase) when no spelling dominates.
+func DominantForm(word string, forms map[string]int) string {
+	total := 0
+	for _, count := range forms {
+		total += count
+	}
+	for form, count := range forms {
+		if count*2 > total {
+			return form
+		}
+	}
+	return word
+}
+
+// DisplayForms maps each word in text whose dominant spelling is not all lowercase to
+// that spelling. Words missing from the map display as counted.
+func (a *Analytics) DisplayForms(text string) map[string]string {
+	return a.DisplayFormsIn(text, "en")
+}
+
+// DisplayFormsIn is DisplayForms for the words WordFrequencyIn counts in lang.
+func (a *Analytics) DisplayFormsIn(text, lang string) map[string]string {
+	display := make(map[string]string)
+	for word, forms := range a.wordForms(text, StopwordsFor(lang, a.Stopwords...)) {
+		if form := DominantForm(word, forms); form != word {
+			display[word] = form
+		}
+	}
+	return display
+}
@@ -0,0 +1,110 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDisplayForms(t *testing.T) {
+	text := "The API returns JSON. Call the API over HTTP; the api key is optional. " +
+		"Json or XML? Go, go, GO. Parsers parse."
+	a := &Analytics{}
+
+	got := a.DisplayForms(text)
+	want := map[string]string{
+		"api":     "API",     // 2 of 3
+		"http":    "HTTP",    // 1 of 1
+		"xml":     "XML",     // 1 of 1
+		"parsers": "Parsers", // 1 of 1
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DisplayForms() = %v, want %v", got, want)
+	}
+
+	// Display forms never change how words are counted
+	if counts := a.WordFrequency(text); counts["api"] != 3 || counts["json"] != 2 || counts["go"] != 3 {
+		t.Errorf("WordFrequency() api=%d json=%d go=%d, want 3, 2, 3", counts["api"], counts["json"], counts["go"])
+	}
+}
+
+func TestDominantForm(t *testing.T) {
+	tests := []struct {
+		name  string
+		word  string
+		forms map[string]int
+		want  string
+	}{
+		{"majority spelling", "api", map[string]int{"API": 9, "api": 1}, "API"},
+		{"lowercase majority", "api", map[string]int{"API": 1, "api": 9}, "api"},
+		{"even split falls back to lowercase", "json", map[string]int{"JSON": 2, "Json": 2}, "json"},
+		{"no forms", "json", nil, "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DominantForm(tt.word, tt.forms); got != tt.want {
+				t.Errorf("DominantForm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopwordsFor(t *testing.T) {
+	if got := StopwordLanguages(); len(got) != 11 || got[0] != "en" {
+		t.Errorf("StopwordLanguages() = %v, want en plus 10 bundled lists", got)
+	}
+	for _, lang := range StopwordLanguages() {
+		if len(StopwordsFor(lang)) < 50 {
+			t.Errorf("StopwordsFor(%q) has %d words, want a full list", lang, len(StopwordsFor(lang)))
+		}
+	}
+
+	tests := []struct {
+		lang, word string
+		want       bool
+	}{
+		{"de", "und", true},
+		{"DE", "und", true},
+		{"de", "the", false},
+		{"fr", "très", true},
+		{"ru", "что", true},
+		{"en", "the", true},
+		{"unknown", "the", true},
+		{"ja", "the", true}, // No bundled list: English
+	}
+	for _, tt := range tests {
+		if _, got := StopwordsFor(tt.lang)[tt.word]; got != tt.want {
+			t.Errorf("StopwordsFor(%q)[%q] = %v, want %v", tt.lang, tt.word, got, tt.want)
+		}
+	}
+
+	merged := StopwordsFor("de", " Widget ", "")
+	if _, ok := merged["widget"]; !ok {
+		t.Error("extra stopword not merged")
+	}
+	if _, ok := merged["und"]; !ok {
+		t.Error("merged set lost the language's own stopwords")
+	}
+	if _, ok := StopwordsFor("de")["widget"]; ok {
+		t.Error("extra stopword leaked into the shared list")
+	}
+}
+
+func TestWordFrequencyIn(t *testing.T) {
+	a := &Analytics{Stopwords: []string{"parser"}}
+
+	german := a.WordFrequencyIn("Der Parser liest die Seite und die Tabelle.", "de")
+	if _, ok := german["und"]; ok {
+		t.Errorf("German counts %v include the stopword und", german)
+	}
+	if german["tabelle"] != 1 || german["parser"] != 0 {
+		t.Errorf("German counts = %v, want tabelle and no parser", german)
+	}
+
+	russian := a.WordFrequencyIn("Это парсер, и это таблица.", "ru")
+	if russian["таблица"] != 1 || russian["это"] != 0 {
+		t.Errorf("Russian counts = %v, want таблица counted and это dropped", russian)
+	}
+	if display := a.DisplayFormsIn("Das Modell von Berlin. Berlin!", "de"); display["berlin"] != "Berlin" {
+		t.Errorf("DisplayFormsIn() = %v, want Berlin", display)
+	}
+}
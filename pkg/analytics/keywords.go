@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"html"
+	"strings"
+)
+
+// keywordReplacer straightens the typography that leaks into keyword data from pages
+// the parser did not clean: curly apostrophes become ASCII, curly double quotes and
+// non-breaking spaces go.
+var keywordReplacer = strings.NewReplacer(
+	"’", "'",
+	"‘", "'",
+	"“", "",
+	"”", "",
+	" ", " ",
+)
+
+// residualEntities are entity names that double-encoded pages leave behind as words
+// once "&amp;nbsp;" is tokenized.
+var residualEntities = map[string]struct{}{
+	"amp": {}, "nbsp": {}, "quot": {}, "apos": {}, "x27": {},
+	"lsquo": {}, "rsquo": {}, "ldquo": {}, "rdquo": {},
+	"ndash": {}, "mdash": {}, "hellip": {},
+}
+
+// NormalizeKeyword cleans one stored keyword: entities decoded, curly quotes
+// straightened, lowercased, surrounding punctuation and a possessive "'s" trimmed. It
+// returns false when nothing worth keeping is left: an empty word, a leftover entity
+// name, or a word in stopwords (see StopwordsFor).
+func NormalizeKeyword(word string, stopwords map[string]struct{}) (string, bool) {
+	if strings.IndexByte(word, '&') >= 0 {
+		word = html.UnescapeString(word)
+	}
+	word = trimWord(strings.ToLower(keywordReplacer.Replace(word)))
+	if _, stop := stopwords[word]; stop {
+		return "", false // Checked before trimming "'s" so contractions like "it's" go
+	}
+	word = strings.TrimSuffix(word, "'s")
+
+	if word == "" {
+		return "", false
+	}
+	if _, entity := residualEntities[word]; entity {
+		return "", false
+	}
+	if _, stop := stopwords[word]; stop {
+		return "", false
+	}
+	return word, true
+}
+
+// Stem reduces an English plural to its singular with the conservative S-stemmer
+// (Harman, 1991): "libraries" -> "library", "caches" -> "cache", "apis" -> "api".
+// Words of three letters or fewer, and words ending in "us" or "ss", are left alone.
+func Stem(word string) string {
+	if len(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "ies") && !strings.HasSuffix(word, "eies") && !strings.HasSuffix(word, "aies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "es") && !strings.HasSuffix(word, "aes") && !strings.HasSuffix(word, "ees") && !strings.HasSuffix(word, "oes"):
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
@@ -0,0 +1,46 @@
+package analytics
+
+import "testing"
+
+func TestNormalizeKeyword(t *testing.T) {
+	stopwords := StopwordsFor("en", "cookie")
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"API", "api", true},
+		{"“quoted”", "quoted", true},
+		{"Go’s", "go", true},
+		{"don’t", "", false}, // Stopword once the apostrophe is straightened
+		{"it's", "", false},
+		{"&amp;", "", false},
+		{"nbsp", "", false},
+		{"Cookie", "", false}, // Caller-supplied stopword
+		{"(kubernetes)", "kubernetes", true},
+		{"---", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeKeyword(tt.in, stopwords)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeKeyword(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"libraries": "library",
+		"caches":    "cache",
+		"apis":      "api",
+		"status":    "status",
+		"class":     "class",
+		"bus":       "bus",
+		"its":       "its",
+	}
+	for in, want := range tests {
+		if got := Stem(in); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import (
+	"embed"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// stopwordFiles bundles a stopword list per language, named by ISO 639-1 code. Each file
+// holds whitespace-separated lowercase words; lines starting with # are comments.
+//
+//go:embed stopwords/*.txt
+var stopwordFiles embed.FS
+
+var (
+	bundledOnce sync.Once
+	bundled     map[string]map[string]struct{}
+)
+
+// loadBundled parses every embedded list once.
+func loadBundled() {
+	bundled = make(map[string]map[string]struct{})
+	entries, _ := stopwordFiles.ReadDir("stopwords")
+	for _, entry := range entries {
+		data, err := stopwordFiles.ReadFile("stopwords/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		words := make(map[string]struct{})
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			for _, word := range strings.Fields(line) {
+				words[strings.ToLower(word)] = struct{}{}
+			}
+		}
+		bundled[strings.TrimSuffix(entry.Name(), ".txt")] = words
+	}
+}
+
+// StopwordLanguages returns the ISO 639-1 codes with a bundled list, English included.
+func StopwordLanguages() []string {
+	bundledOnce.Do(loadBundled)
+	langs := []string{"en"}
+	for lang := range bundled {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs[1:])
+	return langs
+}
+
+// StopwordsFor returns the stopwords for an ISO 639-1 language code with extra merged on
+// top. English, and any language without a bundled list ("unknown" included), uses the
+// English list. Without extra the shared set is returned, so callers must not modify it.
+func StopwordsFor(lang string, extra ...string) map[string]struct{} {
+	bundledOnce.Do(loadBundled)
+	words, ok := bundled[strings.ToLower(lang)]
+	if !ok {
+		words = commonWords
+	}
+	if len(extra) == 0 {
+		return words
+	}
+
+	merged := make(map[string]struct{}, len(words)+len(extra))
+	for word := range words {
+		merged[word] = struct{}{}
+	}
+	for _, word := range extra {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			merged[word] = struct{}{}
+		}
+	}
+	return merged
+}
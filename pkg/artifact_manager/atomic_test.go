@@ -0,0 +1,72 @@
+package artifact_manager
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "generic.yaml")
+
+	if err := WriteFileAtomic(path, []byte("title: first\n"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("title: second\n"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() overwrite error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "title: second\n" {
+		t.Errorf("file = %q, %v; want the second write", data, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, %v; want 0644", info.Mode().Perm(), err)
+	}
+	assertOnlyFile(t, dir, "generic.yaml")
+}
+
+func TestWriteFileAtomic_FailedWriteKeepsPriorFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wordcount.txt")
+	if err := WriteFileAtomic(path, []byte("api:12\nhttp:4\n"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	// The disk fills up halfway through the replacement
+	errDiskFull := errors.New("no space left on device")
+	err := writeFileAtomic(path, 0644, func(w io.Writer) error {
+		if _, err := w.Write([]byte("api:1")); err != nil {
+			return err
+		}
+		return errDiskFull
+	})
+	if !errors.Is(err, errDiskFull) {
+		t.Fatalf("writeFileAtomic() error = %v, want %v", err, errDiskFull)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "api:12\nhttp:4\n" {
+		t.Errorf("file = %q, %v; want the prior contents", data, err)
+	}
+	assertOnlyFile(t, dir, "wordcount.txt")
+}
+
+// assertOnlyFile fails unless name is the only entry in dir (no temporary files left over).
+func assertOnlyFile(t *testing.T, dir, name string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != name {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %v, want only %s", names, name)
+	}
+}
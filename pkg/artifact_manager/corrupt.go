@@ -0,0 +1,49 @@
+package artifact_manager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// CorruptArtifactError reports a stored artifact that exists but cannot be decoded,
+// typically a file truncated by an interrupted write.
+type CorruptArtifactError struct {
+	Path string
+	Err  error
+}
+
+func (e *CorruptArtifactError) Error() string {
+	return fmt.Sprintf("corrupt_artifact: cannot parse %s: %v (re-fetch the URL to rewrite it)", e.Path, e.Err)
+}
+
+func (e *CorruptArtifactError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeParsedPage decodes a stored parsed page read from path with unmarshal
+// (yaml.Unmarshal or json.Unmarshal). An empty or undecodable file is a *CorruptArtifactError.
+func DecodeParsedPage(path string, data []byte, unmarshal func([]byte, interface{}) error) (*models.Page, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, &CorruptArtifactError{Path: path, Err: errors.New("file is empty")}
+	}
+	var page models.Page
+	if err := unmarshal(data, &page); err != nil {
+		return nil, &CorruptArtifactError{Path: path, Err: err}
+	}
+	return &page, nil
+}
+
+// QuarantineArtifact moves a corrupt artifact aside to path.corrupt, replacing an older
+// quarantined copy, so readers find it missing and the next fetch writes it afresh.
+// It returns the new path.
+func QuarantineArtifact(path string) (string, error) {
+	quarantined := path + ".corrupt"
+	if err := os.Rename(path, quarantined); err != nil {
+		return "", fmt.Errorf("failed to quarantine %s: %w", path, err)
+	}
+	return quarantined, nil
+}
@@ -0,0 +1,54 @@
+package artifact_manager
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadParsedPage_CorruptArtifact(t *testing.T) {
+	tests := []struct {
+		name, file, data string
+	}{
+		{"truncated yaml", "generic.yaml", "url: https://example.com/guide\ntitle: \"Widgets and gad"},
+		{"empty yaml", "generic.yaml", ""},
+		{"truncated json", "generic.json", `{"url": "https://example.com/guide", "title": "Widg`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDir := t.TempDir()
+			manager, err := NewManager(baseDir, 0)
+			if err != nil {
+				t.Fatalf("NewManager() error = %v", err)
+			}
+			if err := manager.EnsureURLDir(7); err != nil {
+				t.Fatalf("EnsureURLDir() error = %v", err)
+			}
+			path := GetURLArtifactPath(baseDir, 7, tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			page, found, err := ReadParsedPage(baseDir, 7)
+			var corrupt *CorruptArtifactError
+			if !errors.As(err, &corrupt) || page != nil || found {
+				t.Fatalf("ReadParsedPage() = %v, %v, %v; want a CorruptArtifactError", page, found, err)
+			}
+			if corrupt.Path != path || !strings.HasPrefix(err.Error(), "corrupt_artifact: ") {
+				t.Errorf("error = %q, want corrupt_artifact naming %s", err, path)
+			}
+
+			moved, err := QuarantineArtifact(corrupt.Path)
+			if err != nil {
+				t.Fatalf("QuarantineArtifact() error = %v", err)
+			}
+			if data, err := os.ReadFile(moved); err != nil || string(data) != tt.data {
+				t.Errorf("quarantined file = %q, %v; want the original bytes", data, err)
+			}
+			if _, found, err := ReadParsedPage(baseDir, 7); found || err != nil {
+				t.Errorf("ReadParsedPage() after quarantine = %v, %v; want not found", found, err)
+			}
+		})
+	}
+}
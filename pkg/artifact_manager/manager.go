@@ -2,14 +2,20 @@ package artifact_manager
 
 import (
 	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/urlnorm"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -17,6 +23,7 @@ const (
 	SessionsDir    = "lwp-sessions" // Separate from results
 	RawHTMLDir     = "raw"           // Legacy, will be deprecated
 	ParsedJSONDir  = "parsed"        // Legacy, will be deprecated
+	EnrichDir      = "enrich"        // Cached publication metadata lookups
 )
 
 // GetURLDir returns the directory for a specific URL ID (URL-centric structure).
@@ -37,6 +44,7 @@ func GetURLArtifactPath(baseDir string, urlID int64, artifact string) string {
 // Manager handles storage and retrieval of web artifacts.
 type Manager struct {
 	baseDir string
+	store   Store         // Where artifacts live; a FileStore on baseDir unless NewManagerWithStore
 	maxAge  time.Duration // Max age for a stored artifact before it's considered stale
 }
 
@@ -54,43 +62,22 @@ func NewManager(baseDir string, maxAge time.Duration) (*Manager, error) {
 		return nil, fmt.Errorf("failed to create parsed JSON directory: %w", err)
 	}
 
-	return &Manager{baseDir: baseDir, maxAge: maxAge}, nil
+	return &Manager{baseDir: baseDir, store: NewFileStore(baseDir), maxAge: maxAge}, nil
 }
 
-// normalizeURL creates a canonical representation of a URL for consistent hashing.
-func normalizeURL(rawURL string) (string, error) {
-	u, err := url.Parse(rawURL)
-	if err != nil {
-		return "", fmt.Errorf("invalid URL: %w", err)
-	}
-
-	// Always use HTTPS if possible
-	if u.Scheme == "http" {
-		u.Scheme = "https"
-	}
-	u.Host = strings.ToLower(u.Host) // Lowercase host
-
-	// Sort query parameters alphabetically
-	if u.RawQuery != "" {
-		params := u.Query()
-		var keys []string
-		for k := range params {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys) // Sort keys
-		sortedQuery := url.Values{}
-		for _, k := range keys {
-			for _, v := range params[k] {
-				sortedQuery.Add(k, v)
-			}
-		}
-		u.RawQuery = sortedQuery.Encode()
+// NewManagerWithStore creates a Manager that keeps artifacts in store, e.g. object
+// storage shared between machines. baseDir still anchors the path-based helpers
+// (GetArtifactPath, EnrichCacheDir), which only mean something for a FileStore.
+func NewManagerWithStore(store Store, baseDir string, maxAge time.Duration) *Manager {
+	if baseDir == "" {
+		baseDir = DefaultBaseDir
 	}
+	return &Manager{baseDir: baseDir, store: store, maxAge: maxAge}
+}
 
-	// Strip fragment
-	u.Fragment = ""
-
-	return u.String(), nil
+// Store returns the backend the manager reads and writes artifacts through.
+func (m *Manager) Store() Store {
+	return m.store
 }
 
 // getShortHash generates a short, stable hash from a normalized URL.
@@ -99,6 +86,11 @@ func getShortHash(normalizedURL string) string {
 	return fmt.Sprintf("%x", hash[:6]) // Use first 6 bytes for a 12-char hex string
 }
 
+// ContentHash is the SHA-256 of data as a hex string, as recorded in artifacts.content_hash.
+func ContentHash(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
 // sanitizeSlug creates a filesystem-safe slug from a URL path.
 var invalidFilenameChar = regexp.MustCompile(`[^a-zA-Z0-9\-_]+`)
 func sanitizeSlug(rawURL string) string {
@@ -122,95 +114,105 @@ func sanitizeSlug(rawURL string) string {
 
 // GetArtifactPath constructs a full path for an artifact based on its type.
 func (m *Manager) GetArtifactPath(artifactDir, url string, ext string) (string, error) {
-    normalizedURL, err := normalizeURL(url)
-    if err != nil {
-        return "", err
-    }
+	key, err := artifactKey(artifactDir, url, ext)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.baseDir, filepath.FromSlash(key)), nil
+}
+
+// artifactKey is the slug layout key of an artifact: <artifactDir>/<slug>-<hash><ext>.
+func artifactKey(artifactDir, url string, ext string) (string, error) {
+	normalizedURL, err := urlnorm.NormalizeURL(url)
+	if err != nil {
+		return "", err
+	}
 	slug := sanitizeSlug(url) // Use original URL for slug for human readability
 	shortHash := getShortHash(normalizedURL)
 
-	filename := fmt.Sprintf("%s-%s%s", slug, shortHash, ext)
-	return filepath.Join(m.baseDir, artifactDir, filename), nil
+	return fmt.Sprintf("%s/%s-%s%s", artifactDir, slug, shortHash, ext), nil
 }
 
-// GetRawHTML retrieves raw HTML from storage if fresh.
-func (m *Manager) GetRawHTML(url string) ([]byte, bool, error) {
-	filePath, err := m.GetArtifactPath(RawHTMLDir, url, ".html")
-    if err != nil {
-        return nil, false, err
-    }
-    
-	info, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
+// getFresh reads key when it exists and is younger than maxAge (any age when maxAge <= 0).
+func (m *Manager) getFresh(key, what string) ([]byte, bool, error) {
+	info, err := m.store.Stat(key)
+	if errors.Is(err, fs.ErrNotExist) {
 		return nil, false, nil // Not found
 	}
 	if err != nil {
-		return nil, false, fmt.Errorf("error statting raw HTML artifact: %w", err)
+		return nil, false, fmt.Errorf("error statting %s: %w", what, err)
 	}
 
-	if m.maxAge > 0 && time.Since(info.ModTime()) > m.maxAge {
+	if m.maxAge > 0 && time.Since(info.ModTime) > m.maxAge {
 		return nil, false, nil // Stale
 	}
-    // If maxAge is negative, it means "never expire"
-    // In this case, always fresh if exists.
 
-	data, err := os.ReadFile(filepath.Clean(filePath))
+	return m.get(key, what)
+}
+
+// get reads key regardless of age.
+func (m *Manager) get(key, what string) ([]byte, bool, error) {
+	data, err := m.store.Get(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
 	if err != nil {
-		return nil, false, fmt.Errorf("error reading raw HTML artifact: %w", err)
+		return nil, false, fmt.Errorf("error reading %s: %w", what, err)
 	}
-	return data, true, nil // Found and fresh
+	return data, true, nil
 }
 
-// SetRawHTML stores raw HTML.
-func (m *Manager) SetRawHTML(url string, data []byte) error {
-	filePath, err := m.GetArtifactPath(RawHTMLDir, url, ".html")
-    if err != nil {
-        return err
-    }
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write raw HTML: %w", err)
+// put writes key, wrapping failures with what was being written.
+func (m *Manager) put(key, what string, data []byte) error {
+	if err := m.store.Put(key, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", what, err)
 	}
 	return nil
 }
 
-// GetParsedJSON retrieves parsed JSON from storage if fresh.
-func (m *Manager) GetParsedJSON(url string) ([]byte, bool, error) {
-    filePath, err := m.GetArtifactPath(ParsedJSONDir, url, ".json")
-    if err != nil {
-        return nil, false, err
-    }
-    
-	info, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		return nil, false, nil // Not found
-	}
+// GetRawHTML retrieves raw HTML from storage if fresh.
+func (m *Manager) GetRawHTML(url string) ([]byte, bool, error) {
+	key, err := artifactKey(RawHTMLDir, url, ".html")
 	if err != nil {
-		return nil, false, fmt.Errorf("error statting parsed JSON artifact: %w", err)
+		return nil, false, err
 	}
+	return m.getFresh(key, "raw HTML artifact")
+}
 
-	if m.maxAge > 0 && time.Since(info.ModTime()) > m.maxAge {
-		return nil, false, nil // Stale
+// SetRawHTML stores raw HTML.
+func (m *Manager) SetRawHTML(url string, data []byte) error {
+	key, err := artifactKey(RawHTMLDir, url, ".html")
+	if err != nil {
+		return err
 	}
-    // If maxAge is negative, it means "never expire"
-    // In this case, always fresh if exists.
+	return m.put(key, "raw HTML", data)
+}
 
-	data, err := os.ReadFile(filepath.Clean(filePath))
+// GetParsedJSON retrieves parsed JSON from storage if fresh.
+func (m *Manager) GetParsedJSON(url string) ([]byte, bool, error) {
+	key, err := artifactKey(ParsedJSONDir, url, ".json")
 	if err != nil {
-		return nil, false, fmt.Errorf("error reading parsed JSON artifact: %w", err)
+		return nil, false, err
 	}
-	return data, true, nil // Found and fresh
+	return m.getFresh(key, "parsed JSON artifact")
 }
 
 // SetParsedJSON stores parsed JSON.
 func (m *Manager) SetParsedJSON(url string, data []byte) error {
-    filePath, err := m.GetArtifactPath(ParsedJSONDir, url, ".json")
-    if err != nil {
-        return err
-    }
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write parsed JSON: %w", err)
+	key, err := artifactKey(ParsedJSONDir, url, ".json")
+	if err != nil {
+		return err
 	}
-	return nil
+	return m.put(key, "parsed JSON", data)
+}
+
+// SetSlugArtifact stores data next to the URL's parsed JSON, as parsed/<slug>-<hash><ext>.
+func (m *Manager) SetSlugArtifact(url, ext string, data []byte) error {
+	key, err := artifactKey(ParsedJSONDir, url, ext)
+	if err != nil {
+		return err
+	}
+	return m.put(key, ext, data)
 }
 
 // MaxAge returns the configured max age for artifacts.
@@ -218,6 +220,12 @@ func (m *Manager) MaxAge() time.Duration {
     return m.maxAge
 }
 
+// EnrichCacheDir returns where fetch --enrich-academic caches arXiv/Crossref lookups.
+// Example: lwp-results/enrich/
+func (m *Manager) EnrichCacheDir() string {
+	return filepath.Join(m.baseDir, EnrichDir)
+}
+
 // ===== NEW URL-ID-BASED METHODS =====
 
 // EnsureURLDir ensures the directory for a URL ID exists.
@@ -233,75 +241,95 @@ func (m *Manager) EnsureURLDir(urlID int64) error {
 // GetRawHTMLByID retrieves raw HTML from URL-centric storage.
 // Reads from lwp-results/{url_id}/raw.html
 func (m *Manager) GetRawHTMLByID(urlID int64) ([]byte, bool, error) {
-	filePath := GetURLArtifactPath(m.baseDir, urlID, "raw.html")
-
-	info, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		return nil, false, nil // Not found
-	}
-	if err != nil {
-		return nil, false, fmt.Errorf("error statting raw HTML: %w", err)
-	}
-
-	if m.maxAge > 0 && time.Since(info.ModTime()) > m.maxAge {
-		return nil, false, nil // Stale
-	}
+	return m.getFresh(URLArtifactKey(urlID, "raw.html"), "raw HTML")
+}
 
-	data, err := os.ReadFile(filepath.Clean(filePath))
-	if err != nil {
-		return nil, false, fmt.Errorf("error reading raw HTML: %w", err)
-	}
-	return data, true, nil
+// GetCachedRawHTMLByID retrieves raw HTML from URL-centric storage regardless of maxAge,
+// for callers that validate freshness some other way.
+func (m *Manager) GetCachedRawHTMLByID(urlID int64) ([]byte, bool, error) {
+	return m.get(URLArtifactKey(urlID, "raw.html"), "raw HTML")
 }
 
 // SetRawHTMLByID stores raw HTML in URL-centric storage.
 // Writes to lwp-results/{url_id}/raw.html
 func (m *Manager) SetRawHTMLByID(urlID int64, data []byte) error {
-	if err := m.EnsureURLDir(urlID); err != nil {
-		return err
-	}
-
-	filePath := GetURLArtifactPath(m.baseDir, urlID, "raw.html")
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write raw HTML: %w", err)
-	}
-	return nil
+	return m.put(URLArtifactKey(urlID, "raw.html"), "raw HTML", data)
 }
 
 // GetParsedJSONByID retrieves parsed JSON from URL-centric storage.
 // Reads from lwp-results/{url_id}/generic.yaml
 func (m *Manager) GetParsedJSONByID(urlID int64) ([]byte, bool, error) {
-	filePath := GetURLArtifactPath(m.baseDir, urlID, "generic.yaml")
+	return m.getFresh(URLArtifactKey(urlID, "generic.yaml"), "parsed YAML")
+}
 
-	info, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
-		return nil, false, nil // Not found
-	}
-	if err != nil {
-		return nil, false, fmt.Errorf("error statting parsed YAML: %w", err)
+// SetGenericJSONByID stores the parsed page as JSON in URL-centric storage.
+// Writes to lwp-results/{url_id}/generic.json
+func (m *Manager) SetGenericJSONByID(urlID int64, data []byte) error {
+	return m.put(URLArtifactKey(urlID, "generic.json"), "parsed JSON", data)
+}
+
+// GetURLArtifact reads lwp-results/{url_id}/{fileName} regardless of age.
+func (m *Manager) GetURLArtifact(urlID int64, fileName string) ([]byte, bool, error) {
+	return m.get(URLArtifactKey(urlID, fileName), fileName)
+}
+
+// SetURLArtifact stores data as lwp-results/{url_id}/{fileName}, e.g. an extraction.
+func (m *Manager) SetURLArtifact(urlID int64, fileName string, data []byte) error {
+	return m.put(URLArtifactKey(urlID, fileName), fileName, data)
+}
+
+// RemoveURLArtifact deletes lwp-results/{url_id}/{fileName}; a missing file is not an error.
+func (m *Manager) RemoveURLArtifact(urlID int64, fileName string) error {
+	if err := m.store.Delete(URLArtifactKey(urlID, fileName)); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", fileName, err)
 	}
+	return nil
+}
 
-	if m.maxAge > 0 && time.Since(info.ModTime()) > m.maxAge {
-		return nil, false, nil // Stale
+// GetParsedPageByID loads the parsed page stored for urlID; see ReadParsedPage.
+func (m *Manager) GetParsedPageByID(urlID int64) (*models.Page, bool, error) {
+	return readParsedPage(m.store, urlID)
+}
+
+// ReadParsedPage loads the parsed page fetch stored for urlID from generic.yaml or,
+// for pages stored with --parsed-format json, generic.json. The two files use
+// different key names, so each is decoded with its own codec. A file that exists but
+// does not decode is reported as a *CorruptArtifactError.
+func ReadParsedPage(baseDir string, urlID int64) (*models.Page, bool, error) {
+	return readParsedPage(NewFileStore(baseDir), urlID)
+}
+
+func readParsedPage(store Store, urlID int64) (*models.Page, bool, error) {
+	yamlKey := URLArtifactKey(urlID, "generic.yaml")
+	data, err := store.Get(yamlKey)
+	if err == nil {
+		page, err := DecodeParsedPage(Location(store, yamlKey), data, yaml.Unmarshal)
+		if err != nil {
+			return nil, false, err
+		}
+		return page, true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, fmt.Errorf("error reading parsed YAML: %w", err)
 	}
 
-	data, err := os.ReadFile(filepath.Clean(filePath))
+	jsonKey := URLArtifactKey(urlID, "generic.json")
+	data, err = store.Get(jsonKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
 	if err != nil {
-		return nil, false, fmt.Errorf("error reading parsed YAML: %w", err)
+		return nil, false, fmt.Errorf("error reading parsed JSON: %w", err)
 	}
-	return data, true, nil
+	page, err := DecodeParsedPage(Location(store, jsonKey), data, json.Unmarshal)
+	if err != nil {
+		return nil, false, err
+	}
+	return page, true, nil
 }
 
 // SetParsedYAMLByID stores parsed YAML in URL-centric storage.
 // Writes to lwp-results/{url_id}/generic.yaml
 func (m *Manager) SetParsedYAMLByID(urlID int64, data []byte) error {
-	if err := m.EnsureURLDir(urlID); err != nil {
-		return err
-	}
-
-	filePath := GetURLArtifactPath(m.baseDir, urlID, "generic.yaml")
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write parsed YAML: %w", err)
-	}
-	return nil
+	return m.put(URLArtifactKey(urlID, "generic.yaml"), "parsed YAML", data)
 }
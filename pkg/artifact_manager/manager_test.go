@@ -0,0 +1,99 @@
+package artifact_manager
+
+import (
+	"strings"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/urlnorm"
+)
+
+// The manager hashes the same key the database stores as canonical_url, so a cached
+// file and its url_id are found from any variant of the URL.
+func TestURLKey_ManagerAndDatabaseAgree(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   urlnorm.Policy
+		variants []string
+		distinct string // Same page under the default policy only
+	}{
+		{
+			name:     "default policy",
+			policy:   urlnorm.DefaultPolicy,
+			variants: []string{"https://example.com/docs?b=2&a=1", "http://EXAMPLE.com/docs?a=1&b=2", "https://example.com/docs?a=1&b=2#intro"},
+		},
+		{
+			name:     "keep fragment",
+			policy:   urlnorm.Policy{ForceHTTPS: true, SortQuery: true, KeepFragment: true},
+			variants: []string{"https://example.com/docs#intro", "http://example.com/docs#intro"},
+			distinct: "https://example.com/docs#usage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlnorm.SetPolicy(tt.policy)
+			t.Cleanup(func() { urlnorm.SetPolicy(urlnorm.DefaultPolicy) })
+			t.Chdir(t.TempDir())
+
+			database, err := dbpkg.Open()
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer database.Close()
+			manager, err := NewManager(t.TempDir(), 0)
+			if err != nil {
+				t.Fatalf("NewManager() error = %v", err)
+			}
+
+			var wantID int64
+			var wantPath string
+			for i, u := range tt.variants {
+				urlID, err := database.InsertURL(u)
+				if err != nil {
+					t.Fatalf("InsertURL(%q) error = %v", u, err)
+				}
+				path, err := manager.GetArtifactPath(RawHTMLDir, u, ".html")
+				if err != nil {
+					t.Fatalf("GetArtifactPath(%q) error = %v", u, err)
+				}
+				if i == 0 {
+					wantID, wantPath = urlID, path
+					continue
+				}
+				if urlID != wantID {
+					t.Errorf("InsertURL(%q) = %d, want url_id %d of %q", u, urlID, wantID, tt.variants[0])
+				}
+				if !strings.HasSuffix(path, wantPath[strings.LastIndex(wantPath, "-"):]) {
+					t.Errorf("GetArtifactPath(%q) = %q, want the key hash of %q", u, path, wantPath)
+				}
+				if got, err := database.GetURLID(u); err != nil || got != wantID {
+					t.Errorf("GetURLID(%q) = %d, %v, want %d", u, got, err, wantID)
+				}
+			}
+
+			index, err := database.GetCanonicalURLIndex()
+			if err != nil {
+				t.Fatalf("GetCanonicalURLIndex() error = %v", err)
+			}
+			indexed := false
+			for key, urlID := range index {
+				if urlID != wantID {
+					continue
+				}
+				indexed = true
+				if !strings.HasSuffix(wantPath, "-"+getShortHash(key)+".html") {
+					t.Errorf("canonical_url %q does not hash to the manager's file %q", key, wantPath)
+				}
+			}
+			if !indexed {
+				t.Errorf("url_id %d missing from the canonical URL index %v", wantID, index)
+			}
+
+			if tt.distinct != "" {
+				if urlID, err := database.InsertURL(tt.distinct); err != nil || urlID == wantID {
+					t.Errorf("InsertURL(%q) = %d, %v, want a new url_id", tt.distinct, urlID, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package artifact_manager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Store holds artifacts by key: a slash-separated path relative to the results root,
+// such as "42/raw.html" or "parsed/go_dev_doc-1a2b3c4d5e6f.json". Get and Stat report a
+// missing key with an error matching fs.ErrNotExist; deleting a missing key succeeds.
+// Implementations must be safe for concurrent use: fetch workers share one.
+type Store interface {
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+	Stat(key string) (ArtifactInfo, error)
+	Delete(key string) error
+}
+
+// ArtifactInfo describes a stored artifact without reading it.
+type ArtifactInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// FileStore is the default Store: each key is a file under Root. Puts replace files
+// atomically (see WriteFileAtomic) and create missing directories.
+type FileStore struct {
+	Root string
+}
+
+// NewFileStore returns a FileStore rooted at root (DefaultBaseDir when empty).
+func NewFileStore(root string) *FileStore {
+	if root == "" {
+		root = DefaultBaseDir
+	}
+	return &FileStore{Root: root}
+}
+
+// Path returns the file that holds key.
+func (s *FileStore) Path(key string) string {
+	return filepath.Join(s.Root, filepath.FromSlash(key))
+}
+
+// path validates key before mapping it to a file, so no key reaches outside Root.
+func (s *FileStore) path(key string) (string, error) {
+	clean := path.Clean(key)
+	if key == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid artifact key %q", key)
+	}
+	return s.Path(clean), nil
+}
+
+func (s *FileStore) Get(key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Clean(p))
+}
+
+func (s *FileStore) Put(key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	return WriteFileAtomic(p, data, 0600)
+}
+
+func (s *FileStore) Stat(key string) (ArtifactInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return ArtifactInfo{}, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return ArtifactInfo{}, err
+	}
+	return ArtifactInfo{Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (s *FileStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URLArtifactKey is the key of an artifact in URL-centric storage, e.g. "42/raw.html".
+func URLArtifactKey(urlID int64, name string) string {
+	return fmt.Sprintf("%d/%s", urlID, name)
+}
+
+// Location names key for messages: the file path for a FileStore, the key otherwise.
+func Location(store Store, key string) string {
+	if fileStore, ok := store.(*FileStore); ok {
+		return fileStore.Path(key)
+	}
+	return key
+}
@@ -0,0 +1,118 @@
+package artifact_manager
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+
+	if _, err := store.Get("42/raw.html"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Get() of a missing key error = %v, want fs.ErrNotExist", err)
+	}
+	if err := store.Put("42/raw.html", []byte("<html></html>")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(store.Root, "42", "raw.html")); err != nil || string(data) != "<html></html>" {
+		t.Errorf("file = %q, %v, want the stored bytes under Root", data, err)
+	}
+	if info, err := store.Stat("42/raw.html"); err != nil || info.Size != 13 {
+		t.Errorf("Stat() = %+v, %v, want size 13", info, err)
+	}
+
+	if err := store.Delete("42/raw.html"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("42/raw.html"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+
+	for _, key := range []string{"", "../outside.html", "/etc/passwd", "42/../../outside.html"} {
+		if err := store.Put(key, []byte("x")); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid key error", key)
+		}
+	}
+}
+
+// memStore is a Store that never touches the disk.
+type memStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (s *memStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return data, nil
+}
+
+func (s *memStore) Put(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[key] = data
+	return nil
+}
+
+func (s *memStore) Stat(key string) (ArtifactInfo, error) {
+	data, err := s.Get(key)
+	if err != nil {
+		return ArtifactInfo{}, err
+	}
+	return ArtifactInfo{Size: int64(len(data)), ModTime: time.Now()}, nil
+}
+
+func (s *memStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+func TestManager_CustomStore(t *testing.T) {
+	store := &memStore{files: make(map[string][]byte)}
+	baseDir := t.TempDir()
+	manager := NewManagerWithStore(store, baseDir, time.Hour)
+
+	if err := manager.SetRawHTMLByID(7, []byte("<p>hi</p>")); err != nil {
+		t.Fatalf("SetRawHTMLByID() error = %v", err)
+	}
+	if err := manager.SetParsedYAMLByID(7, []byte("url: https://example.com/\ntitle: Hi\n")); err != nil {
+		t.Fatalf("SetParsedYAMLByID() error = %v", err)
+	}
+	if err := manager.SetRawHTML("https://example.com/", []byte("legacy")); err != nil {
+		t.Fatalf("SetRawHTML() error = %v", err)
+	}
+
+	if data, found, err := manager.GetRawHTMLByID(7); err != nil || !found || string(data) != "<p>hi</p>" {
+		t.Errorf("GetRawHTMLByID() = %q, %v, %v", data, found, err)
+	}
+	if page, found, err := manager.GetParsedPageByID(7); err != nil || !found || page.Title != "Hi" {
+		t.Errorf("GetParsedPageByID() = %+v, %v, %v", page, found, err)
+	}
+	if data, found, err := manager.GetRawHTML("https://example.com/"); err != nil || !found || string(data) != "legacy" {
+		t.Errorf("GetRawHTML() = %q, %v, %v", data, found, err)
+	}
+	if _, found, err := manager.GetCachedRawHTMLByID(8); err != nil || found {
+		t.Errorf("GetCachedRawHTMLByID(missing) = %v, %v, want not found", found, err)
+	}
+
+	if err := manager.RemoveURLArtifact(7, "raw.html"); err != nil {
+		t.Fatalf("RemoveURLArtifact() error = %v", err)
+	}
+	if _, ok := store.files["7/raw.html"]; ok {
+		t.Error("RemoveURLArtifact() left the key in the store")
+	}
+	if entries, _ := os.ReadDir(baseDir); len(entries) != 0 {
+		t.Errorf("custom store wrote %d entries to the base directory", len(entries))
+	}
+}
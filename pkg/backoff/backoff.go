@@ -0,0 +1,27 @@
+// Package backoff computes retry delays shared by the fetcher and the database.
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Jittered returns the delay before retry number attempt (starting at 1): base doubled
+// each attempt and capped at maxDelay (<= 0 = no cap), with full jitter so concurrent
+// callers don't retry in lockstep. A base <= 0 means no delay.
+func Jittered(base, maxDelay time.Duration, attempt int) time.Duration {
+	if base <= 0 || attempt < 1 {
+		return 0
+	}
+
+	ceiling := base << (attempt - 1)
+	if ceiling <= 0 || (maxDelay > 0 && ceiling > maxDelay) {
+		ceiling = maxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	// #nosec G404 -- jitter does not need a cryptographic source
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
@@ -0,0 +1,37 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJittered(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		maxDelay time.Duration
+		attempt  int
+		ceiling  time.Duration
+	}{
+		{"first retry", 10 * time.Millisecond, time.Second, 1, 10 * time.Millisecond},
+		{"doubles", 10 * time.Millisecond, time.Second, 3, 40 * time.Millisecond},
+		{"capped", 10 * time.Millisecond, 25 * time.Millisecond, 3, 25 * time.Millisecond},
+		{"overflow uses the cap", time.Second, time.Minute, 80, time.Minute},
+		{"no cap", 10 * time.Millisecond, 0, 4, 80 * time.Millisecond},
+		{"no base", 0, time.Second, 2, 0},
+		{"attempt zero", 10 * time.Millisecond, time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 50 {
+				got := Jittered(tt.base, tt.maxDelay, tt.attempt)
+				if tt.ceiling == 0 && got != 0 {
+					t.Fatalf("Jittered() = %s, want 0", got)
+				}
+				if tt.ceiling > 0 && (got <= 0 || got > tt.ceiling) {
+					t.Fatalf("Jittered() = %s, want within (0, %s]", got, tt.ceiling)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// ChunksFile is the name of the chunk file written per URL.
+const ChunksFile = "chunks.jsonl"
+
+// Chunk is one fixed-size piece of a page's plain text, a line of chunks.jsonl.
+type Chunk struct {
+	Index     int    `json:"index"`                // Position in the page, from 0
+	Tokens    int    `json:"tokens"`               // Estimated, see EstimateTokens
+	SectionID string `json:"section_id,omitempty"` // Section the chunk's new text starts in (empty for flat pages)
+	Section   string `json:"section,omitempty"`    // That section's heading
+	Text      string `json:"text"`
+}
+
+// ChunkOptions size the chunks of ChunkPage, in estimated tokens.
+type ChunkOptions struct {
+	Size    int // Most tokens in a chunk
+	Overlap int // Tokens of a chunk's end repeated at the start of the next (less than Size)
+}
+
+// Validate rejects sizes ChunkPage cannot honor.
+func (o ChunkOptions) Validate() error {
+	switch {
+	case o.Size <= 0:
+		return fmt.Errorf("chunk size must be positive, got %d", o.Size)
+	case o.Overlap < 0 || o.Overlap >= o.Size:
+		return fmt.Errorf("chunk overlap must be at least 0 and less than the chunk size %d, got %d", o.Size, o.Overlap)
+	}
+	return nil
+}
+
+// ChunkPage splits page's plain text into chunks of at most opts.Size tokens, each
+// starting with up to opts.Overlap tokens from the end of the one before. Chunks end at
+// block boundaries when the current chunk is at least half full; a block that has to
+// be cut is split between sentences, a code block or table between lines. Only a
+// single sentence or line longer than a whole chunk is split between words.
+func ChunkPage(page *models.Page, opts ChunkOptions) []Chunk {
+	maxWords := wordsForTokens(opts.Size)
+	overlapWords := 0
+	if opts.Overlap > 0 {
+		overlapWords = wordsForTokens(opts.Overlap)
+	}
+
+	var chunks []Chunk
+	var current []textUnit
+	words, fresh := 0, 0 // fresh: units after the overlap carried from the previous chunk
+	emit := func() {
+		if fresh == 0 {
+			return
+		}
+		first := current[len(current)-fresh]
+		text := joinUnits(current)
+		chunks = append(chunks, Chunk{
+			Index:     len(chunks),
+			Tokens:    EstimateTokens(text),
+			SectionID: first.sectionID,
+			Section:   first.section,
+			Text:      text,
+		})
+
+		// Carry the trailing units that fit in the overlap
+		start, carried := len(current), 0
+		for start > 0 && carried+current[start-1].words <= overlapWords {
+			start--
+			carried += current[start].words
+		}
+		tail := append([]textUnit(nil), current[start:]...)
+		// A unit too long to carry whole contributes its last sentences or lines
+		if start > 0 && carried < overlapWords {
+			pieces := current[start-1].tail(overlapWords - carried)
+			for _, p := range pieces {
+				carried += p.words
+			}
+			tail = append(pieces, tail...)
+		}
+		current = tail
+		words, fresh = carried, 0
+	}
+
+	for _, block := range pageBlocks(page) {
+		units := block.split(maxWords)
+		// Close a half-full chunk rather than cut the block
+		if words+block.words > maxWords && (words-carriedWords(current, fresh))*2 >= maxWords {
+			emit()
+		}
+		for _, u := range units {
+			if words+u.words > maxWords {
+				if fresh > 0 {
+					emit()
+				}
+				// Drop overlap that leaves no room for the unit
+				for len(current) > 0 && words+u.words > maxWords {
+					words -= current[0].words
+					current = current[1:]
+				}
+			}
+			current = append(current, u)
+			words += u.words
+			fresh++
+		}
+	}
+	emit()
+	return chunks
+}
+
+// carriedWords counts the words of current's overlap units.
+func carriedWords(current []textUnit, fresh int) int {
+	words := 0
+	for _, u := range current[:len(current)-fresh] {
+		words += u.words
+	}
+	return words
+}
+
+// MarshalJSONL encodes chunks one JSON object per line.
+func MarshalJSONL(chunks []Chunk) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, c := range chunks {
+		if err := enc.Encode(c); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// textBlock is one content block's plain text with the section it belongs to.
+type textBlock struct {
+	text               string
+	words              int
+	lines              bool // Code or table: cut between lines, not sentences
+	sectionID, section string
+}
+
+// textUnit is a piece of a block that chunks never cut, unless it alone overflows one.
+type textUnit struct {
+	text               string
+	words              int
+	blockStart, lines  bool
+	sectionID, section string
+}
+
+// pageBlocks lists the text blocks of page in document order.
+func pageBlocks(page *models.Page) []textBlock {
+	var blocks []textBlock
+	add := func(b models.ContentBlock, sectionID, section string) {
+		text, lines := blockText(b)
+		if lines {
+			text = strings.Trim(text, "\r\n") // Keep the first line's indentation
+		} else {
+			text = strings.TrimSpace(text)
+		}
+		if strings.TrimSpace(text) != "" {
+			blocks = append(blocks, textBlock{text: text, words: len(strings.Fields(text)), lines: lines, sectionID: sectionID, section: section})
+		}
+	}
+
+	var walk func(s models.Section)
+	walk = func(s models.Section) {
+		heading := ""
+		if s.Heading != nil {
+			heading = s.Heading.Text
+			add(*s.Heading, s.ID, heading)
+		}
+		for _, b := range s.Blocks {
+			add(b, s.ID, heading)
+		}
+		for _, child := range s.Children {
+			walk(child)
+		}
+	}
+	for _, s := range page.Content {
+		walk(s)
+	}
+
+	heading := ""
+	for _, b := range page.FlatContent {
+		if models.HeadingLevel(b.Type) > 0 {
+			heading = b.Text
+		}
+		add(b, "", heading)
+	}
+	return blocks
+}
+
+// blockText is a block's plain text, reporting whether its lines must stay whole.
+func blockText(b models.ContentBlock) (string, bool) {
+	switch {
+	case b.Code != nil:
+		return b.Code.Content, true
+	case b.Table != nil:
+		var lines []string
+		for _, row := range append([][]string{b.Table.Headers}, b.Table.Rows...) {
+			if len(row) > 0 {
+				lines = append(lines, strings.Join(row, " | "))
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	case b.Math != nil && b.Text == "":
+		return b.Math.Expression, false
+	case b.Type == "pre":
+		return b.Text, true
+	}
+	return b.Text, false
+}
+
+// split cuts the block into units of at most maxWords: the whole block when it fits,
+// otherwise its lines or sentences, and words only within a line or sentence that
+// overflows on its own.
+func (b textBlock) split(maxWords int) []textUnit {
+	pieces := []string{b.text}
+	if b.words > maxWords {
+		if b.lines {
+			pieces = strings.Split(b.text, "\n")
+		} else {
+			pieces = sentences(b.text)
+		}
+	}
+
+	var units []textUnit
+	for _, piece := range pieces {
+		fields := strings.Fields(piece)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) <= maxWords {
+			units = append(units, textUnit{text: piece, words: len(fields)})
+			continue
+		}
+		for len(fields) > 0 {
+			n := min(maxWords, len(fields))
+			units = append(units, textUnit{text: strings.Join(fields[:n], " "), words: n})
+			fields = fields[n:]
+		}
+	}
+	for i := range units {
+		units[i].blockStart = i == 0
+		units[i].lines = b.lines
+		units[i].sectionID, units[i].section = b.sectionID, b.section
+	}
+	return units
+}
+
+// tail returns the trailing sentences (or lines) of u that fit in maxWords.
+func (u textUnit) tail(maxWords int) []textUnit {
+	pieces := sentences(u.text)
+	if u.lines {
+		pieces = strings.Split(u.text, "\n")
+	}
+	var out []textUnit
+	words := 0
+	for i := len(pieces) - 1; i > 0; i-- {
+		n := len(strings.Fields(pieces[i]))
+		if n == 0 {
+			continue
+		}
+		if words+n > maxWords {
+			break
+		}
+		words += n
+		out = append([]textUnit{{text: pieces[i], words: n, lines: u.lines, sectionID: u.sectionID, section: u.section}}, out...)
+	}
+	return out
+}
+
+// sentences splits text after '.', '!' or '?' followed by whitespace.
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if next := text[i+1]; next == ' ' || next == '\n' || next == '\t' {
+				out = append(out, strings.TrimSpace(text[start:i+1]))
+				start = i + 1
+			}
+		}
+	}
+	return append(out, strings.TrimSpace(text[start:]))
+}
+
+// joinUnits rebuilds text: blocks separated by blank lines, the pieces of a block by a
+// newline (lines) or a space (sentences).
+func joinUnits(units []textUnit) string {
+	var sb strings.Builder
+	for i, u := range units {
+		switch {
+		case i == 0:
+		case u.blockStart:
+			sb.WriteString("\n\n")
+		case u.lines:
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(u.text)
+	}
+	return sb.String()
+}
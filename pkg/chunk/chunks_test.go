@@ -0,0 +1,118 @@
+package chunk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// words returns n distinct words, so tests can tell which text landed where.
+func words(prefix string, n int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return strings.Join(w, " ")
+}
+
+func TestChunkPage(t *testing.T) {
+	page := &models.Page{
+		Content: []models.Section{
+			{
+				ID: "section-1", Level: 1, Heading: heading("b1", "h1", "Intro"),
+				Blocks: []models.ContentBlock{
+					{Type: "p", Text: words("a", 20)},
+					{Type: "p", Text: words("b", 12) + ". " + words("c", 8) + "."},
+				},
+			},
+			{
+				ID: "section-2", Level: 1, Heading: heading("b2", "h1", "Code"),
+				Blocks: []models.ContentBlock{
+					{Type: "code", Code: &models.Code{Content: "func main() {\n\tfmt.Println(1, 2, 3, 4, 5, 6, 7, 8)\n\tfmt.Println(1, 2, 3, 4, 5, 6, 7, 8)\n\tfmt.Println(1, 2, 3, 4, 5, 6, 7, 8)\n}\n"}},
+				},
+			},
+		},
+	}
+
+	// 20 tokens = at most 51 words per chunk, 4 tokens = 11 words of overlap
+	chunks := ChunkPage(page, ChunkOptions{Size: 20, Overlap: 4})
+	if len(chunks) < 2 {
+		t.Fatalf("ChunkPage() = %d chunks, want several: %+v", len(chunks), chunks)
+	}
+	for i, c := range chunks {
+		if c.Index != i {
+			t.Errorf("chunks[%d].Index = %d", i, c.Index)
+		}
+		if c.Tokens > 20 || c.Tokens != EstimateTokens(c.Text) {
+			t.Errorf("chunks[%d].Tokens = %d for %d words, want the estimate, at most 20", i, c.Tokens, len(strings.Fields(c.Text)))
+		}
+		// Code lines are never cut
+		for _, line := range strings.Split(c.Text, "\n") {
+			if strings.Contains(line, "Println") && !strings.HasSuffix(line, "8)") {
+				t.Errorf("chunks[%d] cuts a code line: %q", i, line)
+			}
+		}
+	}
+
+	// Intro fits in the first chunk whole; the code section starts a later one
+	if first := chunks[0]; first.SectionID != "section-1" || first.Section != "Intro" || !strings.Contains(first.Text, "c7.") {
+		t.Errorf("chunks[0] = %+v, want all of Intro", first)
+	}
+	last := chunks[len(chunks)-1]
+	if last.SectionID != "section-2" || !strings.HasSuffix(last.Text, "}") {
+		t.Errorf("last chunk = %+v, want the end of the code section", last)
+	}
+	// The next chunk repeats the end of the one before: the last sentence that fits
+	if !strings.HasPrefix(chunks[1].Text, words("c", 8)+".") || strings.Contains(chunks[1].Text, "b11") {
+		t.Errorf("chunks[1] = %q, want it to start with the overlap from chunks[0]", chunks[1].Text)
+	}
+}
+
+func TestChunkPage_SplitsLongParagraphBetweenSentences(t *testing.T) {
+	var sentences []string
+	for i := 0; i < 6; i++ {
+		sentences = append(sentences, words(fmt.Sprintf("s%d-", i), 9)+".")
+	}
+	page := &models.Page{FlatContent: []models.ContentBlock{{Type: "p", Text: strings.Join(sentences, " ")}}}
+
+	chunks := ChunkPage(page, ChunkOptions{Size: 10}) // 26 words: two sentences each
+	if len(chunks) != 3 {
+		t.Fatalf("ChunkPage() = %d chunks, want 3: %+v", len(chunks), chunks)
+	}
+	for i, c := range chunks {
+		if !strings.HasSuffix(c.Text, ".") {
+			t.Errorf("chunks[%d] = %q, want it to end at a sentence", i, c.Text)
+		}
+	}
+}
+
+func TestMarshalJSONL(t *testing.T) {
+	data, err := MarshalJSONL([]Chunk{{Index: 0, Tokens: 1, Text: "a <b>"}, {Index: 1, Tokens: 1, Text: "c"}})
+	if err != nil {
+		t.Fatalf("MarshalJSONL() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"a <b>"`) {
+		t.Fatalf("MarshalJSONL() = %q", data)
+	}
+	for _, line := range lines {
+		var c Chunk
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			t.Errorf("line %q: %v", line, err)
+		}
+	}
+}
+
+func TestChunkOptions_Validate(t *testing.T) {
+	for _, opts := range []ChunkOptions{{Size: 0}, {Size: 10, Overlap: 10}, {Size: 10, Overlap: -1}} {
+		if opts.Validate() == nil {
+			t.Errorf("%+v.Validate() = nil, want an error", opts)
+		}
+	}
+	if err := (ChunkOptions{Size: 512, Overlap: 64}).Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
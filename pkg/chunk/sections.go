@@ -0,0 +1,306 @@
+package chunk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Section file formats
+const (
+	FormatMarkdown = "md"
+	FormatYAML     = "yaml"
+)
+
+// ManifestFile is the name of the manifest written beside the section files.
+const ManifestFile = "manifest.yaml"
+
+// Section is one top-level section of a page, or one part of a section split at the
+// token limit, as written to sections/<File>.
+type Section struct {
+	Index     int    `yaml:"index" json:"index"` // Position in the page, from 0; reassemble in this order
+	File      string `yaml:"file" json:"file"`
+	SectionID string `yaml:"section_id,omitempty" json:"section_id,omitempty"` // Parser section ID (empty for flat pages)
+	Heading   string `yaml:"heading,omitempty" json:"heading,omitempty"`
+	Part      int    `yaml:"part,omitempty" json:"part,omitempty"`       // 1-based part of a split section
+	Parts     int    `yaml:"parts,omitempty" json:"parts,omitempty"`     // Parts the section was split into
+	Tokens    int    `yaml:"tokens" json:"tokens"`                       // Estimated, see EstimateTokens
+	Content   string `yaml:"content,omitempty" json:"content,omitempty"` // Markdown; left out of the manifest
+}
+
+// Manifest lists a page's section files in document order.
+type Manifest struct {
+	URL       string    `yaml:"url" json:"url"`
+	URLID     int64     `yaml:"url_id,omitempty" json:"url_id,omitempty"`
+	Title     string    `yaml:"title,omitempty" json:"title,omitempty"`
+	Format    string    `yaml:"format" json:"format"`
+	MaxTokens int       `yaml:"max_section_tokens,omitempty" json:"max_section_tokens,omitempty"`
+	Sections  []Section `yaml:"sections" json:"sections"`
+}
+
+// SplitSections cuts page into one Section per top-level section. A page whose only
+// root section (usually the h1 title) has children is split at those children, its
+// own blocks becoming an intro section; flat pages are split at their highest-level
+// headings. Sections estimated above maxTokens (0 = no limit) are split further
+// between blocks, each part repeating the heading; a single block larger than the
+// limit stays whole. format (FormatMarkdown or FormatYAML) sets the file extension.
+func SplitSections(page *models.Page, urlID int64, format string, maxTokens int) *Manifest {
+	m := &Manifest{URL: page.URL, URLID: urlID, Title: page.Title, Format: format, MaxTokens: maxTokens}
+	seen := make(map[string]int)
+	for _, top := range topSections(page) {
+		parts := splitPieces(top.pieces, top.headingPiece, maxTokens)
+		slug := top.slug
+		if slug == "" {
+			slug = fmt.Sprintf("section-%d", len(m.Sections)+1)
+		}
+		// Repeated headings ("Examples") get positional suffixes
+		seen[slug]++
+		if n := seen[slug]; n > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		}
+
+		for i, part := range parts {
+			s := Section{Index: len(m.Sections), SectionID: top.id, Heading: top.heading, File: slug + "." + format}
+			if len(parts) > 1 {
+				s.Part, s.Parts = i+1, len(parts)
+				s.File = fmt.Sprintf("%s-part-%d.%s", slug, i+1, format)
+			}
+			s.Content = strings.Join(part, "\n\n") + "\n"
+			s.Tokens = EstimateTokens(s.Content)
+			m.Sections = append(m.Sections, s)
+		}
+	}
+	return m
+}
+
+// Render returns the file for s: Markdown with a front matter back-reference to the
+// page, or a YAML document carrying the same fields.
+func (m *Manifest) Render(s Section) ([]byte, error) {
+	file := sectionFile{URL: m.URL, URLID: m.URLID, Title: m.Title, Section: s}
+	if m.Format == FormatYAML {
+		return yaml.Marshal(file)
+	}
+
+	file.Section.Content = ""
+	front, err := yaml.Marshal(file)
+	if err != nil {
+		return nil, err
+	}
+	return []byte("---\n" + string(front) + "---\n\n" + s.Content), nil
+}
+
+// Marshal encodes the manifest without section contents.
+func (m *Manifest) Marshal() ([]byte, error) {
+	index := *m
+	index.Sections = make([]Section, len(m.Sections))
+	for i, s := range m.Sections {
+		s.Content = ""
+		index.Sections[i] = s
+	}
+	return yaml.Marshal(index)
+}
+
+// sectionFile is a section with its back-reference to the page.
+type sectionFile struct {
+	URL     string `yaml:"url"`
+	URLID   int64  `yaml:"url_id,omitempty"`
+	Title   string `yaml:"title,omitempty"`
+	Section `yaml:",inline"`
+}
+
+// topSection is a top-level section rendered to Markdown pieces, one per block.
+type topSection struct {
+	id, heading, slug string
+	headingPiece      string // The heading's Markdown line, repeated on later parts
+	pieces            []string
+}
+
+func topSections(page *models.Page) []topSection {
+	if len(page.Content) == 0 {
+		return flatSections(page.FlatContent)
+	}
+
+	roots := page.Content
+	var sections []topSection
+	if len(roots) == 1 && len(roots[0].Children) > 0 {
+		intro := roots[0]
+		children := intro.Children
+		intro.Children = nil
+		if len(intro.Blocks) > 0 {
+			top := newTopSection(intro)
+			if top.slug == "" {
+				top.slug = "intro"
+			}
+			sections = append(sections, top)
+		}
+		roots = children
+	}
+	for _, s := range roots {
+		top := newTopSection(s)
+		if s.Heading == nil && len(sections) == 0 {
+			top.slug = "intro" // Text before the first heading
+		}
+		sections = append(sections, top)
+	}
+	return sections
+}
+
+func newTopSection(s models.Section) topSection {
+	top := topSection{id: s.ID}
+	if s.Heading != nil {
+		top.heading = s.Heading.Text
+		top.slug = slugify(s.Heading.Text)
+		top.headingPiece = headingMarkdown(s.Level, s.Heading.Text)
+	}
+	var walk func(s models.Section)
+	walk = func(s models.Section) {
+		if s.Heading != nil {
+			top.pieces = append(top.pieces, headingMarkdown(s.Level, s.Heading.Text))
+		}
+		for _, block := range s.Blocks {
+			if piece := blockMarkdown(block); piece != "" {
+				top.pieces = append(top.pieces, piece)
+			}
+		}
+		for _, child := range s.Children {
+			walk(child)
+		}
+	}
+	walk(s)
+	return top
+}
+
+// flatSections splits flat content at its highest-level headings.
+func flatSections(blocks []models.ContentBlock) []topSection {
+	topLevel := 0
+	for _, block := range blocks {
+		if l := models.HeadingLevel(block.Type); l > 0 && (topLevel == 0 || l < topLevel) {
+			topLevel = l
+		}
+	}
+
+	var sections []topSection
+	var current *topSection
+	for _, block := range blocks {
+		if l := models.HeadingLevel(block.Type); l > 0 && l == topLevel {
+			heading := headingMarkdown(l, block.Text)
+			sections = append(sections, topSection{
+				heading:      block.Text,
+				slug:         slugify(block.Text),
+				headingPiece: heading,
+				pieces:       []string{heading},
+			})
+			current = &sections[len(sections)-1]
+			continue
+		}
+		if current == nil {
+			sections = append(sections, topSection{slug: "intro"})
+			current = &sections[len(sections)-1]
+		}
+		if piece := blockMarkdown(block); piece != "" {
+			current.pieces = append(current.pieces, piece)
+		}
+	}
+	return sections
+}
+
+// splitPieces groups pieces into parts of at most maxTokens (0 = one part), never
+// splitting a piece. Parts after the first start with heading (when set), and a part
+// never holds the heading alone.
+func splitPieces(pieces []string, heading string, maxTokens int) [][]string {
+	if maxTokens <= 0 || len(pieces) == 0 {
+		return [][]string{pieces}
+	}
+	var parts [][]string
+	var current []string
+	tokens := 0
+	for _, piece := range pieces {
+		n := EstimateTokens(piece)
+		if len(current) > 0 && tokens+n > maxTokens && !(len(current) == 1 && current[0] == heading) {
+			parts = append(parts, current)
+			current, tokens = nil, 0
+			if heading != "" {
+				current, tokens = []string{heading}, EstimateTokens(heading)
+			}
+		}
+		current = append(current, piece)
+		tokens += n
+	}
+	return append(parts, current)
+}
+
+func headingMarkdown(level int, text string) string {
+	if level < 1 {
+		level = 1
+	}
+	return strings.Repeat("#", min(level, 6)) + " " + text
+}
+
+// blockMarkdown renders one content block; headings inside flat content keep their level.
+func blockMarkdown(block models.ContentBlock) string {
+	switch {
+	case block.Code != nil:
+		return "```" + block.Code.Language + "\n" + strings.TrimRight(block.Code.Content, "\n") + "\n```"
+	case block.Table != nil:
+		return tableMarkdown(block.Table)
+	case block.Math != nil:
+		if block.Math.Notation == "tex" {
+			if block.Math.Display {
+				return "$$\n" + block.Math.Expression + "\n$$"
+			}
+			return "$" + block.Math.Expression + "$"
+		}
+		return block.Math.Expression
+	}
+
+	text := strings.TrimSpace(block.Text)
+	if text == "" {
+		return ""
+	}
+	switch {
+	case block.Type == "li":
+		return "- " + text
+	case block.Type == "pre":
+		return "```\n" + text + "\n```"
+	case models.HeadingLevel(block.Type) > 0:
+		return headingMarkdown(models.HeadingLevel(block.Type), text)
+	}
+	return text
+}
+
+func tableMarkdown(table *models.Table) string {
+	headers, rows := table.Headers, table.Rows
+	if len(headers) == 0 {
+		if len(rows) == 0 {
+			return ""
+		}
+		headers, rows = rows[0], rows[1:]
+	}
+
+	var sb strings.Builder
+	row := func(cells []string) {
+		padded := make([]string, len(headers))
+		copy(padded, cells)
+		sb.WriteString("| " + strings.Join(padded, " | ") + " |\n")
+	}
+	row(headers)
+	sb.WriteString("|" + strings.Repeat(" --- |", len(headers)) + "\n")
+	for _, cells := range rows {
+		row(cells)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// maxSlugLen keeps file names from long headings readable.
+const maxSlugLen = 60
+
+// slugify returns models.HeadingSlug cut to maxSlugLen, for file names.
+func slugify(heading string) string {
+	slug := models.HeadingSlug(heading)
+	if len(slug) > maxSlugLen {
+		slug = strings.TrimRight(slug[:maxSlugLen], "-")
+	}
+	return slug
+}
@@ -0,0 +1,151 @@
+package chunk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+func heading(id, tag, text string) *models.ContentBlock {
+	return &models.ContentBlock{ID: id, Type: tag, Text: text}
+}
+
+func TestSplitSections(t *testing.T) {
+	page := &models.Page{
+		URL:   "https://example.com/guide",
+		Title: "Guide",
+		Content: []models.Section{{
+			ID: "section-1", Level: 1, Heading: heading("b1", "h1", "Guide"),
+			Blocks: []models.ContentBlock{{Type: "p", Text: "Welcome to the guide."}},
+			Children: []models.Section{
+				{
+					ID: "section-2", Level: 2, Heading: heading("b2", "h2", "Install"),
+					Blocks: []models.ContentBlock{
+						{Type: "p", Text: "Run the installer."},
+						{Type: "code", Code: &models.Code{Language: "sh", Content: "go install ./...\n"}},
+					},
+					Children: []models.Section{{
+						ID: "section-3", Level: 3, Heading: heading("b3", "h3", "Windows"),
+						Blocks: []models.ContentBlock{{Type: "li", Text: "Use PowerShell."}},
+					}},
+				},
+				{
+					ID: "section-4", Level: 2, Heading: heading("b4", "h2", "Install"),
+					Blocks: []models.ContentBlock{{Type: "table", Table: &models.Table{Headers: []string{"os", "cmd"}, Rows: [][]string{{"linux", "make"}}}}},
+				},
+			},
+		}},
+	}
+
+	m := SplitSections(page, 7, FormatMarkdown, 0)
+	var files, ids []string
+	for i, s := range m.Sections {
+		if s.Index != i {
+			t.Errorf("Sections[%d].Index = %d", i, s.Index)
+		}
+		files = append(files, s.File)
+		ids = append(ids, s.SectionID)
+	}
+	if want := []string{"guide.md", "install.md", "install-2.md"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+	if want := []string{"section-1", "section-2", "section-4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("section ids = %v, want %v", ids, want)
+	}
+
+	install := m.Sections[1]
+	wantContent := "## Install\n\nRun the installer.\n\n```sh\ngo install ./...\n```\n\n### Windows\n\n- Use PowerShell.\n"
+	if install.Content != wantContent {
+		t.Errorf("Install content = %q, want %q", install.Content, wantContent)
+	}
+	if install.Tokens != EstimateTokens(wantContent) || install.Tokens == 0 {
+		t.Errorf("Install tokens = %d, want %d", install.Tokens, EstimateTokens(wantContent))
+	}
+	if !strings.Contains(m.Sections[2].Content, "| os | cmd |\n| --- | --- |\n| linux | make |") {
+		t.Errorf("table section content = %q", m.Sections[2].Content)
+	}
+
+	// The file carries the back-reference; the manifest leaves contents out
+	data, err := m.Render(install)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	file := string(data)
+	for _, want := range []string{"url: https://example.com/guide\n", "url_id: 7\n", "index: 1\n", "---\n\n## Install\n"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("Render() = %q, want it to contain %q", file, want)
+		}
+	}
+	data, err = m.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("manifest is not YAML: %v", err)
+	}
+	if len(manifest.Sections) != 3 || manifest.Sections[1].Content != "" || manifest.Sections[1].File != "install.md" {
+		t.Errorf("manifest sections = %+v", manifest.Sections)
+	}
+}
+
+func TestSplitSections_MaxTokens(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("word ", 25)) // 10 tokens
+	page := &models.Page{
+		URL: "https://example.com/long",
+		Content: []models.Section{{
+			ID: "section-1", Level: 2, Heading: heading("b1", "h2", "Long"),
+			Blocks: []models.ContentBlock{
+				{Type: "p", Text: paragraph},
+				{Type: "p", Text: paragraph},
+				{Type: "p", Text: strings.Repeat("word ", 75)}, // Larger than the limit on its own
+			},
+		}},
+	}
+
+	m := SplitSections(page, 0, FormatYAML, 25)
+	if len(m.Sections) != 2 {
+		t.Fatalf("SplitSections() = %d sections, want 2: %+v", len(m.Sections), m.Sections)
+	}
+	for i, s := range m.Sections {
+		if s.Part != i+1 || s.Parts != 2 || s.File != []string{"long-part-1.yaml", "long-part-2.yaml"}[i] {
+			t.Errorf("Sections[%d] = part %d/%d %s", i, s.Part, s.Parts, s.File)
+		}
+		if !strings.HasPrefix(s.Content, "## Long\n\n") {
+			t.Errorf("Sections[%d] content does not repeat the heading: %q", i, s.Content)
+		}
+	}
+	if strings.Count(m.Sections[0].Content, paragraph) != 2 {
+		t.Errorf("first part = %q, want both short paragraphs", m.Sections[0].Content)
+	}
+}
+
+func TestSplitSections_FlatContent(t *testing.T) {
+	page := &models.Page{
+		URL: "https://example.com/flat",
+		FlatContent: []models.ContentBlock{
+			{Type: "p", Text: "Before any heading."},
+			{Type: "h2", Text: "First"},
+			{Type: "p", Text: "One."},
+			{Type: "h3", Text: "Detail"},
+			{Type: "p", Text: "Nested."},
+			{Type: "h2", Text: "Second"},
+			{Type: "p", Text: "Two."},
+		},
+	}
+
+	m := SplitSections(page, 0, FormatMarkdown, 0)
+	var files []string
+	for _, s := range m.Sections {
+		files = append(files, s.File)
+	}
+	if want := []string{"intro.md", "first.md", "second.md"}; !reflect.DeepEqual(files, want) {
+		t.Fatalf("files = %v, want %v", files, want)
+	}
+	if want := "## First\n\nOne.\n\n### Detail\n\nNested.\n"; m.Sections[1].Content != want {
+		t.Errorf("First content = %q, want %q", m.Sections[1].Content, want)
+	}
+}
@@ -0,0 +1,26 @@
+// Package chunk splits parsed pages into ready-to-embed pieces for retrieval pipelines.
+package chunk
+
+import (
+	"math"
+	"strings"
+)
+
+// wordsPerToken is the ratio the summaries use for estimated_tokens.
+const wordsPerToken = 2.5
+
+// EstimateTokens estimates the LLM tokens in text from its word count, the same way
+// fetch summaries compute estimated_tokens.
+func EstimateTokens(text string) int {
+	return TokensForWords(len(strings.Fields(text)))
+}
+
+// TokensForWords estimates the LLM tokens in a text of words words.
+func TokensForWords(words int) int {
+	return int(math.Round(float64(words) / wordsPerToken))
+}
+
+// wordsForTokens is the most words that still estimate to tokens.
+func wordsForTokens(tokens int) int {
+	return int(math.Floor((float64(tokens) + 0.5) * wordsPerToken))
+}
@@ -0,0 +1,132 @@
+package corpus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+// Ranking strategies for PlanBudget: which URLs earn a place in the budget first.
+const (
+	RankConfidence = "confidence" // Highest detection_confidence first
+	RankTFIDF      = "tfidf"      // Most distinctive stored keywords first
+)
+
+// BudgetPlan is a session's token total against a context budget, with the URLs a
+// greedy pass would include to fit it.
+type BudgetPlan struct {
+	SessionID      int64       `json:"session_id" yaml:"session_id"`
+	Budget         int         `json:"budget" yaml:"budget"`
+	Rank           string      `json:"rank" yaml:"rank"`
+	TotalTokens    int         `json:"total_tokens" yaml:"total_tokens"`
+	Fits           bool        `json:"fits" yaml:"fits"` // The whole session fits the budget
+	SelectedTokens int         `json:"selected_tokens" yaml:"selected_tokens"`
+	SelectedCount  int         `json:"selected_count" yaml:"selected_count"`
+	Unestimated    int         `json:"unestimated,omitempty" yaml:"unestimated,omitempty"` // URLs without a token estimate (failed or not fetched)
+	URLs           []BudgetURL `json:"urls" yaml:"urls"`                                   // Most tokens first
+}
+
+// BudgetURL is one URL's share of the budget.
+type BudgetURL struct {
+	URLID    int64   `json:"url_id" yaml:"url_id"`
+	URL      string  `json:"url" yaml:"url"`
+	Tokens   int     `json:"tokens" yaml:"tokens"`
+	Score    float64 `json:"score" yaml:"score"` // detection_confidence or TF-IDF weight, per the plan's rank
+	Included bool    `json:"included" yaml:"included"`
+}
+
+// PlanBudget sums the estimated tokens recorded for the session's URLs and picks which
+// to include within budget: URLs are taken in rank order, and one that would overflow
+// the budget is skipped so smaller, lower-ranked URLs can still fill the remainder.
+func PlanBudget(db *dbpkg.DB, sessionID int64, budget int, rank string) (*BudgetPlan, error) {
+	if rank != RankConfidence && rank != RankTFIDF {
+		return nil, fmt.Errorf("unknown rank %q (use %s or %s)", rank, RankConfidence, RankTFIDF)
+	}
+
+	urls, err := db.GetSessionURLsWithMetadata(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var scores map[int64]float64
+	if rank == RankTFIDF {
+		entries, err := ExportKeywords(db, "", sessionID)
+		if err != nil {
+			return nil, err
+		}
+		scores = tfidfScores(entries)
+	}
+
+	plan := &BudgetPlan{SessionID: sessionID, Budget: budget, Rank: rank, URLs: []BudgetURL{}}
+	for _, u := range urls {
+		if u.EstimatedTokens <= 0 {
+			plan.Unestimated++
+			continue
+		}
+		score := u.DetectionConfidence
+		if rank == RankTFIDF {
+			score = scores[u.URLID]
+		}
+		plan.URLs = append(plan.URLs, BudgetURL{URLID: u.URLID, URL: u.URL, Tokens: u.EstimatedTokens, Score: math.Round(score*100) / 100})
+		plan.TotalTokens += u.EstimatedTokens
+	}
+	plan.Fits = plan.TotalTokens <= budget
+
+	selectWithinBudget(plan.URLs, budget)
+	for _, u := range plan.URLs {
+		if u.Included {
+			plan.SelectedTokens += u.Tokens
+			plan.SelectedCount++
+		}
+	}
+	sort.SliceStable(plan.URLs, func(i, j int) bool { return plan.URLs[i].Tokens > plan.URLs[j].Tokens })
+	return plan, nil
+}
+
+// selectWithinBudget marks URLs included greedily by descending score, cheaper URLs first
+// on ties, skipping any that no longer fit.
+func selectWithinBudget(urls []BudgetURL, budget int) {
+	order := make([]int, len(urls))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ua, ub := urls[order[a]], urls[order[b]]
+		if ua.Score != ub.Score {
+			return ua.Score > ub.Score
+		}
+		return ua.Tokens < ub.Tokens
+	})
+
+	remaining := budget
+	for _, i := range order {
+		if urls[i].Tokens <= remaining {
+			urls[i].Included = true
+			remaining -= urls[i].Tokens
+		}
+	}
+}
+
+// tfidfScores weighs each URL by how distinctive its stored keywords are within the
+// session: the sum of each keyword's share of the URL's counts times log(N/df).
+// Keywords every URL shares add nothing.
+func tfidfScores(entries []KeywordEntry) map[int64]float64 {
+	totals := make(map[int64]int)
+	docFreq := make(map[string]int)
+	for _, e := range entries {
+		totals[e.URLID] += e.Count
+		docFreq[e.Keyword]++
+	}
+
+	scores := make(map[int64]float64, len(totals))
+	docs := float64(len(totals))
+	for _, e := range entries {
+		if totals[e.URLID] == 0 {
+			continue
+		}
+		tf := float64(e.Count) / float64(totals[e.URLID])
+		scores[e.URLID] += tf * math.Log(docs/float64(docFreq[e.Keyword]))
+	}
+	return scores
+}
@@ -0,0 +1,91 @@
+package corpus
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestPlanBudget(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://go.dev/doc/", "https://go.dev/ref/", "https://example.com/blog/", "https://example.com/down/"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	pages := []struct {
+		tokens     int
+		confidence float64
+		keywords   string
+	}{
+		{60, 9, `["go:5"]`},
+		{50, 8, `["go:5","rare:5"]`},
+		{30, 5, `["go:5"]`},
+		{0, 0, ``}, // Failed: no estimate
+	}
+	ids := make([]int64, len(urls))
+	for i, u := range urls {
+		if ids[i], err = database.InsertURL(u); err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		info := dbpkg.ContentTypeInfo{
+			DetectionConfidence: sql.NullFloat64{Float64: pages[i].confidence, Valid: true},
+			TopKeywords:         dbpkg.NewNullString(pages[i].keywords),
+		}
+		if err := database.UpdateURLContentType(ids[i], info); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+		if err := database.InsertSessionResult(sessionID, ids[i], "success", 200, "", "", 0, pages[i].tokens, ""); err != nil {
+			t.Fatalf("InsertSessionResult() error = %v", err)
+		}
+	}
+
+	included := func(plan *BudgetPlan) []int64 {
+		var got []int64
+		for _, u := range plan.URLs {
+			if u.Included {
+				got = append(got, u.URLID)
+			}
+		}
+		return got
+	}
+
+	plan, err := PlanBudget(database, sessionID, 100, RankConfidence)
+	if err != nil {
+		t.Fatalf("PlanBudget() error = %v", err)
+	}
+	if plan.TotalTokens != 140 || plan.Fits || plan.Unestimated != 1 {
+		t.Errorf("totals = %d tokens, fits %v, %d unestimated; want 140, false, 1", plan.TotalTokens, plan.Fits, plan.Unestimated)
+	}
+	if got := []int64{plan.URLs[0].URLID, plan.URLs[1].URLID, plan.URLs[2].URLID}; !reflect.DeepEqual(got, ids[:3]) {
+		t.Errorf("URLs ordered %v, want most tokens first %v", got, ids[:3])
+	}
+	// 60 fits, 50 would overflow the remaining 40 and is skipped, 30 fills the gap
+	if got, want := included(plan), []int64{ids[0], ids[2]}; !reflect.DeepEqual(got, want) || plan.SelectedTokens != 90 || plan.SelectedCount != 2 {
+		t.Errorf("by confidence selected %v (%d tokens), want %v (90 tokens)", got, plan.SelectedTokens, want)
+	}
+
+	// Only the second URL has a keyword the others lack; the tie between the rest goes to the cheaper one
+	plan, err = PlanBudget(database, sessionID, 100, RankTFIDF)
+	if err != nil {
+		t.Fatalf("PlanBudget() error = %v", err)
+	}
+	if got, want := included(plan), []int64{ids[1], ids[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("by tfidf selected %v, want %v", got, want)
+	}
+
+	if plan, err := PlanBudget(database, sessionID, 500, RankConfidence); err != nil || !plan.Fits || plan.SelectedCount != 3 {
+		t.Errorf("PlanBudget(500) = %+v, %v, want everything to fit", plan, err)
+	}
+	if _, err := PlanBudget(database, sessionID, 100, "random"); err == nil {
+		t.Error("PlanBudget() accepted an unknown rank")
+	}
+}
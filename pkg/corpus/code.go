@@ -0,0 +1,104 @@
+package corpus
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/extractors"
+	"gopkg.in/yaml.v3"
+)
+
+// DocsExtractionFile is the per-URL docs extraction fetch writes for docs pages.
+const DocsExtractionFile = "docs.yaml"
+
+// CodeOptions filter the blocks ExportCodeBlocks returns.
+type CodeOptions struct {
+	Language string // Only blocks in this language, case-insensitive ("" = any)
+	MinLines int    // Only blocks with at least this many lines
+}
+
+// CodeEntry is one extracted code block and where it came from.
+type CodeEntry struct {
+	URLID    int64  `json:"url_id"`
+	URL      string `json:"url"`
+	Language string `json:"language"`
+	Lines    int    `json:"lines"`
+	Context  string `json:"context,omitempty"` // Heading of the section the block is in
+	Code     string `json:"code"`
+	Source   string `json:"source"` // docs.yaml, or parsed when re-extracted from the stored page
+}
+
+// ExportCodeBlocks returns the code blocks of every URL in the session, in session
+// order. Each URL's docs.yaml is used when fetch wrote one; otherwise the blocks are
+// re-extracted from its stored parsed page. Both are read from store. URLs without
+// code, or without either artifact, contribute nothing.
+func ExportCodeBlocks(db *dbpkg.DB, store artifact_manager.Store, sessionID int64, opts CodeOptions) ([]CodeEntry, error) {
+	urls, err := db.GetSessionURLs(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []CodeEntry{}
+	for _, u := range urls {
+		blocks, source, err := loadCodeBlocks(store, u.URLID)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			lines := countLines(block.Code)
+			if lines == 0 || lines < opts.MinLines {
+				continue
+			}
+			if opts.Language != "" && !strings.EqualFold(block.Language, opts.Language) {
+				continue
+			}
+			entries = append(entries, CodeEntry{
+				URLID:    u.URLID,
+				URL:      u.OriginalURL,
+				Language: block.Language,
+				Lines:    lines,
+				Context:  block.Context,
+				Code:     block.Code,
+				Source:   source,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// loadCodeBlocks reads urlID's code blocks from its docs extraction in store, falling
+// back to extracting them from the stored page. No artifact at all is not an error.
+func loadCodeBlocks(store artifact_manager.Store, urlID int64) ([]extractors.CodeBlock, string, error) {
+	key := artifact_manager.URLArtifactKey(urlID, DocsExtractionFile)
+	if data, err := store.Get(key); err == nil {
+		var docs extractors.DocsExtraction
+		if err := yaml.Unmarshal(data, &docs); err != nil {
+			return nil, "", fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+		return docs.CodeBlocks, DocsExtractionFile, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, "", fmt.Errorf("failed to read %s: %w", key, err)
+	}
+
+	page, found, err := artifact_manager.NewManagerWithStore(store, "", 0).GetParsedPageByID(urlID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read parsed page of URL %d: %w", urlID, err)
+	}
+	if !found {
+		return nil, "", nil
+	}
+	return extractors.ExtractDocs(page).CodeBlocks, "parsed", nil
+}
+
+// countLines counts code's lines, ignoring a trailing newline.
+func countLines(code string) int {
+	code = strings.TrimRight(code, "\n")
+	if strings.TrimSpace(code) == "" {
+		return 0
+	}
+	return strings.Count(code, "\n") + 1
+}
@@ -0,0 +1,86 @@
+package corpus
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestExportCodeBlocks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://docs.python.org/3/tutorial/", "https://example.com/guide", "https://example.com/about"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "full-parse", "full", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	ids := make([]int64, len(urls))
+	for i, u := range urls {
+		if ids[i], err = database.InsertURL(u); err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+	}
+
+	baseDir := t.TempDir()
+	m, err := artifact_manager.NewManager(baseDir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+	// A docs page with its extraction written by fetch
+	docs := "code_blocks:\n" +
+		"  - language: python\n    code: |\n      for i in range(3):\n          print(i)\n    context: Loops\n" +
+		"  - language: bash\n    code: pip install requests\n    context: Setup\n"
+	if err := m.SetURLArtifact(ids[0], DocsExtractionFile, []byte(docs)); err != nil {
+		t.Fatal(err)
+	}
+	// A page without docs.yaml: its blocks are re-extracted
+	storePage(t, m, ids[1], &models.Page{
+		URL: urls[1],
+		Content: []models.Section{{
+			ID:      "section-1",
+			Heading: &models.ContentBlock{Type: "h2", Text: "Usage"},
+			Blocks:  []models.ContentBlock{{Type: "code", Code: &models.Code{Language: "Python", Content: "import os\nos.getcwd()\nprint('ok')"}}},
+		}},
+	})
+	// No code at all
+	storePage(t, m, ids[2], &models.Page{URL: urls[2], FlatContent: []models.ContentBlock{{Type: "p", Text: "About"}}})
+
+	all, err := ExportCodeBlocks(database, m.Store(), sessionID, CodeOptions{})
+	if err != nil {
+		t.Fatalf("ExportCodeBlocks() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("len(entries) = %d, want 3: %+v", len(all), all)
+	}
+	first := all[0]
+	if first.URL != urls[0] || first.Language != "python" || first.Lines != 2 || first.Context != "Loops" || first.Source != DocsExtractionFile {
+		t.Errorf("first entry = %+v, want the python loop from docs.yaml", first)
+	}
+	if all[2].URLID != ids[1] || all[2].Context != "Usage" || all[2].Source != "parsed" {
+		t.Errorf("last entry = %+v, want the block re-extracted from the stored page", all[2])
+	}
+
+	python, err := ExportCodeBlocks(database, m.Store(), sessionID, CodeOptions{Language: "PYTHON", MinLines: 3})
+	if err != nil {
+		t.Fatalf("ExportCodeBlocks() error = %v", err)
+	}
+	if len(python) != 1 || python[0].URLID != ids[1] {
+		t.Errorf("python with 3+ lines = %+v, want only the re-extracted block", python)
+	}
+
+	if err := os.WriteFile(artifact_manager.GetURLArtifactPath(baseDir, ids[2], DocsExtractionFile), []byte("code_blocks: ["), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExportCodeBlocks(database, m.Store(), sessionID, CodeOptions{}); err == nil {
+		t.Error("ExportCodeBlocks() with a corrupt docs.yaml succeeded, want an error")
+	}
+}
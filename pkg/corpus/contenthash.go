@@ -0,0 +1,37 @@
+package corpus
+
+import (
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/extractor"
+)
+
+// contentHashMinConfidence drops the blocks the parser scores as boilerplate (navigation,
+// footers, link lists), whose churn would otherwise mark every page as changed.
+const contentHashMinConfidence = 0.5
+
+// PageContentHash hashes the page's plain text without boilerplate blocks, whitespace
+// collapsed, so re-fetches of unchanged content hash the same. A page without text
+// blocks (minimal mode) hashes its title and excerpt instead.
+func PageContentHash(page *models.Page) string {
+	return artifact_manager.ContentHash([]byte(PageContentText(page)))
+}
+
+// PageContentText is the text PageContentHash hashes.
+func PageContentText(page *models.Page) string {
+	content := extractor.FilterPage(page, &extractor.Strategy{MinConfidence: contentHashMinConfidence})
+	// FilterPage only walks sections; cheap mode keeps its blocks flat
+	for _, block := range page.FlatContent {
+		if block.Confidence >= contentHashMinConfidence {
+			content.FlatContent = append(content.FlatContent, block)
+		}
+	}
+
+	text := content.ToPlainText()
+	if strings.TrimSpace(text) == "" {
+		text = page.Title + "\n" + page.Metadata.Excerpt
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,33 @@
+package corpus
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestPageContentHash(t *testing.T) {
+	page := func(body, nav string) *models.Page {
+		return &models.Page{Content: []models.Section{{
+			Heading: &models.ContentBlock{Type: "h1", Text: "Guide", Confidence: 0.9},
+			Blocks: []models.ContentBlock{
+				{Type: "p", Text: body, Confidence: 0.8},
+				{Type: "p", Text: nav, Confidence: 0.2},
+			},
+		}}}
+	}
+
+	base := PageContentHash(page("Install the toolkit first.", "Home | Blog | Login"))
+	if got := PageContentHash(page("Install  the toolkit\nfirst.", "Home | Blog | Logout")); got != base {
+		t.Error("hash changed with whitespace and boilerplate only, want it stable")
+	}
+	if got := PageContentHash(page("Install the toolkit last.", "Home | Blog | Login")); got == base {
+		t.Error("hash unchanged after the text changed")
+	}
+
+	cheap := &models.Page{FlatContent: []models.ContentBlock{{Type: "p", Text: "Install the toolkit first.", Confidence: 0.7}}}
+	minimal := &models.Page{Title: "Guide", Metadata: models.PageMetadata{Excerpt: "Widgets"}}
+	if PageContentHash(cheap) == PageContentHash(minimal) {
+		t.Error("cheap and minimal pages hash the same, want flat blocks and title/excerpt hashed")
+	}
+}
@@ -0,0 +1,167 @@
+package corpus
+
+import (
+	"fmt"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/detector"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+)
+
+// DetectPatternClassification explains each page's content-type classification.
+const DetectPatternClassification = "classification"
+
+// ClassificationEvidence is DETECT classification's result for one URL: the stored
+// classification next to a fresh one from the cached HTML, with its signals.
+type ClassificationEvidence struct {
+	URLID             int64  `json:"url_id" yaml:"url_id"`
+	URL               string `json:"url" yaml:"url"`
+	StoredContentType string `json:"stored_content_type,omitempty" yaml:"stored_content_type,omitempty"`
+	Error             string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	detector.ContentTypeExplanation `yaml:",inline"`
+}
+
+// DetectResponse is the data returned by the DETECT verb.
+type DetectResponse struct {
+	Pattern string                   `json:"pattern" yaml:"pattern"`
+	URLs    []ClassificationEvidence `json:"urls" yaml:"urls"`
+}
+
+// DetectOptions mirror the fetch parse options that change what the detector sees.
+type DetectOptions struct {
+	CleanHTML        bool
+	MinContentLength int
+}
+
+func handleDetect(req models.Request) models.Response {
+	pattern, _ := req.Constraints["pattern"].(string)
+	if pattern != DetectPatternClassification {
+		return detectError("unsupported_pattern",
+			fmt.Sprintf("Unsupported detect pattern %q (supported: %s)", pattern, DetectPatternClassification),
+			"Use 'lwp corpus detect --pattern=classification --url-ids=42'")
+	}
+	if len(req.URLIDs) == 0 && req.Session == 0 {
+		return detectError("missing_target", "detect classification needs --url-ids or --session",
+			"Use 'lwp corpus detect --url-ids=42' or 'lwp corpus detect --session=1'")
+	}
+
+	var opts DetectOptions
+	if clean, ok := req.Constraints["clean_html"].(bool); ok {
+		opts.CleanHTML = clean
+	}
+	if minLength, ok := req.Constraints["min_content_length"].(int); ok {
+		opts.MinContentLength = minLength
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return detectError("database_error", fmt.Sprintf("Failed to open database: %v", err),
+			"Ensure database is initialized", "Run 'llm-web-parser db init' if needed")
+	}
+	defer db.Close()
+
+	data, err := ExplainClassifications(db, artifact_manager.DefaultBaseDir, req.URLIDs, int64(req.Session), opts)
+	if err != nil {
+		return detectError("detect_error", err.Error(), "Verify the URL IDs with 'lwp db urls'")
+	}
+
+	explained := 0
+	for _, u := range data.URLs {
+		if u.Error == "" {
+			explained++
+		}
+	}
+	coverage := 0.0
+	if len(data.URLs) > 0 {
+		coverage = float64(explained) / float64(len(data.URLs))
+	}
+	return models.Response{
+		Verb:       VerbDETECT,
+		Data:       data,
+		Confidence: 1.0,
+		Coverage:   coverage,
+		Unknowns:   []string{},
+	}
+}
+
+// ExplainClassifications re-classifies urlIDs (or, without any, every URL in sessionID)
+// from their cached raw HTML and reports the signals behind each classification. URLs
+// without cached HTML are listed with an error rather than failing the whole request.
+func ExplainClassifications(db *dbpkg.DB, baseDir string, urlIDs []int64, sessionID int64, opts DetectOptions) (*DetectResponse, error) {
+	type target struct {
+		id  int64
+		url string
+	}
+	var targets []target
+	if len(urlIDs) > 0 {
+		for _, id := range urlIDs {
+			url, err := db.GetURLByID(id)
+			if err != nil {
+				return nil, fmt.Errorf("URL ID %d not found: %w", id, err)
+			}
+			targets = append(targets, target{id, url})
+		}
+	} else {
+		urls, err := db.GetSessionURLs(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range urls {
+			targets = append(targets, target{u.URLID, u.OriginalURL})
+		}
+	}
+
+	manager, err := artifact_manager.NewManager(baseDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DetectResponse{Pattern: DetectPatternClassification, URLs: []ClassificationEvidence{}}
+	for _, t := range targets {
+		evidence := ClassificationEvidence{URLID: t.id, URL: t.url}
+		if info, err := db.GetURLContentInfo(t.id); err == nil && info.ContentType.Valid {
+			evidence.StoredContentType = info.ContentType.String
+		}
+
+		html, found, err := manager.GetRawHTMLByID(t.id)
+		switch {
+		case err != nil:
+			evidence.Error = fmt.Sprintf("failed to read raw HTML: %v", err)
+		case !found:
+			evidence.Error = "raw HTML not cached; re-fetch the URL first"
+		default:
+			explanation, err := parser.ExplainContentType(models.ParseRequest{
+				URL:              t.url,
+				HTML:             string(html),
+				Mode:             models.ParseModeFull,
+				CleanHTML:        opts.CleanHTML,
+				MinContentLength: opts.MinContentLength,
+			})
+			if err != nil {
+				evidence.Error = err.Error()
+			} else {
+				evidence.ContentTypeExplanation = explanation
+			}
+		}
+		resp.URLs = append(resp.URLs, evidence)
+	}
+	return resp, nil
+}
+
+func detectError(errorType, message string, actions ...string) models.Response {
+	return models.Response{
+		Verb:       VerbDETECT,
+		Data:       nil,
+		Confidence: 0.0,
+		Coverage:   0.0,
+		Unknowns:   []string{},
+		Error: &models.ErrorInfo{
+			Type:             errorType,
+			Message:          message,
+			SuggestedActions: actions,
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package corpus
+
+import (
+	"fmt"
+	"sort"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+// KeywordEntry is one URL's count of one keyword: a row of the sparse (long) export.
+type KeywordEntry struct {
+	URLID   int64  `json:"url_id"`
+	Keyword string `json:"keyword"`
+	Count   int    `json:"count"`
+}
+
+// KeywordMatrix is the dense export: a row of counts per URL over a shared vocabulary.
+type KeywordMatrix struct {
+	Vocabulary []string `json:"vocabulary"`
+	URLIDs     []int64  `json:"url_ids"`
+	Counts     [][]int  `json:"counts"` // Counts[i][j] is how often URLIDs[i] uses Vocabulary[j]
+}
+
+// ExportKeywords returns the stored keyword counts (urls.top_keywords) of the session's
+// URLs matching filter, a corpus query filter expression, ordered by URL then count.
+// URLs without stored keywords contribute no entries.
+func ExportKeywords(db *dbpkg.DB, filter string, sessionID int64) ([]KeywordEntry, error) {
+	filterResult, err := ParseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse filter: %w", err)
+	}
+
+	args := append([]interface{}{sessionID}, filterResult.Args...)
+	rows, err := db.Query(`
+		WITH entries AS (
+			SELECT DISTINCT u.url_id, k.value AS entry, instr(k.value, ':') AS sep
+			FROM session_urls su
+			JOIN urls u ON u.url_id = su.url_id
+			JOIN json_each(u.top_keywords) k
+			WHERE su.session_id = ? AND json_valid(u.top_keywords)
+				AND u.url_id IN (SELECT url_id FROM urls WHERE `+filterResult.WhereClause+`)
+		)
+		SELECT url_id, substr(entry, 1, sep - 1) AS keyword, CAST(substr(entry, sep + 1) AS INTEGER) AS count
+		FROM entries
+		WHERE sep > 1
+		ORDER BY url_id, count DESC, keyword
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keywords: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []KeywordEntry{}
+	for rows.Next() {
+		var e KeywordEntry
+		if err := rows.Scan(&e.URLID, &e.Keyword, &e.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan keyword: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keywords: %w", err)
+	}
+	return entries, nil
+}
+
+// BuildKeywordMatrix turns long entries into a dense matrix. The vocabulary is every
+// keyword, most frequent across the URLs first (ties alphabetical), cut to vocabSize
+// when it is positive.
+func BuildKeywordMatrix(entries []KeywordEntry, vocabSize int) KeywordMatrix {
+	totals := make(map[string]int)
+	var urlIDs []int64
+	for i, e := range entries {
+		totals[e.Keyword] += e.Count
+		if i == 0 || entries[i-1].URLID != e.URLID {
+			urlIDs = append(urlIDs, e.URLID)
+		}
+	}
+
+	vocabulary := make([]string, 0, len(totals))
+	for keyword := range totals {
+		vocabulary = append(vocabulary, keyword)
+	}
+	sort.Slice(vocabulary, func(i, j int) bool {
+		if totals[vocabulary[i]] != totals[vocabulary[j]] {
+			return totals[vocabulary[i]] > totals[vocabulary[j]]
+		}
+		return vocabulary[i] < vocabulary[j]
+	})
+	if vocabSize > 0 && len(vocabulary) > vocabSize {
+		vocabulary = vocabulary[:vocabSize]
+	}
+	column := make(map[string]int, len(vocabulary))
+	for j, keyword := range vocabulary {
+		column[keyword] = j
+	}
+
+	matrix := KeywordMatrix{Vocabulary: vocabulary, URLIDs: []int64{}, Counts: [][]int{}}
+	row := make(map[int64]int, len(urlIDs))
+	for _, urlID := range urlIDs {
+		row[urlID] = len(matrix.URLIDs)
+		matrix.URLIDs = append(matrix.URLIDs, urlID)
+		matrix.Counts = append(matrix.Counts, make([]int, len(vocabulary)))
+	}
+	for _, e := range entries {
+		if j, ok := column[e.Keyword]; ok {
+			matrix.Counts[row[e.URLID]][j] = e.Count
+		}
+	}
+	return matrix
+}
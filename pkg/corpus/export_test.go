@@ -0,0 +1,67 @@
+package corpus
+
+import (
+	"reflect"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestExportKeywords(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://go.dev/doc/", "https://go.dev/ref/", "https://example.com/blog/"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	pages := []struct{ contentType, keywords string }{
+		{"docs", `["go:9","module:4"]`},
+		{"docs", `["go:3","spec:5","bad-entry"]`},
+		{"blog", `["recipe:7"]`},
+	}
+	ids := make([]int64, len(urls))
+	for i, u := range urls {
+		if ids[i], err = database.InsertURL(u); err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		info := dbpkg.ContentTypeInfo{
+			ContentType: dbpkg.NewNullString(pages[i].contentType),
+			TopKeywords: dbpkg.NewNullString(pages[i].keywords),
+		}
+		if err := database.UpdateURLContentType(ids[i], info); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	entries, err := ExportKeywords(database, "content_type=docs", sessionID)
+	if err != nil {
+		t.Fatalf("ExportKeywords() error = %v", err)
+	}
+	want := []KeywordEntry{
+		{ids[0], "go", 9}, {ids[0], "module", 4},
+		{ids[1], "spec", 5}, {ids[1], "go", 3},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ExportKeywords() = %+v, want %+v", entries, want)
+	}
+
+	matrix := BuildKeywordMatrix(entries, 2)
+	wantMatrix := KeywordMatrix{
+		Vocabulary: []string{"go", "spec"}, // go 12, spec 5; module 4 is cut
+		URLIDs:     []int64{ids[0], ids[1]},
+		Counts:     [][]int{{9, 0}, {3, 5}},
+	}
+	if !reflect.DeepEqual(matrix, wantMatrix) {
+		t.Errorf("BuildKeywordMatrix() = %+v, want %+v", matrix, wantMatrix)
+	}
+
+	if _, err := ExportKeywords(database, "no_such_field=1", sessionID); err == nil {
+		t.Error("ExportKeywords() with an unknown field: error = nil")
+	}
+}
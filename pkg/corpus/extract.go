@@ -2,9 +2,8 @@ package corpus
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,10 +13,13 @@ import (
 	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 )
 
-// KeywordCount represents a keyword with its aggregate count.
+// KeywordCount represents a keyword with its aggregate count. Word is the lowercase
+// form counts are aggregated under; Display is its most common original spelling
+// ("API" for "api"), or Word when no spelling dominates.
 type KeywordCount struct {
-	Word  string `json:"word"`
-	Count int    `json:"count"`
+	Word    string `json:"word"`
+	Display string `json:"display"`
+	Count   int    `json:"count"`
 }
 
 // ExtractResponse is the data returned by EXTRACT verb.
@@ -105,7 +107,7 @@ func handleExtract(req models.Request) models.Response {
 	}
 
 	// Aggregate keywords from wordcount.txt files
-	aggregated, filesRead, err := aggregateKeywordsFromFiles(urlIDs)
+	aggregated, forms, filesRead, err := aggregateKeywordsFromFiles(artifact_manager.NewFileStore(artifact_manager.DefaultBaseDir), urlIDs)
 	if err != nil {
 		return models.Response{
 			Verb:       VerbEXTRACT,
@@ -123,10 +125,13 @@ func handleExtract(req models.Request) models.Response {
 	// Sort by count descending
 	keywords := make([]KeywordCount, 0, len(aggregated))
 	for word, count := range aggregated {
-		keywords = append(keywords, KeywordCount{Word: word, Count: count})
+		keywords = append(keywords, KeywordCount{Word: word, Display: analytics.DominantForm(word, forms[word]), Count: count})
 	}
 	sort.Slice(keywords, func(i, j int) bool {
-		return keywords[i].Count > keywords[j].Count
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return keywords[i].Word < keywords[j].Word // Stable order for ties
 	})
 
 	// Apply top limit (0 means no limit)
@@ -162,28 +167,24 @@ func handleExtract(req models.Request) models.Response {
 	}
 }
 
-// aggregateKeywordsFromFiles reads wordcount.txt files and aggregates counts.
-// Returns the aggregated map, count of successfully read files, and any error.
-func aggregateKeywordsFromFiles(urlIDs []int64) (map[string]int, int, error) {
+// aggregateKeywordsFromFiles reads each URL's wordcount.txt from store and aggregates counts.
+// Returns the aggregated map, the counts behind each word's display forms, count of
+// successfully read files, and any error.
+func aggregateKeywordsFromFiles(store artifact_manager.Store, urlIDs []int64) (map[string]int, map[string]map[string]int, int, error) {
 	aggregated := make(map[string]int)
+	forms := make(map[string]map[string]int)
 	filesRead := 0
+	stopwords := analytics.StopwordsFor("en")
 
 	for _, urlID := range urlIDs {
-		wordcountPath := filepath.Join(
-			artifact_manager.GetURLDir(artifact_manager.DefaultBaseDir, urlID),
-			"wordcount.txt",
-		)
-
-		// Read and parse wordcount.txt
-		// Path is safe: constructed from constant base dir + database ID, not user input
-		file, err := os.Open(filepath.Clean(wordcountPath)) // #nosec G304
+		data, err := store.Get(artifact_manager.URLArtifactKey(urlID, "wordcount.txt"))
 		if err != nil {
 			// File might not exist for this URL (parse failure, etc.)
 			// Skip silently and continue
 			continue
 		}
 
-		scanner := bufio.NewScanner(file)
+		scanner := bufio.NewScanner(bytes.NewReader(data))
 		fileHasData := false
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
@@ -191,35 +192,36 @@ func aggregateKeywordsFromFiles(urlIDs []int64) (map[string]int, int, error) {
 				continue
 			}
 
-			// Parse "word:count" format
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) != 2 {
+			// Parse "word:count" format, with an optional ":Display" form
+			parts := strings.SplitN(line, ":", 3)
+			if len(parts) < 2 {
 				continue
 			}
 
-			word := parts[0]
 			count, err := strconv.Atoi(parts[1])
 			if err != nil {
 				continue
 			}
 
-			// Normalize curly apostrophes to straight apostrophes
-			// (legacy wordcount files may contain Unicode U+2019 instead of ASCII ')
-			word = strings.ReplaceAll(word, "\u2019", "'")  // U+2019 (right single quote) → '
-			word = strings.ReplaceAll(word, "\u2018", "'")  // U+2018 (left single quote) → '
-
-			// Filter out stopwords (safety net for legacy wordcount files)
-			if analytics.IsStopword(word) {
+			// Clean and filter the word as corpus normalize would (safety net for legacy
+			// wordcount files), so "Go’s" and "go" aggregate together
+			word, ok := analytics.NormalizeKeyword(parts[0], stopwords)
+			if !ok {
 				continue
 			}
 
 			aggregated[word] += count
+			display := word
+			if len(parts) == 3 && strings.ToLower(parts[2]) == word {
+				display = parts[2]
+			}
+			if forms[word] == nil {
+				forms[word] = make(map[string]int)
+			}
+			forms[word][display] += count
 			fileHasData = true
 		}
 
-		// Close file - error ignored as we've already read the data we need
-		_ = file.Close() // #nosec G104
-
 		if err := scanner.Err(); err != nil {
 			// Log error but continue with other files
 			continue
@@ -230,7 +232,7 @@ func aggregateKeywordsFromFiles(urlIDs []int64) (map[string]int, int, error) {
 		}
 	}
 
-	return aggregated, filesRead, nil
+	return aggregated, forms, filesRead, nil
 }
 
 // generateExtractHints creates LLM-specific guidance based on keywords.
@@ -260,7 +262,7 @@ func extractTopN(keywords []KeywordCount, n int) []string {
 
 	result := make([]string, n)
 	for i := 0; i < n; i++ {
-		result[i] = keywords[i].Word
+		result[i] = keywords[i].Display
 	}
 	return result
 }
@@ -177,6 +177,8 @@ var validFields = map[string]bool{
 	"has_toc":              true,
 	"has_code":             true,
 	"has_code_examples":    true,
+	"noindex":              true,
+	"nofollow":             true,
 	"section_count":        true,
 	"citation_count":       true,
 	"code_block_count":     true,
@@ -46,35 +46,72 @@ func Handle(req models.Request) models.Response {
 
 // Placeholder handlers - all return "NOT IMPLEMENTED YET"
 
-func handleIngest(req models.Request) models.Response {
-	return models.NewNotImplementedResponse(VerbINGEST)
-}
+// handleIngest is implemented in ingest.go
 
 // handleExtract is implemented in extract.go
 
-func handleNormalize(req models.Request) models.Response {
-	return models.NewNotImplementedResponse(VerbNORMALIZE)
-}
+// handleNormalize is implemented in normalize.go
 
 func handleCompare(req models.Request) models.Response {
 	return models.NewNotImplementedResponse(VerbCOMPARE)
 }
 
-func handleDetect(req models.Request) models.Response {
-	return models.NewNotImplementedResponse(VerbDETECT)
-}
+// handleDetect is implemented in detect.go
 
-func handleTrace(req models.Request) models.Response {
-	return models.NewNotImplementedResponse(VerbTRACE)
-}
+// handleTrace is implemented in trace.go
 
 func handleScore(req models.Request) models.Response {
 	return models.NewNotImplementedResponse(VerbSCORE)
 }
 
 func handleQuery(req models.Request) models.Response {
-	// If no filter provided, show helpful examples instead of erroring
-	if req.Filter == "" {
+	var opts QueryOptions
+	if domain, ok := req.Constraints["domain"].(string); ok {
+		opts.Domain = domain
+	}
+	if facet, ok := req.Constraints["facet"].(string); ok && facet != "" {
+		if facet != "domain" {
+			return models.Response{
+				Verb:       VerbQUERY,
+				Data:       nil,
+				Confidence: 0.0,
+				Coverage:   0.0,
+				Unknowns:   []string{},
+				Error: &models.ErrorInfo{
+					Type:             "invalid_facet",
+					Message:          fmt.Sprintf("Unsupported facet: %s", facet),
+					SuggestedActions: []string{"Use --facet=domain"},
+				},
+			}
+		}
+		opts.FacetDomains = true
+	}
+	if snippets, ok := req.Constraints["snippets"].(bool); ok {
+		opts.Snippets = snippets
+	}
+	if explain, ok := req.Constraints["explain_sql"].(bool); ok {
+		opts.ExplainSQL = explain
+	}
+	if sortBy, ok := req.Constraints["sort"].(string); ok && sortBy != "" {
+		if sortBy != SortByDate {
+			return models.Response{
+				Verb:       VerbQUERY,
+				Data:       nil,
+				Confidence: 0.0,
+				Coverage:   0.0,
+				Unknowns:   []string{},
+				Error: &models.ErrorInfo{
+					Type:             "invalid_sort",
+					Message:          fmt.Sprintf("Unsupported sort: %s", sortBy),
+					SuggestedActions: []string{"Use --sort=date"},
+				},
+			}
+		}
+		opts.SortBy = sortBy
+	}
+
+	// If nothing to query by, show helpful examples instead of erroring
+	if req.Filter == "" && opts.Domain == "" && !opts.FacetDomains {
 		return models.Response{
 			Verb:       VerbQUERY,
 			Data:       nil,
@@ -108,7 +145,7 @@ func handleQuery(req models.Request) models.Response {
 	defer db.Close()
 
 	// Execute query
-	resp, err := ExecuteQuery(db, req.Filter, req.Session)
+	resp, err := ExecuteQuery(db, req.Filter, req.Session, opts)
 	if err != nil {
 		return models.Response{
 			Verb:       VerbQUERY,
@@ -170,8 +207,9 @@ func generateQueryHelp(sessionID int) string {
 Content types (academic, docs, wiki, news, blog, repo):
   llm-web-parser corpus query%s --filter="content_type=academic"       # Research papers
 
-Boolean features extracted during parsing (has_code_examples, has_abstract, has_toc, has_infobox):
+Boolean features extracted during parsing (has_code_examples, has_abstract, has_toc, has_infobox, noindex, nofollow):
   llm-web-parser corpus query%s --filter="has_code_examples"           # URLs with code blocks
+  llm-web-parser corpus query%s --filter="noindex=0"                   # Skip pages that ask not to be indexed
 
 Numeric metrics from parsed content (citation_count, section_count, code_block_count, detection_confidence):
   llm-web-parser corpus query%s --filter="citation_count>=20"          # Highly cited papers (>=20 citations)
@@ -179,6 +217,10 @@ Numeric metrics from parsed content (citation_count, section_count, code_block_c
 Search by keyword (use any word from 'corpus extract'):
   llm-web-parser corpus query%s --filter="keyword:api"                 # URLs about "api"
 
+Filter or break down by domain (--domain is a substring match):
+  llm-web-parser corpus query%s --domain=golang.org --filter="content_type=docs"
+  llm-web-parser corpus query%s --facet=domain                         # URL count per domain
+
 Combine with AND/OR:
   llm-web-parser corpus query%s --filter="has_code_examples AND keyword:python"
   llm-web-parser corpus query%s --filter="content_type=academic OR content_type=docs"
@@ -190,6 +232,6 @@ Where this data comes from:
   - Keywords from text analysis (run 'corpus extract')
 
 Run 'llm-web-parser corpus query --help' for full field reference.`,
-		sessionStr, sessionStr, sessionStr, sessionStr,
-		sessionStr, sessionStr)
+		sessionStr, sessionStr, sessionStr, sessionStr, sessionStr,
+		sessionStr, sessionStr, sessionStr, sessionStr)
 }
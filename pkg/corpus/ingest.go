@@ -0,0 +1,329 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/analytics"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/detector"
+	"github.com/dtnitsch/llm-web-parser/pkg/extractors"
+	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+	"gopkg.in/yaml.v3"
+)
+
+// IngestFeatures is recorded as the features and parse mode of sessions INGEST creates.
+const IngestFeatures = "ingest"
+
+// DefaultIngestKeywords is how many keywords INGEST keeps in urls.top_keywords, as
+// fetch --store-keywords does by default.
+const DefaultIngestKeywords = 25
+
+// IngestOptions control what INGEST loads and how the session is labelled.
+type IngestOptions struct {
+	Patterns      []string // Glob patterns of models.Page JSON files
+	Tag           string   // Session tag; empty leaves the session untagged
+	StoreKeywords int      // Keywords kept in urls.top_keywords; <= 0 keeps all
+}
+
+// IngestResponse is the data returned by the INGEST verb.
+type IngestResponse struct {
+	SessionID int64         `json:"session_id,omitempty" yaml:"session_id,omitempty"` // 0 when nothing was ingested
+	Tag       string        `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Files     int           `json:"files" yaml:"files"` // Files matched by the patterns
+	Ingested  int           `json:"ingested" yaml:"ingested"`
+	Skipped   int           `json:"skipped" yaml:"skipped"` // Decoded, but not a page that can be stored
+	Failed    int           `json:"failed" yaml:"failed"`   // Unreadable or not valid page JSON
+	URLs      []IngestedURL `json:"urls" yaml:"urls"`
+	Skips     []IngestIssue `json:"skips,omitempty" yaml:"skips,omitempty"`
+	Errors    []IngestIssue `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// IngestedURL is one page stored by INGEST.
+type IngestedURL struct {
+	URLID       int64  `json:"url_id" yaml:"url_id"`
+	URL         string `json:"url" yaml:"url"`
+	File        string `json:"file" yaml:"file"`
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	WordCount   int    `json:"word_count" yaml:"word_count"`
+}
+
+// IngestIssue explains why a file was skipped or failed.
+type IngestIssue struct {
+	File   string `json:"file" yaml:"file"`
+	URL    string `json:"url,omitempty" yaml:"url,omitempty"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// handleIngest implements the INGEST verb.
+func handleIngest(req models.Request) models.Response {
+	opts := IngestOptions{StoreKeywords: DefaultIngestKeywords}
+	if from, ok := req.Constraints["from"].([]string); ok {
+		opts.Patterns = from
+	}
+	if tag, ok := req.Constraints["session_tag"].(string); ok {
+		opts.Tag = tag
+	}
+	if len(opts.Patterns) == 0 {
+		return ingestError("missing_source", "ingest needs parsed page files to load",
+			"Use 'lwp corpus ingest --from \"parsed/*.json\"'")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return ingestError("database_error", fmt.Sprintf("Failed to open database: %v", err),
+			"Ensure database is initialized", "Run 'llm-web-parser db init' if needed")
+	}
+	defer db.Close()
+
+	resp, err := IngestPages(db, artifact_manager.DefaultBaseDir, opts)
+	if err != nil {
+		return ingestError("ingest_error", err.Error())
+	}
+
+	unknowns := []string{}
+	for _, issue := range append(append([]IngestIssue{}, resp.Skips...), resp.Errors...) {
+		unknowns = append(unknowns, fmt.Sprintf("%s: %s", issue.File, issue.Reason))
+	}
+	return models.Response{
+		Verb:       VerbINGEST,
+		Data:       resp,
+		Confidence: 1.0,
+		Coverage:   float64(resp.Ingested) / float64(resp.Files),
+		Unknowns:   unknowns,
+	}
+}
+
+// IngestPages loads pre-parsed pages into the corpus as if fetch had stored them:
+// each page's URL is added, its content type detected when the file doesn't carry
+// one, its keywords counted, and its artifacts stored under baseDir by StorePage.
+// All ingested pages go into one new session. Files that can't be read or decoded
+// are reported in Errors, pages without a usable URL or content in Skips; neither
+// stops the rest. Nothing is fetched.
+func IngestPages(db *dbpkg.DB, baseDir string, opts IngestOptions) (*IngestResponse, error) {
+	files, err := ExpandPatterns(opts.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match %s", strings.Join(opts.Patterns, ", "))
+	}
+
+	manager, err := artifact_manager.NewManager(baseDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &IngestResponse{Tag: opts.Tag, Files: len(files), URLs: []IngestedURL{}}
+	skip := func(file, rawURL, reason string) {
+		resp.Skips = append(resp.Skips, IngestIssue{File: file, URL: rawURL, Reason: reason})
+		resp.Skipped++
+	}
+	fail := func(file, reason string) {
+		resp.Errors = append(resp.Errors, IngestIssue{File: file, Reason: reason})
+		resp.Failed++
+	}
+
+	type pending struct {
+		file  string
+		urlID int64
+		page  *models.Page
+	}
+	var pages []pending
+	seen := make(map[int64]string) // url_id -> file that claimed it
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fail(file, err.Error())
+			continue
+		}
+		var page models.Page
+		if err := json.Unmarshal(data, &page); err != nil {
+			fail(file, fmt.Sprintf("not a parsed page: %v", err))
+			continue
+		}
+
+		if reason := ingestableURL(page.URL); reason != "" {
+			skip(file, page.URL, reason)
+			continue
+		}
+		if len(page.Content) == 0 && len(page.FlatContent) == 0 {
+			skip(file, page.URL, "page has no content blocks")
+			continue
+		}
+		urlID, err := db.InsertURL(page.URL)
+		if err != nil {
+			fail(file, err.Error())
+			continue
+		}
+		if first, ok := seen[urlID]; ok {
+			skip(file, page.URL, fmt.Sprintf("same URL as %s", first))
+			continue
+		}
+		seen[urlID] = file
+		pages = append(pages, pending{file: file, urlID: urlID, page: &page})
+	}
+	if len(pages) == 0 {
+		return resp, nil
+	}
+
+	urlIDs := make([]int64, len(pages))
+	for i, p := range pages {
+		urlIDs[i] = p.urlID
+	}
+	sessionID, err := db.CreateSession(urlIDs, IngestFeatures, IngestFeatures)
+	if err != nil {
+		return nil, err
+	}
+	resp.SessionID = sessionID
+	// There is no raw HTML to re-parse, so refresh must not try
+	if err := db.SetSessionRawStored(sessionID, false); err != nil {
+		return nil, err
+	}
+	if opts.Tag != "" {
+		if err := db.SetSessionTag(sessionID, opts.Tag); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, p := range pages {
+		size, ingestErr := ingestPage(db, manager, baseDir, p.urlID, p.file, p.page, opts.StoreKeywords)
+		if ingestErr != nil {
+			fail(p.file, ingestErr.Error())
+			if err := db.InsertSessionResult(sessionID, p.urlID, "failed", 0, "ingest_error", ingestErr.Error(), 0, 0, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// No HTTP status: the page never came over the network
+		tokens := chunk.TokensForWords(p.page.Metadata.WordCount)
+		if err := db.InsertSessionResult(sessionID, p.urlID, "success", 0, "", "", size, tokens, PageContentHash(p.page)); err != nil {
+			return nil, err
+		}
+		resp.URLs = append(resp.URLs, IngestedURL{
+			URLID:       p.urlID,
+			URL:         p.page.URL,
+			File:        p.file,
+			ContentType: p.page.Metadata.ContentType,
+			WordCount:   p.page.Metadata.WordCount,
+		})
+		resp.Ingested++
+	}
+	if err := db.UpdateSessionStats(sessionID, resp.Ingested, len(pages)-resp.Ingested); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ingestPage stores one decoded page for urlID through StorePage, as fetch's full
+// parse does, and returns the size of its generic.yaml.
+func ingestPage(db *dbpkg.DB, manager *artifact_manager.Manager, baseDir string, urlID int64, file string, page *models.Page, storeKeywords int) (int64, error) {
+	page.ComputeMetadata()
+	text := page.ToPlainText()
+
+	if page.Metadata.ContentType == "" {
+		detected := detector.DetectContentType(page.URL, page.Title, text)
+		page.Metadata.ContentType = detected.ContentType
+		page.Metadata.ContentSubtype = detected.ContentSubtype
+		page.Metadata.Confidence = detected.Confidence
+	}
+	if page.Metadata.CodeBlockCount == 0 {
+		page.Metadata.CodeBlockCount = countCodeBlocks(page)
+		page.Metadata.HasCodeExamples = page.Metadata.HasCodeExamples || page.Metadata.CodeBlockCount > 0
+	}
+
+	a := &analytics.Analytics{}
+	counts := mapreduce.Map(text, page.Metadata.Language, a)
+	// generic.yaml lists the top 25 whatever storeKeywords is, as fetch does
+	page.Metadata.TopKeywords = nil
+	for _, wc := range mapreduce.Rank(counts, DefaultIngestKeywords) {
+		page.Metadata.TopKeywords = append(page.Metadata.TopKeywords, wc.Word)
+	}
+	links := extractors.ExtractLinks(page)
+
+	yamlData, err := yaml.Marshal(page)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal page: %w", err)
+	}
+	stored, err := StorePage(db, manager, baseDir, StoredPage{
+		URLID:         urlID,
+		Page:          page,
+		YAML:          yamlData,
+		WordCounts:    counts,
+		DisplayForms:  a.DisplayFormsIn(text, page.Metadata.Language),
+		StoreKeywords: storeKeywords,
+		Links:         links,
+		Provenance:    map[string]string{"ingested_from": file},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return stored.SizeBytes, nil
+}
+
+// ingestableURL returns why rawURL can't identify an ingested page, or "" if it can.
+func ingestableURL(rawURL string) string {
+	if rawURL == "" {
+		return "page has no url"
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "url is not an absolute http(s) URL"
+	}
+	return ""
+}
+
+// ExpandPatterns returns the regular files matching any glob pattern, sorted and without
+// duplicates. Ingest and bench use it for their file arguments.
+func ExpandPatterns(patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err != nil || info.IsDir() || seen[m] {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func countCodeBlocks(page *models.Page) int {
+	count := 0
+	for _, b := range page.AllTextBlocks() {
+		if b.Code != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func ingestError(errorType, message string, actions ...string) models.Response {
+	return models.Response{
+		Verb:       VerbINGEST,
+		Data:       nil,
+		Confidence: 0.0,
+		Coverage:   0.0,
+		Unknowns:   []string{},
+		Error: &models.ErrorInfo{
+			Type:             errorType,
+			Message:          message,
+			SuggestedActions: actions,
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package corpus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/chunk"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func writePageJSON(t *testing.T, path string, page *models.Page) {
+	t.Helper()
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("failed to marshal page: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIngestPages(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	dir := t.TempDir()
+	writePageJSON(t, filepath.Join(dir, "a.json"), &models.Page{
+		URL:   "https://go.dev/doc/effective_go",
+		Title: "Effective Go",
+		Content: []models.Section{{
+			ID:      "section-1",
+			Level:   1,
+			Heading: &models.ContentBlock{Type: "h1", Text: "Effective Go"},
+			Blocks: []models.ContentBlock{
+				{Type: "p", Text: "Goroutines are cheap. Goroutines multiplex onto threads.",
+					Links: []models.Link{{Href: "/ref/spec", Text: "spec"}}},
+				{Type: "code", Code: &models.Code{Language: "go", Content: "go f()"}},
+			},
+		}},
+	})
+	// The same URL again, from another export
+	writePageJSON(t, filepath.Join(dir, "b.json"), &models.Page{
+		URL:         "https://go.dev/doc/effective_go",
+		FlatContent: []models.ContentBlock{{Type: "p", Text: "Duplicate"}},
+	})
+	writePageJSON(t, filepath.Join(dir, "c.json"), &models.Page{
+		URL:         "file:///tmp/page.html",
+		FlatContent: []models.ContentBlock{{Type: "p", Text: "Local"}},
+	})
+	if err := os.WriteFile(filepath.Join(dir, "d.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	baseDir := t.TempDir()
+	resp, err := IngestPages(database, baseDir, IngestOptions{
+		Patterns:      []string{filepath.Join(dir, "*.json")},
+		Tag:           "imported",
+		StoreKeywords: DefaultIngestKeywords,
+	})
+	if err != nil {
+		t.Fatalf("IngestPages() error = %v", err)
+	}
+	if resp.Files != 4 || resp.Ingested != 1 || resp.Skipped != 2 || resp.Failed != 1 {
+		t.Fatalf("files/ingested/skipped/failed = %d/%d/%d/%d, want 4/1/2/1",
+			resp.Files, resp.Ingested, resp.Skipped, resp.Failed)
+	}
+	if !strings.Contains(resp.Skips[0].Reason, "a.json") {
+		t.Errorf("duplicate skip reason = %q, want it to name a.json", resp.Skips[0].Reason)
+	}
+	if !strings.HasSuffix(resp.Errors[0].File, "d.json") {
+		t.Errorf("error file = %q, want d.json", resp.Errors[0].File)
+	}
+
+	sess, err := database.GetSessionByID(resp.SessionID)
+	if err != nil {
+		t.Fatalf("GetSessionByID() error = %v", err)
+	}
+	if sess.Tag != "imported" || sess.SuccessCount != 1 || sess.RawStored {
+		t.Errorf("session = %+v, want tag imported, 1 success, no raw HTML", sess)
+	}
+
+	urlID := resp.URLs[0].URLID
+	info, err := database.GetURLContentInfo(urlID)
+	if err != nil {
+		t.Fatalf("GetURLContentInfo() error = %v", err)
+	}
+	if !info.ContentType.Valid || info.CodeBlockCount != 1 || !info.HasCodeExamples {
+		t.Errorf("content info = %+v, want a detected type and one code block", info)
+	}
+	if !strings.Contains(info.TopKeywords.String, `"goroutines:2"`) {
+		t.Errorf("top_keywords = %s, want goroutines:2", info.TopKeywords.String)
+	}
+
+	page, found, err := artifact_manager.ReadParsedPage(baseDir, urlID)
+	if err != nil || !found {
+		t.Fatalf("ReadParsedPage() = %v, %v", found, err)
+	}
+	if page.Title != "Effective Go" || page.Metadata.WordCount == 0 {
+		t.Errorf("stored page = %q with %d words, want the ingested page", page.Title, page.Metadata.WordCount)
+	}
+	if _, err := os.Stat(artifact_manager.GetURLArtifactPath(baseDir, urlID, "wordcount.txt")); err != nil {
+		t.Errorf("wordcount.txt not written: %v", err)
+	}
+
+	// Stored the way fetch stores a page: links included, hashes recorded
+	artifacts, err := database.ListArtifacts(urlID)
+	if err != nil {
+		t.Fatalf("ListArtifacts() error = %v", err)
+	}
+	hashes := make(map[string]string)
+	for _, a := range artifacts {
+		hashes[a.TypeName] = a.ContentHash
+	}
+	for _, typeName := range []string{"yaml_parsed", "links"} {
+		if hashes[typeName] == "" {
+			t.Errorf("artifact %s missing or without a content hash, got %v", typeName, hashes)
+		}
+	}
+	provenance, err := database.GetParsedArtifactMetadata(urlID)
+	if err != nil {
+		t.Fatalf("GetParsedArtifactMetadata() error = %v", err)
+	}
+	if provenance[ProvenanceParserVersion] == "" || !strings.HasSuffix(provenance["ingested_from"], "a.json") {
+		t.Errorf("provenance = %v, want a parser version and the source file", provenance)
+	}
+
+	var tokens int
+	var contentHash string
+	if err := database.QueryRow("SELECT estimated_tokens, content_hash FROM session_results WHERE url_id = ?", urlID).Scan(&tokens, &contentHash); err != nil {
+		t.Fatalf("session result: %v", err)
+	}
+	if tokens != chunk.TokensForWords(page.Metadata.WordCount) || contentHash != PageContentHash(page) {
+		t.Errorf("session result tokens/hash = %d/%q, want %d/%q", tokens, contentHash,
+			chunk.TokensForWords(page.Metadata.WordCount), PageContentHash(page))
+	}
+}
+
+func TestIngestPages_NoMatches(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	if _, err := IngestPages(database, t.TempDir(), IngestOptions{Patterns: []string{"missing/*.json"}}); err == nil {
+		t.Error("IngestPages() with no matching files succeeded, want an error")
+	}
+}
+
+func TestExpandPatterns(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.html", "a.html", "c.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "d.html"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Overlapping patterns list each file once, sorted; directories are skipped
+	files, err := ExpandPatterns([]string{filepath.Join(dir, "*.html"), filepath.Join(dir, "a.*")})
+	if err != nil {
+		t.Fatalf("ExpandPatterns() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a.html"), filepath.Join(dir, "b.html")}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ExpandPatterns() = %v, want %v", files, want)
+	}
+
+	if _, err := ExpandPatterns([]string{"[bad"}); err == nil {
+		t.Error("ExpandPatterns() accepted a malformed pattern")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 )
 
@@ -22,6 +23,8 @@ type URLMetadata struct {
 	HasInfobox          bool    `yaml:"has_infobox"`
 	HasTOC              bool    `yaml:"has_toc"`
 	HasCodeExamples     bool    `yaml:"has_code_examples"`
+	NoIndex             bool    `yaml:"noindex,omitempty"`
+	NoFollow            bool    `yaml:"nofollow,omitempty"`
 	SectionCount        int     `yaml:"section_count"`
 	CitationCount       int     `yaml:"citation_count"`
 	CodeBlockCount      int     `yaml:"code_block_count"`
@@ -52,6 +55,8 @@ func WriteMetadataFile(db *dbpkg.DB, urlID int64, baseDir string) error {
 		HasInfobox:          info.HasInfobox,
 		HasTOC:              info.HasTOC,
 		HasCodeExamples:     info.HasCodeExamples,
+		NoIndex:             info.NoIndex,
+		NoFollow:            info.NoFollow,
 		SectionCount:        info.SectionCount,
 		CitationCount:       info.CitationCount,
 		CodeBlockCount:      info.CodeBlockCount,
@@ -86,7 +91,7 @@ func WriteMetadataFile(db *dbpkg.DB, urlID int64, baseDir string) error {
 	// Write file
 	// Metadata files contain public data, standard file permissions (0644) are appropriate
 	// #nosec G306
-	if err := os.WriteFile(metadataPath, yamlBytes, 0644); err != nil {
+	if err := artifact_manager.WriteFileAtomic(metadataPath, yamlBytes, 0644); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
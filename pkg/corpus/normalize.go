@@ -0,0 +1,287 @@
+package corpus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/analytics"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+)
+
+// NormalizeOptions control how NORMALIZE rewrites a session's keywords.
+type NormalizeOptions struct {
+	Stem      bool     // Also merge English plurals into their singular (analytics.Stem)
+	Stopwords []string // Words to remove on top of the bundled English stopwords
+	DryRun    bool     // Count what would change without writing anything
+}
+
+// NormalizeReport is the data returned by the NORMALIZE verb.
+type NormalizeReport struct {
+	SessionID   int64          `json:"session_id" yaml:"session_id"`
+	DryRun      bool           `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	Stemmed     bool           `json:"stemmed,omitempty" yaml:"stemmed,omitempty"`
+	URLs        int            `json:"urls" yaml:"urls"`
+	URLsChanged int            `json:"urls_changed" yaml:"urls_changed"`
+	TopKeywords KeywordCleanup `json:"top_keywords" yaml:"top_keywords"` // urls.top_keywords
+	Wordcounts  KeywordCleanup `json:"wordcounts" yaml:"wordcounts"`     // Per-URL wordcount.txt files
+}
+
+// KeywordCleanup counts what normalizing did to one keyword store.
+type KeywordCleanup struct {
+	Entries   int `json:"entries" yaml:"entries"`     // Entries read
+	Rewritten int `json:"rewritten" yaml:"rewritten"` // Spelling changed: case, quotes, entities, possessive, plural
+	Merged    int `json:"merged" yaml:"merged"`       // Folded into another entry once rewritten
+	Removed   int `json:"removed" yaml:"removed"`     // Dropped: stopwords, entity residue, nothing left after cleaning
+}
+
+// changed reports whether the store needs rewriting.
+func (c KeywordCleanup) changed() bool {
+	return c.Rewritten+c.Merged+c.Removed > 0
+}
+
+func handleNormalize(req models.Request) models.Response {
+	if req.Session == 0 {
+		return normalizeError("missing_session", "normalize rewrites one session's keywords and needs --session",
+			"Use 'lwp corpus normalize --session=1'")
+	}
+
+	var opts NormalizeOptions
+	if stem, ok := req.Constraints["stem"].(bool); ok {
+		opts.Stem = stem
+	}
+	if dryRun, ok := req.Constraints["dry_run"].(bool); ok {
+		opts.DryRun = dryRun
+	}
+	if stopwords, ok := req.Constraints["stopwords"].(string); ok && stopwords != "" {
+		opts.Stopwords = strings.Split(stopwords, ",")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return normalizeError("database_error", fmt.Sprintf("Failed to open database: %v", err),
+			"Ensure database is initialized", "Run 'llm-web-parser db init' if needed")
+	}
+	defer db.Close()
+
+	if _, err := db.GetSessionByID(int64(req.Session)); err != nil {
+		return normalizeError("session_not_found", err.Error(),
+			"List sessions with 'lwp db sessions'")
+	}
+
+	report, err := NormalizeKeywords(db, artifact_manager.NewFileStore(artifact_manager.DefaultBaseDir), int64(req.Session), opts)
+	if err != nil {
+		return normalizeError("normalize_error", err.Error())
+	}
+	return models.Response{
+		Verb:       VerbNORMALIZE,
+		Data:       report,
+		Confidence: 1.0,
+		Coverage:   1.0,
+		Unknowns:   []string{},
+	}
+}
+
+// NormalizeKeywords cleans the stored keywords of every URL in the session, in both
+// stores: urls.top_keywords and the wordcount.txt artifacts in store. Entries that clean
+// to the same keyword are merged by summing their counts; stopwords and entity residue
+// are removed, using the stopwords of the language stored with each URL's parsed page
+// (English when it has none). Only stores that change are rewritten.
+func NormalizeKeywords(db *dbpkg.DB, store artifact_manager.Store, sessionID int64, opts NormalizeOptions) (*NormalizeReport, error) {
+	urls, err := db.GetSessionURLs(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	pages := artifact_manager.NewManagerWithStore(store, "", 0)
+	stopwordsByLang := make(map[string]map[string]struct{})
+	report := &NormalizeReport{SessionID: sessionID, DryRun: opts.DryRun, Stemmed: opts.Stem, URLs: len(urls)}
+
+	for _, u := range urls {
+		changed := false
+
+		// Fetch counted the page's keywords without the stopwords of its language
+		lang := "en"
+		if page, found, err := pages.GetParsedPageByID(u.URLID); err == nil && found && page.Metadata.Language != "" {
+			lang = page.Metadata.Language
+		}
+		stopwords, ok := stopwordsByLang[lang]
+		if !ok {
+			stopwords = analytics.StopwordsFor(lang, opts.Stopwords...)
+			stopwordsByLang[lang] = stopwords
+		}
+
+		info, err := db.GetURLContentInfo(u.URLID)
+		if err != nil {
+			return nil, err
+		}
+		if info.TopKeywords.Valid && info.TopKeywords.String != "" {
+			var stored []string
+			if err := json.Unmarshal([]byte(info.TopKeywords.String), &stored); err != nil {
+				return nil, fmt.Errorf("URL %d has malformed top_keywords: %w", u.URLID, err)
+			}
+			entries := make([]keywordEntry, 0, len(stored))
+			for _, s := range stored {
+				entries = append(entries, parseKeywordEntry(s))
+			}
+			cleaned, cleanup := normalizeEntries(entries, stopwords, opts.Stem)
+			report.TopKeywords.add(cleanup)
+			if cleanup.changed() {
+				changed = true
+				if !opts.DryRun {
+					if err := db.UpdateTopKeywords(u.URLID, formatTopKeywords(cleaned)); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		key := artifact_manager.URLArtifactKey(u.URLID, "wordcount.txt")
+		data, err := store.Get(key)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read wordcount for URL %d: %w", u.URLID, err)
+		default:
+			var entries []keywordEntry
+			for _, line := range strings.Split(string(data), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					entries = append(entries, parseKeywordEntry(line))
+				}
+			}
+			cleaned, cleanup := normalizeEntries(entries, stopwords, opts.Stem)
+			report.Wordcounts.add(cleanup)
+			if cleanup.changed() {
+				changed = true
+				if !opts.DryRun {
+					if err := store.Put(key, []byte(formatWordcounts(cleaned))); err != nil {
+						return nil, fmt.Errorf("failed to rewrite wordcount for URL %d: %w", u.URLID, err)
+					}
+				}
+			}
+		}
+
+		if changed {
+			report.URLsChanged++
+		}
+	}
+	return report, nil
+}
+
+// keywordEntry is one stored count: "word:count", with wordcount.txt's optional
+// ":Display" spelling.
+type keywordEntry struct {
+	word    string
+	count   int
+	display string
+}
+
+// parseKeywordEntry reads "word:count[:Display]". An entry without a usable count
+// keeps count 0, so normalizing removes it.
+func parseKeywordEntry(s string) keywordEntry {
+	parts := strings.SplitN(s, ":", 3)
+	entry := keywordEntry{word: parts[0]}
+	if len(parts) > 1 {
+		entry.count, _ = strconv.Atoi(parts[1])
+	}
+	if len(parts) > 2 {
+		entry.display = parts[2]
+	}
+	return entry
+}
+
+// normalizeEntries cleans each entry's word (analytics.NormalizeKeyword, then Stem when
+// stem is set) and sums the counts of entries that end up the same, highest count first.
+// A merged keyword keeps the display spelling behind most of its count.
+func normalizeEntries(entries []keywordEntry, stopwords map[string]struct{}, stem bool) ([]keywordEntry, KeywordCleanup) {
+	cleanup := KeywordCleanup{Entries: len(entries)}
+	counts := make(map[string]int)
+	forms := make(map[string]map[string]int)
+	for _, e := range entries {
+		word, ok := analytics.NormalizeKeyword(e.word, stopwords)
+		if !ok || e.count <= 0 {
+			cleanup.Removed++
+			continue
+		}
+		if stem {
+			word = analytics.Stem(word)
+		}
+		if word != e.word {
+			cleanup.Rewritten++
+		}
+		counts[word] += e.count
+
+		display := e.display
+		if display == "" {
+			display = e.word
+		}
+		if strings.ToLower(display) != word {
+			display = word // The cleaned word no longer matches the stored spelling
+		}
+		if forms[word] == nil {
+			forms[word] = make(map[string]int)
+		}
+		forms[word][display] += e.count
+	}
+	cleanup.Merged = len(entries) - cleanup.Removed - len(counts)
+
+	cleaned := make([]keywordEntry, 0, len(counts))
+	for _, wc := range mapreduce.Rank(counts, 0) {
+		entry := keywordEntry{word: wc.Word, count: wc.Count}
+		if display := analytics.DominantForm(wc.Word, forms[wc.Word]); display != wc.Word {
+			entry.display = display
+		}
+		cleaned = append(cleaned, entry)
+	}
+	return cleaned, cleanup
+}
+
+// formatTopKeywords renders entries as the urls.top_keywords JSON array.
+func formatTopKeywords(entries []keywordEntry) string {
+	keywords := make([]string, len(entries))
+	for i, e := range entries {
+		keywords[i] = fmt.Sprintf("%s:%d", e.word, e.count)
+	}
+	data, _ := json.Marshal(keywords) // Marshaling a []string cannot fail
+	return string(data)
+}
+
+// formatWordcounts renders entries in wordcount.txt's "word:count[:Display]" lines.
+func formatWordcounts(entries []keywordEntry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		if e.display != "" {
+			fmt.Fprintf(&sb, "%s:%d:%s\n", e.word, e.count, e.display)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%d\n", e.word, e.count)
+	}
+	return sb.String()
+}
+
+func (c *KeywordCleanup) add(other KeywordCleanup) {
+	c.Entries += other.Entries
+	c.Rewritten += other.Rewritten
+	c.Merged += other.Merged
+	c.Removed += other.Removed
+}
+
+func normalizeError(errorType, message string, actions ...string) models.Response {
+	return models.Response{
+		Verb:       VerbNORMALIZE,
+		Data:       nil,
+		Confidence: 0.0,
+		Coverage:   0.0,
+		Unknowns:   []string{},
+		Error: &models.ErrorInfo{
+			Type:             errorType,
+			Message:          message,
+			SuggestedActions: actions,
+		},
+	}
+}
@@ -0,0 +1,142 @@
+package corpus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNormalizeKeywords(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://go.dev/doc/", "https://go.dev/ref/"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	dirtyID, err := database.InsertURL(urls[0])
+	if err != nil {
+		t.Fatalf("InsertURL() error = %v", err)
+	}
+	cleanID, err := database.InsertURL(urls[1])
+	if err != nil {
+		t.Fatalf("InsertURL() error = %v", err)
+	}
+
+	// Legacy data: case and quote variants, a stopword, entity residue, plurals
+	dirty := `["Go:7","go:3","Go’s:2","the:9","amp:4","libraries:2","library:1"]`
+	if err := database.UpdateTopKeywords(dirtyID, dirty); err != nil {
+		t.Fatalf("UpdateTopKeywords() error = %v", err)
+	}
+	if err := database.UpdateTopKeywords(cleanID, `["api:3"]`); err != nil {
+		t.Fatalf("UpdateTopKeywords() error = %v", err)
+	}
+	store := artifact_manager.NewFileStore(t.TempDir())
+	wordcountKey := artifact_manager.URLArtifactKey(dirtyID, "wordcount.txt")
+	if err := store.Put(wordcountKey, []byte("api:5:API\nAPI:4\n&amp;:2\nnbsp:1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run reports without writing
+	report, err := NormalizeKeywords(database, store, sessionID, NormalizeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("NormalizeKeywords(dry run) error = %v", err)
+	}
+	if info, _ := database.GetURLContentInfo(dirtyID); info.TopKeywords.String != dirty {
+		t.Errorf("dry run rewrote top_keywords to %s", info.TopKeywords.String)
+	}
+
+	report, err = NormalizeKeywords(database, store, sessionID, NormalizeOptions{Stem: true})
+	if err != nil {
+		t.Fatalf("NormalizeKeywords() error = %v", err)
+	}
+	want := NormalizeReport{
+		SessionID:   sessionID,
+		Stemmed:     true,
+		URLs:        2,
+		URLsChanged: 1,
+		TopKeywords: KeywordCleanup{Entries: 8, Rewritten: 3, Merged: 3, Removed: 2},
+		Wordcounts:  KeywordCleanup{Entries: 4, Rewritten: 1, Merged: 1, Removed: 2},
+	}
+	if !reflect.DeepEqual(*report, want) {
+		t.Errorf("report = %+v\nwant %+v", *report, want)
+	}
+
+	info, err := database.GetURLContentInfo(dirtyID)
+	if err != nil {
+		t.Fatalf("GetURLContentInfo() error = %v", err)
+	}
+	if got := info.TopKeywords.String; got != `["go:12","library:3"]` {
+		t.Errorf("top_keywords = %s, want go and library merged", got)
+	}
+	data, err := store.Get(wordcountKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "api:9:API\n" {
+		t.Errorf("wordcount.txt = %q, want the API variants merged", got)
+	}
+
+	// Normalizing clean data changes nothing
+	report, err = NormalizeKeywords(database, store, sessionID, NormalizeOptions{Stem: true})
+	if err != nil {
+		t.Fatalf("NormalizeKeywords(again) error = %v", err)
+	}
+	if report.URLsChanged != 0 {
+		t.Errorf("second pass changed %d URLs, want 0", report.URLsChanged)
+	}
+}
+
+func TestNormalizeKeywords_PageLanguage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://example.de/haus"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	urlID, err := database.InsertURL(urls[0])
+	if err != nil {
+		t.Fatalf("InsertURL() error = %v", err)
+	}
+	if err := database.UpdateTopKeywords(urlID, `["und:5","haus:3","the:2"]`); err != nil {
+		t.Fatalf("UpdateTopKeywords() error = %v", err)
+	}
+
+	page := models.Page{URL: urls[0]}
+	page.Metadata.Language = "de"
+	data, err := yaml.Marshal(&page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := artifact_manager.NewFileStore(t.TempDir())
+	if err := store.Put(artifact_manager.URLArtifactKey(urlID, "generic.yaml"), data); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NormalizeKeywords(database, store, sessionID, NormalizeOptions{}); err != nil {
+		t.Fatalf("NormalizeKeywords() error = %v", err)
+	}
+	info, err := database.GetURLContentInfo(urlID)
+	if err != nil {
+		t.Fatalf("GetURLContentInfo() error = %v", err)
+	}
+	// German stopwords go; an English one is an ordinary word on a German page
+	if got := info.TopKeywords.String; got != `["haus:3","the:2"]` {
+		t.Errorf("top_keywords = %s, want German stopwords removed", got)
+	}
+}
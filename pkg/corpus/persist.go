@@ -0,0 +1,371 @@
+package corpus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+	"github.com/dtnitsch/llm-web-parser/pkg/extractors"
+	"github.com/dtnitsch/llm-web-parser/pkg/mapreduce"
+	"github.com/dtnitsch/llm-web-parser/pkg/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// --parsed-format values.
+const (
+	ParsedFormatYAML = "yaml"
+	ParsedFormatJSON = "json"
+	ParsedFormatBoth = "both"
+)
+
+// Provenance keys recorded in artifact_metadata for each parsed page artifact.
+const (
+	ProvenanceParserVersion = "parser_version"
+	ProvenanceParseMode     = "parse_mode"
+	ProvenanceParsedAt      = "parsed_at"
+)
+
+// parsedArtifact is one on-disk encoding of a parsed page.
+type parsedArtifact struct {
+	format   string // ParsedFormatYAML or ParsedFormatJSON
+	fileName string
+	typeName string // artifact_types.type_name
+}
+
+// parsedArtifacts lists the encodings in the order they are written; the first one
+// stored becomes the page's file path.
+var parsedArtifacts = []parsedArtifact{
+	{ParsedFormatYAML, "generic.yaml", "yaml_parsed"},
+	{ParsedFormatJSON, "generic.json", "json_parsed"},
+}
+
+// writesParsedFormat reports whether --parsed-format selected stores the given encoding.
+func writesParsedFormat(selected, format string) bool {
+	switch selected {
+	case ParsedFormatBoth:
+		return true
+	case "":
+		return format == ParsedFormatYAML
+	default:
+		return selected == format
+	}
+}
+
+// StoredPage is a parsed page and what was counted from it, as StorePage stores it.
+type StoredPage struct {
+	URLID         int64
+	Page          *models.Page
+	YAML          []byte                      // Page marshalled for generic.yaml
+	ParsedFormat  string                      // Encodings to store; empty means ParsedFormatYAML
+	WordCounts    map[string]int              // For wordcount.txt and urls.top_keywords
+	DisplayForms  map[string]string           // Original spellings of counted words
+	StoreKeywords int                         // Keywords kept in urls.top_keywords; <= 0 keeps all
+	Links         *extractors.LinksExtraction // Written to links.yaml when it has any
+	Provenance    map[string]string           // Recorded besides parser version, mode and time
+}
+
+// StoreResult is where StorePage put a page.
+type StoreResult struct {
+	FilePath    string // First parsed artifact stored, e.g. lwp-results/42/generic.yaml
+	SizeBytes   int64  // Its size
+	DuplicateOf int64  // Earlier URL with the same declared canonical, or 0
+}
+
+// StorePage writes a parsed page's artifacts under its URL ID and records them in db,
+// the way fetch and INGEST both store pages: the parsed page in the selected formats
+// with its provenance, wordcount.txt, links.yaml, images.yaml and the specialized
+// extractions, plus the URL's content type, keywords, declared canonical and
+// metadata.yaml under baseDir. A failed step doesn't stop the rest; every failure is
+// returned, joined.
+func StorePage(db *dbpkg.DB, manager *artifact_manager.Manager, baseDir string, p StoredPage) (StoreResult, error) {
+	var result StoreResult
+	var errs []error
+	page := p.Page
+
+	for _, artifact := range parsedArtifacts {
+		if !writesParsedFormat(p.ParsedFormat, artifact.format) {
+			// Drop a copy left by an earlier run in another format so readers never see stale content
+			if err := manager.RemoveURLArtifact(p.URLID, artifact.fileName); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove stale %s: %w", artifact.fileName, err))
+			}
+			if err := db.DeleteArtifact(p.URLID, artifact.typeName); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove stale %s artifact: %w", artifact.typeName, err))
+			}
+			continue
+		}
+
+		data := p.YAML
+		var err error
+		if artifact.format == ParsedFormatJSON {
+			if data, err = json.MarshalIndent(page, "", "  "); err != nil {
+				errs = append(errs, fmt.Errorf("failed to marshal parsed JSON: %w", err))
+				continue
+			}
+			err = manager.SetGenericJSONByID(p.URLID, data)
+		} else {
+			err = manager.SetParsedYAMLByID(p.URLID, data)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to store %s: %w", artifact.fileName, err))
+			continue
+		}
+
+		path := artifact_manager.GetURLArtifactPath("", p.URLID, artifact.fileName)
+		if result.FilePath == "" {
+			result.FilePath, result.SizeBytes = path, int64(len(data))
+		}
+		artifactID, err := insertArtifact(db, p.URLID, artifact.typeName, path, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := recordProvenance(db, artifactID, page, p.Provenance); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	wordcounts := FormatWordCounts(p.WordCounts, p.DisplayForms)
+	if err := manager.SetURLArtifact(p.URLID, "wordcount.txt", []byte(wordcounts)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to write wordcount.txt: %w", err))
+	}
+
+	if err := db.UpdateURLContentType(p.URLID, contentTypeInfo(page, p.WordCounts, p.StoreKeywords)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update content type metadata: %w", err))
+	}
+	// Prefer the page's declared canonical over the derived scheme+host+path form
+	if page.Metadata.CanonicalURL != "" {
+		duplicateOf, err := db.SetDeclaredCanonical(p.URLID, page.Metadata.CanonicalURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to store canonical URL: %w", err))
+		}
+		result.DuplicateOf = duplicateOf
+	}
+	if err := WriteMetadataFile(db, p.URLID, baseDir); err != nil {
+		errs = append(errs, fmt.Errorf("failed to write metadata file: %w", err))
+	}
+
+	if p.Links != nil && p.Links.Total > 0 {
+		if err := storeExtraction(db, manager, p.URLID, p.Links, "links.yaml", "links"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if images := extractors.ExtractImages(page); images != nil {
+		if err := storeExtraction(db, manager, p.URLID, images, "images.yaml", "images"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, e := range SpecializedExtractions(page) {
+		if err := storeExtraction(db, manager, p.URLID, e.Extraction, e.FileName, ""); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// contentTypeInfo is what urls records about a stored page.
+func contentTypeInfo(page *models.Page, counts map[string]int, storeKeywords int) dbpkg.ContentTypeInfo {
+	info := dbpkg.ContentTypeInfo{
+		ContentType:         dbpkg.NewNullString(page.Metadata.ContentType),
+		ContentSubtype:      dbpkg.NewNullString(page.Metadata.ContentSubtype),
+		DetectionConfidence: dbpkg.NewNullFloat64(page.Metadata.Confidence),
+		HasAbstract:         page.Metadata.HasAbstract,
+		HasInfobox:          page.Metadata.HasInfobox,
+		HasTOC:              page.Metadata.HasTOC,
+		HasCodeExamples:     page.Metadata.HasCodeExamples,
+		ContentDate:         dbpkg.NewNullString(page.Metadata.ContentDate),
+		ContentDateKind:     dbpkg.NewNullString(page.Metadata.ContentDateKind),
+		SectionCount:        page.Metadata.SectionCount,
+		CitationCount:       page.Metadata.CitationCount,
+		CodeBlockCount:      page.Metadata.CodeBlockCount,
+		TopKeywords:         dbpkg.NewNullString(formatKeywordsAsJSON(counts, storeKeywords)),
+		MetaKeywords:        dbpkg.NewNullString(formatMetaKeywordsAsJSON(page.Metadata.MetaKeywords)),
+	}
+	if robots := page.Metadata.Robots; robots != nil {
+		info.NoIndex, info.NoFollow = robots.NoIndex, robots.NoFollow
+	}
+	return info
+}
+
+// recordProvenance notes which parser version and mode produced a stored generic.yaml/json,
+// and when, along with extra. parse_mode is the mode actually used, after any escalation.
+func recordProvenance(db *dbpkg.DB, artifactID int64, page *models.Page, extra map[string]string) error {
+	values := map[string]string{
+		ProvenanceParserVersion: parser.Version,
+		ProvenanceParseMode:     page.Metadata.ExtractionMode,
+		ProvenanceParsedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	for k, v := range extra {
+		values[k] = v
+	}
+	for k, v := range values {
+		if err := db.SetArtifactMetadata(artifactID, k, v); err != nil {
+			return fmt.Errorf("failed to record parse provenance %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// storeExtraction writes an extraction to {url_id}/{fileName} and, when typeName is
+// set, records it in the artifacts table under that type.
+func storeExtraction(db *dbpkg.DB, manager *artifact_manager.Manager, urlID int64, extraction interface{}, fileName, typeName string) error {
+	data, err := yaml.Marshal(extraction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", fileName, err)
+	}
+	if err := manager.SetURLArtifact(urlID, fileName, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	if typeName == "" {
+		return nil
+	}
+	_, err = insertArtifact(db, urlID, typeName, artifact_manager.GetURLArtifactPath("", urlID, fileName), data)
+	return err
+}
+
+// insertArtifact records data stored at path as an artifact of typeName.
+func insertArtifact(db *dbpkg.DB, urlID int64, typeName, path string, data []byte) (int64, error) {
+	typeID, err := db.GetArtifactTypeID(typeName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s artifact type: %w", typeName, err)
+	}
+	artifactID, err := db.InsertArtifact(urlID, typeID, artifact_manager.ContentHash(data), path, int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert %s artifact: %w", typeName, err)
+	}
+	return artifactID, nil
+}
+
+// formatKeywordsAsJSON formats word counts as JSON array for database storage.
+// Uses existing mapreduce.TopKeywords() to get top N keywords; limit <= 0 keeps all of them.
+func formatKeywordsAsJSON(counts map[string]int, limit int) string {
+	if limit <= 0 {
+		limit = len(counts)
+	}
+	keywords := mapreduce.TopKeywords(counts, limit)
+	jsonBytes, err := json.Marshal(keywords)
+	if err != nil {
+		return ""
+	}
+	return string(jsonBytes)
+}
+
+// formatMetaKeywordsAsJSON formats meta keywords as JSON array for database storage.
+func formatMetaKeywordsAsJSON(keywords []string) string {
+	if len(keywords) == 0 {
+		return ""
+	}
+	jsonBytes, err := json.Marshal(keywords)
+	if err != nil {
+		return ""
+	}
+	return string(jsonBytes)
+}
+
+// FormatWordCounts formats word counts as wordcount.txt's sorted plain text.
+// Format: "word:count\n" sorted by count descending, ties alphabetical, for easy parsing.
+// Words with a display form other than their lowercase one get it as a third field,
+// e.g. "api:12:API".
+func FormatWordCounts(counts map[string]int, display map[string]string) string {
+	var sb strings.Builder
+	for _, item := range mapreduce.Rank(counts, 0) {
+		if form, ok := display[item.Word]; ok {
+			fmt.Fprintf(&sb, "%s:%d:%s\n", item.Word, item.Count, form)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:%d\n", item.Word, item.Count)
+	}
+	return sb.String()
+}
+
+// specializedExtractor produces one content-type-specific artifact from a fully parsed page.
+type specializedExtractor struct {
+	fileName string
+	applies  func(page *models.Page) bool
+	extract  func(page *models.Page) interface{} // nil when the page has nothing to extract
+}
+
+// specializedExtractors is the registry of extractors run after parsing, in order.
+// A page may match several, e.g. a reference docs page gets docs.yaml and glossary.yaml.
+var specializedExtractors = []specializedExtractor{
+	{
+		fileName: "academic.yaml",
+		applies: func(page *models.Page) bool {
+			return page.Metadata.ContentType == "academic" || page.Metadata.Publication != nil
+		},
+		extract: func(page *models.Page) interface{} {
+			if e := extractors.ExtractAcademic(page); e != nil {
+				return e
+			}
+			return nil
+		},
+	},
+	{
+		fileName: "docs.yaml",
+		applies:  contentTypeIs("docs"),
+		extract: func(page *models.Page) interface{} {
+			if e := extractors.ExtractDocs(page); e != nil {
+				return e
+			}
+			return nil
+		},
+	},
+	{
+		fileName: "wiki.yaml",
+		applies:  contentTypeIs("wiki"),
+		extract: func(page *models.Page) interface{} {
+			if e := extractors.ExtractWiki(page); e != nil {
+				return e
+			}
+			return nil
+		},
+	},
+	{
+		fileName: "glossary.yaml",
+		applies:  extractors.IsGlossaryPage,
+		extract: func(page *models.Page) interface{} {
+			if e := extractors.ExtractGlossary(page); e != nil {
+				return e
+			}
+			return nil
+		},
+	},
+	{
+		fileName: "social.yaml",
+		applies:  func(page *models.Page) bool { return page.Metadata.Social != nil },
+		extract:  func(page *models.Page) interface{} { return page.Metadata.Social },
+	},
+}
+
+func contentTypeIs(contentType string) func(*models.Page) bool {
+	return func(page *models.Page) bool { return page.Metadata.ContentType == contentType }
+}
+
+// SpecializedExtraction is one extractor's output and the file it is saved under.
+type SpecializedExtraction struct {
+	FileName   string
+	Extraction interface{}
+}
+
+// SpecializedExtractions runs every registered extractor that applies to a fully parsed page.
+func SpecializedExtractions(page *models.Page) []SpecializedExtraction {
+	// Only run for full parse mode (skip minimal mode)
+	if page == nil || page.Metadata.ExtractionMode != "full" {
+		return nil
+	}
+
+	var results []SpecializedExtraction
+	for _, ex := range specializedExtractors {
+		if !ex.applies(page) {
+			continue
+		}
+		if extraction := ex.extract(page); extraction != nil {
+			results = append(results, SpecializedExtraction{FileName: ex.fileName, Extraction: extraction})
+		}
+	}
+	return results
+}
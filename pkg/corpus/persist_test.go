@@ -0,0 +1,23 @@
+package corpus
+
+import "testing"
+
+func TestWritesParsedFormat(t *testing.T) {
+	tests := []struct {
+		selected   string
+		yaml, json bool
+	}{
+		{"", true, false},
+		{ParsedFormatYAML, true, false},
+		{ParsedFormatJSON, false, true},
+		{ParsedFormatBoth, true, true},
+	}
+	for _, tt := range tests {
+		if got := writesParsedFormat(tt.selected, ParsedFormatYAML); got != tt.yaml {
+			t.Errorf("writesParsedFormat(%q, yaml) = %v, want %v", tt.selected, got, tt.yaml)
+		}
+		if got := writesParsedFormat(tt.selected, ParsedFormatJSON); got != tt.json {
+			t.Errorf("writesParsedFormat(%q, json) = %v, want %v", tt.selected, got, tt.json)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package corpus
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
 	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
 )
 
@@ -23,19 +26,61 @@ type QueryResult struct {
 	SectionCount        int     `json:"section_count,omitempty"`
 	CitationCount       int     `json:"citation_count,omitempty"`
 	CodeBlockCount      int     `json:"code_block_count,omitempty"`
+	ContentDate         string  `json:"content_date,omitempty"`      // ISO-8601 publication or last-updated date
+	ContentDateKind     string  `json:"content_date_kind,omitempty"` // "published" | "updated"
+
+	Snippets []KeywordSnippet `json:"snippets,omitempty"` // Only with QueryOptions.Snippets
+	Sessions []int64          `json:"sessions,omitempty"` // Sessions the URL appeared in, for corpus-wide queries (session 0)
 }
 
 // QueryResponse is the data returned by QUERY verb.
 type QueryResponse struct {
 	Filter       string        `json:"filter"`
+	Domain       string        `json:"domain,omitempty"`
 	MatchCount   int           `json:"match_count"`
 	TotalCount   int           `json:"total_count"`
 	Matches      []QueryResult `json:"matches"`
+	DomainFacets []FacetCount  `json:"domain_facets,omitempty"`
 	WhereClause  string        `json:"where_clause,omitempty"` // For debugging
+	SQL          *QuerySQL     `json:"sql,omitempty"`          // Only with QueryOptions.ExplainSQL
+}
+
+// QuerySQL is the SQL a QUERY executed, placeholders and bound args included.
+type QuerySQL struct {
+	Query      string        `json:"query" yaml:"query"`
+	Args       []interface{} `json:"args" yaml:"args"`
+	CountQuery string        `json:"count_query" yaml:"count_query"`
+	CountArgs  []interface{} `json:"count_args" yaml:"count_args"`
+}
+
+// FacetCount is the number of matching URLs sharing one facet value.
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// QueryOptions narrows or decorates a QUERY beyond its filter expression.
+type QueryOptions struct {
+	Domain       string // Substring match against the URL's domain
+	FacetDomains bool   // Include per-domain match counts
+	Snippets     bool   // Attach context around keyword: matches (reads each match's generic.yaml)
+	ExplainSQL   bool   // Include the executed SQL and its args in the response
+	SortBy       string // "date": freshest content date first, undated pages last; default is insertion order
+}
+
+// SortByDate orders QUERY matches by content date, newest first.
+const SortByDate = "date"
+
+// dateOrderClause puts undated URLs after every dated one, then falls back to url_id
+// so ties stay stable. table qualifies the columns when the query joins session_urls.
+func dateOrderClause(table string) string {
+	return fmt.Sprintf(" ORDER BY %[1]scontent_date IS NULL, %[1]scontent_date DESC, %[1]surl_id", table)
 }
 
-// ExecuteQuery runs a metadata query against the database.
-func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, error) {
+
+// ExecuteQuery runs a metadata query against the database: the URLs of one session, or
+// with session 0 the whole corpus, each match annotated with the sessions it appeared in.
+func ExecuteQuery(db *dbpkg.DB, filter string, session int, opts QueryOptions) (models.Response, error) {
 	// Parse filter
 	filterResult, err := ParseFilter(filter)
 	if err != nil {
@@ -53,11 +98,22 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 		}, nil
 	}
 
+	// --domain is a substring match, unlike the exact domain= filter
+	if opts.Domain != "" {
+		domainClause := `domain LIKE ? ESCAPE '\'`
+		if filterResult.WhereClause != "1=1" {
+			domainClause = "(" + filterResult.WhereClause + ") AND " + domainClause
+		}
+		filterResult.WhereClause = domainClause
+		filterResult.Args = append(filterResult.Args, "%"+escapeLike(opts.Domain)+"%")
+	}
+
 	// Build query
-	baseQuery := "SELECT url_id, original_url, domain, content_type, content_subtype, detection_confidence, has_abstract, has_infobox, has_toc, has_code_examples, section_count, citation_count, code_block_count FROM urls"
+	baseQuery := "SELECT url_id, original_url, domain, content_type, content_subtype, detection_confidence, has_abstract, has_infobox, has_toc, has_code_examples, section_count, citation_count, code_block_count, content_date, content_date_kind FROM urls"
 
 	var whereClause string
 	var args []interface{}
+	orderClause := dateOrderClause("")
 
 	// Add session filter if specified
 	if session > 0 {
@@ -65,11 +121,12 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 		baseQuery = `
 			SELECT DISTINCT u.url_id, u.original_url, u.domain, u.content_type, u.content_subtype,
 			       u.detection_confidence, u.has_abstract, u.has_infobox, u.has_toc, u.has_code_examples,
-			       u.section_count, u.citation_count, u.code_block_count
+			       u.section_count, u.citation_count, u.code_block_count, u.content_date, u.content_date_kind
 			FROM urls u
 			JOIN session_urls su ON u.url_id = su.url_id
 			WHERE su.session_id = ?`
 		args = append(args, session)
+		orderClause = dateOrderClause("u.")
 
 		if filterResult.WhereClause != "1=1" {
 			whereClause = " AND (" + filterResult.WhereClause + ")"
@@ -81,6 +138,9 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 	}
 
 	query := baseQuery + whereClause
+	if opts.SortBy == SortByDate {
+		query += orderClause
+	}
 
 	// Execute query
 	rows, err := db.Query(query, args...)
@@ -93,7 +153,7 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 	var matches []QueryResult
 	for rows.Next() {
 		var m QueryResult
-		var contentType, contentSubtype sql.NullString
+		var contentType, contentSubtype, contentDate, contentDateKind sql.NullString
 		var detectionConfidence sql.NullFloat64
 
 		err := rows.Scan(
@@ -110,6 +170,8 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 			&m.SectionCount,
 			&m.CitationCount,
 			&m.CodeBlockCount,
+			&contentDate,
+			&contentDateKind,
 		)
 		if err != nil {
 			return models.Response{}, fmt.Errorf("row scan failed: %w", err)
@@ -124,20 +186,36 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 		if detectionConfidence.Valid {
 			m.DetectionConfidence = detectionConfidence.Float64
 		}
+		m.ContentDate, m.ContentDateKind = contentDate.String, contentDateKind.String
 
 		matches = append(matches, m)
 	}
 
+	if session == 0 && len(matches) > 0 {
+		urlSessions, err := db.GetURLSessions()
+		if err != nil {
+			return models.Response{}, err
+		}
+		for i := range matches {
+			matches[i].Sessions = urlSessions[matches[i].URLID]
+		}
+	}
+
+	if opts.Snippets {
+		if keywords := filterKeywords(filter); len(keywords) > 0 {
+			addSnippets(matches, keywords, artifact_manager.DefaultBaseDir)
+		}
+	}
+
 	// Get total count for coverage calculation
 	var totalCount int
 	countQuery := "SELECT COUNT(*) FROM urls"
+	countArgs := []interface{}{}
 	if session > 0 {
 		countQuery = "SELECT COUNT(DISTINCT url_id) FROM session_urls WHERE session_id = ?"
-		err = db.QueryRow(countQuery, session).Scan(&totalCount)
-	} else {
-		err = db.QueryRow(countQuery).Scan(&totalCount)
+		countArgs = append(countArgs, session)
 	}
-	if err != nil {
+	if err := db.QueryRow(countQuery, countArgs...).Scan(&totalCount); err != nil {
 		totalCount = 0 // Non-fatal
 	}
 
@@ -151,11 +229,21 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 	// Build response
 	responseData := QueryResponse{
 		Filter:      filter,
+		Domain:      opts.Domain,
 		MatchCount:  len(matches),
 		TotalCount:  totalCount,
 		Matches:     matches,
 		WhereClause: filterResult.WhereClause, // For debugging
 	}
+	if opts.FacetDomains {
+		responseData.DomainFacets = domainFacets(matches)
+	}
+	if opts.ExplainSQL {
+		if args == nil {
+			args = []interface{}{}
+		}
+		responseData.SQL = &QuerySQL{Query: query, Args: args, CountQuery: countQuery, CountArgs: countArgs}
+	}
 
 	return models.Response{
 		Verb:       VerbQUERY,
@@ -166,6 +254,34 @@ func ExecuteQuery(db *dbpkg.DB, filter string, session int) (models.Response, er
 	}, nil
 }
 
+// likeEscaper escapes LIKE's wildcards and the \ ESCAPE character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes s match itself literally inside a LIKE pattern using ESCAPE '\'.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// domainFacets counts matches per domain, most common first.
+func domainFacets(matches []QueryResult) []FacetCount {
+	counts := make(map[string]int)
+	for _, m := range matches {
+		counts[m.Domain]++
+	}
+
+	facets := make([]FacetCount, 0, len(counts))
+	for domain, count := range counts {
+		facets = append(facets, FacetCount{Value: domain, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
 // calculateConfidence estimates confidence based on filter complexity.
 // Simple filters = high confidence, complex filters = lower confidence.
 func calculateConfidence(whereClause string) float64 {
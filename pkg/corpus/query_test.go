@@ -0,0 +1,225 @@
+package corpus
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestExecuteQuery_ExplainSQL(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://go.dev/doc/", "https://example.com/blog/"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	for i, u := range urls {
+		urlID, err := database.InsertURL(u) // Already linked to the session; returns its ID
+		if err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		contentType := []string{"docs", "blog"}[i]
+		if err := database.UpdateURLContentType(urlID, dbpkg.ContentTypeInfo{ContentType: dbpkg.NewNullString(contentType)}); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	resp, err := ExecuteQuery(database, "content_type=docs", int(sessionID), QueryOptions{ExplainSQL: true})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("ExecuteQuery() error = %v, %+v", err, resp.Error)
+	}
+	data := resp.Data.(QueryResponse)
+	if data.MatchCount != 1 || data.SQL == nil {
+		t.Fatalf("ExecuteQuery() matches = %d, sql = %+v", data.MatchCount, data.SQL)
+	}
+	if !strings.Contains(data.SQL.Query, "JOIN session_urls") || !strings.Contains(data.SQL.Query, data.WhereClause) {
+		t.Errorf("SQL.Query = %q, want the session join with %q", data.SQL.Query, data.WhereClause)
+	}
+	if len(data.SQL.Args) != strings.Count(data.SQL.Query, "?") || data.SQL.Args[0] != int(sessionID) {
+		t.Errorf("SQL.Args = %v for query %q", data.SQL.Args, data.SQL.Query)
+	}
+	if want := []interface{}{int(sessionID)}; !reflect.DeepEqual(data.SQL.CountArgs, want) || !strings.Contains(data.SQL.CountQuery, "session_urls") {
+		t.Errorf("count SQL = %q %v, want session count with args %v", data.SQL.CountQuery, data.SQL.CountArgs, want)
+	}
+
+	// Without the flag the response carries no SQL
+	resp, err = ExecuteQuery(database, "content_type=docs", int(sessionID), QueryOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteQuery() error = %v", err)
+	}
+	if sql := resp.Data.(QueryResponse).SQL; sql != nil {
+		t.Errorf("SQL = %+v without ExplainSQL, want nil", sql)
+	}
+}
+
+func TestExecuteQuery_AllSessions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	first := []string{"https://go.dev/doc/", "https://example.com/blog/"}
+	second := []string{"https://go.dev/doc/", "https://pkg.go.dev/"}
+	firstID, _, err := database.FindOrCreateSession(first, first, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	secondID, _, err := database.FindOrCreateSession(second, second, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	for _, u := range []string{"https://go.dev/doc/", "https://pkg.go.dev/"} {
+		urlID, err := database.InsertURL(u)
+		if err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		if err := database.UpdateURLContentType(urlID, dbpkg.ContentTypeInfo{ContentType: dbpkg.NewNullString("docs")}); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	resp, err := ExecuteQuery(database, "content_type=docs", 0, QueryOptions{})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("ExecuteQuery() error = %v, %+v", err, resp.Error)
+	}
+	sessions := make(map[string][]int64)
+	for _, m := range resp.Data.(QueryResponse).Matches {
+		sessions[m.OriginalURL] = m.Sessions
+	}
+	want := map[string][]int64{
+		"https://go.dev/doc/": {firstID, secondID},
+		"https://pkg.go.dev/": {secondID},
+	}
+	if !reflect.DeepEqual(sessions, want) {
+		t.Errorf("corpus-wide matches = %v, want %v", sessions, want)
+	}
+
+	// Session-scoped results leave the membership out
+	resp, err = ExecuteQuery(database, "content_type=docs", int(firstID), QueryOptions{})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("ExecuteQuery() error = %v, %+v", err, resp.Error)
+	}
+	matches := resp.Data.(QueryResponse).Matches
+	if len(matches) != 1 || matches[0].Sessions != nil {
+		t.Errorf("session matches = %+v, want one match without sessions", matches)
+	}
+}
+
+func TestExecuteQuery_SortByDate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{"https://example.com/undated", "https://example.com/old", "https://example.com/new"}
+	dates := []string{"", "2021-04-01", "2024-09-09T10:00:00Z"}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	for i, u := range urls {
+		urlID, err := database.InsertURL(u)
+		if err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		info := dbpkg.ContentTypeInfo{ContentType: dbpkg.NewNullString("docs"), ContentDate: dbpkg.NewNullString(dates[i])}
+		if dates[i] != "" {
+			info.ContentDateKind = dbpkg.NewNullString("published")
+		}
+		if err := database.UpdateURLContentType(urlID, info); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	for _, session := range []int{int(sessionID), 0} {
+		resp, err := ExecuteQuery(database, "content_type=docs", session, QueryOptions{SortBy: SortByDate})
+		if err != nil || resp.Error != nil {
+			t.Fatalf("ExecuteQuery() error = %v, %+v", err, resp.Error)
+		}
+		var got []string
+		for _, m := range resp.Data.(QueryResponse).Matches {
+			got = append(got, m.ContentDate)
+		}
+		want := []string{"2024-09-09T10:00:00Z", "2021-04-01", ""}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("session %d: content dates = %q, want %q (newest first, undated last)", session, got, want)
+		}
+	}
+}
+
+func TestExecuteQuery_Domain(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{
+		"https://docs.a_b.com/guide",
+		"https://a_b.com/blog",
+		"https://axb.com/post",
+		"https://example.org/about",
+		"https://example.org/contact",
+	}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	for _, u := range urls {
+		if _, err := database.InsertURL(u); err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		domain string
+		want   []string
+	}{
+		{"a_b.com", []string{"a_b.com", "docs.a_b.com"}}, // _ is literal, so axb.com is not a match
+		{"example", []string{"example.org", "example.org"}},
+		{"%", nil}, // % is literal too
+		{"", []string{"a_b.com", "axb.com", "docs.a_b.com", "example.org", "example.org"}},
+	}
+	for _, tt := range tests {
+		resp, err := ExecuteQuery(database, "", int(sessionID), QueryOptions{Domain: tt.domain})
+		if err != nil || resp.Error != nil {
+			t.Fatalf("ExecuteQuery(domain %q) error = %v, %+v", tt.domain, err, resp.Error)
+		}
+		var got []string
+		for _, m := range resp.Data.(QueryResponse).Matches {
+			got = append(got, m.Domain)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExecuteQuery(domain %q) domains = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+
+	// Facets count every match per domain, most common first
+	resp, err := ExecuteQuery(database, "", int(sessionID), QueryOptions{FacetDomains: true})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("ExecuteQuery(FacetDomains) error = %v, %+v", err, resp.Error)
+	}
+	want := []FacetCount{{"example.org", 2}, {"a_b.com", 1}, {"axb.com", 1}, {"docs.a_b.com", 1}}
+	if got := resp.Data.(QueryResponse).DomainFacets; !reflect.DeepEqual(got, want) {
+		t.Errorf("DomainFacets = %v, want %v", got, want)
+	}
+	resp, _ = ExecuteQuery(database, "", int(sessionID), QueryOptions{})
+	if got := resp.Data.(QueryResponse).DomainFacets; got != nil {
+		t.Errorf("DomainFacets = %v without FacetDomains, want nil", got)
+	}
+}
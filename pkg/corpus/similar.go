@@ -0,0 +1,166 @@
+package corpus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+const (
+	// similarContentTypeWeight is the part of a score earned by sharing the query URL's
+	// content type; the rest is keyword cosine similarity.
+	similarContentTypeWeight = 0.2
+	// similarSharedKeywords caps the shared keywords listed per match.
+	similarSharedKeywords = 5
+)
+
+// SimilarResult is the URLs of a session most like one of its URLs.
+type SimilarResult struct {
+	SessionID   int64        `json:"session_id" yaml:"session_id"`
+	URLID       int64        `json:"url_id" yaml:"url_id"`
+	URL         string       `json:"url" yaml:"url"`
+	ContentType string       `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	Similar     []SimilarURL `json:"similar" yaml:"similar"` // Most similar first
+}
+
+// SimilarURL is one match and why it matched.
+type SimilarURL struct {
+	URLID           int64    `json:"url_id" yaml:"url_id"`
+	URL             string   `json:"url" yaml:"url"`
+	ContentType     string   `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+	Score           float64  `json:"score" yaml:"score"`                                         // 0-1: keyword similarity plus the content type bonus
+	KeywordScore    float64  `json:"keyword_similarity" yaml:"keyword_similarity"`               // Cosine of the TF-IDF keyword vectors
+	SameContentType bool     `json:"same_content_type" yaml:"same_content_type"`                 // Both have the same known content type
+	SharedKeywords  []string `json:"shared_keywords,omitempty" yaml:"shared_keywords,omitempty"` // Heaviest shared keywords first
+}
+
+// FindSimilar ranks the other URLs of the session by similarity to urlID and returns the
+// top ones (all when top is 0). Similarity is the cosine of the URLs' stored keyword
+// vectors weighted by TF-IDF, blended with whether their content types match. IDF is
+// smoothed (1 + log(N/df)) so keywords every URL shares still count a little. URLs with
+// nothing in common with urlID are left out.
+func FindSimilar(db *dbpkg.DB, sessionID, urlID int64, top int) (*SimilarResult, error) {
+	urls, err := db.GetSessionURLsWithMetadata(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	result := &SimilarResult{SessionID: sessionID, URLID: urlID, Similar: []SimilarURL{}}
+	found := false
+	for _, u := range urls {
+		if u.URLID == urlID {
+			result.URL, result.ContentType = u.URL, u.ContentType
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("URL %d is not in session %d", urlID, sessionID)
+	}
+
+	entries, err := ExportKeywords(db, "", sessionID)
+	if err != nil {
+		return nil, err
+	}
+	vectors := tfidfVectors(entries)
+	query := vectors[urlID]
+	if len(query) == 0 {
+		return nil, fmt.Errorf("URL %d has no stored keywords to compare (fetch it with --features wordcount or full-parse)", urlID)
+	}
+
+	knownType := result.ContentType != "" && result.ContentType != "unknown"
+	for _, u := range urls {
+		if u.URLID == urlID {
+			continue
+		}
+		match := SimilarURL{
+			URLID:           u.URLID,
+			URL:             u.URL,
+			ContentType:     u.ContentType,
+			SameContentType: knownType && u.ContentType == result.ContentType,
+		}
+		cosine, shared := cosineSimilarity(query, vectors[u.URLID])
+		match.KeywordScore = math.Round(cosine*1000) / 1000
+		match.SharedKeywords = shared
+		score := (1 - similarContentTypeWeight) * cosine
+		if match.SameContentType {
+			score += similarContentTypeWeight
+		}
+		if score == 0 {
+			continue
+		}
+		match.Score = math.Round(score*1000) / 1000
+		result.Similar = append(result.Similar, match)
+	}
+
+	sort.SliceStable(result.Similar, func(i, j int) bool {
+		if result.Similar[i].Score != result.Similar[j].Score {
+			return result.Similar[i].Score > result.Similar[j].Score
+		}
+		return result.Similar[i].URLID < result.Similar[j].URLID
+	})
+	if top > 0 && len(result.Similar) > top {
+		result.Similar = result.Similar[:top]
+	}
+	return result, nil
+}
+
+// tfidfVectors weighs each URL's stored keywords by their share of its counts times the
+// smoothed IDF of the keyword within the session.
+func tfidfVectors(entries []KeywordEntry) map[int64]map[string]float64 {
+	totals := make(map[int64]int)
+	docFreq := make(map[string]int)
+	for _, e := range entries {
+		totals[e.URLID] += e.Count
+		docFreq[e.Keyword]++
+	}
+
+	docs := float64(len(totals))
+	vectors := make(map[int64]map[string]float64, len(totals))
+	for _, e := range entries {
+		if totals[e.URLID] == 0 || e.Count <= 0 {
+			continue
+		}
+		if vectors[e.URLID] == nil {
+			vectors[e.URLID] = make(map[string]float64)
+		}
+		tf := float64(e.Count) / float64(totals[e.URLID])
+		vectors[e.URLID][e.Keyword] = tf * (1 + math.Log(docs/float64(docFreq[e.Keyword])))
+	}
+	return vectors
+}
+
+// cosineSimilarity returns the cosine of a and b and their shared keywords, the ones
+// contributing most to it first.
+func cosineSimilarity(a, b map[string]float64) (float64, []string) {
+	var dot, normA, normB float64
+	contributions := make(map[string]float64)
+	for word, wa := range a {
+		normA += wa * wa
+		if wb, ok := b[word]; ok {
+			dot += wa * wb
+			contributions[word] = wa * wb
+		}
+	}
+	for _, wb := range b {
+		normB += wb * wb
+	}
+	if dot == 0 || normA == 0 || normB == 0 {
+		return 0, nil
+	}
+
+	shared := make([]string, 0, len(contributions))
+	for word := range contributions {
+		shared = append(shared, word)
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if contributions[shared[i]] != contributions[shared[j]] {
+			return contributions[shared[i]] > contributions[shared[j]]
+		}
+		return shared[i] < shared[j]
+	})
+	if len(shared) > similarSharedKeywords {
+		shared = shared[:similarSharedKeywords]
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), shared
+}
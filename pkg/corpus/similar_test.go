@@ -0,0 +1,94 @@
+package corpus
+
+import (
+	"reflect"
+	"testing"
+
+	dbpkg "github.com/dtnitsch/llm-web-parser/pkg/db"
+)
+
+func TestFindSimilar(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := dbpkg.Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer database.Close()
+
+	urls := []string{
+		"https://go.dev/doc/tutorial",
+		"https://go.dev/doc/modules",
+		"https://example.com/blog/go",
+		"https://example.com/recipes",
+		"https://example.com/about",
+	}
+	sessionID, _, err := database.FindOrCreateSession(urls, urls, "wordcount", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	pages := []struct {
+		contentType string
+		keywords    string
+	}{
+		{"docs", `["go:10","module:6","install:4"]`},
+		{"docs", `["module:8","go:6","version:3"]`},
+		{"blog", `["go:3","concurrency:9"]`},
+		{"blog", `["flour:7","butter:5"]`},
+		{"landing", ``},
+	}
+	ids := make([]int64, len(urls))
+	for i, u := range urls {
+		if ids[i], err = database.InsertURL(u); err != nil {
+			t.Fatalf("InsertURL() error = %v", err)
+		}
+		info := dbpkg.ContentTypeInfo{
+			ContentType: dbpkg.NewNullString(pages[i].contentType),
+			TopKeywords: dbpkg.NewNullString(pages[i].keywords),
+		}
+		if err := database.UpdateURLContentType(ids[i], info); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	result, err := FindSimilar(database, sessionID, ids[0], 0)
+	if err != nil {
+		t.Fatalf("FindSimilar() error = %v", err)
+	}
+	if result.URL != urls[0] || result.ContentType != "docs" {
+		t.Errorf("query = %s (%s), want %s (docs)", result.URL, result.ContentType, urls[0])
+	}
+
+	// The other docs page shares the most and the type; the recipes and about pages share nothing
+	var got []int64
+	for _, m := range result.Similar {
+		got = append(got, m.URLID)
+	}
+	if want := []int64{ids[1], ids[2]}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("similar URLs = %v, want %v", got, want)
+	}
+
+	best := result.Similar[0]
+	if !best.SameContentType || best.Score <= best.KeywordScore*0.8 {
+		t.Errorf("best match = %+v, want the content type bonus on top of keyword similarity", best)
+	}
+	// "module" outweighs the more common "go", which the blog page uses too
+	if want := []string{"module", "go"}; !reflect.DeepEqual(best.SharedKeywords, want) {
+		t.Errorf("shared keywords = %v, want %v", best.SharedKeywords, want)
+	}
+	if blog := result.Similar[1]; blog.SameContentType || blog.KeywordScore <= 0 || blog.KeywordScore >= best.KeywordScore {
+		t.Errorf("blog match = %+v, want a weaker keyword-only match", blog)
+	}
+
+	// --top cuts the list
+	if result, err = FindSimilar(database, sessionID, ids[0], 1); err != nil || len(result.Similar) != 1 {
+		t.Errorf("FindSimilar(top=1) = %+v, %v; want one match", result, err)
+	}
+
+	// A URL without keywords or outside the session can't be compared
+	if _, err := FindSimilar(database, sessionID, ids[4], 0); err == nil {
+		t.Error("FindSimilar() for a URL without keywords succeeded, want error")
+	}
+	if _, err := FindSimilar(database, sessionID, ids[4]+100, 0); err == nil {
+		t.Error("FindSimilar() for a URL outside the session succeeded, want error")
+	}
+}
@@ -0,0 +1,100 @@
+package corpus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+)
+
+// maxSnippetRunes caps a snippet's length, not counting the ellipses marking truncation.
+const maxSnippetRunes = 160
+
+// KeywordSnippet shows a matched keyword in context.
+type KeywordSnippet struct {
+	Keyword string `json:"keyword"`
+	Text    string `json:"text"`
+}
+
+var (
+	keywordFilterRe = regexp.MustCompile(`(?i)keyword:\s*([^\s()]+)`)
+	whitespaceRe    = regexp.MustCompile(`\s+`)
+)
+
+// filterKeywords returns the keywords named by keyword: terms in a filter, in order.
+func filterKeywords(filter string) []string {
+	var keywords []string
+	for _, m := range keywordFilterRe.FindAllStringSubmatch(filter, -1) {
+		keywords = append(keywords, strings.ToLower(m[1]))
+	}
+	return keywords
+}
+
+// loadPlainText reads the parsed page fetch stored for urlID and returns its plain text.
+func loadPlainText(baseDir string, urlID int64) (string, error) {
+	page, found, err := artifact_manager.ReadParsedPage(baseDir, urlID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read parsed page: %w", err)
+	}
+	if !found {
+		return "", fmt.Errorf("no parsed page stored for URL ID %d", urlID)
+	}
+	return page.ToPlainText(), nil
+}
+
+// keywordSnippet returns a window of text around the first whole-word occurrence of
+// keyword, with whitespace collapsed, or "" when the keyword does not occur.
+func keywordSnippet(text, keyword string) string {
+	text = strings.TrimSpace(whitespaceRe.ReplaceAllString(text, " "))
+	re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`)
+	if err != nil {
+		return ""
+	}
+	loc := re.FindStringIndex(text)
+	if loc == nil {
+		return ""
+	}
+
+	// Center the keyword, then shift the window if it runs off either end
+	runes := []rune(text)
+	start := utf8.RuneCountInString(text[:loc[0]])
+	keywordLen := utf8.RuneCountInString(text[loc[0]:loc[1]])
+	from := max(0, start-(maxSnippetRunes-keywordLen)/2)
+	to := min(len(runes), from+maxSnippetRunes)
+	from = max(0, to-maxSnippetRunes)
+
+	// Don't cut words in half at either edge
+	for from > 0 && from < start && runes[from-1] != ' ' {
+		from++
+	}
+	for to < len(runes) && to > start+keywordLen && runes[to] != ' ' {
+		to--
+	}
+
+	snippet := strings.TrimSpace(string(runes[from:to]))
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// addSnippets attaches a snippet per keyword to each match whose stored page mentions it.
+// Pages that can't be read are skipped; a missing snippet never drops a match.
+func addSnippets(matches []QueryResult, keywords []string, baseDir string) {
+	for i := range matches {
+		text, err := loadPlainText(baseDir, matches[i].URLID)
+		if err != nil {
+			continue
+		}
+		for _, keyword := range keywords {
+			if snippet := keywordSnippet(text, keyword); snippet != "" {
+				matches[i].Snippets = append(matches[i].Snippets, KeywordSnippet{Keyword: keyword, Text: snippet})
+			}
+		}
+	}
+}
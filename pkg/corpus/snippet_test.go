@@ -0,0 +1,47 @@
+package corpus
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFilterKeywords(t *testing.T) {
+	got := filterKeywords("has_code_examples AND keyword:API OR keyword: python")
+	if strings.Join(got, ",") != "api,python" {
+		t.Errorf("filterKeywords() = %v, want [api python]", got)
+	}
+	if got := filterKeywords("content_type=docs"); len(got) != 0 {
+		t.Errorf("filterKeywords() = %v, want none", got)
+	}
+}
+
+func TestKeywordSnippet(t *testing.T) {
+	short := "Call the   API\n\nwith a token."
+	if got := keywordSnippet(short, "api"); got != "Call the API with a token." {
+		t.Errorf("short snippet = %q", got)
+	}
+
+	// Whole words only: "rapid" must not match "api"
+	if got := keywordSnippet("A rapid start.", "api"); got != "" {
+		t.Errorf("substring snippet = %q, want none", got)
+	}
+
+	long := strings.Repeat("lorem ipsum dolor ", 30) + "the api endpoint " + strings.Repeat("sit amet consectetur ", 30)
+	got := keywordSnippet(long, "api")
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("long snippet %q should be elided on both ends", got)
+	}
+	if !strings.Contains(got, "the api endpoint") {
+		t.Errorf("long snippet %q should contain the keyword in context", got)
+	}
+	if n := utf8.RuneCountInString(strings.Trim(got, "…")); n > maxSnippetRunes {
+		t.Errorf("snippet is %d runes, want at most %d", n, maxSnippetRunes)
+	}
+	vocabulary := "lorem ipsum dolor the api endpoint sit amet consectetur"
+	for _, word := range strings.Fields(strings.Trim(got, "…")) {
+		if !strings.Contains(" "+vocabulary+" ", " "+word+" ") {
+			t.Errorf("snippet %q cuts a word in half: %q", got, word)
+		}
+	}
+}
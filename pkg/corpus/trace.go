@@ -0,0 +1,229 @@
+package corpus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+)
+
+// TraceResponse is the data returned by the TRACE verb: where a term occurs in each
+// page, block by block.
+type TraceResponse struct {
+	Term       string     `json:"term" yaml:"term"`
+	URLCount   int        `json:"url_count" yaml:"url_count"`
+	MatchCount int        `json:"match_count" yaml:"match_count"` // Occurrences across all URLs
+	URLs       []TraceURL `json:"urls" yaml:"urls"`               // URLs that mention the term
+}
+
+// TraceURL lists the blocks of one page that contain the term, in document order.
+type TraceURL struct {
+	URLID  int64        `json:"url_id" yaml:"url_id"`
+	URL    string       `json:"url" yaml:"url"`
+	Blocks []TraceBlock `json:"blocks" yaml:"blocks"`
+}
+
+// TraceBlock is one block containing the term, with what's needed to cite it.
+type TraceBlock struct {
+	Position  int    `json:"position,omitempty" yaml:"position,omitempty"` // Block's document order (see models.ContentBlock)
+	Path      string `json:"path,omitempty" yaml:"path,omitempty"`         // Source element in the readability content
+	Type      string `json:"type" yaml:"type"`
+	SectionID string `json:"section_id,omitempty" yaml:"section_id,omitempty"`
+	Section   string `json:"section,omitempty" yaml:"section,omitempty"` // Heading of the enclosing section
+	Count     int    `json:"count" yaml:"count"`                         // Occurrences in the block
+	Snippet   string `json:"snippet" yaml:"snippet"`
+}
+
+// handleTrace implements the TRACE verb.
+func handleTrace(req models.Request) models.Response {
+	term, _ := req.Constraints["term"].(string)
+	term = strings.Join(strings.Fields(term), " ")
+	if term == "" {
+		return traceError("missing_term", "trace needs a keyword or phrase to locate",
+			"Use 'lwp corpus trace --url-ids=5 --term=error'")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return traceError("database_error", fmt.Sprintf("Failed to open database: %v", err),
+			"Ensure database is initialized", "Run 'llm-web-parser db init' if needed")
+	}
+	defer db.Close()
+
+	urlIDs := req.URLIDs
+	if len(urlIDs) == 0 {
+		if req.Session == 0 {
+			return traceError("missing_parameter", "Either session or url_ids must be provided",
+				"Provide --session=N or --url-ids=1,2,3")
+		}
+		sessionURLs, err := db.GetSessionURLs(int64(req.Session))
+		if err != nil {
+			return traceError("session_error", fmt.Sprintf("Failed to get session URLs: %v", err))
+		}
+		for _, u := range sessionURLs {
+			urlIDs = append(urlIDs, u.URLID)
+		}
+	}
+
+	resp, unknowns := TraceTerm(artifact_manager.DefaultBaseDir, urlIDs, term)
+	coverage := 0.0
+	if len(urlIDs) > 0 {
+		coverage = float64(len(urlIDs)-len(unknowns)) / float64(len(urlIDs))
+	}
+	return models.Response{
+		Verb:       VerbTRACE,
+		Data:       resp,
+		Confidence: 1.0,
+		Coverage:   coverage,
+		Unknowns:   unknowns,
+	}
+}
+
+// TraceTerm finds the blocks of each stored page that contain term as whole words,
+// case-insensitively; a phrase matches across any whitespace. Pages that can't be read
+// are reported in unknowns rather than failing the trace, as are pages parsed before
+// blocks carried positions.
+func TraceTerm(baseDir string, urlIDs []int64, term string) (*TraceResponse, []string) {
+	parts := strings.Fields(term)
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re := regexp.MustCompile(`(?i)` + strings.Join(parts, `\s+`))
+
+	resp := &TraceResponse{Term: term, URLCount: len(urlIDs), URLs: []TraceURL{}}
+	unknowns := []string{}
+	for _, urlID := range urlIDs {
+		page, found, err := artifact_manager.ReadParsedPage(baseDir, urlID)
+		switch {
+		case err != nil:
+			unknowns = append(unknowns, fmt.Sprintf("URL %d: %v", urlID, err))
+			continue
+		case !found:
+			unknowns = append(unknowns, fmt.Sprintf("URL %d: no parsed page stored (fetch it with --features full-parse)", urlID))
+			continue
+		}
+
+		traced := TraceURL{URLID: urlID, URL: page.URL}
+		unpositioned := false
+		visit := func(b models.ContentBlock, sectionID, section string) {
+			text := traceText(b)
+			count := countWholeMatches(re, text)
+			if count == 0 {
+				return
+			}
+			unpositioned = unpositioned || b.Position == 0
+			traced.Blocks = append(traced.Blocks, TraceBlock{
+				Position:  b.Position,
+				Path:      b.Path,
+				Type:      b.Type,
+				SectionID: sectionID,
+				Section:   section,
+				Count:     count,
+				Snippet:   keywordSnippet(text, term),
+			})
+			resp.MatchCount += count
+		}
+
+		var walk func(s models.Section)
+		walk = func(s models.Section) {
+			heading := ""
+			if s.Heading != nil {
+				heading = s.Heading.Text
+				visit(*s.Heading, s.ID, heading)
+			}
+			for _, b := range s.Blocks {
+				visit(b, s.ID, heading)
+			}
+			for _, child := range s.Children {
+				walk(child)
+			}
+		}
+		for _, s := range page.Content {
+			walk(s)
+		}
+		heading := ""
+		for _, b := range page.FlatContent {
+			if len(b.Type) == 2 && b.Type[0] == 'h' && b.Type[1] >= '1' && b.Type[1] <= '6' {
+				heading = b.Text
+			}
+			visit(b, "", heading)
+		}
+
+		if unpositioned {
+			unknowns = append(unknowns, fmt.Sprintf("URL %d was parsed before blocks recorded positions; run 'lwp db refresh --outdated' to add them", urlID))
+		}
+		if len(traced.Blocks) > 0 {
+			resp.URLs = append(resp.URLs, traced)
+		}
+	}
+	return resp, unknowns
+}
+
+// countWholeMatches counts the matches of re in text that are not part of a longer
+// word, i.e. not next to a letter, digit or underscore. Go's \b can't do this: it only
+// knows ASCII letters, so "café" never matched, and needs a word character on its
+// inner side, which "C++" ends without.
+func countWholeMatches(re *regexp.Regexp, text string) int {
+	count := 0
+	for start := 0; start < len(text); {
+		loc := re.FindStringIndex(text[start:])
+		if loc == nil {
+			break
+		}
+		from, to := start+loc[0], start+loc[1]
+		before, _ := utf8.DecodeLastRuneInString(text[:from])
+		after, _ := utf8.DecodeRuneInString(text[to:])
+		if to > from && !isWordRune(before) && !isWordRune(after) {
+			count++
+			start = to
+			continue
+		}
+		// Retry from the next character, so "x" in "xx x" is still found
+		_, size := utf8.DecodeRuneInString(text[from:])
+		start = from + max(size, 1)
+	}
+	return count
+}
+
+// isWordRune reports whether r is a letter, digit or underscore. The RuneError of an
+// empty string is not, so a match may start or end the text.
+func isWordRune(r rune) bool {
+	return r == '_' || (r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+}
+
+// traceText is the searchable text of a block: its text, or the content of a code,
+// table or formula block.
+func traceText(b models.ContentBlock) string {
+	switch {
+	case b.Code != nil:
+		return b.Code.Content
+	case b.Table != nil:
+		var rows []string
+		for _, row := range append([][]string{b.Table.Headers}, b.Table.Rows...) {
+			rows = append(rows, strings.Join(row, " | "))
+		}
+		return strings.Join(rows, "\n")
+	case b.Math != nil && b.Text == "":
+		return b.Math.Expression
+	}
+	return b.Text
+}
+
+func traceError(errorType, message string, actions ...string) models.Response {
+	return models.Response{
+		Verb:       VerbTRACE,
+		Data:       nil,
+		Confidence: 0.0,
+		Coverage:   0.0,
+		Unknowns:   []string{},
+		Error: &models.ErrorInfo{
+			Type:             errorType,
+			Message:          message,
+			SuggestedActions: actions,
+		},
+	}
+}
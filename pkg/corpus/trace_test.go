@@ -0,0 +1,102 @@
+package corpus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"gopkg.in/yaml.v3"
+)
+
+func storePage(t *testing.T, m *artifact_manager.Manager, urlID int64, page *models.Page) {
+	t.Helper()
+	data, err := yaml.Marshal(page)
+	if err != nil {
+		t.Fatalf("failed to marshal page: %v", err)
+	}
+	if err := m.SetParsedYAMLByID(urlID, data); err != nil {
+		t.Fatalf("failed to store page: %v", err)
+	}
+}
+
+func TestTraceTerm(t *testing.T) {
+	baseDir := t.TempDir()
+	m, err := artifact_manager.NewManager(baseDir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager() failed: %v", err)
+	}
+
+	storePage(t, m, 1, &models.Page{
+		URL: "https://example.com/errors",
+		Content: []models.Section{{
+			ID:      "section-1",
+			Level:   2,
+			Heading: &models.ContentBlock{Type: "h2", Text: "Error handling", Position: 1, Path: "article[1]/h2[1]"},
+			Blocks: []models.ContentBlock{
+				{Type: "p", Text: "Every error is wrapped. An error never panics.", Position: 2, Path: "article[1]/p[1]"},
+				{Type: "p", Text: "Errors are rare; terror is not a match.", Position: 3, Path: "article[1]/p[2]"},
+				{Type: "code", Code: &models.Code{Content: "return fmt.Errorf(\"read: %w\", error)"}, Position: 4, Path: "article[1]/pre[1]"},
+			},
+		}},
+	})
+	// Stored before blocks had positions
+	storePage(t, m, 2, &models.Page{
+		URL:         "https://example.com/old",
+		FlatContent: []models.ContentBlock{{Type: "h1", Text: "Old"}, {Type: "p", Text: "A rate\nlimit error."}},
+	})
+
+	resp, unknowns := TraceTerm(baseDir, []int64{1, 2, 3}, "error")
+	if resp.MatchCount != 5 {
+		t.Errorf("MatchCount = %d, want 5", resp.MatchCount)
+	}
+	if len(resp.URLs) != 2 {
+		t.Fatalf("len(URLs) = %d, want 2", len(resp.URLs))
+	}
+
+	blocks := resp.URLs[0].Blocks
+	var positions []int
+	for _, b := range blocks {
+		positions = append(positions, b.Position)
+		if b.SectionID != "section-1" || b.Section != "Error handling" {
+			t.Errorf("block %d section = %q %q, want section-1 \"Error handling\"", b.Position, b.SectionID, b.Section)
+		}
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+	if blocks[1].Count != 2 || blocks[1].Path != "article[1]/p[1]" {
+		t.Errorf("block 2 = %+v, want count 2 at article[1]/p[1]", blocks[1])
+	}
+
+	old := resp.URLs[1].Blocks
+	if len(old) != 1 || old[0].Position != 0 || old[0].Section != "Old" {
+		t.Errorf("old page blocks = %+v, want one unpositioned block under \"Old\"", old)
+	}
+	if len(unknowns) != 2 || !strings.Contains(unknowns[0], "URL 2") || !strings.Contains(unknowns[1], "URL 3") {
+		t.Errorf("unknowns = %v, want URL 2 unpositioned and URL 3 missing", unknowns)
+	}
+
+	// A phrase matches across line breaks
+	resp, _ = TraceTerm(baseDir, []int64{2}, "rate limit")
+	if resp.MatchCount != 1 {
+		t.Errorf("phrase MatchCount = %d, want 1", resp.MatchCount)
+	}
+
+	// Word boundaries hold for non-ASCII letters and for terms ending in punctuation
+	storePage(t, m, 4, &models.Page{
+		URL: "https://example.com/mixed",
+		FlatContent: []models.ContentBlock{
+			{Type: "p", Text: "Le café ouvre. Les cafés ferment. Café!", Position: 1},
+			{Type: "p", Text: "Ошибка чтения, а не ошибками.", Position: 2},
+			{Type: "p", Text: "C++ and C++17 differ from c++.", Position: 3},
+		},
+	})
+	for term, want := range map[string]int{"café": 2, "ошибка": 1, "C++": 2, "caf": 0} {
+		if resp, _ := TraceTerm(baseDir, []int64{4}, term); resp.MatchCount != want {
+			t.Errorf("TraceTerm(%q) MatchCount = %d, want %d", term, resp.MatchCount, want)
+		}
+	}
+}
@@ -13,7 +13,8 @@ const DefaultDBName = "llm-web-parser.db"
 
 type DB struct {
 	*sql.DB
-	path string
+	path  string
+	retry RetryPolicy // Applied by Exec, Query and QueryRow (see retry.go)
 }
 
 // openDB opens a SQLite database at the given path
@@ -32,8 +33,19 @@ func openDB(dbPath string) (*sql.DB, error) {
 	return sqlDB, nil
 }
 
-// Open opens or creates the SQLite database in the current working directory
+// Options configures a database opened with OpenWithOptions.
+type Options struct {
+	Retry RetryPolicy // Retries for statements that find the database locked
+}
+
+// Open opens or creates the SQLite database in the current working directory,
+// retrying locked statements with DefaultRetryPolicy.
 func Open() (*DB, error) {
+	return OpenWithOptions(Options{Retry: DefaultRetryPolicy})
+}
+
+// OpenWithOptions opens or creates the SQLite database in the current working directory.
+func OpenWithOptions(opts Options) (*DB, error) {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -48,8 +60,9 @@ func Open() (*DB, error) {
 	}
 
 	db := &DB{
-		DB:   sqlDB,
-		path: dbPath,
+		DB:    sqlDB,
+		path:  dbPath,
+		retry: opts.Retry,
 	}
 
 	// Auto-initialize schema if it doesn't exist
@@ -97,14 +110,76 @@ func (db *DB) InitSchema() error {
 	return err
 }
 
+// SchemaVersion is the schema revision this build creates and migrates to: the number
+// of the last migration below. Open records it in PRAGMA user_version.
+const SchemaVersion = 9
+
+// columnMigrations add columns to databases created by older builds.
+var columnMigrations = []struct {
+	table  string
+	column string
+	ddl    string
+}{
+	// Migration 1: Add meta_keywords column (2026-03-10)
+	{"urls", "meta_keywords", "ALTER TABLE urls ADD COLUMN meta_keywords TEXT"},
+	// Migration 2: Track canonical URLs declared via <link rel="canonical">
+	{"urls", "canonical_declared", "ALTER TABLE urls ADD COLUMN canonical_declared BOOLEAN DEFAULT 0"},
+	// Migration 4: Session labels (fetch --tag, db tag)
+	{"sessions", "tag", "ALTER TABLE sessions ADD COLUMN tag TEXT"},
+	// Migration 5: Robots directives (<meta name="robots">, X-Robots-Tag)
+	{"urls", "noindex", "ALTER TABLE urls ADD COLUMN noindex BOOLEAN DEFAULT 0"},
+	{"urls", "nofollow", "ALTER TABLE urls ADD COLUMN nofollow BOOLEAN DEFAULT 0"},
+	// Migration 6: Per-session content hash for change monitoring (db changed)
+	{"session_results", "content_hash", "ALTER TABLE session_results ADD COLUMN content_hash TEXT"},
+	// Migration 7: Sessions fetched without keeping raw HTML (fetch --no-store-raw)
+	{"sessions", "raw_stored", "ALTER TABLE sessions ADD COLUMN raw_stored BOOLEAN DEFAULT 1"},
+	// Migration 8: Content dates for freshness sorting (corpus query --sort=date, db urls --sort=date)
+	{"urls", "content_date", "ALTER TABLE urls ADD COLUMN content_date TEXT"},
+	{"urls", "content_date_kind", "ALTER TABLE urls ADD COLUMN content_date_kind TEXT"},
+	// Migration 9: URL key policy; older rows have none and only match by original_url
+	{"urls", "key_policy", "ALTER TABLE urls ADD COLUMN key_policy TEXT"},
+}
+
 // runMigrations runs schema migrations for existing databases
 func (db *DB) runMigrations() error {
-	// Migration 1: Add meta_keywords column (2026-03-10)
-	// Check if column exists by querying table info
-	var hasMetaKeywords bool
-	rows, err := db.Query("PRAGMA table_info(urls)")
+	for _, m := range columnMigrations {
+		exists, err := db.hasColumn(m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(m.ddl); err != nil {
+			return fmt.Errorf("failed to add %s column: %w", m.column, err)
+		}
+	}
+
+	// Migration 3: Seed the yaml_parsed artifact type (generic.yaml) missing from older databases
+	if _, err := db.Exec(`INSERT OR IGNORE INTO artifact_types (type_name, description)
+		VALUES ('yaml_parsed', 'Parsed YAML output from parser (generic.yaml)')`); err != nil {
+		return fmt.Errorf("failed to seed yaml_parsed artifact type: %w", err)
+	}
+
+	// Never lower the version a newer build recorded
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < SchemaVersion {
+		if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// hasColumn reports whether table already has the named column.
+func (db *DB) hasColumn(table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
 	if err != nil {
-		return fmt.Errorf("failed to check table schema: %w", err)
+		return false, fmt.Errorf("failed to check table schema: %w", err)
 	}
 	defer rows.Close()
 
@@ -116,20 +191,12 @@ func (db *DB) runMigrations() error {
 		var dfltValue sql.NullString
 		var pk int
 		if err := rows.Scan(&cid, &name, &dataType, &notNull, &dfltValue, &pk); err != nil {
-			return fmt.Errorf("failed to scan column info: %w", err)
+			return false, fmt.Errorf("failed to scan column info: %w", err)
 		}
-		if name == "meta_keywords" {
-			hasMetaKeywords = true
-			break
+		if name == column {
+			return true, nil
 		}
 	}
 
-	if !hasMetaKeywords {
-		_, err = db.Exec("ALTER TABLE urls ADD COLUMN meta_keywords TEXT")
-		if err != nil {
-			return fmt.Errorf("failed to add meta_keywords column: %w", err)
-		}
-	}
-
-	return nil
+	return false, rows.Err()
 }
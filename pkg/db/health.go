@@ -0,0 +1,78 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// SchemaStatus describes an existing database file without migrating it.
+type SchemaStatus struct {
+	Path           string
+	Initialized    bool     // The schema's tables exist
+	Version        int      // PRAGMA user_version; 0 for databases from builds before versioning
+	MissingColumns []string // "table.column" added by migrations not yet applied
+}
+
+// Current reports whether the database matches this build's schema.
+func (s SchemaStatus) Current() bool {
+	return s.Initialized && s.Version == SchemaVersion && len(s.MissingColumns) == 0
+}
+
+// InspectSchema reads the schema state of the database at dbPath. Unlike Open it never
+// creates the file or applies migrations, so it is safe to run against a database in use.
+func InspectSchema(dbPath string) (*SchemaStatus, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	db := &DB{DB: sqlDB, path: dbPath, retry: DefaultRetryPolicy}
+	defer db.Close()
+
+	status := &SchemaStatus{Path: dbPath}
+	if err := db.QueryRow("PRAGMA user_version").Scan(&status.Version); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	var tableName string
+	err = db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name='urls'").Scan(&tableName)
+	switch {
+	case err == sql.ErrNoRows:
+		return status, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to check schema: %w", err)
+	}
+	status.Initialized = true
+
+	for _, m := range columnMigrations {
+		exists, err := db.hasColumn(m.table, m.column)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			status.MissingColumns = append(status.MissingColumns, m.table+"."+m.column)
+		}
+	}
+	return status, nil
+}
+
+// SQLiteInfo reports the linked SQLite library's version and whether it was built with
+// the FTS5 full-text search extension.
+func SQLiteInfo() (version string, fts5 bool, err error) {
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	defer sqlDB.Close()
+
+	if err := sqlDB.QueryRow("SELECT sqlite_version()").Scan(&version); err != nil {
+		return "", false, fmt.Errorf("failed to read SQLite version: %w", err)
+	}
+	// Builds without FTS5 fail with "no such module: fts5"
+	_, ftsErr := sqlDB.Exec("CREATE VIRTUAL TABLE fts5_probe USING fts5(content)")
+	return version, ftsErr == nil, nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectSchema_Current(t *testing.T) {
+	t.Chdir(t.TempDir())
+	database, err := Open()
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	path := database.Path()
+	database.Close()
+
+	status, err := InspectSchema(path)
+	if err != nil {
+		t.Fatalf("InspectSchema() failed: %v", err)
+	}
+	if !status.Current() {
+		t.Errorf("status = %+v, want current", status)
+	}
+	if status.Version != SchemaVersion {
+		t.Errorf("Version = %d, want %d", status.Version, SchemaVersion)
+	}
+}
+
+func TestInspectSchema_Outdated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDBName)
+	sqlDB, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB() failed: %v", err)
+	}
+	// A database from before content dates and versioning
+	if _, err := sqlDB.Exec(schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := sqlDB.Exec("ALTER TABLE urls DROP COLUMN content_date_kind"); err != nil {
+		t.Fatalf("failed to drop column: %v", err)
+	}
+	sqlDB.Close()
+
+	status, err := InspectSchema(path)
+	if err != nil {
+		t.Fatalf("InspectSchema() failed: %v", err)
+	}
+	if status.Current() || status.Version != 0 || !status.Initialized {
+		t.Errorf("status = %+v, want initialized, version 0, not current", status)
+	}
+	if len(status.MissingColumns) != 1 || status.MissingColumns[0] != "urls.content_date_kind" {
+		t.Errorf("MissingColumns = %v, want [urls.content_date_kind]", status.MissingColumns)
+	}
+
+	// Opening migrates and stamps the version
+	t.Chdir(filepath.Dir(path))
+	database, err := Open()
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	database.Close()
+	if status, err = InspectSchema(path); err != nil || !status.Current() {
+		t.Errorf("after Open: status = %+v, err = %v, want current", status, err)
+	}
+}
+
+func TestInspectSchema_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDBName)
+	if _, err := InspectSchema(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("InspectSchema() error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := InspectSchema(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("InspectSchema() created the file")
+	}
+}
+
+func TestSQLiteInfo(t *testing.T) {
+	version, _, err := SQLiteInfo()
+	if err != nil {
+		t.Fatalf("SQLiteInfo() failed: %v", err)
+	}
+	if version == "" {
+		t.Error("SQLiteInfo() returned an empty version")
+	}
+}
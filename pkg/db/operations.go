@@ -5,35 +5,37 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/urlnorm"
 )
 
 // InsertURL parses and inserts a URL, returning the url_id.
-// If the URL already exists, returns the existing url_id.
+// If the URL, or another with the same storage key, already exists, returns the existing url_id.
 func (db *DB) InsertURL(rawURL string) (int64, error) {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
+	policy := urlnorm.CurrentPolicy()
+	canonicalURL := policy.NormalizeParsed(parsed)
+
 	// Check if URL already exists
-	var existingID int64
-	err = db.QueryRow("SELECT url_id FROM urls WHERE original_url = ?", rawURL).Scan(&existingID)
-	if err == nil {
-		return existingID, nil
-	}
-	if !errors.Is(err, sql.ErrNoRows) {
+	existingID, found, err := db.lookupURLID(rawURL, canonicalURL, policy)
+	if err != nil {
 		return 0, fmt.Errorf("failed to check existing URL: %w", err)
 	}
-
-	// Extract canonical URL (scheme + host + path, no query/fragment)
-	canonicalURL := fmt.Sprintf("%s://%s%s", parsed.Scheme, parsed.Host, parsed.Path)
+	if found {
+		return existingID, nil
+	}
 
 	// Insert URL
 	result, err := db.Exec(`
-		INSERT INTO urls (original_url, canonical_url, scheme, domain, path, fragment)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, rawURL, canonicalURL, parsed.Scheme, parsed.Host, parsed.Path, parsed.Fragment)
+		INSERT INTO urls (original_url, canonical_url, key_policy, scheme, domain, path, fragment)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rawURL, canonicalURL, policy.String(), parsed.Scheme, parsed.Host, parsed.Path, parsed.Fragment)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert URL: %w", err)
 	}
@@ -64,6 +66,34 @@ func (db *DB) InsertURL(rawURL string) (int64, error) {
 	return urlID, nil
 }
 
+// CanonicalURL returns the storage key of a parsed URL under the active urlnorm policy,
+// the same key the artifact manager hashes into file names.
+func CanonicalURL(parsed *url.URL) string {
+	return urlnorm.NormalizeParsed(parsed)
+}
+
+// lookupURLID finds the url_id stored for rawURL, falling back to a URL with the same
+// storage key under policy. Keys stored under another policy are skipped, as are URLs
+// whose canonical_url came from <link rel="canonical">: that column then holds the page's
+// declared canonical, not a storage key.
+func (db *DB) lookupURLID(rawURL, canonicalURL string, policy urlnorm.Policy) (int64, bool, error) {
+	var urlID int64
+	err := db.QueryRow(`
+		SELECT url_id FROM urls
+		WHERE original_url = ?
+		   OR (canonical_url = ? AND key_policy = ? AND canonical_declared = 0)
+		ORDER BY original_url = ? DESC, url_id
+		LIMIT 1
+	`, rawURL, canonicalURL, policy.String(), rawURL).Scan(&urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return urlID, true, nil
+}
+
 // RecordAccess records a fetch attempt in url_accesses.
 func (db *DB) RecordAccess(urlID int64, statusCode int, errorType string, success bool) error {
 	_, err := db.Exec(`
@@ -127,6 +157,28 @@ func (db *DB) SetURLMetadata(urlID int64, namespace, key, value string) error {
 	return nil
 }
 
+// GetURLMetadata returns every key-value pair stored for a URL under namespace.
+func (db *DB) GetURLMetadata(urlID int64, namespace string) (map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT key, value FROM url_metadata
+		WHERE url_id = ? AND namespace = ?
+	`, urlID, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query URL metadata: %w", err)
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan URL metadata: %w", err)
+		}
+		metadata[key] = value
+	}
+	return metadata, rows.Err()
+}
+
 // SetArtifactMetadata sets a metadata key-value pair for an artifact (upsert).
 func (db *DB) SetArtifactMetadata(artifactID int64, key, value string) error {
 	_, err := db.Exec(`
@@ -140,6 +192,54 @@ func (db *DB) SetArtifactMetadata(artifactID int64, key, value string) error {
 	return nil
 }
 
+// GetArtifactMetadata returns the metadata of a URL's artifact of the given type
+// (e.g. "yaml_parsed"). The map is empty when the artifact or its metadata is missing.
+func (db *DB) GetArtifactMetadata(urlID int64, typeName string) (map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT am.key, am.value
+		FROM artifact_metadata am
+		JOIN artifacts a ON a.artifact_id = am.artifact_id
+		JOIN artifact_types t ON t.type_id = a.type_id
+		WHERE a.url_id = ? AND t.type_name = ?
+	`, urlID, typeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query artifact metadata: %w", err)
+	}
+	defer rows.Close()
+
+	metadata := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan artifact metadata: %w", err)
+		}
+		metadata[key] = value
+	}
+	return metadata, rows.Err()
+}
+
+// GetParsedArtifactMetadata returns the metadata of a URL's parsed page artifact:
+// generic.yaml (yaml_parsed), or generic.json (json_parsed) when no YAML was stored.
+func (db *DB) GetParsedArtifactMetadata(urlID int64) (map[string]string, error) {
+	metadata, err := db.GetArtifactMetadata(urlID, "yaml_parsed")
+	if err != nil || len(metadata) > 0 {
+		return metadata, err
+	}
+	return db.GetArtifactMetadata(urlID, "json_parsed")
+}
+
+// DeleteArtifact removes a URL's artifact of the given type and its metadata, if any.
+func (db *DB) DeleteArtifact(urlID int64, typeName string) error {
+	_, err := db.Exec(`
+		DELETE FROM artifacts
+		WHERE url_id = ? AND type_id = (SELECT type_id FROM artifact_types WHERE type_name = ?)
+	`, urlID, typeName)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s artifact: %w", typeName, err)
+	}
+	return nil
+}
+
 // GetArtifactTypeID returns the type_id for a given type_name.
 func (db *DB) GetArtifactTypeID(typeName string) (int64, error) {
 	var typeID int64
@@ -169,6 +269,34 @@ func (db *DB) GetLastAccess(urlID int64) (*AccessRecord, error) {
 	return &record, nil
 }
 
+// GetAccessHistory returns a URL's access records, newest first. limit <= 0 returns all.
+func (db *DB) GetAccessHistory(urlID int64, limit int) ([]AccessRecord, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	rows, err := db.Query(`
+		SELECT access_id, accessed_at, status_code, COALESCE(error_type, ''), success
+		FROM url_accesses
+		WHERE url_id = ?
+		ORDER BY accessed_at DESC, access_id DESC
+		LIMIT ?
+	`, urlID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []AccessRecord
+	for rows.Next() {
+		var record AccessRecord
+		if err := rows.Scan(&record.AccessID, &record.AccessedAt, &record.StatusCode, &record.ErrorType, &record.Success); err != nil {
+			return nil, fmt.Errorf("failed to scan access: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
 // AccessRecord represents a URL access attempt.
 type AccessRecord struct {
 	AccessID   int64
@@ -196,19 +324,112 @@ func (db *DB) GetArtifactPath(urlID int64, typeName string) (string, error) {
 	return filePath, nil
 }
 
-// GetURLID returns the url_id for a given original URL.
+// GetURLID returns the url_id for a given original URL, or for a stored URL with the same storage key.
 func (db *DB) GetURLID(originalURL string) (int64, error) {
-	var urlID int64
-	err := db.QueryRow("SELECT url_id FROM urls WHERE original_url = ?", originalURL).Scan(&urlID)
-	if err == sql.ErrNoRows {
+	policy := urlnorm.CurrentPolicy()
+	canonicalURL, err := policy.Normalize(originalURL)
+	if err != nil {
 		return 0, fmt.Errorf("URL not found: %s", originalURL)
 	}
+	urlID, found, err := db.lookupURLID(originalURL, canonicalURL, policy)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get URL ID: %w", err)
 	}
+	if !found {
+		return 0, fmt.Errorf("URL not found: %s", originalURL)
+	}
 	return urlID, nil
 }
 
+// GetCanonicalURLIndex returns a map of canonical URL to url_id for every URL in the database
+// whose canonical URL was declared or derived under the active urlnorm policy.
+// When several URLs share a canonical form, the lowest url_id wins.
+func (db *DB) GetCanonicalURLIndex() (map[string]int64, error) {
+	rows, err := db.Query(`
+		SELECT url_id, canonical_url
+		FROM urls
+		WHERE canonical_url IS NOT NULL
+		  AND (canonical_declared = 1 OR key_policy = ?)
+		ORDER BY url_id DESC
+	`, urlnorm.CurrentPolicy().String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query canonical URLs: %w", err)
+	}
+	defer rows.Close()
+
+	index := make(map[string]int64)
+	for rows.Next() {
+		var urlID int64
+		var canonicalURL string
+		if err := rows.Scan(&urlID, &canonicalURL); err != nil {
+			return nil, fmt.Errorf("failed to scan canonical URL: %w", err)
+		}
+		index[canonicalURL] = urlID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate canonical URLs: %w", err)
+	}
+
+	return index, nil
+}
+
+// SetDeclaredCanonical records the canonical URL a page declared via <link rel="canonical">,
+// replacing the derived scheme+host+path form. If an earlier URL already declared the same
+// canonical, this URL is flagged as its duplicate and the earlier url_id is returned (0 otherwise).
+func (db *DB) SetDeclaredCanonical(urlID int64, canonicalURL string) (int64, error) {
+	_, err := db.Exec(`
+		UPDATE urls SET
+			canonical_url = ?,
+			canonical_declared = 1,
+			key_policy = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE url_id = ?
+	`, canonicalURL, urlID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update canonical URL: %w", err)
+	}
+
+	var primaryID int64
+	err = db.QueryRow(`
+		SELECT MIN(url_id) FROM urls
+		WHERE canonical_url = ? AND canonical_declared = 1
+	`, canonicalURL).Scan(&primaryID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find canonical duplicates: %w", err)
+	}
+
+	if primaryID == urlID {
+		return 0, nil
+	}
+
+	if err := db.SetURLMetadata(urlID, "canonical", "duplicate_of", strconv.FormatInt(primaryID, 10)); err != nil {
+		return 0, err
+	}
+	return primaryID, nil
+}
+
+// GetDuplicateOf returns the url_id this URL duplicates by declared canonical, or 0 if none.
+func (db *DB) GetDuplicateOf(urlID int64) (int64, error) {
+	var value string
+	err := db.QueryRow(`
+		SELECT value FROM url_metadata
+		WHERE url_id = ? AND namespace = 'canonical' AND key = 'duplicate_of'
+	`, urlID).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get duplicate_of: %w", err)
+	}
+
+	primaryID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duplicate_of value %q: %w", value, err)
+	}
+	return primaryID, nil
+}
+
 // ListArtifacts returns all artifacts for a given URL.
 func (db *DB) ListArtifacts(urlID int64) ([]ArtifactInfo, error) {
 	rows, err := db.Query(`
@@ -293,10 +514,14 @@ type ContentTypeInfo struct {
 	HasInfobox          bool
 	HasTOC              bool
 	HasCodeExamples     bool
+	NoIndex             bool // Robots directives from <meta name="robots"> or X-Robots-Tag
+	NoFollow            bool
+	ContentDate         sql.NullString // ISO-8601 publication or last-updated date
+	ContentDateKind     sql.NullString // "published" or "updated"
 	SectionCount        int
 	CitationCount       int
 	CodeBlockCount      int
-	TopKeywords         sql.NullString // JSON object: {"word1": count1, ...}
+	TopKeywords         sql.NullString // JSON array: ["word1:count1", ...]
 	MetaKeywords        sql.NullString // JSON array: ["keyword1", "keyword2", ...]
 }
 
@@ -311,6 +536,10 @@ func (db *DB) UpdateURLContentType(urlID int64, info ContentTypeInfo) error {
 			has_infobox = ?,
 			has_toc = ?,
 			has_code_examples = ?,
+			noindex = ?,
+			nofollow = ?,
+			content_date = ?,
+			content_date_kind = ?,
 			section_count = ?,
 			citation_count = ?,
 			code_block_count = ?,
@@ -320,6 +549,7 @@ func (db *DB) UpdateURLContentType(urlID int64, info ContentTypeInfo) error {
 		WHERE url_id = ?
 	`, info.ContentType, info.ContentSubtype, info.DetectionConfidence,
 		info.HasAbstract, info.HasInfobox, info.HasTOC, info.HasCodeExamples,
+		info.NoIndex, info.NoFollow, info.ContentDate, info.ContentDateKind,
 		info.SectionCount, info.CitationCount, info.CodeBlockCount,
 		info.TopKeywords, info.MetaKeywords, urlID)
 	if err != nil {
@@ -328,18 +558,31 @@ func (db *DB) UpdateURLContentType(urlID int64, info ContentTypeInfo) error {
 	return nil
 }
 
+// UpdateTopKeywords replaces a URL's stored keyword counts (a JSON array of
+// "word:count" strings, highest count first).
+func (db *DB) UpdateTopKeywords(urlID int64, topKeywords string) error {
+	_, err := db.Exec(`UPDATE urls SET top_keywords = ?, updated_at = CURRENT_TIMESTAMP WHERE url_id = ?`,
+		NewNullString(topKeywords), urlID)
+	if err != nil {
+		return fmt.Errorf("failed to update top keywords: %w", err)
+	}
+	return nil
+}
+
 // GetURLContentInfo retrieves content type information for a URL.
 func (db *DB) GetURLContentInfo(urlID int64) (*ContentTypeInfo, error) {
 	var info ContentTypeInfo
 	err := db.QueryRow(`
 		SELECT content_type, content_subtype, detection_confidence,
-			has_abstract, has_infobox, has_toc, has_code_examples,
+			has_abstract, has_infobox, has_toc, has_code_examples, noindex, nofollow,
+			content_date, content_date_kind,
 			section_count, citation_count, code_block_count, top_keywords, meta_keywords
 		FROM urls
 		WHERE url_id = ?
 	`, urlID).Scan(
 		&info.ContentType, &info.ContentSubtype, &info.DetectionConfidence,
 		&info.HasAbstract, &info.HasInfobox, &info.HasTOC, &info.HasCodeExamples,
+		&info.NoIndex, &info.NoFollow, &info.ContentDate, &info.ContentDateKind,
 		&info.SectionCount, &info.CitationCount, &info.CodeBlockCount,
 		&info.TopKeywords, &info.MetaKeywords,
 	)
@@ -390,6 +633,108 @@ func (db *DB) GetURLsByContentType(contentType string, hasAbstract, hasCode *boo
 	return urls, nil
 }
 
+// KeywordFrequency is a keyword's summed count and the number of URLs it appears in.
+type KeywordFrequency struct {
+	Word       string `json:"word" yaml:"word"`
+	TotalCount int    `json:"total_count" yaml:"total_count"`
+	DocCount   int    `json:"doc_count" yaml:"doc_count"`
+}
+
+// GetKeywordDocFrequency aggregates the stored top_keywords ("word:count" entries) of a
+// session's URLs, returning keywords found in at least minDocCount URLs, most widespread first.
+func (db *DB) GetKeywordDocFrequency(sessionID int64, minDocCount int) ([]KeywordFrequency, error) {
+	rows, err := db.Query(`
+		WITH entries AS (
+			SELECT DISTINCT u.url_id, k.value AS entry, instr(k.value, ':') AS sep
+			FROM session_urls su
+			JOIN urls u ON u.url_id = su.url_id
+			JOIN json_each(u.top_keywords) k
+			WHERE su.session_id = ? AND json_valid(u.top_keywords)
+		)
+		SELECT substr(entry, 1, sep - 1) AS word,
+			SUM(CAST(substr(entry, sep + 1) AS INTEGER)) AS total_count,
+			COUNT(DISTINCT url_id) AS doc_count
+		FROM entries
+		WHERE sep > 1
+		GROUP BY word
+		HAVING doc_count >= ?
+		ORDER BY doc_count DESC, total_count DESC, word
+	`, sessionID, minDocCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keyword document frequency: %w", err)
+	}
+	defer rows.Close()
+
+	var keywords []KeywordFrequency
+	for rows.Next() {
+		var kw KeywordFrequency
+		if err := rows.Scan(&kw.Word, &kw.TotalCount, &kw.DocCount); err != nil {
+			return nil, fmt.Errorf("failed to scan keyword frequency: %w", err)
+		}
+		keywords = append(keywords, kw)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keyword frequencies: %w", err)
+	}
+
+	return keywords, nil
+}
+
+// ConfidenceBuckets are the detection_confidence ranges GetConfidenceHistogram counts,
+// lowest first; each includes its lower bound, and 8-10 includes 10.
+var ConfidenceBuckets = []string{"0-2", "2-4", "4-6", "6-8", "8-10"}
+
+// UnscoredBucket counts URLs with no detection_confidence (never classified, or scored 0).
+const UnscoredBucket = "unscored"
+
+// ConfidenceBucketCount is the number of a session's URLs of one content type whose
+// detection_confidence falls in Bucket.
+type ConfidenceBucketCount struct {
+	ContentType string
+	Bucket      string
+	Count       int
+}
+
+// GetConfidenceHistogram buckets the detection_confidence of a session's URLs per content
+// type. Only non-empty buckets are returned, ordered by content type.
+func (db *DB) GetConfidenceHistogram(sessionID int64) ([]ConfidenceBucketCount, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(NULLIF(u.content_type, ''), 'unknown') AS content_type,
+			CASE
+				WHEN u.detection_confidence IS NULL THEN ?
+				WHEN u.detection_confidence < 2 THEN '0-2'
+				WHEN u.detection_confidence < 4 THEN '2-4'
+				WHEN u.detection_confidence < 6 THEN '4-6'
+				WHEN u.detection_confidence < 8 THEN '6-8'
+				ELSE '8-10'
+			END AS bucket,
+			COUNT(*)
+		FROM session_urls su
+		JOIN urls u ON u.url_id = su.url_id
+		WHERE su.session_id = ?
+		GROUP BY content_type, bucket
+		ORDER BY content_type, bucket
+	`, UnscoredBucket, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query confidence histogram: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []ConfidenceBucketCount
+	for rows.Next() {
+		var c ConfidenceBucketCount
+		if err := rows.Scan(&c.ContentType, &c.Bucket, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan confidence bucket: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read confidence histogram: %w", err)
+	}
+
+	return counts, nil
+}
+
 // NewNullString creates a sql.NullString from a string value.
 func NewNullString(s string) sql.NullString {
 	if s == "" {
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/backoff"
+)
+
+// SQLite primary result codes for a database another connection holds a lock on.
+const (
+	sqliteBusy   = 5
+	sqliteLocked = 6
+)
+
+// RetryPolicy controls how statements that hit SQLITE_BUSY or SQLITE_LOCKED are retried.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt (0 = no retries)
+	BaseDelay  time.Duration // Delay before the first retry; doubles each attempt
+	MaxDelay   time.Duration // Upper bound on a single delay
+}
+
+// DefaultRetryPolicy rides out short write bursts from the fetch worker pool.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: 20 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+
+// Backoff returns the delay before retry number attempt (starting at 1), using
+// exponential growth with full jitter so concurrent writers don't retry in lockstep.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	return backoff.Jittered(p.BaseDelay, p.MaxDelay, attempt)
+}
+
+// Exec runs a statement, retrying while another connection holds the database locked.
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := db.withRetry(func() error {
+		var err error
+		result, err = db.DB.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
+
+// Query runs a query, retrying while another connection holds the database locked.
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := db.withRetry(func() error {
+		var err error
+		rows, err = db.DB.Query(query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRow runs a single-row query, retrying while another connection holds the
+// database locked. As with sql.DB, any error is reported by Scan.
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	var row *sql.Row
+	_ = db.withRetry(func() error {
+		row = db.DB.QueryRow(query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// withRetry runs op until it succeeds, fails with anything but a lock error, or the
+// policy's retries run out.
+func (db *DB) withRetry(op func() error) error {
+	err := op()
+	for attempt := 1; attempt <= db.retry.MaxRetries && isBusy(err); attempt++ {
+		time.Sleep(db.retry.Backoff(attempt))
+		err = op()
+	}
+	return err
+}
+
+// isBusy reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED. Drivers that
+// expose result codes are matched by code (extended codes keep the primary code in
+// the low byte); others by the messages SQLite uses for those codes.
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		code := coded.Code() & 0xff
+		return code == sqliteBusy || code == sqliteLocked
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked") ||
+		strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "SQLITE_LOCKED")
+}
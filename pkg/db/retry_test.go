@@ -0,0 +1,158 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// codedError mimics driver errors that expose SQLite result codes.
+type codedError int
+
+func (e codedError) Error() string { return fmt.Sprintf("sqlite error %d", int(e)) }
+func (e codedError) Code() int     { return int(e) }
+
+func TestIsBusy(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{codedError(5), true},
+		{codedError(6), true},
+		{codedError(517), true}, // SQLITE_BUSY_SNAPSHOT
+		{codedError(19), false}, // SQLITE_CONSTRAINT
+		{fmt.Errorf("insert: %w", codedError(5)), true},
+		{errors.New("database is locked"), true},
+		{errors.New("database table is locked: urls"), true},
+		{errors.New("UNIQUE constraint failed: urls.original_url"), false},
+	}
+	for _, tt := range tests {
+		if got := isBusy(tt.err); got != tt.want {
+			t.Errorf("isBusy(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// openLockTestDB opens a handle on path with one connection that fails at once on a
+// lock (busy_timeout 0), so only the retry policy stands between it and SQLITE_BUSY.
+func openLockTestDB(t *testing.T, path string, policy RetryPolicy) *DB {
+	t.Helper()
+	sqlDB, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB() error = %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	sqlDB.SetMaxOpenConns(1)
+	if _, err := sqlDB.Exec("PRAGMA busy_timeout = 0"); err != nil {
+		t.Fatalf("PRAGMA busy_timeout error = %v", err)
+	}
+	return &DB{DB: sqlDB, path: path, retry: policy}
+}
+
+func TestExec_RetriesWhileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDBName)
+	holder := openLockTestDB(t, path, RetryPolicy{})
+	if _, err := holder.Exec("CREATE TABLE counter (n INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another connection holds the write lock
+	tx, err := holder.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT INTO counter (n) VALUES (0)"); err != nil {
+		t.Fatal(err)
+	}
+
+	noRetry := openLockTestDB(t, path, RetryPolicy{})
+	if _, err := noRetry.Exec("INSERT INTO counter (n) VALUES (1)"); !isBusy(err) {
+		t.Fatalf("Exec() without retries error = %v, want a busy error", err)
+	}
+
+	retrying := openLockTestDB(t, path, RetryPolicy{MaxRetries: 20, BaseDelay: 5 * time.Millisecond, MaxDelay: 20 * time.Millisecond})
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = tx.Commit()
+	}()
+	if _, err := retrying.Exec("INSERT INTO counter (n) VALUES (2)"); err != nil {
+		t.Fatalf("Exec() with retries error = %v", err)
+	}
+}
+
+func TestExec_ConcurrentWritersWithRetries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultDBName)
+	policy := RetryPolicy{MaxRetries: 50, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	first := openLockTestDB(t, path, policy)
+	if _, err := first.Exec("CREATE TABLE counter (n INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	handles := []*DB{first, openLockTestDB(t, path, policy), openLockTestDB(t, path, policy)}
+
+	const writesPerHandle = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, len(handles)*writesPerHandle)
+	for _, h := range handles {
+		wg.Add(1)
+		go func(h *DB) {
+			defer wg.Done()
+			for i := 0; i < writesPerHandle; i++ {
+				if _, err := h.Exec("INSERT INTO counter (n) VALUES (?)", i); err != nil {
+					errs <- err
+				}
+			}
+		}(h)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent Exec() error = %v", err)
+	}
+
+	var count int
+	if err := handles[0].QueryRow("SELECT COUNT(*) FROM counter").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != len(handles)*writesPerHandle {
+		t.Errorf("rows = %d, want %d", count, len(handles)*writesPerHandle)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}
+	for attempt := 1; attempt <= 5; attempt++ {
+		if d := p.Backoff(attempt); d <= 0 || d > p.MaxDelay {
+			t.Errorf("Backoff(%d) = %v, want within (0, %v]", attempt, d, p.MaxDelay)
+		}
+	}
+	if d := (RetryPolicy{}).Backoff(1); d != 0 {
+		t.Errorf("zero policy Backoff(1) = %v, want 0", d)
+	}
+}
+
+func TestOpenWithOptions_Retry(t *testing.T) {
+	t.Chdir(t.TempDir())
+	custom := RetryPolicy{MaxRetries: 7, BaseDelay: time.Millisecond}
+	database, err := OpenWithOptions(Options{Retry: custom})
+	if err != nil {
+		t.Fatalf("OpenWithOptions() error = %v", err)
+	}
+	defer database.Close()
+	if database.retry != custom {
+		t.Errorf("retry = %+v, want %+v", database.retry, custom)
+	}
+
+	// Each handle keeps its own policy
+	other, err := Open()
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer other.Close()
+	if other.retry != DefaultRetryPolicy {
+		t.Errorf("Open() retry = %+v, want DefaultRetryPolicy", other.retry)
+	}
+}
@@ -13,6 +13,8 @@ CREATE TABLE IF NOT EXISTS urls (
     url_id INTEGER PRIMARY KEY AUTOINCREMENT,
     original_url TEXT NOT NULL UNIQUE,
     canonical_url TEXT,
+    canonical_declared BOOLEAN DEFAULT 0, -- 1 when canonical_url came from <link rel="canonical">
+    key_policy TEXT,              -- urlnorm policy canonical_url was derived under; NULL once declared
     scheme TEXT NOT NULL,
     domain TEXT NOT NULL,
     path TEXT,
@@ -30,13 +32,19 @@ CREATE TABLE IF NOT EXISTS urls (
     has_infobox BOOLEAN DEFAULT 0,
     has_toc BOOLEAN DEFAULT 0,
     has_code_examples BOOLEAN DEFAULT 0,
+    noindex BOOLEAN DEFAULT 0,    -- robots directives from <meta name="robots"> or X-Robots-Tag
+    nofollow BOOLEAN DEFAULT 0,
+
+    -- Freshness: the page's best publication or last-updated date
+    content_date TEXT,            -- ISO-8601 (YYYY-MM-DD or RFC 3339), NULL when undetected
+    content_date_kind TEXT,       -- published, updated
 
     -- Content structure counts
     section_count INTEGER DEFAULT 0,
     citation_count INTEGER DEFAULT 0,
     code_block_count INTEGER DEFAULT 0,
 
-    -- Top keywords as JSON object: {"word1": count1, "word2": count2, ...}
+    -- Top keywords as JSON array of "word:count" strings, highest count first: ["word1:12", "word2:7", ...]
     top_keywords TEXT,
 
     -- Meta keywords as JSON array: ["keyword1", "keyword2", ...] from HTML <meta> tags
@@ -156,7 +164,9 @@ CREATE TABLE IF NOT EXISTS sessions (
     failed_count INTEGER DEFAULT 0,
     features TEXT,
     parse_mode TEXT,
-    session_dir TEXT NOT NULL
+    session_dir TEXT NOT NULL,
+    tag TEXT, -- optional user label (fetch --tag, db tag)
+    raw_stored BOOLEAN DEFAULT 1 -- 0 when fetched with --no-store-raw
 );
 
 CREATE INDEX IF NOT EXISTS idx_sessions_created ON sessions(created_at DESC);
@@ -188,6 +198,7 @@ CREATE TABLE IF NOT EXISTS session_results (
     error_message TEXT,
     file_size_bytes INTEGER,
     estimated_tokens INTEGER,
+    content_hash TEXT,            -- SHA256 of the page's normalized text without boilerplate (NULL for failures)
     FOREIGN KEY (session_id) REFERENCES sessions(session_id) ON DELETE CASCADE,
     FOREIGN KEY (url_id) REFERENCES urls(url_id),
     UNIQUE(session_id, url_id)
@@ -199,6 +210,7 @@ CREATE INDEX IF NOT EXISTS idx_session_results_session ON session_results(sessio
 INSERT OR IGNORE INTO artifact_types (type_name, description) VALUES
     ('html_raw', 'Raw HTML content'),
     ('json_parsed', 'Parsed JSON output from parser'),
+    ('yaml_parsed', 'Parsed YAML output from parser (generic.yaml)'),
     ('keywords', 'Extracted keywords'),
     ('wordcount', 'Word frequency analysis'),
     ('links', 'Extracted links'),
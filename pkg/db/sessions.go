@@ -18,12 +18,19 @@ type Session struct {
 	Features     string
 	ParseMode    string
 	SessionDir   string
+	Tag          string // Empty when untagged
+	RawStored    bool   // False when fetched with --no-store-raw (no raw.html to refresh from)
 }
 
+// sessionColumns are the sessions columns scanned by scanSessions, in order.
+const sessionColumns = `s.session_id, s.created_at, s.url_count, s.success_count, s.failed_count,
+	s.features, s.parse_mode, s.session_dir, COALESCE(s.tag, ''), COALESCE(s.raw_stored, 1)`
+
 // FindOrCreateSession checks if a session exists for this URL set.
 // Returns (session_id, cache_hit, error).
 // If cache_hit is true, the session already exists and is fresh.
 // originalURLs are the URLs before sanitization, urls are after sanitization.
+// URLs that share a url_id (see DedupeURLs) count once.
 func (db *DB) FindOrCreateSession(originalURLs, urls []string, features, parseMode string, maxAge time.Duration) (int64, bool, error) {
 	// Sort URLs for consistency (use sanitized URLs for sorting/matching)
 	sortedURLs := make([]string, len(urls))
@@ -48,14 +55,21 @@ func (db *DB) FindOrCreateSession(originalURLs, urls []string, features, parseMo
 		sortedOriginals[i] = pairs[i].original
 	}
 
-	// Get or insert URL IDs
-	urlIDs := make([]int64, len(sortedURLs))
-	for i, rawURL := range sortedURLs {
-		urlID, err := db.InsertURL(rawURL)
+	// Get or insert URL IDs; a URL sharing its ID with an earlier one is dropped
+	urlIDs := make([]int64, 0, len(pairs))
+	linked := pairs[:0]
+	seen := make(map[int64]bool, len(pairs))
+	for _, pair := range pairs {
+		urlID, err := db.InsertURL(pair.sanitized)
 		if err != nil {
-			return 0, false, fmt.Errorf("failed to insert URL %s: %w", rawURL, err)
+			return 0, false, fmt.Errorf("failed to insert URL %s: %w", pair.sanitized, err)
 		}
-		urlIDs[i] = urlID
+		if seen[urlID] {
+			continue
+		}
+		seen[urlID] = true
+		urlIDs = append(urlIDs, urlID)
+		linked = append(linked, pair)
 	}
 
 	// Find matching session
@@ -79,14 +93,14 @@ func (db *DB) FindOrCreateSession(originalURLs, urls []string, features, parseMo
 	}
 
 	// Create new session
-	sessionID, err = db.createSession(len(urls), features, parseMode)
+	sessionID, err = db.createSession(len(urlIDs), features, parseMode)
 	if err != nil {
 		return 0, false, err
 	}
 
 	// Link URLs to session with sanitization tracking
 	for i, urlID := range urlIDs {
-		if err := db.InsertSessionURL(sessionID, urlID, sortedOriginals[i], sortedURLs[i]); err != nil {
+		if err := db.InsertSessionURL(sessionID, urlID, linked[i].original, linked[i].sanitized); err != nil {
 			return 0, false, err
 		}
 	}
@@ -140,6 +154,88 @@ func (db *DB) findSessionByURLs(urlIDs []int64) (sessionID int64, createdAt time
 	return sessionID, createdAt, true, nil
 }
 
+// DedupeURLs finds the urls that are stored under the same url_id as an earlier one in
+// the list: the same page under another scheme, fragment or tracking parameters, per
+// the URL key policy. Their indexes are returned in duplicates and the rest in kept.
+// Every URL is inserted, as FindOrCreateSession would.
+func (db *DB) DedupeURLs(urls []string) (kept, duplicates []int, err error) {
+	seen := make(map[int64]bool, len(urls))
+	for i, rawURL := range urls {
+		urlID, err := db.InsertURL(rawURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to insert URL %s: %w", rawURL, err)
+		}
+		if seen[urlID] {
+			duplicates = append(duplicates, i)
+			continue
+		}
+		seen[urlID] = true
+		kept = append(kept, i)
+	}
+	return kept, duplicates, nil
+}
+
+// CreateSession starts a new session over already-inserted URLs. Unlike
+// FindOrCreateSession it never reuses a session with the same URL set; imports
+// that don't fetch use it.
+func (db *DB) CreateSession(urlIDs []int64, features, parseMode string) (int64, error) {
+	sessionID, err := db.createSession(len(urlIDs), features, parseMode)
+	if err != nil {
+		return 0, err
+	}
+	for _, urlID := range urlIDs {
+		if err := db.InsertSessionURL(sessionID, urlID, "", ""); err != nil {
+			return 0, err
+		}
+	}
+	return sessionID, nil
+}
+
+// AppendSessionURLs adds URLs to an existing session, for building a corpus up run by
+// run. originalURLs are the URLs before sanitization, urls after. URLs already in the
+// session, or repeated in the list, are not added: their indexes are returned in
+// duplicates, and the indexes of the URLs that were added in added. The session's
+// url_count becomes its new total, so a later fetch of the whole set finds it.
+func (db *DB) AppendSessionURLs(sessionID int64, originalURLs, urls []string) (added, duplicates []int, err error) {
+	if _, err := db.GetSessionByID(sessionID); err != nil {
+		return nil, nil, err
+	}
+
+	seen := make(map[int64]bool)
+	for i, rawURL := range urls {
+		urlID, err := db.InsertURL(rawURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to insert URL %s: %w", rawURL, err)
+		}
+
+		var inSession bool
+		if err := db.QueryRow(`
+			SELECT EXISTS (SELECT 1 FROM session_urls WHERE session_id = ? AND url_id = ?)
+		`, sessionID, urlID).Scan(&inSession); err != nil {
+			return nil, nil, fmt.Errorf("failed to check session URL: %w", err)
+		}
+		if inSession || seen[urlID] {
+			duplicates = append(duplicates, i)
+			continue
+		}
+		seen[urlID] = true
+
+		if err := db.InsertSessionURL(sessionID, urlID, originalURLs[i], rawURL); err != nil {
+			return nil, nil, err
+		}
+		added = append(added, i)
+	}
+
+	if _, err := db.Exec(`
+		UPDATE sessions
+		SET url_count = (SELECT COUNT(*) FROM session_urls WHERE session_id = ?)
+		WHERE session_id = ?
+	`, sessionID, sessionID); err != nil {
+		return nil, nil, fmt.Errorf("failed to update session url_count: %w", err)
+	}
+	return added, duplicates, nil
+}
+
 // createSession creates a new session record
 func (db *DB) createSession(urlCount int, features, parseMode string) (int64, error) {
 	// Generate session directory name (will be updated later with actual timestamp)
@@ -191,17 +287,157 @@ func (db *DB) InsertSessionURL(sessionID, urlID int64, originalURL, sanitizedURL
 }
 
 // InsertSessionResult records a result for a URL in a session
-func (db *DB) InsertSessionResult(sessionID, urlID int64, status string, statusCode int, errorType, errorMessage string, fileSizeBytes int64, estimatedTokens int) error {
+func (db *DB) InsertSessionResult(sessionID, urlID int64, status string, statusCode int, errorType, errorMessage string, fileSizeBytes int64, estimatedTokens int, contentHash string) error {
 	_, err := db.Exec(`
-		INSERT INTO session_results (session_id, url_id, status, status_code, error_type, error_message, file_size_bytes, estimated_tokens)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, sessionID, urlID, status, statusCode, errorType, errorMessage, fileSizeBytes, estimatedTokens)
+		INSERT INTO session_results (session_id, url_id, status, status_code, error_type, error_message, file_size_bytes, estimated_tokens, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, sessionID, urlID, status, statusCode, errorType, errorMessage, fileSizeBytes, estimatedTokens, NewNullString(contentHash))
 	if err != nil {
 		return fmt.Errorf("failed to insert session result: %w", err)
 	}
 	return nil
 }
 
+// FindPreviousSession returns the most recent session created before sessionID with
+// exactly the same URL set, as a stale re-run of the same fetch creates.
+func (db *DB) FindPreviousSession(sessionID int64) (int64, bool, error) {
+	var previousID int64
+	err := db.QueryRow(`
+		SELECT p.session_id
+		FROM sessions p
+		JOIN sessions s ON s.session_id = ?
+		WHERE p.session_id < s.session_id
+		  AND p.url_count = s.url_count
+		  AND (SELECT COUNT(*) FROM session_urls pu
+		       JOIN session_urls su ON su.url_id = pu.url_id AND su.session_id = s.session_id
+		       WHERE pu.session_id = p.session_id) = s.url_count
+		ORDER BY p.session_id DESC
+		LIMIT 1
+	`, sessionID).Scan(&previousID)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find previous session: %w", err)
+	}
+	return previousID, true, nil
+}
+
+// GetSessionStatuses returns each URL's result status (success or failed) in a session, by URL ID.
+func (db *DB) GetSessionStatuses(sessionID int64) (map[int64]string, error) {
+	rows, err := db.Query("SELECT url_id, status FROM session_results WHERE session_id = ?", sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session statuses: %w", err)
+	}
+	defer rows.Close()
+
+	statuses := make(map[int64]string)
+	for rows.Next() {
+		var urlID int64
+		var status string
+		if err := rows.Scan(&urlID, &status); err != nil {
+			return nil, fmt.Errorf("failed to scan session status: %w", err)
+		}
+		statuses[urlID] = status
+	}
+	return statuses, rows.Err()
+}
+
+// GetURLSessions returns, by URL ID, the sessions each URL belongs to, oldest first.
+func (db *DB) GetURLSessions() (map[int64][]int64, error) {
+	rows, err := db.Query("SELECT DISTINCT url_id, session_id FROM session_urls ORDER BY url_id, session_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get URL sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make(map[int64][]int64)
+	for rows.Next() {
+		var urlID, sessionID int64
+		if err := rows.Scan(&urlID, &sessionID); err != nil {
+			return nil, fmt.Errorf("failed to scan URL session: %w", err)
+		}
+		sessions[urlID] = append(sessions[urlID], sessionID)
+	}
+	return sessions, rows.Err()
+}
+
+// GetSessionParsedHashes returns the content hashes of the parsed page artifacts
+// (yaml_parsed, json_parsed) stored for a session's URLs, by URL ID then type name.
+func (db *DB) GetSessionParsedHashes(sessionID int64) (map[int64]map[string]string, error) {
+	rows, err := db.Query(`
+		SELECT su.url_id, at.type_name, a.content_hash
+		FROM session_urls su
+		JOIN artifacts a ON a.url_id = su.url_id
+		JOIN artifact_types at ON a.type_id = at.type_id
+		WHERE su.session_id = ? AND at.type_name IN ('yaml_parsed', 'json_parsed')
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parsed artifact hashes: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[int64]map[string]string)
+	for rows.Next() {
+		var urlID int64
+		var typeName, hash string
+		if err := rows.Scan(&urlID, &typeName, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan parsed artifact hash: %w", err)
+		}
+		if hashes[urlID] == nil {
+			hashes[urlID] = make(map[string]string)
+		}
+		hashes[urlID][typeName] = hash
+	}
+	return hashes, rows.Err()
+}
+
+// ContentHashChange compares a URL's content hash in an earlier session with its hash in
+// the latest later session that fetched it successfully.
+type ContentHashChange struct {
+	URLID         int64
+	URL           string
+	SinceSession  int64
+	SinceHash     string
+	LatestSession int64
+	LatestHash    string
+}
+
+// Changed reports whether the content differs between the two sessions.
+func (c ContentHashChange) Changed() bool {
+	return c.SinceHash != c.LatestHash
+}
+
+// CompareContentHashes returns, for every URL hashed in sinceSessionID and again in a later
+// session, its hash then and in the most recent of those later sessions, ordered by URL ID.
+func (db *DB) CompareContentHashes(sinceSessionID int64) ([]ContentHashChange, error) {
+	rows, err := db.Query(`
+		SELECT u.url_id, u.original_url, old.content_hash, new.session_id, new.content_hash
+		FROM session_results old
+		JOIN urls u ON u.url_id = old.url_id
+		JOIN session_results new ON new.url_id = old.url_id
+		WHERE old.session_id = ? AND old.content_hash IS NOT NULL
+		  AND new.session_id = (
+		      SELECT MAX(session_id) FROM session_results
+		      WHERE url_id = old.url_id AND session_id > old.session_id AND content_hash IS NOT NULL)
+		ORDER BY u.url_id
+	`, sinceSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare content hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []ContentHashChange
+	for rows.Next() {
+		c := ContentHashChange{SinceSession: sinceSessionID}
+		if err := rows.Scan(&c.URLID, &c.URL, &c.SinceHash, &c.LatestSession, &c.LatestHash); err != nil {
+			return nil, fmt.Errorf("failed to scan content hash: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
 // UpdateSessionStats updates the success and failed counts for a session
 func (db *DB) UpdateSessionStats(sessionID int64, successCount, failedCount int) error {
 	_, err := db.Exec(`
@@ -219,9 +455,9 @@ func (db *DB) UpdateSessionStats(sessionID int64, successCount, failedCount int)
 func (db *DB) GetSessionByID(sessionID int64) (*Session, error) {
 	var session Session
 	err := db.QueryRow(`
-		SELECT session_id, created_at, url_count, success_count, failed_count, features, parse_mode, session_dir
-		FROM sessions
-		WHERE session_id = ?
+		SELECT `+sessionColumns+`
+		FROM sessions s
+		WHERE s.session_id = ?
 	`, sessionID).Scan(
 		&session.SessionID,
 		&session.CreatedAt,
@@ -231,6 +467,8 @@ func (db *DB) GetSessionByID(sessionID int64) (*Session, error) {
 		&session.Features,
 		&session.ParseMode,
 		&session.SessionDir,
+		&session.Tag,
+		&session.RawStored,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session %d not found", sessionID)
@@ -241,6 +479,26 @@ func (db *DB) GetSessionByID(sessionID int64) (*Session, error) {
 	return &session, nil
 }
 
+// SetSessionTag labels a session; an empty tag removes the label.
+func (db *DB) SetSessionTag(sessionID int64, tag string) error {
+	result, err := db.Exec("UPDATE sessions SET tag = ? WHERE session_id = ?", NewNullString(tag), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to tag session: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("session %d not found", sessionID)
+	}
+	return nil
+}
+
+// SetSessionRawStored records whether a session's run kept the raw HTML it fetched.
+func (db *DB) SetSessionRawStored(sessionID int64, stored bool) error {
+	if _, err := db.Exec("UPDATE sessions SET raw_stored = ? WHERE session_id = ?", stored, sessionID); err != nil {
+		return fmt.Errorf("failed to update session raw_stored: %w", err)
+	}
+	return nil
+}
+
 // GetSessionURLs retrieves all URLs for a session
 func (db *DB) GetSessionURLs(sessionID int64) ([]URLInfo, error) {
 	rows, err := db.Query(`
@@ -315,61 +573,90 @@ func (db *DB) GetSessionResults(sessionID int64) ([]SessionResult, error) {
 
 // ListSessions retrieves all sessions ordered by most recent first
 func (db *DB) ListSessions(limit int) ([]Session, error) {
-	query := `
-		SELECT session_id, created_at, url_count, success_count, failed_count,
-		       features, parse_mode, session_dir
-		FROM sessions
-		ORDER BY created_at DESC
-	`
+	return db.ListSessionsByTag("", limit)
+}
+
+// ListSessionsByTag retrieves sessions labelled tag (all sessions if tag is empty),
+// most recent first.
+func (db *DB) ListSessionsByTag(tag string, limit int) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions s`
+	var args []interface{}
+	if tag != "" {
+		query += " WHERE s.tag = ?"
+		args = append(args, tag)
+	}
+	query += " ORDER BY s.created_at DESC"
 	if limit > 0 {
 		query += fmt.Sprintf(" LIMIT %d", limit)
 	}
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
 	defer rows.Close()
 
-	var sessions []Session
-	for rows.Next() {
-		var s Session
-		if err := rows.Scan(&s.SessionID, &s.CreatedAt, &s.URLCount, &s.SuccessCount,
-			&s.FailedCount, &s.Features, &s.ParseMode, &s.SessionDir); err != nil {
-			return nil, fmt.Errorf("failed to scan session: %w", err)
-		}
-		sessions = append(sessions, s)
-	}
+	return scanSessions(rows)
+}
 
-	return sessions, nil
+// SessionFilter narrows QuerySessions; zero-valued fields don't filter. All set
+// fields must match.
+type SessionFilter struct {
+	TodayOnly   bool
+	FailedOnly  bool
+	URLPattern  string    // LIKE match against any URL in the session
+	Tag         string    // Exact session tag
+	ContentType string    // Session contains at least one URL of this content type
+	Since       time.Time // Created at or after
+	Until       time.Time // Created before
 }
 
 // QuerySessions filters sessions based on criteria
-func (db *DB) QuerySessions(todayOnly bool, failedOnly bool, urlPattern string) ([]Session, error) {
+func (db *DB) QuerySessions(filter SessionFilter) ([]Session, error) {
 	query := `
-		SELECT DISTINCT s.session_id, s.created_at, s.url_count, s.success_count,
-		       s.failed_count, s.features, s.parse_mode, s.session_dir
+		SELECT DISTINCT ` + sessionColumns + `
 		FROM sessions s
 	`
 
 	var conditions []string
 	var args []interface{}
 
-	if todayOnly {
+	if filter.TodayOnly {
 		conditions = append(conditions, "DATE(s.created_at) = DATE('now')")
 	}
 
-	if failedOnly {
+	if filter.FailedOnly {
 		conditions = append(conditions, "s.failed_count > 0")
 	}
 
-	if urlPattern != "" {
+	if filter.Tag != "" {
+		conditions = append(conditions, "s.tag = ?")
+		args = append(args, filter.Tag)
+	}
+
+	// created_at is CURRENT_TIMESTAMP text (UTC), so bounds compare as text in the same layout
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "s.created_at >= ?")
+		args = append(args, filter.Since.UTC().Format(time.DateTime))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "s.created_at < ?")
+		args = append(args, filter.Until.UTC().Format(time.DateTime))
+	}
+
+	if filter.URLPattern != "" || filter.ContentType != "" {
 		query += `
 		JOIN session_urls su ON s.session_id = su.session_id
 		JOIN urls u ON su.url_id = u.url_id
 		`
+	}
+	if filter.URLPattern != "" {
 		conditions = append(conditions, "u.original_url LIKE ?")
-		args = append(args, "%"+urlPattern+"%")
+		args = append(args, "%"+filter.URLPattern+"%")
+	}
+	if filter.ContentType != "" {
+		conditions = append(conditions, "u.content_type = ?")
+		args = append(args, filter.ContentType)
 	}
 
 	if len(conditions) > 0 {
@@ -384,16 +671,20 @@ func (db *DB) QuerySessions(todayOnly bool, failedOnly bool, urlPattern string)
 	}
 	defer rows.Close()
 
+	return scanSessions(rows)
+}
+
+// scanSessions reads rows selected with sessionColumns.
+func scanSessions(rows *sql.Rows) ([]Session, error) {
 	var sessions []Session
 	for rows.Next() {
 		var s Session
 		if err := rows.Scan(&s.SessionID, &s.CreatedAt, &s.URLCount, &s.SuccessCount,
-			&s.FailedCount, &s.Features, &s.ParseMode, &s.SessionDir); err != nil {
+			&s.FailedCount, &s.Features, &s.ParseMode, &s.SessionDir, &s.Tag, &s.RawStored); err != nil {
 			return nil, fmt.Errorf("failed to scan session: %w", err)
 		}
 		sessions = append(sessions, s)
 	}
-
 	return sessions, nil
 }
 
@@ -433,6 +724,10 @@ type URLWithMetadata struct {
 	TopKeywords  []string // Word-frequency extracted keywords
 	MetaKeywords []string // Author-supplied meta tag keywords
 
+	// Freshness
+	ContentDate     string // ISO-8601 publication or last-updated date ("" when undetected)
+	ContentDateKind string // published, updated
+
 	// Session-specific data
 	EstimatedTokens int
 }
@@ -487,6 +782,8 @@ func (db *DB) GetSessionURLsWithMetadata(sessionID int64) ([]URLWithMetadata, er
 			COALESCE(u.code_block_count, 0),
 			COALESCE(u.top_keywords, '[]'),
 			COALESCE(u.meta_keywords, '[]'),
+			COALESCE(u.content_date, ''),
+			COALESCE(u.content_date_kind, ''),
 			COALESCE(sr.estimated_tokens, 0)
 		FROM urls u
 		JOIN session_urls su ON u.url_id = su.url_id
@@ -525,6 +822,8 @@ func (db *DB) GetSessionURLsWithMetadata(sessionID int64) ([]URLWithMetadata, er
 			&u.CodeBlockCount,
 			&topKeywordsJSON,
 			&metaKeywordsJSON,
+			&u.ContentDate,
+			&u.ContentDateKind,
 			&u.EstimatedTokens,
 		)
 		if err != nil {
@@ -170,7 +170,7 @@ func TestInsertSessionResult(t *testing.T) {
 	sessionID, _, _ := db.FindOrCreateSession([]string{"https://example.com"}, []string{"https://example.com"}, "", "", 1*time.Hour)
 
 	// Insert result
-	err := db.InsertSessionResult(sessionID, urlID, "success", 200, "", "", 1024, 256)
+	err := db.InsertSessionResult(sessionID, urlID, "success", 200, "", "", 1024, 256, "")
 	if err != nil {
 		t.Fatalf("InsertSessionResult() error = %v", err)
 	}
@@ -229,6 +229,32 @@ func TestUpdateSessionStats(t *testing.T) {
 	}
 }
 
+func TestSetSessionRawStored(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sessionID, _, _ := db.FindOrCreateSession([]string{"https://example.com"}, []string{"https://example.com"}, "", "", 1*time.Hour)
+
+	session, err := db.GetSessionByID(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionByID() error = %v", err)
+	}
+	if !session.RawStored {
+		t.Error("new session RawStored = false, want true by default")
+	}
+
+	if err := db.SetSessionRawStored(sessionID, false); err != nil {
+		t.Fatalf("SetSessionRawStored() error = %v", err)
+	}
+	sessions, err := db.ListSessions(1)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].RawStored {
+		t.Errorf("ListSessions() = %+v, want RawStored false", sessions)
+	}
+}
+
 func TestGetSessionURLs(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -259,6 +285,108 @@ func TestGetSessionURLs(t *testing.T) {
 	}
 }
 
+func TestAppendSessionURLs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"https://example.com", "https://example.org"}
+	sessionID, _, _ := db.FindOrCreateSession(urls, urls, "", "", 1*time.Hour)
+
+	appended := []string{"https://example.net", "https://example.com", "https://example.io", "https://example.net"}
+	added, duplicates, err := db.AppendSessionURLs(sessionID, appended, appended)
+	if err != nil {
+		t.Fatalf("AppendSessionURLs() error = %v", err)
+	}
+
+	if len(added) != 2 || added[0] != 0 || added[1] != 2 {
+		t.Errorf("added = %v, want [0 2]", added)
+	}
+	if len(duplicates) != 2 || duplicates[0] != 1 || duplicates[1] != 3 {
+		t.Errorf("duplicates = %v, want [1 3]", duplicates)
+	}
+
+	session, err := db.GetSessionByID(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionByID() error = %v", err)
+	}
+	if session.URLCount != 4 {
+		t.Errorf("session.URLCount = %d, want 4", session.URLCount)
+	}
+
+	sessionURLs, err := db.GetSessionURLs(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionURLs() error = %v", err)
+	}
+	if len(sessionURLs) != 4 {
+		t.Errorf("got %d session URLs, want 4", len(sessionURLs))
+	}
+
+	// Fetching the grown set again finds the same session
+	all := []string{"https://example.com", "https://example.org", "https://example.net", "https://example.io"}
+	foundID, cacheHit, err := db.FindOrCreateSession(all, all, "", "", 1*time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	if !cacheHit || foundID != sessionID {
+		t.Errorf("FindOrCreateSession() = %d, %v; want %d, true", foundID, cacheHit, sessionID)
+	}
+
+	if _, _, err := db.AppendSessionURLs(sessionID+100, appended, appended); err == nil {
+		t.Error("AppendSessionURLs() on a missing session succeeded, want error")
+	}
+}
+
+func TestFindOrCreateSession_SharedURLKey(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// Both are stored under one url_id, so the session holds a single URL
+	urls := []string{"http://example.com/b", "https://example.com/b", "https://example.com/c#top"}
+	sessionID, cacheHit, err := db.FindOrCreateSession(urls, urls, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	if cacheHit {
+		t.Error("FindOrCreateSession() cacheHit = true, want a new session")
+	}
+
+	sessionURLs, err := db.GetSessionURLs(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionURLs() error = %v", err)
+	}
+	session, err := db.GetSessionByID(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionByID() error = %v", err)
+	}
+	if len(sessionURLs) != 2 || session.URLCount != 2 {
+		t.Errorf("session has %d URLs, url_count %d; want 2 and 2", len(sessionURLs), session.URLCount)
+	}
+
+	// The same set, spelled either way, finds the session again
+	again := []string{"https://example.com/b", "https://example.com/c", "http://example.com/b"}
+	foundID, cacheHit, err := db.FindOrCreateSession(again, again, "", "", time.Hour)
+	if err != nil || !cacheHit || foundID != sessionID {
+		t.Errorf("FindOrCreateSession(again) = %d, %v, %v; want %d, true", foundID, cacheHit, err, sessionID)
+	}
+}
+
+func TestDedupeURLs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"http://example.com/b", "https://example.com/a", "https://example.com/b", "https://example.com/a#2"}
+	kept, duplicates, err := db.DedupeURLs(urls)
+	if err != nil {
+		t.Fatalf("DedupeURLs() error = %v", err)
+	}
+	if len(kept) != 2 || kept[0] != 0 || kept[1] != 1 {
+		t.Errorf("kept = %v, want [0 1]", kept)
+	}
+	if len(duplicates) != 2 || duplicates[0] != 2 || duplicates[1] != 3 {
+		t.Errorf("duplicates = %v, want [2 3]", duplicates)
+	}
+}
+
 func TestSessionDir_Naming(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -285,3 +413,300 @@ func TestSessionDir_Naming(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[:len(substr)] == substr
 }
+
+func TestGetKeywordDocFrequency(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	sessionID, _, err := db.FindOrCreateSession(urls, urls, "wordcount", "cheap", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+
+	keywords := []string{`["go:5","api:2"]`, `["rust:7","go:1"]`, `["go:3","api:1"]`}
+	for i, rawURL := range urls {
+		urlID, err := db.GetURLID(rawURL)
+		if err != nil {
+			t.Fatalf("GetURLID(%s) error = %v", rawURL, err)
+		}
+		if err := db.UpdateURLContentType(urlID, ContentTypeInfo{TopKeywords: NewNullString(keywords[i])}); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	got, err := db.GetKeywordDocFrequency(sessionID, 1)
+	if err != nil {
+		t.Fatalf("GetKeywordDocFrequency() error = %v", err)
+	}
+	want := []KeywordFrequency{
+		{Word: "go", TotalCount: 9, DocCount: 3},
+		{Word: "api", TotalCount: 3, DocCount: 2},
+		{Word: "rust", TotalCount: 7, DocCount: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GetKeywordDocFrequency() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("keyword %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	got, err = db.GetKeywordDocFrequency(sessionID, 2)
+	if err != nil {
+		t.Fatalf("GetKeywordDocFrequency(min 2) error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("GetKeywordDocFrequency(min 2) = %+v, want go and api", got)
+	}
+}
+
+func TestSetSessionTag(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	research, _, err := db.FindOrCreateSession([]string{"https://example.com/paper"}, []string{"https://example.com/paper"}, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	other, _, err := db.FindOrCreateSession([]string{"https://example.org"}, []string{"https://example.org"}, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+
+	if err := db.SetSessionTag(research, "research"); err != nil {
+		t.Fatalf("SetSessionTag() error = %v", err)
+	}
+	if err := db.SetSessionTag(9999, "research"); err == nil {
+		t.Error("SetSessionTag(missing session) error = nil, want not found")
+	}
+
+	tagged, err := db.ListSessionsByTag("research", 0)
+	if err != nil {
+		t.Fatalf("ListSessionsByTag() error = %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].SessionID != research || tagged[0].Tag != "research" {
+		t.Errorf("ListSessionsByTag(research) = %+v, want only session %d", tagged, research)
+	}
+
+	queried, err := db.QuerySessions(SessionFilter{URLPattern: "example.com", Tag: "research"})
+	if err != nil {
+		t.Fatalf("QuerySessions() error = %v", err)
+	}
+	if len(queried) != 1 || queried[0].SessionID != research {
+		t.Errorf("QuerySessions(tag research) = %+v, want session %d", queried, research)
+	}
+
+	// An empty tag clears the label
+	if err := db.SetSessionTag(research, ""); err != nil {
+		t.Fatalf("SetSessionTag(\"\") error = %v", err)
+	}
+	session, err := db.GetSessionByID(research)
+	if err != nil {
+		t.Fatalf("GetSessionByID() error = %v", err)
+	}
+	if session.Tag != "" {
+		t.Errorf("Tag = %q after clearing, want empty", session.Tag)
+	}
+	if all, _ := db.ListSessionsByTag("", 0); len(all) != 2 {
+		t.Errorf("ListSessionsByTag(\"\") returned %d sessions, want 2 (other = %d)", len(all), other)
+	}
+}
+
+func TestQuerySessions_DateRangeAndContentType(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	paper := []string{"https://arxiv.org/abs/1"}
+	old, _, err := db.FindOrCreateSession(paper, paper, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	blog := []string{"https://example.com/post"}
+	recent, _, err := db.FindOrCreateSession(blog, blog, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+
+	if _, err := db.Exec("UPDATE sessions SET created_at = '2026-01-05 10:00:00' WHERE session_id = ?", old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE sessions SET created_at = '2026-02-20 10:00:00' WHERE session_id = ?", recent); err != nil {
+		t.Fatal(err)
+	}
+	paperID, _ := db.GetURLID(paper[0])
+	if err := db.UpdateURLContentType(paperID, ContentTypeInfo{ContentType: NewNullString("academic")}); err != nil {
+		t.Fatal(err)
+	}
+
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		name   string
+		filter SessionFilter
+		want   []int64
+	}{
+		{"since", SessionFilter{Since: date("2026-02-01")}, []int64{recent}},
+		{"until", SessionFilter{Until: date("2026-02-01")}, []int64{old}},
+		{"range excludes both", SessionFilter{Since: date("2026-01-06"), Until: date("2026-02-20")}, nil},
+		{"content type", SessionFilter{ContentType: "academic"}, []int64{old}},
+		{"content type and since", SessionFilter{ContentType: "academic", Since: date("2026-02-01")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := db.QuerySessions(tt.filter)
+			if err != nil {
+				t.Fatalf("QuerySessions() error = %v", err)
+			}
+			var got []int64
+			for _, s := range sessions {
+				got = append(got, s.SessionID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("QuerySessions(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPreviousSession(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"https://example.com/a", "https://example.com/b"}
+	first, _, err := db.FindOrCreateSession(urls, urls, "", "", time.Nanosecond)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+	// A superset of the same URLs is a different session, not a re-run
+	superset := append([]string{"https://example.com/c"}, urls...)
+	if _, _, err := db.FindOrCreateSession(superset, superset, "", "", time.Nanosecond); err != nil {
+		t.Fatalf("FindOrCreateSession(superset) error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	second, cacheHit, err := db.FindOrCreateSession(urls, urls, "", "", time.Nanosecond)
+	if err != nil || cacheHit {
+		t.Fatalf("FindOrCreateSession(stale) = %d, %v, %v; want a new session", second, cacheHit, err)
+	}
+
+	previous, found, err := db.FindPreviousSession(second)
+	if err != nil || !found || previous != first {
+		t.Errorf("FindPreviousSession(%d) = %d, %v, %v; want %d", second, previous, found, err, first)
+	}
+	if _, found, err := db.FindPreviousSession(first); err != nil || found {
+		t.Errorf("FindPreviousSession(first) found = %v, err = %v; want none", found, err)
+	}
+
+	urlID, _ := db.GetURLID(urls[0])
+	if err := db.InsertSessionResult(first, urlID, "failed", 0, "fetch_error", "boom", 0, 0, ""); err != nil {
+		t.Fatalf("InsertSessionResult() error = %v", err)
+	}
+	statuses, err := db.GetSessionStatuses(first)
+	if err != nil || len(statuses) != 1 || statuses[urlID] != "failed" {
+		t.Errorf("GetSessionStatuses() = %v, %v; want URL %d failed", statuses, err, urlID)
+	}
+}
+
+func TestGetConfidenceHistogram(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c", "https://example.com/d"}
+	sessionID, _, err := db.FindOrCreateSession(urls, urls, "", "", time.Hour)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() error = %v", err)
+	}
+
+	infos := []ContentTypeInfo{
+		{ContentType: NewNullString("docs"), DetectionConfidence: NewNullFloat64(9.5)},
+		{ContentType: NewNullString("docs"), DetectionConfidence: NewNullFloat64(2)},
+		{ContentType: NewNullString("docs"), DetectionConfidence: NewNullFloat64(10)},
+		{}, // never classified
+	}
+	for i, rawURL := range urls {
+		urlID, _ := db.GetURLID(rawURL)
+		if err := db.UpdateURLContentType(urlID, infos[i]); err != nil {
+			t.Fatalf("UpdateURLContentType() error = %v", err)
+		}
+	}
+
+	got, err := db.GetConfidenceHistogram(sessionID)
+	if err != nil {
+		t.Fatalf("GetConfidenceHistogram() error = %v", err)
+	}
+	want := []ConfidenceBucketCount{
+		{ContentType: "docs", Bucket: "2-4", Count: 1},
+		{ContentType: "docs", Bucket: "8-10", Count: 2},
+		{ContentType: "unknown", Bucket: UnscoredBucket, Count: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GetConfidenceHistogram() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("bucket %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCompareContentHashes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urls := []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	sessions := make([]int64, 3)
+	for i := range sessions {
+		id, _, err := db.FindOrCreateSession(urls, urls, "", "", time.Nanosecond)
+		if err != nil {
+			t.Fatalf("FindOrCreateSession() error = %v", err)
+		}
+		sessions[i] = id
+	}
+	ids := make([]int64, len(urls))
+	for i, u := range urls {
+		ids[i], _ = db.GetURLID(u)
+	}
+
+	// a changes in the last session; b is unchanged; c failed later and is never re-hashed
+	results := []struct {
+		session int64
+		url     int
+		hash    string
+	}{
+		{sessions[0], 0, "a1"}, {sessions[0], 1, "b1"}, {sessions[0], 2, "c1"},
+		{sessions[1], 0, "a1"}, {sessions[1], 1, "b1"},
+		{sessions[2], 0, "a2"}, {sessions[2], 1, "b1"},
+	}
+	for _, r := range results {
+		if err := db.InsertSessionResult(r.session, ids[r.url], "success", 200, "", "", 0, 0, r.hash); err != nil {
+			t.Fatalf("InsertSessionResult() error = %v", err)
+		}
+	}
+	if err := db.InsertSessionResult(sessions[2], ids[2], "failed", 0, "fetch_error", "boom", 0, 0, ""); err != nil {
+		t.Fatalf("InsertSessionResult() error = %v", err)
+	}
+
+	got, err := db.CompareContentHashes(sessions[0])
+	if err != nil {
+		t.Fatalf("CompareContentHashes() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("CompareContentHashes() = %+v, want a and b compared", got)
+	}
+	if got[0].URLID != ids[0] || !got[0].Changed() || got[0].LatestSession != sessions[2] || got[0].LatestHash != "a2" {
+		t.Errorf("a = %+v, want changed to a2 in session %d", got[0], sessions[2])
+	}
+	if got[1].URLID != ids[1] || got[1].Changed() {
+		t.Errorf("b = %+v, want unchanged", got[1])
+	}
+
+	if got, err := db.CompareContentHashes(sessions[2]); err != nil || len(got) != 0 {
+		t.Errorf("CompareContentHashes(latest) = %+v, %v; want nothing to compare", got, err)
+	}
+}
@@ -147,3 +147,43 @@ func TestRecordAccess_MultipleURLs(t *testing.T) {
 		t.Error("url2 success = true, want false")
 	}
 }
+
+func TestGetAccessHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urlID, _ := db.InsertURL("https://example.com/flaky")
+	other, _ := db.InsertURL("https://example.com/other")
+
+	db.RecordAccess(urlID, 200, "", true)
+	db.RecordAccess(urlID, 503, "fetch_error", false)
+	db.RecordAccess(urlID, 200, "", true)
+	db.RecordAccess(other, 404, "fetch_error", false)
+
+	history, err := db.GetAccessHistory(urlID, 0)
+	if err != nil {
+		t.Fatalf("GetAccessHistory() failed: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("GetAccessHistory() returned %d records, want 3", len(history))
+	}
+
+	// Newest first, even when accesses share a timestamp
+	want := []int{200, 503, 200}
+	for i, record := range history {
+		if record.StatusCode != want[i] {
+			t.Errorf("history[%d].StatusCode = %d, want %d", i, record.StatusCode, want[i])
+		}
+	}
+	if history[1].ErrorType != "fetch_error" || history[1].Success {
+		t.Errorf("history[1] = %+v, want failed fetch_error", history[1])
+	}
+
+	limited, err := db.GetAccessHistory(urlID, 2)
+	if err != nil {
+		t.Fatalf("GetAccessHistory(limit 2) failed: %v", err)
+	}
+	if len(limited) != 2 || limited[0].AccessID != history[0].AccessID {
+		t.Errorf("GetAccessHistory(limit 2) = %+v, want the 2 newest records", limited)
+	}
+}
@@ -2,6 +2,8 @@ package db
 
 import (
 	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/urlnorm"
 )
 
 // setupTestDB creates an in-memory SQLite database for testing
@@ -9,7 +11,7 @@ func setupTestDB(t *testing.T) *DB {
 	t.Helper()
 
 	// Use in-memory database for tests
-	database := &DB{path: ":memory:"}
+	database := &DB{path: ":memory:", retry: DefaultRetryPolicy}
 	var err error
 	database.DB, err = openDB(":memory:")
 	if err != nil {
@@ -159,6 +161,92 @@ func TestInsertURL_QueryParams(t *testing.T) {
 	}
 }
 
+func TestInsertURL_TrackingParams(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	clean := []string{"https://example.com/item?id=1"}
+	sessionID, _, err := db.FindOrCreateSession(clean, clean, "", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession() failed: %v", err)
+	}
+	urlID, err := db.GetURLID(clean[0])
+	if err != nil {
+		t.Fatalf("GetURLID() failed: %v", err)
+	}
+
+	// Tracking parameters don't change the page: same url_id and the same session
+	tracked := []string{"http://example.com/item?utm_source=news&id=1&fbclid=abc"}
+	trackedID, err := db.InsertURL(tracked[0])
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	if trackedID != urlID {
+		t.Errorf("InsertURL(tracked) = %d, want url_id %d", trackedID, urlID)
+	}
+	gotSession, cacheHit, err := db.FindOrCreateSession(tracked, tracked, "", "minimal", 0)
+	if err != nil || !cacheHit || gotSession != sessionID {
+		t.Errorf("FindOrCreateSession(tracked) = %d, %v, %v, want cache hit on session %d", gotSession, cacheHit, err, sessionID)
+	}
+
+	// A significant parameter does
+	otherID, err := db.InsertURL("https://example.com/item?id=2&utm_source=news")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	if otherID == urlID {
+		t.Error("InsertURL() merged URLs that differ in a significant parameter")
+	}
+
+	// A URL and its tracked copy in one request share a url_id: one session URL, no error
+	pair := []string{"https://example.com/a?utm_source=n", "https://example.com/a"}
+	pairSession, _, err := db.FindOrCreateSession(pair, pair, "", "minimal", 0)
+	if err != nil {
+		t.Fatalf("FindOrCreateSession(pair) failed: %v", err)
+	}
+	if sessionURLs, err := db.GetSessionURLs(pairSession); err != nil || len(sessionURLs) != 1 {
+		t.Errorf("GetSessionURLs(pair) = %v, %v, want one URL", sessionURLs, err)
+	}
+}
+
+func TestInsertURL_KeyPolicy(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	urlID, err := db.InsertURL("https://example.com/item?id=1&utm_source=news")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+
+	// Under another policy the stored key means something else, so it is not reused
+	urlnorm.SetPolicy(urlnorm.Policy{ForceHTTPS: true, SortQuery: true})
+	t.Cleanup(func() { urlnorm.SetPolicy(urlnorm.DefaultPolicy) })
+	if _, err := db.GetURLID("https://example.com/item?id=1"); err == nil {
+		t.Error("GetURLID() matched a key stored under another policy")
+	}
+	otherID, err := db.InsertURL("https://example.com/item?id=1")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	if otherID == urlID {
+		t.Error("InsertURL() reused a url_id keyed under another policy")
+	}
+	if index, err := db.GetCanonicalURLIndex(); err != nil || index["https://example.com/item?id=1"] != otherID {
+		t.Errorf("GetCanonicalURLIndex() = %v, %v, want only keys from the active policy", index, err)
+	}
+
+	// The original URL itself still matches
+	if got, err := db.GetURLID("https://example.com/item?id=1&utm_source=news"); err != nil || got != urlID {
+		t.Errorf("GetURLID(original) = %d, %v, want %d", got, err, urlID)
+	}
+
+	// Back under the policy it was stored with, the key matches again
+	urlnorm.SetPolicy(urlnorm.DefaultPolicy)
+	if got, err := db.GetURLID("http://example.com/item?id=1&fbclid=abc"); err != nil || got != urlID {
+		t.Errorf("GetURLID() under the original policy = %d, %v, want %d", got, err, urlID)
+	}
+}
+
 func TestGetURLID(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -184,3 +272,93 @@ func TestGetURLID(t *testing.T) {
 		t.Error("GetURLID() with non-existent URL should return error")
 	}
 }
+
+func TestGetCanonicalURLIndex(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	firstID, err := db.InsertURL("https://example.com/docs?page=1")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	secondID, err := db.InsertURL("https://example.com/docs?page=2")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	otherID, err := db.InsertURL("https://example.com/about#team")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	printID, err := db.InsertURL("https://example.com/docs?print=1")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	if _, err := db.SetDeclaredCanonical(printID, "https://example.com/docs?page=1"); err != nil {
+		t.Fatalf("SetDeclaredCanonical() failed: %v", err)
+	}
+
+	index, err := db.GetCanonicalURLIndex()
+	if err != nil {
+		t.Fatalf("GetCanonicalURLIndex() error = %v", err)
+	}
+
+	if len(index) != 3 {
+		t.Errorf("index size = %d, want 3", len(index))
+	}
+	if got := index["https://example.com/docs?page=1"]; got != firstID {
+		t.Errorf("index[docs?page=1] = %d, want %d (lowest url_id)", got, firstID)
+	}
+	if got := index["https://example.com/docs?page=2"]; got != secondID {
+		t.Errorf("index[docs?page=2] = %d, want %d", got, secondID)
+	}
+	if got := index["https://example.com/about"]; got != otherID {
+		t.Errorf("index[about] = %d, want %d", got, otherID)
+	}
+}
+
+func TestSetDeclaredCanonical(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	const canonical = "https://example.com/guide"
+
+	firstID, err := db.InsertURL("https://example.com/guide?ref=nav")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+	mirrorID, err := db.InsertURL("https://mirror.example.org/guide.html")
+	if err != nil {
+		t.Fatalf("InsertURL() failed: %v", err)
+	}
+
+	duplicateOf, err := db.SetDeclaredCanonical(firstID, canonical)
+	if err != nil {
+		t.Fatalf("SetDeclaredCanonical() error = %v", err)
+	}
+	if duplicateOf != 0 {
+		t.Errorf("first URL duplicateOf = %d, want 0", duplicateOf)
+	}
+
+	duplicateOf, err = db.SetDeclaredCanonical(mirrorID, canonical)
+	if err != nil {
+		t.Fatalf("SetDeclaredCanonical() error = %v", err)
+	}
+	if duplicateOf != firstID {
+		t.Errorf("mirror duplicateOf = %d, want %d", duplicateOf, firstID)
+	}
+
+	if got, err := db.GetDuplicateOf(mirrorID); err != nil || got != firstID {
+		t.Errorf("GetDuplicateOf(mirror) = %d, %v; want %d", got, err, firstID)
+	}
+	if got, err := db.GetDuplicateOf(firstID); err != nil || got != 0 {
+		t.Errorf("GetDuplicateOf(first) = %d, %v; want 0", got, err)
+	}
+
+	index, err := db.GetCanonicalURLIndex()
+	if err != nil {
+		t.Fatalf("GetCanonicalURLIndex() error = %v", err)
+	}
+	if got := index[canonical]; got != firstID {
+		t.Errorf("index[%s] = %d, want %d", canonical, got, firstID)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/dtnitsch/llm-web-parser/models"
 	"github.com/go-shiori/go-readability"
 )
 
@@ -15,6 +16,7 @@ type EnrichedMetadata struct {
 	DomainCategory string  // gov/health, academic/ai, news/tech, docs/api, commerce, blog
 	Country        string  // TLD-based guess: us, uk, de, jp, etc
 	Confidence     float64 // 0-10 scale based on signal strength
+	TrustRule      string  // Domain pattern of the trust config rule applied to Confidence, if any
 
 	// Academic signals
 	HasDOI         bool
@@ -50,8 +52,9 @@ type HTTPMetadata struct {
 	RedirectChain []string
 }
 
-// Analyze performs smart detection on URL, readability article, and content
-func Analyze(rawURL string, article readability.Article, content string, httpMeta *HTTPMetadata) *EnrichedMetadata {
+// Analyze performs smart detection on URL, readability article, and content.
+// trust optionally overrides the confidence heuristic per domain; nil keeps the built-in score.
+func Analyze(rawURL string, article readability.Article, content string, httpMeta *HTTPMetadata, trust *models.TrustConfig) *EnrichedMetadata {
 	em := &EnrichedMetadata{}
 
 	// Add HTTP metadata if provided
@@ -91,7 +94,7 @@ func Analyze(rawURL string, article readability.Article, content string, httpMet
 	em.detectAcademicSignals(parsedURL, content)
 
 	// Calculate overall confidence score
-	em.Confidence = em.calculateConfidence()
+	em.Confidence = em.calculateConfidence(parsedURL.Hostname(), trust)
 
 	return em
 }
@@ -279,8 +282,9 @@ func (em *EnrichedMetadata) detectAcademicSignals(u *url.URL, content string) {
 	em.AcademicScore = score
 }
 
-// calculateConfidence computes overall confidence (0-10) based on signal strength
-func (em *EnrichedMetadata) calculateConfidence() float64 {
+// calculateConfidence computes overall confidence (0-10) based on signal strength,
+// then applies the first trust rule matching host
+func (em *EnrichedMetadata) calculateConfidence(host string, trust *models.TrustConfig) float64 {
 	confidence := 5.0 // baseline
 
 	// Strong domain signals
@@ -309,10 +313,23 @@ func (em *EnrichedMetadata) calculateConfidence() float64 {
 		confidence += 0.3
 	}
 
-	// Cap at 10
+	// Team source-trust policy wins over the heuristic
+	if rule := matchTrustRule(trust, host); rule != nil {
+		em.TrustRule = rule.Domain
+		if rule.Set != nil {
+			confidence = *rule.Set
+		} else {
+			confidence += rule.Adjust
+		}
+	}
+
+	// Clamp to 0-10
 	if confidence > 10.0 {
 		confidence = 10.0
 	}
+	if confidence < 0 {
+		confidence = 0
+	}
 
 	return confidence
 }
@@ -324,282 +341,279 @@ type ContentTypeResult struct {
 	Confidence     float64 // 0-10 confidence score
 }
 
+// Signal is one piece of evidence a content-type detector matched.
+type Signal struct {
+	Source string  `json:"source" yaml:"source"` // host, path, title or content
+	Match  string  `json:"match" yaml:"match"`   // What matched, e.g. "arxiv.org" or "doi pattern"
+	Weight float64 `json:"weight" yaml:"weight"`
+}
+
+// DetectorResult is what one content-type detector found. A detector fires when the
+// weights of its signals reach its threshold.
+type DetectorResult struct {
+	ContentType string   `json:"content_type" yaml:"content_type"`
+	Score       float64  `json:"score" yaml:"score"`
+	Threshold   float64  `json:"threshold" yaml:"threshold"`
+	Fired       bool     `json:"fired" yaml:"fired"`
+	Signals     []Signal `json:"signals,omitempty" yaml:"signals,omitempty"`
+}
+
+// ContentTypeExplanation is a classification with the evidence behind it: every
+// detector in priority order (the first that fired decided the type, later ones show
+// near misses) and why the subtype was picked.
+type ContentTypeExplanation struct {
+	ContentType    string           `json:"content_type" yaml:"content_type"`
+	ContentSubtype string           `json:"content_subtype,omitempty" yaml:"content_subtype,omitempty"`
+	SubtypeReason  string           `json:"subtype_reason,omitempty" yaml:"subtype_reason,omitempty"`
+	Confidence     float64          `json:"confidence" yaml:"confidence"`
+	Detectors      []DetectorResult `json:"detectors" yaml:"detectors"`
+}
+
+// Result returns the classification without the evidence.
+func (e ContentTypeExplanation) Result() ContentTypeResult {
+	return ContentTypeResult{ContentType: e.ContentType, ContentSubtype: e.ContentSubtype, Confidence: e.Confidence}
+}
+
+// detectInput is the lowercased page a content-type detector looks at.
+type detectInput struct {
+	host, path, title, content string
+}
+
+// contentDetector recognizes one content type. Decisive signals (a known host, say)
+// weigh the whole threshold; weaker content heuristics weigh 1 and must add up.
+type contentDetector struct {
+	contentType string
+	threshold   float64
+	confidence  float64 // Confidence of a page this detector classifies
+	signals     func(in detectInput) []Signal
+}
+
+// contentDetectors in priority order: academic wins over docs, and so on.
+var contentDetectors = []contentDetector{
+	{"academic", 3, 9.0, academicSignals},
+	{"docs", 2, 8.5, docsSignals},
+	{"wiki", 1, 9.5, wikiSignals},
+	{"repo", 1, 8.0, repoSignals},
+	{"blog", 2, 7.5, blogSignals},
+	{"news", 1, 8.0, newsSignals},
+	{"landing", 2, 6.0, landingSignals},
+}
+
+const (
+	// Confidence of a page no detector claims
+	unknownConfidence = 4.0
+	// Confidence when the URL can't be parsed and no detector runs
+	unparsedConfidence = 5.0
+)
+
+var (
+	doiPrefixPattern = regexp.MustCompile(`10\.\d{4,}/`)
+	isoDatePattern   = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+)
+
 // DetectContentType classifies page content type based on URL, title, and content patterns.
 func DetectContentType(rawURL, title, content string) ContentTypeResult {
-	result := ContentTypeResult{
+	return ExplainContentType(rawURL, title, content).Result()
+}
+
+// ExplainContentType classifies a page like DetectContentType and returns the signals
+// each detector matched, with their weights, for debugging misclassifications.
+func ExplainContentType(rawURL, title, content string) ContentTypeExplanation {
+	explanation := ContentTypeExplanation{
 		ContentType: "unknown",
-		Confidence:  5.0,
+		Confidence:  unparsedConfidence,
+		Detectors:   []DetectorResult{},
 	}
 
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return result
+		return explanation
 	}
 
-	host := strings.ToLower(parsedURL.Host)
-	path := strings.ToLower(parsedURL.Path)
-	lowerTitle := strings.ToLower(title)
-	lowerContent := strings.ToLower(content)
-
-	// Academic detection (highest priority for research content)
-	if detectAcademic(host, path, lowerTitle, lowerContent) {
-		result.ContentType = "academic"
-		result.Confidence = 9.0
-
-		// Detect subtype
-		if strings.Contains(host, "arxiv.org") {
-			result.ContentSubtype = "arxiv-paper"
-		} else if strings.Contains(host, "pubmed") {
-			result.ContentSubtype = "pubmed-article"
-		} else if strings.Contains(host, "doi.org") {
-			result.ContentSubtype = "doi-reference"
-		} else if strings.Contains(lowerContent, "abstract") && strings.Contains(lowerContent, "references") {
-			result.ContentSubtype = "research-paper"
-		} else {
-			result.ContentSubtype = "academic-general"
-		}
-		return result
+	in := detectInput{
+		host:    strings.ToLower(parsedURL.Host),
+		path:    strings.ToLower(parsedURL.Path),
+		title:   strings.ToLower(title),
+		content: strings.ToLower(content),
 	}
 
-	// Documentation detection
-	if detectDocs(host, path, lowerTitle, lowerContent) {
-		result.ContentType = "docs"
-		result.Confidence = 8.5
-
-		// Detect subtype
-		if strings.Contains(lowerTitle, "api") || strings.Contains(path, "/api/") {
-			result.ContentSubtype = "api-docs"
-		} else if strings.Contains(lowerTitle, "reference") {
-			result.ContentSubtype = "reference"
-		} else if strings.Contains(lowerTitle, "tutorial") || strings.Contains(lowerTitle, "guide") {
-			result.ContentSubtype = "tutorial"
-		} else {
-			result.ContentSubtype = "general-docs"
+	explanation.Confidence = unknownConfidence
+	decided := false
+	for _, d := range contentDetectors {
+		result := DetectorResult{ContentType: d.contentType, Threshold: d.threshold, Signals: d.signals(in)}
+		for _, signal := range result.Signals {
+			result.Score += signal.Weight
 		}
-		return result
-	}
-
-	// Wikipedia detection
-	if detectWiki(host, path, lowerContent) {
-		result.ContentType = "wiki"
-		result.ContentSubtype = "wikipedia"
-		result.Confidence = 9.5
-		return result
-	}
-
-	// Repository detection (GitHub, GitLab, etc.)
-	if detectRepo(host, path) {
-		result.ContentType = "repo"
-		result.Confidence = 8.0
-
-		if strings.Contains(host, "github.com") {
-			result.ContentSubtype = "github"
-		} else if strings.Contains(host, "gitlab.com") {
-			result.ContentSubtype = "gitlab"
-		} else {
-			result.ContentSubtype = "git-repository"
+		result.Fired = result.Score >= d.threshold
+		explanation.Detectors = append(explanation.Detectors, result)
+
+		if result.Fired && !decided {
+			decided = true
+			explanation.ContentType = d.contentType
+			explanation.Confidence = d.confidence
+			explanation.ContentSubtype, explanation.SubtypeReason = contentSubtype(d.contentType, in)
 		}
-		return result
 	}
+	return explanation
+}
 
-	// Blog detection
-	if detectBlog(host, path, lowerContent) {
-		result.ContentType = "blog"
-		result.ContentSubtype = "blog-post"
-		result.Confidence = 7.5
-		return result
-	}
-
-	// News detection
-	if detectNews(host, lowerTitle) {
-		result.ContentType = "news"
-		result.Confidence = 8.0
-
-		if strings.Contains(host, "tech") {
-			result.ContentSubtype = "tech-news"
-		} else {
-			result.ContentSubtype = "news-article"
+// contentSubtype refines a detected content type and says which pattern picked the subtype.
+func contentSubtype(contentType string, in detectInput) (subtype, reason string) {
+	switch contentType {
+	case "academic":
+		switch {
+		case strings.Contains(in.host, "arxiv.org"):
+			return "arxiv-paper", "host contains arxiv.org"
+		case strings.Contains(in.host, "pubmed"):
+			return "pubmed-article", "host contains pubmed"
+		case strings.Contains(in.host, "doi.org"):
+			return "doi-reference", "host contains doi.org"
+		case strings.Contains(in.content, "abstract") && strings.Contains(in.content, "references"):
+			return "research-paper", "content has abstract and references"
 		}
-		return result
+		return "academic-general", "no more specific academic pattern"
+	case "docs":
+		switch {
+		case strings.Contains(in.title, "api") || strings.Contains(in.path, "/api/"):
+			return "api-docs", "title or path mentions api"
+		case strings.Contains(in.title, "glossary"):
+			return "glossary", "title contains glossary"
+		case strings.Contains(in.title, "reference"):
+			return "reference", "title contains reference"
+		case strings.Contains(in.title, "tutorial") || strings.Contains(in.title, "guide"):
+			return "tutorial", "title contains tutorial or guide"
+		}
+		return "general-docs", "no more specific docs pattern"
+	case "wiki":
+		return "wikipedia", "every wiki is treated as wikipedia"
+	case "repo":
+		switch {
+		case strings.Contains(in.host, "github.com"):
+			return "github", "host contains github.com"
+		case strings.Contains(in.host, "gitlab.com"):
+			return "gitlab", "host contains gitlab.com"
+		}
+		return "git-repository", "other repository host"
+	case "blog":
+		return "blog-post", "every blog page is a post"
+	case "news":
+		if strings.Contains(in.host, "tech") {
+			return "tech-news", "host contains tech"
+		}
+		return "news-article", "host without tech"
+	case "landing":
+		return "marketing", "every landing page is marketing"
 	}
+	return "", ""
+}
 
-	// Landing page detection (low content, marketing-focused)
-	if detectLanding(lowerContent) {
-		result.ContentType = "landing"
-		result.ContentSubtype = "marketing"
-		result.Confidence = 6.0
-		return result
+// containsSignals returns a signal of weight for each pattern text contains.
+func containsSignals(text, source string, weight float64, patterns ...string) []Signal {
+	var signals []Signal
+	for _, pattern := range patterns {
+		if strings.Contains(text, pattern) {
+			signals = append(signals, Signal{Source: source, Match: pattern, Weight: weight})
+		}
 	}
-
-	// Default: unknown with medium-low confidence
-	result.Confidence = 4.0
-	return result
+	return signals
 }
 
-// detectAcademic checks for academic paper patterns
-func detectAcademic(host, path, title, content string) bool {
+// academicSignals checks for academic paper patterns: a known host, /abs/ or /paper/
+// path or academic title decides alone; otherwise three content signals are needed.
+func academicSignals(in detectInput) []Signal {
 	// URL-based detection
-	academicHosts := []string{
+	signals := containsSignals(in.host, "host", 3,
 		"arxiv.org", "doi.org", "pubmed", "scholar.google",
 		"researchgate.net", "academia.edu", "biorxiv.org",
-		"medrxiv.org", "ssrn.com",
-	}
-	for _, ah := range academicHosts {
-		if strings.Contains(host, ah) {
-			return true
-		}
-	}
-
-	// Path patterns
-	if strings.Contains(path, "/abs/") || strings.Contains(path, "/paper/") {
-		return true
-	}
-
-	// Title patterns
-	titlePatterns := []string{"abstract", "arxiv:", "doi:"}
-	for _, pattern := range titlePatterns {
-		if strings.Contains(title, pattern) {
-			return true
-		}
-	}
+		"medrxiv.org", "ssrn.com")
+	signals = append(signals, containsSignals(in.path, "path", 3, "/abs/", "/paper/")...)
+	signals = append(signals, containsSignals(in.title, "title", 3, "abstract", "arxiv:", "doi:")...)
 
 	// Strong academic signals in content
-	academicSignals := 0
-	if strings.Contains(content, "abstract") {
-		academicSignals++
-	}
-	if strings.Contains(content, "references") || strings.Contains(content, "bibliography") {
-		academicSignals++
+	signals = append(signals, containsSignals(in.content, "content", 1, "abstract")...)
+	switch {
+	case strings.Contains(in.content, "references"):
+		signals = append(signals, Signal{Source: "content", Match: "references", Weight: 1})
+	case strings.Contains(in.content, "bibliography"):
+		signals = append(signals, Signal{Source: "content", Match: "bibliography", Weight: 1})
 	}
-	if regexp.MustCompile(`10\.\d{4,}/`).MatchString(content) { // DOI pattern
-		academicSignals++
+	if doiPrefixPattern.MatchString(in.content) {
+		signals = append(signals, Signal{Source: "content", Match: "doi pattern", Weight: 1})
 	}
-	if strings.Contains(content, "et al.") {
-		academicSignals++
-	}
-
-	return academicSignals >= 3
+	return append(signals, containsSignals(in.content, "content", 1, "et al.")...)
 }
 
-// detectDocs checks for documentation patterns
-func detectDocs(host, path, title, content string) bool {
-	// URL-based detection
-	if strings.Contains(host, "docs.") || strings.Contains(host, "documentation.") {
-		return true
-	}
-	if strings.Contains(path, "/docs/") || strings.Contains(path, "/documentation/") {
-		return true
-	}
-	if strings.Contains(path, "/reference/") || strings.Contains(path, "/manual/") {
-		return true
-	}
-
-	// Title patterns
-	docsTitlePatterns := []string{
+// docsSignals checks for documentation patterns: a docs host, path or title decides
+// alone; otherwise the content needs both code examples and structured sections.
+func docsSignals(in detectInput) []Signal {
+	signals := containsSignals(in.host, "host", 2, "docs.", "documentation.")
+	signals = append(signals, containsSignals(in.path, "path", 2, "/docs/", "/documentation/", "/reference/", "/manual/")...)
+	signals = append(signals, containsSignals(in.title, "title", 2,
 		"documentation", "api reference", "getting started",
-		"user guide", "developer guide", "manual",
-	}
-	for _, pattern := range docsTitlePatterns {
-		if strings.Contains(title, pattern) {
-			return true
-		}
-	}
+		"user guide", "developer guide", "manual")...)
 
 	// Content patterns (code examples + structured sections)
-	hasCodeBlocks := strings.Count(content, "```") >= 2 || strings.Count(content, "<code>") >= 3
-	hasSections := strings.Count(content, "##") >= 3 || strings.Count(content, "<h2") >= 3
-
-	return hasCodeBlocks && hasSections
-}
-
-// detectWiki checks for Wikipedia or wiki-style content
-func detectWiki(host, path, content string) bool {
-	// Wikipedia domains
-	if strings.Contains(host, "wikipedia.org") {
-		return true
+	if strings.Count(in.content, "```") >= 2 || strings.Count(in.content, "<code>") >= 3 {
+		signals = append(signals, Signal{Source: "content", Match: "code blocks", Weight: 1})
 	}
-
-	// Path pattern
-	if strings.Contains(path, "/wiki/") {
-		return true
+	if strings.Count(in.content, "##") >= 3 || strings.Count(in.content, "<h2") >= 3 {
+		signals = append(signals, Signal{Source: "content", Match: "3+ sections", Weight: 1})
 	}
+	return signals
+}
 
+// wikiSignals checks for Wikipedia or wiki-style content
+func wikiSignals(in detectInput) []Signal {
+	signals := containsSignals(in.host, "host", 1, "wikipedia.org")
+	signals = append(signals, containsSignals(in.path, "path", 1, "/wiki/")...)
 	// Infobox detection (strong Wikipedia signal)
-	if strings.Contains(content, "infobox") || strings.Contains(content, "class=\"infobox\"") {
-		return true
-	}
-
-	return false
+	return append(signals, containsSignals(in.content, "content", 1, "infobox")...)
 }
 
-// detectRepo checks for code repository patterns
-func detectRepo(host, path string) bool {
-	repoHosts := []string{"github.com", "gitlab.com", "bitbucket.org"}
-	for _, rh := range repoHosts {
-		if strings.Contains(host, rh) {
-			return true
-		}
-	}
-	return false
+// repoSignals checks for code repository hosts
+func repoSignals(in detectInput) []Signal {
+	return containsSignals(in.host, "host", 1, "github.com", "gitlab.com", "bitbucket.org")
 }
 
-// detectBlog checks for blog patterns
-func detectBlog(host, path, content string) bool {
-	// URL-based detection
-	if strings.Contains(host, "blog.") || strings.Contains(path, "/blog/") {
-		return true
-	}
-
-	// Blog platforms
-	blogPlatforms := []string{"medium.com", "substack.com", "wordpress.com", "blogger.com"}
-	for _, bp := range blogPlatforms {
-		if strings.Contains(host, bp) {
-			return true
-		}
-	}
+// blogSignals checks for blog patterns: a blog host, path or platform decides alone;
+// otherwise the content needs both a byline and a date.
+func blogSignals(in detectInput) []Signal {
+	signals := containsSignals(in.host, "host", 2, "blog.")
+	signals = append(signals, containsSignals(in.path, "path", 2, "/blog/")...)
+	signals = append(signals, containsSignals(in.host, "host", 2, "medium.com", "substack.com", "wordpress.com", "blogger.com")...)
 
 	// Author byline + published date (common blog pattern)
-	hasAuthor := strings.Contains(content, "by ") || strings.Contains(content, "author")
-	hasDate := regexp.MustCompile(`\d{4}-\d{2}-\d{2}`).MatchString(content)
-
-	return hasAuthor && hasDate
+	switch {
+	case strings.Contains(in.content, "by "):
+		signals = append(signals, Signal{Source: "content", Match: "by ", Weight: 1})
+	case strings.Contains(in.content, "author"):
+		signals = append(signals, Signal{Source: "content", Match: "author", Weight: 1})
+	}
+	if isoDatePattern.MatchString(in.content) {
+		signals = append(signals, Signal{Source: "content", Match: "date (yyyy-mm-dd)", Weight: 1})
+	}
+	return signals
 }
 
-// detectNews checks for news article patterns
-func detectNews(host, title string) bool {
-	newsDomains := []string{
+// newsSignals checks for news outlets and news-style headlines
+func newsSignals(in detectInput) []Signal {
+	signals := containsSignals(in.host, "host", 1,
 		"techcrunch", "wired", "arstechnica", "theverge",
 		"reuters", "bbc", "cnn", "nytimes", "wsj",
-		"bloomberg", "forbes",
-	}
-	for _, nd := range newsDomains {
-		if strings.Contains(host, nd) {
-			return true
-		}
-	}
-
-	// News-style headlines (all caps words, breaking, exclusive)
-	newsPatterns := []string{"breaking:", "exclusive:", "report:", "update:"}
-	for _, pattern := range newsPatterns {
-		if strings.Contains(title, pattern) {
-			return true
-		}
-	}
-
-	return false
+		"bloomberg", "forbes")
+	return append(signals, containsSignals(in.title, "title", 1, "breaking:", "exclusive:", "report:", "update:")...)
 }
 
-// detectLanding checks for landing page patterns
-func detectLanding(content string) bool {
-	// Landing pages tend to have:
-	// - Low word count
-	// - High button/CTA count
-	// - Minimal actual content
-
-	wordCount := len(strings.Fields(content))
-	ctaCount := strings.Count(content, "sign up") + strings.Count(content, "get started") +
-		strings.Count(content, "try free") + strings.Count(content, "buy now")
-
-	// Low content + high CTAs = landing page
-	return wordCount < 500 && ctaCount >= 2
+// landingSignals checks for landing pages: little text and several calls to action.
+func landingSignals(in detectInput) []Signal {
+	var signals []Signal
+	if len(strings.Fields(in.content)) < 500 {
+		signals = append(signals, Signal{Source: "content", Match: "under 500 words", Weight: 1})
+	}
+	ctaCount := strings.Count(in.content, "sign up") + strings.Count(in.content, "get started") +
+		strings.Count(in.content, "try free") + strings.Count(in.content, "buy now")
+	if ctaCount >= 2 {
+		signals = append(signals, Signal{Source: "content", Match: "2+ calls to action", Weight: 1})
+	}
+	return signals
 }
@@ -0,0 +1,93 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		title       string
+		content     string
+		contentType string
+		subtype     string
+		fired       []string // Detectors expected to fire, in priority order
+	}{
+		{
+			name:        "academic content signals add up",
+			url:         "https://example.com/research/transformers",
+			title:       "Attention is all you need",
+			content:     "Abstract. We propose a model (Vaswani et al., 2017). See 10.48550/arxiv.1706.03762. References follow.",
+			contentType: "academic",
+			subtype:     "research-paper",
+			fired:       []string{"academic"},
+		},
+		{
+			name:        "docs path decides and blog near miss still fires",
+			url:         "https://blog.example.com/docs/install",
+			title:       "Install guide",
+			content:     "Install the package.",
+			contentType: "docs",
+			subtype:     "tutorial",
+			fired:       []string{"docs", "blog"},
+		},
+		{
+			name:        "nothing matches",
+			url:         "https://example.com/about",
+			title:       "About us",
+			content:     strings.Repeat("word ", 500),
+			contentType: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explanation := ExplainContentType(tt.url, tt.title, tt.content)
+			if explanation.ContentType != tt.contentType || explanation.ContentSubtype != tt.subtype {
+				t.Errorf("type = %s/%s, want %s/%s", explanation.ContentType, explanation.ContentSubtype, tt.contentType, tt.subtype)
+			}
+			if got := DetectContentType(tt.url, tt.title, tt.content); got != explanation.Result() {
+				t.Errorf("DetectContentType() = %+v, ExplainContentType().Result() = %+v", got, explanation.Result())
+			}
+
+			if len(explanation.Detectors) != len(contentDetectors) {
+				t.Fatalf("got %d detectors, want all %d", len(explanation.Detectors), len(contentDetectors))
+			}
+			var fired []string
+			for _, d := range explanation.Detectors {
+				score := 0.0
+				for _, s := range d.Signals {
+					score += s.Weight
+				}
+				if score != d.Score {
+					t.Errorf("%s score = %v, signal weights sum to %v", d.ContentType, d.Score, score)
+				}
+				if d.Fired {
+					fired = append(fired, d.ContentType)
+				}
+			}
+			if len(fired) != len(tt.fired) || (len(fired) > 0 && fired[0] != tt.fired[0]) {
+				t.Errorf("fired = %v, want %v", fired, tt.fired)
+			}
+		})
+	}
+}
+
+func TestExplainContentType_ContentSignals(t *testing.T) {
+	explanation := ExplainContentType("https://example.com/p", "A study", "abstract ... et al. ... references")
+	academic := explanation.Detectors[0]
+	if academic.ContentType != "academic" || !academic.Fired || academic.Score != 3 {
+		t.Fatalf("academic detector = %+v, want three content signals reaching threshold 3", academic)
+	}
+	want := map[string]bool{"abstract": true, "references": true, "et al.": true}
+	if len(academic.Signals) != len(want) {
+		t.Fatalf("signals = %+v, want abstract, references and et al.", academic.Signals)
+	}
+	for _, s := range academic.Signals {
+		if !want[s.Match] || s.Source != "content" || s.Weight != 1 {
+			t.Errorf("unexpected signal %+v", s)
+		}
+	}
+}
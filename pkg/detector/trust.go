@@ -0,0 +1,57 @@
+package detector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadTrustConfig reads and validates a trust config file.
+func LoadTrustConfig(path string) (*models.TrustConfig, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trust config: %w", err)
+	}
+
+	var config models.TrustConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse trust config %s: %w", path, err)
+	}
+	for i, rule := range config.Rules {
+		switch {
+		case strings.TrimSpace(rule.Domain) == "":
+			return nil, fmt.Errorf("trust rule %d: domain is required", i+1)
+		case rule.Set != nil && rule.Adjust != 0:
+			return nil, fmt.Errorf("trust rule %d (%s): use either set or adjust, not both", i+1, rule.Domain)
+		case rule.Set != nil && (*rule.Set < 0 || *rule.Set > 10):
+			return nil, fmt.Errorf("trust rule %d (%s): set must be between 0 and 10", i+1, rule.Domain)
+		}
+	}
+	return &config, nil
+}
+
+// matchTrustRule returns the first rule whose domain pattern matches host, or nil.
+func matchTrustRule(config *models.TrustConfig, host string) *models.TrustRule {
+	if config == nil {
+		return nil
+	}
+	host = strings.ToLower(strings.TrimPrefix(host, "www."))
+	for i := range config.Rules {
+		pattern := strings.ToLower(strings.TrimSpace(config.Rules[i].Domain))
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return &config.Rules[i]
+			}
+			continue
+		}
+		pattern = strings.TrimPrefix(pattern, "www.")
+		if host == pattern || strings.HasSuffix(host, "."+pattern) {
+			return &config.Rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-shiori/go-readability"
+)
+
+func TestTrustConfigOverridesConfidence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trust.yaml")
+	config := `rules:
+  - domain: "*.gov"
+    set: 9
+  - domain: medium.com
+    adjust: -2
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	trust, err := LoadTrustConfig(path)
+	if err != nil {
+		t.Fatalf("LoadTrustConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		url        string
+		confidence float64
+		rule       string
+	}{
+		{"https://www.cdc.gov/flu", 9, "*.gov"},
+		{"https://medium.com/@someone/post", 3, "medium.com"},
+		{"https://blog.medium.com/post", 3, "medium.com"},
+		{"https://notmedium.com/post", 5, ""},
+	}
+	for _, tt := range tests {
+		em := Analyze(tt.url, readability.Article{}, "", nil, trust)
+		if em.Confidence != tt.confidence || em.TrustRule != tt.rule {
+			t.Errorf("%s: confidence=%v rule=%q, want %v %q", tt.url, em.Confidence, em.TrustRule, tt.confidence, tt.rule)
+		}
+	}
+
+	if em := Analyze("https://www.cdc.gov/flu", readability.Article{}, "", nil, nil); em.Confidence != 7 {
+		t.Errorf("without trust config confidence = %v, want the heuristic 7", em.Confidence)
+	}
+}
+
+func TestLoadTrustConfigRejectsBadRules(t *testing.T) {
+	for name, config := range map[string]string{
+		"no domain":    "rules:\n  - set: 5\n",
+		"both":         "rules:\n  - domain: a.com\n    set: 5\n    adjust: 1\n",
+		"out of range": "rules:\n  - domain: a.com\n    set: 11\n",
+	} {
+		path := filepath.Join(t.TempDir(), "trust.yaml")
+		if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTrustConfig(path); err == nil {
+			t.Errorf("%s: LoadTrustConfig() error = nil, want invalid rule", name)
+		}
+	}
+}
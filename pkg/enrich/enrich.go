@@ -0,0 +1,322 @@
+// Package enrich looks up authoritative paper metadata for arXiv IDs and DOIs found on
+// parsed pages, from the arXiv API and Crossref.
+package enrich
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultArXivEndpoint    = "https://export.arxiv.org/api/query"
+	DefaultCrossrefEndpoint = "https://api.crossref.org/works/"
+)
+
+// Client resolves identifiers to publication metadata. Lookups are memoized for the
+// life of the client and, when CacheDir is set, on disk across runs. Safe for
+// concurrent use.
+type Client struct {
+	HTTP             *http.Client
+	ArXivEndpoint    string
+	CrossrefEndpoint string
+	CacheDir         string // "" disables the on-disk cache
+
+	mu   sync.Mutex
+	memo map[string]*models.PublicationMetadata // nil value = looked up, nothing found
+}
+
+// NewClient returns a client for the public arXiv and Crossref APIs, caching in cacheDir.
+func NewClient(cacheDir string) *Client {
+	return &Client{
+		HTTP:             &http.Client{Timeout: 15 * time.Second},
+		ArXivEndpoint:    DefaultArXivEndpoint,
+		CrossrefEndpoint: DefaultCrossrefEndpoint,
+		CacheDir:         cacheDir,
+	}
+}
+
+var (
+	arxivURLPattern = regexp.MustCompile(`arxiv\.org/(?:abs|pdf)/(\d{4}\.\d{4,5})`)
+	doiURLPattern   = regexp.MustCompile(`doi\.org/(10\.\d{4,}/\S+)`)
+)
+
+// Identifiers returns the arXiv ID and DOI for a page: those the detector found in its
+// text, else ones in its URL (arxiv.org/abs/..., doi.org/...).
+func Identifiers(page *models.Page) (arxivID, doi string) {
+	arxivID = page.Metadata.ArXivID
+	doi = cleanDOI(page.Metadata.DOIPattern)
+	if arxivID == "" {
+		if m := arxivURLPattern.FindStringSubmatch(page.URL); m != nil {
+			arxivID = m[1]
+		}
+	}
+	if doi == "" {
+		if m := doiURLPattern.FindStringSubmatch(page.URL); m != nil {
+			if unescaped, err := url.PathUnescape(m[1]); err == nil {
+				doi = cleanDOI(unescaped)
+			}
+		}
+	}
+	return arxivID, doi
+}
+
+// cleanDOI strips punctuation the detector's pattern picks up from surrounding text.
+func cleanDOI(doi string) string {
+	return strings.TrimRight(strings.TrimSpace(doi), `.,;:)]}>"'`)
+}
+
+// Lookup returns metadata for the page's arXiv ID (preferred) or DOI, or nil when the
+// page has neither or no source knows them.
+func (c *Client) Lookup(page *models.Page) (*models.PublicationMetadata, error) {
+	arxivID, doi := Identifiers(page)
+	switch {
+	case arxivID != "":
+		meta, err := c.cached("arxiv:"+arxivID, func() (*models.PublicationMetadata, error) { return c.fetchArXiv(arxivID) })
+		if meta != nil || err != nil || doi == "" {
+			return meta, err
+		}
+		fallthrough
+	case doi != "":
+		return c.cached("doi:"+strings.ToLower(doi), func() (*models.PublicationMetadata, error) { return c.fetchCrossref(doi) })
+	}
+	return nil, nil
+}
+
+// cached returns the memoized or on-disk result for key, calling lookup on a miss.
+// Failed lookups are not cached, so a later run can retry them.
+func (c *Client) cached(key string, lookup func() (*models.PublicationMetadata, error)) (*models.PublicationMetadata, error) {
+	c.mu.Lock()
+	if meta, ok := c.memo[key]; ok {
+		c.mu.Unlock()
+		return meta, nil
+	}
+	c.mu.Unlock()
+
+	meta, found := c.readCache(key)
+	if !found {
+		var err error
+		if meta, err = lookup(); err != nil {
+			return nil, err
+		}
+		c.writeCache(key, meta)
+	}
+
+	c.mu.Lock()
+	if c.memo == nil {
+		c.memo = make(map[string]*models.PublicationMetadata)
+	}
+	c.memo[key] = meta
+	c.mu.Unlock()
+	return meta, nil
+}
+
+var unsafeCacheChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (c *Client) cachePath(key string) string {
+	return filepath.Join(c.CacheDir, unsafeCacheChars.ReplaceAllString(key, "_")+".yaml")
+}
+
+// cacheEntry is a cached lookup; Metadata is nil when the source had no record.
+type cacheEntry struct {
+	Key      string                      `yaml:"key"`
+	Metadata *models.PublicationMetadata `yaml:"metadata"`
+}
+
+func (c *Client) readCache(key string) (*models.PublicationMetadata, bool) {
+	if c.CacheDir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.cachePath(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := yaml.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+	return entry.Metadata, true
+}
+
+// writeCache stores a lookup result; cache write failures only cost a repeat lookup.
+func (c *Client) writeCache(key string, meta *models.PublicationMetadata) {
+	if c.CacheDir == "" {
+		return
+	}
+	data, err := yaml.Marshal(cacheEntry{Key: key, Metadata: meta})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.CacheDir, 0750); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath(key), data, 0600) // #nosec G104
+}
+
+// get fetches endpoint and returns the body; found is false on 404.
+func (c *Client) get(endpoint string) (body []byte, found bool, err error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "llm-web-parser (metadata enrichment)")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("%s returned HTTP %d", req.URL.Host, resp.StatusCode)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s response: %w", req.URL.Host, err)
+	}
+	return body, true, nil
+}
+
+// arxivFeed is the subset of the arXiv API's Atom response we use.
+type arxivFeed struct {
+	Entries []struct {
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Summary   string `xml:"summary"`
+		Published string `xml:"published"`
+		DOI       string `xml:"http://arxiv.org/schemas/atom doi"`
+		Journal   string `xml:"http://arxiv.org/schemas/atom journal_ref"`
+		Authors   []struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+	} `xml:"entry"`
+}
+
+func (c *Client) fetchArXiv(id string) (*models.PublicationMetadata, error) {
+	body, found, err := c.get(c.ArXivEndpoint + "?id_list=" + url.QueryEscape(id))
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var feed arxivFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse arXiv response for %s: %w", id, err)
+	}
+	// Unknown IDs come back as an entry with no title (or an "Error" entry)
+	if len(feed.Entries) == 0 || collapse(feed.Entries[0].Title) == "" || collapse(feed.Entries[0].Title) == "Error" {
+		return nil, nil
+	}
+
+	entry := feed.Entries[0]
+	meta := &models.PublicationMetadata{
+		Source:   "arxiv",
+		ArXivID:  id,
+		DOI:      strings.TrimSpace(entry.DOI),
+		Title:    collapse(entry.Title),
+		Abstract: collapse(entry.Summary),
+		Venue:    collapse(entry.Journal),
+	}
+	if len(entry.Published) >= len("2006-01-02") {
+		meta.Published = entry.Published[:len("2006-01-02")]
+	}
+	for _, author := range entry.Authors {
+		if name := collapse(author.Name); name != "" {
+			meta.Authors = append(meta.Authors, name)
+		}
+	}
+	return meta, nil
+}
+
+// crossrefWork is the subset of a Crossref /works/{doi} response we use.
+type crossrefWork struct {
+	Message struct {
+		DOI            string   `json:"DOI"`
+		Title          []string `json:"title"`
+		ContainerTitle []string `json:"container-title"`
+		Abstract       string   `json:"abstract"`
+		Author         []struct {
+			Given  string `json:"given"`
+			Family string `json:"family"`
+			Name   string `json:"name"` // Organizational authors
+		} `json:"author"`
+		Issued struct {
+			DateParts [][]int `json:"date-parts"`
+		} `json:"issued"`
+	} `json:"message"`
+}
+
+var markupTag = regexp.MustCompile(`<[^>]+>`)
+
+func (c *Client) fetchCrossref(doi string) (*models.PublicationMetadata, error) {
+	body, found, err := c.get(c.CrossrefEndpoint + url.PathEscape(doi))
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var work crossrefWork
+	if err := json.Unmarshal(body, &work); err != nil {
+		return nil, fmt.Errorf("failed to parse Crossref response for %s: %w", doi, err)
+	}
+	msg := work.Message
+
+	meta := &models.PublicationMetadata{
+		Source: "crossref",
+		DOI:    doi,
+		// Crossref abstracts are JATS XML (<jats:p>...); keep the text
+		Abstract: collapse(markupTag.ReplaceAllString(msg.Abstract, " ")),
+	}
+	if msg.DOI != "" {
+		meta.DOI = msg.DOI
+	}
+	if len(msg.Title) > 0 {
+		meta.Title = collapse(msg.Title[0])
+	}
+	if len(msg.ContainerTitle) > 0 {
+		meta.Venue = collapse(msg.ContainerTitle[0])
+	}
+	for _, author := range msg.Author {
+		name := collapse(author.Given + " " + author.Family)
+		if name == "" {
+			name = collapse(author.Name)
+		}
+		if name != "" {
+			meta.Authors = append(meta.Authors, name)
+		}
+	}
+	if parts := msg.Issued.DateParts; len(parts) > 0 && len(parts[0]) > 0 {
+		layouts := []string{"%04d", "%04d-%02d", "%04d-%02d-%02d"}
+		date := parts[0]
+		if len(date) > 3 {
+			date = date[:3]
+		}
+		args := make([]interface{}, len(date))
+		for i, v := range date {
+			args[i] = v
+		}
+		meta.Published = fmt.Sprintf(layouts[len(date)-1], args...)
+	}
+	if meta.Title == "" {
+		return nil, nil
+	}
+	return meta, nil
+}
+
+// collapse trims text and joins internal whitespace runs (arXiv wraps titles) with one space.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
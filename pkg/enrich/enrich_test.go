@@ -0,0 +1,150 @@
+package enrich
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+const arxivResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:arxiv="http://arxiv.org/schemas/atom">
+  <entry>
+    <id>http://arxiv.org/abs/2103.00020v1</id>
+    <published>2021-02-26T17:04:26Z</published>
+    <title>Learning Transferable Visual Models
+      From Natural Language Supervision</title>
+    <summary>  State-of-the-art computer vision systems.  </summary>
+    <author><name>Alec Radford</name></author>
+    <author><name>Jong Wook Kim</name></author>
+  </entry>
+</feed>`
+
+const crossrefResponse = `{"message": {
+  "DOI": "10.1038/nature14539",
+  "title": ["Deep learning"],
+  "container-title": ["Nature"],
+  "abstract": "<jats:p>Deep learning allows models.</jats:p>",
+  "author": [{"given": "Yann", "family": "LeCun"}, {"given": "Yoshua", "family": "Bengio"}],
+  "issued": {"date-parts": [[2015, 5, 27]]}
+}}`
+
+// newTestClient serves canned arXiv and Crossref responses and counts requests.
+func newTestClient(t *testing.T, cacheDir string) (*Client, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch {
+		case r.URL.Path == "/arxiv" && r.URL.Query().Get("id_list") == "2103.00020":
+			w.Write([]byte(arxivResponse))
+		case r.URL.Path == "/arxiv":
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`))
+		case r.URL.Path == "/works/10.1038/nature14539":
+			w.Write([]byte(crossrefResponse))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(cacheDir)
+	client.ArXivEndpoint = server.URL + "/arxiv"
+	client.CrossrefEndpoint = server.URL + "/works/"
+	return client, &requests
+}
+
+func TestLookup_ArXiv(t *testing.T) {
+	client, requests := newTestClient(t, "")
+	page := &models.Page{URL: "https://arxiv.org/abs/2103.00020"}
+
+	meta, err := client.Lookup(page)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if meta == nil {
+		t.Fatal("Lookup() = nil, want arXiv metadata")
+	}
+	if meta.Source != "arxiv" || meta.Title != "Learning Transferable Visual Models From Natural Language Supervision" {
+		t.Errorf("Lookup() source %q, title %q", meta.Source, meta.Title)
+	}
+	if meta.Published != "2021-02-26" || meta.Abstract != "State-of-the-art computer vision systems." {
+		t.Errorf("Lookup() published %q, abstract %q", meta.Published, meta.Abstract)
+	}
+	if strings.Join(meta.Authors, ", ") != "Alec Radford, Jong Wook Kim" {
+		t.Errorf("Lookup() authors = %v", meta.Authors)
+	}
+
+	// A second page citing the same paper is served from memory
+	if _, err := client.Lookup(&models.Page{Metadata: models.PageMetadata{ArXivID: "2103.00020"}}); err != nil {
+		t.Fatalf("second Lookup() error = %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestLookup_CrossrefDOI(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	page := &models.Page{Metadata: models.PageMetadata{DOIPattern: "10.1038/nature14539."}}
+
+	meta, err := client.Lookup(page)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if meta == nil {
+		t.Fatal("Lookup() = nil, want Crossref metadata")
+	}
+	if meta.Source != "crossref" || meta.Title != "Deep learning" || meta.Venue != "Nature" {
+		t.Errorf("Lookup() source %q, title %q, venue %q", meta.Source, meta.Title, meta.Venue)
+	}
+	if meta.Published != "2015-05-27" || meta.Abstract != "Deep learning allows models." {
+		t.Errorf("Lookup() published %q, abstract %q", meta.Published, meta.Abstract)
+	}
+	if strings.Join(meta.Authors, ", ") != "Yann LeCun, Yoshua Bengio" {
+		t.Errorf("Lookup() authors = %v", meta.Authors)
+	}
+}
+
+func TestLookup_UnknownFallsBackToDOI(t *testing.T) {
+	client, _ := newTestClient(t, "")
+	page := &models.Page{Metadata: models.PageMetadata{ArXivID: "9999.99999", DOIPattern: "10.1038/nature14539"}}
+
+	meta, err := client.Lookup(page)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if meta == nil || meta.Source != "crossref" {
+		t.Errorf("Lookup() = %+v, want Crossref metadata", meta)
+	}
+
+	if meta, err := client.Lookup(&models.Page{URL: "https://example.com/"}); meta != nil || err != nil {
+		t.Errorf("Lookup(no identifiers) = %+v, %v; want nil, nil", meta, err)
+	}
+}
+
+func TestLookup_DiskCache(t *testing.T) {
+	dir := t.TempDir()
+	page := &models.Page{URL: "https://doi.org/10.1038/nature14539"}
+
+	first, requests := newTestClient(t, dir)
+	if _, err := first.Lookup(page); err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+
+	// A new client (a later run) reads the cached result instead of the API
+	second, secondRequests := newTestClient(t, dir)
+	meta, err := second.Lookup(page)
+	if err != nil {
+		t.Fatalf("cached Lookup() error = %v", err)
+	}
+	if meta == nil || meta.Title != "Deep learning" {
+		t.Errorf("cached Lookup() = %+v", meta)
+	}
+	if atomic.LoadInt32(requests) != 1 || atomic.LoadInt32(secondRequests) != 0 {
+		t.Errorf("requests = %d then %d, want 1 then 0", *requests, *secondRequests)
+	}
+}
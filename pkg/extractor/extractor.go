@@ -66,6 +66,7 @@ func FilterPage(page *models.Page, strategy *Strategy) *models.Page {
 		Title:    page.Title,
 		Metadata: page.Metadata, // Copy metadata
 		Content:  filterSections(page.Content, strategy),
+		Images:   page.Images,
 	}
 
 	return filteredPage
@@ -9,10 +9,13 @@ import (
 
 // AcademicExtraction contains academic-specific extracted data.
 type AcademicExtraction struct {
+	// Canonical metadata from arXiv/Crossref (fetch --enrich-academic)
+	Publication *models.PublicationMetadata `yaml:"publication,omitempty" json:"publication,omitempty"`
 	Abstract   *Section   `yaml:"abstract,omitempty" json:"abstract,omitempty"`
 	Sections   []Section  `yaml:"sections,omitempty" json:"sections,omitempty"`
 	Citations  []Citation `yaml:"citations,omitempty" json:"citations,omitempty"`
 	References []Reference `yaml:"references,omitempty" json:"references,omitempty"`
+	Math       []models.Math `yaml:"math,omitempty" json:"math,omitempty"` // Formulas in page order (full mode)
 }
 
 // Section represents a structured section (e.g., Introduction, Methods, Results).
@@ -40,13 +43,14 @@ func ExtractAcademic(page *models.Page) *AcademicExtraction {
 		return nil
 	}
 
-	extraction := &AcademicExtraction{}
+	extraction := &AcademicExtraction{Publication: page.Metadata.Publication}
 
 	// Extract from full mode content (hierarchical sections)
 	if len(page.Content) > 0 {
 		extraction.Abstract = extractAbstract(page.Content)
 		extraction.Sections = extractSections(page.Content)
 		extraction.References = extractReferences(page.Content)
+		extraction.Math = extractMath(page.Content)
 	}
 
 	// Extract citations from flat content or full content
@@ -59,6 +63,20 @@ func ExtractAcademic(page *models.Page) *AcademicExtraction {
 	return extraction
 }
 
+// extractMath collects the math blocks of sections and their children, in page order.
+func extractMath(sections []models.Section) []models.Math {
+	var math []models.Math
+	for _, section := range sections {
+		for _, block := range section.Blocks {
+			if block.Math != nil {
+				math = append(math, *block.Math)
+			}
+		}
+		math = append(math, extractMath(section.Children)...)
+	}
+	return math
+}
+
 // extractAbstract finds the abstract section.
 func extractAbstract(sections []models.Section) *Section {
 	for _, section := range sections {
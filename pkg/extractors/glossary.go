@@ -0,0 +1,144 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// Limits for the heading/paragraph glossary heuristic: a term heading is a few words,
+// its definition a paragraph or two, and a glossary has more than a couple of them.
+const (
+	maxTermWords       = 8
+	maxDefinitionChars = 800
+	minGlossaryTerms   = 3
+)
+
+// GlossaryExtraction contains term→definition pairs from a glossary or reference page.
+type GlossaryExtraction struct {
+	Terms []Term `yaml:"terms" json:"terms"`
+}
+
+// Term is one glossary entry.
+type Term struct {
+	Name       string `yaml:"name" json:"name"`
+	Definition string `yaml:"definition" json:"definition"`
+}
+
+// IsGlossaryPage reports whether a page was classified as reference material or calls
+// itself a glossary.
+func IsGlossaryPage(page *models.Page) bool {
+	if page == nil {
+		return false
+	}
+	switch page.Metadata.ContentSubtype {
+	case "reference", "glossary":
+		return true
+	}
+	return strings.Contains(strings.ToLower(page.Title), "glossary")
+}
+
+// ExtractGlossary pairs terms with definitions, from dt/dd blocks when the page has them
+// and otherwise from short headings each followed by a short run of paragraphs. Returns
+// nil when fewer than three terms are found.
+func ExtractGlossary(page *models.Page) *GlossaryExtraction {
+	if page == nil {
+		return nil
+	}
+
+	blocks := page.FlatContent
+	if len(page.Content) > 0 {
+		blocks = flattenSections(page.Content)
+	}
+
+	terms := definitionListTerms(blocks)
+	if len(terms) < minGlossaryTerms {
+		terms = headingTerms(blocks)
+	}
+	if len(terms) < minGlossaryTerms {
+		return nil
+	}
+	return &GlossaryExtraction{Terms: terms}
+}
+
+// flattenSections lists a section tree's blocks in document order, headings included.
+func flattenSections(sections []models.Section) []models.ContentBlock {
+	var blocks []models.ContentBlock
+	for _, section := range sections {
+		if section.Heading != nil {
+			blocks = append(blocks, *section.Heading)
+		}
+		blocks = append(blocks, section.Blocks...)
+		blocks = append(blocks, flattenSections(section.Children)...)
+	}
+	return blocks
+}
+
+// definitionListTerms pairs each dt block with the dd blocks that follow it.
+func definitionListTerms(blocks []models.ContentBlock) []Term {
+	var terms []Term
+	for i := 0; i < len(blocks); i++ {
+		if blocks[i].Type != "dt" {
+			continue
+		}
+		name := blocks[i].Text
+
+		var definition []string
+		for i+1 < len(blocks) && blocks[i+1].Type == "dd" {
+			i++
+			definition = append(definition, strings.TrimSpace(blocks[i].Text))
+		}
+		if term := newTerm(name, definition); term != nil {
+			terms = append(terms, *term)
+		}
+	}
+	return terms
+}
+
+// headingTerms treats a short heading followed only by paragraphs, up to the next heading,
+// as a term and its definition. Headings over code, lists or tables are skipped.
+func headingTerms(blocks []models.ContentBlock) []Term {
+	var terms []Term
+	for i := 0; i < len(blocks); i++ {
+		if !isHeadingBlock(blocks[i].Type) {
+			continue
+		}
+		name := blocks[i].Text
+
+		var definition []string
+		plain := true
+		for i+1 < len(blocks) && !isHeadingBlock(blocks[i+1].Type) {
+			i++
+			if blocks[i].Type != "p" {
+				plain = false
+				continue
+			}
+			definition = append(definition, strings.TrimSpace(blocks[i].Text))
+		}
+		if !plain {
+			continue
+		}
+		if term := newTerm(name, definition); term != nil {
+			terms = append(terms, *term)
+		}
+	}
+	return terms
+}
+
+// newTerm returns a Term when name is short and the definition is present but not long.
+func newTerm(name string, definition []string) *Term {
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(name), ":"))
+	text := strings.TrimSpace(strings.Join(definition, " "))
+	if name == "" || text == "" || len(strings.Fields(name)) > maxTermWords || len(text) > maxDefinitionChars {
+		return nil
+	}
+	return &Term{Name: name, Definition: text}
+}
+
+func isHeadingBlock(blockType string) bool {
+	switch blockType {
+	case "h2", "h3", "h4", "h5", "h6":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package extractors
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestExtractGlossary_HeadingParagraphs(t *testing.T) {
+	page := &models.Page{
+		Title: "Glossary",
+		FlatContent: []models.ContentBlock{
+			{Type: "h1", Text: "Glossary"},
+			{Type: "p", Text: "Terms used throughout these docs."},
+			{Type: "h2", Text: "Idempotent:"},
+			{Type: "p", Text: "Safe to repeat without changing the result."},
+			{Type: "h2", Text: "Lease"},
+			{Type: "p", Text: "A time-limited lock."},
+			{Type: "p", Text: "Renew it before it expires."},
+			{Type: "h2", Text: "Examples"},
+			{Type: "code", Text: "lease.Renew()"},
+			{Type: "h2", Text: "Quorum"},
+			{Type: "p", Text: "A majority of voting members."},
+		},
+	}
+
+	if !IsGlossaryPage(page) {
+		t.Error("IsGlossaryPage() = false, want true for a page titled Glossary")
+	}
+	got := ExtractGlossary(page)
+	if got == nil || len(got.Terms) != 3 {
+		t.Fatalf("ExtractGlossary() = %+v, want 3 terms", got)
+	}
+	want := []Term{
+		{Name: "Idempotent", Definition: "Safe to repeat without changing the result."},
+		{Name: "Lease", Definition: "A time-limited lock. Renew it before it expires."},
+		{Name: "Quorum", Definition: "A majority of voting members."},
+	}
+	for i, term := range want {
+		if got.Terms[i] != term {
+			t.Errorf("term %d = %+v, want %+v", i, got.Terms[i], term)
+		}
+	}
+}
+
+func TestExtractGlossary_DefinitionListAndMinimum(t *testing.T) {
+	blocks := []models.ContentBlock{
+		{Type: "dt", Text: "ETag"}, {Type: "dd", Text: "An opaque version identifier."},
+		{Type: "dt", Text: "TTL"}, {Type: "dd", Text: "How long a cached entry stays fresh."},
+		{Type: "dt", Text: "Origin"}, {Type: "dd", Text: "The server holding the canonical copy."},
+	}
+	if got := ExtractGlossary(&models.Page{FlatContent: blocks}); got == nil || got.Terms[1].Name != "TTL" {
+		t.Errorf("ExtractGlossary(dl) = %+v, want three dt/dd terms", got)
+	}
+	if got := ExtractGlossary(&models.Page{FlatContent: blocks[:4]}); got != nil {
+		t.Errorf("ExtractGlossary(two terms) = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,33 @@
+package extractors
+
+import "github.com/dtnitsch/llm-web-parser/models"
+
+// ImagesExtraction contains the images found in a page's main content.
+type ImagesExtraction struct {
+	Total         int            `yaml:"total" json:"total"`
+	MissingAlt    int            `yaml:"missing_alt" json:"missing_alt"`
+	FeaturedImage string         `yaml:"featured_image,omitempty" json:"featured_image,omitempty"`
+	Images        []models.Image `yaml:"images" json:"images"`
+}
+
+// ExtractImages packages the parser's image list for the images.yaml artifact.
+// Returns nil when the page has no content images (e.g. minimal mode).
+func ExtractImages(page *models.Page) *ImagesExtraction {
+	if page == nil || len(page.Images) == 0 {
+		return nil
+	}
+
+	extraction := &ImagesExtraction{
+		Total:         len(page.Images),
+		FeaturedImage: page.Metadata.Image,
+		Images:        page.Images,
+	}
+
+	for _, img := range page.Images {
+		if img.Alt == "" {
+			extraction.MissingAlt++
+		}
+	}
+
+	return extraction
+}
@@ -0,0 +1,30 @@
+package extractors
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestExtractImages(t *testing.T) {
+	if got := ExtractImages(&models.Page{URL: "https://example.com/"}); got != nil {
+		t.Errorf("ExtractImages(no images) = %+v, want nil", got)
+	}
+
+	page := &models.Page{
+		Images: []models.Image{
+			{Src: "https://example.com/a.png", Alt: "A"},
+			{Src: "https://example.com/b.png"},
+			{Src: "https://example.com/c.png"},
+		},
+	}
+	page.Metadata.Image = "https://example.com/og.png"
+
+	got := ExtractImages(page)
+	if got == nil {
+		t.Fatal("ExtractImages() = nil, want images")
+	}
+	if got.Total != 3 || got.MissingAlt != 2 || got.FeaturedImage != "https://example.com/og.png" || len(got.Images) != 3 {
+		t.Errorf("ExtractImages() = %+v, want 3 images, 2 missing alt, featured og.png", got)
+	}
+}
@@ -0,0 +1,105 @@
+package extractors
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// LinksExtraction contains the deduplicated outbound links of a page.
+type LinksExtraction struct {
+	Total    int             `yaml:"total" json:"total"`
+	Internal int             `yaml:"internal" json:"internal"`
+	External int             `yaml:"external" json:"external"`
+	Links    []ExtractedLink `yaml:"links" json:"links"`
+}
+
+// ExtractedLink is a single resolved link with its anchor text.
+type ExtractedLink struct {
+	URL   string          `yaml:"url" json:"url"`
+	Text  string          `yaml:"text,omitempty" json:"text,omitempty"`
+	Type  models.LinkType `yaml:"type" json:"type"`
+	Count int             `yaml:"count,omitempty" json:"count,omitempty"` // Only set when the link appears more than once
+}
+
+// ExtractLinks collects every link from the page's content blocks, resolves it to an
+// absolute URL and dedupes it. Same-page anchors and non-HTTP schemes (mailto:, javascript:) are skipped.
+func ExtractLinks(page *models.Page) *LinksExtraction {
+	if page == nil {
+		return nil
+	}
+
+	base, err := url.Parse(page.URL)
+	if err != nil {
+		return nil
+	}
+
+	extraction := &LinksExtraction{Links: []ExtractedLink{}}
+	seen := make(map[string]int)
+
+	for _, block := range page.AllTextBlocks() {
+		for _, link := range block.Links {
+			ref, err := url.Parse(strings.TrimSpace(link.Href))
+			if err != nil {
+				continue
+			}
+
+			resolved := base.ResolveReference(ref)
+			if resolved.Scheme != "http" && resolved.Scheme != "https" {
+				continue
+			}
+			resolved.Fragment = ""
+
+			// Skip anchors back to the page itself
+			if resolved.String() == stripFragment(base) {
+				continue
+			}
+
+			absURL := resolved.String()
+			if idx, ok := seen[absURL]; ok {
+				existing := &extraction.Links[idx]
+				existing.Count++
+				if existing.Text == "" {
+					existing.Text = link.Text
+				}
+				continue
+			}
+
+			linkType := models.LinkExternal
+			if strings.EqualFold(resolved.Host, base.Host) {
+				linkType = models.LinkInternal
+			}
+
+			seen[absURL] = len(extraction.Links)
+			extraction.Links = append(extraction.Links, ExtractedLink{
+				URL:   absURL,
+				Text:  link.Text,
+				Type:  linkType,
+				Count: 1,
+			})
+		}
+	}
+
+	for i := range extraction.Links {
+		if extraction.Links[i].Type == models.LinkInternal {
+			extraction.Internal++
+		} else {
+			extraction.External++
+		}
+		// Keep the YAML compact: a count of 1 is implied
+		if extraction.Links[i].Count == 1 {
+			extraction.Links[i].Count = 0
+		}
+	}
+	extraction.Total = len(extraction.Links)
+
+	return extraction
+}
+
+// stripFragment returns the URL string without its fragment.
+func stripFragment(u *url.URL) string {
+	clone := *u
+	clone.Fragment = ""
+	return clone.String()
+}
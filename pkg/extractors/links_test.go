@@ -0,0 +1,83 @@
+package extractors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestExtractLinks(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []models.Link
+		want  []ExtractedLink
+	}{
+		{
+			name:  "relative URLs resolve against the page",
+			links: []models.Link{{Href: "guide", Text: "Guide"}, {Href: "/api"}, {Href: "../faq?q=1"}},
+			want: []ExtractedLink{
+				{URL: "https://example.com/docs/guide", Text: "Guide", Type: models.LinkInternal},
+				{URL: "https://example.com/api", Type: models.LinkInternal},
+				{URL: "https://example.com/faq?q=1", Type: models.LinkInternal},
+			},
+		},
+		{
+			name:  "internal and external by host",
+			links: []models.Link{{Href: "https://EXAMPLE.com/x"}, {Href: "https://other.org/"}, {Href: "http://sub.example.com/"}},
+			want: []ExtractedLink{
+				{URL: "https://EXAMPLE.com/x", Type: models.LinkInternal},
+				{URL: "https://other.org/", Type: models.LinkExternal},
+				{URL: "http://sub.example.com/", Type: models.LinkExternal},
+			},
+		},
+		{
+			name: "repeated hrefs are counted once",
+			links: []models.Link{
+				{Href: "/a"}, {Href: "https://example.com/a", Text: "A"}, {Href: "/a#part", Text: "Part"}, {Href: "/b"},
+			},
+			want: []ExtractedLink{
+				{URL: "https://example.com/a", Text: "A", Type: models.LinkInternal, Count: 3},
+				{URL: "https://example.com/b", Type: models.LinkInternal},
+			},
+		},
+		{
+			name:  "fragment-only and self links are skipped",
+			links: []models.Link{{Href: "#intro"}, {Href: ""}, {Href: "page#usage"}, {Href: "https://example.com/docs/page"}},
+			want:  []ExtractedLink{},
+		},
+		{
+			name: "non-HTTP schemes are skipped",
+			links: []models.Link{
+				{Href: "mailto:team@example.com"}, {Href: "javascript:void(0)"}, {Href: "tel:+15555550100"}, {Href: " /kept "},
+			},
+			want: []ExtractedLink{{URL: "https://example.com/kept", Type: models.LinkInternal}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := &models.Page{
+				URL:         "https://example.com/docs/page",
+				FlatContent: []models.ContentBlock{{Type: "p", Text: "Links.", Links: tt.links}},
+			}
+			got := ExtractLinks(page)
+			if got == nil {
+				t.Fatal("ExtractLinks() = nil")
+			}
+			if !reflect.DeepEqual(got.Links, tt.want) {
+				t.Errorf("Links = %+v, want %+v", got.Links, tt.want)
+			}
+			internal := 0
+			for _, link := range tt.want {
+				if link.Type == models.LinkInternal {
+					internal++
+				}
+			}
+			if got.Total != len(tt.want) || got.Internal != internal || got.External != len(tt.want)-internal {
+				t.Errorf("totals = %d/%d/%d, want %d/%d/%d",
+					got.Total, got.Internal, got.External, len(tt.want), internal, len(tt.want)-internal)
+			}
+		})
+	}
+}
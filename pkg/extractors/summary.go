@@ -0,0 +1,295 @@
+package extractors
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// SummaryFile is the per-URL artifact Summarize's output is stored as.
+const SummaryFile = "llm-summary.md"
+
+// Summary limits: the digest is meant to be read before deciding to read the page.
+const (
+	summaryLeadSentences = 2  // Sentences of the lead paragraph
+	summaryMaxFindings   = 5  // Key findings of an academic page
+	summaryMaxAPIs       = 10 // Key APIs of a docs page
+	summaryMaxOutline    = 8  // Headings of the generic outline
+	summaryMaxAbstract   = 120
+)
+
+// summaryTemplates pick what a digest shows for each content type; other types get
+// the generic overview and outline.
+var summaryTemplates = map[string]func(*strings.Builder, *models.Page){
+	"academic": academicSummary,
+	"docs":     docsSummary,
+	"news":     newsSummary,
+}
+
+var (
+	findingsHeading = regexp.MustCompile(`(?i)\b(results?|findings|conclusions?|discussion|contributions?)\b`)
+	// A heading naming an API: a call like "Open(name)", or one token with a dot,
+	// underscore or inner capital, like "http.Get", "max_retries" or "readFile"
+	apiHeading = regexp.MustCompile(`^[\w.$:]+\s*\(.*\)$|^[A-Za-z_$][\w$]*(?:[._:]+[\w$]+)+$|^[a-z_$][a-z0-9_$]*[A-Z][\w$]*$`)
+)
+
+// Summarize renders a short Markdown digest of page from its own content, using a
+// template chosen by content type: academic pages get the abstract and key findings,
+// docs pages their purpose and key APIs, news pages who, what and when, and anything
+// else an overview and outline. It calls nothing external and is deterministic.
+// A page without text returns "".
+func Summarize(page *models.Page) string {
+	if page == nil || len(page.AllTextBlocks()) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	title := page.Title
+	if title == "" {
+		title = page.URL
+	}
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+
+	var facts []string
+	if page.URL != "" {
+		facts = append(facts, page.URL)
+	}
+	if kind := page.Metadata.ContentType; kind != "" {
+		if page.Metadata.ContentSubtype != "" {
+			kind += "/" + page.Metadata.ContentSubtype
+		}
+		facts = append(facts, kind)
+	}
+	if date := summaryDate(page); date != "" {
+		facts = append(facts, date)
+	}
+	if page.Metadata.WordCount > 0 {
+		facts = append(facts, fmt.Sprintf("%d words", page.Metadata.WordCount))
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(&sb, "%s\n\n", strings.Join(facts, " · "))
+	}
+
+	template, ok := summaryTemplates[page.Metadata.ContentType]
+	if !ok {
+		template = genericSummary
+	}
+	template(&sb, page)
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// academicSummary: abstract, authors and key findings.
+func academicSummary(sb *strings.Builder, page *models.Page) {
+	abstract := ""
+	if pub := page.Metadata.Publication; pub != nil {
+		if len(pub.Authors) > 0 {
+			fmt.Fprintf(sb, "**Authors:** %s\n\n", strings.Join(pub.Authors, ", "))
+		}
+		abstract = pub.Abstract
+	}
+	if abstract == "" {
+		if s := extractAbstract(page.Content); s != nil {
+			abstract = s.Content
+		}
+	}
+	if abstract == "" {
+		abstract = page.Metadata.Excerpt
+	}
+	if abstract != "" {
+		fmt.Fprintf(sb, "## Abstract\n\n%s\n\n", truncateWords(abstract, summaryMaxAbstract))
+	}
+
+	var findings []string
+	var walk func(sections []models.Section, inFindings bool)
+	walk = func(sections []models.Section, inFindings bool) {
+		for _, s := range sections {
+			here := inFindings || (s.Heading != nil && findingsHeading.MatchString(s.Heading.Text))
+			if here {
+				for _, b := range s.Blocks {
+					if len(findings) == summaryMaxFindings {
+						return
+					}
+					if b.Type == "p" || b.Type == "li" {
+						if first := leadSentences(b.Text, 1); first != "" {
+							findings = append(findings, first)
+						}
+					}
+				}
+			}
+			walk(s.Children, here)
+		}
+	}
+	walk(page.Content, false)
+	if len(findings) > 0 {
+		sb.WriteString("## Key findings\n\n")
+		for _, f := range findings {
+			fmt.Fprintf(sb, "- %s\n", f)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// docsSummary: what the page is for, the APIs it documents and its examples.
+func docsSummary(sb *strings.Builder, page *models.Page) {
+	if purpose := leadText(page); purpose != "" {
+		fmt.Fprintf(sb, "## Purpose\n\n%s\n\n", purpose)
+	}
+
+	docs := ExtractDocs(page)
+	var apis []string
+	seen := make(map[string]bool)
+	add := func(api string) {
+		if api != "" && !seen[api] && len(apis) < summaryMaxAPIs {
+			seen[api] = true
+			apis = append(apis, api)
+		}
+	}
+	for _, block := range page.AllTextBlocks() {
+		if isHeadingBlock(block.Type) && apiHeading.MatchString(strings.TrimSpace(block.Text)) {
+			add("`" + strings.TrimSpace(block.Text) + "`")
+		}
+	}
+	for _, p := range docs.APIParams {
+		entry := "`" + p.Name + "`"
+		if p.Type != "" {
+			entry += " (" + p.Type + ")"
+		}
+		if p.Description != "" {
+			entry += ": " + leadSentences(p.Description, 1)
+		}
+		add(entry)
+	}
+	if len(apis) > 0 {
+		sb.WriteString("## Key APIs\n\n")
+		for _, api := range apis {
+			fmt.Fprintf(sb, "- %s\n", api)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(docs.CodeBlocks) > 0 {
+		languages := make(map[string]int)
+		var order []string
+		for _, c := range docs.CodeBlocks {
+			lang := c.Language
+			if lang == "" {
+				lang = "unlabelled"
+			}
+			if languages[lang] == 0 {
+				order = append(order, lang)
+			}
+			languages[lang]++
+		}
+		parts := make([]string, len(order))
+		for i, lang := range order {
+			parts[i] = fmt.Sprintf("%d %s", languages[lang], lang)
+		}
+		fmt.Fprintf(sb, "**Code examples:** %s\n\n", strings.Join(parts, ", "))
+	}
+	if docs.VersionInfo != "" {
+		fmt.Fprintf(sb, "**Version:** %s\n\n", docs.VersionInfo)
+	}
+}
+
+// newsSummary: who reported it, what happened and when.
+func newsSummary(sb *strings.Builder, page *models.Page) {
+	var who []string
+	if page.Metadata.Author != "" {
+		who = append(who, page.Metadata.Author)
+	}
+	if page.Metadata.SiteName != "" {
+		who = append(who, page.Metadata.SiteName)
+	}
+	if len(who) > 0 {
+		fmt.Fprintf(sb, "**Who:** %s\n\n", strings.Join(who, ", "))
+	}
+	if what := leadText(page); what != "" {
+		fmt.Fprintf(sb, "**What:** %s\n\n", what)
+	}
+	if when := summaryDate(page); when != "" {
+		fmt.Fprintf(sb, "**When:** %s\n\n", when)
+	}
+}
+
+// genericSummary: the lead and the top-level outline.
+func genericSummary(sb *strings.Builder, page *models.Page) {
+	if lead := leadText(page); lead != "" {
+		fmt.Fprintf(sb, "## Overview\n\n%s\n\n", lead)
+	}
+
+	var outline []string
+	for _, block := range page.AllTextBlocks() {
+		if len(outline) == summaryMaxOutline {
+			break
+		}
+		if (block.Type == "h1" || block.Type == "h2") && block.Text != "" && block.Text != page.Title {
+			outline = append(outline, block.Text)
+		}
+	}
+	if len(outline) > 0 {
+		sb.WriteString("## Outline\n\n")
+		for _, heading := range outline {
+			fmt.Fprintf(sb, "- %s\n", heading)
+		}
+		sb.WriteString("\n")
+	}
+	if len(page.Metadata.TopKeywords) > 0 {
+		keywords := page.Metadata.TopKeywords
+		if len(keywords) > 8 {
+			keywords = keywords[:8]
+		}
+		fmt.Fprintf(sb, "**Keywords:** %s\n\n", strings.Join(keywords, ", "))
+	}
+}
+
+// leadText is the page's opening: the first sentences of its first paragraph, or
+// the meta description when there is no paragraph.
+func leadText(page *models.Page) string {
+	for _, block := range page.AllTextBlocks() {
+		if block.Type == "p" && len(strings.Fields(block.Text)) >= 8 {
+			return leadSentences(block.Text, summaryLeadSentences)
+		}
+	}
+	return strings.TrimSpace(page.Metadata.Excerpt)
+}
+
+// summaryDate is the page's date with its kind, e.g. "published 2024-03-01".
+func summaryDate(page *models.Page) string {
+	switch {
+	case page.Metadata.ContentDate != "" && page.Metadata.ContentDateKind != "":
+		return page.Metadata.ContentDateKind + " " + page.Metadata.ContentDate
+	case page.Metadata.ContentDate != "":
+		return page.Metadata.ContentDate
+	}
+	return page.Metadata.PublishedTime
+}
+
+// leadSentences returns the first n sentences of text, on one line.
+func leadSentences(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	end, found := 0, 0
+	for i := 0; i < len(text)-1 && found < n; i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if text[i+1] == ' ' {
+				end = i + 1
+				found++
+			}
+		}
+	}
+	if found < n || end == 0 {
+		return text
+	}
+	return text[:end]
+}
+
+// truncateWords keeps the first max words of text, marking a cut with an ellipsis.
+func truncateWords(text string, max int) string {
+	words := strings.Fields(text)
+	if len(words) <= max {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:max], " ") + "…"
+}
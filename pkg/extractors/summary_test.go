@@ -0,0 +1,125 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestSummarize_Academic(t *testing.T) {
+	page := &models.Page{
+		URL:   "https://arxiv.org/abs/2401.00001",
+		Title: "Sparse Attention at Scale",
+		Content: []models.Section{
+			{ID: "s1", Level: 2, Heading: &models.ContentBlock{Type: "h2", Text: "Abstract"}, Blocks: []models.ContentBlock{
+				{Type: "p", Text: "We study sparse attention. It halves memory use."},
+			}},
+			{ID: "s2", Level: 2, Heading: &models.ContentBlock{Type: "h2", Text: "Methods"}, Blocks: []models.ContentBlock{
+				{Type: "p", Text: "We train twelve models. Each runs for a week."},
+			}},
+			{ID: "s3", Level: 2, Heading: &models.ContentBlock{Type: "h2", Text: "Results"}, Blocks: []models.ContentBlock{
+				{Type: "p", Text: "Sparse models match dense accuracy. The gap closes with scale."},
+				{Type: "p", Text: "Memory drops by 48%. Latency is unchanged."},
+			}},
+		},
+		Metadata: models.PageMetadata{ContentType: "academic", ContentSubtype: "arxiv-paper"},
+	}
+
+	got := Summarize(page)
+	for _, want := range []string{
+		"# Sparse Attention at Scale\n",
+		"academic/arxiv-paper",
+		"## Abstract\n\nWe study sparse attention. It halves memory use.\n",
+		"## Key findings\n\n- Sparse models match dense accuracy.\n- Memory drops by 48%.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "twelve models") {
+		t.Errorf("Summarize() took a finding from Methods:\n%s", got)
+	}
+	if Summarize(page) != got {
+		t.Error("Summarize() is not deterministic")
+	}
+}
+
+func TestSummarize_Docs(t *testing.T) {
+	page := &models.Page{
+		Title: "net/http",
+		FlatContent: []models.ContentBlock{
+			{Type: "h1", Text: "net/http"},
+			{Type: "p", Text: "Package http provides HTTP client and server implementations for Go programs. Get, Head and Post make requests."},
+			{Type: "h2", Text: "Overview"},
+			{Type: "h2", Text: "http.Get"},
+			{Type: "h3", Text: "ListenAndServe(addr, handler)"},
+			{Type: "code", Code: &models.Code{Language: "go", Content: "resp, err := http.Get(url)"}},
+		},
+		Metadata: models.PageMetadata{ContentType: "docs"},
+	}
+
+	got := Summarize(page)
+	for _, want := range []string{
+		"## Purpose\n\nPackage http provides HTTP client and server implementations for Go programs. Get, Head and Post make requests.\n",
+		"## Key APIs\n\n- `http.Get`\n- `ListenAndServe(addr, handler)`\n",
+		"**Code examples:** 1 go",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "`Overview`") {
+		t.Errorf("Summarize() listed a prose heading as an API:\n%s", got)
+	}
+}
+
+func TestSummarize_News(t *testing.T) {
+	page := &models.Page{
+		Title: "City council approves budget",
+		FlatContent: []models.ContentBlock{
+			{Type: "p", Text: "The city council approved a 2.1 billion dollar budget on Tuesday night. The vote was 7 to 2. Debate lasted four hours."},
+		},
+		Metadata: models.PageMetadata{
+			ContentType:     "news",
+			Author:          "Jane Doe",
+			SiteName:        "Metro Daily",
+			ContentDate:     "2025-06-03",
+			ContentDateKind: "published",
+		},
+	}
+
+	got := Summarize(page)
+	for _, want := range []string{
+		"**Who:** Jane Doe, Metro Daily\n",
+		"**What:** The city council approved a 2.1 billion dollar budget on Tuesday night. The vote was 7 to 2.\n",
+		"**When:** published 2025-06-03\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestSummarize_Generic(t *testing.T) {
+	page := &models.Page{
+		Title: "About us",
+		FlatContent: []models.ContentBlock{
+			{Type: "h1", Text: "About us"},
+			{Type: "p", Text: "We build tools for reading the web with fewer tokens and less noise."},
+			{Type: "h2", Text: "Team"},
+			{Type: "h2", Text: "History"},
+		},
+		Metadata: models.PageMetadata{ContentType: "landing", TopKeywords: []string{"tools", "web"}},
+	}
+
+	got := Summarize(page)
+	for _, want := range []string{"## Overview\n", "## Outline\n\n- Team\n- History\n", "**Keywords:** tools, web"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summarize() missing %q in:\n%s", want, got)
+		}
+	}
+	if Summarize(&models.Page{Title: "Empty"}) != "" {
+		t.Error("Summarize() of a page without text should be empty")
+	}
+}
@@ -0,0 +1,204 @@
+package fetcher
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when a host has failed too often and is cooling down.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// HostBreaker is a per-host circuit breaker. After Threshold consecutive failures
+// to a host, requests to it are short-circuited until Cooldown has elapsed.
+type HostBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	consecutiveFailures int
+	totalFailures       int
+	shortCircuited      int
+	openUntil           time.Time
+	opened              bool
+	probing             bool      // Half-open: one request is testing the host, the rest are short-circuited
+	pausedUntil         time.Time // Retry-After: hold requests to the host until then
+}
+
+// HostFailureStats summarizes failures for a single host.
+type HostFailureStats struct {
+	Host           string `json:"host" yaml:"host"`
+	Failures       int    `json:"failures" yaml:"failures"`
+	ShortCircuited int    `json:"short_circuited,omitempty" yaml:"short_circuited,omitempty"`
+	CircuitOpened  bool   `json:"circuit_opened,omitempty" yaml:"circuit_opened,omitempty"`
+}
+
+// NewHostBreaker creates a breaker. A threshold <= 0 disables short-circuiting
+// but failures are still counted for reporting.
+func NewHostBreaker(threshold int, cooldown time.Duration) *HostBreaker {
+	return &HostBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*hostState),
+	}
+}
+
+// Allow reports whether a request to host may proceed, and whether the caller is the
+// half-open probe. A probe must end with RecordSuccess, RecordFailure or EndProbe.
+func (b *HostBreaker) Allow(host string) (allowed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.hosts[host]
+	if !ok {
+		return true, false
+	}
+	if state.probing || (!state.openUntil.IsZero() && time.Now().Before(state.openUntil)) {
+		state.shortCircuited++
+		return false, false
+	}
+	if state.openUntil.IsZero() {
+		return true, false
+	}
+
+	// Cooldown elapsed: half-open, let one request through to probe the host while the
+	// rest stay short-circuited. A further failure re-opens the circuit immediately.
+	state.openUntil = time.Time{}
+	state.probing = true
+	state.consecutiveFailures = b.threshold - 1
+	return true, true
+}
+
+// EndProbe settles a half-open probe that ended without a verdict on the host, such as a
+// cancelled request or a 404: the circuit goes back to open with its cooldown elapsed,
+// so the next caller probes again. It does nothing when no probe is in flight.
+func (b *HostBreaker) EndProbe(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, ok := b.hosts[host]; ok && state.probing {
+		state.probing = false
+		state.openUntil = time.Now()
+	}
+}
+
+// Pause holds requests to host until until, as a Retry-After response asks.
+// An earlier pause never shortens a later one.
+func (b *HostBreaker) Pause(host string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state(host)
+	if until.After(state.pausedUntil) {
+		state.pausedUntil = until
+	}
+}
+
+// PausedUntil returns when requests to host may resume (zero or past = now).
+func (b *HostBreaker) PausedUntil(host string) time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, ok := b.hosts[host]; ok {
+		return state.pausedUntil
+	}
+	return time.Time{}
+}
+
+// OpenUntil short-circuits host until until, regardless of its failure count, for a host
+// that asked for a longer Retry-After than the fetcher will wait. A threshold <= 0
+// disables short-circuiting here too.
+func (b *HostBreaker) OpenUntil(host string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+	state := b.state(host)
+	if until.After(state.openUntil) {
+		state.openUntil = until
+	}
+	state.opened = true
+}
+
+// state returns host's state, creating it. The caller holds b.mu.
+func (b *HostBreaker) state(host string) *hostState {
+	state, ok := b.hosts[host]
+	if !ok {
+		state = &hostState{}
+		b.hosts[host] = state
+	}
+	return state
+}
+
+// RecordSuccess resets the consecutive failure count for host.
+func (b *HostBreaker) RecordSuccess(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if state, ok := b.hosts[host]; ok {
+		state.consecutiveFailures = 0
+		state.probing = false
+	}
+}
+
+// RecordFailure counts a failure for host, opening the circuit once the threshold is reached.
+func (b *HostBreaker) RecordFailure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state(host)
+	state.consecutiveFailures++
+	state.totalFailures++
+	state.probing = false
+
+	if b.threshold > 0 && state.consecutiveFailures >= b.threshold {
+		state.openUntil = time.Now().Add(b.cooldown)
+		state.opened = true
+	}
+}
+
+// Stats returns failure counts for every host that failed at least once, worst first.
+func (b *HostBreaker) Stats() []HostFailureStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make([]HostFailureStats, 0, len(b.hosts))
+	for host, state := range b.hosts {
+		if state.totalFailures == 0 && state.shortCircuited == 0 {
+			continue
+		}
+		stats = append(stats, HostFailureStats{
+			Host:           host,
+			Failures:       state.totalFailures,
+			ShortCircuited: state.shortCircuited,
+			CircuitOpened:  state.opened,
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Failures != stats[j].Failures {
+			return stats[i].Failures > stats[j].Failures
+		}
+		return stats[i].Host < stats[j].Host
+	})
+
+	return stats
+}
+
+// hostOf returns the lowercase host of rawURL, or rawURL itself if it cannot be parsed.
+func hostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(parsed.Host)
+}
@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// allow reports whether b lets a request to host through, whether or not as the probe.
+func allow(b *HostBreaker, host string) bool {
+	allowed, _ := b.Allow(host)
+	return allowed
+}
+
+func TestHostBreaker_OpensAfterThreshold(t *testing.T) {
+	b := NewHostBreaker(2, time.Hour)
+
+	b.RecordFailure("a.example")
+	if !allow(b, "a.example") {
+		t.Fatal("Allow() = false after one failure, want the circuit closed below the threshold")
+	}
+	// A success in between resets the run of failures
+	b.RecordSuccess("a.example")
+	b.RecordFailure("a.example")
+	if !allow(b, "a.example") {
+		t.Fatal("Allow() = false, want the failure count reset by the success")
+	}
+
+	b.RecordFailure("a.example")
+	if allow(b, "a.example") {
+		t.Fatal("Allow() = true after two failures in a row, want the circuit open")
+	}
+	if !allow(b, "b.example") {
+		t.Error("Allow() for another host = false, want hosts tracked separately")
+	}
+
+	want := []HostFailureStats{{Host: "a.example", Failures: 3, ShortCircuited: 1, CircuitOpened: true}}
+	if got := b.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestHostBreaker_HalfOpenLetsOneProbeThrough(t *testing.T) {
+	b := NewHostBreaker(1, 10*time.Millisecond)
+	b.RecordFailure("a.example")
+	if allow(b, "a.example") {
+		t.Fatal("Allow() = true during the cooldown, want false")
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if !allow(b, "a.example") {
+		t.Fatal("Allow() = false after the cooldown, want one probe through")
+	}
+	if allow(b, "a.example") {
+		t.Fatal("Allow() = true for a second caller while the probe is in flight, want false")
+	}
+
+	// A successful probe closes the circuit for everyone
+	b.RecordSuccess("a.example")
+	if !allow(b, "a.example") || !allow(b, "a.example") {
+		t.Error("Allow() = false after a successful probe, want the circuit closed")
+	}
+}
+
+func TestHostBreaker_FailedProbeReopens(t *testing.T) {
+	b := NewHostBreaker(3, 10*time.Millisecond)
+	for range 3 {
+		b.RecordFailure("a.example")
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if !allow(b, "a.example") {
+		t.Fatal("Allow() = false after the cooldown, want a probe")
+	}
+	// One failure is enough to re-open a half-open circuit
+	b.RecordFailure("a.example")
+	if allow(b, "a.example") {
+		t.Error("Allow() = true after the probe failed, want the circuit re-opened")
+	}
+}
+
+func TestHostBreaker_EndProbe(t *testing.T) {
+	b := NewHostBreaker(1, 10*time.Millisecond)
+	b.RecordFailure("a.example")
+	time.Sleep(20 * time.Millisecond)
+
+	if allowed, probe := b.Allow("a.example"); !allowed || !probe {
+		t.Fatalf("Allow() = %v, %v after the cooldown, want a probe", allowed, probe)
+	}
+	// A probe that ends without a verdict hands the probe to the next caller, and only to it
+	b.EndProbe("a.example")
+	if allowed, probe := b.Allow("a.example"); !allowed || !probe {
+		t.Fatalf("Allow() = %v, %v after EndProbe(), want the next caller to probe", allowed, probe)
+	}
+	if allow(b, "a.example") {
+		t.Error("Allow() = true for a caller behind the new probe, want false")
+	}
+
+	// Without a probe in flight EndProbe changes nothing
+	b.EndProbe("b.example")
+	if allowed, probe := b.Allow("b.example"); !allowed || probe {
+		t.Errorf("Allow() = %v, %v for a host that never failed, want allowed without probing", allowed, probe)
+	}
+}
+
+func TestHostBreaker_Pause(t *testing.T) {
+	b := NewHostBreaker(0, time.Minute)
+	later := time.Now().Add(time.Minute)
+	b.Pause("a.example", later)
+	b.Pause("a.example", time.Now())
+	if got := b.PausedUntil("a.example"); !got.Equal(later) {
+		t.Errorf("PausedUntil() = %s, want the later pause %s kept", got, later)
+	}
+	if got := b.PausedUntil("b.example"); !got.IsZero() {
+		t.Errorf("PausedUntil() for an unpaused host = %s, want zero", got)
+	}
+
+	// Threshold 0 counts failures but never short-circuits
+	for range 5 {
+		b.RecordFailure("a.example")
+	}
+	b.OpenUntil("a.example", later)
+	if !allow(b, "a.example") {
+		t.Error("Allow() = false with threshold 0, want breaking disabled")
+	}
+}
@@ -0,0 +1,84 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Error categories: a stable, coarse classification of why a fetch failed, for
+// triaging failures without parsing error messages.
+const (
+	CategoryTimeout     = "timeout"      // Deadline or client timeout
+	CategoryCircuitOpen = "circuit_open" // Host skipped after repeated failures
+	CategoryRateLimited = "rate_limited" // 429, or a Retry-After longer than allowed
+	CategoryHTTPClient  = "http_4xx"     // Any other 4xx status
+	CategoryHTTPServer  = "http_5xx"     // 5xx status
+	CategoryHTTPOther   = "http_status"  // Non-200 status outside 4xx/5xx (e.g. an unfollowed 3xx)
+	CategoryDNS         = "dns"          // Host name did not resolve
+	CategoryTLS         = "tls"          // Certificate or handshake failure
+	CategoryConnection  = "connection"   // Refused, reset or unreachable
+	CategoryCancelled   = "cancelled"    // Fetch cancelled by the caller
+	CategoryUnknown     = "unknown"
+)
+
+// Categorize classifies an error returned by GetHtmlBytes from its typed cause. The
+// checks run from most to least specific, so a 429 that exhausted its Retry-After
+// budget is rate_limited rather than http_4xx.
+func Categorize(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var statusErr *StatusError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var opErr *net.OpError
+	var certInvalid x509.CertificateInvalidError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certVerify *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return CategoryCircuitOpen
+	case errors.Is(err, ErrRetryAfterTooLong):
+		return CategoryRateLimited
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return CategoryRateLimited
+		case statusErr.StatusCode >= 500:
+			return CategoryHTTPServer
+		case statusErr.StatusCode >= 400:
+			return CategoryHTTPClient
+		}
+		return CategoryHTTPOther
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimeout
+	case errors.Is(err, context.Canceled):
+		return CategoryCancelled
+	case errors.As(err, &dnsErr):
+		return CategoryDNS
+	case errors.As(err, &certInvalid), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr),
+		errors.As(err, &certVerify), errors.As(err, &recordErr):
+		return CategoryTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CategoryTimeout
+	case errors.As(err, &opErr):
+		return CategoryConnection
+	}
+	return CategoryUnknown
+}
+
+// StatusCode returns the HTTP status carried by err, or 0 when the server never answered.
+func StatusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
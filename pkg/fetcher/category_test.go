@@ -0,0 +1,49 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"not found", &StatusError{StatusCode: 404}, CategoryHTTPClient},
+		{"too many requests", &StatusError{StatusCode: 429}, CategoryRateLimited},
+		{"retry-after too long", fmt.Errorf("host asked to retry (%w): %w", ErrRetryAfterTooLong, &StatusError{StatusCode: 503}), CategoryRateLimited},
+		{"server error", &StatusError{StatusCode: 502}, CategoryHTTPServer},
+		{"unfollowed redirect", &StatusError{StatusCode: 304}, CategoryHTTPOther},
+		{"circuit open", fmt.Errorf("skipping example.com: %w", ErrCircuitOpen), CategoryCircuitOpen},
+		{"deadline", fmt.Errorf("fetch cancelled: %w", context.DeadlineExceeded), CategoryTimeout},
+		{"cancelled", fmt.Errorf("fetch cancelled: %w", context.Canceled), CategoryCancelled},
+		{"dns", fmt.Errorf("failed to make HTTP request: %w", &net.DNSError{Err: "no such host", Name: "nope.invalid"}), CategoryDNS},
+		{"tls", fmt.Errorf("failed to make HTTP request: %w", x509.UnknownAuthorityError{}), CategoryTLS},
+		{"refused", fmt.Errorf("failed to make HTTP request: %w", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), CategoryConnection},
+		{"other", errors.New("failed to read response body: unexpected EOF"), CategoryUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Categorize(tt.err); got != tt.want {
+				t.Errorf("Categorize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	if got := StatusCode(fmt.Errorf("wrapped: %w", &StatusError{StatusCode: 410})); got != 410 {
+		t.Errorf("StatusCode() = %d, want 410", got)
+	}
+	if got := StatusCode(errors.New("connection reset")); got != 0 {
+		t.Errorf("StatusCode() = %d, want 0", got)
+	}
+}
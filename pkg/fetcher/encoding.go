@@ -0,0 +1,44 @@
+package fetcher
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is advertised on every request. Setting it ourselves turns off the
+// transport's transparent gzip handling, so readBody decodes gzip as well as Brotli.
+const acceptEncoding = "gzip, br"
+
+// readBody reads resp's body, undoing each Content-Encoding the server applied. Codings
+// are listed in the order they were applied, so they are removed last to first.
+func readBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	codings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		switch coding := strings.ToLower(strings.TrimSpace(codings[i])); coding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode gzip response body: %w", err)
+			}
+			defer gz.Close()
+			body = gz
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			return nil, fmt.Errorf("unsupported Content-Encoding %q", coding)
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
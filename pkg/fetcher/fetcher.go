@@ -2,10 +2,11 @@ package fetcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 )
@@ -20,18 +21,66 @@ type FetchResponse struct {
 	Headers       http.Header
 }
 
+// HTTPMetadata describes the response a page body came from.
+type HTTPMetadata struct {
+	StatusCode    int
+	ContentType   string
+	FinalURL      string   // URL after following redirects
+	RedirectChain []string // Each URL redirected to, in order
+	Validators    Validators
+	UserAgent     string   // Agent from the pool the request was sent with ("" = Go default)
+	RobotsTags    []string // X-Robots-Tag header values, one per header line
+}
+
+// Options configures retries, per-host circuit breaking and concurrency, and User-Agent rotation.
+type Options struct {
+	Retry            RetryPolicy
+	BreakerThreshold int            // Consecutive failures before a host is short-circuited (0 = never)
+	BreakerCooldown  time.Duration  // How long a host stays short-circuited
+	UserAgents       *UserAgentPool // nil sends Go's default User-Agent
+	// Per-host concurrency that backs off on 429/503 (Initial 0 = unlimited)
+	HostConcurrency HostConcurrency
+	// Context cancels in-flight requests and retry backoffs when done (nil = never)
+	Context context.Context
+}
+
 type Fetcher struct {
-	client *http.Client
+	client  *http.Client
+	retry   RetryPolicy
+	breaker *HostBreaker
+	limiter *HostLimiter
+	agents  *UserAgentPool
+	ctx     context.Context
 }
 
+// NewFetcher creates a fetcher with no retries and no circuit breaking.
 func NewFetcher() *Fetcher {
+	return NewFetcherWithOptions(Options{})
+}
+
+// NewFetcherWithOptions creates a fetcher with the given retry and breaker settings.
+func NewFetcherWithOptions(opts Options) *Fetcher {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	return &Fetcher{
-		client: &http.Client{},
+		client:  &http.Client{},
+		retry:   opts.Retry,
+		breaker: NewHostBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		limiter: NewHostLimiter(opts.HostConcurrency),
+		agents:  opts.UserAgents,
+		ctx:     ctx,
 	}
 }
 
+// HostFailures returns per-host failure counts accumulated by this fetcher.
+func (f *Fetcher) HostFailures() []HostFailureStats {
+	return f.breaker.Stats()
+}
+
 func (f *Fetcher) GetHtml(url string) (*goquery.Document, error) {
-    bodyBytes, err := f.GetHtmlBytes(url)
+    bodyBytes, _, err := f.GetHtmlBytes(url)
     if err != nil {
         return nil, err
     }
@@ -43,26 +92,144 @@ func (f *Fetcher) GetHtml(url string) (*goquery.Document, error) {
     return doc, nil
 }
 
-func (f *Fetcher) GetHtmlBytes(url string) ([]byte, error) {
-    req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create HTTP request: %w", err)
-    }
-    resp, err := f.client.Do(req)
-    if err != nil {
-        return nil, fmt.Errorf("failed to make HTTP request: %w", err)
-    }
-    defer func() { _ = resp.Body.Close() }()
+// GetHtmlBytes fetches url, retrying transient failures with jittered backoff, or after
+// the wait a 429/503 asks for with Retry-After. Such a wait pauses every request to the
+// host, and one beyond RetryPolicy.MaxRetryAfter fails with ErrRetryAfterTooLong and
+// opens the host's circuit until then. Each attempt also takes one of the host's
+// HostConcurrency slots, and every 429/503 shrinks them.
+// Returns an error wrapping ErrCircuitOpen if the host is currently short-circuited, and
+// one wrapping the context's error once the fetcher's context is done.
+// The metadata is non-nil whenever the server answered, including with a non-200 status.
+func (f *Fetcher) GetHtmlBytes(url string) ([]byte, *HTTPMetadata, error) {
+	host := hostOf(url)
 
-    if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("failed to fetch HTML, status code: %d", resp.StatusCode)
-    }
+	allowed, probe := f.breaker.Allow(host)
+	if !allowed {
+		return nil, nil, fmt.Errorf("skipping %s: %w", host, ErrCircuitOpen)
+	}
+	if probe {
+		// Returns that neither record a success nor a failure leave the probe unsettled
+		defer f.breaker.EndProbe(host)
+	}
 
-    bodyBytes, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return nil, fmt.Errorf("failed to read response body: %w", err)
-    }
-    return bodyBytes, nil
+	var lastErr error
+	var lastMeta *HTTPMetadata
+	for attempt := 0; attempt <= f.retry.MaxRetries; attempt++ {
+		delay := time.Until(f.breaker.PausedUntil(host))
+		if attempt > 0 {
+			delay = max(delay, f.retry.Backoff(attempt))
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-f.ctx.Done():
+				timer.Stop()
+				return nil, lastMeta, fmt.Errorf("fetch of %s cancelled: %w", url, f.ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		if err := f.limiter.Acquire(f.ctx, host); err != nil {
+			return nil, lastMeta, fmt.Errorf("fetch of %s cancelled: %w", url, err)
+		}
+		body, meta, err := f.getHtmlBytesOnce(url)
+		f.limiter.Release(host)
+		if err == nil {
+			f.breaker.RecordSuccess(host)
+			f.limiter.RecordSuccess(host)
+			return body, meta, nil
+		}
+		if f.ctx.Err() != nil {
+			// Cancelled by the caller, not the host's fault
+			return nil, meta, err
+		}
+
+		lastErr, lastMeta = err, meta
+		if !isRetryable(err) {
+			// The host answered; a 404 says nothing about its health
+			return nil, meta, err
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && isThrottled(statusErr.StatusCode) {
+			f.limiter.RecordThrottled(host, statusErr.StatusCode)
+		}
+		if statusErr != nil && statusErr.RetryAfter > 0 {
+			until := time.Now().Add(statusErr.RetryAfter)
+			if f.retry.MaxRetryAfter > 0 && statusErr.RetryAfter > f.retry.MaxRetryAfter {
+				f.breaker.RecordFailure(host)
+				f.breaker.OpenUntil(host, until)
+				return nil, meta, fmt.Errorf("%s asked to retry after %s, more than the %s limit (%w): %w",
+					host, statusErr.RetryAfter, f.retry.MaxRetryAfter, ErrRetryAfterTooLong, err)
+			}
+			f.breaker.Pause(host, until)
+		}
+	}
+
+	// Retries exhausted: count one failure against the host
+	f.breaker.RecordFailure(host)
+	return nil, lastMeta, lastErr
+}
+
+func (f *Fetcher) getHtmlBytesOnce(url string) ([]byte, *HTTPMetadata, error) {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create HTTP request (%w): %w", errInvalidRequest, err)
+	}
+	if (req.URL.Scheme != "http" && req.URL.Scheme != "https") || req.URL.Host == "" {
+		return nil, nil, fmt.Errorf("failed to create HTTP request for %q: %w", url, errInvalidRequest)
+	}
+	agent := f.setUserAgent(req)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if agent != "" {
+			return nil, nil, fmt.Errorf("failed to make HTTP request (user agent %q): %w", agent, err)
+		}
+		return nil, nil, fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	meta := responseMetadata(resp)
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &StatusError{StatusCode: resp.StatusCode}
+		if isThrottled(resp.StatusCode) {
+			statusErr.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, meta, statusErr
+	}
+
+	bodyBytes, err := readBody(resp)
+	if err != nil {
+		return nil, meta, err
+	}
+	return bodyBytes, meta, nil
+}
+
+// responseMetadata captures status, content type and redirects of resp. Like Fetch, the
+// chain lists each URL redirected to; every followed request keeps the response that caused it.
+func responseMetadata(resp *http.Response) *HTTPMetadata {
+	meta := &HTTPMetadata{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		FinalURL:    resp.Request.URL.String(),
+		Validators:  responseValidators(resp),
+		UserAgent:   resp.Request.Header.Get("User-Agent"),
+		RobotsTags:  resp.Header.Values("X-Robots-Tag"),
+	}
+	for req := resp.Request; req.Response != nil; req = req.Response.Request {
+		meta.RedirectChain = append([]string{req.URL.String()}, meta.RedirectChain...)
+	}
+	return meta
+}
+
+// setUserAgent sets the next pooled agent on req, if rotation is configured, and returns it.
+func (f *Fetcher) setUserAgent(req *http.Request) string {
+	agent := f.agents.For(req.URL.String())
+	if agent != "" {
+		req.Header.Set("User-Agent", agent)
+	}
+	return agent
 }
 
 // Fetch performs enriched HTTP fetch with metadata capture
@@ -81,19 +248,21 @@ func (f *Fetcher) Fetch(url string) (*FetchResponse, error) {
 		},
 	}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
+	f.setUserAgent(req)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := readBody(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	// Build response
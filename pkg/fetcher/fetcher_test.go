@@ -0,0 +1,243 @@
+package fetcher
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestGetHtmlBytesStopsWhenContextDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	f := NewFetcherWithOptions(Options{
+		Retry:            RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+		Context:          ctx,
+	})
+
+	start := time.Now()
+	_, _, err := f.GetHtmlBytes(server.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetHtmlBytes() error = %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetHtmlBytes() took %s, want the retry backoff cut short", elapsed)
+	}
+	// Cancellation is not the host's fault
+	if !allow(f.breaker, hostOf(server.URL)) {
+		t.Error("circuit opened after a cancelled fetch, want it closed")
+	}
+}
+
+func TestGetHtmlBytesInvalidURLNotRetried(t *testing.T) {
+	f := NewFetcherWithOptions(Options{
+		Retry:            RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: time.Minute},
+		BreakerThreshold: 1,
+		BreakerCooldown:  time.Minute,
+	})
+
+	for _, rawURL := range []string{"ftp://example.com/file", "https://exa mple.com/", "/relative/path"} {
+		start := time.Now()
+		if _, _, err := f.GetHtmlBytes(rawURL); !errors.Is(err, errInvalidRequest) {
+			t.Errorf("GetHtmlBytes(%q) error = %v, want an invalid request", rawURL, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("GetHtmlBytes(%q) took %s, want no retries", rawURL, elapsed)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Sat, 01 Mar 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Sat, 01 Mar 2025 11:00:00 GMT", 0, true}, // Already past
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGetHtmlBytesHonorsRetryAfter(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer server.Close()
+
+	// The backoff alone would retry almost at once
+	f := NewFetcherWithOptions(Options{
+		Retry: RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRetryAfter: time.Minute},
+	})
+	start := time.Now()
+	if _, _, err := f.GetHtmlBytes(server.URL); err != nil {
+		t.Fatalf("GetHtmlBytes() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the 1s Retry-After", elapsed)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestGetHtmlBytesRetryAfterBeyondLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	f := NewFetcherWithOptions(Options{
+		Retry:            RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxRetryAfter: time.Minute},
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Second,
+	})
+	_, _, err := f.GetHtmlBytes(server.URL)
+	if !errors.Is(err, ErrRetryAfterTooLong) {
+		t.Fatalf("GetHtmlBytes() error = %v, want ErrRetryAfterTooLong", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GetHtmlBytes() error = %v, want it to wrap the 503", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("requests = %d, want 1 (no retries)", got)
+	}
+
+	// The host's other URLs are skipped for the hour it asked for, not the 1s cooldown
+	if _, _, err := f.GetHtmlBytes(server.URL + "/other"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("next GetHtmlBytes() error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestGetHtmlBytesDecodesContentEncoding(t *testing.T) {
+	const page = "<html><body><p>Served compressed by the CDN</p></body></html>"
+	encoders := map[string]func(w io.Writer) io.WriteCloser{
+		"br":   func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) },
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+	}
+	for coding, newWriter := range encoders {
+		var accepted string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accepted = r.Header.Get("Accept-Encoding")
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", coding)
+			enc := newWriter(w)
+			enc.Write([]byte(page))
+			enc.Close()
+		}))
+
+		body, _, err := NewFetcher().GetHtmlBytes(server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: GetHtmlBytes() error = %v", coding, err)
+		}
+		if string(body) != page {
+			t.Errorf("%s: body = %q, want the decoded page", coding, body)
+		}
+		if accepted != acceptEncoding {
+			t.Errorf("%s: Accept-Encoding = %q, want %q", coding, accepted, acceptEncoding)
+		}
+	}
+}
+
+func TestGetHtmlBytesUnsupportedEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "zstd")
+		w.Write([]byte{0x28, 0xb5, 0x2f, 0xfd})
+	}))
+	defer server.Close()
+
+	if _, _, err := NewFetcher().GetHtmlBytes(server.URL); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Errorf("GetHtmlBytes() error = %v, want an unsupported zstd encoding", err)
+	}
+}
+
+func TestHeadAsksLikeGet(t *testing.T) {
+	const page = "<html><body><p>Served compressed by the CDN</p></body></html>"
+	var compressed strings.Builder
+	enc := gzip.NewWriter(&compressed)
+	enc.Write([]byte(page))
+	enc.Close()
+
+	type request struct{ method, encoding, agent string }
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, request{r.Method, r.Header.Get("Accept-Encoding"), r.Header.Get("User-Agent")})
+		body := page
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			body = compressed.String()
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if r.Method != http.MethodHead {
+			w.Write([]byte(body))
+		}
+	}))
+	defer server.Close()
+
+	agents, err := NewUserAgentPool([]string{"agent/1", "agent/2"}, RotatePerRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := NewFetcherWithOptions(Options{UserAgents: agents})
+	_, got, err := f.GetHtmlBytes(server.URL)
+	if err != nil {
+		t.Fatalf("GetHtmlBytes() error = %v", err)
+	}
+	head, err := f.Head(server.URL, got.UserAgent)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+
+	// Same encoding, so the stored Content-Length still validates the page
+	if match, ok := got.Validators.Matches(head.Validators); !ok || !match {
+		t.Errorf("HEAD validators %+v do not match GET's %+v", head.Validators, got.Validators)
+	}
+	if len(requests) != 2 || requests[1].encoding != acceptEncoding || requests[1].agent != requests[0].agent {
+		t.Errorf("requests = %+v, want a HEAD with the GET's encoding and agent", requests)
+	}
+
+	// Without a stored agent the pool picks one
+	if _, err := f.Head(server.URL, ""); err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	if last := requests[len(requests)-1]; last.agent != "agent/1" && last.agent != "agent/2" {
+		t.Errorf("HEAD without a stored agent sent %q, want one from the pool", last.agent)
+	}
+}
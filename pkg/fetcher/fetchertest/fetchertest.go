@@ -0,0 +1,113 @@
+// Package fetchertest provides an in-memory fetcher for tests that must not touch the network.
+package fetchertest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/fetcher"
+)
+
+// Fetcher serves canned HTML per URL. Unknown URLs answer 404. Each page carries an
+// ETag derived from its content, so changing a page changes its validators. Safe for
+// concurrent use.
+type Fetcher struct {
+	mu       sync.Mutex
+	pages    map[string]string
+	errors   map[string]error
+	robots   map[string][]string
+	requests []string
+	heads    []string
+}
+
+// New returns a Fetcher serving pages, keyed by exact URL.
+func New(pages map[string]string) *Fetcher {
+	f := &Fetcher{pages: make(map[string]string), errors: make(map[string]error), robots: make(map[string][]string)}
+	for url, html := range pages {
+		f.pages[url] = html
+	}
+	return f
+}
+
+// SetPage adds or replaces the page served for url.
+func (f *Fetcher) SetPage(url, html string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pages[url] = html
+}
+
+// SetRobotsTag makes responses for url carry the given X-Robots-Tag header lines.
+func (f *Fetcher) SetRobotsTag(url string, values ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.robots[url] = values
+}
+
+// FailWith makes requests for url return err instead of a page.
+func (f *Fetcher) FailWith(url string, err error) *Fetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[url] = err
+	return f
+}
+
+// GetHtmlBytes returns the canned page for url.
+func (f *Fetcher) GetHtmlBytes(url string) ([]byte, *fetcher.HTTPMetadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, url)
+
+	html, meta, err := f.lookup(url)
+	if err != nil {
+		return nil, meta, err
+	}
+	return []byte(html), meta, nil
+}
+
+// Head returns the metadata GetHtmlBytes would, without recording a page request.
+func (f *Fetcher) Head(url, _ string) (*fetcher.HTTPMetadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.heads = append(f.heads, url)
+
+	_, meta, err := f.lookup(url)
+	return meta, err
+}
+
+func (f *Fetcher) lookup(url string) (string, *fetcher.HTTPMetadata, error) {
+	if err, ok := f.errors[url]; ok {
+		return "", nil, err
+	}
+
+	html, ok := f.pages[url]
+	if !ok {
+		return "", &fetcher.HTTPMetadata{StatusCode: http.StatusNotFound, FinalURL: url},
+			&fetcher.StatusError{StatusCode: http.StatusNotFound}
+	}
+	return html, &fetcher.HTTPMetadata{
+		StatusCode:  http.StatusOK,
+		ContentType: "text/html; charset=utf-8",
+		FinalURL:    url,
+		Validators: fetcher.Validators{
+			ETag:          fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(html))),
+			ContentLength: int64(len(html)),
+		},
+		RobotsTags: f.robots[url],
+	}, nil
+}
+
+// Requests returns every URL requested so far, in request order.
+func (f *Fetcher) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+// Heads returns every URL revalidated with Head so far, in request order.
+func (f *Fetcher) Heads() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.heads...)
+}
@@ -0,0 +1,200 @@
+package fetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// hostDelayStep is the crawl delay a host gets on its first 429/503; each further one doubles it.
+	hostDelayStep = 250 * time.Millisecond
+	// hostMaxDelay caps the crawl delay between requests to a host.
+	hostMaxDelay = 10 * time.Second
+	// hostRestoreAfter is the run of successes that earns a host one more concurrent
+	// request and half its crawl delay back.
+	hostRestoreAfter = 10
+)
+
+// HostConcurrency bounds how many requests run against one host at once. The limit
+// adapts AIMD-style: a 429 or 503 halves it (not below Min) and doubles the host's crawl
+// delay, and every hostRestoreAfter successes in a row add one back (up to Max) and
+// halve the delay.
+type HostConcurrency struct {
+	Initial  int                   // Limit each host starts at (0 = adaptive limiting off)
+	Min      int                   // Floor for backoffs (<= 0 = 1)
+	Max      int                   // Ceiling for restores (< Initial = Initial)
+	OnChange func(HostLimitChange) // Called, outside the limiter's lock, whenever a host's limit or delay changes
+}
+
+// HostLimitChange describes a host's limit moving after a backoff or a run of successes.
+type HostLimitChange struct {
+	Host       string
+	Limit      int           // Concurrent requests now allowed
+	Delay      time.Duration // Minimum gap between request starts now
+	PrevLimit  int
+	PrevDelay  time.Duration
+	StatusCode int // 429 or 503 that caused a backoff (0 = restored after successes)
+}
+
+// HostLimiter enforces HostConcurrency per host.
+type HostLimiter struct {
+	cfg HostConcurrency
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimit
+}
+
+type hostLimit struct {
+	limit     int
+	active    int
+	delay     time.Duration
+	nextStart time.Time     // Earliest start of the next request, from delay
+	successes int           // Successes since the last backoff or restore
+	changed   chan struct{} // Closed and replaced whenever a slot frees or the limit grows
+}
+
+// NewHostLimiter creates a limiter, or returns nil when cfg.Initial <= 0. A nil limiter
+// lets every request through.
+func NewHostLimiter(cfg HostConcurrency) *HostLimiter {
+	if cfg.Initial <= 0 {
+		return nil
+	}
+	cfg.Min = max(cfg.Min, 1)
+	cfg.Initial = max(cfg.Initial, cfg.Min)
+	cfg.Max = max(cfg.Max, cfg.Initial)
+	return &HostLimiter{cfg: cfg, hosts: make(map[string]*hostLimit)}
+}
+
+// Acquire waits for a free request slot on host and for its crawl delay, returning the
+// context's error if ctx is done first. Every successful Acquire must be paired with Release.
+func (l *HostLimiter) Acquire(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		state := l.state(host)
+		if state.active >= state.limit {
+			changed := state.changed
+			l.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+			}
+			continue
+		}
+
+		now := time.Now()
+		wait := state.nextStart.Sub(now)
+		if wait <= 0 {
+			state.active++
+			state.nextStart = now.Add(state.delay)
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Release frees the slot taken by Acquire.
+func (l *HostLimiter) Release(host string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.state(host)
+	state.active--
+	state.notify()
+}
+
+// RecordThrottled backs host off after a 429 or 503: its limit halves and its crawl delay doubles.
+func (l *HostLimiter) RecordThrottled(host string, statusCode int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	state := l.state(host)
+	change := HostLimitChange{Host: host, PrevLimit: state.limit, PrevDelay: state.delay, StatusCode: statusCode}
+	state.successes = 0
+	state.limit = max(state.limit/2, l.cfg.Min)
+	state.delay = min(max(state.delay*2, hostDelayStep), hostMaxDelay)
+	change.Limit, change.Delay = state.limit, state.delay
+	l.mu.Unlock()
+
+	l.report(change)
+}
+
+// RecordSuccess counts a success for host, restoring one request of concurrency and
+// half the crawl delay after each hostRestoreAfter in a row.
+func (l *HostLimiter) RecordSuccess(host string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	state := l.state(host)
+	state.successes++
+	if state.successes < hostRestoreAfter || (state.limit >= l.cfg.Max && state.delay == 0) {
+		l.mu.Unlock()
+		return
+	}
+	change := HostLimitChange{Host: host, PrevLimit: state.limit, PrevDelay: state.delay}
+	state.successes = 0
+	state.limit = min(state.limit+1, l.cfg.Max)
+	state.delay /= 2
+	if state.delay < hostDelayStep {
+		state.delay = 0
+	}
+	state.notify()
+	change.Limit, change.Delay = state.limit, state.delay
+	l.mu.Unlock()
+
+	l.report(change)
+}
+
+// Limit returns host's current concurrency limit and crawl delay.
+func (l *HostLimiter) Limit(host string) (int, time.Duration) {
+	if l == nil {
+		return 0, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.state(host)
+	return state.limit, state.delay
+}
+
+// report passes change to OnChange unless nothing moved.
+func (l *HostLimiter) report(change HostLimitChange) {
+	if l.cfg.OnChange == nil || (change.Limit == change.PrevLimit && change.Delay == change.PrevDelay) {
+		return
+	}
+	l.cfg.OnChange(change)
+}
+
+// state returns host's state, creating it at the initial limit. The caller holds l.mu.
+func (l *HostLimiter) state(host string) *hostLimit {
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostLimit{limit: l.cfg.Initial, changed: make(chan struct{})}
+		l.hosts[host] = state
+	}
+	return state
+}
+
+// notify wakes every Acquire waiting on the host. The caller holds the limiter's lock.
+func (s *hostLimit) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,140 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimiterBacksOffAndRestores(t *testing.T) {
+	var changes []HostLimitChange
+	l := NewHostLimiter(HostConcurrency{Initial: 8, Min: 2, Max: 9, OnChange: func(c HostLimitChange) {
+		changes = append(changes, c)
+	}})
+
+	l.RecordThrottled("a.example", http.StatusTooManyRequests)
+	if limit, delay := l.Limit("a.example"); limit != 4 || delay != hostDelayStep {
+		t.Fatalf("after one 429: limit %d, delay %s; want 4, %s", limit, delay, hostDelayStep)
+	}
+	l.RecordThrottled("a.example", http.StatusServiceUnavailable)
+	l.RecordThrottled("a.example", http.StatusServiceUnavailable)
+	if limit, delay := l.Limit("a.example"); limit != 2 || delay != 4*hostDelayStep {
+		t.Fatalf("after three: limit %d, delay %s; want the minimum 2, %s", limit, delay, 4*hostDelayStep)
+	}
+	// Other hosts are unaffected
+	if limit, delay := l.Limit("b.example"); limit != 8 || delay != 0 {
+		t.Errorf("other host: limit %d, delay %s; want 8, 0", limit, delay)
+	}
+
+	// Additive increase: one more slot and half the delay per run of successes
+	for range hostRestoreAfter - 1 {
+		l.RecordSuccess("a.example")
+	}
+	if limit, _ := l.Limit("a.example"); limit != 2 {
+		t.Fatalf("limit %d before a full run of successes, want 2", limit)
+	}
+	l.RecordSuccess("a.example")
+	if limit, delay := l.Limit("a.example"); limit != 3 || delay != 2*hostDelayStep {
+		t.Fatalf("after %d successes: limit %d, delay %s; want 3, %s", hostRestoreAfter, limit, delay, 2*hostDelayStep)
+	}
+	for range 20 * hostRestoreAfter {
+		l.RecordSuccess("a.example")
+	}
+	if limit, delay := l.Limit("a.example"); limit != 9 || delay != 0 {
+		t.Errorf("after sustained successes: limit %d, delay %s; want the maximum 9, 0", limit, delay)
+	}
+
+	// The third backoff hit the floor but still raised the delay, so it is reported
+	if len(changes) < 4 || changes[0].StatusCode != http.StatusTooManyRequests || changes[0].PrevLimit != 8 ||
+		changes[2].Limit != 2 || changes[3].StatusCode != 0 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if last := changes[len(changes)-1]; last.Limit != 9 {
+		t.Errorf("last change = %+v, want the restore to 9", last)
+	}
+}
+
+func TestHostLimiterAcquireWaitsForSlot(t *testing.T) {
+	l := NewHostLimiter(HostConcurrency{Initial: 1})
+	ctx := context.Background()
+	if err := l.Acquire(ctx, "a.example"); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if err := l.Acquire(ctx, "a.example"); err == nil {
+			close(acquired)
+		}
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire() did not wait for the only slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Another host has its own slots
+	if err := l.Acquire(ctx, "b.example"); err != nil {
+		t.Fatalf("Acquire(other host) error = %v", err)
+	}
+
+	l.Release("a.example")
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Acquire() still waiting after Release()")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Acquire(cancelled, "a.example"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Acquire() on a full host with a done context = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewHostLimiterDisabled(t *testing.T) {
+	l := NewHostLimiter(HostConcurrency{})
+	if l != nil {
+		t.Fatal("NewHostLimiter() with no initial limit should be nil")
+	}
+	// A nil limiter lets everything through
+	if err := l.Acquire(context.Background(), "a.example"); err != nil {
+		t.Errorf("nil Acquire() error = %v", err)
+	}
+	l.Release("a.example")
+	l.RecordThrottled("a.example", http.StatusTooManyRequests)
+}
+
+func TestGetHtmlBytesThrottleReducesHostConcurrency(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("<html><body>ok</body></html>"))
+	}))
+	defer server.Close()
+
+	var throttled int32
+	f := NewFetcherWithOptions(Options{
+		Retry: RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		HostConcurrency: HostConcurrency{Initial: 4, OnChange: func(c HostLimitChange) {
+			if c.StatusCode == http.StatusTooManyRequests {
+				atomic.AddInt32(&throttled, 1)
+			}
+		}},
+	})
+	if _, _, err := f.GetHtmlBytes(server.URL); err != nil {
+		t.Fatalf("GetHtmlBytes() error = %v", err)
+	}
+	if atomic.LoadInt32(&throttled) != 1 {
+		t.Errorf("throttle reported %d times, want once", throttled)
+	}
+	if limit, delay := f.limiter.Limit(hostOf(server.URL)); limit != 2 || delay != hostDelayStep {
+		t.Errorf("host limit %d, delay %s after a 429; want 2, %s", limit, delay, hostDelayStep)
+	}
+}
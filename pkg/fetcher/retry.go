@@ -0,0 +1,84 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dtnitsch/llm-web-parser/pkg/backoff"
+)
+
+// ErrRetryAfterTooLong is returned when a 429 or 503 asks for a longer wait than
+// RetryPolicy.MaxRetryAfter allows.
+var ErrRetryAfterTooLong = errors.New("retry-after exceeds limit")
+
+// errInvalidRequest marks a fetch that failed before anything was sent, such as one for
+// a malformed or non-HTTP URL. Retrying it cannot succeed.
+var errInvalidRequest = errors.New("invalid request")
+
+// RetryPolicy controls how transient fetch failures are retried.
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt (0 = no retries)
+	BaseDelay  time.Duration // Delay before the first retry; doubles each attempt
+	MaxDelay   time.Duration // Upper bound on a single delay
+	// Longest Retry-After a 429/503 may ask for; longer requests fail the fetch (0 = no limit)
+	MaxRetryAfter time.Duration
+}
+
+// Backoff returns the delay before retry number attempt (starting at 1), using
+// exponential growth with full jitter so concurrent workers don't retry in lockstep.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	return backoff.Jittered(p.BaseDelay, p.MaxDelay, attempt)
+}
+
+// StatusError is returned when the server responds with a non-200 status code.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Wait a 429 or 503 asked for with Retry-After (0 = none)
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch HTML, status code: %d", e.StatusCode)
+}
+
+// parseRetryAfter reads a Retry-After header: delay-seconds or an HTTP date, which is
+// turned into the time left until then. It returns false for a missing or malformed value.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// isRetryable reports whether err is a transient failure worth retrying.
+// Network errors, 429 and 5xx responses are retried; other 4xx responses, requests that
+// could not be built and cancellations are not.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) || errors.Is(err, errInvalidRequest) ||
+		errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	return true
+}
+
+// isThrottled reports whether statusCode asks the client to slow down: 429 or 503.
+func isThrottled(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,63 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},  // 1.6s capped by MaxDelay
+		{70, time.Second}, // Shift overflow falls back to MaxDelay
+	}
+	for _, tt := range tests {
+		for range 50 {
+			if got := policy.Backoff(tt.attempt); got <= 0 || got > tt.ceiling {
+				t.Fatalf("Backoff(%d) = %s, want in (0, %s]", tt.attempt, got, tt.ceiling)
+			}
+		}
+	}
+
+	if got := policy.Backoff(0); got != 0 {
+		t.Errorf("Backoff(0) = %s, want 0 before the first retry", got)
+	}
+	if got := (RetryPolicy{}).Backoff(3); got != 0 {
+		t.Errorf("Backoff() without a base delay = %s, want 0", got)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"network error", errors.New("connection reset by peer"), true},
+		{"429", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"500", &StatusError{StatusCode: http.StatusInternalServerError}, true},
+		{"503 wrapped", fmt.Errorf("fetch: %w", &StatusError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"404", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"403", &StatusError{StatusCode: http.StatusForbidden}, false},
+		{"circuit open", fmt.Errorf("skipping a.example: %w", ErrCircuitOpen), false},
+		{"invalid request", fmt.Errorf("failed to create HTTP request (%w): %w", errInvalidRequest, errors.New("bad URL")), false},
+		{"cancelled", fmt.Errorf("failed to make HTTP request: %w", context.Canceled), false},
+		{"deadline", fmt.Errorf("failed to make HTTP request: %w", context.DeadlineExceeded), true},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package fetcher
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Rotation modes for a UserAgentPool.
+const (
+	RotatePerRequest = "request" // Next agent on every request, retries included
+	RotatePerHost    = "host"    // Each host keeps the agent it was first given
+)
+
+// UserAgentPool hands out User-Agent strings round-robin, either per request or per host.
+// A nil pool sends Go's default agent. Safe for concurrent use.
+type UserAgentPool struct {
+	agents  []string
+	perHost bool
+
+	mu    sync.Mutex
+	next  int
+	hosts map[string]string
+}
+
+// NewUserAgentPool returns a pool rotating agents in the given mode.
+func NewUserAgentPool(agents []string, mode string) (*UserAgentPool, error) {
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("user agent pool is empty")
+	}
+	if mode != RotatePerRequest && mode != RotatePerHost {
+		return nil, fmt.Errorf("invalid user agent rotation %q (use %s or %s)", mode, RotatePerRequest, RotatePerHost)
+	}
+	return &UserAgentPool{
+		agents:  append([]string(nil), agents...),
+		perHost: mode == RotatePerHost,
+		hosts:   make(map[string]string),
+	}, nil
+}
+
+// LoadUserAgents reads one agent per line from path, skipping blank lines and # comments.
+func LoadUserAgents(path string) ([]string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user agent file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var agents []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		agents = append(agents, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read user agent file: %w", err)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no user agents in %s", path)
+	}
+	return agents, nil
+}
+
+// For returns the agent to use for a request to url.
+func (p *UserAgentPool) For(url string) string {
+	if p == nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host := hostOf(url)
+	if p.perHost {
+		if agent, ok := p.hosts[host]; ok {
+			return agent
+		}
+	}
+	agent := p.agents[p.next%len(p.agents)]
+	p.next++
+	if p.perHost {
+		p.hosts[host] = agent
+	}
+	return agent
+}
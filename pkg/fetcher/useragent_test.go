@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserAgentPoolRotation(t *testing.T) {
+	agents := []string{"agent-a", "agent-b"}
+
+	perRequest, err := NewUserAgentPool(agents, RotatePerRequest)
+	if err != nil {
+		t.Fatalf("NewUserAgentPool() error = %v", err)
+	}
+	got := []string{perRequest.For("https://x.com/1"), perRequest.For("https://x.com/2"), perRequest.For("https://x.com/3")}
+	if got[0] != "agent-a" || got[1] != "agent-b" || got[2] != "agent-a" {
+		t.Errorf("per-request agents = %v, want a, b, a", got)
+	}
+
+	perHost, err := NewUserAgentPool(agents, RotatePerHost)
+	if err != nil {
+		t.Fatalf("NewUserAgentPool() error = %v", err)
+	}
+	x1, y, x2 := perHost.For("https://x.com/1"), perHost.For("https://y.com/"), perHost.For("https://x.com/2")
+	if x1 != x2 || x1 == y {
+		t.Errorf("per-host agents x=%q,%q y=%q, want x pinned and y different", x1, x2, y)
+	}
+
+	if _, err := NewUserAgentPool(agents, "random"); err == nil {
+		t.Error("NewUserAgentPool(random) error = nil, want invalid mode")
+	}
+	var none *UserAgentPool
+	if agent := none.For("https://x.com/"); agent != "" {
+		t.Errorf("nil pool agent = %q, want empty", agent)
+	}
+}
+
+func TestLoadUserAgents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.txt")
+	if err := os.WriteFile(path, []byte("# browsers\nagent-a\n\n  agent-b  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	agents, err := LoadUserAgents(path)
+	if err != nil {
+		t.Fatalf("LoadUserAgents() error = %v", err)
+	}
+	if len(agents) != 2 || agents[0] != "agent-a" || agents[1] != "agent-b" {
+		t.Errorf("LoadUserAgents() = %q, want [agent-a agent-b]", agents)
+	}
+}
+
+func TestGetHtmlBytesSendsPooledAgent(t *testing.T) {
+	var seen []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.UserAgent())
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	pool, _ := NewUserAgentPool([]string{"agent-a", "agent-b"}, RotatePerRequest)
+	f := NewFetcherWithOptions(Options{UserAgents: pool})
+
+	_, meta, err := f.GetHtmlBytes(server.URL)
+	if err == nil {
+		t.Fatal("GetHtmlBytes() error = nil, want 403")
+	}
+	if meta == nil || meta.UserAgent != "agent-a" {
+		t.Errorf("metadata = %+v, want UserAgent agent-a", meta)
+	}
+	if len(seen) != 1 || seen[0] != "agent-a" {
+		t.Errorf("server saw agents %v, want [agent-a]", seen)
+	}
+}
@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Validators are the response headers that identify one representation of a URL.
+// Comparing them against a HEAD response tells whether a cached body is still current
+// without trusting local file times.
+type Validators struct {
+	ETag          string
+	LastModified  string
+	ContentLength int64 // -1 when the server didn't say
+}
+
+// Empty reports whether the server sent none of the validators.
+func (v Validators) Empty() bool {
+	return v.ETag == "" && v.LastModified == "" && v.ContentLength < 0
+}
+
+// Matches compares stored validators v against current ones, strongest first: ETag, then
+// Last-Modified, then Content-Length. ok is false when no validator is present on both
+// sides, in which case match means nothing and the caller must decide another way.
+func (v Validators) Matches(current Validators) (match, ok bool) {
+	switch {
+	case v.ETag != "" && current.ETag != "":
+		return v.ETag == current.ETag, true
+	case v.LastModified != "" && current.LastModified != "":
+		return v.LastModified == current.LastModified, true
+	case v.ContentLength >= 0 && current.ContentLength >= 0:
+		return v.ContentLength == current.ContentLength, true
+	}
+	return false, false
+}
+
+func responseValidators(resp *http.Response) Validators {
+	return Validators{
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+		ContentLength: resp.ContentLength,
+	}
+}
+
+// Head issues a single HEAD request for url and returns its metadata and validators.
+// It neither retries nor counts against the host's circuit breaker: a failed HEAD only
+// means the caller falls back to another freshness check. The request negotiates the
+// same encodings as a GET, since Content-Length and the ETag describe the encoded body,
+// and sends userAgent when set (the agent the stored validators were fetched with)
+// instead of the next pooled one.
+func (f *Fetcher) Head(url, userAgent string) (*HTTPMetadata, error) {
+	req, err := http.NewRequestWithContext(f.ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HEAD request: %w", err)
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	} else {
+		f.setUserAgent(req)
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make HEAD request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+	return responseMetadata(resp), nil
+}
@@ -2,20 +2,42 @@ package mapreduce
 
 import "github.com/dtnitsch/llm-web-parser/pkg/analytics"
 
-// Map generates a word frequency map for a single document's content.
-func Map(content string, a *analytics.Analytics) map[string]int {
-	return a.WordFrequency(content)
+// Map generates a word frequency map for a single document's content, leaving out the
+// stopwords of its language (an ISO 639-1 code; unknown languages use English).
+func Map(content, lang string, a *analytics.Analytics) map[string]int {
+	return a.WordFrequencyIn(content, lang)
 }
 
 // Reduce aggregates a slice of word frequency maps into a single map.
+// For large corpora prefer a Reducer, which never needs every map at once.
 func Reduce(intermediate []map[string]int) map[string]int {
-	finalResults := make(map[string]int)
-
+	r := NewReducer()
 	for _, counts := range intermediate {
-		for word, count := range counts {
-			finalResults[word] += count
-		}
+		r.Add(counts)
 	}
+	return r.Result()
+}
+
+// Reducer folds word frequency maps into a running total as they arrive, so each
+// document's map can be released once added. Not safe for concurrent use.
+type Reducer struct {
+	counts map[string]int
+}
+
+// NewReducer returns an empty Reducer.
+func NewReducer() *Reducer {
+	return &Reducer{counts: make(map[string]int)}
+}
+
+// Add folds counts into the total. A nil map is a no-op.
+func (r *Reducer) Add(counts map[string]int) {
+	for word, count := range counts {
+		r.counts[word] += count
+	}
+}
 
-	return finalResults
+// Result returns the aggregated counts. The map is shared with the Reducer, so callers
+// should stop calling Add once they take it.
+func (r *Reducer) Result() map[string]int {
+	return r.counts
 }
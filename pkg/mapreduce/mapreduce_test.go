@@ -0,0 +1,27 @@
+package mapreduce
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestReducerMatchesReduce(t *testing.T) {
+	docs := []map[string]int{
+		{"parser": 3, "widget": 1},
+		nil,
+		{"parser": 2, "release": 4},
+	}
+
+	r := NewReducer()
+	for _, counts := range docs {
+		r.Add(counts)
+	}
+
+	want := map[string]int{"parser": 5, "widget": 1, "release": 4}
+	if got := r.Result(); !maps.Equal(got, want) {
+		t.Errorf("Reducer.Result() = %v, want %v", got, want)
+	}
+	if got := Reduce(docs); !maps.Equal(got, want) {
+		t.Errorf("Reduce() = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package mapreduce
 
 import (
+	"container/heap"
 	"fmt"
 	"sort"
 	"strings"
@@ -35,71 +36,107 @@ func isValidKeyword(word string) bool {
 	return singleQuoteCount%2 == 0
 }
 
-// TopKeywords returns the top N keywords from aggregated word counts as formatted strings.
-// Each string is formatted as "word:count" (e.g., "learning:1153").
-// Filters out malformed tokens (unmatched delimiters, trailing special chars).
-func TopKeywords(wordCounts map[string]int, n int) []string {
-	type kv struct {
-		Key   string
-		Value int
+// WordCount is a word and how often it occurred.
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// ranksBefore orders by count descending, breaking ties alphabetically so output is
+// stable across runs.
+func ranksBefore(a, b WordCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
 	}
+	return a.Word < b.Word
+}
 
-	// Convert map to slice for sorting, filtering out invalid keywords
-	var ss []kv
-	for k, v := range wordCounts {
-		if isValidKeyword(k) {
-			ss = append(ss, kv{k, v})
+// heapSelectRatio is how much larger than n the candidate set must be before a bounded
+// heap beats sorting everything.
+const heapSelectRatio = 4
+
+// Rank returns the n highest-ranked words in counts, or all of them when n <= 0.
+func Rank(counts map[string]int, n int) []WordCount {
+	return rank(counts, n, nil)
+}
+
+// rank returns the top n words accepted by keep (nil keeps all), highest first. Small n
+// against a large vocabulary uses a size-n min-heap, O(len·log n); otherwise a full sort.
+func rank(counts map[string]int, n int, keep func(string) bool) []WordCount {
+	if n <= 0 || n*heapSelectRatio >= len(counts) {
+		all := make([]WordCount, 0, len(counts))
+		for w, c := range counts {
+			if keep == nil || keep(w) {
+				all = append(all, WordCount{w, c})
+			}
+		}
+		sort.Slice(all, func(i, j int) bool { return ranksBefore(all[i], all[j]) })
+		if n > 0 && len(all) > n {
+			all = all[:n]
 		}
+		return all
 	}
 
-	// Sort by count (descending)
-	sort.Slice(ss, func(i, j int) bool {
-		return ss[i].Value > ss[j].Value
-	})
+	// h[0] is the lowest-ranked word kept so far; anything ranking above it replaces it
+	h := make(minHeap, 0, n)
+	for w, c := range counts {
+		if keep != nil && !keep(w) {
+			continue
+		}
+		wc := WordCount{w, c}
+		if len(h) < n {
+			heap.Push(&h, wc)
+		} else if ranksBefore(wc, h[0]) {
+			h[0] = wc
+			heap.Fix(&h, 0)
+		}
+	}
 
-	// Limit to top N
-	limit := n
-	if len(ss) < n {
-		limit = len(ss)
+	ranked := make([]WordCount, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		ranked[i] = heap.Pop(&h).(WordCount)
 	}
+	return ranked
+}
 
-	// Format as "word:count" strings
-	keywords := make([]string, limit)
-	for i := 0; i < limit; i++ {
-		keywords[i] = fmt.Sprintf("%s:%d", ss[i].Key, ss[i].Value)
+// minHeap keeps the lowest-ranked WordCount at the root.
+type minHeap []WordCount
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return ranksBefore(h[j], h[i]) }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(WordCount)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopKeywords returns the top N keywords from aggregated word counts as formatted strings.
+// Each string is formatted as "word:count" (e.g., "learning:1153"); equal counts sort
+// alphabetically. Filters out malformed tokens (unmatched delimiters, trailing special chars).
+func TopKeywords(wordCounts map[string]int, n int) []string {
+	if n <= 0 {
+		return []string{}
 	}
+	ranked := rank(wordCounts, n, isValidKeyword)
 
+	// Format as "word:count" strings
+	keywords := make([]string, len(ranked))
+	for i, wc := range ranked {
+		keywords[i] = fmt.Sprintf("%s:%d", wc.Word, wc.Count)
+	}
 	return keywords
 }
 
 // PrintTopKeywords prints the top N keywords in a numbered list format.
 // Filters out malformed tokens (unmatched delimiters, trailing special chars).
 func PrintTopKeywords(wordCounts map[string]int, n int) {
-	type kv struct {
-		Key   string
-		Value int
+	if n <= 0 {
+		return
 	}
-
-	var ss []kv
-	for k, v := range wordCounts {
-		if isValidKeyword(k) {
-			ss = append(ss, kv{k, v})
-		}
-	}
-
-	sort.Slice(ss, func(i, j int) bool {
-		return ss[i].Value > ss[j].Value
-	})
-
-	limit := n
-	if len(ss) < n {
-		limit = len(ss)
-	}
-	if limit < 0 {
-		limit = 0
-	}
-
-	for i := 0; i < limit; i++ {
-		fmt.Printf("%d. %s: %d\n", i+1, ss[i].Key, ss[i].Value)
+	for i, wc := range rank(wordCounts, n, isValidKeyword) {
+		fmt.Printf("%d. %s: %d\n", i+1, wc.Word, wc.Count)
 	}
 }
@@ -0,0 +1,49 @@
+package mapreduce
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestTopKeywordsOrderAndTies(t *testing.T) {
+	counts := map[string]int{"beta": 3, "alpha": 3, "gamma": 5, "delta": 1, "broken(": 9}
+	got := TopKeywords(counts, 3)
+	want := []string{"gamma:5", "alpha:3", "beta:3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("TopKeywords() = %v, want %v", got, want)
+	}
+}
+
+func TestRankHeapMatchesFullSort(t *testing.T) {
+	counts := syntheticCounts(5000)
+	full := Rank(counts, 0)
+	for _, n := range []int{1, 10, 100, 1249, 1250, 5000} {
+		got := Rank(counts, n)
+		if !slices.Equal(got, full[:n]) {
+			t.Errorf("Rank(n=%d) differs from the first %d of a full sort", n, n)
+		}
+	}
+}
+
+// syntheticCounts builds a Zipf-like vocabulary with many tied counts.
+func syntheticCounts(n int) map[string]int {
+	r := rand.New(rand.NewSource(1))
+	counts := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		counts[fmt.Sprintf("word%06d", i)] = 1 + r.Intn(1000)/(1+i/100)
+	}
+	return counts
+}
+
+func BenchmarkTopKeywords(b *testing.B) {
+	counts := syntheticCounts(200_000)
+	for _, n := range []int{25, 200_000} {
+		b.Run(fmt.Sprintf("top%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				TopKeywords(counts, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+// Package pagediff compares two parsed versions of the same page structurally.
+package pagediff
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// PageDiff describes how a page changed between a stored and a newer parse.
+type PageDiff struct {
+	URL          string `yaml:"url" json:"url"`
+	TitleChanged bool   `yaml:"title_changed" json:"title_changed"`
+	OldTitle     string `yaml:"old_title,omitempty" json:"old_title,omitempty"`
+	NewTitle     string `yaml:"new_title,omitempty" json:"new_title,omitempty"`
+
+	OldWordCount   int `yaml:"old_word_count" json:"old_word_count"`
+	NewWordCount   int `yaml:"new_word_count" json:"new_word_count"`
+	WordCountDelta int `yaml:"word_count_delta" json:"word_count_delta"`
+
+	SectionsAdded   []SectionRef    `yaml:"sections_added,omitempty" json:"sections_added,omitempty"`
+	SectionsRemoved []SectionRef    `yaml:"sections_removed,omitempty" json:"sections_removed,omitempty"`
+	SectionsChanged []SectionChange `yaml:"sections_changed,omitempty" json:"sections_changed,omitempty"`
+
+	CodeBlocksAdded   []CodeRef `yaml:"code_blocks_added,omitempty" json:"code_blocks_added,omitempty"`
+	CodeBlocksRemoved []CodeRef `yaml:"code_blocks_removed,omitempty" json:"code_blocks_removed,omitempty"`
+}
+
+// SectionRef identifies a section by its heading slug.
+type SectionRef struct {
+	Slug    string `yaml:"slug" json:"slug"`
+	Heading string `yaml:"heading" json:"heading"`
+	Level   int    `yaml:"level" json:"level"`
+}
+
+// SectionChange is a section present in both versions whose text differs.
+type SectionChange struct {
+	SectionRef `yaml:",inline"`
+	WordDelta  int `yaml:"word_delta" json:"word_delta"`
+}
+
+// CodeRef identifies a code block by content hash, with its first line for context.
+type CodeRef struct {
+	Hash      string `yaml:"hash" json:"hash"`
+	Language  string `yaml:"language,omitempty" json:"language,omitempty"`
+	FirstLine string `yaml:"first_line" json:"first_line"`
+}
+
+// HasChanges reports whether anything structural or textual differs.
+func (d *PageDiff) HasChanges() bool {
+	return d.TitleChanged || d.WordCountDelta != 0 ||
+		len(d.SectionsAdded) > 0 || len(d.SectionsRemoved) > 0 || len(d.SectionsChanged) > 0 ||
+		len(d.CodeBlocksAdded) > 0 || len(d.CodeBlocksRemoved) > 0
+}
+
+// Compare diffs old against new. Sections are matched by heading slug rather than
+// by position or block ID, so inserting a section doesn't mark every later one as changed.
+func Compare(old, new *models.Page) *PageDiff {
+	d := &PageDiff{
+		URL:          new.URL,
+		OldWordCount: old.Metadata.WordCount,
+		NewWordCount: new.Metadata.WordCount,
+	}
+	d.WordCountDelta = d.NewWordCount - d.OldWordCount
+
+	if strings.TrimSpace(old.Title) != strings.TrimSpace(new.Title) {
+		d.TitleChanged = true
+		d.OldTitle = old.Title
+		d.NewTitle = new.Title
+	}
+
+	oldSections, newSections := outline(old), outline(new)
+	oldBySlug := make(map[string]sectionText, len(oldSections))
+	for _, s := range oldSections {
+		oldBySlug[s.ref.Slug] = s
+	}
+	newSlugs := make(map[string]bool, len(newSections))
+	for _, s := range newSections {
+		newSlugs[s.ref.Slug] = true
+		prev, ok := oldBySlug[s.ref.Slug]
+		switch {
+		case !ok:
+			d.SectionsAdded = append(d.SectionsAdded, s.ref)
+		case prev.text != s.text:
+			d.SectionsChanged = append(d.SectionsChanged, SectionChange{
+				SectionRef: s.ref,
+				WordDelta:  len(strings.Fields(s.text)) - len(strings.Fields(prev.text)),
+			})
+		}
+	}
+	for _, s := range oldSections {
+		if !newSlugs[s.ref.Slug] {
+			d.SectionsRemoved = append(d.SectionsRemoved, s.ref)
+		}
+	}
+
+	oldCode, newCode := codeBlocks(old), codeBlocks(new)
+	for _, hash := range newCode.order {
+		if _, ok := oldCode.byHash[hash]; !ok {
+			d.CodeBlocksAdded = append(d.CodeBlocksAdded, newCode.byHash[hash])
+		}
+	}
+	for _, hash := range oldCode.order {
+		if _, ok := newCode.byHash[hash]; !ok {
+			d.CodeBlocksRemoved = append(d.CodeBlocksRemoved, oldCode.byHash[hash])
+		}
+	}
+
+	return d
+}
+
+// sectionText is a headed section and the text of its own blocks (children excluded).
+type sectionText struct {
+	ref  SectionRef
+	text string
+}
+
+// outline flattens a page into headed sections in document order. Full pages use
+// their section tree; flat pages are split at heading blocks. Text before the first
+// heading has no stable identity and only counts towards the word-count delta.
+func outline(page *models.Page) []sectionText {
+	var sections []sectionText
+	seen := make(map[string]int)
+	add := func(heading string, level int, text string) {
+		slug := models.HeadingSlug(heading)
+		if slug == "" {
+			return
+		}
+		// Repeated headings ("Example", "Returns") get positional suffixes
+		seen[slug]++
+		if n := seen[slug]; n > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		}
+		sections = append(sections, sectionText{
+			ref:  SectionRef{Slug: slug, Heading: heading, Level: level},
+			text: text,
+		})
+	}
+
+	var walk func([]models.Section)
+	walk = func(list []models.Section) {
+		for _, s := range list {
+			if s.Heading != nil {
+				add(s.Heading.Text, s.Level, blocksText(s.Blocks))
+			}
+			walk(s.Children)
+		}
+	}
+	walk(page.Content)
+
+	var heading string
+	var level int
+	var body []models.ContentBlock
+	for _, block := range page.FlatContent {
+		if l := models.HeadingLevel(block.Type); l > 0 {
+			if heading != "" {
+				add(heading, level, blocksText(body))
+			}
+			heading, level, body = block.Text, l, nil
+			continue
+		}
+		body = append(body, block)
+	}
+	if heading != "" {
+		add(heading, level, blocksText(body))
+	}
+
+	return sections
+}
+
+// codeSet indexes code blocks by content hash, keeping document order.
+type codeSet struct {
+	byHash map[string]CodeRef
+	order  []string
+}
+
+// codeBlocks collects a page's distinct code blocks.
+func codeBlocks(page *models.Page) codeSet {
+	set := codeSet{byHash: make(map[string]CodeRef)}
+	addBlocks := func(blocks []models.ContentBlock) {
+		for _, block := range blocks {
+			if block.Code == nil {
+				continue
+			}
+			sum := sha256.Sum256([]byte(strings.TrimSpace(block.Code.Content)))
+			hash := fmt.Sprintf("%x", sum[:6])
+			if _, dup := set.byHash[hash]; dup {
+				continue
+			}
+			firstLine, _, _ := strings.Cut(strings.TrimSpace(block.Code.Content), "\n")
+			set.byHash[hash] = CodeRef{Hash: hash, Language: block.Code.Language, FirstLine: firstLine}
+			set.order = append(set.order, hash)
+		}
+	}
+
+	addBlocks(page.FlatContent)
+	var walk func([]models.Section)
+	walk = func(list []models.Section) {
+		for _, s := range list {
+			addBlocks(s.Blocks)
+			walk(s.Children)
+		}
+	}
+	walk(page.Content)
+
+	return set
+}
+
+// blocksText joins block text with whitespace normalized, so reflowed HTML isn't a change.
+func blocksText(blocks []models.ContentBlock) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		text := block.Text
+		if block.Code != nil {
+			text = block.Code.Content
+		}
+		if text = strings.Join(strings.Fields(text), " "); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
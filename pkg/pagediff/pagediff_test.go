@@ -0,0 +1,72 @@
+package pagediff
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func flatPage(title string, blocks ...models.ContentBlock) *models.Page {
+	return &models.Page{URL: "https://example.com/docs", Title: title, FlatContent: blocks}
+}
+
+func TestCompare_MatchesSectionsBySlug(t *testing.T) {
+	old := flatPage("Docs",
+		models.ContentBlock{Type: "h2", Text: "Install"},
+		models.ContentBlock{Type: "p", Text: "go get example.com/widget"},
+		models.ContentBlock{Type: "h2", Text: "Usage"},
+		models.ContentBlock{Type: "p", Text: "Call New."},
+		models.ContentBlock{Type: "code", Code: &models.Code{Content: "widget.New()"}},
+	)
+	// A section inserted first must not mark the unchanged ones after it as changed
+	updated := flatPage("Docs",
+		models.ContentBlock{Type: "h2", Text: "Overview"},
+		models.ContentBlock{Type: "p", Text: "Widgets do one thing."},
+		models.ContentBlock{Type: "h2", Text: "Install"},
+		models.ContentBlock{Type: "p", Text: "go get example.com/widget"},
+		models.ContentBlock{Type: "h2", Text: "Usage"},
+		models.ContentBlock{Type: "p", Text: "Call New with options."},
+		models.ContentBlock{Type: "code", Code: &models.Code{Content: "widget.New(opts)"}},
+	)
+
+	d := Compare(old, updated)
+
+	if d.TitleChanged {
+		t.Errorf("TitleChanged = true, want false")
+	}
+	if len(d.SectionsAdded) != 1 || d.SectionsAdded[0].Slug != "overview" {
+		t.Errorf("SectionsAdded = %+v, want [overview]", d.SectionsAdded)
+	}
+	if len(d.SectionsRemoved) != 0 {
+		t.Errorf("SectionsRemoved = %+v, want none", d.SectionsRemoved)
+	}
+	if len(d.SectionsChanged) != 1 || d.SectionsChanged[0].Slug != "usage" {
+		t.Errorf("SectionsChanged = %+v, want [usage]", d.SectionsChanged)
+	}
+	if len(d.CodeBlocksAdded) != 1 || d.CodeBlocksAdded[0].FirstLine != "widget.New(opts)" {
+		t.Errorf("CodeBlocksAdded = %+v, want widget.New(opts)", d.CodeBlocksAdded)
+	}
+	if len(d.CodeBlocksRemoved) != 1 || d.CodeBlocksRemoved[0].FirstLine != "widget.New()" {
+		t.Errorf("CodeBlocksRemoved = %+v, want widget.New()", d.CodeBlocksRemoved)
+	}
+	if !d.HasChanges() {
+		t.Errorf("HasChanges() = false, want true")
+	}
+}
+
+func TestCompare_DuplicateHeadingsGetSuffixes(t *testing.T) {
+	page := flatPage("API",
+		models.ContentBlock{Type: "h3", Text: "Example"},
+		models.ContentBlock{Type: "p", Text: "first"},
+		models.ContentBlock{Type: "h3", Text: "Example"},
+		models.ContentBlock{Type: "p", Text: "second"},
+	)
+
+	sections := outline(page)
+	if len(sections) != 2 || sections[0].ref.Slug != "example" || sections[1].ref.Slug != "example-2" {
+		t.Fatalf("outline slugs = %+v, want example, example-2", sections)
+	}
+	if d := Compare(page, page); d.HasChanges() {
+		t.Errorf("Compare(page, page) reported changes: %+v", d)
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Elements each mode captures as content blocks unless ParseRequest.BlockTags narrows them.
+var (
+	fullBlockTags  = []string{"h1", "h2", "h3", "h4", "h5", "h6", "p", "li", "pre", "code", "table", "math"}
+	cheapBlockTags = []string{"h1", "h2", "h3", "p", "div", "pre", "blockquote"}
+)
+
+// SupportedBlockTags lists the elements --block-tags accepts. div is left out: cheap mode
+// only uses it for leaf divs, and as an explicit choice it would duplicate nested text.
+var SupportedBlockTags = []string{"h1", "h2", "h3", "h4", "h5", "h6", "p", "li", "pre", "code", "table", "blockquote", "math"}
+
+// ParseBlockTags parses a comma-separated tag list such as "h1,h2,p,code", lowercasing
+// and de-duplicating it. An empty list returns nil, meaning each mode's default set.
+func ParseBlockTags(list string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if !slices.Contains(SupportedBlockTags, tag) {
+			return nil, fmt.Errorf("unsupported block tag %q (supported: %s)", tag, strings.Join(SupportedBlockTags, ","))
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// blockSelector returns the goquery selector for a mode: its defaults, or the
+// requested tags when there are any.
+func blockSelector(defaults, requested []string) string {
+	if len(requested) > 0 {
+		return strings.Join(requested, ",")
+	}
+	return strings.Join(defaults, ",")
+}
+
+// selectsTag reports whether a selector built by blockSelector captures tag.
+func selectsTag(selector, tag string) bool {
+	return slices.Contains(strings.Split(selector, ","), tag)
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestParseBlockTags(t *testing.T) {
+	tags, err := ParseBlockTags(" H2, p ,code,,p")
+	if err != nil {
+		t.Fatalf("ParseBlockTags() error = %v", err)
+	}
+	if want := []string{"h2", "p", "code"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("ParseBlockTags() = %v, want %v", tags, want)
+	}
+
+	if tags, err := ParseBlockTags(""); err != nil || tags != nil {
+		t.Errorf("ParseBlockTags(\"\") = %v, %v; want nil, nil", tags, err)
+	}
+	if _, err := ParseBlockTags("p,span"); err == nil {
+		t.Error("ParseBlockTags(\"p,span\") error = nil, want unsupported tag")
+	}
+}
+
+const mixedPage = `<!DOCTYPE html>
+<html><head><title>Widget Guide</title></head>
+<body><article>
+<h1>Widget Guide</h1>
+<p>Widgets are small components. Every widget does one thing well, and a widget composes with other widgets.</p>
+<h2>Install</h2>
+<p>Install the widget toolkit with your package manager before building any widget.</p>
+<pre>go get example.com/widget</pre>
+<ul><li>Fast</li><li>Small</li></ul>
+</article></body></html>`
+
+func TestParse_BlockTagsRestrictCapture(t *testing.T) {
+	p := &Parser{}
+	for _, mode := range []models.ParseMode{models.ParseModeCheap, models.ParseModeFull} {
+		page, err := p.Parse(models.ParseRequest{
+			URL:       "https://example.com/guide",
+			HTML:      mixedPage,
+			Mode:      mode,
+			BlockTags: []string{"pre"},
+		})
+		if err != nil {
+			t.Fatalf("Parse(mode %d) error = %v", mode, err)
+		}
+
+		blocks := page.FlatContent
+		for _, section := range page.Content {
+			blocks = append(blocks, section.Blocks...)
+			if section.Heading != nil {
+				t.Errorf("mode %d: heading %q captured, want only pre blocks", mode, section.Heading.Text)
+			}
+		}
+		if len(blocks) != 1 {
+			t.Fatalf("mode %d: %d blocks, want only the pre block: %+v", mode, len(blocks), blocks)
+		}
+		if blocks[0].Type != "pre" && blocks[0].Type != "code" {
+			t.Errorf("mode %d: block type = %q, want pre or code", mode, blocks[0].Type)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// cleanSelector matches elements that never carry readable content.
+const cleanSelector = "script,style,noscript"
+
+// CleanHTML strips <script>, <style>, <noscript> elements and HTML comments, plus
+// the elements matching any of extraSelectors (e.g. a site's cookie banner).
+// Readability usually drops the built-in ones itself, but inline style blocks
+// occasionally survive and leak CSS into extracted text.
+func CleanHTML(rawHTML string, extraSelectors ...string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML for cleaning: %w", err)
+	}
+
+	removeNonContent(doc.Selection, extraSelectors)
+	for _, n := range doc.Nodes {
+		removeComments(n)
+	}
+
+	cleaned, err := doc.Html()
+	if err != nil {
+		return "", fmt.Errorf("failed to render cleaned HTML: %w", err)
+	}
+	return cleaned, nil
+}
+
+// removeNonContent removes the elements beneath sel that match cleanSelector or one
+// of extraSelectors. Invalid selectors match nothing.
+func removeNonContent(sel *goquery.Selection, extraSelectors []string) {
+	sel.Find(cleanSelector).Remove()
+	for _, selector := range extraSelectors {
+		sel.Find(selector).Remove()
+	}
+}
+
+// removeComments deletes comment nodes beneath n.
+func removeComments(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode {
+			n.RemoveChild(child)
+		} else {
+			removeComments(child)
+		}
+		child = next
+	}
+}
@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// leakyPage mimics a page whose article body carries an inline style block,
+// which readability has been seen to pass through into extracted text.
+const leakyPage = `<!DOCTYPE html>
+<html>
+<head><title>Leaky page</title><script>var tracking = true;</script></head>
+<body>
+<article>
+  <h1>Understanding Widgets</h1>
+  <style>.widget { color: red; margin: 0 auto; }</style>
+  <!-- build: 2024-01-01 -->
+  <p>Widgets are small components that do one thing well.</p>
+  <noscript>Please enable JavaScript</noscript>
+  <script>document.write("ad");</script>
+  <p>They compose into larger systems.</p>
+</article>
+</body>
+</html>`
+
+func TestCleanHTML_StripsLeakyBlocks(t *testing.T) {
+	cleaned, err := CleanHTML(leakyPage)
+	if err != nil {
+		t.Fatalf("CleanHTML() error = %v", err)
+	}
+
+	leaks := []string{
+		".widget { color: red",
+		"var tracking",
+		"document.write",
+		"Please enable JavaScript",
+		"build: 2024-01-01",
+		"<style",
+		"<script",
+		"<noscript",
+		"<!--",
+	}
+	for _, leak := range leaks {
+		if strings.Contains(cleaned, leak) {
+			t.Errorf("cleaned HTML still contains %q", leak)
+		}
+	}
+
+	keep := []string{
+		"Understanding Widgets",
+		"Widgets are small components that do one thing well.",
+		"They compose into larger systems.",
+		"<title>Leaky page</title>",
+	}
+	for _, want := range keep {
+		if !strings.Contains(cleaned, want) {
+			t.Errorf("cleaned HTML lost content %q", want)
+		}
+	}
+}
+
+func TestCleanHTML_NoOpOnCleanPage(t *testing.T) {
+	input := `<html><head></head><body><p>Plain paragraph.</p></body></html>`
+
+	cleaned, err := CleanHTML(input)
+	if err != nil {
+		t.Fatalf("CleanHTML() error = %v", err)
+	}
+	if cleaned != input {
+		t.Errorf("CleanHTML() = %q, want %q", cleaned, input)
+	}
+}
+
+func TestCleanHTML_ExtraSelectors(t *testing.T) {
+	input := `<html><head></head><body><div class="cookie-banner">We use cookies.</div>` +
+		`<p>Plain paragraph.</p><aside id="share">Share this</aside><script>x()</script></body></html>`
+
+	cleaned, err := CleanHTML(input, ".cookie-banner", "aside#share", "[invalid")
+	if err != nil {
+		t.Fatalf("CleanHTML() error = %v", err)
+	}
+	want := `<html><head></head><body><p>Plain paragraph.</p></body></html>`
+	if cleaned != want {
+		t.Errorf("CleanHTML() = %q, want %q", cleaned, want)
+	}
+}
+
+func TestParse_CleanHTMLKeepsLeaksOutOfBlocks(t *testing.T) {
+	leaks := []string{".widget", "color: red", "var tracking", "document.write", "Please enable JavaScript", "build: 2024-01-01"}
+
+	// Readability's content and a --content-selector match are both built from the cleaned HTML
+	requests := map[string]models.ParseRequest{
+		"cheap":           {Mode: models.ParseModeCheap},
+		"full":            {Mode: models.ParseModeFull},
+		"full, selector":  {Mode: models.ParseModeFull, ContentSelector: "article"},
+		"cheap, selector": {Mode: models.ParseModeCheap, ContentSelector: "article"},
+	}
+	for name, req := range requests {
+		t.Run(name, func(t *testing.T) {
+			req.URL, req.HTML, req.CleanHTML = "https://example.com/widgets", leakyPage, true
+			p := &Parser{}
+			page, err := p.Parse(req)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			text := pageText(page)
+			for _, leak := range leaks {
+				if strings.Contains(text, leak) {
+					t.Errorf("parsed blocks contain %q:\n%s", leak, text)
+				}
+			}
+			if !strings.Contains(text, "Widgets are small components") || !strings.Contains(text, "They compose into larger systems.") {
+				t.Errorf("parsed blocks lost the article text:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestParse_CleanSelectors(t *testing.T) {
+	html := `<html><head><title>Widgets</title></head><body><article>
+<h1>Understanding Widgets</h1>
+<div class="newsletter">Subscribe to our newsletter for weekly widget news and offers.</div>
+<p>Widgets are small components that do one thing well.</p>
+<p>They compose into larger systems.</p>
+</article></body></html>`
+
+	for _, req := range []models.ParseRequest{
+		{Mode: models.ParseModeFull, CleanHTML: true},
+		{Mode: models.ParseModeFull, ContentSelector: "article"},
+	} {
+		req.URL, req.HTML, req.CleanSelectors = "https://example.com/widgets", html, []string{".newsletter"}
+		page, err := (&Parser{}).Parse(req)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		text := pageText(page)
+		if strings.Contains(text, "Subscribe") {
+			t.Errorf("Parse(selector %q) kept the cleaned element:\n%s", req.ContentSelector, text)
+		}
+		if !strings.Contains(text, "Widgets are small components") {
+			t.Errorf("Parse(selector %q) lost the article text:\n%s", req.ContentSelector, text)
+		}
+	}
+}
+
+// pageText joins the text of every block of page, headings included, one per line.
+func pageText(page *models.Page) string {
+	var lines []string
+	var walk func(sections []models.Section)
+	walk = func(sections []models.Section) {
+		for _, s := range sections {
+			if s.Heading != nil {
+				lines = append(lines, s.Heading.Text)
+			}
+			for _, b := range s.Blocks {
+				lines = append(lines, b.Text)
+			}
+			walk(s.Children)
+		}
+	}
+	walk(page.Content)
+	for _, b := range page.FlatContent {
+		lines = append(lines, b.Text)
+	}
+	return strings.Join(lines, "\n")
+}
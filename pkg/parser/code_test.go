@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// fencedPage renders code fences the way GitHub and common static site generators do,
+// between paragraphs long enough that readability keeps them.
+const fencedPage = `<!DOCTYPE html>
+<html><head><title>Fences</title></head>
+<body><article>
+<h2>Install</h2>
+<p>Run the installer for your platform, then check the version it prints. The widget
+toolkit ships as a single module, so one command fetches it along with everything it needs.</p>
+<div class="highlight highlight-source-shell"><pre>go install example.com/widget@latest</pre></div>
+<h2>Usage</h2>
+<p>Create a widget with its defaults and run it. Options can be passed to New, but the
+defaults suit most programs, and a widget started this way stops when the process exits.</p>
+<pre><code class="language-go">widget.New().Run()</code></pre>
+<p>Scripts can drive the same widget through the Python bindings, which wrap the Go
+package and accept the same options, so examples translate between the two directly.</p>
+<pre class="chroma"><code data-lang="Python">print("widget")</code></pre>
+<p>Call <code>Run</code> once per process.</p>
+</article></body></html>`
+
+func TestParseFull_PreCodeIsOneBlock(t *testing.T) {
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/fences", HTML: fencedPage, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var codes []*models.Code
+	for _, section := range page.Content {
+		for _, block := range section.Blocks {
+			if block.Code != nil {
+				codes = append(codes, block.Code)
+			}
+		}
+	}
+
+	// Three fenced blocks plus the inline <code>Run</code>, which has no <pre>
+	want := []models.Code{
+		{Language: "shell", Content: "go install example.com/widget@latest"},
+		{Language: "go", Content: "widget.New().Run()"},
+		{Language: "python", Content: `print("widget")`},
+		{Content: "Run"},
+	}
+	if len(codes) != len(want) {
+		t.Fatalf("got %d code blocks, want %d: %+v", len(codes), len(want), codes)
+	}
+	for i, code := range codes {
+		if *code != want[i] {
+			t.Errorf("code block %d = %+v, want %+v", i, *code, want[i])
+		}
+	}
+	if page.Metadata.CodeBlockCount != len(want) {
+		t.Errorf("CodeBlockCount = %d, want %d", page.Metadata.CodeBlockCount, len(want))
+	}
+}
+
+func TestParse_CodeOnlyBlockTagsKeepFencedCode(t *testing.T) {
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{
+		URL:       "https://example.com/fences",
+		HTML:      fencedPage,
+		Mode:      models.ParseModeFull,
+		BlockTags: []string{"code"},
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	count := 0
+	for _, section := range page.Content {
+		count += len(section.Blocks)
+	}
+	// Without pre in the selector, each <code> is the block: two fences and the inline one
+	if count != 3 {
+		t.Errorf("got %d blocks, want 3", count)
+	}
+}
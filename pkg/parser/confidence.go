@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfidenceConfig returns the built-in block confidence weights.
+func DefaultConfidenceConfig() models.ConfidenceConfig {
+	return models.ConfidenceConfig{
+		Base:       0.4,
+		Structured: 0.95, // structured content is usually high-signal
+		Density: []models.DensityBand{
+			{MinWords: 120, Bonus: 0.4},
+			{MinWords: 40, Bonus: 0.25},
+			{MinWords: 15, Bonus: 0.1},
+		},
+		LinkPenalty: 0.05,
+	}
+}
+
+// LoadConfidenceConfig reads confidence weights from path, on top of the defaults.
+func LoadConfidenceConfig(path string) (*models.ConfidenceConfig, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read confidence config: %w", err)
+	}
+
+	config := DefaultConfidenceConfig()
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse confidence config %s: %w", path, err)
+	}
+
+	switch {
+	case config.Base < 0 || config.Base > 1:
+		return nil, fmt.Errorf("confidence config: base must be between 0 and 1")
+	case config.Structured < 0 || config.Structured > 1:
+		return nil, fmt.Errorf("confidence config: structured must be between 0 and 1")
+	case config.LinkPenalty < 0:
+		return nil, fmt.Errorf("confidence config: link_penalty must be >= 0")
+	}
+	for i, band := range config.Density {
+		if band.MinWords < 0 {
+			return nil, fmt.Errorf("confidence config: density band %d: min_words must be >= 0", i+1)
+		}
+	}
+
+	// Highest threshold first, so scoring can take the first band a block exceeds
+	slices.SortFunc(config.Density, func(a, b models.DensityBand) int { return cmp.Compare(b.MinWords, a.MinWords) })
+	return &config, nil
+}
+
+// confidence returns the weights for block scoring: p.Confidence, or the defaults.
+func (p *Parser) confidence() models.ConfidenceConfig {
+	if p.Confidence != nil {
+		return *p.Confidence
+	}
+	return DefaultConfidenceConfig()
+}
+
+// computeConfidence scores a block from its word count and links. Density bands must be
+// ordered by MinWords descending, as DefaultConfidenceConfig and LoadConfidenceConfig return them.
+func computeConfidence(config models.ConfidenceConfig, text string, links int, blockType string) float64 {
+	if blockType == "code" || blockType == "table" {
+		return config.Structured
+	}
+
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0.0
+	}
+
+	score := config.Base
+
+	// Text density
+	for _, band := range config.Density {
+		if words > band.MinWords {
+			score += band.Bonus
+			break
+		}
+	}
+
+	// Link penalty
+	score -= float64(links) * config.LinkPenalty
+
+	// Clamp
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
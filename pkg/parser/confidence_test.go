@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func words(n int) string {
+	return strings.TrimSpace(strings.Repeat("word ", n))
+}
+
+// TestComputeConfidence_Defaults pins the built-in scoring that --filter conf:>=X and the
+// confidence distribution stats depend on.
+func TestComputeConfidence_Defaults(t *testing.T) {
+	defaults := DefaultConfidenceConfig()
+	tests := []struct {
+		name      string
+		text      string
+		links     int
+		blockType string
+		want      float64
+	}{
+		{"empty", "", 0, "p", 0},
+		{"short", words(10), 0, "p", 0.4},
+		{"at first band edge", words(15), 0, "p", 0.4},
+		{"over 15 words", words(16), 0, "p", 0.5},
+		{"over 40 words", words(41), 0, "p", 0.65},
+		{"over 120 words", words(121), 0, "li", 0.8},
+		{"link penalty", words(41), 3, "p", 0.5},
+		{"clamped at zero", words(5), 20, "p", 0},
+		{"code", "", 0, "code", 0.95},
+		{"table", "", 0, "table", 0.95},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeConfidence(defaults, tt.text, tt.links, tt.blockType)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("computeConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfidenceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "confidence.yaml")
+	config := `base: 0.5
+link_penalty: 0
+density:
+  - {min_words: 10, bonus: 0.1}
+  - {min_words: 50, bonus: 0.3}
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadConfidenceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfidenceConfig() error = %v", err)
+	}
+	if got.Structured != DefaultConfidenceConfig().Structured {
+		t.Errorf("Structured = %v, want the default kept", got.Structured)
+	}
+	if got.Density[0].MinWords != 50 {
+		t.Errorf("Density = %+v, want sorted by min_words descending", got.Density)
+	}
+
+	// 60 words take the 50-word band only; links cost nothing
+	if score := computeConfidence(*got, words(60), 4, "p"); math.Abs(score-0.8) > 1e-9 {
+		t.Errorf("computeConfidence() = %v, want 0.8", score)
+	}
+
+	if err := os.WriteFile(path, []byte("base: 1.5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfidenceConfig(path); err == nil {
+		t.Error("LoadConfidenceConfig(base: 1.5) error = nil, want out of range")
+	}
+}
+
+func TestParser_UsesConfidenceConfig(t *testing.T) {
+	html := `<html><head><title>t</title></head><body><article><p>` + words(20) + `</p></article></body></html>`
+	p := &Parser{Confidence: &models.ConfidenceConfig{Base: 0.9}}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/", HTML: html, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(page.Content) == 0 || len(page.Content[0].Blocks) == 0 {
+		t.Fatalf("no blocks parsed: %+v", page.Content)
+	}
+	if got := page.Content[0].Blocks[0].Confidence; got != 0.9 {
+		t.Errorf("block confidence = %v, want 0.9 from the parser's config", got)
+	}
+}
@@ -0,0 +1,285 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Content date kinds
+const (
+	ContentDatePublished = "published"
+	ContentDateUpdated   = "updated"
+)
+
+// contentDate is the page's best publication or last-updated date.
+type contentDate struct {
+	Date   string // ISO-8601: YYYY-MM-DD, or RFC 3339 in UTC when the source gives a time
+	Kind   string // ContentDatePublished or ContentDateUpdated
+	Source string // "json-ld" | "meta" | "time" | "text" | "readability"
+}
+
+// Meta tags (by name, property or itemprop, lowercased) that carry a content date.
+var (
+	updatedMetaTags = map[string]bool{
+		"article:modified_time": true,
+		"og:updated_time":       true,
+		"datemodified":          true,
+		"last-modified":         true,
+		"dcterms.modified":      true,
+		"dc.date.modified":      true,
+	}
+	publishedMetaTags = map[string]bool{
+		"article:published_time": true,
+		"og:published_time":      true,
+		"datepublished":          true,
+		"date":                   true,
+		"pubdate":                true,
+		"publish-date":           true,
+		"publish_date":           true,
+		"dc.date":                true,
+		"dc.date.issued":         true,
+		"dcterms.created":        true,
+		"dcterms.date":           true,
+		"dcterms.issued":         true,
+		"parsely-pub-date":       true,
+		"sailthru.date":          true,
+	}
+)
+
+// visibleDatePattern matches bylines like "Last updated: March 3, 2024" or
+// "Published on 2024-03-03" in the page text.
+var visibleDatePattern = regexp.MustCompile(`(?i)\b(last\s+updated|last\s+modified|updated|modified|published|posted)(?:\s+on)?\s*:?\s+` +
+	`(\d{4}-\d{2}-\d{2}|\d{4}/\d{2}/\d{2}` +
+	`|(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+\d{1,2},?\s+\d{4}` +
+	`|\d{1,2}\s+(?:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?,?\s+\d{4})`)
+
+// visibleTextLimit bounds how much body text is scanned for a visible date.
+const visibleTextLimit = 100_000
+
+// Layouts tried in order; the first group carries a time of day.
+var (
+	dateTimeLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z0700",
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		time.RFC1123,
+		time.RFC1123Z,
+	}
+	dateLayouts = []string{
+		"2006-01-02",
+		"2006/01/02",
+		"January 2 2006",
+		"Jan 2 2006",
+		"2 January 2006",
+		"2 Jan 2006",
+	}
+)
+
+// detectContentDate combines the dates a page declares about itself into one. Sources
+// are tried from most to least structured: JSON-LD dateModified/datePublished, meta
+// tags, <time datetime> elements, then "Updated ..."/"Published ..." text in the body;
+// readabilityDate (go-readability's published time) is the last resort. Within the
+// first source that has any date, the updated date wins unless it precedes the
+// published one. It returns nil when the page carries no usable date.
+func detectContentDate(doc *goquery.Document, readabilityDate string) *contentDate {
+	if doc != nil {
+		sources := []struct {
+			name    string
+			collect func(*goquery.Document, *dateCandidates)
+		}{
+			{"json-ld", collectJSONLDDates},
+			{"meta", collectMetaDates},
+			{"time", collectTimeDates},
+			{"text", collectVisibleDates},
+		}
+		for _, source := range sources {
+			var found dateCandidates
+			source.collect(doc, &found)
+			if date := found.best(source.name); date != nil {
+				return date
+			}
+		}
+	}
+
+	if t, hasClock, ok := parseContentDate(readabilityDate); ok {
+		return &contentDate{Date: formatContentDate(t, hasClock), Kind: ContentDatePublished, Source: "readability"}
+	}
+	return nil
+}
+
+// dateCandidates holds the latest updated and published dates one source declares.
+type dateCandidates struct {
+	updated, published           time.Time
+	updatedClock, publishedClock bool
+}
+
+// add records raw as a date of kind, keeping the latest of each kind.
+func (c *dateCandidates) add(kind, raw string) {
+	t, hasClock, ok := parseContentDate(raw)
+	if !ok {
+		return
+	}
+	switch kind {
+	case ContentDateUpdated:
+		if t.After(c.updated) {
+			c.updated, c.updatedClock = t, hasClock
+		}
+	case ContentDatePublished:
+		if t.After(c.published) {
+			c.published, c.publishedClock = t, hasClock
+		}
+	}
+}
+
+func (c *dateCandidates) best(source string) *contentDate {
+	switch {
+	case !c.updated.IsZero() && !c.updated.Before(c.published):
+		return &contentDate{Date: formatContentDate(c.updated, c.updatedClock), Kind: ContentDateUpdated, Source: source}
+	case !c.published.IsZero():
+		return &contentDate{Date: formatContentDate(c.published, c.publishedClock), Kind: ContentDatePublished, Source: source}
+	}
+	return nil
+}
+
+// collectJSONLDDates reads dateModified and datePublished from every JSON-LD object,
+// including those nested in @graph arrays.
+func collectJSONLDDates(doc *goquery.Document, found *dateCandidates) {
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, value := range v {
+				if s, ok := value.(string); ok {
+					switch key {
+					case "dateModified":
+						found.add(ContentDateUpdated, s)
+					case "datePublished":
+						found.add(ContentDatePublished, s)
+					}
+					continue
+				}
+				walk(value)
+			}
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err == nil {
+			walk(data)
+		}
+	})
+}
+
+func collectMetaDates(doc *goquery.Document, found *dateCandidates) {
+	doc.Find("meta[content]").Each(func(_ int, s *goquery.Selection) {
+		content, _ := s.Attr("content")
+		for _, attr := range []string{"property", "name", "itemprop", "http-equiv"} {
+			key := strings.ToLower(strings.TrimSpace(s.AttrOr(attr, "")))
+			switch {
+			case updatedMetaTags[key]:
+				found.add(ContentDateUpdated, content)
+			case publishedMetaTags[key]:
+				found.add(ContentDatePublished, content)
+			}
+		}
+	})
+}
+
+// collectTimeDates reads <time datetime> elements. One marked as the modified date
+// (itemprop, or an "updated"/"modified" class) is an updated date; the first other is
+// taken as published, since later ones are usually comments or related posts.
+func collectTimeDates(doc *goquery.Document, found *dateCandidates) {
+	sawPublished := false
+	doc.Find("time[datetime]").Each(func(_ int, s *goquery.Selection) {
+		datetime, _ := s.Attr("datetime")
+		marker := strings.ToLower(s.AttrOr("itemprop", "") + " " + s.AttrOr("class", ""))
+		switch {
+		case strings.Contains(marker, "modified") || strings.Contains(marker, "updated"):
+			found.add(ContentDateUpdated, datetime)
+		case !sawPublished:
+			sawPublished = true
+			found.add(ContentDatePublished, datetime)
+		}
+	})
+}
+
+// collectVisibleDates scans the body text for the first "Updated ..." and
+// "Published ..." dates.
+func collectVisibleDates(doc *goquery.Document, found *dateCandidates) {
+	text := strings.Join(strings.Fields(doc.Find("body").Text()), " ")
+	if len(text) > visibleTextLimit {
+		text = text[:visibleTextLimit]
+	}
+	var sawUpdated, sawPublished bool
+	for _, m := range visibleDatePattern.FindAllStringSubmatch(text, -1) {
+		label := strings.ToLower(m[1])
+		if strings.Contains(label, "updated") || strings.Contains(label, "modified") {
+			if !sawUpdated {
+				sawUpdated = true
+				found.add(ContentDateUpdated, m[2])
+			}
+		} else if !sawPublished {
+			sawPublished = true
+			found.add(ContentDatePublished, m[2])
+		}
+	}
+}
+
+// parseContentDate parses the date formats pages use, reporting whether the value
+// carried a time of day. Dates before 1990 or more than a day in the future are
+// rejected as placeholders or event dates.
+func parseContentDate(raw string) (time.Time, bool, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false, false
+	}
+
+	t, hasClock, ok := time.Time{}, false, false
+	for _, layout := range dateTimeLayouts {
+		if parsed, err := time.Parse(layout, raw); err == nil {
+			t, hasClock, ok = parsed, true, true
+			break
+		}
+	}
+	if !ok {
+		// "Mar. 3, 2024" and "Sept 3 2024" reduce to the layouts above
+		text := strings.NewReplacer(",", " ", ".", " ").Replace(raw)
+		text = strings.Join(strings.Fields(text), " ")
+		if fields := strings.Fields(text); len(fields) == 3 {
+			for i, f := range fields {
+				if strings.EqualFold(f, "sept") {
+					fields[i] = "Sep"
+				}
+			}
+			text = strings.Join(fields, " ")
+		}
+		for _, layout := range dateLayouts {
+			if parsed, err := time.Parse(layout, text); err == nil {
+				t, ok = parsed, true
+				break
+			}
+		}
+	}
+	if !ok || t.Year() < 1990 || t.After(time.Now().Add(24*time.Hour)) {
+		return time.Time{}, false, false
+	}
+	return t, hasClock, true
+}
+
+func formatContentDate(t time.Time, hasClock bool) string {
+	if hasClock {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDetectContentDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		html        string
+		readability string
+		want        *contentDate
+	}{
+		{
+			name: "json-ld modified beats published and meta",
+			html: `<head><script type="application/ld+json">{"@context":"https://schema.org","@type":"Article",
+				"datePublished":"2023-01-10T08:00:00+02:00","dateModified":"2024-05-02T09:30:00Z"}</script>
+				<meta property="article:published_time" content="2022-01-01"></head><body></body>`,
+			want: &contentDate{Date: "2024-05-02T09:30:00Z", Kind: ContentDateUpdated, Source: "json-ld"},
+		},
+		{
+			name: "json-ld graph",
+			html: `<head><script type="application/ld+json">{"@graph":[{"@type":"WebSite"},
+				{"@type":"BlogPosting","datePublished":"2021-07-04"}]}</script></head><body></body>`,
+			want: &contentDate{Date: "2021-07-04", Kind: ContentDatePublished, Source: "json-ld"},
+		},
+		{
+			name: "updated before published is ignored",
+			html: `<head><meta property="article:published_time" content="2024-03-01">
+				<meta property="article:modified_time" content="2020-01-01"></head><body></body>`,
+			want: &contentDate{Date: "2024-03-01", Kind: ContentDatePublished, Source: "meta"},
+		},
+		{
+			name: "meta name is case-insensitive",
+			html: `<head><meta name="DC.Date" content="2019-11-20"></head><body></body>`,
+			want: &contentDate{Date: "2019-11-20", Kind: ContentDatePublished, Source: "meta"},
+		},
+		{
+			name: "time element marked updated",
+			html: `<body><time datetime="2022-02-02">Feb 2</time>
+				<time class="updated" datetime="2023-06-15T12:00:00-04:00">Jun 15</time></body>`,
+			want: &contentDate{Date: "2023-06-15T16:00:00Z", Kind: ContentDateUpdated, Source: "time"},
+		},
+		{
+			name: "visible updated text",
+			html: `<body><p>Posted on Jan. 5, 2020</p><p>Last updated: Sept 9, 2024</p></body>`,
+			want: &contentDate{Date: "2024-09-09", Kind: ContentDateUpdated, Source: "text"},
+		},
+		{
+			name: "visible day-first date",
+			html: `<body><p>Published 3 March 2021 by the team</p></body>`,
+			want: &contentDate{Date: "2021-03-03", Kind: ContentDatePublished, Source: "text"},
+		},
+		{
+			name:        "readability fallback",
+			html:        `<body><p>No dates here.</p></body>`,
+			readability: "2018-08-08",
+			want:        &contentDate{Date: "2018-08-08", Kind: ContentDatePublished, Source: "readability"},
+		},
+		{
+			name: "placeholder and future dates rejected",
+			html: `<head><meta name="date" content="1970-01-01"><meta name="pubdate" content="2999-01-01"></head>
+				<body><p>Updated recently</p></body>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("NewDocumentFromReader() error = %v", err)
+			}
+			got := detectContentDate(doc, tt.readability)
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("detectContentDate() = %+v, want nil", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("detectContentDate() = %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
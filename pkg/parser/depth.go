@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// limitSectionDepth flattens sections nested deeper than maxDepth (top level = 1) into
+// their ancestor at maxDepth: each removed section's heading becomes an ordinary block
+// there, followed by its blocks, in document order. maxDepth <= 0 leaves the tree as is.
+func limitSectionDepth(sections []models.Section, maxDepth int) []models.Section {
+	if maxDepth <= 0 {
+		return sections
+	}
+	for i := range sections {
+		if maxDepth == 1 {
+			sections[i].Blocks = absorbChildren(sections[i])
+			sections[i].Children = nil
+			continue
+		}
+		sections[i].Children = limitSectionDepth(sections[i].Children, maxDepth-1)
+	}
+	return sections
+}
+
+// absorbChildren returns a section's blocks merged with every descendant's heading and
+// blocks, in document order (Position) since a section's own blocks may follow its children.
+func absorbChildren(section models.Section) []models.ContentBlock {
+	blocks := append([]models.ContentBlock{}, section.Blocks...)
+	var walk func(children []models.Section)
+	walk = func(children []models.Section) {
+		for _, child := range children {
+			if child.Heading != nil {
+				blocks = append(blocks, *child.Heading)
+			}
+			blocks = append(blocks, child.Blocks...)
+			walk(child.Children)
+		}
+	}
+	walk(section.Children)
+
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].Position < blocks[j].Position
+	})
+	return blocks
+}
+
+func countSections(sections []models.Section) int {
+	count := len(sections)
+	for _, s := range sections {
+		count += countSections(s.Children)
+	}
+	return count
+}
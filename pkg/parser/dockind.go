@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Document kinds recorded in PageMetadata.DocumentKind.
+const (
+	DocumentKindHTML     = "html"
+	DocumentKindXHTML    = "xhtml"
+	DocumentKindFragment = "fragment"
+)
+
+// Only the start of a document is sniffed; the markers below all belong near the top
+const documentSniffLen = 4096
+
+var (
+	xmlDeclaration = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+	xhtmlMarker    = regexp.MustCompile(`(?i)<!doctype\s+html\s+public\s+"-//w3c//dtd\s+xhtml|<html[^>]*\sxmlns\s*=\s*["']http://www\.w3\.org/1999/xhtml`)
+	documentMarker = regexp.MustCompile(`(?i)<(!doctype\s+html|html|head|body)[\s>/]`)
+	selfClosingTag = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9:-]*)((?:\s[^<>]*?)?)\s*/>`)
+)
+
+// voidElements never have content, so their self-closing form means the same in HTML.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// sniffDocument tells a full HTML document from XHTML and from a bare fragment, such
+// as an API-rendered partial, by looking for a doctype or html/head/body element.
+func sniffDocument(rawHTML string) string {
+	head := strings.TrimPrefix(rawHTML, "\uFEFF")
+	head = head[:min(len(head), documentSniffLen)]
+	switch {
+	case xhtmlMarker.MatchString(head):
+		return DocumentKindXHTML
+	case documentMarker.MatchString(head):
+		return DocumentKindHTML
+	}
+	return DocumentKindFragment
+}
+
+// prepareDocument returns rawHTML in a shape readability and goquery handle well, with
+// its sniffed kind. Fragments are wrapped in a minimal document. XHTML loses its XML
+// declaration, and self-closed non-void elements are closed explicitly: an HTML parser
+// ignores the slash, so <script src="a.js"/> or <div/> would swallow the rest of the page.
+func prepareDocument(rawHTML string) (string, string) {
+	if strings.TrimSpace(rawHTML) == "" {
+		return rawHTML, ""
+	}
+	kind := sniffDocument(rawHTML)
+	switch kind {
+	case DocumentKindFragment:
+		return "<!DOCTYPE html><html><head></head><body>" + rawHTML + "</body></html>", kind
+	case DocumentKindXHTML:
+		rawHTML = xmlDeclaration.ReplaceAllString(strings.TrimPrefix(rawHTML, "\uFEFF"), "")
+		rawHTML = selfClosingTag.ReplaceAllStringFunc(rawHTML, func(tag string) string {
+			m := selfClosingTag.FindStringSubmatch(tag)
+			if voidElements[strings.ToLower(m[1])] {
+				return tag
+			}
+			return "<" + m[1] + m[2] + "></" + m[1] + ">"
+		})
+	}
+	return rawHTML, kind
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+const xhtmlPage = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Widget specs</title><script src="app.js"/></head>
+<body><div class="spacer"/><h1>Widget specs</h1>
+<p>Every widget is machined from a single block of aluminium.<br/>Tolerances stay under a tenth of a millimetre.</p>
+</body></html>`
+
+func TestSniffDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"div-only fragment", `<div class="card"><p>Hello</p></div>`, DocumentKindFragment},
+		{"text fragment", `Just some text`, DocumentKindFragment},
+		{"html5 document", `<!DOCTYPE html><html><body><p>Hi</p></body></html>`, DocumentKindHTML},
+		{"body without doctype", "\n<body><p>Hi</p></body>", DocumentKindHTML},
+		{"xhtml doctype", xhtmlPage, DocumentKindXHTML},
+		{"xhtml namespace only", `<html xmlns="http://www.w3.org/1999/xhtml"><body/></html>`, DocumentKindXHTML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sniffDocument(tt.html); got != tt.want {
+				t.Errorf("sniffDocument() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareDocument_XHTML(t *testing.T) {
+	got, kind := prepareDocument(xhtmlPage)
+	if kind != DocumentKindXHTML {
+		t.Fatalf("kind = %q, want %q", kind, DocumentKindXHTML)
+	}
+	for _, want := range []string{`<script src="app.js"></script>`, `<div class="spacer"></div>`, `<br/>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prepared XHTML missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<?xml") {
+		t.Error("prepared XHTML still has its XML declaration")
+	}
+}
+
+func TestParse_DocumentKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		wantKind string
+		wantText string
+	}{
+		{
+			name:     "div-only fragment",
+			html:     `<div><h1>Widget specs</h1><p>Every widget is machined from a single block of aluminium.</p></div>`,
+			wantKind: DocumentKindFragment,
+			wantText: "machined from a single block",
+		},
+		{
+			name:     "xhtml with self-closed script",
+			html:     xhtmlPage,
+			wantKind: DocumentKindXHTML,
+			wantText: "machined from a single block",
+		},
+	}
+
+	p := &Parser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := p.Parse(models.ParseRequest{URL: "https://widgets.example.com/specs", HTML: tt.html, Mode: models.ParseModeFull})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if page.Metadata.DocumentKind != tt.wantKind {
+				t.Errorf("DocumentKind = %q, want %q", page.Metadata.DocumentKind, tt.wantKind)
+			}
+			var text []string
+			for _, section := range page.Content {
+				for _, block := range section.Blocks {
+					text = append(text, block.Text)
+				}
+			}
+			if !strings.Contains(strings.Join(text, " "), tt.wantText) {
+				t.Errorf("blocks %q do not contain %q", text, tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+)
+
+// Content sources recorded in PageMetadata.ContentSource.
+const (
+	ContentSourceReadability  = "readability"
+	ContentSourceBodyFallback = "body_fallback"
+	ContentSourceSelector     = "selector" // ParseRequest.ContentSelector matched
+)
+
+// bodyContent returns the page's <body> HTML and text with non-content elements
+// and extraSelectors removed, for pages where readability extracts little or nothing (SPAs, unusual
+// markup). ok is false when the body has no text either.
+func bodyContent(rawHTML string, extraSelectors []string) (content, text string, ok bool) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return "", "", false
+	}
+
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		return "", "", false
+	}
+	removeNonContent(body, extraSelectors)
+
+	text = strings.TrimSpace(body.Text())
+	if text == "" {
+		return "", "", false
+	}
+
+	content, err = body.Html()
+	if err != nil {
+		return "", "", false
+	}
+	return content, text, true
+}
+
+// ValidateContentSelector reports whether selector is a CSS selector --content-selector
+// can use.
+func ValidateContentSelector(selector string) error {
+	if _, err := cascadia.ParseGroup(selector); err != nil {
+		return fmt.Errorf("invalid content selector %q: %w", selector, err)
+	}
+	return nil
+}
+
+// selectorContent returns the HTML and text of the first element matching selector,
+// with non-content elements and extraSelectors removed, for sites where readability picks the wrong
+// region. ok is false when nothing matches or the match has no text.
+func selectorContent(rawHTML, selector string, extraSelectors []string) (content, text string, ok bool) {
+	if ValidateContentSelector(selector) != nil {
+		return "", "", false
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rawHTML))
+	if err != nil {
+		return "", "", false
+	}
+
+	root := doc.Find(selector).First()
+	if root.Length() == 0 {
+		return "", "", false
+	}
+	removeNonContent(root, extraSelectors)
+
+	text = strings.TrimSpace(root.Text())
+	if text == "" {
+		return "", "", false
+	}
+
+	content, err = goquery.OuterHtml(root)
+	if err != nil {
+		return "", "", false
+	}
+	return content, text, true
+}
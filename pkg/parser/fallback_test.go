@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// Readability favours the long sidebar over the short release notes it sits beside
+const selectorPage = `<html><head><title>Release 4.2</title></head><body>
+<div class="sidebar">
+<p>Our community forum is the best place to ask questions, share plugins and meet other users from around the world, every day of the week.</p>
+<p>Subscribe to the newsletter for monthly tips, upcoming events, conference talks and interviews with the people who build the project.</p>
+<p>Sponsors keep the project independent. Read about our sponsorship tiers, the benefits for companies and how the money is spent each year.</p>
+</div>
+<article class="main"><h1>Release 4.2</h1><p>Adds streaming uploads.</p><script>track()</script></article>
+</body></html>`
+
+// Readability drops the <aside>, leaving too little text, so the body is used instead
+const asidePage = `<html><head><title>Widgets</title></head><body><div>
+<p>Widgets, at a glance.</p>
+<aside>
+<p>Streaming uploads resume after a dropped connection without starting over from the first byte.</p>
+<p>Dashboards refresh every few seconds and keep the filters you picked between visits.</p>
+<p>Exports write CSV or JSON and include every column shown in the table at the time.</p>
+<p>Teams share widgets with read-only links that expire after thirty days by default.</p>
+</aside>
+<script>boot()</script>
+</div></body></html>`
+
+func TestParse_BodyFallback(t *testing.T) {
+	p := &Parser{}
+	req := models.ParseRequest{URL: "https://example.com/widgets", HTML: asidePage, Mode: models.ParseModeCheap, MinContentLength: 250}
+
+	page, err := p.Parse(req)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.ContentSource != ContentSourceBodyFallback || page.Metadata.ExtractionQuality != "degraded" {
+		t.Errorf("content source %q, quality %q; want %q, degraded", page.Metadata.ContentSource, page.Metadata.ExtractionQuality, ContentSourceBodyFallback)
+	}
+	text := page.ToPlainText()
+	if !strings.Contains(text, "Streaming uploads resume") || !strings.Contains(text, "Widgets, at a glance.") {
+		t.Errorf("text = %q, want the whole body", text)
+	}
+	if strings.Contains(text, "boot()") {
+		t.Errorf("text = %q, want the script dropped", text)
+	}
+
+	// With the fallback off, readability's short content is kept
+	req.MinContentLength = 0
+	if page, err = p.Parse(req); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.ContentSource != ContentSourceReadability || strings.Contains(page.ToPlainText(), "Streaming uploads") {
+		t.Errorf("without the fallback: content source %q, text %q; want readability's content", page.Metadata.ContentSource, page.ToPlainText())
+	}
+
+	// Minimal mode parses no content, so it never falls back
+	req.MinContentLength, req.Mode = 250, models.ParseModeMinimal
+	if page, err = p.Parse(req); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.ContentSource != "" {
+		t.Errorf("minimal mode content source = %q, want none", page.Metadata.ContentSource)
+	}
+}
+
+func TestBodyContent_EmptyBody(t *testing.T) {
+	if _, _, ok := bodyContent(`<html><body><script>boot()</script></body></html>`, nil); ok {
+		t.Error("bodyContent() of a body with only a script = ok, want no fallback")
+	}
+}
+
+func TestParse_ContentSelector(t *testing.T) {
+	p := &Parser{}
+	req := models.ParseRequest{URL: "https://example.com/releases/4.2", HTML: selectorPage, Mode: models.ParseModeFull, ContentSelector: "article.main"}
+
+	page, err := p.Parse(req)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.ContentSource != ContentSourceSelector {
+		t.Errorf("ContentSource = %q, want %q", page.Metadata.ContentSource, ContentSourceSelector)
+	}
+	text := page.ToPlainText()
+	if !strings.Contains(text, "Adds streaming uploads.") {
+		t.Errorf("text = %q, want the release notes", text)
+	}
+	if strings.Contains(text, "newsletter") || strings.Contains(text, "track()") {
+		t.Errorf("text = %q, want neither the sidebar nor the script", text)
+	}
+
+	// No match: readability's content is kept
+	req.ContentSelector = "main#content"
+	page, err = p.Parse(req)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.ContentSource != ContentSourceReadability {
+		t.Errorf("ContentSource without a match = %q, want %q", page.Metadata.ContentSource, ContentSourceReadability)
+	}
+}
+
+func TestValidateContentSelector(t *testing.T) {
+	for _, selector := range []string{"article.main", "#content > div", "main, article"} {
+		if err := ValidateContentSelector(selector); err != nil {
+			t.Errorf("ValidateContentSelector(%q) error = %v", selector, err)
+		}
+	}
+	if err := ValidateContentSelector("article[["); err == nil {
+		t.Error("ValidateContentSelector(\"article[[\") succeeded, want an error")
+	}
+}
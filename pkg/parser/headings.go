@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/atom"
+)
+
+// restoreDemotedHeadings undoes readability turning every <h1> into an <h2>: for each
+// of the source page's <h1> headings that content no longer has as an <h1>, the first
+// <h2> in content with the same text becomes an <h1> again, so the outline keeps the
+// page's own heading levels. content is returned as is when nothing needs restoring.
+func restoreDemotedHeadings(content, sourceHTML string) string {
+	if !strings.Contains(strings.ToLower(sourceHTML), "<h1") {
+		return content
+	}
+	source, err := goquery.NewDocumentFromReader(strings.NewReader(sourceHTML))
+	if err != nil {
+		return content
+	}
+	demoted := make(map[string]bool)
+	source.Find("h1").Each(func(_ int, s *goquery.Selection) {
+		demoted[headingKey(s)] = true
+	})
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return content
+	}
+	// An <h1> readability kept was not demoted
+	doc.Find("h1").Each(func(_ int, s *goquery.Selection) {
+		delete(demoted, headingKey(s))
+	})
+	restored := false
+	doc.Find("h2").Each(func(_ int, s *goquery.Selection) {
+		text := headingKey(s)
+		if !demoted[text] {
+			return
+		}
+		delete(demoted, text)
+		node := s.Get(0)
+		node.Data, node.DataAtom = "h1", atom.H1
+		restored = true
+	})
+	if !restored {
+		return content
+	}
+
+	restoredContent, err := doc.Find("body").Html()
+	if err != nil {
+		return content
+	}
+	return restoredContent
+}
+
+// headingKey is a heading's text with whitespace collapsed, for matching it across documents.
+func headingKey(s *goquery.Selection) string {
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
@@ -0,0 +1,42 @@
+package parser
+
+import "testing"
+
+func TestRestoreDemotedHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		source  string
+		want    string
+	}{
+		{
+			name:    "demoted h1 restored",
+			content: `<div><h2>Guide</h2><p>Intro.</p><h2>Setup</h2></div>`,
+			source:  `<body><h1>Guide</h1><p>Intro.</p><h2>Setup</h2></body>`,
+			want:    `<div><h1>Guide</h1><p>Intro.</p><h2>Setup</h2></div>`,
+		},
+		{
+			name:    "only the first matching h2",
+			content: `<div><h2>Overview</h2><p>A.</p><h2>Overview</h2></div>`,
+			source:  `<body><h1>Overview</h1><p>A.</p><h2>Overview</h2></body>`,
+			want:    `<div><h1>Overview</h1><p>A.</p><h2>Overview</h2></div>`,
+		},
+		{
+			name:    "h1 kept by readability",
+			content: `<div><h1>Guide</h1><h2>Guide</h2></div>`,
+			source:  `<body><h1>Guide</h1><h2>Guide</h2></body>`,
+			want:    `<div><h1>Guide</h1><h2>Guide</h2></div>`,
+		},
+		{
+			name:    "no h1 in the source",
+			content: `<div><h2>Guide</h2></div>`,
+			source:  `<body><h2>Guide</h2></body>`,
+			want:    `<div><h2>Guide</h2></div>`,
+		},
+	}
+	for _, tt := range tests {
+		if got := restoreDemotedHeadings(tt.content, tt.source); got != tt.want {
+			t.Errorf("%s: restoreDemotedHeadings() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestExtractImages(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<article>
+<img src="/img/hero.png" alt=" The hero " width="800px" height="600">
+<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" alt="inline">
+<img src="https://tracker.example.net/p.gif" width="1" height="1">
+<img src="spacer.gif" height="2">
+<img data-src="lazy/chart.webp" alt="Chart">
+<img src="" data-src="DATA:image/png;base64,AAAA">
+<img src="https://cdn.example.org/photo.jpg" width="auto">
+<img src="../img/hero.png" alt="Duplicate">
+<img>
+</article>`))
+	if err != nil {
+		t.Fatalf("NewDocumentFromReader() error = %v", err)
+	}
+	base, _ := url.Parse("https://example.com/blog/post")
+
+	want := []models.Image{
+		{Src: "https://example.com/img/hero.png", Alt: "The hero", Width: 800, Height: 600},
+		{Src: "https://example.com/blog/lazy/chart.webp", Alt: "Chart"},
+		{Src: "https://cdn.example.org/photo.jpg"},
+	}
+	if got := extractImages(doc, base); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractImages() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDimension(t *testing.T) {
+	tests := map[string]int{
+		"300":    300,
+		" 300px": 300,
+		"2":      2,
+		"":       0,
+		"50%":    0,
+		"auto":   0,
+		"-5":     0,
+	}
+	for value, want := range tests {
+		if got := parseDimension(value); got != want {
+			t.Errorf("parseDimension(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// Math notations recorded in models.Math.Notation.
+const (
+	MathNotationMathML = "mathml"
+	MathNotationTeX    = "tex"
+)
+
+// texMath matches TeX between display delimiters \[...\] and $$...$$, then inline
+// \(...\) and $...$. A $...$ pair follows Pandoc's rule so prices stay text: no space
+// inside either dollar, and no digit right after the closing one.
+var texMath = regexp.MustCompile(`(?s)\\\[(.+?)\\\]|\$\$(.+?)\$\$|\\\((.+?)\\\)|\$([^\s$](?:[^$\n]*[^\s$])?)\$(?:[^0-9]|$)`)
+
+// mathMLBlock returns a <math> element as MathML, display when it is set apart as a block.
+func mathMLBlock(s *goquery.Selection) *models.Math {
+	markup, err := goquery.OuterHtml(s)
+	if err != nil || strings.TrimSpace(s.Text()) == "" {
+		return nil
+	}
+	display := strings.EqualFold(s.AttrOr("display", ""), "block") || strings.EqualFold(s.AttrOr("mode", ""), "display")
+	return &models.Math{Notation: MathNotationMathML, Display: display, Expression: markup}
+}
+
+// texExpressions finds TeX formulas in a text block, in order of appearance.
+func texExpressions(text string) []models.Math {
+	if !strings.ContainsAny(text, `$\`) {
+		return nil
+	}
+	var found []models.Math
+	for _, m := range texMath.FindAllStringSubmatch(text, -1) {
+		for group, display := range []bool{true, true, false, false} {
+			if expr := strings.TrimSpace(m[group+1]); expr != "" {
+				found = append(found, models.Math{Notation: MathNotationTeX, Display: display, Expression: expr})
+				break
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestTexExpressions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []models.Math
+	}{
+		{
+			name: "inline dollars",
+			text: "The energy $E = mc^2$ is conserved.",
+			want: []models.Math{{Notation: MathNotationTeX, Expression: "E = mc^2"}},
+		},
+		{
+			name: "display dollars and brackets",
+			text: `Sum: $$\sum_{i=1}^n i$$ and \[ \int_0^1 x\,dx \]`,
+			want: []models.Math{
+				{Notation: MathNotationTeX, Display: true, Expression: `\sum_{i=1}^n i`},
+				{Notation: MathNotationTeX, Display: true, Expression: `\int_0^1 x\,dx`},
+			},
+		},
+		{
+			name: "inline parentheses",
+			text: `where \(\alpha > 0\) holds`,
+			want: []models.Math{{Notation: MathNotationTeX, Expression: `\alpha > 0`}},
+		},
+		{
+			name: "prices are not math",
+			text: "Plans cost $5 and $10 per month, or $ 20 yearly.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := texExpressions(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("texExpressions() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("texExpressions()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+const mathPage = `<html><head><title>On Widgets</title></head><body><article>
+<h1>On Widgets</h1>
+<p>The widget energy $E = mc^2$ grows with mass, as shown below.</p>
+<math display="block"><mi>x</mi><mo>=</mo><mn>2</mn></math>
+<p>Inline MathML such as <math><msup><mi>y</mi><mn>2</mn></msup></math> also appears.</p>
+<p>\[ \int_0^1 f(x)\,dx \]</p>
+</article></body></html>`
+
+func TestParse_MathBlocks(t *testing.T) {
+	page, err := (&Parser{}).Parse(models.ParseRequest{URL: "https://arxiv.org/abs/2101.00001", HTML: mathPage, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var math []models.Math
+	var collect func([]models.Section)
+	collect = func(sections []models.Section) {
+		for _, s := range sections {
+			for _, b := range s.Blocks {
+				if b.Type == "math" && b.Math != nil {
+					math = append(math, *b.Math)
+				}
+			}
+			collect(s.Children)
+		}
+	}
+	collect(page.Content)
+
+	if len(math) != 4 {
+		t.Fatalf("math blocks = %+v, want 4", math)
+	}
+	wants := []struct {
+		notation string
+		display  bool
+		contains string
+	}{
+		{MathNotationTeX, false, "E = mc^2"},
+		{MathNotationMathML, true, "<mi>x</mi>"},
+		{MathNotationMathML, false, "<msup>"},
+		{MathNotationTeX, true, `\int_0^1 f(x)\,dx`},
+	}
+	for i, want := range wants {
+		got := math[i]
+		if got.Notation != want.notation || got.Display != want.display || !strings.Contains(got.Expression, want.contains) {
+			t.Errorf("math[%d] = %+v, want %s display=%v containing %q", i, got, want.notation, want.display, want.contains)
+		}
+	}
+	if !page.Metadata.HasLaTeX {
+		t.Error("HasLaTeX = false, want true for TeX formulas")
+	}
+}
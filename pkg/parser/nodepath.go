@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// nodePath locates s in the readability content as the element names below <body>
+// with their 1-based position among same-named siblings, e.g. "div[1]/p[3]". It is
+// a path into the readability output, not the original page, so it survives markup
+// changes readability strips away.
+func nodePath(s *goquery.Selection) string {
+	if s.Length() == 0 {
+		return ""
+	}
+	var steps []string
+	for n := s.Get(0); n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if n.Data == "body" || n.Data == "html" {
+			break
+		}
+		index := 1
+		for sib := n.PrevSibling; sib != nil; sib = sib.PrevSibling {
+			if sib.Type == html.ElementNode && sib.Data == n.Data {
+				index++
+			}
+		}
+		steps = append(steps, fmt.Sprintf("%s[%d]", n.Data, index))
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return strings.Join(steps, "/")
+}
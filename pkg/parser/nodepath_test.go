@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestParse_BlockPositionsAndPaths(t *testing.T) {
+	html := `<html><body><article>
+<h1>Guide</h1>
+<p>First paragraph of the guide.</p>
+<div><p>Nested paragraph inside a div.</p></div>
+<p>Second top-level paragraph.</p>
+<pre><code>go build ./...</code></pre>
+</article></body></html>`
+
+	modes := map[string]models.ParseMode{"full": models.ParseModeFull, "cheap": models.ParseModeCheap}
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			p := &Parser{}
+			page, err := p.Parse(models.ParseRequest{URL: "https://example.com/guide", HTML: html, Mode: mode})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			var blocks []models.ContentBlock
+			var walk func(s models.Section)
+			walk = func(s models.Section) {
+				if s.Heading != nil {
+					blocks = append(blocks, *s.Heading)
+				}
+				blocks = append(blocks, s.Blocks...)
+				for _, child := range s.Children {
+					walk(child)
+				}
+			}
+			for _, s := range page.Content {
+				walk(s)
+			}
+			blocks = append(blocks, page.FlatContent...)
+
+			paths := make(map[string]string)
+			for i, b := range blocks {
+				if b.Position != i+1 {
+					t.Errorf("block %d (%q) Position = %d, want %d", i, b.Text, b.Position, i+1)
+				}
+				paths[b.Text] = b.Path
+			}
+			if paths["Second top-level paragraph."] == "" || paths["Nested paragraph inside a div."] == "" {
+				t.Fatalf("paths = %v, want one for every paragraph", paths)
+			}
+
+			// Paths point into readability's output, not the original page (readability
+			// unwraps a div around a lone paragraph, for one): each resolves there to its
+			// block's element
+			article, _, err := readArticle(models.ParseRequest{URL: "https://example.com/guide", HTML: html}, mode, nil)
+			if err != nil {
+				t.Fatalf("readArticle() error = %v", err)
+			}
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resolved := 0
+			for _, b := range blocks {
+				if b.Type != "p" {
+					continue
+				}
+				resolved++
+				if got := strings.TrimSpace(resolvePath(doc, b.Path).Text()); got != b.Text {
+					t.Errorf("path %q resolves to %q, want %q", b.Path, got, b.Text)
+				}
+			}
+			if resolved != 3 {
+				t.Errorf("checked %d paragraph paths, want 3", resolved)
+			}
+		})
+	}
+}
+
+// resolvePath follows a nodePath from <body>, returning an empty selection when a step
+// does not exist.
+func resolvePath(doc *goquery.Document, path string) *goquery.Selection {
+	s := doc.Find("body")
+	for _, step := range strings.Split(path, "/") {
+		name, index, ok := strings.Cut(strings.TrimSuffix(step, "]"), "[")
+		n, err := strconv.Atoi(index)
+		if !ok || err != nil {
+			return &goquery.Selection{}
+		}
+		s = s.ChildrenFiltered(name).Eq(n - 1)
+	}
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -12,7 +13,10 @@ import (
 	"github.com/go-shiori/go-readability"
 )
 
-type Parser struct{}
+type Parser struct {
+	// Block confidence weights for full-mode parsing (nil = DefaultConfidenceConfig)
+	Confidence *models.ConfidenceConfig
+}
 
 func (p *Parser) Parse(req models.ParseRequest) (*models.Page, error) {
 	mode := models.ResolveParseMode(req)
@@ -22,27 +26,38 @@ func (p *Parser) Parse(req models.ParseRequest) (*models.Page, error) {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	// Extract meta keywords from HTML early (fast operation)
-	metaKeywords := extractMetaKeywords(req.HTML)
+	// Wrap bare fragments and tidy XHTML before goquery and readability see them
+	var documentKind string
+	req.HTML, documentKind = prepareDocument(req.HTML)
+
+	// Extract head metadata from the raw HTML early (fast operation)
+	var metaKeywords []string
+	var canonicalURL string
+	var social *models.SocialMetadata
+	headDoc, docErr := goquery.NewDocumentFromReader(strings.NewReader(req.HTML))
+	if docErr == nil {
+		metaKeywords = extractMetaKeywords(headDoc)
+		canonicalURL = extractCanonicalURL(headDoc, parsedURL)
+		social = extractSocialMetadata(headDoc, parsedURL)
+	}
 
-	readParser := readability.NewParser()
-	article, err := readParser.Parse(strings.NewReader(req.HTML), parsedURL)
+	article, contentSource, err := readArticle(req, mode, parsedURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML with readability: %w", err)
+		return nil, err
 	}
 
 	var page *models.Page
 
 	switch mode {
 	case models.ParseModeMinimal:
-		page, err = p.parseMinimal(req.URL, article, parsedURL)
+		page, err = p.parseMinimal(req.URL, article, parsedURL, req.Trust)
 		if err != nil {
 			return nil, err
 		}
 		// No auto-escalation for minimal mode - user must explicitly use --features
 
 	case models.ParseModeCheap:
-		page, err = p.parseCheap(req.URL, article, parsedURL)
+		page, err = p.parseCheap(req.URL, article, parsedURL, req.Trust, blockSelector(cheapBlockTags, req.BlockTags), req.KeepLineBreaks)
 		if err != nil {
 			return nil, err
 		}
@@ -51,7 +66,7 @@ func (p *Parser) Parse(req models.ParseRequest) (*models.Page, error) {
 
 		// 🔑 escalation logic lives HERE
 		if page.Metadata.ExtractionQuality == "low" {
-			page, err = p.parseFull(req.URL, article, parsedURL)
+			page, err = p.parseFull(req.URL, article, parsedURL, req.Trust, blockSelector(fullBlockTags, req.BlockTags), req.KeepLineBreaks)
 			if err != nil {
 				return nil, err
 			}
@@ -60,7 +75,7 @@ func (p *Parser) Parse(req models.ParseRequest) (*models.Page, error) {
 		}
 
 	case models.ParseModeFull:
-		page, err = p.parseFull(req.URL, article, parsedURL)
+		page, err = p.parseFull(req.URL, article, parsedURL, req.Trust, blockSelector(fullBlockTags, req.BlockTags), req.KeepLineBreaks)
 		if err != nil {
 			return nil, err
 		}
@@ -68,15 +83,110 @@ func (p *Parser) Parse(req models.ParseRequest) (*models.Page, error) {
 		page.ComputeMetadata()
 	}
 
+	if req.MaxSectionDepth > 0 && len(page.Content) > 0 {
+		page.Content = limitSectionDepth(page.Content, req.MaxSectionDepth)
+		page.Metadata.SectionCount = countSections(page.Content)
+	}
+	if req.SectionLanguages {
+		page.DetectSectionLanguages()
+	}
+
 	// Populate meta keywords (extracted from HTML)
 	if len(metaKeywords) > 0 {
 		page.Metadata.MetaKeywords = metaKeywords
 	}
+	page.Metadata.CanonicalURL = canonicalURL
+	page.Metadata.DocumentKind = documentKind
+	applySocialFallbacks(page, social)
+	page.Title, page.Metadata.TitleSource = chooseTitle(article.Title, headDoc, social, page.Metadata.SiteName)
+	page.Metadata.Paywalled = detectPaywall(headDoc, article.TextContent)
+	page.Metadata.Soft404 = detectSoft404(page.Title, article.TextContent)
+	page.Metadata.Robots = extractRobotsDirectives(headDoc, req.RobotsTags)
+	if date := detectContentDate(headDoc, page.Metadata.PublishedTime); date != nil {
+		page.Metadata.ContentDate, page.Metadata.ContentDateKind, page.Metadata.ContentDateSource = date.Date, date.Kind, date.Source
+	}
+
+	if mode != models.ParseModeMinimal || contentSource == ContentSourceSelector {
+		page.Metadata.ContentSource = contentSource
+		if contentSource == ContentSourceBodyFallback {
+			page.Metadata.ExtractionQuality = "degraded"
+		}
+	}
 
 	return page, nil
 }
 
-func (p *Parser) parseMinimal(rawURL string, article readability.Article, _ *url.URL) (*models.Page, error) {
+// readArticle runs readability over the request's HTML, cleaning its input and output
+// with CleanHTML and falling back to the whole body when it finds too little text.
+// When ContentSelector matches, the matched element replaces readability's content.
+func readArticle(req models.ParseRequest, mode models.ParseMode, parsedURL *url.URL) (readability.Article, string, error) {
+	rawHTML := req.HTML
+	if req.CleanHTML {
+		if cleaned, cleanErr := CleanHTML(rawHTML, req.CleanSelectors...); cleanErr == nil {
+			rawHTML = cleaned
+		}
+	}
+
+	var selected, selectedText string
+	var matched bool
+	if req.ContentSelector != "" {
+		selected, selectedText, matched = selectorContent(rawHTML, req.ContentSelector, req.CleanSelectors)
+	}
+
+	readParser := readability.NewParser()
+	readParser.KeepClasses = true // Highlighter classes name the language of code blocks
+	article, err := readParser.Parse(strings.NewReader(rawHTML), parsedURL)
+	if err != nil && !matched {
+		return readability.Article{}, "", fmt.Errorf("failed to parse HTML with readability: %w", err)
+	}
+	if matched {
+		// Readability still supplies the title, byline and excerpt when it can
+		if err != nil {
+			article = readability.Article{}
+		}
+		article.Content = selected
+		article.TextContent = selectedText
+		article.Length = len(selectedText)
+		return article, ContentSourceSelector, nil
+	}
+
+	article.Content = restoreDemotedHeadings(article.Content, rawHTML)
+
+	// Readability can still pass inline style blocks through; clean its output too
+	if req.CleanHTML {
+		if cleaned, cleanErr := CleanHTML(article.Content, req.CleanSelectors...); cleanErr == nil {
+			article.Content = cleaned
+		}
+	}
+
+	// Readability sometimes gives up on valid pages; fall back to the whole body
+	contentSource := ContentSourceReadability
+	if mode != models.ParseModeMinimal && req.MinContentLength > 0 &&
+		len(strings.TrimSpace(article.TextContent)) < req.MinContentLength {
+		if content, text, ok := bodyContent(rawHTML, req.CleanSelectors); ok && len(text) > len(strings.TrimSpace(article.TextContent)) {
+			article.Content = content
+			article.TextContent = text
+			contentSource = ContentSourceBodyFallback
+		}
+	}
+	return article, contentSource, nil
+}
+
+// ExplainContentType re-runs content-type detection on a page the way Parse does and
+// returns the signals behind the classification.
+func ExplainContentType(req models.ParseRequest) (detector.ContentTypeExplanation, error) {
+	parsedURL, err := url.Parse(req.URL)
+	if err != nil {
+		return detector.ContentTypeExplanation{}, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	article, _, err := readArticle(req, models.ResolveParseMode(req), parsedURL)
+	if err != nil {
+		return detector.ContentTypeExplanation{}, err
+	}
+	return detector.ExplainContentType(req.URL, article.Title, article.Content), nil
+}
+
+func (p *Parser) parseMinimal(rawURL string, article readability.Article, _ *url.URL, trust *models.TrustConfig) (*models.Page, error) {
 	// Minimal mode: ONLY extract metadata from go-readability, no content parsing
 	page := &models.Page{
 		URL:   rawURL,
@@ -88,7 +198,7 @@ func (p *Parser) parseMinimal(rawURL string, article readability.Article, _ *url
 	page.Metadata.ExtractionQuality = "minimal" // New quality level
 
 	// Enrich with free metadata (readability + smart detection)
-	enrichMetadata(page, article, rawURL)
+	enrichMetadata(page, article, rawURL, trust)
 
 	// Don't compute full metadata - we have no content blocks
 	// Just mark as computed so downstream doesn't try
@@ -101,6 +211,9 @@ func (p *Parser) parseFull(
 	rawURL string,
 	article readability.Article,
 	parsedURL *url.URL,
+	trust *models.TrustConfig,
+	selector string,
+	keepLineBreaks bool,
 ) (*models.Page, error) {
 
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content))
@@ -108,29 +221,67 @@ func (p *Parser) parseFull(
 		return nil, fmt.Errorf("failed to parse HTML document: %w", err)
 	}
 
+	weights := p.confidence()
+
 	var (
 		rootSections   []models.Section
-		sectionStack   []*models.Section
+		sectionStack   []sectionFrame
 		sectionCounter int
 		blockCounter   int
 	)
 
-	currentSection := func() *models.Section {
+	// leaveClosedSections pops sections whose <section>/<article> element ended before s,
+	// so text after a nested section returns to the section that encloses it.
+	leaveClosedSections := func(s *goquery.Selection) {
+		for len(sectionStack) > 0 {
+			scope := sectionStack[len(sectionStack)-1].scope
+			if scope == nil || scope.Contains(s.Get(0)) {
+				return
+			}
+			sectionStack = sectionStack[:len(sectionStack)-1]
+		}
+	}
+
+	currentSection := func(s *goquery.Selection) *models.Section {
+		leaveClosedSections(s)
 		if len(sectionStack) == 0 {
 			sectionCounter++
-			s := models.Section{
+			untitled := models.Section{
 				ID:    fmt.Sprintf("section-%d", sectionCounter),
 				Level: 0,
 			}
-			rootSections = append(rootSections, s)
-			sectionStack = append(sectionStack, &rootSections[len(rootSections)-1])
+			rootSections = append(rootSections, untitled)
+			sectionStack = append(sectionStack, sectionFrame{section: &rootSections[len(rootSections)-1]})
 		}
-		return sectionStack[len(sectionStack)-1]
+		return sectionStack[len(sectionStack)-1].section
 	}
 
-	doc.Find("h1,h2,h3,h4,h5,h6,p,li,pre,code,table").Each(func(_ int, s *goquery.Selection) {
+	foundTeX := false
+	preCaptured := selectsTag(selector, "pre")
+	doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
 		tag := goquery.NodeName(s)
-		text := normalizeText(s.Text())
+		if preCaptured && inPre(s) {
+			return // Part of the enclosing <pre> block
+		}
+
+		// MATHML (kept as markup; the enclosing paragraph keeps its text rendering)
+		if tag == "math" {
+			if math := mathMLBlock(s); math != nil {
+				blockCounter++
+				section := currentSection(s)
+				section.Blocks = append(section.Blocks, models.ContentBlock{
+					ID:         fmt.Sprintf("block-%d", blockCounter),
+					Position:   blockCounter,
+					Path:       nodePath(s),
+					Type:       "math",
+					Math:       math,
+					Confidence: weights.Structured,
+				})
+			}
+			return
+		}
+
+		text := blockText(s, keepLineBreaks)
 		if text == "" && tag != "table" {
 			return
 		}
@@ -145,6 +296,8 @@ func (p *Parser) parseFull(
 
 			headingBlock := models.ContentBlock{
 				ID:         fmt.Sprintf("block-%d", blockCounter),
+				Position:   blockCounter,
+				Path:       nodePath(s),
 				Type:       tag,
 				Text:       text,
 				Links:      links,
@@ -157,30 +310,42 @@ func (p *Parser) parseFull(
 				Heading: &headingBlock,
 			}
 
-			for len(sectionStack) > 0 && sectionStack[len(sectionStack)-1].Level >= level {
+			// Close sections at the same or a deeper level, untitled preamble sections
+			// (level 0), and sections whose element ended before this heading
+			leaveClosedSections(s)
+			for len(sectionStack) > 0 {
+				top := sectionStack[len(sectionStack)-1].section
+				if top.Level < level && top.Heading != nil {
+					break
+				}
 				sectionStack = sectionStack[:len(sectionStack)-1]
 			}
 
+			frame := sectionFrame{scope: sectionScope(s)}
 			if len(sectionStack) == 0 {
 				rootSections = append(rootSections, newSection)
-				sectionStack = append(sectionStack, &rootSections[len(rootSections)-1])
+				frame.section = &rootSections[len(rootSections)-1]
 			} else {
-				parent := sectionStack[len(sectionStack)-1]
+				parent := sectionStack[len(sectionStack)-1].section
 				parent.Children = append(parent.Children, newSection)
-				sectionStack = append(sectionStack, &parent.Children[len(parent.Children)-1])
+				frame.section = &parent.Children[len(parent.Children)-1]
 			}
+			sectionStack = append(sectionStack, frame)
 			return
 		}
 
 		// TABLES
 		if tag == "table" {
 			blockCounter++
-			currentSection().Blocks = append(currentSection().Blocks, models.ContentBlock{
+			section := currentSection(s)
+			section.Blocks = append(section.Blocks, models.ContentBlock{
 				ID:         fmt.Sprintf("block-%d", blockCounter),
+				Position:   blockCounter,
+				Path:       nodePath(s),
 				Type:       "table",
 				Table:      extractTable(s),
 				Links:      links,
-				Confidence: 0.95,
+				Confidence: weights.Structured,
 			})
 			return
 		}
@@ -192,25 +357,45 @@ func (p *Parser) parseFull(
 				return // Skip empty/line-number-only blocks
 			}
 			blockCounter++
-			currentSection().Blocks = append(currentSection().Blocks, models.ContentBlock{
+			section := currentSection(s)
+			section.Blocks = append(section.Blocks, models.ContentBlock{
 				ID:         fmt.Sprintf("block-%d", blockCounter),
+				Position:   blockCounter,
+				Path:       nodePath(s),
 				Type:       "code",
-				Code:       &models.Code{Content: codeContent},
+				Code:       &models.Code{Language: codeLanguage(s), Content: codeContent},
 				Links:      links,
-				Confidence: 0.95,
+				Confidence: weights.Structured,
 			})
 			return
 		}
 
 		// TEXT
 		blockCounter++
-		currentSection().Blocks = append(currentSection().Blocks, models.ContentBlock{
+		section := currentSection(s)
+		section.Blocks = append(section.Blocks, models.ContentBlock{
 			ID:         fmt.Sprintf("block-%d", blockCounter),
+			Position:   blockCounter,
+			Path:       nodePath(s),
 			Type:       tag,
 			Text:       text,
 			Links:      links,
-			Confidence: computeConfidence(text, len(links), tag),
+			Confidence: computeConfidence(weights, text, len(links), tag),
 		})
+
+		// TeX formulas in the text follow it as math blocks
+		for _, math := range texExpressions(text) {
+			foundTeX = true
+			blockCounter++
+			section.Blocks = append(section.Blocks, models.ContentBlock{
+				ID:         fmt.Sprintf("block-%d", blockCounter),
+				Position:   blockCounter,
+				Path:       nodePath(s),
+				Type:       "math",
+				Math:       &math,
+				Confidence: weights.Structured,
+			})
+		}
 	})
 
 	page := &models.Page{
@@ -219,16 +404,37 @@ func (p *Parser) parseFull(
 		Content: rootSections,
 	}
 
+	page.Images = extractImages(doc, parsedURL)
+	page.Metadata.ImageCount = len(page.Images)
+
 	page.Metadata.ExtractionMode = "full"
 	page.Metadata.ExtractionQuality = "ok"
 
 	// Enrich metadata from article and detector
-	enrichMetadata(page, article, rawURL)
+	enrichMetadata(page, article, rawURL, trust)
+	page.Metadata.HasLaTeX = page.Metadata.HasLaTeX || foundTeX
 
 	return page, nil
 }
 
-func (p *Parser) parseCheap(rawURL string, article readability.Article, parsedURL *url.URL) (*models.Page, error) {
+// sectionFrame is an open section while building the tree. scope is the <section> or
+// <article> element its heading sits in, if any; the section closes when that element does.
+type sectionFrame struct {
+	section *models.Section
+	scope   *goquery.Selection
+}
+
+// sectionScope returns the nearest <section> or <article> around a heading, or nil. Plain
+// divs don't count: sites often wrap a lone heading in one, with its text after the div.
+func sectionScope(heading *goquery.Selection) *goquery.Selection {
+	scope := heading.Closest("section, article")
+	if scope.Length() == 0 {
+		return nil
+	}
+	return scope
+}
+
+func (p *Parser) parseCheap(rawURL string, article readability.Article, parsedURL *url.URL, trust *models.TrustConfig, selector string, keepLineBreaks bool) (*models.Page, error) {
 
 	doc, err := goquery.NewDocumentFromReader(
 		strings.NewReader(article.Content),
@@ -240,20 +446,24 @@ func (p *Parser) parseCheap(rawURL string, article readability.Article, parsedUR
 	var blocks []models.ContentBlock
 	blockCounter := 0
 
-	doc.Find("h1,h2,h3,p,div,pre,blockquote").Each(func(_ int, s *goquery.Selection) {
+	preCaptured := selectsTag(selector, "pre")
+	doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
 		// Skip container divs with children to avoid duplication
 		if s.Children().Length() > 0 && goquery.NodeName(s) == "div" {
 			return
 		}
 
 		tag := goquery.NodeName(s)
+		if preCaptured && inPre(s) {
+			return // Part of the enclosing <pre> block
+		}
 		var text string
 
 		// Handle code blocks specially to remove line numbers
 		if tag == "pre" {
 			text = cleanCodeBlock(s)
 		} else {
-			text = normalizeText(s.Text())
+			text = blockText(s, keepLineBreaks)
 		}
 
 		if text == "" {
@@ -265,6 +475,8 @@ func (p *Parser) parseCheap(rawURL string, article readability.Article, parsedUR
 
 		blocks = append(blocks, models.ContentBlock{
 			ID:         fmt.Sprintf("block-%d", blockCounter),
+			Position:   blockCounter,
+			Path:       nodePath(s),
 			Type:       tag,
 			Text:       text,
 			Links:      links,
@@ -283,11 +495,14 @@ func (p *Parser) parseCheap(rawURL string, article readability.Article, parsedUR
 		FlatContent: blocks,
 	}
 
+	page.Images = extractImages(doc, parsedURL)
+	page.Metadata.ImageCount = len(page.Images)
+
 	page.Metadata.ExtractionMode = "cheap"
 	page.Metadata.ExtractionQuality = quality
 
 	// Enrich metadata from article and detector
-	enrichMetadata(page, article, rawURL)
+	enrichMetadata(page, article, rawURL, trust)
 
 	return page, nil
 }
@@ -297,14 +512,23 @@ func extractTable(s *goquery.Selection) *models.Table {
 	var rows [][]string
 
 	s.Find("tr").Each(func(_ int, tr *goquery.Selection) {
-		var row []string
+		cells := tr.Find("th,td")
+		isHeaderRow := cells.Length() > 0 && cells.Filter("td").Length() == 0
 
-		tr.Find("th").Each(func(_ int, th *goquery.Selection) {
-			headers = append(headers, normalizeText(th.Text()))
-		})
+		// The first all-<th> row names the columns; row-label <th> cells stay in their row
+		if isHeaderRow && headers == nil {
+			cells.Each(func(_ int, th *goquery.Selection) {
+				headers = appendSpanned(headers, normalizeText(th.Text()), th)
+			})
+			return
+		}
+		if isHeaderRow {
+			return
+		}
 
-		tr.Find("td").Each(func(_ int, td *goquery.Selection) {
-			row = append(row, normalizeText(td.Text()))
+		var row []string
+		cells.Each(func(_ int, cell *goquery.Selection) {
+			row = appendSpanned(row, normalizeText(cell.Text()), cell)
 		})
 
 		if len(row) > 0 {
@@ -318,9 +542,25 @@ func extractTable(s *goquery.Selection) *models.Table {
 	}
 }
 
+// maxColspan caps how many columns a single cell may expand into.
+const maxColspan = 50
+
+// appendSpanned appends text once per column the cell spans. Rowspan is not
+// carried into later rows; this is a best-effort flattening for record output.
+func appendSpanned(cells []string, text string, cell *goquery.Selection) []string {
+	span := 1
+	if v, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr("colspan", "1"))); err == nil && v > 1 {
+		span = min(v, maxColspan)
+	}
+	for i := 0; i < span; i++ {
+		cells = append(cells, text)
+	}
+	return cells
+}
+
 func normalizeText(input string) string {
 	var b strings.Builder
-	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner := bufio.NewScanner(strings.NewReader(cleanText(input)))
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -353,6 +593,59 @@ func extractLinks(s *goquery.Selection,	pageURL *url.URL) []models.Link {
 	return links
 }
 
+// extractImages collects images from the main content, resolving src to absolute URLs.
+// Data URIs and tracking-pixel-sized images are skipped; duplicates are collapsed.
+func extractImages(doc *goquery.Document, pageURL *url.URL) []models.Image {
+	var images []models.Image
+	seen := make(map[string]bool)
+
+	doc.Find("img").Each(func(_ int, img *goquery.Selection) {
+		src := strings.TrimSpace(img.AttrOr("src", ""))
+		if src == "" {
+			// Lazy-loaded images often keep the real URL in data-src
+			src = strings.TrimSpace(img.AttrOr("data-src", ""))
+		}
+		if src == "" || strings.HasPrefix(strings.ToLower(src), "data:") {
+			return
+		}
+
+		width := parseDimension(img.AttrOr("width", ""))
+		height := parseDimension(img.AttrOr("height", ""))
+		if (width > 0 && width <= 2) || (height > 0 && height <= 2) {
+			return // tracking pixel
+		}
+
+		ref, err := url.Parse(src)
+		if err != nil {
+			return
+		}
+		absSrc := pageURL.ResolveReference(ref).String()
+		if seen[absSrc] {
+			return
+		}
+		seen[absSrc] = true
+
+		images = append(images, models.Image{
+			Src:    absSrc,
+			Alt:    normalizeText(img.AttrOr("alt", "")),
+			Width:  width,
+			Height: height,
+		})
+	})
+
+	return images
+}
+
+// parseDimension parses an HTML width/height attribute ("300" or "300px"), returning 0 if unset or invalid.
+func parseDimension(value string) int {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 func classifyLink(href string, pageURL *url.URL) models.LinkType {
 	if strings.HasPrefix(href, "#") || strings.HasPrefix(href, "/") {
 		return models.LinkInternal
@@ -370,44 +663,10 @@ func classifyLink(href string, pageURL *url.URL) models.LinkType {
 	return models.LinkExternal
 }
 
-func computeConfidence(text string, links int, blockType string) float64 {
-	if blockType == "code" || blockType == "table" {
-		return 0.95 // structured content is usually high-signal
-	}
-
-	words := len(strings.Fields(text))
-	if words == 0 {
-		return 0.0
-	}
-
-	score := 0.4
-
-	// Text density
-	switch {
-	case words > 120:
-		score += 0.4
-	case words > 40:
-		score += 0.25
-	case words > 15:
-		score += 0.1 
-	}
-
-	// Link penalty
-	score -= float64(links) * 0.05
-
-	// Clamp
-	if score < 0 {
-		return 0
-	}
-	if score > 1 {
-		return 1
-	}
-	return score
-}
-
-
-// enrichMetadata populates page metadata from readability article and detector analysis
-func enrichMetadata(page *models.Page, article readability.Article, rawURL string) {
+// enrichMetadata populates page metadata from readability article and detector analysis.
+// Every parse mode calls it, so minimal and cheap pages keep the byline, excerpt, site
+// name and image too.
+func enrichMetadata(page *models.Page, article readability.Article, rawURL string, trust *models.TrustConfig) {
 	// Populate readability metadata
 	page.Metadata.Author = article.Byline
 	page.Metadata.Excerpt = article.Excerpt
@@ -420,13 +679,14 @@ func enrichMetadata(page *models.Page, article readability.Article, rawURL strin
 
 	// Get content for detector analysis (use article.Content for academic detection)
 	// This is more reliable than page.ToPlainText() which may be empty in cheap mode
-	enriched := detector.Analyze(rawURL, article, article.Content, nil)
+	enriched := detector.Analyze(rawURL, article, article.Content, nil, trust)
 
 	// Populate detector metadata
 	page.Metadata.DomainType = enriched.DomainType
 	page.Metadata.DomainCategory = enriched.DomainCategory
 	page.Metadata.Country = enriched.Country
 	page.Metadata.Confidence = enriched.Confidence
+	page.Metadata.TrustRule = enriched.TrustRule
 
 	page.Metadata.HasDOI = enriched.HasDOI
 	page.Metadata.DOIPattern = enriched.DOIPattern
@@ -500,11 +760,51 @@ func countCodeBlocks(content string) int {
 	// Count markdown code blocks
 	markdownBlocks := strings.Count(content, "```") / 2
 
-	// Count HTML code blocks
-	htmlCodeBlocks := strings.Count(content, "<code>")
-	htmlPreBlocks := strings.Count(content, "<pre>")
+	// Count HTML code blocks: each <pre>, plus any <code> outside one (<pre><code> is one block)
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return markdownBlocks
+	}
+	htmlBlocks := doc.Find("pre").Length()
+	doc.Find("code").Each(func(_ int, s *goquery.Selection) {
+		if !inPre(s) {
+			htmlBlocks++
+		}
+	})
+
+	return markdownBlocks + htmlBlocks
+}
 
-	return markdownBlocks + htmlCodeBlocks + htmlPreBlocks
+// inPre reports whether s is a <code> inside a <pre>. When both are captured the <pre>
+// alone yields the block, so a fenced <pre><code> isn't emitted twice.
+func inPre(s *goquery.Selection) bool {
+	return goquery.NodeName(s) == "code" && s.ParentsFiltered("pre").Length() > 0
+}
+
+// codeLanguagePrefixes are class prefixes highlighters use to name a block's language:
+// language-go (CommonMark, Prism, highlight.js), lang-go, highlight-source-go (GitHub).
+var codeLanguagePrefixes = []string{"language-", "lang-", "highlight-source-"}
+
+// codeLanguage returns the language declared on a code block, checking the inner <code>
+// first, then the block itself, then its wrapper. Returns "" when none is declared.
+func codeLanguage(s *goquery.Selection) string {
+	candidates := []*goquery.Selection{s.Find("code").First(), s, s.Parent()}
+	for _, c := range candidates {
+		if c.Length() == 0 {
+			continue
+		}
+		if lang := strings.TrimSpace(c.AttrOr("data-lang", "")); lang != "" {
+			return strings.ToLower(lang)
+		}
+		for _, class := range strings.Fields(c.AttrOr("class", "")) {
+			for _, prefix := range codeLanguagePrefixes {
+				if lang, ok := strings.CutPrefix(class, prefix); ok && lang != "" {
+					return strings.ToLower(lang)
+				}
+			}
+		}
+	}
+	return ""
 }
 
 // cleanCodeBlock removes line numbers and cleans code block content
@@ -520,8 +820,8 @@ func cleanCodeBlock(s *goquery.Selection) string {
 	// Get the text content
 	text := clone.Text()
 
-	// Clean up the text
-	text = normalizeText(text)
+	// Keep the code's own line breaks and indentation
+	text = normalizeCode(text)
 
 	// If the result looks like just line numbers (all digits/spaces), return empty
 	// This handles cases where entire pre blocks are just line number containers
@@ -542,12 +842,7 @@ func cleanCodeBlock(s *goquery.Selection) string {
 // extractMetaKeywords extracts keywords from HTML meta tags
 // Looks for: <meta name="keywords" content="react, hooks, components">
 // Returns: ["react", "hooks", "components"]
-func extractMetaKeywords(html string) []string {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
-	if err != nil {
-		return nil
-	}
-
+func extractMetaKeywords(doc *goquery.Document) []string {
 	var keywords []string
 
 	// Try <meta name="keywords">
@@ -577,3 +872,31 @@ func extractMetaKeywords(html string) []string {
 
 	return keywords
 }
+
+// extractCanonicalURL returns the absolute URL declared by <link rel="canonical">,
+// or "" if the page declares none. Relative hrefs are resolved against base.
+func extractCanonicalURL(doc *goquery.Document, base *url.URL) string {
+	var canonical string
+	doc.Find("link[rel]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		rel, _ := s.Attr("rel")
+		if !strings.EqualFold(strings.TrimSpace(rel), "canonical") {
+			return true
+		}
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		if href == "" {
+			return true
+		}
+		ref, err := url.Parse(href)
+		if err != nil {
+			return true
+		}
+		resolved := base.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			return true
+		}
+		resolved.Fragment = ""
+		canonical = resolved.String()
+		return false
+	})
+	return canonical
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"github.com/go-shiori/go-readability"
+)
+
+const articleContent = `<div><h1>Widget Guide</h1>
+<p>Widgets are small components that each do one thing well and compose with other widgets.</p>
+<p>Install the widget toolkit with your package manager before building any widget.</p>
+<p>Every widget ships with tests, documentation and a changelog describing each release.</p></div>`
+
+// Every mode keeps readability's byline, excerpt, site name and image, not just the
+// detector-driven full path, so summaries have them for cheap and minimal parses too.
+func TestReadabilityMetadataInEveryMode(t *testing.T) {
+	article := readability.Article{
+		Title:       "Widget Guide",
+		Byline:      "Ada Lovelace",
+		Excerpt:     "How to build and ship widgets.",
+		SiteName:    "Widget Co",
+		Image:       "https://widgets.example.com/img/guide.png",
+		Favicon:     "https://widgets.example.com/favicon.ico",
+		Content:     articleContent,
+		TextContent: "Widgets are small components.",
+	}
+	rawURL := "https://widgets.example.com/guide"
+	parsedURL, _ := url.Parse(rawURL)
+	p := &Parser{}
+
+	parses := map[string]func() (*models.Page, error){
+		"minimal": func() (*models.Page, error) { return p.parseMinimal(rawURL, article, parsedURL, nil) },
+		"cheap": func() (*models.Page, error) {
+			return p.parseCheap(rawURL, article, parsedURL, nil, blockSelector(cheapBlockTags, nil), false)
+		},
+		"full": func() (*models.Page, error) {
+			return p.parseFull(rawURL, article, parsedURL, nil, blockSelector(fullBlockTags, nil), false)
+		},
+	}
+	for mode, parse := range parses {
+		page, err := parse()
+		if err != nil {
+			t.Fatalf("%s: parse error = %v", mode, err)
+		}
+		meta := page.Metadata
+		if meta.Author != article.Byline || meta.Excerpt != article.Excerpt || meta.SiteName != article.SiteName ||
+			meta.Image != article.Image || meta.Favicon != article.Favicon {
+			t.Errorf("%s: author %q, excerpt %q, site %q, image %q, favicon %q; want the article's",
+				mode, meta.Author, meta.Excerpt, meta.SiteName, meta.Image, meta.Favicon)
+		}
+	}
+}
+
+// A cheap parse keeps the page's excerpt whether it stays cheap or escalates to full.
+func TestParse_CheapExcerpt(t *testing.T) {
+	html := `<!DOCTYPE html>
+<html><head>
+<title>Widget Guide</title>
+<meta property="og:description" content="How to build and ship widgets.">
+</head>
+<body><article>` + articleContent + `</article></body></html>`
+
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://widgets.example.com/guide", HTML: html, Mode: models.ParseModeCheap})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.Excerpt == "" {
+		t.Error("Metadata.Excerpt is empty for a cheap parse of a page with a description")
+	}
+}
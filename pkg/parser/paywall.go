@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// paywallPhrases are calls to action that stand in for the rest of a paywalled article.
+var paywallPhrases = []string{
+	"subscribe to continue",
+	"subscribe to read",
+	"subscribe now to continue",
+	"subscribe to unlock",
+	"subscribers only",
+	"exclusive to subscribers",
+	"this article is for subscribers",
+	"this content is for subscribers",
+	"already a subscriber",
+	"continue reading with a subscription",
+	"sign in to continue reading",
+	"log in to continue reading",
+	"register to continue reading",
+	"create a free account to continue",
+	"become a member to read",
+	"unlock this article",
+	"purchase this article",
+	"buy this article",
+	"purchase access",
+	"get full access to this article",
+}
+
+const (
+	// A phrase this close to the end of the extracted text is where the teaser stops
+	paywallTailChars = 600
+	// Teasers are short; a long article that merely mentions subscriptions is not one
+	paywallTeaserMaxWords = 800
+	// A phrase only in the page chrome (an overlay outside the article) needs a stub article
+	paywallOverlayMaxWords = 300
+)
+
+// accessibleForFreeFalse matches schema.org's paywall declaration in JSON-LD.
+var accessibleForFreeFalse = regexp.MustCompile(`(?i)"isAccessibleForFree"\s*:\s*"?false"?`)
+
+// detectPaywall reports whether the extracted text looks like a teaser for paywalled
+// content: the page declares isAccessibleForFree false, the text ends near a paywall
+// phrase, or the text is a stub while the rest of the page carries one.
+func detectPaywall(doc *goquery.Document, text string) bool {
+	if doc != nil {
+		declared := false
+		doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			declared = accessibleForFreeFalse.MatchString(s.Text())
+			return !declared
+		})
+		if declared {
+			return true
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) > paywallTeaserMaxWords {
+		return false
+	}
+	normalized := strings.Join(words, " ")
+	tail := normalized[max(0, len(normalized)-paywallTailChars):]
+	if containsPaywallPhrase(tail) {
+		return true
+	}
+
+	if doc == nil || len(words) > paywallOverlayMaxWords {
+		return false
+	}
+	body := strings.Join(strings.Fields(strings.ToLower(doc.Find("body").Text())), " ")
+	return containsPaywallPhrase(body)
+}
+
+func containsPaywallPhrase(text string) bool {
+	for _, phrase := range paywallPhrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestParse_DetectsPaywall(t *testing.T) {
+	longArticle := strings.Repeat("<p>The committee reviewed the budget figures and the reporting deadlines in detail. </p>", 120)
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{
+			name: "teaser ends at subscribe prompt",
+			html: `<html><head><title>Markets</title></head><body><article><h1>Markets rally</h1>
+<p>Stocks rose sharply on Tuesday as investors weighed the central bank's latest signals on rates.</p>
+<p>Analysts said the move reflected relief after weeks of volatility in bond markets.</p>
+<p>Subscribe to continue reading this story.</p></article></body></html>`,
+			want: true,
+		},
+		{
+			name: "schema.org isAccessibleForFree false",
+			html: `<html><head><title>Study</title><script type="application/ld+json">{"@type":"NewsArticle","isAccessibleForFree": "False"}</script></head>
+<body><article><h1>Study</h1>` + longArticle + `</article></body></html>`,
+			want: true,
+		},
+		{
+			name: "long article with a subscription footer",
+			html: `<html><head><title>Report</title></head><body><article><h1>Report</h1>` + longArticle +
+				`<p>Already a subscriber? Manage your newsletter preferences.</p></article></body></html>`,
+			want: false,
+		},
+		{
+			name: "free article",
+			html: `<html><head><title>Guide</title></head><body><article><h1>Guide</h1>` + longArticle + `</article></body></html>`,
+			want: false,
+		},
+	}
+
+	p := &Parser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := p.Parse(models.ParseRequest{URL: "https://news.example.com/story", HTML: tt.html, Mode: models.ParseModeCheap})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if page.Metadata.Paywalled != tt.want {
+				t.Errorf("Metadata.Paywalled = %v, want %v", page.Metadata.Paywalled, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// robotsValuedDirectives take a value after a colon ("max-snippet: 50"), so a colon after
+// them does not name a crawler.
+var robotsValuedDirectives = map[string]bool{
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+	"unavailable_after": true,
+}
+
+// extractRobotsDirectives merges <meta name="robots"> content with the X-Robots-Tag header
+// lines in headers. It returns nil when neither declares a directive for all crawlers.
+func extractRobotsDirectives(doc *goquery.Document, headers []string) *models.RobotsDirectives {
+	robots := &models.RobotsDirectives{}
+	seen := make(map[string]bool)
+	add := func(source string, directives []string) {
+		if len(directives) == 0 {
+			return
+		}
+		switch robots.Source {
+		case "":
+			robots.Source = source
+		case source:
+		default:
+			robots.Source = "meta+header"
+		}
+		for _, d := range directives {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			robots.Directives = append(robots.Directives, d)
+			switch d {
+			case "noindex":
+				robots.NoIndex = true
+			case "nofollow":
+				robots.NoFollow = true
+			case "none":
+				robots.NoIndex, robots.NoFollow = true, true
+			}
+		}
+	}
+
+	if doc != nil {
+		doc.Find("meta[name][content]").Each(func(_ int, s *goquery.Selection) {
+			if name, _ := s.Attr("name"); strings.EqualFold(strings.TrimSpace(name), "robots") {
+				content, _ := s.Attr("content")
+				add("meta", parseRobotsDirectives(content))
+			}
+		})
+	}
+	for _, header := range headers {
+		// "googlebot: noindex" addresses one crawler; skip the whole line
+		if name, _, found := strings.Cut(header, ":"); found && !strings.Contains(name, ",") &&
+			!robotsValuedDirectives[strings.ToLower(strings.TrimSpace(name))] {
+			continue
+		}
+		add("header", parseRobotsDirectives(header))
+	}
+
+	if robots.Source == "" {
+		return nil
+	}
+	return robots
+}
+
+// parseRobotsDirectives splits a comma-separated directive list into lowercased directives,
+// closing up the space in valued ones ("max-snippet: 50" becomes "max-snippet:50").
+func parseRobotsDirectives(content string) []string {
+	var directives []string
+	for _, part := range strings.Split(content, ",") {
+		d := strings.ToLower(strings.TrimSpace(part))
+		if name, value, found := strings.Cut(d, ":"); found {
+			d = strings.TrimSpace(name) + ":" + strings.TrimSpace(value)
+		}
+		if d != "" {
+			directives = append(directives, d)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractRobotsDirectives(t *testing.T) {
+	tests := []struct {
+		name           string
+		head           string
+		headers        []string
+		wantNil        bool
+		noIndex        bool
+		noFollow       bool
+		wantDirectives []string
+		wantSource     string
+	}{
+		{
+			name:           "meta noindex nofollow",
+			head:           `<meta name="robots" content="NoIndex, nofollow">`,
+			noIndex:        true,
+			noFollow:       true,
+			wantDirectives: []string{"noindex", "nofollow"},
+			wantSource:     "meta",
+		},
+		{
+			name:           "header none",
+			headers:        []string{"none"},
+			noIndex:        true,
+			noFollow:       true,
+			wantDirectives: []string{"none"},
+			wantSource:     "header",
+		},
+		{
+			name:           "meta and header merge",
+			head:           `<meta name="ROBOTS" content="noarchive">`,
+			headers:        []string{"noindex, max-snippet: 50", "noarchive"},
+			noIndex:        true,
+			wantDirectives: []string{"noarchive", "noindex", "max-snippet:50"},
+			wantSource:     "meta+header",
+		},
+		{
+			name:           "crawler-specific directives ignored",
+			head:           `<meta name="googlebot" content="noindex">`,
+			headers:        []string{"googlebot: noindex, nofollow", "unavailable_after: 2030-01-01"},
+			wantDirectives: []string{"unavailable_after:2030-01-01"},
+			wantSource:     "header",
+		},
+		{
+			name:    "nothing declared",
+			head:    `<meta name="description" content="noindex">`,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.head + "</head><body></body></html>"))
+			if err != nil {
+				t.Fatalf("NewDocumentFromReader() error = %v", err)
+			}
+			got := extractRobotsDirectives(doc, tt.headers)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("extractRobotsDirectives() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("extractRobotsDirectives() = nil")
+			}
+			if got.NoIndex != tt.noIndex || got.NoFollow != tt.noFollow {
+				t.Errorf("noindex=%v nofollow=%v, want %v %v", got.NoIndex, got.NoFollow, tt.noIndex, tt.noFollow)
+			}
+			if !reflect.DeepEqual(got.Directives, tt.wantDirectives) {
+				t.Errorf("directives = %v, want %v", got.Directives, tt.wantDirectives)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("source = %q, want %q", got.Source, tt.wantSource)
+			}
+		})
+	}
+}
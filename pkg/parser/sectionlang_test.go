@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// A Japanese tutorial with an English API reference section: each section is judged
+// on its own prose, code examples left out.
+const bilingualHTML = `<article>
+<h1>はじめてのウェブ解析</h1>
+<p>このガイドでは、ウェブページを取得して、本文を読みやすい形に変換する方法を説明します。まず、対象となるページの一覧を用意してください。次に、コマンドを実行すると、結果がファイルに保存されます。</p>
+<p>保存された結果は、あとから何度でも読み直すことができます。ネットワークに接続できないときでも、手元のデータを使って作業を続けられます。</p>
+<h2>API Reference</h2>
+<p>The client exposes a single function that fetches a page and returns its parsed content. Callers should check the returned error before reading any field of the result, because partial results are never returned.</p>
+<pre><code>// Fetch returns the parsed page
+page, err := client.Fetch(ctx, url)</code></pre>
+<h2>よくある質問</h2>
+<p>取得に失敗した場合は、時間をおいてからもう一度試してください。同じページを何度も取得すると、相手のサーバーに負担がかかることがあります。</p>
+</article>`
+
+func TestParse_SectionLanguages(t *testing.T) {
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/guide", HTML: bilingualHTML, Mode: models.ParseModeFull, SectionLanguages: true})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	languages := make(map[string]string)
+	var walk func(sections []models.Section)
+	walk = func(sections []models.Section) {
+		for _, s := range sections {
+			if s.Heading != nil {
+				languages[s.Heading.Text] = s.Language
+			}
+			walk(s.Children)
+		}
+	}
+	walk(page.Content)
+
+	want := map[string]string{"はじめてのウェブ解析": "ja", "API Reference": "en", "よくある質問": "ja"}
+	for heading, lang := range want {
+		if languages[heading] != lang {
+			t.Errorf("section %q language = %q, want %q (all: %v)", heading, languages[heading], lang, languages)
+		}
+	}
+
+	dist := page.Metadata.LanguageDistribution
+	if len(dist) != 2 || dist["ja"] <= dist["en"] || dist["en"] == 0 {
+		t.Errorf("LanguageDistribution = %v, want mostly ja with some en", dist)
+	}
+
+	// Off by default: no per-section work
+	page, err = p.Parse(models.ParseRequest{URL: "https://example.com/guide", HTML: bilingualHTML, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if page.Metadata.LanguageDistribution != nil || page.Content[0].Language != "" {
+		t.Errorf("section languages detected without SectionLanguages: %v", page.Metadata.LanguageDistribution)
+	}
+}
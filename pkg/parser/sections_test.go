@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// sectionTree renders sections as indented "heading: first word of each block" lines,
+// so expected containment and order read like the document's outline.
+func sectionTree(sections []models.Section, depth int) []string {
+	var lines []string
+	for _, s := range sections {
+		heading := "(untitled)"
+		if s.Heading != nil {
+			heading = s.Heading.Text
+		}
+		var blocks []string
+		for _, b := range s.Blocks {
+			blocks = append(blocks, strings.Fields(b.Text)[0])
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s", strings.Repeat("  ", depth), heading, strings.Join(blocks, ",")))
+		lines = append(lines, sectionTree(s.Children, depth+1)...)
+	}
+	return lines
+}
+
+func parseSections(t *testing.T, html string) []string {
+	t.Helper()
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/doc", HTML: html, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	// Whatever the outline, the parser must keep the page's structural invariants
+	for _, violation := range page.Validate() {
+		t.Errorf("Validate() violation: %v", violation)
+	}
+	return sectionTree(page.Content, 0)
+}
+
+func TestParseFull_SectionAttribution(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{
+			name: "heading levels nest and unwind",
+			html: `<article>
+<h1>Guide</h1><p>Overview of the guide.</p>
+<h2>Install</h2><p>Download the package.</p>
+<h3>Linux</h3><p>Use apt.</p>
+<h3>macOS</h3><p>Use brew.</p>
+<h2>Usage</h2><p>Run the tool.</p>
+<h4>Flags</h4><p>Flags are optional.</p>
+<h2>FAQ</h2><p>Questions follow.</p>
+</article>`,
+			want: []string{
+				"Guide: Overview",
+				"  Install: Download",
+				"    Linux: Use",
+				"    macOS: Use",
+				"  Usage: Run",
+				"    Flags: Flags",
+				"  FAQ: Questions",
+			},
+		},
+		{
+			name: "content before the first heading stays a sibling",
+			html: `<article>
+<p>Preface before any heading.</p>
+<h1>Chapter One</h1><p>First chapter text.</p>
+<h1>Chapter Two</h1><p>Second chapter text.</p>
+</article>`,
+			want: []string{
+				"(untitled): Preface",
+				"Chapter One: First",
+				"Chapter Two: Second",
+			},
+		},
+		{
+			name: "text after a nested section returns to its parent",
+			html: `<article>
+<section><h2>Alpha</h2><p>Alpha opening text.</p>
+  <section><h3>Beta</h3><p>Beta detail text.</p></section>
+  <p>Closing alpha text.</p>
+</section>
+<section><h2>Gamma</h2><p>Gamma text.</p></section>
+</article>`,
+			want: []string{
+				"Alpha: Alpha,Closing",
+				"  Beta: Beta",
+				"Gamma: Gamma",
+			},
+		},
+		{
+			name: "sections close but wrapper divs do not",
+			html: `<article>
+<h1>Report</h1>
+<section><h3>Method</h3><p>We measured things.</p></section>
+<p>Summary under report.</p>
+<div class="heading"><h3>Results</h3></div>
+<p>Things were measured.</p>
+<p>Results continue.</p>
+</article>`,
+			want: []string{
+				"Report: Summary",
+				"  Method: We",
+				"  Results: Things,Results",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSections(t, tt.html)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sections:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParse_MaxSectionDepth(t *testing.T) {
+	html := `<article>
+<h1>Guide</h1><p>Overview of the guide.</p>
+<h2>Install</h2><p>Download the package.</p>
+<h3>Linux</h3><p>Pick a distribution.</p>
+<h4>Debian</h4><p>Use apt.</p>
+<h5>Bookworm</h5><p>Enable backports.</p>
+<h6>Arm64</h6><p>Use the arm64 build.</p>
+<h4>Fedora</h4><p>Use dnf.</p>
+<h3>macOS</h3><p>Use brew.</p>
+<h2>Usage</h2><p>Run the tool.</p>
+</article>`
+
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/doc", HTML: html, Mode: models.ParseModeFull, MaxSectionDepth: 3})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Headings below h3 stay in the text, in order, as blocks of the Linux section
+	want := []string{
+		"Guide: Overview",
+		"  Install: Download",
+		"    Linux: Pick,Debian,Use,Bookworm,Enable,Arm64,Use,Fedora,Use",
+		"    macOS: Use",
+		"  Usage: Run",
+	}
+	if got := sectionTree(page.Content, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("sections:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if page.Metadata.SectionCount != 5 {
+		t.Errorf("SectionCount = %d, want 5", page.Metadata.SectionCount)
+	}
+
+	if len(page.Content) == 0 || len(page.Content[0].Children) == 0 || len(page.Content[0].Children[0].Children) == 0 {
+		t.Fatalf("no Guide > Install > Linux section in %v", sectionTree(page.Content, 0))
+	}
+	linux := page.Content[0].Children[0].Children[0]
+	if len(linux.Blocks) < 6 {
+		t.Fatalf("Linux section has %d blocks, want the flattened headings among them", len(linux.Blocks))
+	}
+	if linux.Blocks[1].Type != "h4" || linux.Blocks[3].Type != "h5" || linux.Blocks[5].Type != "h6" {
+		t.Errorf("flattened heading types = %s, %s, %s", linux.Blocks[1].Type, linux.Blocks[3].Type, linux.Blocks[5].Type)
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// extractSocialMetadata reads OpenGraph and Twitter card <meta> tags. Sites disagree on
+// property= vs name=, so both are accepted for either prefix; the first non-empty value
+// of each tag wins. Returns nil when the page declares none.
+func extractSocialMetadata(doc *goquery.Document, base *url.URL) *models.SocialMetadata {
+	tags := make(map[string]string)
+	doc.Find("meta[property], meta[name]").Each(func(_ int, s *goquery.Selection) {
+		key := strings.ToLower(strings.TrimSpace(s.AttrOr("property", "")))
+		if key == "" {
+			key = strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+		}
+		if !strings.HasPrefix(key, "og:") && !strings.HasPrefix(key, "twitter:") {
+			return
+		}
+		content := strings.Join(strings.Fields(s.AttrOr("content", "")), " ")
+		if content == "" || tags[key] != "" {
+			return
+		}
+		tags[key] = content
+	})
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return &models.SocialMetadata{
+		Title:              tags["og:title"],
+		Description:        tags["og:description"],
+		Image:              resolveSocialURL(base, firstNonEmpty(tags["og:image"], tags["og:image:url"], tags["og:image:secure_url"])),
+		Type:               tags["og:type"],
+		URL:                resolveSocialURL(base, tags["og:url"]),
+		SiteName:           tags["og:site_name"],
+		Locale:             tags["og:locale"],
+		TwitterCard:        tags["twitter:card"],
+		TwitterTitle:       tags["twitter:title"],
+		TwitterDescription: tags["twitter:description"],
+		TwitterImage:       resolveSocialURL(base, firstNonEmpty(tags["twitter:image"], tags["twitter:image:src"])),
+		TwitterSite:        tags["twitter:site"],
+		TwitterCreator:     tags["twitter:creator"],
+	}
+}
+
+// applySocialFallbacks fills an empty excerpt and image from the page's social tags.
+func applySocialFallbacks(page *models.Page, social *models.SocialMetadata) {
+	if social == nil {
+		return
+	}
+	page.Metadata.Social = social
+	if page.Metadata.Excerpt == "" {
+		page.Metadata.Excerpt = social.PreviewDescription()
+	}
+	if page.Metadata.Image == "" {
+		page.Metadata.Image = social.PreviewImage()
+	}
+}
+
+// resolveSocialURL makes a tag's URL absolute; values that don't parse are kept as given.
+func resolveSocialURL(base *url.URL, raw string) string {
+	if raw == "" || base == nil {
+		return raw
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
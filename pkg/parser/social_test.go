@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+const socialPage = `<!DOCTYPE html>
+<html>
+<head>
+<title>Widget launch</title>
+<meta property="og:title" content="Widgets 2.0 are here">
+<meta property="og:type" content="article">
+<meta property="og:image" content="/img/launch.png">
+<meta name="og:site_name" content="Widget Co">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:description" content="Faster,   smaller widgets.">
+<meta property="twitter:site" content="@widgetco">
+<meta name="description" content="">
+</head>
+<body><article><p>Short.</p></article></body>
+</html>`
+
+func TestExtractSocialMetadata(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(socialPage))
+	if err != nil {
+		t.Fatalf("NewDocumentFromReader() error = %v", err)
+	}
+	base, _ := url.Parse("https://widgets.example.com/news/launch")
+
+	social := extractSocialMetadata(doc, base)
+	if social == nil {
+		t.Fatal("extractSocialMetadata() = nil, want tags")
+	}
+
+	want := models.SocialMetadata{
+		Title:              "Widgets 2.0 are here",
+		Image:              "https://widgets.example.com/img/launch.png",
+		Type:               "article",
+		SiteName:           "Widget Co",
+		TwitterCard:        "summary_large_image",
+		TwitterDescription: "Faster, smaller widgets.",
+		TwitterSite:        "@widgetco",
+	}
+	if *social != want {
+		t.Errorf("extractSocialMetadata() = %+v, want %+v", *social, want)
+	}
+
+	plain, _ := goquery.NewDocumentFromReader(strings.NewReader(`<html><head><title>x</title></head></html>`))
+	if got := extractSocialMetadata(plain, base); got != nil {
+		t.Errorf("extractSocialMetadata() on a page without tags = %+v, want nil", got)
+	}
+}
+
+func TestParse_SocialFallbacks(t *testing.T) {
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://widgets.example.com/news/launch", HTML: socialPage})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if page.Metadata.Social == nil || page.Metadata.Social.TwitterCard != "summary_large_image" {
+		t.Fatalf("Metadata.Social = %+v, want the page's social tags", page.Metadata.Social)
+	}
+	if page.Metadata.Image == "" {
+		t.Error("Metadata.Image is empty, want the og:image")
+	}
+	if page.Metadata.Excerpt == "" {
+		t.Error("Metadata.Excerpt is empty, want a description")
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// soft404Phrases are what error and placeholder pages say in place of content.
+var soft404Phrases = []string{
+	"page not found",
+	"not found",
+	"page doesn't exist",
+	"page does not exist",
+	"page you requested",
+	"page you are looking for",
+	"page you're looking for",
+	"could not be found",
+	"couldn't be found",
+	"can't be found",
+	"cannot be found",
+	"no longer exists",
+	"no longer available",
+	"has been removed",
+	"nothing was found",
+}
+
+const (
+	// A title naming the error only marks a short page; a long one is an article about it
+	soft404TitleMaxWords = 300
+	// A phrase only in the text needs a near-empty page
+	soft404TextMaxWords = 80
+)
+
+// error404 matches "404" as a status code, not inside other numbers or IDs.
+var error404 = regexp.MustCompile(`(^|[^\w.])404([^\w.]|$)`)
+
+// detectSoft404 reports whether a page that was served with 200 looks like an error or
+// placeholder page: its title says 404 or not found and there is little text, or the
+// text is almost empty and says so itself.
+func detectSoft404(title, text string) bool {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) > soft404TitleMaxWords {
+		return false
+	}
+	if containsSoft404Signal(strings.ToLower(title)) {
+		return true
+	}
+	if len(words) > soft404TextMaxWords {
+		return false
+	}
+	return containsSoft404Signal(strings.Join(words, " "))
+}
+
+func containsSoft404Signal(text string) bool {
+	text = strings.ReplaceAll(text, "’", "'")
+	if error404.MatchString(text) {
+		return true
+	}
+	for _, phrase := range soft404Phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
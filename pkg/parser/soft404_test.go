@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+func TestDetectSoft404(t *testing.T) {
+	article := strings.Repeat("Status codes tell clients whether a request worked and what to do next. ", 40)
+	tests := []struct {
+		name, title, text string
+		want              bool
+	}{
+		{"404 title", "404 - Example Store", "Sorry, we looked everywhere. Try the search box or go back to the home page.", true},
+		{"not found title", "Page Not Found | Docs", "Return home", true},
+		{"curly apostrophe in body", "Example Co", "Oops! This page doesn’t exist. Maybe it moved? Head back to the homepage.", true},
+		{"removed listing", "Listings", "This listing has been removed by the seller.", true},
+		{"placeholder with empty title", "", "The page you are looking for could not be found.", true},
+		{"article about 404 errors", "How to fix 404 errors", article, false},
+		{"long page mentioning not found", "Troubleshooting", article + "If the file is not found, check the path.", false},
+		{"404 inside a number", "Order 140421 shipped", "Your order shipped today and arrives on Friday.", false},
+		{"version number", "Release 2.404.1", "Bug fixes.", false},
+		{"ordinary short page", "Contact us", "Email hello@example.com or call us during office hours.", false},
+	}
+	for _, tt := range tests {
+		if got := detectSoft404(tt.title, tt.text); got != tt.want {
+			t.Errorf("%s: detectSoft404() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParse_FlagsSoft404(t *testing.T) {
+	html := `<html><head><title>Page not found</title></head><body><main><h1>Oops</h1>
+<p>We can't find that page. It may have been moved or deleted.</p><a href="/">Home</a></main></body></html>`
+	page, err := (&Parser{}).Parse(models.ParseRequest{URL: "https://shop.example.com/p/123", HTML: html, Mode: models.ParseModeFull})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !page.Metadata.Soft404 {
+		t.Error("Metadata.Soft404 = false, want true for a not-found page served as content")
+	}
+}
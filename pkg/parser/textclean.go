@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// blockText returns a block's normalized text. A <br> separates words like any other
+// whitespace, or, with keepLineBreaks (--canonicalize-whitespace=false), starts a new
+// line, so poetry, addresses and lyrics keep their shape. Line breaks in the page
+// source are only formatting and are collapsed either way.
+func blockText(s *goquery.Selection, keepLineBreaks bool) string {
+	var lines []string
+	var current strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			current.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			lines = append(lines, current.String())
+			current.Reset()
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range s.Nodes {
+		walk(n)
+	}
+	lines = append(lines, current.String())
+
+	separator := " "
+	if keepLineBreaks {
+		separator = "\n"
+	}
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = normalizeText(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, separator)
+}
+
+// normalizeCode tidies a code block without touching its layout: it unifies line
+// endings, strips trailing whitespace and drops blank lines at either end, but keeps
+// every line's indentation and the blank lines between them.
+func normalizeCode(code string) string {
+	code = strings.ReplaceAll(code, "\r\n", "\n")
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r\u00a0")
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanText repairs extracted text: entities left escaped by double-encoded pages
+// ("&amp;", "&#x27;"), UTF-8 that was decoded as Windows-1252 ("donâ€™t"), typographic
+// apostrophes (so "don’t" and "don't" count as one word) and non-breaking spaces.
+func cleanText(s string) string {
+	if strings.IndexByte(s, '&') >= 0 {
+		s = html.UnescapeString(s)
+	}
+	s = fixMojibake(s)
+	return textReplacer.Replace(s)
+}
+
+var textReplacer = strings.NewReplacer(
+	"\u2019", "'", // Right single quotation mark, mostly used as an apostrophe
+	"\u2018", "'",
+	"\u00a0", " ", // Non-breaking space
+)
+
+// mojibakeLeads are the first bytes, read as Windows-1252, of the UTF-8 sequences that
+// show up as mojibake in practice: Â/Ã for Latin-1 letters and symbols, Å and Ë for
+// Œ, Š and ˆ, â for punctuation (’ “ ” – — … •) and ð for emoji. Other lead bytes are
+// ordinary letters too often followed by symbols to repair safely.
+var mojibakeLeads = map[byte]bool{0xC2: true, 0xC3: true, 0xC5: true, 0xCB: true, 0xE2: true, 0xF0: true}
+
+// cp1252Bytes maps the characters Windows-1252 puts in 0x80-0x9F back to their byte.
+var cp1252Bytes = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// windows1252Byte returns the byte r encodes to in Windows-1252. Runes 0x80-0x9F
+// map to themselves, as decoders do for the five bytes Windows-1252 leaves undefined.
+func windows1252Byte(r rune) (byte, bool) {
+	if b, ok := cp1252Bytes[r]; ok {
+		return b, true
+	}
+	if r < 0x100 {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+// fixMojibake re-decodes runs of characters that are the Windows-1252 reading of a UTF-8
+// sequence, leaving everything else as is.
+func fixMojibake(s string) string {
+	if !strings.ContainsAny(s, "ÂÃÅËâð") {
+		return s
+	}
+
+	runes := []rune(s)
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		if r, n := decodeMojibake(runes[i:]); n > 0 {
+			b.WriteRune(r)
+			i += n - 1
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
+
+// decodeMojibake decodes the UTF-8 sequence whose Windows-1252 reading starts runes,
+// returning the repaired rune and how many runes it replaces (0 = not mojibake).
+func decodeMojibake(runes []rune) (rune, int) {
+	lead, ok := windows1252Byte(runes[0])
+	if !ok || !mojibakeLeads[lead] {
+		return 0, 0
+	}
+	size := 2
+	switch {
+	case lead >= 0xF0:
+		size = 4
+	case lead >= 0xE0:
+		size = 3
+	}
+	if len(runes) < size {
+		return 0, 0
+	}
+
+	buf := []byte{lead}
+	for _, r := range runes[1:size] {
+		b, ok := windows1252Byte(r)
+		if !ok || b < 0x80 || b > 0xBF {
+			return 0, 0
+		}
+		buf = append(buf, b)
+	}
+	r, n := utf8.DecodeRune(buf)
+	if r == utf8.RuneError || n != size {
+		return 0, 0
+	}
+	return r, size
+}
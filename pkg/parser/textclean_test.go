@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"named entities", "Fish &amp; Chips &eacute;t&eacute;", "Fish & Chips été"},
+		{"numeric entities", "don&#x27;t stop &#8212; ever", "don't stop — ever"},
+		{"curly apostrophe", "don\u2019t \u2018quote\u2019", "don't 'quote'"},
+		{"nbsp", "10\u00a0km", "10 km"},
+		{"mojibake apostrophe", "donâ€™t", "don't"},
+		{"mojibake latin", "cafÃ© naÃ¯ve", "café naïve"},
+		{"mojibake quotes", "â€œquotedâ€\u009d", "\u201cquoted\u201d"},
+		{"mojibake dash", "2020â€“2024", "2020–2024"},
+		{"mojibake emoji", "ship it ðŸš€", "ship it 🚀"},
+		{"plain accents kept", "Ãlvaro measured 1 Ångström", "Ãlvaro measured 1 Ångström"},
+		{"plain text untouched", "nothing to fix here", "nothing to fix here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanText(tt.input); got != tt.want {
+				t.Errorf("cleanText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_NormalizesEntitiesAndMojibake(t *testing.T) {
+	// Double-encoded entities survive HTML parsing as literal "&amp;..." text
+	html := `<html><head><title>Caf&amp;eacute; guide</title></head><body><article>
+<h1>Caf&amp;eacute; guide</h1>
+<p>Itâ€™s the best cafÃ© in town &amp;amp; it&#8217;s open late. ` + strings.Repeat("More text about coffee. ", 20) + `</p>
+</article></body></html>`
+
+	p := &Parser{}
+	page, err := p.Parse(models.ParseRequest{URL: "https://example.com/cafe", HTML: html, Mode: models.ParseModeCheap})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	text := page.ToPlainText()
+	for _, want := range []string{"It's the best café in town & it's open late"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("plain text missing %q:\n%s", want, text)
+		}
+	}
+	for _, leak := range []string{"â€", "Ã©", "&amp;", "\u2019"} {
+		if strings.Contains(text, leak) {
+			t.Errorf("plain text still contains %q:\n%s", leak, text)
+		}
+	}
+}
+
+const pythonSnippet = `def greet(names):
+    for name in names:
+        if name:
+            print(f"hello {name}")
+
+    return len(names)`
+
+func TestParse_CodeKeepsIndentationThroughYAML(t *testing.T) {
+	html := `<html><head><title>Greeting</title></head><body><article>
+<h1>Greeting people</h1>
+<p>` + strings.Repeat("This function greets everyone on the list and counts them. ", 8) + `</p>
+<pre><code class="language-python">
+` + pythonSnippet + `
+</code></pre>
+<p>` + strings.Repeat("Call it with a list of names to see the output. ", 8) + `</p>
+</article></body></html>`
+
+	for name, mode := range map[string]models.ParseMode{"full": models.ParseModeFull, "cheap": models.ParseModeCheap} {
+		t.Run(name, func(t *testing.T) {
+			p := &Parser{}
+			page, err := p.Parse(models.ParseRequest{URL: "https://example.com/greet", HTML: html, Mode: mode})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// The stored parsed page and db show both go through YAML
+			data, err := yaml.Marshal(page)
+			if err != nil {
+				t.Fatalf("yaml.Marshal() error = %v", err)
+			}
+			var stored models.Page
+			if err := yaml.Unmarshal(data, &stored); err != nil {
+				t.Fatalf("yaml.Unmarshal() error = %v", err)
+			}
+
+			var code []string
+			for _, block := range append(stored.FlatContent, sectionBlocks(stored.Content)...) {
+				switch {
+				case block.Code != nil:
+					code = append(code, block.Code.Content)
+				case block.Type == "pre":
+					code = append(code, block.Text)
+				}
+			}
+			if len(code) != 1 || code[0] != pythonSnippet {
+				t.Errorf("code blocks = %q, want [%q]", code, pythonSnippet)
+			}
+		})
+	}
+}
+
+func sectionBlocks(sections []models.Section) []models.ContentBlock {
+	var blocks []models.ContentBlock
+	for _, s := range sections {
+		blocks = append(blocks, s.Blocks...)
+		blocks = append(blocks, sectionBlocks(s.Children)...)
+	}
+	return blocks
+}
+
+func TestParse_LineBreaks(t *testing.T) {
+	html := `<html><head><title>Poem</title></head><body><article>
+<h1>A short poem</h1>
+<p>The fog comes<br>on little cat feet.<br/>
+It sits looking<br>
+over harbor and city</p>
+<p>` + strings.Repeat("Some notes on the poem follow here. ", 10) + `</p>
+</article></body></html>`
+
+	tests := []struct {
+		keepLineBreaks bool
+		want           string
+	}{
+		{false, "The fog comes on little cat feet. It sits looking over harbor and city"},
+		{true, "The fog comes\non little cat feet.\nIt sits looking\nover harbor and city"},
+	}
+	for _, tt := range tests {
+		p := &Parser{}
+		page, err := p.Parse(models.ParseRequest{URL: "https://example.com/poem", HTML: html, Mode: models.ParseModeFull, KeepLineBreaks: tt.keepLineBreaks})
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		found := false
+		for _, block := range sectionBlocks(page.Content) {
+			if strings.HasPrefix(block.Text, "The fog") {
+				found = true
+				if block.Text != tt.want {
+					t.Errorf("KeepLineBreaks=%v: text = %q, want %q", tt.keepLineBreaks, block.Text, tt.want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("KeepLineBreaks=%v: poem block not found", tt.keepLineBreaks)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// Title sources recorded in PageMetadata.TitleSource.
+const (
+	TitleSourceReadability = "readability"
+	TitleSourceOGTitle     = "og_title"
+	TitleSourceH1          = "h1"
+	TitleSourceTitleTag    = "title_tag"
+)
+
+type titleCandidate struct {
+	title  string
+	source string
+}
+
+// chooseTitle walks the fallback chain readability → og:title → first <h1> → <title> and
+// returns the first non-empty title that is more than the site's name. When every
+// candidate is the site name (or empty), the first non-empty one is kept.
+func chooseTitle(readabilityTitle string, doc *goquery.Document, social *models.SocialMetadata, siteName string) (title, source string) {
+	candidates := []titleCandidate{{titleText(readabilityTitle), TitleSourceReadability}}
+	if social != nil {
+		candidates = append(candidates, titleCandidate{titleText(social.Title), TitleSourceOGTitle})
+		siteName = firstNonEmpty(siteName, social.SiteName)
+	}
+	if doc != nil {
+		candidates = append(candidates,
+			titleCandidate{titleText(doc.Find("body h1").First().Text()), TitleSourceH1},
+			titleCandidate{titleText(doc.Find("head title").First().Text()), TitleSourceTitleTag},
+		)
+	}
+
+	var fallback *titleCandidate
+	for i, c := range candidates {
+		if c.title == "" {
+			continue
+		}
+		if !isSiteName(c.title, siteName) {
+			return c.title, c.source
+		}
+		if fallback == nil {
+			fallback = &candidates[i]
+		}
+	}
+	if fallback == nil {
+		return "", ""
+	}
+	return fallback.title, fallback.source
+}
+
+// isSiteName reports whether title names the site rather than the page.
+func isSiteName(title, siteName string) bool {
+	siteName = titleText(siteName)
+	return siteName != "" && strings.EqualFold(title, siteName)
+}
+
+// titleText normalizes a title candidate and collapses its whitespace.
+func titleText(s string) string {
+	return strings.Join(strings.Fields(normalizeText(s)), " ")
+}
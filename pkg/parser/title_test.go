@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/dtnitsch/llm-web-parser/models"
+)
+
+// titlePage builds a page whose head and first heading are given; the article body is
+// long enough for readability to keep.
+func titlePage(head, h1 string) string {
+	heading := ""
+	if h1 != "" {
+		heading = "<h1>" + h1 + "</h1>"
+	}
+	return fmt.Sprintf(`<html><head>%s</head><body><header>%s</header><article>
+<p>Widgets are small mechanical parts that hold larger assemblies together in factories.</p>
+<p>This release makes every widget lighter and cheaper to produce at scale.</p>
+</article></body></html>`, head, heading)
+}
+
+func TestParse_TitleFallbacks(t *testing.T) {
+	tests := []struct {
+		name       string
+		html       string
+		wantTitle  string
+		wantSource string
+	}{
+		{
+			name:       "readability title",
+			html:       titlePage(`<title>Widgets 2.0 release notes</title>`, "Widgets 2.0"),
+			wantTitle:  "Widgets 2.0 release notes",
+			wantSource: TitleSourceReadability,
+		},
+		{
+			name:       "first h1 when the head has no title",
+			html:       titlePage(``, "Widgets 2.0   release"),
+			wantTitle:  "Widgets 2.0 release",
+			wantSource: TitleSourceH1,
+		},
+		{
+			name: "no title anywhere",
+			html: titlePage(``, ""),
+		},
+	}
+
+	p := &Parser{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, mode := range []models.ParseMode{models.ParseModeMinimal, models.ParseModeFull} {
+				page, err := p.Parse(models.ParseRequest{URL: "https://widgets.example.com/news/launch", HTML: tt.html, Mode: mode})
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				if page.Title != tt.wantTitle || page.Metadata.TitleSource != tt.wantSource {
+					t.Errorf("%s mode: title %q from %q, want %q from %q",
+						page.Metadata.ExtractionMode, page.Title, page.Metadata.TitleSource, tt.wantTitle, tt.wantSource)
+				}
+			}
+		})
+	}
+}
+
+func TestChooseTitle(t *testing.T) {
+	const siteHead = `<meta property="og:site_name" content="Widget Co">`
+	tests := []struct {
+		name             string
+		readabilityTitle string
+		html             string
+		wantTitle        string
+		wantSource       string
+	}{
+		{
+			name:             "readability title",
+			readabilityTitle: "Widgets 2.0 are here",
+			html:             titlePage(`<title>Widgets | Widget Co</title>`, "Widgets"),
+			wantTitle:        "Widgets 2.0 are here",
+			wantSource:       TitleSourceReadability,
+		},
+		{
+			name:             "og:title when readability returns the site name",
+			readabilityTitle: "Widget Co",
+			html:             titlePage(siteHead+`<meta property="og:title" content="Widgets 2.0 are here">`, "Widgets"),
+			wantTitle:        "Widgets 2.0 are here",
+			wantSource:       TitleSourceOGTitle,
+		},
+		{
+			name:             "h1 when readability and og:title are empty",
+			readabilityTitle: "",
+			html:             titlePage(siteHead, "Widgets 2.0"),
+			wantTitle:        "Widgets 2.0",
+			wantSource:       TitleSourceH1,
+		},
+		{
+			name:             "title tag when the others name the site",
+			readabilityTitle: "widget co",
+			html:             titlePage(siteHead+`<title>Release notes for 2.0</title>`, "Widget Co"),
+			wantTitle:        "Release notes for 2.0",
+			wantSource:       TitleSourceTitleTag,
+		},
+		{
+			name:             "site name when nothing more specific exists",
+			readabilityTitle: "Widget Co",
+			html:             titlePage(siteHead+`<title>Widget Co</title>`, ""),
+			wantTitle:        "Widget Co",
+			wantSource:       TitleSourceReadability,
+		},
+	}
+
+	base, _ := url.Parse("https://widgets.example.com/news/launch")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("NewDocumentFromReader() error = %v", err)
+			}
+			title, source := chooseTitle(tt.readabilityTitle, doc, extractSocialMetadata(doc, base), "")
+			if title != tt.wantTitle || source != tt.wantSource {
+				t.Errorf("chooseTitle() = %q from %q, want %q from %q", title, source, tt.wantTitle, tt.wantSource)
+			}
+		})
+	}
+
+	if title, source := chooseTitle(" Widgets ", nil, nil, ""); title != "Widgets" || source != TitleSourceReadability {
+		t.Errorf("chooseTitle() without a document = %q from %q", title, source)
+	}
+}
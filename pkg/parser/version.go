@@ -0,0 +1,6 @@
+package parser
+
+// Version identifies what Parse produces. Bump it whenever a change alters the parsed
+// output for the same HTML, so pages stored by an older parser can be found (their
+// parsed artifact records the version) and re-parsed with db refresh --outdated.
+const Version = "1.2.0"
@@ -0,0 +1,165 @@
+// Package schema describes output structs as JSON Schema, read from their Go types and
+// struct tags, so consumers can learn the shape of summaries and corpus responses.
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect Generate emits.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema node. Only the keywords the generator produces are modeled.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty" yaml:"title,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty" yaml:"$defs,omitempty"`
+}
+
+// Field is one serialized field of a struct, in declaration order.
+type Field struct {
+	Name     string
+	Schema   *Schema
+	Optional bool // omitempty: absent when zero
+}
+
+// Generate returns the schema of v's type as serialized under tag ("json" or "yaml").
+// Fields without omitempty are required; an `enum:"a,b"` tag lists a string field's values.
+func Generate(title string, v interface{}, tag string) *Schema {
+	s := typeSchema(reflect.TypeOf(v), tag, map[reflect.Type]bool{})
+	s.Schema = Draft
+	s.Title = title
+	return s
+}
+
+// Fields lists the serialized fields of struct v under tag, embedded structs flattened.
+func Fields(v interface{}, tag string) []Field {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return structFields(t, tag, map[reflect.Type]bool{t: true})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func typeSchema(t reflect.Type, tag string, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem(), tag, visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), tag, visiting)}
+	case reflect.Struct:
+		// A type nested inside itself is left open rather than expanded forever
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, f := range structFields(t, tag, visiting) {
+			s.Properties[f.Name] = f.Schema
+			if !f.Optional {
+				s.Required = append(s.Required, f.Name)
+			}
+		}
+		return s
+	}
+	return &Schema{} // interface{}: any value
+}
+
+func structFields(t reflect.Type, tag string, visiting map[reflect.Type]bool) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, opts, tagged := strings.Cut(sf.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+
+		// encoding/json flattens untagged embedded structs; yaml.v3 only those marked inline
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		inline := hasOption(opts, "inline") || (tag == "json" && sf.Anonymous && name == "")
+		if ft.Kind() == reflect.Struct && inline {
+			fields = append(fields, structFields(ft, tag, visiting)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name) // yaml.v3's default key
+			}
+		}
+		s := typeSchema(sf.Type, tag, visiting)
+		if enum := sf.Tag.Get("enum"); enum != "" {
+			s.Enum = strings.Split(enum, ",")
+		}
+		fields = append(fields, Field{Name: name, Schema: s, Optional: tagged && hasOption(opts, "omitempty")})
+	}
+	return fields
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeName renders a field's schema compactly, e.g. "string", "[string]" or
+// "[success, failed]" for an enum.
+func TypeName(s *Schema) string {
+	switch {
+	case len(s.Enum) > 0:
+		return "[" + strings.Join(s.Enum, ", ") + "]"
+	case s.Type == "array" && s.Items != nil:
+		return "[" + TypeName(s.Items) + "]"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map of " + TypeName(s.AdditionalProperties)
+	case s.Type == "integer":
+		return "int"
+	case s.Type == "number":
+		return "float"
+	case s.Type == "boolean":
+		return "bool"
+	case s.Type == "":
+		return "any"
+	}
+	return s.Type
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Kind string `json:"kind" enum:"a,b"`
+}
+
+type embedded struct {
+	Shared int `json:"shared"`
+}
+
+type sample struct {
+	Name    string            `json:"name"`
+	Count   int               `json:"count,omitempty"`
+	Score   float64           `json:"score"`
+	OK      bool              `json:"ok"`
+	Tags    []string          `json:"tags,omitempty"`
+	Counts  map[string]int    `json:"counts,omitempty"`
+	Inner   *inner            `json:"inner,omitempty"`
+	When    time.Time         `json:"when"`
+	Data    interface{}       `json:"data"`
+	Skipped string            `json:"-"`
+	Self    []sample          `json:"self,omitempty"`
+	Extra   map[string]string `yaml:"extra"`
+	embedded
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate("sample", sample{}, "json")
+	if s.Schema != Draft || s.Title != "sample" || s.Type != "object" {
+		t.Fatalf("Generate() header = %q %q %q", s.Schema, s.Title, s.Type)
+	}
+
+	wantTypes := map[string]string{
+		"name": "string", "count": "integer", "score": "number", "ok": "boolean",
+		"tags": "array", "counts": "object", "inner": "object", "when": "string",
+		"data": "", "self": "array", "Extra": "object", "shared": "integer",
+	}
+	if len(s.Properties) != len(wantTypes) {
+		t.Errorf("properties = %v, want %d", reflect.ValueOf(s.Properties).MapKeys(), len(wantTypes))
+	}
+	for name, want := range wantTypes {
+		if p, ok := s.Properties[name]; !ok || p.Type != want {
+			t.Errorf("property %s = %+v, want type %q", name, p, want)
+		}
+	}
+
+	if got := s.Properties["inner"].Properties["kind"].Enum; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("inner.kind enum = %v, want [a b]", got)
+	}
+	if s.Properties["when"].Format != "date-time" {
+		t.Errorf("when format = %q, want date-time", s.Properties["when"].Format)
+	}
+	if items := s.Properties["self"].Items; items == nil || items.Type != "object" || items.Properties != nil {
+		t.Errorf("self items = %+v, want an open object for the recursive type", items)
+	}
+	wantRequired := []string{"name", "score", "ok", "when", "data", "Extra", "shared"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("required = %v, want %v", s.Required, wantRequired)
+	}
+}
+
+// Under yaml the embedded struct is not inlined (and its type is unexported), so
+// "shared" is missing, and the json-only "-" tag no longer hides Skipped.
+func TestFieldsAndTypeName(t *testing.T) {
+	fields := Fields(&sample{}, "yaml")
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name+"="+TypeName(f.Schema))
+	}
+	want := []string{"name=string", "count=int", "score=float", "ok=bool", "tags=[string]", "counts=map of int",
+		"inner=object", "when=string", "data=any", "skipped=string", "self=[object]", "extra=map of string"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Fields(yaml) = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// How long UpdateSessionIndex waits for another run to finish its update
+	indexLockTimeout = 10 * time.Second
+	// A lock older than this was left behind by a run that crashed mid-update
+	indexLockStale = 30 * time.Second
+	indexLockPoll  = 10 * time.Millisecond
+)
+
+// lockFile takes an exclusive lock on path, shared with other processes, by creating
+// path+".lock"; the returned func releases it. Creating the file with O_EXCL works on
+// every platform, unlike flock. The lock file holds a token unique to this holder, so
+// neither a stale-lock takeover nor a late release removes someone else's lock.
+func lockFile(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	token := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	deadline := time.Now().Add(indexLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600) // #nosec G304 -- derived from the fixed index path
+		if err == nil {
+			_, writeErr := fmt.Fprintf(f, "%s\n", token)
+			if closeErr := f.Close(); writeErr == nil {
+				writeErr = closeErr
+			}
+			if writeErr != nil {
+				_ = os.Remove(lockPath) // #nosec G104 -- nobody else can hold it yet
+				return nil, fmt.Errorf("failed to write lock file: %w", writeErr)
+			}
+			return func() { releaseLock(lockPath, token) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if takeOverStaleLock(lockPath, token) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", lockPath)
+		}
+		time.Sleep(indexLockPoll)
+	}
+}
+
+// takeOverStaleLock removes lockPath when it is older than indexLockStale, reporting
+// whether it did. Removing it directly could delete a fresh lock another waiter created
+// after the stale one was gone, so the lock is first renamed aside, which only one
+// waiter can win, and deleted only if what was moved is the stale lock that was checked.
+// Anything else is put back.
+func takeOverStaleLock(lockPath, token string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= indexLockStale {
+		return false
+	}
+	stale, err := os.ReadFile(lockPath) // #nosec G304 -- derived from the fixed index path
+	if err != nil {
+		return false
+	}
+
+	aside := lockPath + "." + token
+	if err := os.Rename(lockPath, aside); err != nil {
+		return false // Another waiter moved or removed it first
+	}
+	moved, err := os.ReadFile(aside) // #nosec G304 -- derived from the fixed index path
+	if movedInfo, statErr := os.Stat(aside); err == nil && statErr == nil &&
+		bytes.Equal(moved, stale) && time.Since(movedInfo.ModTime()) > indexLockStale {
+		_ = os.Remove(aside) // #nosec G104 -- a leftover only costs a stray file
+		return true
+	}
+
+	// A live lock replaced the stale one between the checks: hand it back without
+	// replacing a lock created since
+	if err := os.Link(aside, lockPath); err == nil || errors.Is(err, os.ErrExist) {
+		_ = os.Remove(aside) // #nosec G104
+	} else {
+		_ = os.Rename(aside, lockPath) // #nosec G104 -- no hard links on this filesystem
+	}
+	return false
+}
+
+// releaseLock removes lockPath if it still holds token, leaving alone a lock another
+// run took over after this one was considered stale.
+func releaseLock(lockPath, token string) {
+	data, err := os.ReadFile(lockPath) // #nosec G304 -- derived from the fixed index path
+	if err != nil || string(bytes.TrimSpace(data)) != token {
+		return
+	}
+	_ = os.Remove(lockPath) // #nosec G104 -- the lock goes stale if this fails
+}
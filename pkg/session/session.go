@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dtnitsch/llm-web-parser/pkg/artifact_manager"
+	"github.com/dtnitsch/llm-web-parser/pkg/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,6 +22,7 @@ type Info struct {
 	Success     int       `yaml:"success"`
 	Failed      int       `yaml:"failed"`
 	Features    []string  `yaml:"features,omitempty"`
+	Tag         string    `yaml:"tag,omitempty"`
 	URLsPreview []string  `yaml:"urls_preview,omitempty"` // First 3 URLs
 }
 
@@ -67,10 +70,17 @@ func EnsureSessionDir(sessionID int64, timestamp time.Time) error {
 	return nil
 }
 
-// UpdateSessionIndex adds or updates a session entry in lwp-sessions/index.yaml.
+// UpdateSessionIndex adds or updates a session entry in lwp-sessions/index.yaml. The
+// read-modify-write holds a lock file, so concurrent fetch runs don't drop each other's entries.
 func UpdateSessionIndex(info Info) error {
 	indexPath := GetSessionsIndexPath()
 
+	unlock, err := lockFile(indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to lock session index: %w", err)
+	}
+	defer unlock()
+
 	// Read existing index
 	var index Index
 	data, err := os.ReadFile(filepath.Clean(indexPath))
@@ -110,7 +120,7 @@ func UpdateSessionIndex(info Info) error {
 		return fmt.Errorf("failed to marshal session index: %w", err)
 	}
 
-	if err := os.WriteFile(indexPath, output, 0600); err != nil {
+	if err := artifact_manager.WriteFileAtomic(indexPath, output, 0600); err != nil {
 		return fmt.Errorf("failed to write session index: %w", err)
 	}
 
@@ -141,74 +151,40 @@ func FormatFeatures(features string) []string {
 	return result
 }
 
-// GenerateFieldsReference creates or updates the FIELDS.yaml reference file.
-func GenerateFieldsReference(baseDir string) error {
+// GenerateFieldsReference writes the FIELDS.yaml reference for summary-details.yaml,
+// listing fields as generated from the summary struct ('lwp schema' has the full JSON
+// Schema). The file is rewritten whenever the fields change.
+func GenerateFieldsReference(baseDir string, fields []schema.Field) error {
 	fieldsPath := filepath.Join(baseDir, "FIELDS.yaml")
 
-	// Check if file already exists
-	if _, err := os.Stat(fieldsPath); err == nil {
-		// File exists, don't overwrite
-		return nil
+	var sb strings.Builder
+	sb.WriteString(`# Summary Fields Reference (LLM-Optimized)
+# Generated from the summary-details.yaml structure; run 'llm-web-parser schema' for the JSON Schema
+
+fields:
+`)
+	for _, f := range fields {
+		line := fmt.Sprintf("  %s: %s", f.Name, schema.TypeName(f.Schema))
+		if f.Optional {
+			line += " # omitted when empty"
+		}
+		sb.WriteString(line + "\n")
 	}
+	sb.WriteString(fieldsReferenceFooter)
+	content := sb.String()
 
-	content := `# Summary Fields Reference (LLM-Optimized)
-# Auto-generated field documentation for llm-web-parser output
+	if existing, err := os.ReadFile(filepath.Clean(fieldsPath)); err == nil && string(existing) == content {
+		return nil
+	}
+	if err := artifact_manager.WriteFileAtomic(fieldsPath, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write FIELDS.yaml: %w", err)
+	}
 
-fields:
-  # Status & Basic Info
-  url: string
-  status: [success, failed]
-  status_code: int (HTTP status)
-  error: string (only if failed)
-
-  # Basic Metadata
-  title: string
-  excerpt: string (description/summary)
-  site_name: string
-  author: string
-  published_at: string (ISO-8601 date)
-
-  # Domain Classification
-  domain_type: [gov, edu, academic, commercial, mobile, unknown]
-  domain_category: [gov/health, academic/ai, academic/general, news/tech, docs/api, blog, general]
-  country: string (2-letter code or "unknown")
-  confidence: float (0-10 quality/credibility score)
-
-  # Academic Signals (boolean, only present if true)
-  has_doi: bool
-  has_arxiv: bool
-  has_latex: bool
-  has_citations: bool
-  has_references: bool
-  has_abstract: bool
-  academic_score: float (0-10 composite academic signal strength)
-  doi: string (DOI pattern if found)
-  arxiv_id: string (ArXiv ID if found)
-
-  # Content Metrics
-  word_count: int
-  estimated_tokens: int (word_count / 2.5)
-  read_time_min: float
-  section_count: int (number of sections/headings)
-  block_count: int (number of content blocks)
-
-  # Language Detection
-  language: string (ISO-639-1 code: en, es, fr, de, etc)
-  language_confidence: float (0-1)
-
-  # Content Type
-  content_type: [landing, article, documentation, unknown]
-  extraction_mode: [minimal, cheap, full]
-
-  # Visual Metadata
-  has_favicon: bool (site has favicon)
-  image_count: int (number of images detected)
-
-  # HTTP Metadata
-  final_url: string (after redirects)
-  redirect_chain: [string] (list of redirect URLs)
-  http_content_type: string (Content-Type header)
+	return nil
+}
 
+// fieldsReferenceFooter holds the hand-written query examples that follow the field list.
+const fieldsReferenceFooter = `
 query_examples:
   - desc: Government health sites with high confidence
     yq: '.[] | select(.domain_category == "gov/health" and .confidence >= 7)'
@@ -228,6 +204,12 @@ query_examples:
   - desc: Non-English content
     yq: '.[] | select(.language != "en" and .language_confidence > 0.8)'
 
+  - desc: Full content only (drop paywall teasers)
+    yq: '.[] | select(.paywalled | not)'
+
+  - desc: Drop pages that ask not to be indexed
+    yq: '.[] | select(.noindex | not)'
+
   - desc: Failed fetches only
     yq: '.[] | select(.status == "failed")'
 
@@ -241,10 +223,3 @@ usage:
   session_index: lwp-sessions/index.yaml (list all sessions)
   url_artifacts: lwp-results/{url_id}/ (raw.html, generic.yaml, etc.)
 `
-
-	if err := os.WriteFile(fieldsPath, []byte(content), 0600); err != nil {
-		return fmt.Errorf("failed to write FIELDS.yaml: %w", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestUpdateSessionIndex_Concurrent(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("lwp-sessions", 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each goroutine stands in for a separate fetch run finishing at the same time
+	const runs = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for id := int64(1); id <= runs; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- UpdateSessionIndex(Info{SessionID: id, Created: time.Now(), URLCount: 1, Success: 1})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateSessionIndex() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(GetSessionsIndexPath())
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var index Index
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index.yaml does not parse: %v", err)
+	}
+	if len(index.Sessions) != runs {
+		t.Fatalf("index has %d sessions, want %d", len(index.Sessions), runs)
+	}
+	for i, s := range index.Sessions {
+		if want := int64(runs - i); s.SessionID != want {
+			t.Errorf("sessions[%d] = %d, want %d (newest first)", i, s.SessionID, want)
+		}
+	}
+	if _, err := os.Stat(GetSessionsIndexPath() + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestLockFile_StaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.yaml")
+	if err := os.WriteFile(path+".lock", []byte("12345\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * indexLockStale)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lock left by a crashed run is taken over rather than waited on
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+	if takeOverStaleLock(path+".lock", "waiter") {
+		t.Error("takeOverStaleLock() took over a fresh lock")
+	}
+	unlock()
+	if matches, _ := filepath.Glob(path + ".lock*"); len(matches) != 0 {
+		t.Errorf("lock files left behind: %v", matches)
+	}
+}
+
+func TestLockFile_ReleaseKeepsTakenOverLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.yaml")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	// Another run decided the lock was stale and holds its own now
+	if err := os.WriteFile(path+".lock", []byte("999-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if data, err := os.ReadFile(path + ".lock"); err != nil || string(data) != "999-1\n" {
+		t.Errorf("late release removed the other run's lock: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package urlnorm reduces URLs to the key that identifies them in storage. The artifact
+// manager hashes the key into file names and the database stores it as canonical_url,
+// so both layers normalize through the one active policy kept here.
+package urlnorm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// trackingParams are query parameters that identify a campaign, click or mail-out
+// rather than content; DropTracking removes them and any utm_* parameter.
+var trackingParams = map[string]bool{
+	"gclid": true, "gclsrc": true, "dclid": true, "gbraid": true, "wbraid": true, // Google Ads
+	"fbclid": true, "msclkid": true, "twclid": true, "ttclid": true, "li_fat_id": true, "yclid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, // Mailchimp
+	"_ga": true, "_gl": true, // Google Analytics cross-domain
+	"_hsenc": true, "_hsmi": true, "mkt_tok": true, "vero_id": true, "oly_anon_id": true, "oly_enc_id": true,
+}
+
+// IsTrackingParam reports whether a query parameter name only tracks the visit.
+func IsTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// Policy controls which differences between two URLs still make them different pages.
+// Hosts are always lowercased.
+type Policy struct {
+	KeepFragment bool // Keep #fragments; servers never see them, so they are dropped by default
+	ForceHTTPS   bool // Rewrite http:// to https://
+	SortQuery    bool // Order query parameters by key, so ?b=2&a=1 matches ?a=1&b=2
+	DropQuery    bool // Drop the query string entirely
+	DropTracking bool // Drop tracking parameters (IsTrackingParam) and keep the rest
+}
+
+// DefaultPolicy forces https, drops tracking parameters, sorts the rest and drops fragments.
+var DefaultPolicy = Policy{ForceHTTPS: true, SortQuery: true, DropTracking: true}
+
+var (
+	mu     sync.RWMutex
+	active = DefaultPolicy
+)
+
+// SetPolicy changes the policy NormalizeURL applies. Set it once at startup: keys
+// computed under another policy no longer match stored ones.
+func SetPolicy(p Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = p
+}
+
+// CurrentPolicy returns the policy NormalizeURL applies.
+func CurrentPolicy() Policy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// NormalizeURL returns the storage key of rawURL under the active policy.
+func NormalizeURL(rawURL string) (string, error) {
+	return CurrentPolicy().Normalize(rawURL)
+}
+
+// NormalizeParsed returns the storage key of an already parsed URL under the active policy.
+func NormalizeParsed(u *url.URL) string {
+	return CurrentPolicy().NormalizeParsed(u)
+}
+
+// Normalize returns the storage key of rawURL under p.
+func (p Policy) Normalize(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	return p.NormalizeParsed(u), nil
+}
+
+// NormalizeParsed returns the storage key of an already parsed URL under p. u is not modified.
+func (p Policy) NormalizeParsed(u *url.URL) string {
+	key := *u
+	if p.ForceHTTPS && key.Scheme == "http" {
+		key.Scheme = "https"
+	}
+	key.Host = strings.ToLower(key.Host)
+
+	switch {
+	case p.DropQuery:
+		key.RawQuery = ""
+		key.ForceQuery = false
+	case p.DropTracking && key.RawQuery != "":
+		key.RawQuery = dropTracking(key.RawQuery)
+		key.ForceQuery = false
+	}
+	if p.SortQuery && !p.DropQuery && key.RawQuery != "" {
+		key.RawQuery = key.Query().Encode() // Encode orders by key
+	}
+
+	if !p.KeepFragment {
+		key.Fragment = ""
+		key.RawFragment = ""
+	}
+	return key.String()
+}
+
+// dropTracking removes tracking parameters from a raw query, keeping the order and
+// encoding of the others.
+func dropTracking(rawQuery string) string {
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if pair != "" && !IsTrackingParam(name) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// String returns the ParsePolicy spec of p, naming every option so that policies which
+// produce different keys have different specs. The database stores it next to each key.
+func (p Policy) String() string {
+	options := []string{"drop-fragment", "keep-scheme", "keep-query-order", "significant-params"}
+	if p.KeepFragment {
+		options[0] = "keep-fragment"
+	}
+	if p.ForceHTTPS {
+		options[1] = "force-https"
+	}
+	if p.SortQuery {
+		options[2] = "sort-query"
+	}
+	switch {
+	case p.DropQuery:
+		options[3] = "drop-params"
+	case p.DropTracking:
+		options[3] = "drop-tracking"
+	}
+	return strings.Join(options, ",")
+}
+
+// ParsePolicy reads a comma-separated list of options applied on top of DefaultPolicy:
+// keep-fragment, drop-fragment, force-https, keep-scheme, sort-query, keep-query-order,
+// and one of the query parameter policies significant-params (keep every parameter),
+// drop-tracking (drop tracking parameters) and drop-params (drop the whole query;
+// drop-query is an alias). "default" or an empty spec is DefaultPolicy itself.
+func ParsePolicy(spec string) (Policy, error) {
+	p := DefaultPolicy
+	for _, option := range strings.Split(spec, ",") {
+		switch strings.ToLower(strings.TrimSpace(option)) {
+		case "", "default":
+		case "keep-fragment":
+			p.KeepFragment = true
+		case "drop-fragment":
+			p.KeepFragment = false
+		case "force-https":
+			p.ForceHTTPS = true
+		case "keep-scheme":
+			p.ForceHTTPS = false
+		case "sort-query":
+			p.SortQuery, p.DropQuery = true, false
+		case "keep-query-order":
+			p.SortQuery = false
+		case "significant-params":
+			p.DropQuery, p.DropTracking = false, false
+		case "drop-tracking":
+			p.DropQuery, p.DropTracking = false, true
+		case "drop-params", "drop-query":
+			p.DropQuery, p.DropTracking = true, false
+		default:
+			return Policy{}, fmt.Errorf("unknown URL key option %q (supported: keep-fragment, drop-fragment, force-https, keep-scheme, sort-query, keep-query-order, significant-params, drop-tracking, drop-params)", option)
+		}
+	}
+	return p, nil
+}
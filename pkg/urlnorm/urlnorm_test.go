@@ -0,0 +1,76 @@
+package urlnorm
+
+import "testing"
+
+func TestPolicy_Normalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		url    string
+		want   string
+	}{
+		{"default forces https", DefaultPolicy, "http://Example.COM/a", "https://example.com/a"},
+		{"default sorts query", DefaultPolicy, "https://example.com/?b=2&a=1", "https://example.com/?a=1&b=2"},
+		{"default drops fragment", DefaultPolicy, "https://example.com/page#intro", "https://example.com/page"},
+		{"keep fragment", Policy{ForceHTTPS: true, KeepFragment: true}, "https://example.com/page#intro", "https://example.com/page#intro"},
+		{"keep scheme", Policy{SortQuery: true}, "http://example.com/", "http://example.com/"},
+		{"keep query order", Policy{ForceHTTPS: true}, "https://example.com/?b=2&a=1", "https://example.com/?b=2&a=1"},
+		{"drop query", Policy{ForceHTTPS: true, SortQuery: true, DropQuery: true}, "https://example.com/docs?page=2", "https://example.com/docs"},
+		{"default drops tracking", DefaultPolicy, "https://example.com/item?utm_source=x&id=123&fbclid=abc", "https://example.com/item?id=123"},
+		{"only tracking params", DefaultPolicy, "https://example.com/item?utm_campaign=spring&UTM_MEDIUM=mail", "https://example.com/item"},
+		{"drop tracking keeps order", Policy{ForceHTTPS: true, DropTracking: true}, "https://example.com/?b=2&gclid=1&a=1", "https://example.com/?b=2&a=1"},
+		{"significant params", Policy{ForceHTTPS: true, SortQuery: true}, "https://example.com/?utm_source=x&id=1", "https://example.com/?id=1&utm_source=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Normalize(tt.url)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("keep-fragment, keep-scheme,drop-query")
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	if want := (Policy{KeepFragment: true, SortQuery: true, DropQuery: true}); p != want {
+		t.Errorf("ParsePolicy() = %+v, want %+v", p, want)
+	}
+
+	if p, err := ParsePolicy(""); err != nil || p != DefaultPolicy {
+		t.Errorf("ParsePolicy(\"\") = %+v, %v, want DefaultPolicy", p, err)
+	}
+	if p, err := ParsePolicy("significant-params"); err != nil || p.DropTracking || p.DropQuery {
+		t.Errorf("ParsePolicy(significant-params) = %+v, %v, want every parameter kept", p, err)
+	}
+	if p, err := ParsePolicy("drop-params,drop-tracking"); err != nil || !p.DropTracking || p.DropQuery {
+		t.Errorf("ParsePolicy(drop-params,drop-tracking) = %+v, %v, want the last parameter policy", p, err)
+	}
+	if _, err := ParsePolicy("lowercase-path"); err == nil {
+		t.Error("ParsePolicy() accepted an unknown option")
+	}
+}
+
+func TestPolicy_String(t *testing.T) {
+	if got, want := DefaultPolicy.String(), "drop-fragment,force-https,sort-query,drop-tracking"; got != want {
+		t.Errorf("DefaultPolicy.String() = %q, want %q", got, want)
+	}
+	for _, spec := range []string{"", "keep-fragment,keep-scheme", "keep-query-order,significant-params", "drop-params"} {
+		p, err := ParsePolicy(spec)
+		if err != nil {
+			t.Fatalf("ParsePolicy(%q) error = %v", spec, err)
+		}
+		if back, err := ParsePolicy(p.String()); err != nil || back != p {
+			t.Errorf("ParsePolicy(%q) = %+v, %v, want %+v back", p.String(), back, err, p)
+		}
+	}
+	if DefaultPolicy.String() == (Policy{ForceHTTPS: true, SortQuery: true}).String() {
+		t.Error("policies that key tracking parameters differently share a spec")
+	}
+}